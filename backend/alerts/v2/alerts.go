@@ -7,6 +7,7 @@ import (
 	"time"
 
 	e "github.com/pkg/errors"
+	"github.com/samber/lo"
 	"github.com/sendgrid/sendgrid-go"
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -18,6 +19,7 @@ import (
 	slackV2 "github.com/highlight-run/highlight/backend/alerts/v2/destinations/slack"
 	webhookV2 "github.com/highlight-run/highlight/backend/alerts/v2/destinations/webhook"
 	"github.com/highlight-run/highlight/backend/env"
+	"github.com/highlight-run/highlight/backend/errorgroups"
 	"github.com/highlight-run/highlight/backend/lambda"
 	"github.com/highlight-run/highlight/backend/model"
 	modelInputs "github.com/highlight-run/highlight/backend/private-graph/graph/model"
@@ -83,7 +85,7 @@ func SendAlerts(ctx context.Context, db *gorm.DB, mailClient *sendgrid.Client, l
 		alertInput.LogInput = buildLogAlertInput(ctx, db, &alertInput)
 	case modelInputs.ProductTypeTraces:
 		alertInput.TraceInput = buildTraceAlertInput(ctx, db, &alertInput)
-	case modelInputs.ProductTypeMetrics:
+	case modelInputs.ProductTypeMetrics, modelInputs.ProductTypeWebVitals:
 		alertInput.MetricInput = buildMetricAlertInput(ctx, db, &alertInput)
 	case modelInputs.ProductTypeEvents:
 		// nothing extra needed
@@ -178,19 +180,117 @@ func buildErrorAlertInput(ctx context.Context, db *gorm.DB, alertInput *destinat
 	}
 
 	return &destinationsV2.ErrorInput{
-		Event:             errorObject.Event,
-		Stacktrace:        stacktrace,
-		State:             errorGroup.State,
-		ErrorLink:         errorURL,
-		ProjectName:       *project.Name,
-		ServiceName:       errorObject.ServiceName,
-		SessionSecureID:   sessionSecureID,
-		SessionIdentifier: sessionIdentifier,
-		SessionLink:       sessionUrl,
-		SessionExcluded:   sessionExcluded,
+		Event:              errorObject.Event,
+		Stacktrace:         stacktrace,
+		State:              errorGroup.State,
+		ErrorLink:          errorURL,
+		ProjectName:        *project.Name,
+		ServiceName:        errorObject.ServiceName,
+		SessionSecureID:    sessionSecureID,
+		SessionIdentifier:  sessionIdentifier,
+		SessionLink:        sessionUrl,
+		SessionExcluded:    sessionExcluded,
+		CodeOwner:          lo.FromPtr(errorGroup.CodeOwner),
+		ResolvedInVersion:  lo.FromPtr(errorGroup.ResolvedInVersion),
+		RegressedInVersion: lo.FromPtr(errorGroup.RegressedInVersion),
 	}, nil
 }
 
+// SendErrorRegressionAlerts sends the project's regression alerts matching the error object
+// that caused the error group to regress.
+func SendErrorRegressionAlerts(ctx context.Context, db *gorm.DB, mailClient *sendgrid.Client, lambdaClient *lambda.Client, errorGroup *model.ErrorGroup, errorObject *model.ErrorObject) error {
+	var alerts []*model.Alert
+	if err := db.WithContext(ctx).Model(&model.Alert{}).
+		Where(&model.Alert{ProjectID: errorGroup.ProjectID, ProductType: modelInputs.ProductTypeErrors, ThresholdType: modelInputs.ThresholdTypeRegression}).
+		Where("disabled = ?", false).
+		Find(&alerts).Error; err != nil {
+		return err
+	}
+
+	for _, alert := range alerts {
+		if !errorgroups.ErrorMatchesQuery(errorObject, lo.FromPtr(alert.Query)) {
+			continue
+		}
+		if err := SendAlerts(ctx, db, mailClient, lambdaClient, alert, string(modelInputs.ReservedErrorGroupKeySecureID), errorGroup.SecureID, 1); err != nil {
+			log.WithContext(ctx).WithError(err).WithField("alert_id", alert.ID).Error("failed to send error regression alert")
+		}
+	}
+	return nil
+}
+
+// SendErrorGroupAssignmentNotifications notifies the assignee of the error group through their chosen destinations.
+// Admins without a chosen destination are notified by email.
+// admin is the admin that assigned the group, and rule the auto-assignment rule that did; both are nil for regressions.
+func SendErrorGroupAssignmentNotifications(ctx context.Context, db *gorm.DB, mailClient *sendgrid.Client, lambdaClient *lambda.Client, notificationType destinationsV2.NotificationType, errorGroup *model.ErrorGroup, admin *model.Admin, rule *model.ErrorGroupAssignmentRule) error {
+	if errorGroup.AssigneeAdminID == nil && errorGroup.AssigneeTeam == nil {
+		return nil
+	}
+
+	var project model.Project
+	if err := db.WithContext(ctx).Model(&model.Project{}).Where(&model.Project{Model: model.Model{ID: errorGroup.ProjectID}}).Take(&project).Error; err != nil {
+		return err
+	}
+
+	var assigneeDestinations []*model.AssigneeDestination
+	query := db.WithContext(ctx).Model(&model.AssigneeDestination{}).Where("project_id = ?", errorGroup.ProjectID)
+	if errorGroup.AssigneeAdminID != nil {
+		query = query.Where("assignee_admin_id = ?", *errorGroup.AssigneeAdminID)
+	} else {
+		query = query.Where("assignee_team = ?", *errorGroup.AssigneeTeam)
+	}
+	if err := query.Find(&assigneeDestinations).Error; err != nil {
+		return err
+	}
+
+	destinations := lo.Map(assigneeDestinations, func(destination *model.AssigneeDestination, _ int) *model.AlertDestination {
+		return &model.AlertDestination{
+			DestinationType: destination.DestinationType,
+			TypeID:          destination.TypeID,
+			TypeName:        destination.TypeName,
+		}
+	})
+
+	assigneeName := lo.FromPtr(errorGroup.AssigneeTeam)
+	if errorGroup.AssigneeAdminID != nil {
+		var assignee model.Admin
+		if err := db.WithContext(ctx).Model(&model.Admin{}).Where("id = ?", *errorGroup.AssigneeAdminID).Take(&assignee).Error; err != nil {
+			return err
+		}
+		assigneeName = lo.FromPtr(assignee.Email)
+		if assignee.Name != nil && *assignee.Name != "" {
+			assigneeName = *assignee.Name
+		}
+		if len(destinations) == 0 && assignee.Email != nil {
+			destinations = append(destinations, &model.AlertDestination{
+				DestinationType: modelInputs.AlertDestinationTypeEmail,
+				TypeID:          *assignee.Email,
+				TypeName:        *assignee.Email,
+			})
+		}
+	}
+	if len(destinations) == 0 {
+		return nil
+	}
+
+	input := &destinationsV2.ErrorGroupAssignmentInput{
+		ErrorGroup:   errorGroup,
+		ErrorLink:    fmt.Sprintf("%s/%d/errors/%s", env.Config.FrontendUri, errorGroup.ProjectID, errorGroup.SecureID),
+		ProjectName:  lo.FromPtr(project.Name),
+		AssigneeName: assigneeName,
+		Admin:        admin,
+	}
+	if rule != nil {
+		input.RuleName = rule.Name
+	}
+
+	SendNotifications(ctx, db, mailClient, lambdaClient, destinationsV2.NotificationInput{
+		NotificationType:          notificationType,
+		WorkspaceID:               project.WorkspaceID,
+		ErrorGroupAssignmentInput: input,
+	}, destinations)
+	return nil
+}
+
 func buildLogAlertInput(ctx context.Context, db *gorm.DB, alertInput *destinationsV2.AlertInput) *destinationsV2.LogInput {
 	frontendURL := env.Config.FrontendUri
 	queryStr := url.QueryEscape(*alertInput.Alert.Query)
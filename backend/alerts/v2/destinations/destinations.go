@@ -1,8 +1,10 @@
 package destinationsV2
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/aws/smithy-go/ptr"
 	"github.com/highlight-run/highlight/backend/model"
 	modelInputs "github.com/highlight-run/highlight/backend/private-graph/graph/model"
 )
@@ -12,12 +14,16 @@ type NotificationType string
 const (
 	NotificationTypeAlertCreated NotificationType = "alert_created"
 	NotificationTypeAlertUpdated NotificationType = "alert_updated"
+	// sent to the assignee of an error group
+	NotificationTypeErrorGroupAssigned  NotificationType = "error_group_assigned"
+	NotificationTypeErrorGroupRegressed NotificationType = "error_group_regressed"
 )
 
 type NotificationInput struct {
-	NotificationType NotificationType
-	WorkspaceID      int
-	AlertUpsertInput *AlertUpsertInput
+	NotificationType          NotificationType
+	WorkspaceID               int
+	AlertUpsertInput          *AlertUpsertInput
+	ErrorGroupAssignmentInput *ErrorGroupAssignmentInput
 }
 
 type AlertUpsertInput struct {
@@ -25,6 +31,47 @@ type AlertUpsertInput struct {
 	Admin *model.Admin
 }
 
+type ErrorGroupAssignmentInput struct {
+	ErrorGroup   *model.ErrorGroup
+	ErrorLink    string
+	ProjectName  string
+	AssigneeName string
+	// nil when the error group was assigned by an auto-assignment rule
+	Admin    *model.Admin
+	RuleName string
+}
+
+// ErrorGroupAssignmentTitle is the heading of an assignment notification.
+func (n *NotificationInput) ErrorGroupAssignmentTitle() string {
+	if n.NotificationType == NotificationTypeErrorGroupRegressed {
+		if version := n.ErrorGroupAssignmentInput.ErrorGroup.RegressedInVersion; version != nil && *version != "" {
+			return fmt.Sprintf("Assigned Error Regressed in %s", *version)
+		}
+		return "Assigned Error Regressed"
+	}
+	return fmt.Sprintf("Error Assigned to %s", n.ErrorGroupAssignmentInput.AssigneeName)
+}
+
+// ErrorGroupAssignmentDescription describes who assigned the error group, or that it regressed.
+// The error event is not included so that destinations can format it.
+func (n *NotificationInput) ErrorGroupAssignmentDescription() string {
+	input := n.ErrorGroupAssignmentInput
+	if n.NotificationType == NotificationTypeErrorGroupRegressed {
+		return fmt.Sprintf("An error assigned to %s in %s regressed.", input.AssigneeName, input.ProjectName)
+	}
+	if input.Admin != nil {
+		name := input.Admin.Name
+		if name == nil {
+			name = input.Admin.Email
+		}
+		return fmt.Sprintf("%s assigned an error in %s to %s.", ptr.ToString(name), input.ProjectName, input.AssigneeName)
+	}
+	if input.RuleName != "" {
+		return fmt.Sprintf("The assignment rule \"%s\" assigned an error in %s to %s.", input.RuleName, input.ProjectName, input.AssigneeName)
+	}
+	return fmt.Sprintf("An error in %s was assigned to %s.", input.ProjectName, input.AssigneeName)
+}
+
 // specific to alert notifications
 type AlertInput struct {
 	Alert        *model.Alert
@@ -59,6 +106,27 @@ type ErrorInput struct {
 	SessionIdentifier string
 	SessionLink       string
 	SessionExcluded   bool
+	CodeOwner         string
+	// set for regression alerts
+	ResolvedInVersion  string
+	RegressedInVersion string
+}
+
+// IsRegression reports whether the alert fires when an error group regresses
+// rather than when an error count threshold is crossed.
+func (a *AlertInput) IsRegression() bool {
+	return a.Alert.ProductType == modelInputs.ProductTypeErrors && a.Alert.ThresholdType == modelInputs.ThresholdTypeRegression
+}
+
+// ErrorAlertTitle is the heading of an error alert message.
+func (a *AlertInput) ErrorAlertTitle() string {
+	if !a.IsRegression() {
+		return fmt.Sprintf("Error Alert: %d Recent Occurrences", int(a.AlertValue))
+	}
+	if a.ErrorInput.RegressedInVersion != "" {
+		return fmt.Sprintf("Error Regressed in %s", a.ErrorInput.RegressedInVersion)
+	}
+	return "Error Regressed"
 }
 
 type LogInput struct {
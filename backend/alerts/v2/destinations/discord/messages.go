@@ -60,7 +60,7 @@ func SendAlerts(ctx context.Context, discordGuildId *string, alertInput *destina
 		sendLogAlert(ctx, *discordGuildId, alertInput, destinations)
 	case modelInputs.ProductTypeTraces:
 		sendTraceAlert(ctx, *discordGuildId, alertInput, destinations)
-	case modelInputs.ProductTypeMetrics:
+	case modelInputs.ProductTypeMetrics, modelInputs.ProductTypeWebVitals:
 		sendMetricAlert(ctx, *discordGuildId, alertInput, destinations)
 	case modelInputs.ProductTypeEvents:
 		sendEventAlert(ctx, *discordGuildId, alertInput, destinations)
@@ -143,7 +143,7 @@ func sendErrorAlert(ctx context.Context, discordGuildId string, alertInput *dest
 	embed.Color = RED_ALERT
 
 	// HEADER
-	embed.Title = fmt.Sprintf("**Error Alert: %d Recent Occurrences**", int(alertInput.AlertValue))
+	embed.Title = fmt.Sprintf("**%s**", alertInput.ErrorAlertTitle())
 
 	// BODY
 	// location
@@ -182,7 +182,7 @@ func sendErrorAlert(ctx context.Context, discordGuildId string, alertInput *dest
 	actionButtons := discordgo.ActionsRow{Components: []discordgo.MessageComponent{}}
 	caser := cases.Title(language.AmericanEnglish)
 	for _, action := range modelInputs.AllErrorState {
-		if alertInput.ErrorInput.State == action {
+		if alertInput.ErrorInput.State == action || action == modelInputs.ErrorStateRegressed || action == modelInputs.ErrorStateMerged {
 			continue
 		}
 
@@ -490,6 +490,8 @@ func SendNotifications(ctx context.Context, discordGuildId *string, notification
 		sendAlertCreatedNotification(ctx, *discordGuildId, notificationInput, destinations)
 	case destinationsV2.NotificationTypeAlertUpdated:
 		sendAlertUpdatedNotification(ctx, *discordGuildId, notificationInput, destinations)
+	case destinationsV2.NotificationTypeErrorGroupAssigned, destinationsV2.NotificationTypeErrorGroupRegressed:
+		sendErrorGroupAssignmentNotification(ctx, *discordGuildId, notificationInput, destinations)
 	default:
 		log.WithContext(ctx).WithFields(
 			log.Fields{
@@ -543,6 +545,27 @@ func sendAlertUpdatedNotification(ctx context.Context, discordGuildId string, no
 	deliverAlerts(ctx, discordGuildId, &messageSend, destinations)
 }
 
+func sendErrorGroupAssignmentNotification(ctx context.Context, discordGuildId string, notificationInput destinationsV2.NotificationInput, destinations []model.AlertDestination) {
+	input := notificationInput.ErrorGroupAssignmentInput
+
+	embed := newMessageEmbed()
+	embed.Color = RED_ALERT
+	embed.Title = notificationInput.ErrorGroupAssignmentTitle()
+	embed.Description = fmt.Sprintf("%s\n[View error](%s)", notificationInput.ErrorGroupAssignmentDescription(), input.ErrorLink)
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{
+			Name:  "Error",
+			Value: fmt.Sprintf("```%s```", input.ErrorGroup.Event),
+		},
+	}
+
+	messageSend := discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{embed},
+	}
+
+	deliverAlerts(ctx, discordGuildId, &messageSend, destinations)
+}
+
 func deliverAlerts(ctx context.Context, discordGuildId string, messageSend *discordgo.MessageSend, destinations []model.AlertDestination) {
 	bot, err := discord.NewDiscordBot(discordGuildId)
 	if err != nil {
@@ -39,7 +39,7 @@ func SendAlerts(ctx context.Context, mailClient *sendgrid.Client, lambdaClient *
 		sendLogAlert(ctx, mailClient, lambdaClient, alertInput, destinations)
 	case modelInputs.ProductTypeTraces:
 		sendTraceAlert(ctx, mailClient, lambdaClient, alertInput, destinations)
-	case modelInputs.ProductTypeMetrics:
+	case modelInputs.ProductTypeMetrics, modelInputs.ProductTypeWebVitals:
 		sendMetricAlert(ctx, mailClient, lambdaClient, alertInput, destinations)
 	case modelInputs.ProductTypeEvents:
 		sendEventAlert(ctx, mailClient, lambdaClient, alertInput, destinations)
@@ -80,14 +80,17 @@ func sendErrorAlert(ctx context.Context, mailClient *sendgrid.Client, lambdaClie
 		SubjectLine: fmt.Sprintf("%s Alert", alertInput.Alert.Name),
 		Template:    lambda.ReactEmailTemplateErrorsAlert,
 		TemplateData: map[string]interface{}{
-			"alertLink":       alertInput.AlertLink,
-			"errorCount":      int(alertInput.AlertValue),
-			"errorEvent":      alertInput.ErrorInput.Event,
-			"errorLink":       alertInput.ErrorInput.ErrorLink,
-			"projectName":     alertInput.ProjectName,
-			"serviceName":     alertInput.ErrorInput.ServiceName,
-			"sessionExcluded": alertInput.ErrorInput.SessionExcluded,
-			"sessionLink":     alertInput.ErrorInput.SessionLink,
+			"alertLink":          alertInput.AlertLink,
+			"errorCount":         int(alertInput.AlertValue),
+			"errorEvent":         alertInput.ErrorInput.Event,
+			"errorLink":          alertInput.ErrorInput.ErrorLink,
+			"projectName":        alertInput.ProjectName,
+			"serviceName":        alertInput.ErrorInput.ServiceName,
+			"sessionExcluded":    alertInput.ErrorInput.SessionExcluded,
+			"sessionLink":        alertInput.ErrorInput.SessionLink,
+			"regression":         alertInput.IsRegression(),
+			"resolvedInVersion":  alertInput.ErrorInput.ResolvedInVersion,
+			"regressedInVersion": alertInput.ErrorInput.RegressedInVersion,
 		},
 	}
 
@@ -255,6 +258,8 @@ func SendNotifications(ctx context.Context, mailClient *sendgrid.Client, lambdaC
 		sendAlertCreatedNotification(ctx, mailClient, lambdaClient, notificationInput, destinations)
 	case destinationsV2.NotificationTypeAlertUpdated:
 		sendAlertUpdatedNotification(ctx, mailClient, lambdaClient, notificationInput, destinations)
+	case destinationsV2.NotificationTypeErrorGroupAssigned, destinationsV2.NotificationTypeErrorGroupRegressed:
+		sendErrorGroupAssignmentNotification(ctx, mailClient, lambdaClient, notificationInput, destinations)
 	default:
 		log.WithContext(ctx).WithFields(
 			log.Fields{
@@ -316,6 +321,23 @@ func sendAlertUpdatedNotification(ctx context.Context, mailClient *sendgrid.Clie
 	deliverAlerts(ctx, mailClient, lambdaClient, emailData, lo.Filter(destinations, shouldKeepDestination))
 }
 
+func sendErrorGroupAssignmentNotification(ctx context.Context, mailClient *sendgrid.Client, lambdaClient *lambda.Client, notificationInput destinationsV2.NotificationInput, destinations []model.AlertDestination) {
+	input := notificationInput.ErrorGroupAssignmentInput
+
+	emailData := &EmailData{
+		SubjectLine: notificationInput.ErrorGroupAssignmentTitle(),
+		Template:    lambda.ReactEmailTemplateErrorGroupAssignment,
+		TemplateData: map[string]interface{}{
+			"title":       notificationInput.ErrorGroupAssignmentTitle(),
+			"description": notificationInput.ErrorGroupAssignmentDescription(),
+			"errorLink":   input.ErrorLink,
+			"event":       input.ErrorGroup.Event,
+		},
+	}
+
+	deliverAlerts(ctx, mailClient, lambdaClient, emailData, destinations)
+}
+
 func deliverAlerts(ctx context.Context, mailClient *sendgrid.Client, lambdaClient *lambda.Client, emailTemplate *EmailData, destinations []model.AlertDestination) {
 	if mailClient == nil {
 		log.WithContext(ctx).Error("mail client is nil")
@@ -38,7 +38,7 @@ func SendAlerts(ctx context.Context, microsoftTeamsTenantId *string, alertInput
 		sendLogAlert(ctx, *microsoftTeamsTenantId, alertInput, destinations)
 	case modelInputs.ProductTypeTraces:
 		sendTraceAlert(ctx, *microsoftTeamsTenantId, alertInput, destinations)
-	case modelInputs.ProductTypeMetrics:
+	case modelInputs.ProductTypeMetrics, modelInputs.ProductTypeWebVitals:
 		sendMetricAlert(ctx, *microsoftTeamsTenantId, alertInput, destinations)
 	case modelInputs.ProductTypeEvents:
 		sendEventAlert(ctx, *microsoftTeamsTenantId, alertInput, destinations)
@@ -107,6 +107,7 @@ func sendErrorAlert(ctx context.Context, microsoftTeamsTenantId string, alertInp
 	}
 
 	messagePayload := microsoftteamsV2_templates.ErrorAlertPayload{
+		Title:           alertInput.ErrorAlertTitle(),
 		ErrorCount:      int(alertInput.AlertValue),
 		Location:        locationName,
 		ErrorLink:       alertInput.ErrorInput.ErrorLink,
@@ -335,6 +336,8 @@ func SendNotifications(ctx context.Context, microsoftTeamsTenantId *string, noti
 		sendAlertCreatedNotification(ctx, *microsoftTeamsTenantId, notificationInput, destinations)
 	case destinationsV2.NotificationTypeAlertUpdated:
 		sendAlertUpdatedNotification(ctx, *microsoftTeamsTenantId, notificationInput, destinations)
+	case destinationsV2.NotificationTypeErrorGroupAssigned, destinationsV2.NotificationTypeErrorGroupRegressed:
+		sendErrorGroupAssignmentNotification(ctx, *microsoftTeamsTenantId, notificationInput, destinations)
 	default:
 		log.WithContext(ctx).WithFields(
 			log.Fields{
@@ -382,6 +385,24 @@ func sendAlertUpdatedNotification(ctx context.Context, microsoftTeamsTenantId st
 	deliverAlerts(ctx, microsoftTeamsTenantId, microsoftteamsV2_templates.AlertUpsertMessageTemplate, messagePayload, destinations)
 }
 
+func sendErrorGroupAssignmentNotification(ctx context.Context, microsoftTeamsTenantId string, notificationInput destinationsV2.NotificationInput, destinations []model.AlertDestination) {
+	input := notificationInput.ErrorGroupAssignmentInput
+
+	errorEvent := input.ErrorGroup.Event
+	if len(errorEvent) > 250 {
+		errorEvent = errorEvent[:250] + "..."
+	}
+
+	messagePayload := microsoftteamsV2_templates.ErrorGroupAssignmentPayload{
+		Title:       notificationInput.ErrorGroupAssignmentTitle(),
+		Description: notificationInput.ErrorGroupAssignmentDescription(),
+		ErrorLink:   input.ErrorLink,
+		Event:       errorEvent,
+	}
+
+	deliverAlerts(ctx, microsoftTeamsTenantId, microsoftteamsV2_templates.ErrorGroupAssignmentMessageTemplate, messagePayload, destinations)
+}
+
 func deliverAlerts(ctx context.Context, microsoftTeamsTenantId string, messageTemplate []byte, messagePayload interface{}, destinations []model.AlertDestination) {
 	bot, err := microsoft_teams.NewMicrosoftTeamsBot(microsoftTeamsTenantId)
 	if err != nil {
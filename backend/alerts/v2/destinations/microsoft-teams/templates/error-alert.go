@@ -1,6 +1,7 @@
 package microsoftteamsV2_templates
 
 type ErrorAlertPayload struct {
+	Title           string
 	ErrorCount      int
 	Location        string
 	ErrorLink       string
@@ -20,7 +21,7 @@ var ErrorAlertMessageTemplate = []byte(`{
 		"type": "TextBlock",
 		"size": "Large",
 		"weight": "Bolder",
-		"text": "Error Alert: {{.ErrorCount}} Recent Occurances"
+		"text": "{{.Title}}"
 		},
 		{
 		"type": "TextBlock",
@@ -0,0 +1,40 @@
+package microsoftteamsV2_templates
+
+type ErrorGroupAssignmentPayload struct {
+	Title       string
+	Description string
+	ErrorLink   string
+	Event       string
+}
+
+var ErrorGroupAssignmentMessageTemplate = []byte(`{
+	"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+	"type": "AdaptiveCard",
+	"version": "1.6",
+	"body": [
+		{
+			"type":   "TextBlock",
+			"size":   "Large",
+			"weight": "Bolder",
+			"text":   "{{.Title}}"
+		},
+		{
+			"type": "TextBlock",
+			"text": "{{.Description}}",
+			"wrap": true
+		},
+		{
+			"type":     "TextBlock",
+			"fontType": "Monospace",
+			"text":     "{{.Event}}",
+			"wrap":     true
+		}
+	],
+	"actions": [
+		{
+			"type":  "Action.OpenUrl",
+			"title": "View Error",
+			"url":   "{{.ErrorLink}}"
+		}
+	]
+  }`)
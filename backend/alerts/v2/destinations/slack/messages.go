@@ -50,7 +50,7 @@ func SendAlerts(ctx context.Context, slackAccessToken *string, alertInput *desti
 		sendLogAlert(ctx, *slackAccessToken, alertInput, destinations)
 	case modelInputs.ProductTypeTraces:
 		sendTraceAlert(ctx, *slackAccessToken, alertInput, destinations)
-	case modelInputs.ProductTypeMetrics:
+	case modelInputs.ProductTypeMetrics, modelInputs.ProductTypeWebVitals:
 		sendMetricAlert(ctx, *slackAccessToken, alertInput, destinations)
 	case modelInputs.ProductTypeEvents:
 		sendEventAlert(ctx, *slackAccessToken, alertInput, destinations)
@@ -144,7 +144,7 @@ func sendErrorAlert(ctx context.Context, slackAccessToken string, alertInput *de
 	var headerBlockSet []slack.Block
 
 	previewText := fmt.Sprintf("Error Alert: %s", alertInput.ErrorInput.Event)
-	headerBlock := slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*Error Alert: %d Recent Occurrences*", int(alertInput.AlertValue)), false, false)
+	headerBlock := slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*%s*", alertInput.ErrorAlertTitle()), false, false)
 	headerBlockSet = append(headerBlockSet, slack.NewSectionBlock(headerBlock, nil, nil))
 
 	// BODY
@@ -192,7 +192,7 @@ func sendErrorAlert(ctx context.Context, slackAccessToken string, alertInput *de
 	var actionBlocks []slack.BlockElement
 	caser := cases.Title(language.AmericanEnglish)
 	for _, action := range modelInputs.AllErrorState {
-		if alertInput.ErrorInput.State == action {
+		if alertInput.ErrorInput.State == action || action == modelInputs.ErrorStateRegressed || action == modelInputs.ErrorStateMerged {
 			continue
 		}
 
@@ -589,6 +589,8 @@ func SendNotifications(ctx context.Context, slackAccessToken *string, notificati
 		sendAlertCreatedNotification(ctx, *slackAccessToken, notificationInput, destinations)
 	case destinationsV2.NotificationTypeAlertUpdated:
 		sendAlertUpdatedNotification(ctx, *slackAccessToken, notificationInput, destinations)
+	case destinationsV2.NotificationTypeErrorGroupAssigned, destinationsV2.NotificationTypeErrorGroupRegressed:
+		sendErrorGroupAssignmentNotification(ctx, *slackAccessToken, notificationInput, destinations)
 	default:
 		log.WithContext(ctx).WithFields(
 			log.Fields{
@@ -628,6 +630,29 @@ func sendAlertUpdatedNotification(ctx context.Context, slackAccessToken string,
 	deliverAlerts(ctx, slackAccessToken, destinations, message, nil, nil)
 }
 
+func sendErrorGroupAssignmentNotification(ctx context.Context, slackAccessToken string, notificationInput destinationsV2.NotificationInput, destinations []model.AlertDestination) {
+	input := notificationInput.ErrorGroupAssignmentInput
+
+	errorEvent := input.ErrorGroup.Event
+	if len(errorEvent) > 250 {
+		errorEvent = errorEvent[:250] + "..."
+	}
+
+	headerBlock := slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*%s*", notificationInput.ErrorGroupAssignmentTitle()), false, false)
+	bodyBlock := slack.NewTextBlockObject(
+		slack.MarkdownType,
+		fmt.Sprintf("%s\n*<%s|View error>*\n```%s```", notificationInput.ErrorGroupAssignmentDescription(), input.ErrorLink, errorEvent),
+		false,
+		false,
+	)
+	blockSet := []slack.Block{
+		slack.NewSectionBlock(headerBlock, nil, nil),
+		slack.NewSectionBlock(bodyBlock, nil, nil),
+	}
+
+	deliverAlerts(ctx, slackAccessToken, destinations, notificationInput.ErrorGroupAssignmentTitle(), blockSet, nil)
+}
+
 func deliverAlerts(ctx context.Context, slackAccessToken string, destinations []model.AlertDestination, previewText string, headerBlockSet []slack.Block, attachment *slack.Attachment) {
 	slackClient := slack.New(slackAccessToken)
 	if slackClient == nil {
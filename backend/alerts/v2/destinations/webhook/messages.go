@@ -15,6 +15,7 @@ import (
 	"github.com/highlight-run/highlight/backend/routing"
 	"github.com/highlight-run/highlight/backend/util"
 	"github.com/pkg/errors"
+	"github.com/samber/lo"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -34,7 +35,7 @@ func SendAlerts(ctx context.Context, alertInput *destinationsV2.AlertInput, dest
 		sendLogAlert(ctx, alertInput, destinations)
 	case modelInputs.ProductTypeTraces:
 		sendTraceAlert(ctx, alertInput, destinations)
-	case modelInputs.ProductTypeMetrics:
+	case modelInputs.ProductTypeMetrics, modelInputs.ProductTypeWebVitals:
 		sendMetricAlert(ctx, alertInput, destinations)
 	case modelInputs.ProductTypeEvents:
 		sendEventAlert(ctx, alertInput, destinations)
@@ -88,6 +89,10 @@ type ErrorAlertPayload struct {
 	ErrorResolveURL string
 	ErrorIgnoreURL  string
 	ErrorSnoozeURL  string
+	CodeOwner       string `json:",omitempty"`
+	// set for regression alerts
+	ResolvedInVersion  string `json:",omitempty"`
+	RegressedInVersion string `json:",omitempty"`
 }
 
 func sendErrorAlert(ctx context.Context, alertInput *destinationsV2.AlertInput, destinations []model.AlertDestination) {
@@ -96,8 +101,13 @@ func sendErrorAlert(ctx context.Context, alertInput *destinationsV2.AlertInput,
 		query = *alertInput.Alert.Query
 	}
 
+	event := model.AlertType.ERRORS
+	if alertInput.IsRegression() {
+		event = model.AlertType.ERROR_REGRESSION
+	}
+
 	messagePayload := ErrorAlertPayload{
-		Event:           model.AlertType.ERRORS,
+		Event:           event,
 		AlertName:       alertInput.Alert.Name,
 		Query:           query,
 		ErrorCount:      int64(alertInput.AlertValue),
@@ -110,6 +120,10 @@ func sendErrorAlert(ctx context.Context, alertInput *destinationsV2.AlertInput,
 		ErrorResolveURL: routing.AttachQueryParam(ctx, alertInput.ErrorInput.ErrorLink, "action", "resolved"),
 		ErrorIgnoreURL:  routing.AttachQueryParam(ctx, alertInput.ErrorInput.ErrorLink, "action", "ignored"),
 		ErrorSnoozeURL:  routing.AttachQueryParam(ctx, alertInput.ErrorInput.ErrorLink, "action", "snooze"),
+		CodeOwner:       alertInput.ErrorInput.CodeOwner,
+
+		ResolvedInVersion:  alertInput.ErrorInput.ResolvedInVersion,
+		RegressedInVersion: alertInput.ErrorInput.RegressedInVersion,
 	}
 
 	sendAlerts(ctx, messagePayload, destinations)
@@ -285,6 +299,8 @@ func SendNotifications(ctx context.Context, notificationInput destinationsV2.Not
 		sendAlertCreatedNotification(ctx, notificationInput, destinations)
 	case destinationsV2.NotificationTypeAlertUpdated:
 		sendAlertUpdatedNotification(ctx, notificationInput, destinations)
+	case destinationsV2.NotificationTypeErrorGroupAssigned, destinationsV2.NotificationTypeErrorGroupRegressed:
+		sendErrorGroupAssignmentNotification(ctx, notificationInput, destinations)
 	default:
 		log.WithContext(ctx).WithFields(
 			log.Fields{
@@ -346,6 +362,54 @@ func sendAlertUpdatedNotification(ctx context.Context, notificationInput destina
 	sendAlerts(ctx, messagePayload, destinations)
 }
 
+type ErrorGroupAssignmentPayload struct {
+	Event              string
+	ErrorGroupSecureID string
+	ErrorUrl           string
+	ErrorEvent         string
+	ProjectName        string
+	AssigneeName       string
+	AssigneeAdminID    *int
+	AssigneeTeam       *string
+	AdminName          string
+	RuleName           string
+	RegressedInVersion string
+}
+
+func sendErrorGroupAssignmentNotification(ctx context.Context, notificationInput destinationsV2.NotificationInput, destinations []model.AlertDestination) {
+	input := notificationInput.ErrorGroupAssignmentInput
+
+	event := "ERROR_GROUP_ASSIGNED"
+	if notificationInput.NotificationType == destinationsV2.NotificationTypeErrorGroupRegressed {
+		event = "ERROR_GROUP_REGRESSED"
+	}
+
+	var adminName string
+	if input.Admin != nil {
+		name := input.Admin.Name
+		if name == nil {
+			name = input.Admin.Email
+		}
+		adminName = lo.FromPtr(name)
+	}
+
+	messagePayload := ErrorGroupAssignmentPayload{
+		Event:              event,
+		ErrorGroupSecureID: input.ErrorGroup.SecureID,
+		ErrorUrl:           input.ErrorLink,
+		ErrorEvent:         input.ErrorGroup.Event,
+		ProjectName:        input.ProjectName,
+		AssigneeName:       input.AssigneeName,
+		AssigneeAdminID:    input.ErrorGroup.AssigneeAdminID,
+		AssigneeTeam:       input.ErrorGroup.AssigneeTeam,
+		AdminName:          adminName,
+		RuleName:           input.RuleName,
+		RegressedInVersion: lo.FromPtr(input.ErrorGroup.RegressedInVersion),
+	}
+
+	sendAlerts(ctx, messagePayload, destinations)
+}
+
 func sendAlerts(ctx context.Context, messagePayload interface{}, destinations []model.AlertDestination) {
 	payloadJson, err := json.Marshal(messagePayload)
 	if err != nil {
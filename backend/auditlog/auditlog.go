@@ -0,0 +1,173 @@
+package auditlog
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// Target types of audit log events.
+const (
+	TargetAlert               = "alert"
+	TargetErrorAlert          = "error_alert"
+	TargetSessionAlert        = "session_alert"
+	TargetLogAlert            = "log_alert"
+	TargetMetricMonitor       = "metric_monitor"
+	TargetProject             = "project"
+	TargetProjectSettings     = "project_settings"
+	TargetWorkspace           = "workspace"
+	TargetWorkspaceSettings   = "workspace_settings"
+	TargetWorkspaceInviteLink = "workspace_invite_link"
+	TargetAdmin               = "admin"
+	TargetSessions            = "sessions"
+	TargetSCIMToken           = "scim_token"
+	TargetSCIMGroup           = "scim_group"
+	TargetAPIToken            = "api_token"
+	TargetRedactionRule       = "redaction_rule"
+	TargetErrorGroup          = "error_group"
+	TargetErrorGroupingRule   = "error_grouping_rule"
+	TargetErrorAssignmentRule = "error_group_assignment_rule"
+)
+
+// Verbs of audit log events. Actions are recorded as <target>.<verb>, e.g. alert.delete
+const (
+	VerbCreate  = "create"
+	VerbUpdate  = "update"
+	VerbDelete  = "delete"
+	VerbInvite  = "invite"
+	VerbJoin    = "join"
+	VerbRemove  = "remove"
+	VerbEnable  = "enable"
+	VerbDisable = "disable"
+	VerbMerge   = "merge"
+	VerbSplit   = "split"
+)
+
+func Action(target string, verb string) string {
+	return target + "." + verb
+}
+
+const redacted = "[REDACTED]"
+
+// ignoredFields change on every write and are not recorded in diffs.
+var ignoredFields = []string{"id", "created_at", "updated_at", "deleted_at"}
+
+// sensitiveFields are recorded as changed without their values.
+var sensitiveFields = []string{"secret", "token", "password", "api_key", "apikey"}
+
+func normalizeField(field string) string {
+	return strings.ToLower(strings.ReplaceAll(field, "_", ""))
+}
+
+func isIgnoredField(field string) bool {
+	return lo.ContainsBy(ignoredFields, func(ignored string) bool {
+		return normalizeField(ignored) == normalizeField(field)
+	})
+}
+
+func isSensitiveField(field string) bool {
+	return lo.ContainsBy(sensitiveFields, func(sensitive string) bool {
+		return strings.Contains(normalizeField(field), normalizeField(sensitive))
+	})
+}
+
+// toFields returns the JSON fields of the value. Values that are not objects are returned as a "value" field.
+func toFields(value any) (map[string]any, error) {
+	if value == nil {
+		return map[string]any{}, nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "error marshaling audit log value")
+	}
+	var parsed any
+	if err := json.Unmarshal(b, &parsed); err != nil {
+		return nil, errors.Wrap(err, "error unmarshaling audit log value")
+	}
+	switch v := parsed.(type) {
+	case nil:
+		return map[string]any{}, nil
+	case map[string]any:
+		return v, nil
+	default:
+		return map[string]any{"value": v}, nil
+	}
+}
+
+// Diff returns the top-level fields that differ between the JSON representations of before and after,
+// as {"field": {"before": ..., "after": ...}}. Either value may be nil for creations and deletions.
+func Diff(before any, after any) (model.JSONB, error) {
+	beforeFields, err := toFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := toFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := model.JSONB{}
+	for _, field := range lo.Union(lo.Keys(beforeFields), lo.Keys(afterFields)) {
+		if isIgnoredField(field) {
+			continue
+		}
+		beforeValue, afterValue := beforeFields[field], afterFields[field]
+		if reflect.DeepEqual(beforeValue, afterValue) {
+			continue
+		}
+		if isSensitiveField(field) {
+			beforeValue = lo.Ternary[any](beforeValue == nil, nil, redacted)
+			afterValue = lo.Ternary[any](afterValue == nil, nil, redacted)
+		}
+		diff[field] = map[string]any{"before": beforeValue, "after": afterValue}
+	}
+	return diff, nil
+}
+
+var csvHeader = []string{"created_at", "workspace_id", "project_id", "admin_id", "admin_email", "action", "target_type", "target_id", "ip", "user_agent", "diff"}
+
+// WriteCSV writes the audit logs as CSV with a header row.
+func WriteCSV(w io.Writer, logs []*model.AuditLog) error {
+	formatID := func(id *int) string {
+		if id == nil {
+			return ""
+		}
+		return strconv.Itoa(*id)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, auditLog := range logs {
+		diff, err := json.Marshal(auditLog.Diff)
+		if err != nil {
+			return errors.Wrap(err, "error marshaling audit log diff")
+		}
+		if err := writer.Write([]string{
+			auditLog.CreatedAt.UTC().Format(time.RFC3339),
+			strconv.Itoa(auditLog.WorkspaceID),
+			formatID(auditLog.ProjectID),
+			formatID(auditLog.AdminID),
+			auditLog.AdminEmail,
+			auditLog.Action,
+			auditLog.TargetType,
+			auditLog.TargetID,
+			auditLog.IP,
+			auditLog.UserAgent,
+			string(diff),
+		}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
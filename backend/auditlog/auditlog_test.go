@@ -0,0 +1,71 @@
+package auditlog
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	before := &model.Project{Model: model.Model{ID: 1, UpdatedAt: time.Now()}, Name: lo.ToPtr("web"), ExcludedUsers: []string{"a"}, Secret: lo.ToPtr("abc")}
+	after := &model.Project{Model: model.Model{ID: 1, UpdatedAt: time.Now().Add(time.Minute)}, Name: lo.ToPtr("frontend"), ExcludedUsers: []string{"a"}, Secret: lo.ToPtr("abc")}
+
+	diff, err := Diff(before, after)
+	require.NoError(t, err)
+	assert.Equal(t, model.JSONB{"Name": map[string]any{"before": "web", "after": "frontend"}}, diff)
+
+	// creations and deletions record every field
+	diff, err = Diff(nil, map[string]any{"name": "alert", "threshold": 10})
+	require.NoError(t, err)
+	assert.Equal(t, model.JSONB{
+		"name":      map[string]any{"before": nil, "after": "alert"},
+		"threshold": map[string]any{"before": nil, "after": float64(10)},
+	}, diff)
+
+	diff, err = Diff(map[string]any{"id": 2, "role": "ADMIN"}, (*model.WorkspaceAdmin)(nil))
+	require.NoError(t, err)
+	assert.Equal(t, model.JSONB{"role": map[string]any{"before": "ADMIN", "after": nil}}, diff)
+
+	// sensitive values are redacted
+	diff, err = Diff(map[string]any{"client_secret": "a"}, map[string]any{"client_secret": "b"})
+	require.NoError(t, err)
+	assert.Equal(t, model.JSONB{"client_secret": map[string]any{"before": redacted, "after": redacted}}, diff)
+
+	// values that are not objects are diffed as a single field
+	diff, err = Diff(2, 3)
+	require.NoError(t, err)
+	assert.Equal(t, model.JSONB{"value": map[string]any{"before": float64(2), "after": float64(3)}}, diff)
+}
+
+func TestWriteCSV(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, []*model.AuditLog{{
+		CreatedAt:   createdAt,
+		WorkspaceID: 1,
+		ProjectID:   lo.ToPtr(2),
+		AdminID:     lo.ToPtr(3),
+		AdminEmail:  "jay@example.com",
+		Action:      Action(TargetAlert, VerbDelete),
+		TargetType:  TargetAlert,
+		TargetID:    "4",
+		IP:          "127.0.0.1",
+		UserAgent:   "Mozilla/5.0, (Macintosh)",
+		Diff:        model.JSONB{"name": map[string]any{"before": "errors", "after": nil}},
+	}}))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, csvHeader, records[0])
+	assert.Equal(t, []string{
+		"2024-01-02T03:04:05Z", "1", "2", "3", "jay@example.com", "alert.delete", "alert", "4", "127.0.0.1",
+		"Mozilla/5.0, (Macintosh)", `{"name":{"after":null,"before":"errors"}}`,
+	}, records[1])
+}
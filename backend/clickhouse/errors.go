@@ -32,6 +32,9 @@ type ClickhouseErrorGroup struct {
 	ErrorTagID          int64
 	ErrorTagTitle       string
 	ErrorTagDescription string
+	CodeOwner           string
+	AssigneeID          int64
+	AssigneeTeam        string
 }
 
 type ClickhouseErrorObject struct {
@@ -78,6 +81,15 @@ func (client *Client) WriteErrorGroups(ctx context.Context, groups []*model.Erro
 		} else {
 			chEg.SnoozedUntil = -1
 		}
+		if group.CodeOwner != nil {
+			chEg.CodeOwner = *group.CodeOwner
+		}
+		if group.AssigneeAdminID != nil {
+			chEg.AssigneeID = int64(*group.AssigneeAdminID)
+		}
+		if group.AssigneeTeam != nil {
+			chEg.AssigneeTeam = *group.AssigneeTeam
+		}
 		if group.ErrorTag != nil {
 			chEg.ErrorTagID = int64(group.ErrorTag.ID)
 			chEg.ErrorTagTitle = group.ErrorTag.Title
@@ -593,6 +605,9 @@ var reservedErrorGroupKeys = lo.Map(modelInputs.AllReservedErrorGroupKey, func(k
 var ErrorGroupsTableConfig = model.TableConfig{
 	TableName: ErrorGroupsTable,
 	KeysToColumns: map[string]string{
+		string(modelInputs.ReservedErrorGroupKeyAssigneeID):   "AssigneeID",
+		string(modelInputs.ReservedErrorGroupKeyAssigneeTeam): "AssigneeTeam",
+		string(modelInputs.ReservedErrorGroupKeyCodeOwner):    "CodeOwner",
 		string(modelInputs.ReservedErrorGroupKeyEvent):        "Event",
 		string(modelInputs.ReservedErrorGroupKeySecureID):     "SecureID",
 		string(modelInputs.ReservedErrorGroupKeySnoozedUntil): "SnoozedUntil",
@@ -636,6 +651,9 @@ var ErrorsJoinedTableConfig = model.TableConfig{
 		string(modelInputs.ReservedErrorsJoinedKeyID):              "ID",
 		string(modelInputs.ReservedErrorsJoinedKeyBrowser):         "Browser",
 		string(modelInputs.ReservedErrorsJoinedKeyClientID):        "ClientID",
+		string(modelInputs.ReservedErrorsJoinedKeyAssigneeID):      "AssigneeID",
+		string(modelInputs.ReservedErrorsJoinedKeyAssigneeTeam):    "AssigneeTeam",
+		string(modelInputs.ReservedErrorsJoinedKeyCodeOwner):       "CodeOwner",
 		string(modelInputs.ReservedErrorsJoinedKeyEnvironment):     "Environment",
 		string(modelInputs.ReservedErrorsJoinedKeyEvent):           "Event",
 		string(modelInputs.ReservedErrorsJoinedKeyHasSession):      "HasSession",
@@ -859,6 +877,8 @@ func readErrorGroups(params modelInputs.QueryInput, projectId int) (*sqlbuilder.
 	parser.AssignSearchFilters(sbInner, params.Query, ErrorsJoinedTableConfig)
 
 	sb.JoinWithOption(sqlbuilder.InnerJoin, sb.BuilderAs(sbInner, "join"), "ID = ErrorGroupID")
+	// merged error groups are found through the group they were merged into
+	sb.Where(sb.NotEqual("Status", modelInputs.ErrorStateMerged.String()))
 
 	return sb, nil
 }
@@ -0,0 +1,171 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/highlight-run/highlight/backend/model"
+	modelInputs "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/huandu/go-sqlbuilder"
+	e "github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+const SessionClicksTable = "session_clicks"
+const SessionScrollDepthsTable = "session_scroll_depths"
+
+// Scroll depths are counted in buckets of this many pixels
+const ScrollDepthBucketPixels = 100
+
+// Heatmaps return at most this many click buckets, the most clicked first
+const maxHeatmapClicks = 10_000
+
+// SessionClick counts the clicks of a session on an element at a position of a page.
+type SessionClick struct {
+	ProjectID        int32
+	SessionID        int64
+	SessionCreatedAt time.Time
+	URL              string
+	Selector         string
+	// ViewportWidth is the breakpoint of the viewport width, so that clicks are compared across similar layouts
+	ViewportWidth int32
+	// X is the percentage of the viewport width
+	X int32
+	// Y is the offset from the top of the page in pixels
+	Y     int32
+	Count uint64
+}
+
+// SessionScrollDepth is the furthest a page view of a session was scrolled.
+type SessionScrollDepth struct {
+	ProjectID        int32
+	SessionID        int64
+	SessionCreatedAt time.Time
+	URL              string
+	PageViewStart    time.Time
+	ViewportWidth    int32
+	ViewportHeight   int32
+	// MaxScrollDepth is the offset from the top of the page of the bottom of the viewport in pixels
+	MaxScrollDepth int32
+}
+
+func (client *Client) WriteSessionHeatmaps(ctx context.Context, clicks []*SessionClick, scrollDepths []*SessionScrollDepth) error {
+	var g errgroup.Group
+
+	if len(clicks) > 0 {
+		g.Go(func() error {
+			batch, err := client.conn.PrepareBatch(ctx, fmt.Sprintf("INSERT INTO %s", SessionClicksTable))
+			if err != nil {
+				return e.Wrap(err, "failed to create session clicks batch")
+			}
+			for _, click := range clicks {
+				if err := batch.AppendStruct(click); err != nil {
+					return err
+				}
+			}
+			return batch.Send()
+		})
+	}
+
+	if len(scrollDepths) > 0 {
+		g.Go(func() error {
+			batch, err := client.conn.PrepareBatch(ctx, fmt.Sprintf("INSERT INTO %s", SessionScrollDepthsTable))
+			if err != nil {
+				return e.Wrap(err, "failed to create session scroll depths batch")
+			}
+			for _, scrollDepth := range scrollDepths {
+				if err := batch.AppendStruct(scrollDepth); err != nil {
+					return err
+				}
+			}
+			return batch.Send()
+		})
+	}
+
+	return g.Wait()
+}
+
+// readHeatmapTable selects the rows of the sessions matching the search query on the page.
+func readHeatmapTable(admin *model.Admin, table string, projectID int, url string, viewportWidth *int, params modelInputs.QueryInput, retentionDate time.Time) (*sqlbuilder.SelectBuilder, string, []interface{}, error) {
+	sessionsSql, sessionsArgs, _, err := GetSessionsQueryImpl(admin, params, projectID, retentionDate, "ID", nil, nil, nil, nil)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.From(table + " FINAL").
+		Where(sb.Equal("ProjectID", projectID)).
+		Where(sb.Equal("URL", url)).
+		Where(sb.Between("SessionCreatedAt", params.DateRange.StartDate, params.DateRange.EndDate))
+	if viewportWidth != nil {
+		sb.Where(sb.Equal("ViewportWidth", *viewportWidth))
+	}
+	// the sessions query may select a sampling hash column besides the id
+	return sb, fmt.Sprintf("SessionID IN (SELECT ID FROM (%s))", sessionsSql), sessionsArgs, nil
+}
+
+// QueryHeatmap returns the clicks and scroll depths of the page views of the url in the sessions matching the search query.
+func (client *Client) QueryHeatmap(ctx context.Context, admin *model.Admin, projectID int, url string, viewportWidth *int, params modelInputs.QueryInput, retentionDate time.Time) (*modelInputs.Heatmap, error) {
+	heatmap := &modelInputs.Heatmap{
+		URL:          url,
+		Clicks:       []*modelInputs.HeatmapClick{},
+		ScrollDepths: []*modelInputs.ScrollDepth{},
+	}
+
+	sb, sessionsFilter, sessionsArgs, err := readHeatmapTable(admin, SessionClicksTable, projectID, url, viewportWidth, params, retentionDate)
+	if err != nil {
+		return nil, err
+	}
+	sb.Select("Selector", "ViewportWidth", "X", "Y", "sum(Count) AS Clicks")
+	sql, args := sb.BuildWithFlavor(sqlbuilder.ClickHouse)
+	sql = fmt.Sprintf("%s AND %s GROUP BY Selector, ViewportWidth, X, Y ORDER BY Clicks DESC LIMIT %d", sql, sessionsFilter, maxHeatmapClicks)
+
+	rows, err := client.conn.Query(ctx, sql, append(args, sessionsArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var click modelInputs.HeatmapClick
+		var width, x, y int32
+		var count uint64
+		if err := rows.Scan(&click.Selector, &width, &x, &y, &count); err != nil {
+			return nil, err
+		}
+		click.ViewportWidth, click.X, click.Y, click.Count = int(width), int(x), int(y), int64(count)
+		heatmap.Clicks = append(heatmap.Clicks, &click)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sb, sessionsFilter, sessionsArgs, err = readHeatmapTable(admin, SessionScrollDepthsTable, projectID, url, viewportWidth, params, retentionDate)
+	if err != nil {
+		return nil, err
+	}
+	sb.Select(fmt.Sprintf("toInt32(intDiv(MaxScrollDepth, %d) * %d) AS Depth", ScrollDepthBucketPixels, ScrollDepthBucketPixels), "count() AS PageViews")
+	sql, args = sb.BuildWithFlavor(sqlbuilder.ClickHouse)
+	sql = fmt.Sprintf("%s AND %s GROUP BY Depth ORDER BY Depth DESC", sql, sessionsFilter)
+
+	rows, err = client.conn.Query(ctx, sql, append(args, sessionsArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	// page views that reached a depth also reached all shallower depths
+	var reached int64
+	for rows.Next() {
+		var depth int32
+		var pageViews uint64
+		if err := rows.Scan(&depth, &pageViews); err != nil {
+			return nil, err
+		}
+		reached += int64(pageViews)
+		heatmap.ScrollDepths = append([]*modelInputs.ScrollDepth{{Depth: int(depth), PageViews: reached}}, heatmap.ScrollDepths...)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	heatmap.PageViews = reached
+
+	return heatmap, nil
+}
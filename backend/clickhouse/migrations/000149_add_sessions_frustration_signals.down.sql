@@ -0,0 +1,10 @@
+ALTER TABLE sessions DROP COLUMN IF EXISTS HasDeadClicks,
+    DROP COLUMN IF EXISTS HasErrorClicks,
+    DROP COLUMN IF EXISTS HasThrashedCursor,
+    DROP COLUMN IF EXISTS HasUTurns;
+DROP VIEW IF EXISTS sessions_joined_vw;
+CREATE VIEW IF NOT EXISTS sessions_joined_vw AS
+select ProjectID as ProjectId,
+        CreatedAt as Timestamp,
+        *
+from sessions FINAL SETTINGS splitby_max_substrings_includes_remaining_string = 1;
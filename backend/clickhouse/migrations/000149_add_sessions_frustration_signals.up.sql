@@ -0,0 +1,11 @@
+ALTER TABLE sessions
+ADD COLUMN IF NOT EXISTS HasDeadClicks Bool,
+ADD COLUMN IF NOT EXISTS HasErrorClicks Bool,
+ADD COLUMN IF NOT EXISTS HasThrashedCursor Bool,
+ADD COLUMN IF NOT EXISTS HasUTurns Bool;
+DROP VIEW IF EXISTS sessions_joined_vw;
+CREATE VIEW IF NOT EXISTS sessions_joined_vw AS
+select ProjectID as ProjectId,
+        CreatedAt as Timestamp,
+        *
+from sessions FINAL SETTINGS splitby_max_substrings_includes_remaining_string = 1;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS session_clicks;
+DROP TABLE IF EXISTS session_scroll_depths;
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS session_clicks (
+    ProjectID Int32,
+    SessionID Int64,
+    SessionCreatedAt DateTime64(6),
+    URL String,
+    Selector String,
+    ViewportWidth Int32,
+    X Int32,
+    Y Int32,
+    Count UInt64
+) ENGINE = ReplacingMergeTree
+ORDER BY (
+        ProjectID,
+        URL,
+        SessionID,
+        Selector,
+        ViewportWidth,
+        X,
+        Y
+    );
+CREATE TABLE IF NOT EXISTS session_scroll_depths (
+    ProjectID Int32,
+    SessionID Int64,
+    SessionCreatedAt DateTime64(6),
+    URL String,
+    PageViewStart DateTime64(6),
+    ViewportWidth Int32,
+    ViewportHeight Int32,
+    MaxScrollDepth Int32
+) ENGINE = ReplacingMergeTree
+ORDER BY (ProjectID, URL, SessionID, PageViewStart);
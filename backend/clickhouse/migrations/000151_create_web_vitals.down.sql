@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS web_vitals_mv;
+DROP TABLE IF EXISTS web_vitals;
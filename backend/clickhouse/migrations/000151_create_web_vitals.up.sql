@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS web_vitals
+(
+    ProjectId       UInt32,
+    Timestamp       DateTime64(9) CODEC (Delta, ZSTD),
+    UUID            UUID DEFAULT generateUUIDv4(),
+    MetricName      LowCardinality(String),
+    Value           Float64,
+    URL             String,
+    DeviceClass     LowCardinality(String),
+    Browser         LowCardinality(String),
+    Release         String,
+    Environment     String,
+    SecureSessionId String,
+    Attributes      Map(LowCardinality(String), String),
+    RetentionDays   UInt8 DEFAULT 30
+) ENGINE = MergeTree()
+      PARTITION BY toStartOfDay(Timestamp)
+      ORDER BY (ProjectId, MetricName, toUnixTimestamp64Nano(Timestamp))
+      TTL toDateTime(Timestamp) + toIntervalDay(RetentionDays);
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS web_vitals_mv TO web_vitals AS
+SELECT ProjectId,
+       Timestamp,
+       MetricName,
+       Value,
+       Attributes['url_pattern']       as URL,
+       Attributes['device_class']      as DeviceClass,
+       Attributes['browser_name']      as Browser,
+       Attributes['release']           as Release,
+       Attributes['environment']       as Environment,
+       `Exemplars.SecureSessionID`[1] as SecureSessionId,
+       Attributes,
+       RetentionDays
+FROM metrics_sum
+WHERE Attributes['category'] = 'WebVital';
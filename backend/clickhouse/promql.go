@@ -0,0 +1,279 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/huandu/go-sqlbuilder"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/highlight-run/highlight/backend/promql"
+	"github.com/highlight-run/highlight/backend/util"
+)
+
+const promMaxSeries = 10_000
+
+// histogram rows are exposed as the classic prometheus `_bucket`, `_count` and `_sum` series
+var promHistogramSuffixes = []string{"_bucket", "_count", "_sum"}
+
+// PromQuerier implements the promql.Querier on top of the metrics tables.
+type PromQuerier struct {
+	client *Client
+}
+
+func NewPromQuerier(client *Client) *PromQuerier {
+	return &PromQuerier{client: client}
+}
+
+func promMatcherCondition(sb *sqlbuilder.SelectBuilder, m *promql.Matcher) string {
+	var column string
+	switch m.Name {
+	case promql.MetricNameLabel:
+		column = "Name"
+	case promql.JobLabel:
+		column = "Service"
+	default:
+		column = fmt.Sprintf("Labels[%s]", sb.Var(m.Name))
+	}
+	switch m.Type {
+	case promql.MatchNotEqual:
+		return sb.NotEqual(column, m.Value)
+	case promql.MatchRegexp:
+		return fmt.Sprintf("match(%s, %s)", column, sb.Var("^(?:"+m.Value+")$"))
+	case promql.MatchNotRegexp:
+		return fmt.Sprintf("NOT match(%s, %s)", column, sb.Var("^(?:"+m.Value+")$"))
+	default:
+		return sb.Equal(column, m.Value)
+	}
+}
+
+// promMetricName returns the metric name if it is matched exactly, so that it can be used
+// in the primary key of the inner queries.
+func promMetricName(matchers []*promql.Matcher) (string, bool) {
+	for _, m := range matchers {
+		if m.Name == promql.MetricNameLabel && m.Type == promql.MatchEqual {
+			return m.Value, true
+		}
+	}
+	return "", false
+}
+
+// promSourceQuery returns a query over the sum and histogram tables with a
+// (Name, Service, Labels, Ts, V, Delta) row per sample. The columns are renamed so that
+// the aliases do not shadow the table columns used for filtering.
+func promSourceQuery(projectID int, matchers []*promql.Matcher, start, end time.Time) sqlbuilder.Builder {
+	name, exact := promMetricName(matchers)
+
+	filter := func(sb *sqlbuilder.SelectBuilder, metricName string) {
+		sb.Where(sb.Equal("ProjectId", projectID)).
+			Where(sb.GreaterThan("Timestamp", start)).
+			Where(sb.LessEqualThan("Timestamp", end))
+		if exact {
+			sb.Where(sb.Equal("MetricName", metricName))
+		}
+	}
+	delta := fmt.Sprintf("AggregationTemporality = %d", pmetric.AggregationTemporalityDelta)
+
+	sumSb := sqlbuilder.NewSelectBuilder()
+	sumSb.Select("MetricName AS Name", "ServiceName AS Service", "Attributes AS Labels", "Timestamp AS Ts", "Value AS V", delta+" AS Delta").
+		From(MetricsSumTable)
+	filter(sumSb, name)
+	builders := []sqlbuilder.Builder{sumSb}
+
+	for _, suffix := range promHistogramSuffixes {
+		histogramName, ok := strings.CutSuffix(name, suffix)
+		if exact && !ok {
+			continue
+		}
+		sb := sqlbuilder.NewSelectBuilder()
+		switch suffix {
+		case "_bucket":
+			sb.Select(
+				fmt.Sprintf("concat(MetricName, %s) AS Name", sb.Var(suffix)),
+				"ServiceName AS Service",
+				fmt.Sprintf("mapUpdate(Attributes, map(%s, le)) AS Labels", sb.Var(promql.BucketLabel)),
+				"Timestamp AS Ts",
+				"toFloat64(BucketCount) AS V",
+				delta+" AS Delta",
+			).From(fmt.Sprintf("%s ARRAY JOIN arrayPushBack(arrayMap(b -> toString(b), ExplicitBounds), '+Inf') AS le, arrayCumSum(BucketCounts) AS BucketCount", MetricsHistogramTable))
+		case "_count":
+			sb.Select(fmt.Sprintf("concat(MetricName, %s) AS Name", sb.Var(suffix)), "ServiceName AS Service", "Attributes AS Labels", "Timestamp AS Ts", "toFloat64(Count) AS V", delta+" AS Delta").
+				From(MetricsHistogramTable)
+		case "_sum":
+			sb.Select(fmt.Sprintf("concat(MetricName, %s) AS Name", sb.Var(suffix)), "ServiceName AS Service", "Attributes AS Labels", "Timestamp AS Ts", "Sum AS V", delta+" AS Delta").
+				From(MetricsHistogramTable)
+		}
+		filter(sb, histogramName)
+		builders = append(builders, sb)
+	}
+
+	return sqlbuilder.UnionAll(builders...)
+}
+
+func (q *PromQuerier) SelectWindows(ctx context.Context, projectID int, params promql.SelectParams) ([]*promql.SeriesWindows, error) {
+	end := params.StepTime(params.Steps - 1)
+	startMs := params.Start.UnixMilli()
+	stepMs := params.Step.Milliseconds()
+	windowMs := params.Window.Milliseconds()
+
+	sb := sqlbuilder.NewSelectBuilder()
+	// each sample is joined to every step whose window (t - window, t] contains it
+	ts := "toUnixTimestamp64Milli(Ts)"
+	firstStep := fmt.Sprintf("toUInt32(greatest(0, ceil((%s - %d) / %d)))", ts, startMs, stepMs)
+	lastStep := fmt.Sprintf("toUInt32(greatest(0, least(%d, ceil((%s + %d - %d) / %d))))", params.Steps, ts, windowMs, startMs, stepMs)
+	source := promSourceQuery(projectID, params.Matchers, params.Start.Add(-params.Window), end)
+
+	sb.Select(
+		"Name", "Service", "Labels", "Step",
+		"argMin(V, Ts)", "argMax(V, Ts)", "sum(V)", "count()", "toBool(max(Delta))",
+	).
+		From(fmt.Sprintf("%s ARRAY JOIN range(%s, %s) AS Step", sb.BuilderAs(source, "source"), firstStep, lastStep))
+	for _, m := range params.Matchers {
+		sb.Where(promMatcherCondition(sb, m))
+	}
+	sb.GroupBy("Name", "Service", "Labels", "Step").
+		OrderBy("Name", "Service", "Labels", "Step")
+
+	sql, args := sb.BuildWithFlavor(sqlbuilder.ClickHouse)
+	span, _ := util.StartSpanFromContext(ctx, "clickhouse.promql.selectWindows")
+	span.SetAttribute("Query", sql)
+	span.SetAttribute("ProjectId", projectID)
+
+	rows, err := q.client.connReadonly.Query(ctx, sql, args...)
+	if err != nil {
+		span.Finish(err)
+		return nil, err
+	}
+
+	var result []*promql.SeriesWindows
+	var current *promql.SeriesWindows
+	var currentKey string
+	for rows.Next() {
+		var (
+			metricName, serviceName string
+			attributes              map[string]string
+			step                    uint32
+			w                       promql.Window
+			delta                   bool
+		)
+		if err := rows.Scan(&metricName, &serviceName, &attributes, &step, &w.First, &w.Last, &w.Sum, &w.Count, &delta); err != nil {
+			span.Finish(err)
+			return nil, err
+		}
+		w.Step = int(step)
+		w.Delta = delta
+
+		labels := promLabels(metricName, serviceName, attributes)
+		if key := labels.Key(); current == nil || key != currentKey {
+			if len(result) >= promMaxSeries {
+				rows.Close()
+				span.Finish()
+				return nil, fmt.Errorf("query matched more than %d series", promMaxSeries)
+			}
+			current = &promql.SeriesWindows{Labels: labels}
+			currentKey = key
+			result = append(result, current)
+		}
+		current.Windows = append(current.Windows, w)
+	}
+	rows.Close()
+
+	span.Finish(rows.Err())
+	return result, rows.Err()
+}
+
+func promLabels(metricName, serviceName string, attributes map[string]string) promql.Labels {
+	labels := make(promql.Labels, len(attributes)+2)
+	for k, v := range attributes {
+		if v != "" {
+			labels[k] = v
+		}
+	}
+	labels[promql.MetricNameLabel] = metricName
+	if serviceName != "" {
+		labels[promql.JobLabel] = serviceName
+	}
+	return labels
+}
+
+func (q *PromQuerier) LabelNames(ctx context.Context, projectID int, start, end time.Time) ([]string, error) {
+	keys, err := KeysAggregated(ctx, q.client, MetricKeysTable, projectID, start, end, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{promql.MetricNameLabel, promql.JobLabel}
+	for _, key := range keys {
+		// reserved keys such as `metric_name` are exposed as prometheus labels above
+		if _, ok := metricsKeysToColumns[key.Name]; !ok {
+			names = append(names, key.Name)
+		}
+	}
+	return names, nil
+}
+
+func (q *PromQuerier) LabelValues(ctx context.Context, projectID int, name string, start, end time.Time) ([]string, error) {
+	var column string
+	switch name {
+	case promql.MetricNameLabel:
+		column = "MetricName"
+	case promql.JobLabel:
+		column = "ServiceName"
+	default:
+		return KeyValuesAggregated(ctx, q.client, MetricKeyValuesTable, projectID, name, start, end, nil, nil, nil)
+	}
+
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.Select(fmt.Sprintf("DISTINCT %s", column)).
+		From(MetricsTable).
+		Where(sb.Equal("ProjectId", projectID)).
+		Where(sb.Between("Timestamp", start, end)).
+		Where(sb.NotEqual(column, "")).
+		Limit(promMaxSeries)
+	sql, args := sb.BuildWithFlavor(sqlbuilder.ClickHouse)
+
+	rows, err := q.client.connReadonly.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	rows.Close()
+	return values, rows.Err()
+}
+
+func (q *PromQuerier) Series(ctx context.Context, projectID int, matchers []*promql.Matcher, start, end time.Time) ([]promql.Labels, error) {
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.Select("DISTINCT Name", "Service", "Labels").
+		From(sb.BuilderAs(promSourceQuery(projectID, matchers, start, end), "source"))
+	for _, m := range matchers {
+		sb.Where(promMatcherCondition(sb, m))
+	}
+	sb.Limit(promMaxSeries)
+	sql, args := sb.BuildWithFlavor(sqlbuilder.ClickHouse)
+
+	rows, err := q.client.connReadonly.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	var result []promql.Labels
+	for rows.Next() {
+		var (
+			metricName, serviceName string
+			attributes              map[string]string
+		)
+		if err := rows.Scan(&metricName, &serviceName, &attributes); err != nil {
+			return nil, err
+		}
+		result = append(result, promLabels(metricName, serviceName, attributes))
+	}
+	rows.Close()
+	return result, rows.Err()
+}
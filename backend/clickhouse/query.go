@@ -522,12 +522,13 @@ func transformSql(
 }
 
 var resourceTables = map[string]bool{
-	"sessions": true,
-	"errors":   true,
-	"logs":     true,
-	"traces":   true,
-	"events":   true,
-	"metrics":  true,
+	"sessions":   true,
+	"errors":     true,
+	"logs":       true,
+	"traces":     true,
+	"events":     true,
+	"metrics":    true,
+	"web_vitals": true,
 }
 
 func GetTables(sql string) ([]string, error) {
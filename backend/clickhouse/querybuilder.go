@@ -136,17 +136,21 @@ const (
 )
 
 var customFieldTypes map[string]FieldType = map[string]FieldType{
-	"viewed":          boolean,
-	"viewed_by_me":    viewedByMe,
-	"has_session":     boolean,
-	"has_errors":      boolean,
-	"has_rage_clicks": boolean,
-	"processed":       boolean,
-	"first_time":      boolean,
-	"has_comments":    boolean,
-	"app_version":     text,
-	"active_length":   long,
-	"pages_visited":   long,
+	"viewed":              boolean,
+	"viewed_by_me":        viewedByMe,
+	"has_session":         boolean,
+	"has_errors":          boolean,
+	"has_rage_clicks":     boolean,
+	"has_dead_clicks":     boolean,
+	"has_error_clicks":    boolean,
+	"has_thrashed_cursor": boolean,
+	"has_u_turns":         boolean,
+	"processed":           boolean,
+	"first_time":          boolean,
+	"has_comments":        boolean,
+	"app_version":         text,
+	"active_length":       long,
+	"pages_visited":       long,
 }
 
 // parseColumnRule applies a top-level column filter
@@ -0,0 +1,149 @@
+package clickhouse
+
+import (
+	"context"
+	"math"
+	"time"
+
+	modelInputs "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/huandu/go-sqlbuilder"
+)
+
+// error groups are created, and errors can be timestamped, shortly before their release is first seen
+const releaseWindowSlack = time.Hour
+
+// readReleaseErrorGroups selects the error groups whose first error object was reported by the release
+// between startDate and endDate. Groups created before the release are not scanned.
+func readReleaseErrorGroups(projectID int, version string, startDate time.Time, endDate time.Time) *sqlbuilder.SelectBuilder {
+	startDate = startDate.Add(-releaseWindowSlack)
+
+	groupsSb := sqlbuilder.NewSelectBuilder()
+	groupsSb.Select("ID").
+		From(ErrorGroupsTable).
+		Where(groupsSb.Equal("ProjectID", projectID)).
+		Where(groupsSb.GreaterEqualThan("CreatedAt", startDate))
+
+	innerSb := sqlbuilder.NewSelectBuilder()
+	innerSb.Select("DISTINCT ErrorGroupID").
+		From(ErrorObjectsTable).
+		Where(innerSb.Equal("ProjectID", projectID)).
+		Where(innerSb.Equal("ServiceVersion", version)).
+		Where(innerSb.Between("Timestamp", startDate, endDate))
+
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.Select("ErrorGroupID", "min(Timestamp) AS FirstSeen").
+		From(ErrorObjectsTable + " FINAL").
+		Where(sb.Equal("ProjectID", projectID)).
+		Where(sb.Between("Timestamp", startDate, endDate)).
+		Where(sb.In("ErrorGroupID", innerSb)).
+		Where(sb.In("ErrorGroupID", groupsSb)).
+		GroupBy("ErrorGroupID").
+		Having(sb.Equal("argMin(ServiceVersion, Timestamp)", version))
+	return sb
+}
+
+// QueryReleaseErrorGroupIds returns the error groups first seen in the release between startDate and endDate,
+// most recent first.
+func (client *Client) QueryReleaseErrorGroupIds(ctx context.Context, projectID int, version string, startDate time.Time, endDate time.Time, count int, page *int) ([]int64, int64, error) {
+	offset := 0
+	if page != nil && *page > 1 {
+		offset = (*page - 1) * count
+	}
+
+	sb := readReleaseErrorGroups(projectID, version, startDate, endDate)
+	sb.OrderBy("FirstSeen DESC").Limit(count).Offset(offset)
+	sql, args := sb.BuildWithFlavor(sqlbuilder.ClickHouse)
+
+	rows, err := client.conn.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		var firstSeen time.Time
+		if err := rows.Scan(&id, &firstSeen); err != nil {
+			return nil, 0, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	countSb := sqlbuilder.NewSelectBuilder()
+	countSql, countArgs := countSb.Select("count()").
+		From(countSb.BuilderAs(readReleaseErrorGroups(projectID, version, startDate, endDate), "release_error_groups")).
+		BuildWithFlavor(sqlbuilder.ClickHouse)
+
+	var total uint64
+	if err := client.conn.QueryRow(ctx, countSql, countArgs...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	return ids, int64(total), nil
+}
+
+// QueryReleaseStats aggregates the errors, sessions and spans reported by the release between startDate and endDate.
+func (client *Client) QueryReleaseStats(ctx context.Context, projectID int, version string, startDate time.Time, endDate time.Time) (*modelInputs.ReleaseStats, error) {
+	stats := &modelInputs.ReleaseStats{
+		Version:   version,
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+
+	sb := sqlbuilder.NewSelectBuilder()
+	sql, args := sb.Select("count()").
+		From(ErrorObjectsTable + " FINAL").
+		Where(sb.Equal("ProjectID", projectID)).
+		Where(sb.Equal("ServiceVersion", version)).
+		Where(sb.Between("Timestamp", startDate, endDate)).
+		BuildWithFlavor(sqlbuilder.ClickHouse)
+	if err := client.conn.QueryRow(ctx, sql, args...).Scan(&stats.ErrorCount); err != nil {
+		return nil, err
+	}
+
+	sb = sqlbuilder.NewSelectBuilder()
+	sql, args = sb.Select("count()").
+		From(sb.BuilderAs(readReleaseErrorGroups(projectID, version, startDate, endDate), "release_error_groups")).
+		BuildWithFlavor(sqlbuilder.ClickHouse)
+	if err := client.conn.QueryRow(ctx, sql, args...).Scan(&stats.NewErrorGroups); err != nil {
+		return nil, err
+	}
+
+	var sessionsWithErrors uint64
+	sb = sqlbuilder.NewSelectBuilder()
+	sql, args = sb.Select("count()", "countIf(HasErrors)").
+		From(SessionsTable + " FINAL").
+		Where(sb.Equal("ProjectID", projectID)).
+		Where(sb.Equal("AppVersion", version)).
+		Where(sb.Equal("Excluded", false)).
+		Where(sb.Between("CreatedAt", startDate, endDate)).
+		BuildWithFlavor(sqlbuilder.ClickHouse)
+	if err := client.conn.QueryRow(ctx, sql, args...).Scan(&stats.SessionCount, &sessionsWithErrors); err != nil {
+		return nil, err
+	}
+	if stats.SessionCount > 0 {
+		stats.ErrorRate = float64(stats.ErrorCount) / float64(stats.SessionCount)
+		crashFree := float64(stats.SessionCount-sessionsWithErrors) / float64(stats.SessionCount)
+		stats.CrashFreeSessions = &crashFree
+	}
+
+	var p95 float64
+	sb = sqlbuilder.NewSelectBuilder()
+	sql, args = sb.Select("count()", "quantile(0.95)(Duration)").
+		From(TracesTable).
+		Where(sb.Equal("ProjectId", projectID)).
+		Where(sb.Equal("ServiceVersion", version)).
+		Where(sb.Between("Timestamp", startDate, endDate)).
+		BuildWithFlavor(sqlbuilder.ClickHouse)
+	if err := client.conn.QueryRow(ctx, sql, args...).Scan(&stats.SpanCount, &p95); err != nil {
+		return nil, err
+	}
+	if stats.SpanCount > 0 && !math.IsNaN(p95) {
+		stats.P95Latency = &p95
+	}
+
+	return stats, nil
+}
@@ -29,44 +29,48 @@ import (
 const timeFormat = "2006-01-02T15:04:05.000Z"
 
 var fieldMap = map[string]string{
-	"pages_visited":     "PagesVisited",
-	"viewed_by_me":      "ViewedByAdmins",
-	"created_at":        "CreatedAt",
-	"updated_at":        "UpdatedAt",
-	"identified":        "Identified",
-	"identifier":        "Identifier",
-	"city":              "City",
-	"loc_state":         "State",
-	"country":           "Country",
-	"os_name":           "OSName",
-	"os_version":        "OSVersion",
-	"browser_name":      "BrowserName",
-	"browser_version":   "BrowserVersion",
-	"processed":         "Processed",
-	"excluded":          "Excluded",
-	"has_comments":      "HasComments",
-	"has_rage_clicks":   "HasRageClicks",
-	"has_errors":        "HasErrors",
-	"has_session":       "HasSession",
-	"length":            "Length",
-	"active_length":     "ActiveLength",
-	"environment":       "Environment",
-	"app_version":       "AppVersion",
-	"first_time":        "FirstTime",
-	"viewed":            "Viewed",
-	"Type":              "Type",
-	"Event":             "Event",
-	"event":             "Event",
-	"state":             "Status",
-	"browser":           "Browser",
-	"visited_url":       "VisitedURL",
-	"timestamp":         "Timestamp",
-	"secure_id":         "SecureID",
-	"service_name":      "ServiceName",
-	"service_version":   "ServiceVersion",
-	"Tag":               "ErrorTagTitle",
-	"secure_session_id": "SecureSessionID",
-	"trace_id":          "TraceID",
+	"pages_visited":       "PagesVisited",
+	"viewed_by_me":        "ViewedByAdmins",
+	"created_at":          "CreatedAt",
+	"updated_at":          "UpdatedAt",
+	"identified":          "Identified",
+	"identifier":          "Identifier",
+	"city":                "City",
+	"loc_state":           "State",
+	"country":             "Country",
+	"os_name":             "OSName",
+	"os_version":          "OSVersion",
+	"browser_name":        "BrowserName",
+	"browser_version":     "BrowserVersion",
+	"processed":           "Processed",
+	"excluded":            "Excluded",
+	"has_comments":        "HasComments",
+	"has_rage_clicks":     "HasRageClicks",
+	"has_dead_clicks":     "HasDeadClicks",
+	"has_error_clicks":    "HasErrorClicks",
+	"has_thrashed_cursor": "HasThrashedCursor",
+	"has_u_turns":         "HasUTurns",
+	"has_errors":          "HasErrors",
+	"has_session":         "HasSession",
+	"length":              "Length",
+	"active_length":       "ActiveLength",
+	"environment":         "Environment",
+	"app_version":         "AppVersion",
+	"first_time":          "FirstTime",
+	"viewed":              "Viewed",
+	"Type":                "Type",
+	"Event":               "Event",
+	"event":               "Event",
+	"state":               "Status",
+	"browser":             "Browser",
+	"visited_url":         "VisitedURL",
+	"timestamp":           "Timestamp",
+	"secure_id":           "SecureID",
+	"service_name":        "ServiceName",
+	"service_version":     "ServiceVersion",
+	"Tag":                 "ErrorTagTitle",
+	"secure_session_id":   "SecureSessionID",
+	"trace_id":            "TraceID",
 }
 
 type ClickhouseSession struct {
@@ -92,6 +96,10 @@ type ClickhouseSession struct {
 	Processed          *bool
 	HasComments        bool
 	HasRageClicks      *bool
+	HasDeadClicks      *bool
+	HasErrorClicks     *bool
+	HasThrashedCursor  *bool
+	HasUTurns          *bool
 	HasErrors          *bool
 	Length             int64
 	ActiveLength       int64
@@ -113,6 +121,10 @@ var defaultSessionsKeys = []*modelInputs.QueryKey{
 	{Name: string(modelInputs.ReservedSessionKeyHasComments), Type: modelInputs.KeyTypeBoolean},
 	{Name: string(modelInputs.ReservedSessionKeyHasErrors), Type: modelInputs.KeyTypeBoolean},
 	{Name: string(modelInputs.ReservedSessionKeyHasRageClicks), Type: modelInputs.KeyTypeBoolean},
+	{Name: string(modelInputs.ReservedSessionKeyHasDeadClicks), Type: modelInputs.KeyTypeBoolean},
+	{Name: string(modelInputs.ReservedSessionKeyHasErrorClicks), Type: modelInputs.KeyTypeBoolean},
+	{Name: string(modelInputs.ReservedSessionKeyHasThrashedCursor), Type: modelInputs.KeyTypeBoolean},
+	{Name: string(modelInputs.ReservedSessionKeyHasUTurns), Type: modelInputs.KeyTypeBoolean},
 	{Name: string(modelInputs.ReservedSessionKeyIdentified), Type: modelInputs.KeyTypeBoolean},
 	{Name: string(modelInputs.ReservedSessionKeyLength), Type: modelInputs.KeyTypeNumeric},
 	{Name: string(modelInputs.ReservedSessionKeyPagesVisited), Type: modelInputs.KeyTypeNumeric},
@@ -124,14 +136,18 @@ var defaultSessionsKeys = []*modelInputs.QueryKey{
 }
 
 var booleanKeys = map[string]bool{
-	string(modelInputs.ReservedSessionKeyCompleted):      true,
-	string(modelInputs.ReservedSessionKeyFirstTime):      true,
-	string(modelInputs.ReservedSessionKeyIdentified):     true,
-	string(modelInputs.ReservedSessionKeyHasComments):    true,
-	string(modelInputs.ReservedSessionKeyHasErrors):      true,
-	string(modelInputs.ReservedSessionKeyHasRageClicks):  true,
-	string(modelInputs.ReservedSessionKeyViewedByAnyone): true,
-	string(modelInputs.ReservedSessionKeyViewedByMe):     true,
+	string(modelInputs.ReservedSessionKeyCompleted):         true,
+	string(modelInputs.ReservedSessionKeyFirstTime):         true,
+	string(modelInputs.ReservedSessionKeyIdentified):        true,
+	string(modelInputs.ReservedSessionKeyHasComments):       true,
+	string(modelInputs.ReservedSessionKeyHasErrors):         true,
+	string(modelInputs.ReservedSessionKeyHasRageClicks):     true,
+	string(modelInputs.ReservedSessionKeyHasDeadClicks):     true,
+	string(modelInputs.ReservedSessionKeyHasErrorClicks):    true,
+	string(modelInputs.ReservedSessionKeyHasThrashedCursor): true,
+	string(modelInputs.ReservedSessionKeyHasUTurns):         true,
+	string(modelInputs.ReservedSessionKeyViewedByAnyone):    true,
+	string(modelInputs.ReservedSessionKeyViewedByMe):        true,
 }
 
 const SessionsJoinedTable = "sessions_joined_vw"
@@ -205,6 +221,10 @@ func (client *Client) WriteSessions(ctx context.Context, sessions []*model.Sessi
 			Processed:          session.Processed,
 			HasComments:        session.HasComments,
 			HasRageClicks:      session.HasRageClicks,
+			HasDeadClicks:      session.HasDeadClicks,
+			HasErrorClicks:     session.HasErrorClicks,
+			HasThrashedCursor:  session.HasThrashedCursor,
+			HasUTurns:          session.HasUTurns,
 			HasErrors:          session.HasErrors,
 			Length:             session.Length,
 			ActiveLength:       session.ActiveLength,
@@ -448,7 +468,22 @@ func (client *Client) DeleteSessions(ctx context.Context, projectId int, session
 		Where(sb.In("ID", sessionIds))
 	sql, args := sb.BuildWithFlavor(sqlbuilder.ClickHouse)
 
-	return client.conn.Exec(ctx, sql, args...)
+	if err := client.conn.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	for _, table := range []string{SessionClicksTable, SessionScrollDepthsTable} {
+		sb := sqlbuilder.NewDeleteBuilder()
+		sb.DeleteFrom(table).
+			Where(sb.Equal("ProjectID", projectId)).
+			Where(sb.In("SessionID", sessionIds))
+		sql, args := sb.BuildWithFlavor(sqlbuilder.ClickHouse)
+		if err := client.conn.Exec(ctx, sql, args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 var SessionsTableConfig = model.TableConfig{
@@ -487,6 +522,10 @@ var SessionsJoinedTableConfig = model.TableConfig{
 		string(modelInputs.ReservedSessionKeyHasComments):        "HasComments",
 		string(modelInputs.ReservedSessionKeyHasErrors):          "HasErrors",
 		string(modelInputs.ReservedSessionKeyHasRageClicks):      "HasRageClicks",
+		string(modelInputs.ReservedSessionKeyHasDeadClicks):      "HasDeadClicks",
+		string(modelInputs.ReservedSessionKeyHasErrorClicks):     "HasErrorClicks",
+		string(modelInputs.ReservedSessionKeyHasThrashedCursor):  "HasThrashedCursor",
+		string(modelInputs.ReservedSessionKeyHasUTurns):          "HasUTurns",
 		string(modelInputs.ReservedSessionKeyIdentified):         "Identified",
 		string(modelInputs.ReservedSessionKeyIdentifier):         "Identifier",
 		string(modelInputs.ReservedSessionKeyIP):                 "IP",
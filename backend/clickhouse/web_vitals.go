@@ -0,0 +1,289 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/highlight-run/highlight/backend/model"
+	modelInputs "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/samber/lo"
+)
+
+const WebVitalsTable = "web_vitals"
+
+// WebVitalCategory is the category of the metrics reported by the client web vitals listener
+const WebVitalCategory = "WebVital"
+
+// Attributes added to web vital metrics on ingest, read by the web_vitals materialized view
+const (
+	WebVitalURLPatternAttribute  = "url_pattern"
+	WebVitalDeviceClassAttribute = "device_class"
+	WebVitalBrowserAttribute     = "browser_name"
+	WebVitalReleaseAttribute     = "release"
+)
+
+// WebVitalThreshold is the upper bound of the good and needs improvement ratings of a web vital.
+type WebVitalThreshold struct {
+	Name             string
+	Good             float64
+	NeedsImprovement float64
+}
+
+// WebVitalThresholds are Google's Core Web Vitals thresholds, see https://web.dev/articles/vitals
+var WebVitalThresholds = []WebVitalThreshold{
+	{Name: "CLS", Good: 0.1, NeedsImprovement: 0.25},
+	{Name: "FCP", Good: 1800, NeedsImprovement: 3000},
+	{Name: "FID", Good: 100, NeedsImprovement: 300},
+	{Name: "INP", Good: 200, NeedsImprovement: 500},
+	{Name: "LCP", Good: 2500, NeedsImprovement: 4000},
+	{Name: "TTFB", Good: 800, NeedsImprovement: 1800},
+}
+
+func (t WebVitalThreshold) Rating(value float64) modelInputs.WebVitalRating {
+	if value <= t.Good {
+		return modelInputs.WebVitalRatingGood
+	} else if value <= t.NeedsImprovement {
+		return modelInputs.WebVitalRatingNeedsImprovement
+	}
+	return modelInputs.WebVitalRatingPoor
+}
+
+var webVitalKeysToColumns = map[string]string{
+	string(modelInputs.ReservedWebVitalKeyBrowser):         "Browser",
+	string(modelInputs.ReservedWebVitalKeyDeviceClass):     "DeviceClass",
+	string(modelInputs.ReservedWebVitalKeyEnvironment):     "Environment",
+	string(modelInputs.ReservedWebVitalKeyMetricName):      "MetricName",
+	string(modelInputs.ReservedWebVitalKeyRelease):         "Release",
+	string(modelInputs.ReservedWebVitalKeySecureSessionID): "SecureSessionId",
+	string(modelInputs.ReservedWebVitalKeyTimestamp):       "Timestamp",
+	string(modelInputs.ReservedWebVitalKeyURL):             "URL",
+	string(modelInputs.ReservedWebVitalKeyValue):           "Value",
+}
+
+var webVitalGroupByColumns = map[modelInputs.WebVitalsGroupBy]string{
+	modelInputs.WebVitalsGroupByURL:         "URL",
+	modelInputs.WebVitalsGroupByDeviceClass: "DeviceClass",
+	modelInputs.WebVitalsGroupByBrowser:     "Browser",
+	modelInputs.WebVitalsGroupByRelease:     "Release",
+}
+
+var reservedWebVitalKeys = lo.Map(modelInputs.AllReservedWebVitalKey, func(key modelInputs.ReservedWebVitalKey, _ int) string {
+	return string(key)
+})
+
+var webVitalsTableConfig = model.TableConfig{
+	AttributesColumns: []model.ColumnMapping{{Column: "Attributes"}},
+	BodyColumn:        "MetricName",
+	KeysToColumns:     webVitalKeysToColumns,
+	ReservedKeys:      reservedWebVitalKeys,
+	TableName:         WebVitalsTable,
+}
+
+var WebVitalsSampleableTableConfig = SampleableTableConfig{
+	tableConfig: webVitalsTableConfig,
+}
+
+// Web vitals reports return at most this many groups by default, the most measured first
+const defaultWebVitalsGroups = 50
+
+type webVitalKey struct {
+	Group string
+	Name  string
+}
+
+// readWebVitals returns the p75 and rating distribution of every web vital of the groups in the date range of the params.
+func (client *Client) readWebVitals(ctx context.Context, projectID int, params modelInputs.QueryInput, groupBy *modelInputs.WebVitalsGroupBy, limit int) ([]webVitalKey, map[webVitalKey]*modelInputs.WebVitalMetric, error) {
+	groupCol := "''"
+	if groupBy != nil {
+		groupCol = webVitalGroupByColumns[*groupBy]
+	}
+
+	names := lo.Map(WebVitalThresholds, func(t WebVitalThreshold, _ int) string {
+		return fmt.Sprintf("'%s'", t.Name)
+	})
+	threshold := func(bound func(t WebVitalThreshold) float64) string {
+		values := lo.Map(WebVitalThresholds, func(t WebVitalThreshold, _ int) string {
+			return fmt.Sprintf("%g", bound(t))
+		})
+		return fmt.Sprintf("transform(MetricName, [%s], [%s], 0)", strings.Join(names, ", "), strings.Join(values, ", "))
+	}
+	good := threshold(func(t WebVitalThreshold) float64 { return t.Good })
+	needsImprovement := threshold(func(t WebVitalThreshold) float64 { return t.NeedsImprovement })
+
+	sb, _, err := makeSelectBuilder(webVitalsTableConfig, []string{
+		fmt.Sprintf("toString(%s) AS Key", groupCol),
+		"toString(MetricName) AS Name",
+		"quantile(.75)(Value)",
+		"count()",
+		fmt.Sprintf("countIf(Value <= %s)", good),
+		fmt.Sprintf("countIf(Value > %s)", needsImprovement),
+	}, []int{projectID}, params, Pagination{CountOnly: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	sb.Where(fmt.Sprintf("MetricName IN (%s)", strings.Join(names, ", "))).
+		GroupBy("Key", "Name").
+		OrderBy("sum(count()) OVER (PARTITION BY Key) DESC", "Key", "Name").
+		Limit(limit * len(WebVitalThresholds))
+
+	sql, args := sb.BuildWithFlavor(sqlbuilder.ClickHouse)
+	rows, err := client.conn.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var keys []webVitalKey
+	metrics := map[webVitalKey]*modelInputs.WebVitalMetric{}
+	for rows.Next() {
+		var key webVitalKey
+		var p75 float64
+		var count, goodCount, poorCount uint64
+		if err := rows.Scan(&key.Group, &key.Name, &p75, &count, &goodCount, &poorCount); err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, key)
+		metrics[key] = &modelInputs.WebVitalMetric{
+			Name:             key.Name,
+			P75:              p75,
+			Count:            int64(count),
+			Good:             int64(goodCount),
+			NeedsImprovement: int64(count - goodCount - poorCount),
+			Poor:             int64(poorCount),
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return keys, metrics, nil
+}
+
+// QueryWebVitalsReport returns the p75 and good / needs improvement / poor distribution of the Core Web Vitals of the project,
+// grouped by page url pattern, device class, browser or release, and compared to another date range if set.
+func (client *Client) QueryWebVitalsReport(ctx context.Context, projectID int, params modelInputs.QueryInput, groupBy *modelInputs.WebVitalsGroupBy, compareDateRange *modelInputs.DateRangeRequiredInput, limit *int) ([]*modelInputs.WebVitalsGroup, error) {
+	limitCount := defaultWebVitalsGroups
+	if limit != nil && *limit > 0 {
+		limitCount = *limit
+	}
+
+	keys, metrics, err := client.readWebVitals(ctx, projectID, params, groupBy, limitCount)
+	if err != nil {
+		return nil, err
+	}
+
+	if compareDateRange != nil {
+		compareParams := params
+		compareParams.DateRange = compareDateRange
+		// read more groups of the compared range, as the top groups may have been measured less often before
+		_, previousMetrics, err := client.readWebVitals(ctx, projectID, compareParams, groupBy, limitCount*10)
+		if err != nil {
+			return nil, err
+		}
+		for key, metric := range metrics {
+			if previous, ok := previousMetrics[key]; ok {
+				metric.PreviousP75 = &previous.P75
+				metric.PreviousCount = &previous.Count
+			}
+		}
+	}
+
+	thresholds := lo.SliceToMap(WebVitalThresholds, func(t WebVitalThreshold) (string, WebVitalThreshold) {
+		return t.Name, t
+	})
+	groups := []*modelInputs.WebVitalsGroup{}
+	groupsByKey := map[string]*modelInputs.WebVitalsGroup{}
+	for _, key := range keys {
+		group, ok := groupsByKey[key.Group]
+		if !ok {
+			group = &modelInputs.WebVitalsGroup{Key: key.Group, Metrics: []*modelInputs.WebVitalMetric{}}
+			groupsByKey[key.Group] = group
+			groups = append(groups, group)
+		}
+		metric := metrics[key]
+		metric.Rating = thresholds[metric.Name].Rating(metric.P75)
+		group.Metrics = append(group.Metrics, metric)
+	}
+	return groups, nil
+}
+
+func (client *Client) ReadWebVitalsMetrics(ctx context.Context, projectID int, params modelInputs.QueryInput, sql *string, groupBy []string, nBuckets *int, bucketBy string, bucketWindow *int, limit *int, limitAggregator *modelInputs.MetricAggregator, limitColumn *string, expressions []*modelInputs.MetricExpressionInput) (*modelInputs.MetricsBuckets, error) {
+	return client.ReadMetrics(ctx, ReadMetricsInput{
+		SampleableConfig: WebVitalsSampleableTableConfig,
+		ProjectIDs:       []int{projectID},
+		Params:           params,
+		Sql:              sql,
+		GroupBy:          groupBy,
+		BucketCount:      nBuckets,
+		BucketWindow:     bucketWindow,
+		BucketBy:         bucketBy,
+		Limit:            limit,
+		LimitAggregator:  limitAggregator,
+		LimitColumn:      limitColumn,
+		Expressions:      expressions,
+	})
+}
+
+func (client *Client) WebVitalsKeys(ctx context.Context, projectID int, startDate time.Time, endDate time.Time, query *string, typeArg *modelInputs.KeyType) ([]*modelInputs.QueryKey, error) {
+	keys := []*modelInputs.QueryKey{}
+	for _, key := range modelInputs.AllReservedWebVitalKey {
+		keyType := modelInputs.KeyTypeString
+		if key == modelInputs.ReservedWebVitalKeyValue || key == modelInputs.ReservedWebVitalKeyTimestamp {
+			keyType = modelInputs.KeyTypeNumeric
+		}
+		if typeArg != nil && *typeArg == modelInputs.KeyTypeNumeric && keyType != modelInputs.KeyTypeNumeric {
+			continue
+		}
+		if query != nil && !strings.Contains(string(key), strings.ToLower(*query)) {
+			continue
+		}
+		keys = append(keys, &modelInputs.QueryKey{Name: string(key), Type: keyType})
+	}
+	return keys, nil
+}
+
+func (client *Client) WebVitalsKeyValues(ctx context.Context, projectID int, keyName string, startDate time.Time, endDate time.Time, query *string, limit *int) ([]string, error) {
+	limitCount := 10
+	if limit != nil {
+		limitCount = *limit
+	}
+
+	sb := sqlbuilder.NewSelectBuilder()
+	col, ok := webVitalKeysToColumns[keyName]
+	if !ok {
+		col = fmt.Sprintf("Attributes[%s]", sb.Var(keyName))
+	}
+
+	sb.Select(fmt.Sprintf("toString(%s) AS KeyValue", col)).
+		From(WebVitalsTable).
+		Where(sb.Equal("ProjectId", projectID)).
+		Where(sb.Between("Timestamp", startDate, endDate)).
+		Where("KeyValue != ''")
+	if query != nil && *query != "" {
+		sb.Where(fmt.Sprintf("KeyValue ILIKE %s", sb.Var("%"+*query+"%")))
+	}
+	sb.GroupBy("KeyValue").
+		OrderBy("count() DESC").
+		Limit(limitCount)
+
+	sql, args := sb.BuildWithFlavor(sqlbuilder.ClickHouse)
+	rows, err := client.conn.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	values := []string{}
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+func (client *Client) WebVitalsLogLines(ctx context.Context, projectID int, params modelInputs.QueryInput) ([]*modelInputs.LogLine, error) {
+	return logLines(ctx, client, webVitalsTableConfig, projectID, params)
+}
@@ -0,0 +1,87 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	modelInputs "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryWebVitalsReport(t *testing.T) {
+	ctx := context.Background()
+	client, _ := NewClient(TestDatabase)
+	defer func() {
+		assert.NoError(t, client.conn.Exec(ctx, fmt.Sprintf("TRUNCATE TABLE %s", WebVitalsTable)))
+	}()
+
+	now := time.Now()
+	previous := now.Add(-7 * 24 * time.Hour)
+	batch, err := client.conn.PrepareBatch(ctx, fmt.Sprintf("INSERT INTO %s (ProjectId, Timestamp, MetricName, Value, URL, DeviceClass, Browser, Release)", WebVitalsTable))
+	require.NoError(t, err)
+	for _, row := range []struct {
+		timestamp time.Time
+		name      string
+		value     float64
+		url       string
+		device    string
+	}{
+		{now, "LCP", 1000, "/", "desktop"},
+		{now, "LCP", 2000, "/", "desktop"},
+		{now, "LCP", 3000, "/", "mobile"},
+		{now, "LCP", 5000, "/", "mobile"},
+		{now, "CLS", 0.3, "/", "mobile"},
+		{now, "LCP", 1000, "/users/{id-1}", "desktop"},
+		{now, "Jank", 1000, "/", "desktop"},
+		{previous, "LCP", 5000, "/", "desktop"},
+		{previous, "LCP", 6000, "/", "desktop"},
+	} {
+		require.NoError(t, batch.Append(uint32(1), row.timestamp, row.name, row.value, row.url, row.device, "Chrome", "v1"))
+	}
+	require.NoError(t, batch.Send())
+
+	params := modelInputs.QueryInput{
+		DateRange: &modelInputs.DateRangeRequiredInput{StartDate: now.Add(-time.Hour), EndDate: now.Add(time.Hour)},
+	}
+	groupBy := modelInputs.WebVitalsGroupByURL
+	groups, err := client.QueryWebVitalsReport(ctx, 1, params, &groupBy, &modelInputs.DateRangeRequiredInput{
+		StartDate: previous.Add(-time.Hour),
+		EndDate:   previous.Add(time.Hour),
+	}, nil)
+	require.NoError(t, err)
+
+	// the most measured urls are first
+	require.Len(t, groups, 2)
+	assert.Equal(t, "/", groups[0].Key)
+	assert.Equal(t, "/users/{id-1}", groups[1].Key)
+
+	require.Len(t, groups[0].Metrics, 2)
+	cls, lcp := groups[0].Metrics[0], groups[0].Metrics[1]
+	assert.Equal(t, "CLS", cls.Name)
+	assert.Equal(t, modelInputs.WebVitalRatingPoor, cls.Rating)
+	assert.Nil(t, cls.PreviousP75)
+
+	assert.Equal(t, "LCP", lcp.Name)
+	assert.InDelta(t, 3500, lcp.P75, 1)
+	assert.Equal(t, modelInputs.WebVitalRatingNeedsImprovement, lcp.Rating)
+	assert.Equal(t, int64(4), lcp.Count)
+	assert.Equal(t, int64(2), lcp.Good)
+	assert.Equal(t, int64(1), lcp.NeedsImprovement)
+	assert.Equal(t, int64(1), lcp.Poor)
+	require.NotNil(t, lcp.PreviousP75)
+	assert.InDelta(t, 5750, *lcp.PreviousP75, 1)
+	assert.Equal(t, int64(2), *lcp.PreviousCount)
+
+	// the search query filters the measurements
+	params.Query = "device_class=desktop"
+	groups, err = client.QueryWebVitalsReport(ctx, 1, params, nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "", groups[0].Key)
+	require.Len(t, groups[0].Metrics, 1)
+	assert.Equal(t, int64(3), groups[0].Metrics[0].Count)
+	assert.Equal(t, modelInputs.WebVitalRatingGood, groups[0].Metrics[0].Rating)
+}
@@ -0,0 +1,264 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// header prefixes envelope encrypted data. It is followed by the id of the data key and the sealed data.
+var header = []byte("HLENC1")
+
+// ErrKeyDestroyed is returned when decrypting data of a project whose data keys were destroyed.
+var ErrKeyDestroyed = errors.New("the data key was destroyed")
+
+// Unwrapped data keys are cached so that the KMS is not called for every payload.
+// Keys destroyed or rotated on another instance stop being used once they expire from the cache.
+const keyCacheTTL = 5 * time.Minute
+
+type dataKey struct {
+	id        int
+	projectID int
+	aead      cipher.AEAD
+	macKey    []byte
+	expires   time.Time
+}
+
+// Keyring envelope encrypts data with per-project data keys, stored wrapped by a KMS master key.
+type Keyring struct {
+	db  *gorm.DB
+	kms KMS
+
+	mu sync.Mutex
+	// current is the data key encrypting new data of each project
+	current map[int]*dataKey
+	keys    map[int]*dataKey
+}
+
+func NewKeyring(db *gorm.DB, kms KMS) *Keyring {
+	return &Keyring{db: db, kms: kms, current: make(map[int]*dataKey), keys: make(map[int]*dataKey)}
+}
+
+func (k *Keyring) cached(keys map[int]*dataKey, id int) *dataKey {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	key, ok := keys[id]
+	if !ok || time.Now().After(key.expires) {
+		return nil
+	}
+	return key
+}
+
+func (k *Keyring) cache(projectID int, key *dataKey, current bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys[key.id] = key
+	if current {
+		k.current[projectID] = key
+	}
+}
+
+func (k *Keyring) unwrap(ctx context.Context, row *model.ProjectDataKey) (*dataKey, error) {
+	raw, err := k.kms.UnwrapKey(ctx, row.MasterKeyID, row.WrappedKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error unwrapping data key %d", row.ID)
+	}
+	aead, err := newAEAD(raw)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, raw)
+	mac.Write([]byte("url-signing"))
+	return &dataKey{id: row.ID, projectID: row.ProjectID, aead: aead, macKey: mac.Sum(nil), expires: time.Now().Add(keyCacheTTL)}, nil
+}
+
+func (k *Keyring) createKey(ctx context.Context, projectID int) (*dataKey, error) {
+	raw, err := randomBytes(keySize)
+	if err != nil {
+		return nil, err
+	}
+	masterKeyID := k.kms.CurrentKeyID()
+	wrapped, err := k.kms.WrapKey(ctx, masterKeyID, raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "error wrapping data key")
+	}
+	row := model.ProjectDataKey{ProjectID: projectID, MasterKeyID: masterKeyID, WrappedKey: wrapped}
+	if err := k.db.WithContext(ctx).Create(&row).Error; err != nil {
+		return nil, errors.Wrap(err, "error saving data key")
+	}
+	key, err := k.unwrap(ctx, &row)
+	if err != nil {
+		return nil, err
+	}
+	k.cache(projectID, key, true)
+	return key, nil
+}
+
+// currentKey returns the latest data key of the project, creating it for the project's first payload.
+func (k *Keyring) currentKey(ctx context.Context, projectID int) (*dataKey, error) {
+	if key := k.cached(k.current, projectID); key != nil {
+		return key, nil
+	}
+	var rows []*model.ProjectDataKey
+	if err := k.db.WithContext(ctx).Where(&model.ProjectDataKey{ProjectID: projectID}).Order("id DESC").Limit(1).Find(&rows).Error; err != nil {
+		return nil, errors.Wrap(err, "error querying data key")
+	}
+	if len(rows) == 0 {
+		return k.createKey(ctx, projectID)
+	}
+	key, err := k.unwrap(ctx, rows[0])
+	if err != nil {
+		return nil, err
+	}
+	k.cache(projectID, key, true)
+	return key, nil
+}
+
+func (k *Keyring) key(ctx context.Context, projectID int, id int) (*dataKey, error) {
+	if key := k.cached(k.keys, id); key != nil && key.projectID == projectID {
+		return key, nil
+	}
+	var row model.ProjectDataKey
+	if err := k.db.WithContext(ctx).Where(&model.ProjectDataKey{Model: model.Model{ID: id}, ProjectID: projectID}).Take(&row).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrKeyDestroyed
+		}
+		return nil, errors.Wrap(err, "error querying data key")
+	}
+	key, err := k.unwrap(ctx, &row)
+	if err != nil {
+		return nil, err
+	}
+	k.cache(projectID, key, false)
+	return key, nil
+}
+
+// additionalData binds the ciphertext to its project and data key.
+func additionalData(prefix []byte, projectID int) []byte {
+	return binary.BigEndian.AppendUint64(bytes.Clone(prefix), uint64(projectID))
+}
+
+// Encrypt encrypts the data with the current data key of the project.
+func (k *Keyring) Encrypt(ctx context.Context, projectID int, plaintext []byte) ([]byte, error) {
+	key, err := k.currentKey(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	prefix := binary.BigEndian.AppendUint32(bytes.Clone(header), uint32(key.id))
+	sealed, err := seal(key.aead, plaintext, additionalData(prefix, projectID))
+	if err != nil {
+		return nil, err
+	}
+	return append(prefix, sealed...), nil
+}
+
+// Decrypt decrypts data encrypted by Encrypt. Data that is not encrypted is returned unchanged,
+// so that data stored before encryption was enabled can still be read.
+func (k *Keyring) Decrypt(ctx context.Context, projectID int, data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, header) {
+		return data, nil
+	}
+	prefixLength := len(header) + 4
+	if len(data) < prefixLength {
+		return nil, errors.New("encrypted data is too short")
+	}
+	key, err := k.key(ctx, projectID, int(binary.BigEndian.Uint32(data[len(header):prefixLength])))
+	if err != nil {
+		return nil, err
+	}
+	return open(key.aead, data[prefixLength:], additionalData(data[:prefixLength], projectID))
+}
+
+// Sign returns a signature of the message with a key derived from the current data key of the project.
+func (k *Keyring) Sign(ctx context.Context, projectID int, message string) (string, error) {
+	key, err := k.currentKey(ctx, projectID)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d.%s", key.id, sign(key.macKey, message)), nil
+}
+
+// Verify returns whether the signature was returned by Sign for the message.
+func (k *Keyring) Verify(ctx context.Context, projectID int, message string, signature string) bool {
+	id, mac, ok := strings.Cut(signature, ".")
+	if !ok {
+		return false
+	}
+	keyID, err := strconv.Atoi(id)
+	if err != nil {
+		return false
+	}
+	key, err := k.key(ctx, projectID, keyID)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(mac), []byte(sign(key.macKey, message)))
+}
+
+func sign(macKey []byte, message string) string {
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write([]byte(message))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// RotateDataKey creates a new data key for the project's new data. Existing data is still decrypted with older keys.
+func (k *Keyring) RotateDataKey(ctx context.Context, projectID int) error {
+	_, err := k.createKey(ctx, projectID)
+	return err
+}
+
+// RewrapDataKeys wraps the data keys wrapped by older master keys with the current master key,
+// returning how many were rewrapped.
+func (k *Keyring) RewrapDataKeys(ctx context.Context) (int, error) {
+	masterKeyID := k.kms.CurrentKeyID()
+	var rows []*model.ProjectDataKey
+	count := 0
+	err := k.db.WithContext(ctx).Where("master_key_id <> ?", masterKeyID).FindInBatches(&rows, 1000, func(tx *gorm.DB, batch int) error {
+		for _, row := range rows {
+			raw, err := k.kms.UnwrapKey(ctx, row.MasterKeyID, row.WrappedKey)
+			if err != nil {
+				return errors.Wrapf(err, "error unwrapping data key %d", row.ID)
+			}
+			wrapped, err := k.kms.WrapKey(ctx, masterKeyID, raw)
+			if err != nil {
+				return errors.Wrapf(err, "error wrapping data key %d", row.ID)
+			}
+			if err := k.db.WithContext(ctx).Model(row).Updates(&model.ProjectDataKey{MasterKeyID: masterKeyID, WrappedKey: wrapped}).Error; err != nil {
+				return errors.Wrapf(err, "error saving data key %d", row.ID)
+			}
+			count++
+		}
+		return nil
+	}).Error
+	return count, err
+}
+
+// DestroyProjectKeys deletes the data keys of the project, crypto-shredding all of its encrypted data.
+// This cannot be undone.
+func (k *Keyring) DestroyProjectKeys(ctx context.Context, projectID int) error {
+	if err := k.db.WithContext(ctx).Where("project_id = ?", projectID).Delete(&model.ProjectDataKey{}).Error; err != nil {
+		return errors.Wrap(err, "error deleting data keys")
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	delete(k.current, projectID)
+	for id, key := range k.keys {
+		if key.projectID == projectID {
+			delete(k.keys, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package encryption
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/highlight-run/highlight/backend/util"
+	e "github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var db *gorm.DB
+
+func TestMain(m *testing.M) {
+	var err error
+	db, err = util.CreateAndMigrateTestDB("highlight_testing_db")
+	if err != nil {
+		log.WithContext(context.TODO()).Error(e.Wrap(err, "error creating testdb"))
+	}
+	os.Exit(m.Run())
+}
+
+func TestKeyring(t *testing.T) {
+	ctx := context.Background()
+	defer func() {
+		require.NoError(t, util.ClearTablesInDB(db))
+	}()
+
+	oldKey, err := GenerateMasterKey()
+	require.NoError(t, err)
+	kms, err := NewKeyfileKMS(writeKeyfile(t, Keyfile{CurrentKeyID: "old", Keys: map[string]string{"old": oldKey}}))
+	require.NoError(t, err)
+	keyring := NewKeyring(db, kms)
+
+	encrypted, err := keyring.Encrypt(ctx, 1, []byte("payload"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(encrypted), "payload")
+	decrypted, err := keyring.Decrypt(ctx, 1, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("payload"), decrypted)
+
+	// payloads stored before encryption was enabled are returned unchanged
+	decrypted, err = keyring.Decrypt(ctx, 1, []byte("legacy"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("legacy"), decrypted)
+
+	// payloads are bound to their project
+	_, err = keyring.Decrypt(ctx, 2, encrypted)
+	assert.Error(t, err)
+
+	signature, err := keyring.Sign(ctx, 1, "/decrypted/1/1/session-contents-compressed")
+	require.NoError(t, err)
+	assert.True(t, keyring.Verify(ctx, 1, "/decrypted/1/1/session-contents-compressed", signature))
+	assert.False(t, keyring.Verify(ctx, 1, "/decrypted/1/2/session-contents-compressed", signature))
+	assert.False(t, keyring.Verify(ctx, 2, "/decrypted/1/1/session-contents-compressed", signature))
+
+	// rotating the data key keeps existing payloads readable
+	require.NoError(t, keyring.RotateDataKey(ctx, 1))
+	rotated, err := keyring.Encrypt(ctx, 1, []byte("payload"))
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted[:len(header)+4], rotated[:len(header)+4])
+	for _, data := range [][]byte{encrypted, rotated} {
+		decrypted, err = keyring.Decrypt(ctx, 1, data)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("payload"), decrypted)
+	}
+
+	// rotating the master key rewraps the data keys
+	newKey, err := GenerateMasterKey()
+	require.NoError(t, err)
+	kms, err = NewKeyfileKMS(writeKeyfile(t, Keyfile{CurrentKeyID: "new", Keys: map[string]string{"old": oldKey, "new": newKey}}))
+	require.NoError(t, err)
+	count, err := NewKeyring(db, kms).RewrapDataKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	kms, err = NewKeyfileKMS(writeKeyfile(t, Keyfile{CurrentKeyID: "new", Keys: map[string]string{"new": newKey}}))
+	require.NoError(t, err)
+	keyring = NewKeyring(db, kms)
+	decrypted, err = keyring.Decrypt(ctx, 1, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("payload"), decrypted)
+
+	// destroying the project keys crypto-shreds its payloads
+	other, err := keyring.Encrypt(ctx, 2, []byte("payload"))
+	require.NoError(t, err)
+	require.NoError(t, keyring.DestroyProjectKeys(ctx, 1))
+	_, err = keyring.Decrypt(ctx, 1, rotated)
+	assert.ErrorIs(t, err, ErrKeyDestroyed)
+	assert.False(t, keyring.Verify(ctx, 1, "/decrypted/1/1/session-contents-compressed", signature))
+	decrypted, err = keyring.Decrypt(ctx, 2, other)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("payload"), decrypted)
+}
@@ -0,0 +1,136 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// KMS wraps the data keys of projects with master keys that never leave it.
+type KMS interface {
+	// CurrentKeyID is the id of the master key wrapping new data keys.
+	CurrentKeyID() string
+	WrapKey(ctx context.Context, masterKeyID string, dataKey []byte) ([]byte, error)
+	UnwrapKey(ctx context.Context, masterKeyID string, wrappedKey []byte) ([]byte, error)
+}
+
+// Keyfile is the format of the file read by KeyfileKMS. To rotate the master key,
+// add a new key, make it current and rewrap the data keys; the old key can be removed once none use it.
+type Keyfile struct {
+	CurrentKeyID string `json:"current_key_id"`
+	// Keys are the base64 encoded 256 bit master keys by id.
+	Keys map[string]string `json:"keys"`
+}
+
+// KeyfileKMS wraps data keys with master keys read from a local file, for self-hosted deployments.
+type KeyfileKMS struct {
+	currentKeyID string
+	keys         map[string]cipher.AEAD
+}
+
+func NewKeyfileKMS(path string) (*KeyfileKMS, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading keyfile")
+	}
+	var keyfile Keyfile
+	if err := json.Unmarshal(b, &keyfile); err != nil {
+		return nil, errors.Wrap(err, "error parsing keyfile")
+	}
+	if _, ok := keyfile.Keys[keyfile.CurrentKeyID]; !ok {
+		return nil, errors.Errorf("keyfile has no current key %q", keyfile.CurrentKeyID)
+	}
+
+	kms := &KeyfileKMS{currentKeyID: keyfile.CurrentKeyID, keys: make(map[string]cipher.AEAD)}
+	for id, encoded := range keyfile.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error decoding master key %s", id)
+		}
+		if len(key) != keySize {
+			return nil, errors.Errorf("master key %s must be %d bytes", id, keySize)
+		}
+		if kms.keys[id], err = newAEAD(key); err != nil {
+			return nil, err
+		}
+	}
+	return kms, nil
+}
+
+// GenerateMasterKey returns a new base64 encoded master key for a keyfile.
+func GenerateMasterKey() (string, error) {
+	key, err := randomBytes(keySize)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+func (k *KeyfileKMS) CurrentKeyID() string {
+	return k.currentKeyID
+}
+
+func (k *KeyfileKMS) WrapKey(_ context.Context, masterKeyID string, dataKey []byte) ([]byte, error) {
+	aead, ok := k.keys[masterKeyID]
+	if !ok {
+		return nil, errors.Errorf("unknown master key %s", masterKeyID)
+	}
+	return seal(aead, dataKey, []byte(masterKeyID))
+}
+
+func (k *KeyfileKMS) UnwrapKey(_ context.Context, masterKeyID string, wrappedKey []byte) ([]byte, error) {
+	aead, ok := k.keys[masterKeyID]
+	if !ok {
+		return nil, errors.Errorf("unknown master key %s", masterKeyID)
+	}
+	return open(aead, wrappedKey, []byte(masterKeyID))
+}
+
+const keySize = 32
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return nil, errors.Wrap(err, "error generating random bytes")
+	}
+	return b, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating aes cipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating gcm cipher")
+	}
+	return aead, nil
+}
+
+// seal encrypts the plaintext, prefixing it with a random nonce.
+func seal(aead cipher.AEAD, plaintext []byte, additionalData []byte) ([]byte, error) {
+	nonce, err := randomBytes(aead.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, ciphertext []byte, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	plaintext, err := aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], additionalData)
+	if err != nil {
+		return nil, errors.Wrap(err, "error decrypting")
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,54 @@
+package encryption
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeKeyfile(t *testing.T, keyfile Keyfile) string {
+	b, err := json.Marshal(keyfile)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "keyfile.json")
+	require.NoError(t, os.WriteFile(path, b, 0600))
+	return path
+}
+
+func TestKeyfileKMS(t *testing.T) {
+	ctx := context.Background()
+	oldKey, err := GenerateMasterKey()
+	require.NoError(t, err)
+	newKey, err := GenerateMasterKey()
+	require.NoError(t, err)
+
+	kms, err := NewKeyfileKMS(writeKeyfile(t, Keyfile{CurrentKeyID: "old", Keys: map[string]string{"old": oldKey}}))
+	require.NoError(t, err)
+	assert.Equal(t, "old", kms.CurrentKeyID())
+	wrapped, err := kms.WrapKey(ctx, "old", []byte("data key"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(wrapped), "data key")
+
+	// after rotation, keys wrapped by the old master key can still be unwrapped
+	kms, err = NewKeyfileKMS(writeKeyfile(t, Keyfile{CurrentKeyID: "new", Keys: map[string]string{"old": oldKey, "new": newKey}}))
+	require.NoError(t, err)
+	assert.Equal(t, "new", kms.CurrentKeyID())
+	unwrapped, err := kms.UnwrapKey(ctx, "old", wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data key"), unwrapped)
+
+	// the wrapped key is bound to its master key id
+	_, err = kms.UnwrapKey(ctx, "new", wrapped)
+	assert.Error(t, err)
+	_, err = kms.UnwrapKey(ctx, "missing", wrapped)
+	assert.Error(t, err)
+
+	_, err = NewKeyfileKMS(writeKeyfile(t, Keyfile{CurrentKeyID: "missing", Keys: map[string]string{"old": oldKey}}))
+	assert.Error(t, err)
+	_, err = NewKeyfileKMS(writeKeyfile(t, Keyfile{CurrentKeyID: "short", Keys: map[string]string{"short": "c2hvcnQ="}}))
+	assert.Error(t, err)
+}
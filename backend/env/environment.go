@@ -37,6 +37,9 @@ type Configuration struct {
 	AwsS3ResourcesBucketName    string `mapstructure:"AWS_S3_RESOURCES_BUCKET"`
 	AwsS3SourceMapBucketName    string `mapstructure:"AWS_S3_SOURCE_MAP_BUCKET_NAME_NEW"`
 	AwsS3StagingBucketName      string `mapstructure:"AWS_S3_STAGING_BUCKET_NAME"`
+	AzureStorageAccountKey      string `mapstructure:"AZURE_STORAGE_ACCOUNT_KEY"`
+	AzureStorageAccountName     string `mapstructure:"AZURE_STORAGE_ACCOUNT_NAME"`
+	AzureStorageEndpoint        string `mapstructure:"AZURE_STORAGE_ENDPOINT"`
 	ClearbitApiKey              string `mapstructure:"CLEARBIT_API_KEY"`
 	ClickUpClientID             string `mapstructure:"CLICKUP_CLIENT_ID"`
 	ClickUpClientSecret         string `mapstructure:"CLICKUP_CLIENT_SECRET"`
@@ -99,9 +102,11 @@ type Configuration struct {
 	OAuthRedirectUrl            string `mapstructure:"OAUTH_REDIRECT_URL"`
 	OTLPDogfoodEndpoint         string `mapstructure:"OTLP_DOGFOOD_ENDPOINT"`
 	OTLPEndpoint                string `mapstructure:"OTLP_ENDPOINT"`
+	ObjectStorageBackend        string `mapstructure:"OBJECT_STORAGE_BACKEND"`
 	ObjectStorageFS             string `mapstructure:"OBJECT_STORAGE_FS"`
 	OnPrem                      string `mapstructure:"ON_PREM"`
 	OpenAIApiKey                string `mapstructure:"OPENAI_API_KEY"`
+	PayloadEncryptionKeyfile    string `mapstructure:"PAYLOAD_ENCRYPTION_KEYFILE"`
 	PredictionsEndpoint         string `mapstructure:"PREDICTIONS_ENDPOINT"`
 	PricingBasicPriceID         string `mapstructure:"BASIC_PLAN_PRICE_ID"`
 	PricingEnterprisePriceID    string `mapstructure:"ENTERPRISE_PLAN_PRICE_ID"`
@@ -111,6 +116,8 @@ type Configuration struct {
 	RedisEndpoint               string `mapstructure:"REDIS_EVENTS_STAGING_ENDPOINT"`
 	RedisPassword               string `mapstructure:"REDIS_PASSWORD"`
 	Release                     string `mapstructure:"RELEASE"`
+	SAMLCertificate             string `mapstructure:"SAML_CERTIFICATE"` // PEM encoded, signs SAML requests
+	SAMLPrivateKey              string `mapstructure:"SAML_PRIVATE_KEY"`
 	SQLDatabase                 string `mapstructure:"PSQL_DB"`
 	SQLDockerHost               string `mapstructure:"PSQL_DOCKER_HOST"`
 	SQLHost                     string `mapstructure:"PSQL_HOST"`
@@ -129,6 +136,7 @@ type Configuration struct {
 	StripeErrorsProductID       string `mapstructure:"STRIPE_ERRORS_PRODUCT_ID"`
 	StripeSessionsProductID     string `mapstructure:"STRIPE_SESSIONS_PRODUCT_ID"`
 	StripeWebhookSecret         string `mapstructure:"STRIPE_WEBHOOK_SECRET"`
+	TrustedProxies              string `mapstructure:"TRUSTED_PROXIES"` // comma separated IPs or CIDRs
 	VercelClientId              string `mapstructure:"VERCEL_CLIENT_ID"`
 	VercelClientSecret          string `mapstructure:"VERCEL_CLIENT_SECRET"`
 	Version                     string `mapstructure:"REACT_APP_COMMIT_SHA"`
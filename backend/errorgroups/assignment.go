@@ -0,0 +1,41 @@
+package errorgroups
+
+import (
+	"sort"
+
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// ValidateAssignmentRule checks that the rule assigns to exactly one of an admin or a team.
+// An empty query matches every error.
+func ValidateAssignmentRule(rule *model.ErrorGroupAssignmentRule) error {
+	if (rule.AssigneeAdminID == nil) == (rule.AssigneeTeam == nil) {
+		return errors.New("assignment rules require either an assignee admin or an assignee team")
+	}
+	if rule.AssigneeTeam != nil && *rule.AssigneeTeam == "" {
+		return errors.New("assignee team must not be empty")
+	}
+	return nil
+}
+
+// MatchAssignmentRule returns the first enabled rule in priority order matching the error.
+func MatchAssignmentRule(rules []*model.ErrorGroupAssignmentRule, errorObj *model.ErrorObject) *model.ErrorGroupAssignmentRule {
+	rules = lo.Filter(rules, func(rule *model.ErrorGroupAssignmentRule, _ int) bool {
+		return !rule.Disabled
+	})
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].Priority != rules[j].Priority {
+			return rules[i].Priority < rules[j].Priority
+		}
+		return rules[i].ID < rules[j].ID
+	})
+
+	for _, rule := range rules {
+		if ErrorMatchesQuery(errorObj, rule.Query) {
+			return rule
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package errorgroups
+
+import (
+	"testing"
+
+	"github.com/aws/smithy-go/ptr"
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAssignmentRule(t *testing.T) {
+	assert.NoError(t, ValidateAssignmentRule(&model.ErrorGroupAssignmentRule{AssigneeAdminID: ptr.Int(1)}))
+	assert.NoError(t, ValidateAssignmentRule(&model.ErrorGroupAssignmentRule{AssigneeTeam: ptr.String("@highlight/payments")}))
+	assert.Error(t, ValidateAssignmentRule(&model.ErrorGroupAssignmentRule{}))
+	assert.Error(t, ValidateAssignmentRule(&model.ErrorGroupAssignmentRule{AssigneeAdminID: ptr.Int(1), AssigneeTeam: ptr.String("@highlight/payments")}))
+	assert.Error(t, ValidateAssignmentRule(&model.ErrorGroupAssignmentRule{AssigneeTeam: ptr.String("")}))
+}
+
+func TestMatchAssignmentRule(t *testing.T) {
+	errorObj := &model.ErrorObject{
+		Event:       "payment declined for order 1234",
+		Type:        "BACKEND",
+		ServiceName: "checkout",
+		StackTrace:  ptr.String("[]"),
+	}
+	rules := []*model.ErrorGroupAssignmentRule{
+		{Model: model.Model{ID: 1}, Query: "", AssigneeTeam: ptr.String("@highlight/oncall"), Priority: 2},
+		{Model: model.Model{ID: 2}, Query: "service_name=checkout", AssigneeTeam: ptr.String("@highlight/payments"), Priority: 1},
+		{Model: model.Model{ID: 3}, Query: "service_name=billing", AssigneeAdminID: ptr.Int(1)},
+		{Model: model.Model{ID: 4}, Query: "", AssigneeAdminID: ptr.Int(2), Disabled: true},
+	}
+
+	assert.Equal(t, 2, MatchAssignmentRule(rules, errorObj).ID)
+	assert.Equal(t, 1, MatchAssignmentRule(rules[:1], errorObj).ID)
+	assert.Nil(t, MatchAssignmentRule(rules[2:], errorObj))
+	assert.Nil(t, MatchAssignmentRule(nil, errorObj))
+}
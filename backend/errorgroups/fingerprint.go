@@ -1,10 +1,12 @@
 package errorgroups
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
 
+	"github.com/PaesslerAG/jsonpath"
 	"github.com/aws/smithy-go/ptr"
 
 	"github.com/highlight-run/highlight/backend/model"
@@ -41,6 +43,36 @@ func GetFingerprints(projectID int, errorTraces []*privateModel.ErrorTrace) []*m
 	return fingerprints
 }
 
+// GetJSONFingerprints returns a fingerprint for each of the project's JSON paths
+// found in an error event that is a JSON encoded object.
+func GetJSONFingerprints(projectID int, event string, jsonPaths []string) []*model.ErrorFingerprint {
+	var fingerprints []*model.ErrorFingerprint
+	jsonStrings := []string{}
+	if err := json.Unmarshal([]byte(event), &jsonStrings); err != nil || len(jsonStrings) != 1 {
+		return fingerprints
+	}
+	errorAsJson := interface{}(nil)
+	if err := json.Unmarshal([]byte(jsonStrings[0]), &errorAsJson); err != nil {
+		return fingerprints
+	}
+	for _, path := range jsonPaths {
+		value, err := jsonpath.Get(path, errorAsJson)
+		if err != nil {
+			continue
+		}
+		marshalled, err := json.Marshal(value)
+		if err != nil {
+			continue
+		}
+		fingerprints = append(fingerprints, &model.ErrorFingerprint{
+			ProjectID: projectID,
+			Type:      model.Fingerprint.JsonResult,
+			Value:     path + "=" + string(marshalled),
+		})
+	}
+	return fingerprints
+}
+
 func GetKey(projectID int, errorObj *model.ErrorObject, structuredStackTrace []*privateModel.ErrorTrace) string {
 	var fingerprintsStr string
 	for _, fp := range GetFingerprints(projectID, structuredStackTrace) {
@@ -0,0 +1,70 @@
+package errorgroups
+
+import (
+	"strconv"
+	"strings"
+
+	privateModel "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+)
+
+// CompareVersions compares two dotted numeric versions such as `v1.2.10` or `2024.01.5-rc1`.
+// Pre-release and build suffixes are ignored. ok is false when either version is not numeric.
+func CompareVersions(a, b string) (cmp int, ok bool) {
+	as, ok := parseVersion(a)
+	if !ok {
+		return 0, false
+	}
+	bs, ok := parseVersion(b)
+	if !ok {
+		return 0, false
+	}
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		if x < y {
+			return -1, true
+		} else if x > y {
+			return 1, true
+		}
+	}
+	return 0, true
+}
+
+func parseVersion(version string) ([]int, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if idx := strings.IndexAny(version, "-+"); idx >= 0 {
+		version = version[:idx]
+	}
+	if version == "" {
+		return nil, false
+	}
+	var parts []int
+	for _, part := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, true
+}
+
+// ReopenedState returns the state of a resolved error group that received a new error object.
+// The group regresses unless the error comes from a version that is not newer than the
+// version the group was resolved in, in which case the fix has not been deployed there yet.
+func ReopenedState(resolvedInVersion *string, serviceVersion string) privateModel.ErrorState {
+	if resolvedInVersion != nil && serviceVersion != "" {
+		if serviceVersion == *resolvedInVersion {
+			return privateModel.ErrorStateOpen
+		}
+		if cmp, ok := CompareVersions(serviceVersion, *resolvedInVersion); ok && cmp <= 0 {
+			return privateModel.ErrorStateOpen
+		}
+	}
+	return privateModel.ErrorStateRegressed
+}
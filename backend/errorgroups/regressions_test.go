@@ -0,0 +1,39 @@
+package errorgroups
+
+import (
+	"testing"
+
+	"github.com/aws/smithy-go/ptr"
+	privateModel "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareVersions(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		cmp  int
+		ok   bool
+	}{
+		{"1.2.3", "1.2.3", 0, true},
+		{"v1.2.10", "1.2.9", 1, true},
+		{"1.2", "1.2.1", -1, true},
+		{"2.0.0-rc1", "2.0.0", 0, true},
+		{"2024.01.5", "2023.12.31", 1, true},
+		{"abc123", "1.0.0", 0, false},
+		{"", "1.0.0", 0, false},
+	} {
+		cmp, ok := CompareVersions(tc.a, tc.b)
+		assert.Equal(t, tc.ok, ok, "%s vs %s", tc.a, tc.b)
+		assert.Equal(t, tc.cmp, cmp, "%s vs %s", tc.a, tc.b)
+	}
+}
+
+func TestReopenedState(t *testing.T) {
+	assert.Equal(t, privateModel.ErrorStateRegressed, ReopenedState(nil, "1.0.0"))
+	assert.Equal(t, privateModel.ErrorStateRegressed, ReopenedState(ptr.String("1.0.0"), ""))
+	assert.Equal(t, privateModel.ErrorStateRegressed, ReopenedState(ptr.String("1.0.0"), "1.0.1"))
+	assert.Equal(t, privateModel.ErrorStateRegressed, ReopenedState(ptr.String("abc123"), "def456"))
+	assert.Equal(t, privateModel.ErrorStateOpen, ReopenedState(ptr.String("1.0.0"), "1.0.0"))
+	assert.Equal(t, privateModel.ErrorStateOpen, ReopenedState(ptr.String("abc123"), "abc123"))
+	assert.Equal(t, privateModel.ErrorStateOpen, ReopenedState(ptr.String("1.0.0"), "0.9.9"))
+}
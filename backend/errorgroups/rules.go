@@ -0,0 +1,229 @@
+package errorgroups
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/smithy-go/ptr"
+	"github.com/highlight-run/highlight/backend/clickhouse"
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/highlight-run/highlight/backend/parser"
+	"github.com/highlight-run/highlight/backend/parser/listener"
+	privateModel "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	publicModel "github.com/highlight-run/highlight/backend/public-graph/graph/model"
+	e "github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// fingerprintTemplateVariable matches a `{{ variable }}` placeholder in a fingerprint template.
+var fingerprintTemplateVariable = regexp.MustCompile(`\{\{\s*([a-z_.]+)\s*\}\}`)
+
+var fingerprintTemplateVariables = map[string]func(errorObj *model.ErrorObject, frame *privateModel.ErrorTrace) string{
+	"event":           func(errorObj *model.ErrorObject, _ *privateModel.ErrorTrace) string { return errorObj.Event },
+	"type":            func(errorObj *model.ErrorObject, _ *privateModel.ErrorTrace) string { return errorObj.Type },
+	"url":             func(errorObj *model.ErrorObject, _ *privateModel.ErrorTrace) string { return errorObj.URL },
+	"source":          func(errorObj *model.ErrorObject, _ *privateModel.ErrorTrace) string { return errorObj.Source },
+	"environment":     func(errorObj *model.ErrorObject, _ *privateModel.ErrorTrace) string { return errorObj.Environment },
+	"service_name":    func(errorObj *model.ErrorObject, _ *privateModel.ErrorTrace) string { return errorObj.ServiceName },
+	"service_version": func(errorObj *model.ErrorObject, _ *privateModel.ErrorTrace) string { return errorObj.ServiceVersion },
+	"frame.function": func(_ *model.ErrorObject, frame *privateModel.ErrorTrace) string {
+		if frame == nil {
+			return ""
+		}
+		return ptr.ToString(frame.FunctionName)
+	},
+	"frame.file": func(_ *model.ErrorObject, frame *privateModel.ErrorTrace) string {
+		if frame == nil {
+			return ""
+		}
+		return ptr.ToString(frame.FileName)
+	},
+}
+
+// GroupingResult is the effect of a project's grouping rules on an error.
+type GroupingResult struct {
+	MatchedRules []*model.ErrorGroupingRule
+	// Fingerprint replaces the stack trace fingerprints when set.
+	Fingerprint *string
+	// StackTrace holds the frames used for fingerprinting.
+	StackTrace     []*privateModel.ErrorTrace
+	IgnoredFrames  int
+	NotInAppFrames int
+}
+
+// ValidateGroupingRule checks that the rule action has the settings it needs.
+// An empty query matches every error.
+func ValidateGroupingRule(rule *model.ErrorGroupingRule) error {
+	switch rule.Action {
+	case privateModel.ErrorGroupingRuleActionFingerprint:
+		template := strings.TrimSpace(ptr.ToString(rule.FingerprintTemplate))
+		if template == "" {
+			return e.New("fingerprint template is required")
+		}
+		for _, match := range fingerprintTemplateVariable.FindAllStringSubmatch(template, -1) {
+			if _, ok := fingerprintTemplateVariables[match[1]]; !ok {
+				return e.Errorf("unknown fingerprint template variable %q", match[1])
+			}
+		}
+	case privateModel.ErrorGroupingRuleActionIgnoreFrames, privateModel.ErrorGroupingRuleActionNotInApp:
+		if ptr.ToString(rule.FramePattern) == "" {
+			return e.New("frame pattern is required")
+		}
+		if _, err := regexp.Compile(*rule.FramePattern); err != nil {
+			return e.Wrap(err, "invalid frame pattern")
+		}
+	default:
+		return e.Errorf("unknown grouping rule action %q", rule.Action)
+	}
+	return nil
+}
+
+// RenderFingerprintTemplate substitutes the template placeholders with values of the error and its top frame.
+func RenderFingerprintTemplate(template string, errorObj *model.ErrorObject, frame *privateModel.ErrorTrace) string {
+	return fingerprintTemplateVariable.ReplaceAllStringFunc(template, func(s string) string {
+		name := fingerprintTemplateVariable.FindStringSubmatch(s)[1]
+		if fn, ok := fingerprintTemplateVariables[name]; ok {
+			return fn(errorObj, frame)
+		}
+		return s
+	})
+}
+
+func matchesFrame(pattern *regexp.Regexp, frame *privateModel.ErrorTrace) bool {
+	return pattern.MatchString(ptr.ToString(frame.FileName)) || pattern.MatchString(ptr.ToString(frame.FunctionName))
+}
+
+// ErrorMatchesQuery returns whether the error object matches the error search query of a rule or alert.
+// An empty query matches every error.
+func ErrorMatchesQuery(errorObj *model.ErrorObject, query string) bool {
+	return errorMatchesFilters(errorObj, parser.Parse(query, clickhouse.BackendErrorObjectInputConfig))
+}
+
+func errorMatchesFilters(errorObj *model.ErrorObject, filters listener.Filters) bool {
+	input := &publicModel.BackendErrorObjectInput{
+		Event:       errorObj.Event,
+		Type:        errorObj.Type,
+		URL:         errorObj.URL,
+		Source:      errorObj.Source,
+		StackTrace:  ptr.ToString(errorObj.StackTrace),
+		Timestamp:   errorObj.Timestamp,
+		Payload:     errorObj.Payload,
+		Environment: errorObj.Environment,
+		Service: &publicModel.ServiceInput{
+			Name:    errorObj.ServiceName,
+			Version: errorObj.ServiceVersion,
+		},
+	}
+	return clickhouse.ErrorMatchesQuery(input, filters)
+}
+
+type compiledGroupingRule struct {
+	rule    *model.ErrorGroupingRule
+	filters listener.Filters
+	pattern *regexp.Regexp
+}
+
+// GroupingRules are the enabled grouping rules of a project in priority order,
+// with their queries parsed and frame patterns compiled, see CompileGroupingRules.
+type GroupingRules []compiledGroupingRule
+
+// CompileGroupingRules prepares the rules to be applied to every ingested error of the project.
+// Frame rules with an invalid pattern are skipped.
+func CompileGroupingRules(rules []*model.ErrorGroupingRule) GroupingRules {
+	rules = lo.Filter(rules, func(rule *model.ErrorGroupingRule, _ int) bool {
+		return !rule.Disabled
+	})
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].Priority != rules[j].Priority {
+			return rules[i].Priority < rules[j].Priority
+		}
+		return rules[i].ID < rules[j].ID
+	})
+
+	var compiled GroupingRules
+	for _, rule := range rules {
+		compiledRule := compiledGroupingRule{
+			rule:    rule,
+			filters: parser.Parse(rule.Query, clickhouse.BackendErrorObjectInputConfig),
+		}
+		if rule.Action == privateModel.ErrorGroupingRuleActionIgnoreFrames || rule.Action == privateModel.ErrorGroupingRuleActionNotInApp {
+			pattern, err := regexp.Compile(ptr.ToString(rule.FramePattern))
+			if err != nil {
+				continue
+			}
+			compiledRule.pattern = pattern
+		}
+		compiled = append(compiled, compiledRule)
+	}
+	return compiled
+}
+
+// ApplyGroupingRules evaluates the compiled rules in priority order against the error.
+// Frame rules accumulate, while only the first matching fingerprint rule applies.
+// Frames matched by a NotInApp rule are flagged with InApp=false.
+func ApplyGroupingRules(rules GroupingRules, errorObj *model.ErrorObject, structuredStackTrace []*privateModel.ErrorTrace) GroupingResult {
+	result := GroupingResult{StackTrace: structuredStackTrace}
+
+	ignored := make(map[int]bool)
+	notInApp := make(map[int]bool)
+	var fingerprintRule *model.ErrorGroupingRule
+	for _, compiled := range rules {
+		rule := compiled.rule
+		if rule.Action == privateModel.ErrorGroupingRuleActionFingerprint && fingerprintRule != nil {
+			continue
+		}
+		if !errorMatchesFilters(errorObj, compiled.filters) {
+			continue
+		}
+		result.MatchedRules = append(result.MatchedRules, rule)
+
+		switch rule.Action {
+		case privateModel.ErrorGroupingRuleActionFingerprint:
+			fingerprintRule = rule
+		case privateModel.ErrorGroupingRuleActionIgnoreFrames, privateModel.ErrorGroupingRuleActionNotInApp:
+			for idx, frame := range structuredStackTrace {
+				if frame == nil || !matchesFrame(compiled.pattern, frame) {
+					continue
+				}
+				if rule.Action == privateModel.ErrorGroupingRuleActionIgnoreFrames {
+					ignored[idx] = true
+				} else {
+					notInApp[idx] = true
+				}
+			}
+		}
+	}
+
+	if len(ignored) > 0 || len(notInApp) > 0 {
+		result.StackTrace = nil
+		var inApp []*privateModel.ErrorTrace
+		for idx, frame := range structuredStackTrace {
+			if ignored[idx] {
+				result.IgnoredFrames++
+				continue
+			}
+			if notInApp[idx] {
+				result.NotInAppFrames++
+				frame.InApp = ptr.Bool(false)
+			} else {
+				inApp = append(inApp, frame)
+			}
+			result.StackTrace = append(result.StackTrace, frame)
+		}
+		// group on the in-app frames, unless the whole stack is library code
+		if len(inApp) > 0 {
+			result.StackTrace = inApp
+		}
+	}
+
+	if fingerprintRule != nil {
+		var top *privateModel.ErrorTrace
+		if len(result.StackTrace) > 0 {
+			top = result.StackTrace[0]
+		}
+		result.Fingerprint = ptr.String(RenderFingerprintTemplate(*fingerprintRule.FingerprintTemplate, errorObj, top))
+	}
+
+	return result
+}
@@ -0,0 +1,88 @@
+package errorgroups
+
+import (
+	"testing"
+
+	"github.com/aws/smithy-go/ptr"
+	"github.com/highlight-run/highlight/backend/model"
+	privateModel "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func groupingRulesTrace() []*privateModel.ErrorTrace {
+	return []*privateModel.ErrorTrace{
+		{FileName: ptr.String("node_modules/react-dom/index.js"), FunctionName: ptr.String("commitRoot")},
+		{FileName: ptr.String("src/util/logger.ts"), FunctionName: ptr.String("logError")},
+		{FileName: ptr.String("src/checkout.ts"), FunctionName: ptr.String("submitOrder")},
+	}
+}
+
+func TestValidateGroupingRule(t *testing.T) {
+	assert.NoError(t, ValidateGroupingRule(&model.ErrorGroupingRule{
+		Action:              privateModel.ErrorGroupingRuleActionFingerprint,
+		FingerprintTemplate: ptr.String("{{ type }}-{{frame.function}}"),
+	}))
+	assert.Error(t, ValidateGroupingRule(&model.ErrorGroupingRule{
+		Action:              privateModel.ErrorGroupingRuleActionFingerprint,
+		FingerprintTemplate: ptr.String("{{ user_id }}"),
+	}))
+	assert.Error(t, ValidateGroupingRule(&model.ErrorGroupingRule{
+		Action: privateModel.ErrorGroupingRuleActionFingerprint,
+	}))
+	assert.Error(t, ValidateGroupingRule(&model.ErrorGroupingRule{
+		Action:       privateModel.ErrorGroupingRuleActionIgnoreFrames,
+		FramePattern: ptr.String("node_modules/("),
+	}))
+	assert.NoError(t, ValidateGroupingRule(&model.ErrorGroupingRule{
+		Action:       privateModel.ErrorGroupingRuleActionNotInApp,
+		FramePattern: ptr.String("^node_modules/"),
+	}))
+}
+
+func TestApplyGroupingRules(t *testing.T) {
+	errorObj := &model.ErrorObject{
+		Event:       "payment declined for order 1234",
+		Type:        "BACKEND",
+		ServiceName: "checkout",
+		StackTrace:  ptr.String("[]"),
+	}
+	rules := []*model.ErrorGroupingRule{
+		{Model: model.Model{ID: 1}, Query: "service_name=checkout", Action: privateModel.ErrorGroupingRuleActionFingerprint, FingerprintTemplate: ptr.String("{{ service_name }}:{{ frame.function }}"), Priority: 1},
+		{Model: model.Model{ID: 2}, Query: "", Action: privateModel.ErrorGroupingRuleActionFingerprint, FingerprintTemplate: ptr.String("ignored"), Priority: 2},
+		{Model: model.Model{ID: 3}, Query: "", Action: privateModel.ErrorGroupingRuleActionIgnoreFrames, FramePattern: ptr.String("^logError$")},
+		{Model: model.Model{ID: 4}, Query: "", Action: privateModel.ErrorGroupingRuleActionNotInApp, FramePattern: ptr.String("^node_modules/")},
+		{Model: model.Model{ID: 5}, Query: "service_name=billing", Action: privateModel.ErrorGroupingRuleActionIgnoreFrames, FramePattern: ptr.String(".*")},
+		{Model: model.Model{ID: 6}, Query: "", Action: privateModel.ErrorGroupingRuleActionIgnoreFrames, FramePattern: ptr.String(".*"), Disabled: true},
+	}
+
+	trace := groupingRulesTrace()
+	result := ApplyGroupingRules(CompileGroupingRules(rules), errorObj, trace)
+	assert.Equal(t, []int{3, 4, 1}, []int{result.MatchedRules[0].ID, result.MatchedRules[1].ID, result.MatchedRules[2].ID})
+	assert.Equal(t, 1, result.IgnoredFrames)
+	assert.Equal(t, 1, result.NotInAppFrames)
+	assert.Equal(t, []*privateModel.ErrorTrace{trace[2]}, result.StackTrace)
+	assert.Equal(t, ptr.Bool(false), trace[0].InApp)
+	assert.Nil(t, trace[2].InApp)
+	assert.Equal(t, "checkout:submitOrder", ptr.ToString(result.Fingerprint))
+
+	// when every remaining frame is library code, the library frames are still used for grouping
+	trace = groupingRulesTrace()[:1]
+	result = ApplyGroupingRules(CompileGroupingRules(rules[3:4]), errorObj, trace)
+	assert.Equal(t, trace, result.StackTrace)
+	assert.Nil(t, result.Fingerprint)
+
+	// frame rules with an invalid pattern are skipped
+	invalid := []*model.ErrorGroupingRule{{Model: model.Model{ID: 7}, Action: privateModel.ErrorGroupingRuleActionIgnoreFrames, FramePattern: ptr.String("(")}}
+	assert.Empty(t, CompileGroupingRules(invalid))
+
+	result = ApplyGroupingRules(nil, errorObj, trace)
+	assert.Empty(t, result.MatchedRules)
+	assert.Equal(t, trace, result.StackTrace)
+}
+
+func TestErrorMatchesQuery(t *testing.T) {
+	errorObj := &model.ErrorObject{Event: "payment declined", ServiceName: "checkout", StackTrace: ptr.String("[]")}
+	assert.True(t, ErrorMatchesQuery(errorObj, ""))
+	assert.True(t, ErrorMatchesQuery(errorObj, "service_name=checkout"))
+	assert.False(t, ErrorMatchesQuery(errorObj, "service_name=billing"))
+}
@@ -25,6 +25,7 @@ import (
 
 	"github.com/highlight-run/highlight/backend/model"
 	modelInputs "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/highlight-run/highlight/backend/redact"
 	"github.com/highlight-run/highlight/backend/store"
 	"github.com/highlight-run/highlight/backend/util"
 	"github.com/lukasbob/srcset"
@@ -259,6 +260,14 @@ func (s *Snapshot) EscapeJavascript(ctx context.Context) error {
 	return escapeJavascript(ctx, s.data)
 }
 
+// unredactedSnapshotKeys hold node structure and inlined assets rather than page content.
+var unredactedSnapshotKeys = []string{"tagName", "rr_dataURL", "_cssText"}
+
+// Redact replaces the personal data found by the project's redaction rules in the text and attribute values of the snapshot.
+func (s *Snapshot) Redact(redactor *redact.Redactor) {
+	redactor.Object(s.data, unredactedSnapshotKeys...)
+}
+
 func escapeJavascript(ctx context.Context, root map[string]interface{}) error {
 	escapeNodeScriptTags(ctx, root)
 	escapeNodeWithJSAttrs(ctx, root)
@@ -14,6 +14,7 @@ import (
 	"github.com/go-test/deep"
 	"github.com/highlight-run/highlight/backend/model"
 	modelInputs "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/highlight-run/highlight/backend/redact"
 	"github.com/highlight-run/highlight/backend/redis"
 	"github.com/highlight-run/highlight/backend/storage"
 	"github.com/highlight-run/highlight/backend/store"
@@ -252,6 +253,27 @@ func TestEscapeJavascript(t *testing.T) {
 	}
 }
 
+func TestRedact(t *testing.T) {
+	var data map[string]interface{}
+	err := json.Unmarshal([]byte(`{"node": {"type": 2, "tagName": "input", "attributes": {"value": "vadim@highlight.io", "placeholder": "email"}, "childNodes": [{"type": 3, "textContent": "contact jay@highlight.io"}]}}`), &data)
+	if err != nil {
+		t.Fatalf("error unmarshaling: %v", err)
+	}
+
+	snapshot, err := NewSnapshot(data, nil)
+	if err != nil {
+		t.Fatalf("error parsing: %v", err)
+	}
+
+	redactor, err := redact.New([]*model.RedactionRule{{Detector: modelInputs.RedactionDetectorEmail}})
+	assert.NoError(t, err)
+	snapshot.Redact(redactor)
+
+	processed, err := snapshot.Encode()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"node": {"type": 2, "tagName": "input", "attributes": {"value": "[REDACTED]", "placeholder": "email"}, "childNodes": [{"type": 3, "textContent": "contact [REDACTED]"}]}}`, string(processed))
+}
+
 func TestSnapshot_ReplaceAssets(t *testing.T) {
 	defer teardown(t)
 	ctx := context.TODO()
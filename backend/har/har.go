@@ -0,0 +1,450 @@
+package har
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/highlight-run/highlight/backend/env"
+	"github.com/highlight-run/highlight/backend/model"
+)
+
+// Version of the HTTP Archive format produced by Build.
+const Version = "1.2"
+
+// CreatorName is the name of the application recorded as the creator of the archive.
+const CreatorName = "Highlight"
+
+const webSocketInitiatorType = "websocket"
+
+// HAR is the root of an HTTP Archive, see http://www.softwareishard.com/blog/har-12-spec/
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+	Comment string  `json:"comment,omitempty"`
+}
+
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Entry is a single request of the session.
+// Fields prefixed with an underscore are custom fields allowed by the spec.
+type Entry struct {
+	StartedDateTime string   `json:"startedDateTime"`
+	Time            float64  `json:"time"`
+	Request         Request  `json:"request"`
+	Response        Response `json:"response"`
+	Cache           struct{} `json:"cache"`
+	Timings         Timings  `json:"timings"`
+
+	ResourceType       string             `json:"_resourceType,omitempty"`
+	HighlightRequestID string             `json:"_highlightRequestId,omitempty"`
+	TraceID            string             `json:"_traceId,omitempty"`
+	URLBlocked         bool               `json:"_urlBlocked,omitempty"`
+	WebSocketMessages  []WebSocketMessage `json:"_webSocketMessages,omitempty"`
+}
+
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    *PostData   `json:"postData,omitempty"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+type Cookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type PostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type Content struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+// Timings of an entry in milliseconds. -1 is used for the phases that do not apply or were not recorded.
+type Timings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	SSL     float64 `json:"ssl"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// WebSocketMessage is a frame of a websocket entry, in the format exported by Chrome DevTools.
+type WebSocketMessage struct {
+	Type string `json:"type"`
+	// Time is the unix time of the message in seconds
+	Time   float64 `json:"time"`
+	Opcode int     `json:"opcode"`
+	Data   string  `json:"data"`
+}
+
+// resource is a recorded network resource. Websocket open and close requests are recorded
+// as separate resources with the same socket id.
+type resource struct {
+	model.NetworkResource
+	SocketID string `json:"socketId"`
+	Type     string `json:"type"`
+}
+
+type webSocketEvent struct {
+	SocketID  string  `json:"socketId"`
+	Type      string  `json:"type"`
+	Name      string  `json:"name"`
+	TimeStamp float64 `json:"timeStamp"`
+	Size      float64 `json:"size"`
+	Message   string  `json:"message"`
+}
+
+// Options of the archive built for a session.
+type Options struct {
+	SessionStart time.Time
+	// StartTime and EndTime optionally limit the archive to the requests started within the range
+	StartTime *time.Time
+	EndTime   *time.Time
+	// TraceIDs are the highlight request ids of the session that have a backend trace
+	TraceIDs map[string]bool
+}
+
+// RequestIDs returns the highlight request ids of the recorded resources,
+// the ids sent in the X-Highlight-Request header that are used as the trace id of the backend request.
+func RequestIDs(resources []interface{}) ([]string, error) {
+	parsed, err := parse[resource](resources)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, r := range parsed {
+		if id := r.RequestResponsePairs.Request.ID; id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return lo.Uniq(ids), nil
+}
+
+// Build converts the recorded network resources and websocket events of a session to an HTTP Archive.
+func Build(resources []interface{}, webSocketEvents []interface{}, opts Options) (*HAR, error) {
+	parsedResources, err := parse[resource](resources)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing network resources")
+	}
+	parsedEvents, err := parse[webSocketEvent](webSocketEvents)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing websocket events")
+	}
+
+	messages := map[string][]WebSocketMessage{}
+	for _, event := range parsedEvents {
+		var messageType string
+		switch event.Type {
+		case "sent":
+			messageType = "send"
+		case "received":
+			messageType = "receive"
+		default:
+			continue
+		}
+		messages[event.SocketID] = append(messages[event.SocketID], WebSocketMessage{
+			Type:   messageType,
+			Time:   event.TimeStamp / 1000,
+			Opcode: 1,
+			Data:   event.Message,
+		})
+	}
+
+	type entryStart struct {
+		start time.Time
+		entry Entry
+	}
+	var entries []entryStart
+	sockets := map[string]int{}
+	for _, r := range parsedResources {
+		if r.InitiatorType == webSocketInitiatorType {
+			// the close request only carries the end of the socket opened earlier
+			if r.Type == "close" {
+				if idx, ok := sockets[r.SocketID]; ok && r.ResponseEndAbs > 0 {
+					entries[idx].entry.Time = r.End(opts.SessionStart).Sub(entries[idx].start).Seconds() * 1000
+				}
+				continue
+			}
+		}
+
+		start := r.Start(opts.SessionStart)
+		if opts.StartTime != nil && start.Before(*opts.StartTime) {
+			continue
+		}
+		if opts.EndTime != nil && start.After(*opts.EndTime) {
+			continue
+		}
+
+		var entry Entry
+		if r.InitiatorType == webSocketInitiatorType {
+			entry = webSocketEntry(r, start, messages[r.SocketID])
+			sockets[r.SocketID] = len(entries)
+		} else {
+			entry = requestEntry(r, start, opts)
+		}
+		entries = append(entries, entryStart{start: start, entry: entry})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].start.Before(entries[j].start)
+	})
+
+	return &HAR{Log: Log{
+		Version: Version,
+		Creator: Creator{Name: CreatorName, Version: env.Config.Version},
+		Entries: lo.Map(entries, func(e entryStart, _ int) Entry {
+			return e.entry
+		}),
+	}}, nil
+}
+
+func parse[T any](values []interface{}) ([]T, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	var result []T
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func requestEntry(r resource, start time.Time, opts Options) Entry {
+	pairs := r.RequestResponsePairs
+	method := strings.ToUpper(pairs.Request.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	requestURL := pairs.Request.URL
+	if requestURL == "" {
+		requestURL = r.Name
+	}
+	requestHeaders := headers(pairs.Request.HeadersRaw)
+	responseHeaders := headers(pairs.Response.HeadersRaw)
+
+	request := Request{
+		Method:      method,
+		URL:         requestURL,
+		HTTPVersion: r.NextHopProtocol,
+		Cookies:     []Cookie{},
+		Headers:     requestHeaders,
+		QueryString: queryString(requestURL),
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+	if text, ok := body(pairs.Request.Body); ok {
+		request.PostData = &PostData{MimeType: header(requestHeaders, "Content-Type"), Text: text}
+		request.BodySize = len(text)
+	}
+
+	status := int(pairs.Response.Status)
+	content := Content{
+		Size:     int(r.DecodedBodySize),
+		MimeType: header(responseHeaders, "Content-Type"),
+	}
+	if pairs.Response.Size > 0 {
+		content.Size = int(pairs.Response.Size)
+	}
+	if text, ok := body(pairs.Response.Body); ok {
+		content.Text = text
+	}
+	response := Response{
+		Status:      status,
+		StatusText:  http.StatusText(status),
+		HTTPVersion: r.NextHopProtocol,
+		Cookies:     []Cookie{},
+		Headers:     responseHeaders,
+		Content:     content,
+		RedirectURL: header(responseHeaders, "Location"),
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+	if r.EncodedBodySize > 0 {
+		response.BodySize = int(r.EncodedBodySize)
+	}
+
+	entry := Entry{
+		StartedDateTime:    start.UTC().Format(time.RFC3339Nano),
+		Time:               duration(r.StartTimeAbs, r.ResponseEndAbs),
+		Request:            request,
+		Response:           response,
+		Timings:            timings(r),
+		ResourceType:       r.InitiatorType,
+		HighlightRequestID: pairs.Request.ID,
+		URLBlocked:         pairs.URLBlocked,
+	}
+	if opts.TraceIDs[pairs.Request.ID] {
+		entry.TraceID = pairs.Request.ID
+	}
+	return entry
+}
+
+func webSocketEntry(r resource, start time.Time, messages []WebSocketMessage) Entry {
+	return Entry{
+		StartedDateTime: start.UTC().Format(time.RFC3339Nano),
+		Time:            duration(r.StartTimeAbs, r.ResponseEndAbs),
+		Request: Request{
+			Method:      http.MethodGet,
+			URL:         r.Name,
+			Cookies:     []Cookie{},
+			Headers:     []NameValue{},
+			QueryString: queryString(r.Name),
+			HeadersSize: -1,
+			BodySize:    0,
+		},
+		Response: Response{
+			Status:      http.StatusSwitchingProtocols,
+			StatusText:  http.StatusText(http.StatusSwitchingProtocols),
+			Cookies:     []Cookie{},
+			Headers:     []NameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings:           Timings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1},
+		ResourceType:      webSocketInitiatorType,
+		WebSocketMessages: messages,
+	}
+}
+
+// duration returns the milliseconds between two absolute performance timestamps, or -1 if either was not recorded.
+func duration(start, end float64) float64 {
+	if start <= 0 || end <= 0 || end < start {
+		return -1
+	}
+	return end - start
+}
+
+// timings maps the resource timing of a request to the phases of a HAR entry.
+// The send, wait and receive phases are required, so the whole request is reported as waiting
+// when the detailed timing is not available, as is the case for cross-origin requests.
+func timings(r resource) Timings {
+	t := Timings{
+		Blocked: duration(r.StartTimeAbs, r.DomainLookupStartAbs),
+		DNS:     duration(r.DomainLookupStartAbs, r.DomainLookupEndAbs),
+		Connect: duration(r.ConnectStartAbs, r.ConnectEndAbs),
+		SSL:     duration(r.SecureConnectionStartAbs, r.ConnectEndAbs),
+		Send:    0,
+		Wait:    duration(r.RequestStartAbs, r.ResponseStartAbs),
+		Receive: duration(r.ResponseStartAbs, r.ResponseEndAbs),
+	}
+	if t.Wait < 0 || t.Receive < 0 {
+		t.Wait = lo.Max([]float64{duration(r.StartTimeAbs, r.ResponseEndAbs), 0})
+		t.Receive = 0
+	}
+	return t
+}
+
+// headers converts the recorded headers, either an object or the raw header lines of an XHR, to sorted name-value pairs.
+func headers(raw any) []NameValue {
+	result := []NameValue{}
+	switch h := raw.(type) {
+	case map[string]interface{}:
+		for name, value := range h {
+			if text, ok := body(value); ok {
+				result = append(result, NameValue{Name: name, Value: text})
+			}
+		}
+		sort.Slice(result, func(i, j int) bool {
+			return result[i].Name < result[j].Name
+		})
+	case string:
+		for _, line := range strings.Split(h, "\n") {
+			name, value, found := strings.Cut(strings.TrimSpace(line), ":")
+			if found {
+				result = append(result, NameValue{Name: strings.TrimSpace(name), Value: strings.TrimSpace(value)})
+			}
+		}
+	}
+	return result
+}
+
+func header(headers []NameValue, name string) string {
+	for _, h := range headers {
+		if strings.EqualFold(h.Name, name) {
+			return h.Value
+		}
+	}
+	return ""
+}
+
+// body returns the text of a recorded body, which is either the captured string or the parsed JSON value.
+func body(value any) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, v != ""
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+		return string(data), true
+	}
+}
+
+func queryString(rawURL string) []NameValue {
+	result := []NameValue{}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return result
+	}
+	for name, values := range u.Query() {
+		for _, value := range values {
+			result = append(result, NameValue{Name: name, Value: value})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
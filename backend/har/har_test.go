@@ -0,0 +1,93 @@
+package har
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/highlight-run/highlight/backend/env"
+)
+
+func TestBuild(t *testing.T) {
+	var resources, webSocketEvents []interface{}
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"startTimeAbs": 1700000001000, "responseEndAbs": 1700000001250, "domainLookupStartAbs": 1700000001010, "domainLookupEndAbs": 1700000001020,
+		 "connectStartAbs": 1700000001020, "connectEndAbs": 1700000001060, "secureConnectionStartAbs": 1700000001040,
+		 "requestStartAbs": 1700000001060, "responseStartAbs": 1700000001200, "encodedBodySize": 20, "decodedBodySize": 40,
+		 "nextHopProtocol": "h2", "initiatorType": "fetch", "name": "https://api.example.com/users?page=2&sort=name",
+		 "requestResponsePairs": {
+			"request": {"id": "abc", "url": "https://api.example.com/users?page=2&sort=name", "verb": "post", "headers": {"Content-Type": "application/json"}, "body": "{\"name\":\"a\"}"},
+			"response": {"status": 201, "size": 42, "headers": "content-type: application/json\r\nx-request-id: 1\r\n", "body": {"id": 1}}
+		 }},
+		{"startTimeAbs": 1700000000500, "responseEndAbs": 1700000000600, "initiatorType": "script", "name": "https://cdn.example.com/app.js",
+		 "requestResponsePairs": {"request": {"id": "def"}, "response": {"status": 200}}},
+		{"socketId": "s1", "initiatorType": "websocket", "type": "open", "name": "wss://ws.example.com", "startTimeAbs": 1700000002000},
+		{"socketId": "s1", "initiatorType": "websocket", "type": "close", "name": "wss://ws.example.com", "responseEndAbs": 1700000005000},
+		{"startTimeAbs": 1700000010000, "responseEndAbs": 1700000010100, "initiatorType": "xmlhttprequest", "name": "https://api.example.com/late"}
+	]`), &resources))
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"socketId": "s1", "type": "sent", "name": "wss://ws.example.com", "timeStamp": 1700000003000, "size": 5, "message": "hello"},
+		{"socketId": "s1", "type": "received", "name": "wss://ws.example.com", "timeStamp": 1700000003500, "size": 5, "message": "world"},
+		{"socketId": "s1", "type": "error", "name": "wss://ws.example.com", "timeStamp": 1700000004000}
+	]`), &webSocketEvents))
+
+	ids, err := RequestIDs(resources)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"abc", "def"}, ids)
+
+	version := env.Config.Version
+	env.Config.Version = "abc123"
+	t.Cleanup(func() { env.Config.Version = version })
+
+	sessionStart := time.UnixMilli(1700000000000)
+	end := time.UnixMilli(1700000009000)
+	har, err := Build(resources, webSocketEvents, Options{
+		SessionStart: sessionStart,
+		EndTime:      &end,
+		TraceIDs:     map[string]bool{"abc": true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "1.2", har.Log.Version)
+	assert.Equal(t, Creator{Name: "Highlight", Version: "abc123"}, har.Log.Creator)
+
+	// entries are sorted by start time and limited to the time range
+	require.Len(t, har.Log.Entries, 3)
+	script, fetch, socket := har.Log.Entries[0], har.Log.Entries[1], har.Log.Entries[2]
+
+	assert.Equal(t, "GET", script.Request.Method)
+	assert.Equal(t, "https://cdn.example.com/app.js", script.Request.URL)
+	assert.Equal(t, "def", script.HighlightRequestID)
+	assert.Empty(t, script.TraceID)
+	assert.Equal(t, Timings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1, Wait: 100}, script.Timings)
+
+	assert.Equal(t, "2023-11-14T22:13:21Z", fetch.StartedDateTime)
+	assert.Equal(t, float64(250), fetch.Time)
+	assert.Equal(t, "POST", fetch.Request.Method)
+	assert.Equal(t, "h2", fetch.Request.HTTPVersion)
+	assert.Equal(t, []NameValue{{Name: "page", Value: "2"}, {Name: "sort", Value: "name"}}, fetch.Request.QueryString)
+	assert.Equal(t, &PostData{MimeType: "application/json", Text: `{"name":"a"}`}, fetch.Request.PostData)
+	assert.Equal(t, 201, fetch.Response.Status)
+	assert.Equal(t, "Created", fetch.Response.StatusText)
+	assert.Equal(t, []NameValue{{Name: "content-type", Value: "application/json"}, {Name: "x-request-id", Value: "1"}}, fetch.Response.Headers)
+	assert.Equal(t, Content{Size: 42, MimeType: "application/json", Text: `{"id":1}`}, fetch.Response.Content)
+	assert.Equal(t, 20, fetch.Response.BodySize)
+	assert.Equal(t, Timings{Blocked: 10, DNS: 10, Connect: 40, SSL: 20, Wait: 140, Receive: 50}, fetch.Timings)
+	assert.Equal(t, "abc", fetch.HighlightRequestID)
+	assert.Equal(t, "abc", fetch.TraceID)
+
+	assert.Equal(t, "wss://ws.example.com", socket.Request.URL)
+	assert.Equal(t, 101, socket.Response.Status)
+	assert.Equal(t, float64(3000), socket.Time)
+	assert.Equal(t, []WebSocketMessage{
+		{Type: "send", Time: 1700000003, Opcode: 1, Data: "hello"},
+		{Type: "receive", Time: 1700000003.5, Opcode: 1, Data: "world"},
+	}, socket.WebSocketMessages)
+
+	data, err := json.Marshal(har)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"_traceId":"abc"`)
+	assert.Contains(t, string(data), `"_webSocketMessages":[{"type":"send"`)
+}
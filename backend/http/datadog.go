@@ -0,0 +1,172 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	hlog "github.com/highlight/highlight/sdk/highlight-go/log"
+	log "github.com/sirupsen/logrus"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+const (
+	DatadogAPIKeyHeader     = "DD-API-KEY"
+	DatadogAPIKeyQueryParam = "dd-api-key"
+	// DatadogTagsAttribute holds the tags of a log that are not in the `key:value` format.
+	DatadogTagsAttribute = "ddtags"
+)
+
+// datadog reserved attributes, see https://docs.datadoghq.com/logs/log_configuration/attributes_naming_convention/#reserved-attributes
+var (
+	datadogMessageKeys   = []string{"message", "msg"}
+	datadogLevelKeys     = []string{"status", "level", "severity"}
+	datadogTimestampKeys = []string{"timestamp", "date", "@timestamp"}
+)
+
+func getDatadogAPIKey(r *http.Request) string {
+	if apiKey := r.Header.Get(DatadogAPIKeyHeader); apiKey != "" {
+		return apiKey
+	}
+	return r.URL.Query().Get(DatadogAPIKeyQueryParam)
+}
+
+// parseDatadogTags maps comma separated `key:value` tags onto attributes.
+func parseDatadogTags(tags string, attributes map[string]string) {
+	var bare []string
+	for _, tag := range strings.Split(tags, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if k, v, ok := strings.Cut(tag, ":"); ok && k != "" {
+			attributes[k] = v
+		} else {
+			bare = append(bare, tag)
+		}
+	}
+	if len(bare) > 0 {
+		attributes[DatadogTagsAttribute] = strings.Join(bare, ",")
+	}
+}
+
+// parseDatadogTimestamp parses a unix timestamp in milliseconds or an ISO8601 date.
+func parseDatadogTimestamp(ts string) (time.Time, bool) {
+	if ms, err := strconv.ParseFloat(ts, 64); err == nil {
+		return time.UnixMilli(int64(ms)), true
+	}
+	if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+func datadogEntryToLog(entry map[string]interface{}, serviceName string) hlog.Log {
+	attributes := make(map[string]string)
+	for k, v := range entry {
+		if k == "ddtags" {
+			if tags, ok := v.(string); ok {
+				parseDatadogTags(tags, attributes)
+			}
+			continue
+		}
+		for key, value := range hlog.FormatAttributes(k, v) {
+			attributes[key] = value
+		}
+	}
+
+	lg := hlog.Log{
+		Attributes: attributes,
+		Message:    popAttribute(attributes, datadogMessageKeys),
+		Level:      strings.ToLower(popAttribute(attributes, datadogLevelKeys)),
+		Timestamp:  time.Now().UTC().Format(hlog.TimestampFormat),
+	}
+	if lg.Level == "" {
+		lg.Level = model.LogLevelInfo.String()
+	}
+	if t, ok := parseDatadogTimestamp(popAttribute(attributes, datadogTimestampKeys)); ok {
+		lg.Timestamp = t.UTC().Format(hlog.TimestampFormat)
+	}
+	if svc := popAttribute(attributes, []string{"service"}); serviceName == "" {
+		serviceName = svc
+	}
+	if serviceName != "" {
+		attributes[string(semconv.ServiceNameKey)] = serviceName
+	}
+	if host := popAttribute(attributes, []string{"hostname", "host"}); host != "" {
+		attributes[string(semconv.HostNameKey)] = host
+	}
+	if source := popAttribute(attributes, []string{"ddsource"}); source != "" {
+		attributes[LogSourceAttribute] = source
+	}
+	return lg
+}
+
+// parseDatadogLogs parses the logs intake payload, which is either a single log or an array of logs.
+func parseDatadogLogs(body []byte) ([]map[string]interface{}, error) {
+	body = bytes.TrimSpace(body)
+	if bytes.HasPrefix(body, []byte("[")) {
+		var entries []map[string]interface{}
+		if err := json.Unmarshal(body, &entries); err != nil {
+			return nil, err
+		}
+		return entries, nil
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal(body, &entry); err != nil {
+		return nil, err
+	}
+	return []map[string]interface{}{entry}, nil
+}
+
+// HandleDatadogValidate implements the API key validation used by the datadog agent on startup.
+func HandleDatadogValidate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	// only a registered api key is valid, requests without one are not logs of a known project
+	apiKey := getDatadogAPIKey(r)
+	if _, _, err := getTokenProjectAndService(r, apiKey); apiKey == "" || err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors":["Forbidden"]}`))
+		return
+	}
+	_, _ = w.Write([]byte(`{"valid":true}`))
+}
+
+// HandleDatadogLogs implements the datadog logs intake API.
+// https://docs.datadoghq.com/api/latest/logs/#send-logs
+func HandleDatadogLogs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	projectID, serviceName, err := getTokenProjectAndService(r, getDatadogAPIKey(r))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	body, err := GetBody(ctx, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	entries, err := parseDatadogLogs(body)
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Error("invalid datadog logs payload")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, entry := range entries {
+		if err := hlog.SubmitHTTPLog(ctx, tracer, projectID, datadogEntryToLog(entry, serviceName)); err != nil {
+			log.WithContext(ctx).WithError(err).Error("failed to submit log")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = w.Write([]byte("{}"))
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"bytes"
+	"compress/zlib"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const DatadogLogsJson = `[
+  {"ddsource":"nginx","ddtags":"env:staging,version:5.1,canary","hostname":"i-012345678","message":"GET /checkout 500","service":"payment","status":"Error","timestamp":1704067200123,"http":{"status_code":500}},
+  {"message":"worker started","date":"2024-01-01T00:00:05Z"}
+]`
+
+func TestParseDatadogTags(t *testing.T) {
+	attributes := map[string]string{}
+	parseDatadogTags("env:prod, team:core,, beta,url:http://a", attributes)
+	assert.Equal(t, map[string]string{"env": "prod", "team": "core", "url": "http://a", DatadogTagsAttribute: "beta"}, attributes)
+}
+
+func TestHandleDatadogLogs(t *testing.T) {
+	var b bytes.Buffer
+	zw := zlib.NewWriter(&b)
+	_, _ = zw.Write([]byte(DatadogLogsJson))
+	_ = zw.Close()
+
+	r := httptest.NewRequest("POST", "/api/v2/logs", &b)
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Content-Encoding", "deflate")
+	r.Header.Set(DatadogAPIKeyHeader, testDatadogAPIKey)
+	w := httptest.NewRecorder()
+	HandleDatadogLogs(w, r)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	events := lastLogEvents(2)
+	assert.Equal(t, time.UnixMilli(1704067200123).UTC(), events[0].Time.UTC())
+	assert.Equal(t, "GET /checkout 500", eventAttribute(events[0], "log.message"))
+	assert.Equal(t, "error", eventAttribute(events[0], "log.severity"))
+	assert.Equal(t, "payment", eventAttribute(events[0], "service.name"))
+	assert.Equal(t, "i-012345678", eventAttribute(events[0], "host.name"))
+	assert.Equal(t, "nginx", eventAttribute(events[0], LogSourceAttribute))
+	assert.Equal(t, "staging", eventAttribute(events[0], "env"))
+	assert.Equal(t, "5.1", eventAttribute(events[0], "version"))
+	assert.Equal(t, "canary", eventAttribute(events[0], DatadogTagsAttribute))
+	assert.Equal(t, "500", eventAttribute(events[0], "http.status_code"))
+
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 5, 0, time.UTC), events[1].Time.UTC())
+	assert.Equal(t, "info", eventAttribute(events[1], "log.severity"))
+}
+
+func TestHandleDatadogLogsSingle(t *testing.T) {
+	r := httptest.NewRequest("POST", "/api/v2/logs?dd-api-key="+testDatadogAPIKey+"&service=override", strings.NewReader(`{"message":"hi","service":"payment"}`))
+	w := httptest.NewRecorder()
+	HandleDatadogLogs(w, r)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "override", eventAttribute(lastLogEvents(1)[0], "service.name"))
+}
+
+func TestHandleDatadogValidate(t *testing.T) {
+	r := httptest.NewRequest("GET", "/api/v1/validate", nil)
+	r.Header.Set(DatadogAPIKeyHeader, testDatadogAPIKey)
+	w := httptest.NewRecorder()
+	HandleDatadogValidate(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	HandleDatadogValidate(w, httptest.NewRequest("GET", "/api/v1/validate", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// project ids are not api keys
+	r = httptest.NewRequest("GET", "/api/v1/validate", nil)
+	r.Header.Set(DatadogAPIKeyHeader, "1")
+	w = httptest.NewRecorder()
+	HandleDatadogValidate(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
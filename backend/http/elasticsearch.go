@@ -0,0 +1,220 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	hlog "github.com/highlight/highlight/sdk/highlight-go/log"
+	e "github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+// ElasticsearchIndexAttribute records the index a document was sent to.
+const ElasticsearchIndexAttribute = "elasticsearch.index"
+
+// elasticsearchVersion is reported to shippers that check compatibility before sending data.
+const elasticsearchVersion = "8.11.0"
+
+// elasticsearch documents keys, in order of precedence, that hold the log message, timestamp and level
+var (
+	esMessageKeys   = []string{"message", "msg", "log", "@message"}
+	esTimestampKeys = []string{"@timestamp", "timestamp", "time"}
+	esLevelKeys     = []string{"log.level", "level", "severity", "loglevel"}
+	esServiceKeys   = []string{"service.name", "service"}
+)
+
+type esBulkAction struct {
+	Index string `json:"_index"`
+	ID    string `json:"_id"`
+}
+
+type esBulkItem struct {
+	Index  string `json:"_index"`
+	ID     string `json:"_id"`
+	Status int    `json:"status"`
+	Result string `json:"result,omitempty"`
+}
+
+func esParseTimestamp(ts string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999Z0700", "2006-01-02 15:04:05.999999999"} {
+		if t, err := time.Parse(layout, ts); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// esDocumentToLog maps an elasticsearch document onto a log, flattening the remaining fields into attributes.
+func esDocumentToLog(raw []byte, index, serviceName string) (hlog.Log, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return hlog.Log{}, err
+	}
+
+	attributes := make(map[string]string)
+	for k, v := range doc {
+		for key, value := range hlog.FormatAttributes(k, v) {
+			attributes[key] = value
+		}
+	}
+
+	lg := hlog.Log{
+		Attributes: attributes,
+		Message:    popAttribute(attributes, esMessageKeys),
+		Level:      strings.ToLower(popAttribute(attributes, esLevelKeys)),
+		Timestamp:  time.Now().UTC().Format(hlog.TimestampFormat),
+	}
+	if lg.Level == "" {
+		lg.Level = model.LogLevelInfo.String()
+	}
+	if t, ok := esParseTimestamp(popAttribute(attributes, esTimestampKeys)); ok {
+		lg.Timestamp = t.UTC().Format(hlog.TimestampFormat)
+	}
+	if svc := popAttribute(attributes, esServiceKeys); serviceName == "" {
+		serviceName = svc
+	}
+	if serviceName == "" {
+		serviceName = index
+	}
+	if serviceName != "" {
+		attributes[string(semconv.ServiceNameKey)] = serviceName
+	}
+	if index != "" {
+		attributes[ElasticsearchIndexAttribute] = index
+	}
+	return lg, nil
+}
+
+// HandleElasticsearchInfo responds like the elasticsearch root endpoint so that shippers such as
+// filebeat, fluentd and vector pass their version check.
+func HandleElasticsearchInfo(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"name":         "highlight",
+		"cluster_name": "highlight",
+		"version": map[string]interface{}{
+			"number":                              elasticsearchVersion,
+			"build_flavor":                        "default",
+			"minimum_wire_compatibility_version":  "7.17.0",
+			"minimum_index_compatibility_version": "7.0.0",
+		},
+		"tagline": "You Know, for Search",
+	})
+}
+
+// HandleElasticsearchBulk implements the index and create actions of the elasticsearch bulk API.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-bulk.html
+func HandleElasticsearchBulk(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	projectID, serviceName, err := getProjectAndService(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	body, err := GetBody(ctx, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	start := time.Now()
+	var items []map[string]esBulkItem
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(body)+1)
+	nextLine := func() ([]byte, bool) {
+		for scanner.Scan() {
+			if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+				return line, true
+			}
+		}
+		return nil, false
+	}
+
+	for {
+		line, ok := nextLine()
+		if !ok {
+			break
+		}
+		var action map[string]esBulkAction
+		if err := json.Unmarshal(line, &action); err != nil || len(action) != 1 {
+			log.WithContext(ctx).WithError(err).Error("invalid elasticsearch bulk action")
+			http.Error(w, "invalid elasticsearch bulk action", http.StatusBadRequest)
+			return
+		}
+
+		for op, meta := range action {
+			if meta.Index == "" {
+				meta.Index = chi.URLParam(r, "index")
+			}
+			if meta.ID == "" {
+				meta.ID = uuid.New().String()
+			}
+			item := esBulkItem{Index: meta.Index, ID: meta.ID}
+
+			switch op {
+			case "index", "create":
+				doc, ok := nextLine()
+				if !ok {
+					http.Error(w, "elasticsearch bulk action is missing a document", http.StatusBadRequest)
+					return
+				}
+				lg, err := esDocumentToLog(doc, meta.Index, serviceName)
+				if err == nil {
+					err = hlog.SubmitHTTPLog(ctx, tracer, projectID, lg)
+				}
+				if err != nil {
+					log.WithContext(ctx).WithError(err).Error("failed to submit elasticsearch document")
+					item.Status = http.StatusBadRequest
+				} else {
+					item.Status = http.StatusCreated
+					item.Result = "created"
+				}
+			case "update":
+				// logs are immutable; the partial document is acknowledged but dropped
+				if _, ok := nextLine(); !ok {
+					http.Error(w, "elasticsearch bulk action is missing a document", http.StatusBadRequest)
+					return
+				}
+				item.Status = http.StatusOK
+				item.Result = "noop"
+			case "delete":
+				item.Status = http.StatusOK
+				item.Result = "noop"
+			default:
+				log.WithContext(ctx).WithError(e.Errorf("unsupported action %s", op)).Error("invalid elasticsearch bulk action")
+				http.Error(w, "unsupported elasticsearch bulk action "+op, http.StatusBadRequest)
+				return
+			}
+			items = append(items, map[string]esBulkItem{op: item})
+		}
+	}
+
+	hasErrors := false
+	for _, item := range items {
+		for _, i := range item {
+			hasErrors = hasErrors || i.Status >= http.StatusBadRequest
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	_ = json.NewEncoder(w).Encode(struct {
+		Took   int64                   `json:"took"`
+		Errors bool                    `json:"errors"`
+		Items  []map[string]esBulkItem `json:"items"`
+	}{
+		Took:   time.Since(start).Milliseconds(),
+		Errors: hasErrors,
+		Items:  items,
+	})
+}
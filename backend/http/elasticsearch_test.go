@@ -0,0 +1,77 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+)
+
+const ElasticsearchBulkNDJson = `{"index":{"_index":"filebeat-8.11.0","_id":"a1"}}
+{"@timestamp":"2024-01-01T00:00:00.250Z","message":"user signed in","log":{"level":"WARN"},"host":{"name":"web-1"},"bytes":512}
+
+{"create":{}}
+{"msg":"created without an index","service":"worker"}
+{"delete":{"_id":"a1"}}
+{"update":{"_id":"a1"}}
+{"doc":{"message":"ignored"}}
+`
+
+func TestHandleElasticsearchBulk(t *testing.T) {
+	r := httptest.NewRequest("POST", "/v1/logs/elasticsearch/logs-app/_bulk", strings.NewReader(ElasticsearchBulkNDJson))
+	r.Header.Set("Content-Type", "application/x-ndjson")
+	r.SetBasicAuth("1", "")
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("index", "logs-app")
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+	w := httptest.NewRecorder()
+	HandleElasticsearchBulk(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Errors bool
+		Items  []map[string]esBulkItem
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.False(t, resp.Errors)
+	assert.Len(t, resp.Items, 4)
+	assert.Equal(t, esBulkItem{Index: "filebeat-8.11.0", ID: "a1", Status: http.StatusCreated, Result: "created"}, resp.Items[0]["index"])
+	assert.Equal(t, "logs-app", resp.Items[1]["create"].Index)
+	assert.Equal(t, http.StatusOK, resp.Items[2]["delete"].Status)
+	assert.Equal(t, "noop", resp.Items[3]["update"].Result)
+
+	events := lastLogEvents(2)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 250e6, time.UTC), events[0].Time.UTC())
+	assert.Equal(t, "user signed in", eventAttribute(events[0], "log.message"))
+	assert.Equal(t, "warn", eventAttribute(events[0], "log.severity"))
+	assert.Equal(t, "web-1", eventAttribute(events[0], "host.name"))
+	assert.Equal(t, "512", eventAttribute(events[0], "bytes"))
+	assert.Equal(t, "filebeat-8.11.0", eventAttribute(events[0], "service.name"))
+	assert.Equal(t, "filebeat-8.11.0", eventAttribute(events[0], ElasticsearchIndexAttribute))
+
+	assert.Equal(t, "created without an index", eventAttribute(events[1], "log.message"))
+	assert.Equal(t, "info", eventAttribute(events[1], "log.severity"))
+	assert.Equal(t, "worker", eventAttribute(events[1], "service.name"))
+}
+
+func TestHandleElasticsearchBulkInvalid(t *testing.T) {
+	for _, body := range []string{`{"index":{}}`, `{"upsert":{}}` + "\n{}", `not json`} {
+		r := httptest.NewRequest("POST", "/v1/logs/elasticsearch/_bulk?project=1", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		HandleElasticsearchBulk(w, r)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
+
+func TestHandleElasticsearchInfo(t *testing.T) {
+	w := httptest.NewRecorder()
+	HandleElasticsearchInfo(w, httptest.NewRequest("GET", "/v1/logs/elasticsearch/", nil))
+	assert.Equal(t, "Elasticsearch", w.Header().Get("X-Elastic-Product"))
+	assert.Contains(t, w.Body.String(), elasticsearchVersion)
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"compress/gzip"
+	"compress/zlib"
 	"context"
 	"encoding/json"
 	"errors"
@@ -28,6 +29,10 @@ const (
 	LogDrainServiceQueryParam = "service"
 	LogDrainProjectHeader     = "x-highlight-project"
 	LogDrainServiceHeader     = "x-highlight-service"
+	// LogSourceAttribute records the source that a log shipper collected a log from
+	LogSourceAttribute = "source"
+	// LokiTenantHeader is set by loki clients configured with a `tenant_id`
+	LokiTenantHeader = "X-Scope-OrgID"
 )
 
 func GetBody(ctx context.Context, r *http.Request) ([]byte, error) {
@@ -46,6 +51,11 @@ func GetBody(ctx context.Context, r *http.Request) ([]byte, error) {
 		}
 	} else if enc == "snappy" {
 		reader = snappy.NewReader(r.Body)
+	} else if enc == "deflate" {
+		reader, err = zlib.NewReader(r.Body)
+		if err != nil {
+			return nil, err
+		}
 	} else {
 		reader = r.Body
 	}
@@ -93,6 +103,77 @@ func getQueryStringParams(r *http.Request) (int, string, error) {
 	return projectID, qs.Get(LogDrainServiceQueryParam), nil
 }
 
+// getProjectAndService resolves the project and service of a log drain request from the
+// query string, the highlight headers, the loki tenant header or the basic auth username,
+// since not every log shipper supports customizing all of them.
+func getProjectAndService(r *http.Request) (int, string, error) {
+	if projectID, serviceName, err := getQueryStringParams(r); err == nil && projectID != 0 {
+		return projectID, serviceName, nil
+	}
+
+	projectVerboseID := r.Header.Get(LogDrainProjectHeader)
+	if projectVerboseID == "" {
+		projectVerboseID = r.Header.Get(LokiTenantHeader)
+	}
+	if projectVerboseID == "" {
+		projectVerboseID, _, _ = r.BasicAuth()
+	}
+	if projectVerboseID == "" {
+		return 0, "", errors.New("no highlight project provided")
+	}
+	projectID, err := model2.FromVerboseID(projectVerboseID)
+	if err != nil {
+		log.WithContext(r.Context()).WithError(err).WithField("projectVerboseID", projectVerboseID).Error("failed to parse highlight project id from http logs request")
+		return 0, "", err
+	}
+	serviceName := r.URL.Query().Get(LogDrainServiceQueryParam)
+	if svc := r.Header.Get(LogDrainServiceHeader); svc != "" {
+		serviceName = svc
+	}
+	return projectID, serviceName, nil
+}
+
+// IngestTokenStore resolves the project of the vendor specific credentials, such as a datadog api key
+// or a splunk HEC token, that log forwarders are configured with.
+type IngestTokenStore interface {
+	GetIngestTokenProject(ctx context.Context, token string) (int, error)
+}
+
+var ingestTokens IngestTokenStore
+
+// getTokenProjectAndService resolves the project of a log drain request authenticated with a
+// vendor specific token, which is registered as an API token with the LogsIngest scope.
+// Requests without a token fall back to the regular log drain project parameters.
+func getTokenProjectAndService(r *http.Request, token string) (int, string, error) {
+	if token == "" {
+		return getProjectAndService(r)
+	}
+	if ingestTokens == nil {
+		return 0, "", errors.New("invalid token")
+	}
+	projectID, err := ingestTokens.GetIngestTokenProject(r.Context(), token)
+	if err != nil {
+		log.WithContext(r.Context()).WithError(err).Warn("failed to find the highlight project of a log drain token")
+		return 0, "", errors.New("invalid token")
+	}
+	serviceName := r.URL.Query().Get(LogDrainServiceQueryParam)
+	if svc := r.Header.Get(LogDrainServiceHeader); svc != "" {
+		serviceName = svc
+	}
+	return projectID, serviceName, nil
+}
+
+// popAttribute removes and returns the first non-empty value of keys from the flattened attributes.
+func popAttribute(attributes map[string]string, keys []string) string {
+	for _, k := range keys {
+		if v, ok := attributes[k]; ok && v != "" {
+			delete(attributes, k)
+			return v
+		}
+	}
+	return ""
+}
+
 func parsePinoLevel(level uint8) string {
 	switch level {
 	case 10:
@@ -306,12 +387,31 @@ func HandleRawLog(w http.ResponseWriter, r *http.Request) {
 
 var tracer trace.Tracer
 
-func Listen(r *chi.Mux, t trace.Tracer) {
+func Listen(r *chi.Mux, t trace.Tracer, tokens IngestTokenStore) {
 	tracer = t
+	ingestTokens = tokens
 	r.Route("/v1", func(r chi.Router) {
 		r.Use(highlightChi.Middleware)
 		r.HandleFunc("/logs/raw", HandleRawLog)
 		r.HandleFunc("/logs/json", HandleJSONLog)
 		r.HandleFunc("/logs/firehose", HandleFirehoseLog)
+		r.Post("/logs/loki/api/v1/push", HandleLokiPush)
+		r.Route("/logs/elasticsearch", func(r chi.Router) {
+			// shippers check the cluster version before sending any data
+			r.Get("/", HandleElasticsearchInfo)
+			r.Post("/_bulk", HandleElasticsearchBulk)
+			r.Post("/{index}/_bulk", HandleElasticsearchBulk)
+		})
+	})
+	// splunk and datadog forwarders only allow configuring the host, so their paths are served as is
+	r.Route("/services/collector", func(r chi.Router) {
+		r.Use(highlightChi.Middleware)
+		r.Get("/health", HandleSplunkHealth)
+		r.Get("/health/1.0", HandleSplunkHealth)
+		r.Post("/", HandleSplunkEvent)
+		r.Post("/event", HandleSplunkEvent)
+		r.Post("/event/1.0", HandleSplunkEvent)
 	})
+	r.With(highlightChi.Middleware).Get("/api/v1/validate", HandleDatadogValidate)
+	r.With(highlightChi.Middleware).Post("/api/v2/logs", HandleDatadogLogs)
 }
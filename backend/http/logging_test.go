@@ -3,7 +3,9 @@ package http
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
@@ -113,10 +115,25 @@ func (m *MockResponseWriter) WriteHeader(statusCode int) {
 
 var spanRecorder = tracetest.NewSpanRecorder()
 
+const (
+	testDatadogAPIKey = "0123456789abcdef0123456789abcdef"
+	testSplunkToken   = "12345678-9abc-def0-1234-56789abcdef0"
+)
+
+type mockIngestTokens map[string]int
+
+func (m mockIngestTokens) GetIngestTokenProject(_ context.Context, token string) (int, error) {
+	if projectID, ok := m[token]; ok {
+		return projectID, nil
+	}
+	return 0, errors.New("invalid ingest token")
+}
+
 func TestMain(m *testing.M) {
 	tracer = sdktrace.NewTracerProvider(
 		sdktrace.WithSpanProcessor(spanRecorder),
 	).Tracer("test")
+	ingestTokens = mockIngestTokens{testDatadogAPIKey: 1, testSplunkToken: 1}
 
 	code := m.Run()
 	os.Exit(code)
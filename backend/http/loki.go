@@ -0,0 +1,301 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/highlight/highlight/sdk/highlight-go"
+	hlog "github.com/highlight/highlight/sdk/highlight-go/log"
+	e "github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// lokiLevelLabels are the stream labels checked for the log level, in order of precedence.
+var lokiLevelLabels = []string{"level", "detected_level", "severity", "lvl"}
+
+// lokiServiceLabels are the stream labels checked for the service name, in order of precedence.
+// loki derives the `service_name` label from the same set of labels.
+var lokiServiceLabels = []string{"service_name", "service", "app", "application", "job", "container"}
+
+type lokiEntry struct {
+	Timestamp time.Time
+	Line      string
+	Metadata  map[string]string
+}
+
+type lokiStream struct {
+	Labels  map[string]string
+	Entries []lokiEntry
+}
+
+// parseLokiLabels parses a stream label set in the prometheus format, i.e. `{job="api", env="prod"}`.
+func parseLokiLabels(s string) (map[string]string, error) {
+	labels := make(map[string]string)
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") {
+		return nil, e.Errorf("invalid loki labels %q", s)
+	}
+	s = strings.TrimSpace(s[1 : len(s)-1])
+	for s != "" {
+		name, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			return nil, e.Errorf("invalid loki label %q", s)
+		}
+		rest = strings.TrimSpace(rest)
+		quoted, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			return nil, e.Wrapf(err, "invalid loki label value %q", rest)
+		}
+		value, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, e.Wrapf(err, "invalid loki label value %q", quoted)
+		}
+		labels[strings.TrimSpace(name)] = value
+		s = strings.TrimPrefix(strings.TrimSpace(rest[len(quoted):]), ",")
+		s = strings.TrimSpace(s)
+	}
+	return labels, nil
+}
+
+// Minimal decoder for the loki push protobuf (logproto.PushRequest).
+// Only the fields needed for ingest are decoded; unknown fields are skipped.
+
+func walkLokiProto(b []byte, fn func(num protowire.Number, v uint64, payload []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		var v uint64
+		var payload []byte
+		switch typ {
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			payload, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		if err := fn(num, v, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unmarshalLokiEntry(b []byte) (entry lokiEntry, err error) {
+	entry.Metadata = make(map[string]string)
+	err = walkLokiProto(b, func(num protowire.Number, _ uint64, payload []byte) error {
+		switch num {
+		case 1:
+			var seconds, nanos uint64
+			if err := walkLokiProto(payload, func(num protowire.Number, v uint64, _ []byte) error {
+				switch num {
+				case 1:
+					seconds = v
+				case 2:
+					nanos = v
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			entry.Timestamp = time.Unix(int64(seconds), int64(int32(nanos)))
+		case 2:
+			entry.Line = string(payload)
+		case 3:
+			var name, value string
+			if err := walkLokiProto(payload, func(num protowire.Number, _ uint64, payload []byte) error {
+				switch num {
+				case 1:
+					name = string(payload)
+				case 2:
+					value = string(payload)
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			entry.Metadata[name] = value
+		}
+		return nil
+	})
+	return
+}
+
+func unmarshalLokiPushRequest(b []byte) (streams []lokiStream, err error) {
+	err = walkLokiProto(b, func(num protowire.Number, _ uint64, payload []byte) error {
+		if num != 1 {
+			return nil
+		}
+		var stream lokiStream
+		if err := walkLokiProto(payload, func(num protowire.Number, _ uint64, payload []byte) error {
+			switch num {
+			case 1:
+				labels, err := parseLokiLabels(string(payload))
+				if err != nil {
+					return err
+				}
+				stream.Labels = labels
+			case 2:
+				entry, err := unmarshalLokiEntry(payload)
+				if err != nil {
+					return err
+				}
+				stream.Entries = append(stream.Entries, entry)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		streams = append(streams, stream)
+		return nil
+	})
+	return
+}
+
+// unmarshalLokiPushJSON parses the JSON push format, where values are
+// `[<unix epoch in nanoseconds>, <log line>, <optional structured metadata>]` tuples.
+func unmarshalLokiPushJSON(b []byte) ([]lokiStream, error) {
+	var req struct {
+		Streams []struct {
+			Stream map[string]string   `json:"stream"`
+			Values [][]json.RawMessage `json:"values"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(b, &req); err != nil {
+		return nil, err
+	}
+
+	var streams []lokiStream
+	for _, s := range req.Streams {
+		stream := lokiStream{Labels: s.Stream}
+		for _, value := range s.Values {
+			if len(value) < 2 {
+				return nil, e.New("loki values must contain a timestamp and a log line")
+			}
+			var ts string
+			if err := json.Unmarshal(value[0], &ts); err != nil {
+				return nil, e.Wrap(err, "invalid loki timestamp")
+			}
+			ns, err := strconv.ParseInt(ts, 10, 64)
+			if err != nil {
+				return nil, e.Wrap(err, "invalid loki timestamp")
+			}
+			entry := lokiEntry{Timestamp: time.Unix(0, ns), Metadata: map[string]string{}}
+			if err := json.Unmarshal(value[1], &entry.Line); err != nil {
+				return nil, e.Wrap(err, "invalid loki log line")
+			}
+			if len(value) > 2 {
+				if err := json.Unmarshal(value[2], &entry.Metadata); err != nil {
+					return nil, e.Wrap(err, "invalid loki structured metadata")
+				}
+			}
+			stream.Entries = append(stream.Entries, entry)
+		}
+		streams = append(streams, stream)
+	}
+	return streams, nil
+}
+
+func lokiStreamLogs(stream lokiStream, serviceName string) []hlog.Log {
+	level := model.LogLevelInfo.String()
+	for _, key := range lokiLevelLabels {
+		if l := stream.Labels[key]; l != "" {
+			level = strings.ToLower(l)
+			break
+		}
+	}
+	if serviceName == "" {
+		for _, key := range lokiServiceLabels {
+			if svc := stream.Labels[key]; svc != "" {
+				serviceName = svc
+				break
+			}
+		}
+	}
+
+	var logs []hlog.Log
+	for _, entry := range stream.Entries {
+		lg := hlog.Log{
+			Attributes: make(map[string]string, len(stream.Labels)+len(entry.Metadata)+1),
+			Message:    entry.Line,
+			Timestamp:  entry.Timestamp.UTC().Format(hlog.TimestampFormat),
+			Level:      level,
+		}
+		for k, v := range stream.Labels {
+			lg.Attributes[k] = v
+		}
+		for k, v := range entry.Metadata {
+			lg.Attributes[k] = v
+		}
+		if serviceName != "" {
+			lg.Attributes[string(semconv.ServiceNameKey)] = serviceName
+		}
+		logs = append(logs, lg)
+	}
+	return logs
+}
+
+// HandleLokiPush implements the loki push API (`/loki/api/v1/push`) used by promtail and grafana alloy.
+// https://grafana.com/docs/loki/latest/reference/loki-http-api/#ingest-logs
+func HandleLokiPush(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	projectID, serviceName, err := getProjectAndService(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	body, err := GetBody(ctx, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	span, _ := highlight.StartTrace(ctx, "http.loki.parse")
+	var streams []lokiStream
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		streams, err = unmarshalLokiPushJSON(body)
+	} else {
+		// the protobuf payload uses the snappy block format rather than the framed format handled by `GetBody`
+		var output []byte
+		if output, err = snappy.Decode(nil, body); err == nil {
+			streams, err = unmarshalLokiPushRequest(output)
+		}
+	}
+	span.RecordError(err)
+	highlight.EndTrace(span)
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Error("invalid loki push request")
+		http.Error(w, fmt.Sprintf("invalid loki push request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, stream := range streams {
+		for _, lg := range lokiStreamLogs(stream, serviceName) {
+			if err := hlog.SubmitHTTPLog(ctx, tracer, projectID, lg); err != nil {
+				log.WithContext(ctx).WithError(err).Error("failed to submit log")
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
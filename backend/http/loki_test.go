@@ -0,0 +1,110 @@
+package http
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const LokiPushJson = `{"streams":[{"stream":{"job":"api","level":"WARN","env":"prod"},"values":[["1704067200000000000","first line"],["1704067201500000000","second line",{"trace_id":"abc"}]]}]}`
+
+func lastLogEvents(n int) []sdktrace.Event {
+	spans := spanRecorder.Ended()
+	return lo.Map(spans[len(spans)-n:], func(span sdktrace.ReadOnlySpan, _ int) sdktrace.Event {
+		return span.Events()[0]
+	})
+}
+
+func eventAttribute(event sdktrace.Event, key string) string {
+	attr, _ := lo.Find(event.Attributes, func(item attribute.KeyValue) bool {
+		return string(item.Key) == key
+	})
+	return attr.Value.AsString()
+}
+
+func TestParseLokiLabels(t *testing.T) {
+	labels, err := parseLokiLabels(`{job="api", path="/a \"b\"",env="prod"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"job": "api", "path": `/a "b"`, "env": "prod"}, labels)
+
+	labels, err = parseLokiLabels(`{}`)
+	assert.NoError(t, err)
+	assert.Empty(t, labels)
+
+	for _, invalid := range []string{`job="api"`, `{job}`, `{job="api}`} {
+		_, err = parseLokiLabels(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestHandleLokiPushJSON(t *testing.T) {
+	r, _ := http.NewRequest("POST", "/v1/logs/loki/api/v1/push", strings.NewReader(LokiPushJson))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set(LokiTenantHeader, "1")
+	w := &MockResponseWriter{}
+	HandleLokiPush(w, r)
+	assert.Equal(t, http.StatusNoContent, w.statusCode)
+
+	events := lastLogEvents(2)
+	assert.Equal(t, time.Unix(1704067200, 0).UTC(), events[0].Time)
+	assert.Equal(t, "first line", eventAttribute(events[0], "log.message"))
+	assert.Equal(t, "warn", eventAttribute(events[0], "log.severity"))
+	assert.Equal(t, "api", eventAttribute(events[0], "service.name"))
+	assert.Equal(t, "prod", eventAttribute(events[0], "env"))
+	assert.Equal(t, time.Unix(1704067201, 5e8).UTC(), events[1].Time)
+	assert.Equal(t, "abc", eventAttribute(events[1], "trace_id"))
+}
+
+func TestHandleLokiPushProtobuf(t *testing.T) {
+	var timestamp, label, entry, stream, req []byte
+	timestamp = protowire.AppendTag(timestamp, 1, protowire.VarintType)
+	timestamp = protowire.AppendVarint(timestamp, 1704067200)
+	timestamp = protowire.AppendTag(timestamp, 2, protowire.VarintType)
+	timestamp = protowire.AppendVarint(timestamp, 1000)
+	label = protowire.AppendTag(label, 1, protowire.BytesType)
+	label = protowire.AppendString(label, "pod")
+	label = protowire.AppendTag(label, 2, protowire.BytesType)
+	label = protowire.AppendString(label, "api-1")
+	entry = protowire.AppendTag(entry, 1, protowire.BytesType)
+	entry = protowire.AppendBytes(entry, timestamp)
+	entry = protowire.AppendTag(entry, 2, protowire.BytesType)
+	entry = protowire.AppendString(entry, "hello from promtail")
+	entry = protowire.AppendTag(entry, 3, protowire.BytesType)
+	entry = protowire.AppendBytes(entry, label)
+	stream = protowire.AppendTag(stream, 1, protowire.BytesType)
+	stream = protowire.AppendString(stream, `{service_name="checkout", level="error"}`)
+	stream = protowire.AppendTag(stream, 2, protowire.BytesType)
+	stream = protowire.AppendBytes(stream, entry)
+	req = protowire.AppendTag(req, 1, protowire.BytesType)
+	req = protowire.AppendBytes(req, stream)
+
+	r, _ := http.NewRequest("POST", "/v1/logs/loki/api/v1/push?project=1&service=svc", bytes.NewReader(snappy.Encode(nil, req)))
+	r.Header.Set("Content-Type", "application/x-protobuf")
+	w := &MockResponseWriter{}
+	HandleLokiPush(w, r)
+	assert.Equal(t, http.StatusNoContent, w.statusCode)
+
+	event := lastLogEvents(1)[0]
+	assert.Equal(t, time.Unix(1704067200, 0).UTC(), event.Time)
+	assert.Equal(t, "hello from promtail", eventAttribute(event, "log.message"))
+	assert.Equal(t, "error", eventAttribute(event, "log.severity"))
+	assert.Equal(t, "svc", eventAttribute(event, "service.name"))
+	assert.Equal(t, "api-1", eventAttribute(event, "pod"))
+}
+
+func TestHandleLokiPushUnauthorized(t *testing.T) {
+	r, _ := http.NewRequest("POST", "/v1/logs/loki/api/v1/push", strings.NewReader(LokiPushJson))
+	r.Header.Set("Content-Type", "application/json")
+	w := &MockResponseWriter{}
+	HandleLokiPush(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.statusCode)
+}
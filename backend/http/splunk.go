@@ -0,0 +1,204 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	hlog "github.com/highlight/highlight/sdk/highlight-go/log"
+	e "github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+const (
+	SplunkSourceTypeAttribute = "sourcetype"
+	SplunkIndexAttribute      = "splunk.index"
+)
+
+// splunk HEC status codes, see
+// https://docs.splunk.com/Documentation/Splunk/latest/Data/TroubleshootHTTPEventCollector
+const (
+	splunkCodeSuccess       = 0
+	splunkCodeTokenRequired = 2
+	splunkCodeInvalidToken  = 4
+	splunkCodeNoData        = 5
+	splunkCodeInvalidFormat = 6
+	splunkCodeHealthy       = 17
+)
+
+var (
+	splunkMessageKeys = []string{"message", "msg", "log"}
+	splunkLevelKeys   = []string{"level", "severity", "log.level", "loglevel"}
+	splunkServiceKeys = []string{"service.name", "service"}
+)
+
+// splunkEvent is a HEC event, where `event` is either a raw string or a JSON object.
+type splunkEvent struct {
+	Time       json.RawMessage        `json:"time"`
+	Host       string                 `json:"host"`
+	Source     string                 `json:"source"`
+	SourceType string                 `json:"sourcetype"`
+	Index      string                 `json:"index"`
+	Event      interface{}            `json:"event"`
+	Fields     map[string]interface{} `json:"fields"`
+}
+
+func writeSplunkResponse(w http.ResponseWriter, status int, code int, text string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(struct {
+		Text string `json:"text"`
+		Code int    `json:"code"`
+	}{Text: text, Code: code})
+}
+
+// getSplunkToken returns the HEC token from the `Authorization: Splunk <token>` header,
+// the basic auth password or the `token` query string parameter.
+func getSplunkToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Splunk "); ok {
+		return strings.TrimSpace(token)
+	}
+	if _, password, ok := r.BasicAuth(); ok && password != "" {
+		return password
+	}
+	return r.URL.Query().Get("token")
+}
+
+// parseSplunkTime parses the epoch seconds `time` field, which may be a number or a string.
+func parseSplunkTime(raw json.RawMessage) (time.Time, bool) {
+	s := strings.Trim(string(raw), `"`)
+	if s == "" || s == "null" {
+		return time.Time{}, false
+	}
+	seconds, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	sec, frac := math.Modf(seconds)
+	return time.Unix(int64(sec), int64(math.Round(frac*1e3))*int64(time.Millisecond)), true
+}
+
+func splunkEventToLog(event splunkEvent, serviceName string) hlog.Log {
+	attributes := make(map[string]string)
+	for k, v := range event.Fields {
+		for key, value := range hlog.FormatAttributes(k, v) {
+			attributes[key] = value
+		}
+	}
+
+	lg := hlog.Log{
+		Attributes: attributes,
+		Timestamp:  time.Now().UTC().Format(hlog.TimestampFormat),
+	}
+	switch ev := event.Event.(type) {
+	case string:
+		lg.Message = ev
+	case map[string]interface{}:
+		for k, v := range ev {
+			for key, value := range hlog.FormatAttributes(k, v) {
+				attributes[key] = value
+			}
+		}
+		lg.Message = popAttribute(attributes, splunkMessageKeys)
+	default:
+		data, _ := json.Marshal(ev)
+		lg.Message = string(data)
+	}
+
+	lg.Level = strings.ToLower(popAttribute(attributes, splunkLevelKeys))
+	if lg.Level == "" {
+		lg.Level = model.LogLevelInfo.String()
+	}
+	if t, ok := parseSplunkTime(event.Time); ok {
+		lg.Timestamp = t.UTC().Format(hlog.TimestampFormat)
+	}
+	if svc := popAttribute(attributes, splunkServiceKeys); serviceName == "" {
+		serviceName = svc
+	}
+	for key, value := range map[string]string{
+		string(semconv.ServiceNameKey): serviceName,
+		string(semconv.HostNameKey):    event.Host,
+		LogSourceAttribute:             event.Source,
+		SplunkSourceTypeAttribute:      event.SourceType,
+		SplunkIndexAttribute:           event.Index,
+	} {
+		if value != "" {
+			attributes[key] = value
+		}
+	}
+	return lg
+}
+
+// parseSplunkEvents parses a HEC payload, which batches events by concatenating JSON objects
+// with optional whitespace between them.
+func parseSplunkEvents(body []byte) ([]splunkEvent, error) {
+	var events []splunkEvent
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	for {
+		var event splunkEvent
+		if err := decoder.Decode(&event); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if event.Event == nil {
+			return nil, e.New("splunk event is missing the event field")
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// HandleSplunkHealth implements the HEC health check used by forwarders before sending data.
+func HandleSplunkHealth(w http.ResponseWriter, r *http.Request) {
+	writeSplunkResponse(w, http.StatusOK, splunkCodeHealthy, "HEC is healthy")
+}
+
+// HandleSplunkEvent implements the splunk HTTP event collector (HEC) event endpoint.
+// https://docs.splunk.com/Documentation/Splunk/latest/Data/FormateventsforHTTPEventCollector
+func HandleSplunkEvent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	token := getSplunkToken(r)
+	if token == "" {
+		writeSplunkResponse(w, http.StatusUnauthorized, splunkCodeTokenRequired, "Token is required")
+		return
+	}
+	projectID, serviceName, err := getTokenProjectAndService(r, token)
+	if err != nil {
+		writeSplunkResponse(w, http.StatusForbidden, splunkCodeInvalidToken, "Invalid token")
+		return
+	}
+
+	body, err := GetBody(ctx, r)
+	if err != nil {
+		writeSplunkResponse(w, http.StatusBadRequest, splunkCodeInvalidFormat, "Invalid data format")
+		return
+	}
+	events, err := parseSplunkEvents(body)
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Error("invalid splunk hec payload")
+		writeSplunkResponse(w, http.StatusBadRequest, splunkCodeInvalidFormat, "Invalid data format")
+		return
+	}
+	if len(events) == 0 {
+		writeSplunkResponse(w, http.StatusBadRequest, splunkCodeNoData, "No data")
+		return
+	}
+
+	for _, event := range events {
+		if err := hlog.SubmitHTTPLog(ctx, tracer, projectID, splunkEventToLog(event, serviceName)); err != nil {
+			log.WithContext(ctx).WithError(err).Error("failed to submit log")
+			writeSplunkResponse(w, http.StatusBadRequest, splunkCodeInvalidFormat, "Invalid data format")
+			return
+		}
+	}
+
+	writeSplunkResponse(w, http.StatusOK, splunkCodeSuccess, "Success")
+}
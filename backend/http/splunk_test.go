@@ -0,0 +1,67 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// HEC batches are concatenated JSON objects, not necessarily separated by newlines
+const SplunkHECBatch = `{"time":1704067200.25,"host":"web-1","source":"/var/log/app.log","sourcetype":"_json","index":"main","event":{"message":"payment failed","level":"ERROR","order":{"id":42}},"fields":{"region":"us-east-1"}}{"time":"1704067201","event":"plain text event","fields":{"service":"billing"}}
+{"event":["a","b"]}`
+
+func TestHandleSplunkEvent(t *testing.T) {
+	r := httptest.NewRequest("POST", "/services/collector/event", strings.NewReader(SplunkHECBatch))
+	r.Header.Set("Authorization", "Splunk "+testSplunkToken)
+	w := httptest.NewRecorder()
+	HandleSplunkEvent(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"text":"Success","code":0}`, w.Body.String())
+
+	events := lastLogEvents(3)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 250e6, time.UTC), events[0].Time.UTC())
+	assert.Equal(t, "payment failed", eventAttribute(events[0], "log.message"))
+	assert.Equal(t, "error", eventAttribute(events[0], "log.severity"))
+	assert.Equal(t, "web-1", eventAttribute(events[0], "host.name"))
+	assert.Equal(t, "/var/log/app.log", eventAttribute(events[0], LogSourceAttribute))
+	assert.Equal(t, "_json", eventAttribute(events[0], SplunkSourceTypeAttribute))
+	assert.Equal(t, "main", eventAttribute(events[0], SplunkIndexAttribute))
+	assert.Equal(t, "42", eventAttribute(events[0], "order.id"))
+	assert.Equal(t, "us-east-1", eventAttribute(events[0], "region"))
+
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC), events[1].Time.UTC())
+	assert.Equal(t, "plain text event", eventAttribute(events[1], "log.message"))
+	assert.Equal(t, "info", eventAttribute(events[1], "log.severity"))
+	assert.Equal(t, "billing", eventAttribute(events[1], "service.name"))
+
+	assert.Equal(t, `["a","b"]`, eventAttribute(events[2], "log.message"))
+}
+
+func TestHandleSplunkEventErrors(t *testing.T) {
+	for _, tc := range []struct {
+		auth   string
+		body   string
+		status int
+		code   string
+	}{
+		{"", `{"event":"x"}`, http.StatusUnauthorized, `"code":2`},
+		{"Splunk not-a-token!", `{"event":"x"}`, http.StatusForbidden, `"code":4`},
+		{"Splunk 1", `{"event":"x"}`, http.StatusForbidden, `"code":4`},
+		{"Splunk " + testSplunkToken, ``, http.StatusBadRequest, `"code":5`},
+		{"Splunk " + testSplunkToken, `{"time":1}`, http.StatusBadRequest, `"code":6`},
+		{"Splunk " + testSplunkToken, `{"event":"x"`, http.StatusBadRequest, `"code":6`},
+	} {
+		r := httptest.NewRequest("POST", "/services/collector/event", strings.NewReader(tc.body))
+		if tc.auth != "" {
+			r.Header.Set("Authorization", tc.auth)
+		}
+		w := httptest.NewRecorder()
+		HandleSplunkEvent(w, r)
+		assert.Equal(t, tc.status, w.Code, tc.body)
+		assert.Contains(t, w.Body.String(), tc.code, tc.body)
+	}
+}
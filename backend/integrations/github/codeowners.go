@@ -0,0 +1,92 @@
+package github
+
+import (
+	"bufio"
+	"regexp"
+	"strings"
+)
+
+// CodeOwnersPaths are the locations GitHub reads a CODEOWNERS file from, in order of precedence.
+var CodeOwnersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// CodeOwners holds the rules of a CODEOWNERS file.
+type CodeOwners struct {
+	rules []codeOwnersRule
+}
+
+// ParseCodeOwners parses the content of a CODEOWNERS file.
+// Lines with an invalid pattern are skipped, as GitHub does.
+func ParseCodeOwners(content string) *CodeOwners {
+	codeOwners := &CodeOwners{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		pattern, err := compileCodeOwnersPattern(fields[0])
+		if err != nil {
+			continue
+		}
+		codeOwners.rules = append(codeOwners.rules, codeOwnersRule{pattern: pattern, owners: fields[1:]})
+	}
+	return codeOwners
+}
+
+// Owners returns the owners of the file. The last matching rule takes precedence.
+func (c *CodeOwners) Owners(path string) []string {
+	path = strings.TrimPrefix(path, "/")
+	for idx := len(c.rules) - 1; idx >= 0; idx-- {
+		if c.rules[idx].pattern.MatchString(path) {
+			return c.rules[idx].owners
+		}
+	}
+	return nil
+}
+
+// compileCodeOwnersPattern converts a gitignore style pattern to a regular expression.
+func compileCodeOwnersPattern(pattern string) (*regexp.Regexp, error) {
+	directory := strings.HasSuffix(pattern, "/")
+	trimmed := strings.TrimSuffix(pattern, "/")
+	// patterns without a slash other than a trailing one match at any depth
+	anchored := strings.Contains(trimmed, "/")
+	trimmed = strings.TrimPrefix(trimmed, "/")
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	if !anchored {
+		expr.WriteString("(?:.*/)?")
+	}
+	for idx := 0; idx < len(trimmed); idx++ {
+		switch {
+		case strings.HasPrefix(trimmed[idx:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			idx += 2
+		case strings.HasPrefix(trimmed[idx:], "**"):
+			expr.WriteString(".*")
+			idx += 1
+		case trimmed[idx] == '*':
+			expr.WriteString("[^/]*")
+		case trimmed[idx] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(trimmed[idx])))
+		}
+	}
+	// a pattern matching a directory matches everything within it
+	if directory {
+		expr.WriteString("/.*$")
+	} else {
+		expr.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(expr.String())
+}
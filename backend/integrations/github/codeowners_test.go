@@ -0,0 +1,30 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeOwners(t *testing.T) {
+	codeOwners := ParseCodeOwners(`
+# default owners
+*       @highlight/core
+
+*.go    @highlight/backend
+/frontend/ @highlight/frontend
+docs/** @highlight/docs # trailing comment
+apps/*/package.json @highlight/infra
+invalid[ @nobody
+`)
+
+	assert.Equal(t, []string{"@highlight/core"}, codeOwners.Owners("README.md"))
+	assert.Equal(t, []string{"@highlight/backend"}, codeOwners.Owners("backend/store/store.go"))
+	assert.Equal(t, []string{"@highlight/frontend"}, codeOwners.Owners("/frontend/src/index.tsx"))
+	assert.Equal(t, []string{"@highlight/core"}, codeOwners.Owners("packages/frontend/index.ts"))
+	assert.Equal(t, []string{"@highlight/docs"}, codeOwners.Owners("docs/getting-started/index.md"))
+	assert.Equal(t, []string{"@highlight/infra"}, codeOwners.Owners("apps/web/package.json"))
+	assert.Equal(t, []string{"@highlight/core"}, codeOwners.Owners("apps/web/src/package.json"))
+
+	assert.Nil(t, ParseCodeOwners("").Owners("main.go"))
+}
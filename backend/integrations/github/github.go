@@ -33,6 +33,8 @@ type ClientInterface interface {
 	GetRepoContent(ctx context.Context, githubPath string, path string, version string) (fileContent *github.RepositoryContent, directoryContent []*github.RepositoryContent, resp *github.Response, err error)
 	GetRepoBlob(ctx context.Context, githubPath string, blobSHA string) (*github.Blob, *github.Response, error)
 	GetLatestCommitHash(ctx context.Context, githubPath string) (string, *github.Response, error)
+	ListFileCommits(ctx context.Context, githubPath string, path string, version string, count int) ([]*github.RepositoryCommit, *github.Response, error)
+	GetCommit(ctx context.Context, githubPath string, sha string) (*github.RepositoryCommit, *github.Response, error)
 	SearchIssues(ctx context.Context, rawQuery string) ([]*github.Issue, error)
 }
 
@@ -183,6 +185,22 @@ func (c *Client) GetLatestCommitHash(ctx context.Context, githubPath string) (st
 	return c.client.Repositories.GetCommitSHA1(ctx, repoPath[0], repoPath[1], "HEAD", "")
 }
 
+// ListFileCommits returns the most recent commits touching the file in the history of version.
+func (c *Client) ListFileCommits(ctx context.Context, githubPath string, path string, version string, count int) ([]*github.RepositoryCommit, *github.Response, error) {
+	repoPath := strings.Split(githubPath, "/")
+	return c.client.Repositories.ListCommits(ctx, repoPath[0], repoPath[1], &github.CommitsListOptions{
+		SHA:         version,
+		Path:        path,
+		ListOptions: github.ListOptions{PerPage: count},
+	})
+}
+
+// GetCommit returns the commit including the patches of the files it changed.
+func (c *Client) GetCommit(ctx context.Context, githubPath string, sha string) (*github.RepositoryCommit, *github.Response, error) {
+	repoPath := strings.Split(githubPath, "/")
+	return c.client.Repositories.GetCommit(ctx, repoPath[0], repoPath[1], sha, nil)
+}
+
 func (c *Client) SearchIssues(ctx context.Context, rawQuery string) ([]*github.Issue, error) {
 	owner, err := c.GetInstallationOwner(ctx)
 	if err != nil {
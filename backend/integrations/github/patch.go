@@ -0,0 +1,42 @@
+package github
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// PatchChangedLines returns the line numbers of the new file touched by a unified diff patch.
+// Lines are touched when they were added, or when lines were removed right before them.
+func PatchChangedLines(patch string) []int {
+	var changed []int
+	line := 0
+	inHunk := false
+	for _, text := range strings.Split(patch, "\n") {
+		if match := hunkHeader.FindStringSubmatch(text); match != nil {
+			line, _ = strconv.Atoi(match[1])
+			inHunk = true
+			continue
+		}
+		if !inHunk {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(text, "+"), strings.HasPrefix(text, "-"):
+			if len(changed) == 0 || changed[len(changed)-1] != line {
+				changed = append(changed, line)
+			}
+			// removed lines do not exist in the new file
+			if strings.HasPrefix(text, "+") {
+				line++
+			}
+		case strings.HasPrefix(text, `\`):
+			// "\ No newline at end of file"
+		default:
+			line++
+		}
+	}
+	return changed
+}
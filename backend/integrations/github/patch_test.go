@@ -0,0 +1,26 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatchChangedLines(t *testing.T) {
+	patch := `@@ -1,4 +1,5 @@
+ package main
+-import "fmt"
++import (
++	"fmt"
++)
+ 
+ func main() {
+@@ -20,3 +21,2 @@ func main() {
+ 	a := 1
+-	b := 2
+ 	fmt.Println(a)
+\ No newline at end of file`
+
+	assert.Equal(t, []int{2, 3, 4, 22}, PatchChangedLines(patch))
+	assert.Empty(t, PatchChangedLines(""))
+}
@@ -31,7 +31,8 @@ const alertEvalFreq = time.Minute
 func applyDefaultFilters(productType modelInputs.ProductType) string {
 	if productType == modelInputs.ProductTypeErrors {
 		now := time.Now().UTC()
-		return fmt.Sprintf(`status=OPEN snoozed_until<"%s" `, now.Format(timeFormatSecondsNoTz))
+		// regressed error groups are open again and keep alerting
+		return fmt.Sprintf(`status=(%s OR %s) snoozed_until<"%s" `, modelInputs.ErrorStateOpen, modelInputs.ErrorStateRegressed, now.Format(timeFormatSecondsNoTz))
 	}
 
 	return ""
@@ -69,7 +70,8 @@ func WatchMetricAlerts(ctx context.Context, DB *gorm.DB, MailClient *sendgrid.Cl
 
 func getMetricAlerts(ctx context.Context, DB *gorm.DB) []*model.Alert {
 	var alerts []*model.Alert
-	if err := DB.Model(&model.Alert{}).Where("disabled = ?", false).Find(&alerts).Error; err != nil {
+	// regression alerts are sent at ingest when an error group regresses
+	if err := DB.Model(&model.Alert{}).Where("disabled = ?", false).Where("threshold_type IS DISTINCT FROM ?", modelInputs.ThresholdTypeRegression).Find(&alerts).Error; err != nil {
 		log.WithContext(ctx).Error("Error querying for metric alerts")
 	}
 
@@ -101,7 +103,8 @@ func processMetricAlert(ctx context.Context, DB *gorm.DB, MailClient *sendgrid.C
 	saveMetricState := alert.Sql == nil &&
 		alert.ProductType != modelInputs.ProductTypeErrors &&
 		alert.ProductType != modelInputs.ProductTypeSessions &&
-		alert.ProductType != modelInputs.ProductTypeEvents
+		alert.ProductType != modelInputs.ProductTypeEvents &&
+		alert.ProductType != modelInputs.ProductTypeWebVitals
 
 	endDate := curDate
 	startDate := curDate.Add(-1 * thresholdWindow)
@@ -148,6 +151,8 @@ func processMetricAlert(ctx context.Context, DB *gorm.DB, MailClient *sendgrid.C
 		config = clickhouse.TracesSampleableTableConfig
 	case modelInputs.ProductTypeEvents:
 		config = clickhouse.EventsSampleableTableConfig
+	case modelInputs.ProductTypeWebVitals:
+		config = clickhouse.WebVitalsSampleableTableConfig
 	default:
 		return errors.Errorf("Unknown product type: %s", alert.ProductType)
 	}
@@ -40,6 +40,7 @@ const (
 	PushOTeLMetricSum                      PayloadType = iota
 	PushOTeLMetricHistogram                PayloadType = iota
 	PushOTeLMetricSummary                  PayloadType = iota
+	AssignErrorGroupCodeOwner              PayloadType = iota
 	HealthCheck                            PayloadType = math.MaxInt
 )
 
@@ -139,6 +140,11 @@ type ErrorObjectDataSyncArgs struct {
 	ErrorObjectID int
 }
 
+type AssignErrorGroupCodeOwnerArgs struct {
+	ErrorGroupID  int
+	ErrorObjectID int
+}
+
 type RetryableMessage interface {
 	GetType() PayloadType
 	GetFailures() int
@@ -150,24 +156,25 @@ type RetryableMessage interface {
 }
 
 type Message struct {
-	Type                  PayloadType
-	Failures              int
-	MaxRetries            int
-	KafkaMessage          *kafka.Message             `json:",omitempty"`
-	PushPayload           *PushPayloadArgs           `json:",omitempty"`
-	InitializeSession     *InitializeSessionArgs     `json:",omitempty"`
-	IdentifySession       *IdentifySessionArgs       `json:",omitempty"`
-	AddTrackProperties    *AddTrackPropertiesArgs    `json:",omitempty"`
-	AddSessionProperties  *AddSessionPropertiesArgs  `json:",omitempty"`
-	PushBackendPayload    *PushBackendPayloadArgs    `json:",omitempty"`
-	PushMetrics           *PushMetricsArgs           `json:",omitempty"`
-	AddSessionFeedback    *AddSessionFeedbackArgs    `json:",omitempty"`
-	PushLogs              *PushLogsArgs              `json:",omitempty"`
-	PushTraces            *PushTracesArgs            `json:",omitempty"`
-	SessionDataSync       *SessionDataSyncArgs       `json:",omitempty"`
-	ErrorGroupDataSync    *ErrorGroupDataSyncArgs    `json:",omitempty"`
-	ErrorObjectDataSync   *ErrorObjectDataSyncArgs   `json:",omitempty"`
-	PushCompressedPayload *PushCompressedPayloadArgs `json:",omitempty"`
+	Type                      PayloadType
+	Failures                  int
+	MaxRetries                int
+	KafkaMessage              *kafka.Message                 `json:",omitempty"`
+	PushPayload               *PushPayloadArgs               `json:",omitempty"`
+	InitializeSession         *InitializeSessionArgs         `json:",omitempty"`
+	IdentifySession           *IdentifySessionArgs           `json:",omitempty"`
+	AddTrackProperties        *AddTrackPropertiesArgs        `json:",omitempty"`
+	AddSessionProperties      *AddSessionPropertiesArgs      `json:",omitempty"`
+	PushBackendPayload        *PushBackendPayloadArgs        `json:",omitempty"`
+	PushMetrics               *PushMetricsArgs               `json:",omitempty"`
+	AddSessionFeedback        *AddSessionFeedbackArgs        `json:",omitempty"`
+	PushLogs                  *PushLogsArgs                  `json:",omitempty"`
+	PushTraces                *PushTracesArgs                `json:",omitempty"`
+	SessionDataSync           *SessionDataSyncArgs           `json:",omitempty"`
+	ErrorGroupDataSync        *ErrorGroupDataSyncArgs        `json:",omitempty"`
+	ErrorObjectDataSync       *ErrorObjectDataSyncArgs       `json:",omitempty"`
+	PushCompressedPayload     *PushCompressedPayloadArgs     `json:",omitempty"`
+	AssignErrorGroupCodeOwner *AssignErrorGroupCodeOwnerArgs `json:",omitempty"`
 }
 
 func (m *Message) GetType() PayloadType {
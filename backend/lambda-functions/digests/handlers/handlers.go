@@ -157,7 +157,7 @@ func (h *handlers) GetDigestData(ctx context.Context, input utils.ProjectIdRespo
 		WHERE eo.project_id = ?
 		AND eo.created_at >= ?
 		AND eo.created_at < ?
-		AND eg.state <> 'IGNORED'
+		AND eg.state NOT IN ('IGNORED', 'MERGED')
 	`, input.ProjectId, input.Start, input.End).Scan(&curErrors).Error; err != nil {
 		return nil, errors.Wrap(err, "error querying current error count")
 	}
@@ -171,7 +171,7 @@ func (h *handlers) GetDigestData(ctx context.Context, input utils.ProjectIdRespo
 		WHERE eo.project_id = ?
 		AND eo.created_at >= ?
 		AND eo.created_at < ?
-		AND eg.state <> 'IGNORED'
+		AND eg.state NOT IN ('IGNORED', 'MERGED')
 	`, input.ProjectId, input.Prior, input.Start).Scan(&prevErrors).Error; err != nil {
 		return nil, errors.Wrap(err, "error querying previous error count")
 	}
@@ -266,7 +266,7 @@ func (h *handlers) GetDigestData(ctx context.Context, input utils.ProjectIdRespo
 		WHERE eg.project_id = ?
 		AND eg.created_at >= ?
 		AND eg.created_at < ?
-		AND eg.state <> 'IGNORED'
+		AND eg.state NOT IN ('IGNORED', 'MERGED')
 		GROUP BY eg.id
 		ORDER BY count(distinct coalesce(s.identifier, s.client_id)) desc
 		LIMIT 5
@@ -292,7 +292,7 @@ func (h *handlers) GetDigestData(ctx context.Context, input utils.ProjectIdRespo
 		WHERE eg.project_id = ?
 		AND eo.created_at >= ?
 		AND eo.created_at < ?
-		AND eg.state <> 'IGNORED'
+		AND eg.state NOT IN ('IGNORED', 'MERGED')
 		GROUP BY eg.id
 		ORDER BY sum(case when eo.created_at >= ? then 1 else 0 end) desc
 		LIMIT 5
@@ -109,7 +109,9 @@ func isId(input string) bool {
 	return false
 }
 
-func normalize(input string) (string, error) {
+// NormalizeURL returns the path of the url with the parts that look like ids replaced by {id-N} placeholders,
+// so that the urls of pages showing different records are grouped together.
+func NormalizeURL(input string) (string, error) {
 	parsed, err := url.Parse(input)
 	if err != nil {
 		return "", nil
@@ -192,7 +194,7 @@ func GetUserJourneySteps(projectId int, sessionId int, data []byte) ([]model.Use
 			if err := json.Unmarshal(e.Data.Payload, &unmarshalled); err != nil {
 				return nil, err
 			}
-			normalizedUrl, err := normalize(string(unmarshalled))
+			normalizedUrl, err := NormalizeURL(string(unmarshalled))
 			if err != nil {
 				return nil, err
 			}
@@ -187,7 +187,8 @@ const (
 	// session insights
 	ReactEmailTemplateSessionInsights ReactEmailTemplate = "session-insights"
 	// notifications
-	ReactEmailTemplateAlertUpsert ReactEmailTemplate = "alert-upsert"
+	ReactEmailTemplateAlertUpsert          ReactEmailTemplate = "alert-upsert"
+	ReactEmailTemplateErrorGroupAssignment ReactEmailTemplate = "error-group-assignment"
 )
 
 func (s *Client) GetSessionInsightEmailHtml(ctx context.Context, toEmail string, unsubscribeUrl string, data utils.SessionInsightsData) (string, error) {
@@ -30,6 +30,7 @@ import (
 	"github.com/highlight-run/highlight/backend/assets"
 	"github.com/highlight-run/highlight/backend/clickhouse"
 	"github.com/highlight-run/highlight/backend/embeddings"
+	"github.com/highlight-run/highlight/backend/encryption"
 	"github.com/highlight-run/highlight/backend/enterprise"
 	"github.com/highlight-run/highlight/backend/env"
 	highlightHttp "github.com/highlight-run/highlight/backend/http"
@@ -44,9 +45,11 @@ import (
 	"github.com/highlight-run/highlight/backend/pricing"
 	private "github.com/highlight-run/highlight/backend/private-graph/graph"
 	privategen "github.com/highlight-run/highlight/backend/private-graph/graph/generated"
+	"github.com/highlight-run/highlight/backend/promql"
 	public "github.com/highlight-run/highlight/backend/public-graph/graph"
 	publicgen "github.com/highlight-run/highlight/backend/public-graph/graph/generated"
 	"github.com/highlight-run/highlight/backend/redis"
+	"github.com/highlight-run/highlight/backend/scim"
 	"github.com/highlight-run/highlight/backend/stepfunctions"
 	"github.com/highlight-run/highlight/backend/storage"
 	"github.com/highlight-run/highlight/backend/store"
@@ -292,7 +295,17 @@ func main() {
 	mpm := marketplacemetering.NewFromConfig(cfg)
 
 	var storageClient storage.Client
-	if env.IsProduction() || env.Config.AwsRoleArn != "" {
+	if env.Config.ObjectStorageBackend == "gcs" {
+		log.WithContext(ctx).Info("using google cloud storage for object storage")
+		if storageClient, err = storage.NewGCSClient(ctx); err != nil {
+			log.WithContext(ctx).Fatalf("error creating gcs storage client: %v", err)
+		}
+	} else if env.Config.ObjectStorageBackend == "azure" {
+		log.WithContext(ctx).Info("using azure blob storage for object storage")
+		if storageClient, err = storage.NewAzureBlobClient(ctx); err != nil {
+			log.WithContext(ctx).Fatalf("error creating azure blob storage client: %v", err)
+		}
+	} else if env.IsProduction() || env.Config.AwsRoleArn != "" {
 		log.WithContext(ctx).Info("using S3 for object storage")
 		if storageClient, err = storage.NewS3Client(ctx); err != nil {
 			log.WithContext(ctx).Fatalf("error creating s3 storage client: %v", err)
@@ -307,6 +320,14 @@ func main() {
 			log.WithContext(ctx).Fatalf("error creating filesystem storage client: %v", err)
 		}
 	}
+	if env.Config.PayloadEncryptionKeyfile != "" {
+		log.WithContext(ctx).Info("encrypting stored session payloads with keys from the payload encryption keyfile")
+		kms, err := encryption.NewKeyfileKMS(env.Config.PayloadEncryptionKeyfile)
+		if err != nil {
+			log.WithContext(ctx).Fatalf("error loading payload encryption keyfile: %v", err)
+		}
+		storageClient.SetPayloadEncryptor(encryption.NewKeyring(db, kms), env.Config.PrivateGraphUri)
+	}
 
 	// sync writes with batching per-partition
 	kafkaProducer := kafkaqueue.New(ctx, kafkaqueue.GetTopic(kafkaqueue.GetTopicOptions{Type: kafkaqueue.TopicTypeDefault}), kafkaqueue.Producer, nil)
@@ -458,6 +479,19 @@ func main() {
 		r.HandleFunc("/slack-events", privateResolver.SlackEventsWebhook(ctx, env.Config.SlackSigningSecret))
 		r.Post(fmt.Sprintf("%s/%s", privateEndpoint, "microsoft-teams/bot"), privateResolver.MicrosoftTeamsBotEndpoint)
 
+		// prometheus compatible query API, authenticated with a project API key
+		r.Route(fmt.Sprintf("%s/prometheus/api/v1", strings.TrimSuffix(privateEndpoint, "/")), func(r chi.Router) {
+			r.Use(cors.New(PRIVATE_GRAPH_CORS_OPTIONS).Handler)
+			r.Use(highlightChi.Middleware)
+			promql.NewAPI(clickhouse.NewPromQuerier(clickhouseClient), privateResolver.Query().APIKeyToOrgID).Listen(r)
+		})
+
+		// SCIM user and group provisioning, authenticated with a workspace SCIM token
+		r.Route(fmt.Sprintf("%s/scim/v2", strings.TrimSuffix(privateEndpoint, "/")), func(r chi.Router) {
+			r.Use(highlightChi.Middleware)
+			scim.NewAPI(dataStore, strings.TrimSuffix(env.Config.PrivateGraphUri, "/")+"/scim/v2").Listen(r)
+		})
+
 		r.Route(privateEndpoint, func(r chi.Router) {
 			r.Use(cors.New(PRIVATE_GRAPH_CORS_OPTIONS).Handler)
 			r.Use(highlightChi.Middleware)
@@ -465,6 +499,9 @@ func main() {
 			if fsClient, ok := storageClient.(*storage.FilesystemClient); ok {
 				fsClient.SetupHTTPSListener(r)
 			}
+			if env.Config.PayloadEncryptionKeyfile != "" {
+				storage.SetupDecryptedPayloadListener(r, storageClient)
+			}
 			r.Get("/assets/{project_id}/{hash_val}", privateResolver.AssetHandler)
 			r.Get("/project-token/{project_id}", privateResolver.ProjectJWTHandler)
 
@@ -500,6 +537,7 @@ func main() {
 				Cache: lru.New[string](10000),
 			})
 			privateServer.Use(private.NewGraphqlOAuthValidator(privateResolver.Store))
+			privateServer.Use(private.NewGraphqlAPITokenValidator())
 			privateServer.Use(util.NewTracer(util.PrivateGraph))
 			privateServer.Use(htrace.NewGraphqlTracer(string(util.PrivateGraph), trace.WithSpanKind(trace.SpanKindConsumer)).WithRequestFieldLogging())
 			privateServer.SetErrorPresenter(htrace.GraphQLErrorPresenter(string(util.PrivateGraph)))
@@ -583,7 +621,7 @@ func main() {
 		otelHandler := otel.New(publicResolver)
 		otelHandler.Listen(r)
 		vercel.Listen(r, tracerNoResources)
-		highlightHttp.Listen(r, tracerNoResources)
+		highlightHttp.Listen(r, tracerNoResources, dataStore)
 	}
 
 	/*
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/highlight-run/highlight/backend/encryption"
+	"github.com/highlight-run/highlight/backend/env"
+	"github.com/highlight-run/highlight/backend/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// Manages the keys encrypting stored session payloads:
+//
+//	-generate-master-key       prints a new master key to add to the keyfile
+//	-rewrap                    rewraps all data keys with the current master key of the keyfile
+//	-rotate-project <id>       creates a new data key for the project's new payloads
+//	-destroy-project <id>      deletes the data keys of the project, crypto-shredding its payloads
+func main() {
+	generateMasterKey := flag.Bool("generate-master-key", false, "print a new master key")
+	rewrap := flag.Bool("rewrap", false, "rewrap the data keys with the current master key")
+	rotateProject := flag.Int("rotate-project", 0, "rotate the data key of the project")
+	destroyProject := flag.Int("destroy-project", 0, "destroy the data keys of the project")
+	flag.Parse()
+
+	ctx := context.TODO()
+	if *generateMasterKey {
+		key, err := encryption.GenerateMasterKey()
+		if err != nil {
+			log.WithContext(ctx).Fatal(err)
+		}
+		fmt.Println(key)
+		return
+	}
+
+	kms, err := encryption.NewKeyfileKMS(env.Config.PayloadEncryptionKeyfile)
+	if err != nil {
+		log.WithContext(ctx).Fatalf("error loading payload encryption keyfile: %+v", err)
+	}
+	log.WithContext(ctx).Info("setting up db")
+	db, err := model.SetupDB(ctx, env.Config.SQLDatabase)
+	if err != nil {
+		log.WithContext(ctx).Fatalf("error setting up db: %+v", err)
+	}
+	keyring := encryption.NewKeyring(db, kms)
+
+	if *rewrap {
+		count, err := keyring.RewrapDataKeys(ctx)
+		if err != nil {
+			log.WithContext(ctx).Fatalf("error rewrapping data keys: %+v", err)
+		}
+		log.WithContext(ctx).Infof("rewrapped %d data keys with master key %s", count, kms.CurrentKeyID())
+	}
+	if *rotateProject != 0 {
+		if err := keyring.RotateDataKey(ctx, *rotateProject); err != nil {
+			log.WithContext(ctx).Fatalf("error rotating data key: %+v", err)
+		}
+		log.WithContext(ctx).Infof("rotated data key of project %d", *rotateProject)
+	}
+	if *destroyProject != 0 {
+		if err := keyring.DestroyProjectKeys(ctx, *destroyProject); err != nil {
+			log.WithContext(ctx).Fatalf("error destroying data keys: %+v", err)
+		}
+		log.WithContext(ctx).Warnf("destroyed data keys of project %d, its encrypted payloads can no longer be read", *destroyProject)
+	}
+}
@@ -66,6 +66,8 @@ var AlertType = struct {
 	LOGS     string
 	TRACES   string
 	METRICS  string
+	// fired when a resolved error group regresses
+	ERROR_REGRESSION string
 }{
 	// deprecated alerts
 	ERROR:            "ERROR_ALERT",
@@ -77,11 +79,12 @@ var AlertType = struct {
 	NEW_SESSION:      "NEW_SESSION_ALERT",
 	LOG:              "LOG",
 	// new alerts
-	SESSIONS: "SESSIONS_ALERT",
-	ERRORS:   "ERRORS_ALERT",
-	LOGS:     "LOGS_ALERT",
-	TRACES:   "TRACES_ALERT",
-	METRICS:  "METRICS_ALERT",
+	SESSIONS:         "SESSIONS_ALERT",
+	ERRORS:           "ERRORS_ALERT",
+	LOGS:             "LOGS_ALERT",
+	TRACES:           "TRACES_ALERT",
+	METRICS:          "METRICS_ALERT",
+	ERROR_REGRESSION: "ERROR_REGRESSION_ALERT",
 }
 
 var AdminRole = struct {
@@ -117,6 +120,8 @@ var ContextKeys = struct {
 	ZapierProject  contextString
 	SessionId      contextString
 	SSOClientID    contextString
+	// The APIToken that authenticated the request, if any.
+	APIToken contextString
 }{
 	IP:             "ip",
 	UserAgent:      "userAgent",
@@ -128,6 +133,7 @@ var ContextKeys = struct {
 	ZapierProject:  "project",
 	SessionId:      "sessionId",
 	SSOClientID:    "clientID",
+	APIToken:       "apiToken",
 }
 
 var Models = []interface{}{
@@ -168,6 +174,11 @@ var Models = []interface{}{
 	&RegistrationData{},
 	&MetricMonitor{},
 	&ErrorFingerprint{},
+	&ErrorGroupingRule{},
+	&ErrorGroupAssignmentRule{},
+	&RedactionRule{},
+	&ProjectDataKey{},
+	&AssigneeDestination{},
 	&EventChunk{},
 	&SavedAsset{},
 	&ProjectAssetTransform{},
@@ -184,6 +195,7 @@ var Models = []interface{}{
 	&EmailOptOut{},
 	&BillingEmailHistory{},
 	&Service{},
+	&Release{},
 	&SetupEvent{},
 	&SessionAdminsView{},
 	&ErrorGroupAdminsView{},
@@ -192,6 +204,7 @@ var Models = []interface{}{
 	&ProjectClientSamplingSettings{},
 	&AllWorkspaceSettings{},
 	&ErrorGroupActivityLog{},
+	&ErrorGroupMerge{},
 	&UserJourneyStep{},
 	&SystemConfiguration{},
 	&SessionInsight{},
@@ -201,6 +214,12 @@ var Models = []interface{}{
 	&Alert{},
 	&AlertDestination{},
 	&SSOClient{},
+	&SAMLClient{},
+	&SCIMToken{},
+	&SCIMUser{},
+	&SCIMGroup{},
+	&AuditLog{},
+	&APIToken{},
 }
 
 func init() {
@@ -411,6 +430,16 @@ type Project struct {
 	RageClickRadiusPixels int `gorm:"default:8"`
 	// Minimum count of clicks in a rage click event
 	RageClickCount int `gorm:"default:5"`
+	// Maximum time after a click without a DOM change or navigation for a dead click event
+	DeadClickTimeoutMilliseconds int `gorm:"default:1000"`
+	// Maximum time after a click for an error to count as an error click event
+	ErrorClickWindowMilliseconds int `gorm:"default:1000"`
+	// Maximum time window considered for a thrashed cursor event
+	ThrashedCursorWindowMilliseconds int `gorm:"default:1500"`
+	// Minimum count of cursor direction changes in a thrashed cursor event
+	ThrashedCursorDirectionChanges int `gorm:"default:8"`
+	// Maximum time spent on a page before navigating back for a u-turn event
+	UTurnWindowSeconds int `gorm:"default:7"`
 
 	// Applies to all browser extensions
 	// TODO - rename to FilterBrowserExtension #5811
@@ -504,6 +533,8 @@ type AllWorkspaceSettings struct {
 	EnableTeamsIntegration bool `gorm:"default:false"`
 
 	EnableLogTraceIngestion bool `gorm:"default:false"`
+
+	AuditLogRetentionDays int `gorm:"default:365"`
 }
 
 type HasSecret interface {
@@ -756,6 +787,10 @@ type Session struct {
 	Processed           *bool `json:"processed"`
 	HasComments         bool  `json:"has_comments" gorm:"default:false"`
 	HasRageClicks       *bool `json:"has_rage_clicks"`
+	HasDeadClicks       *bool `json:"has_dead_clicks"`
+	HasErrorClicks      *bool `json:"has_error_clicks"`
+	HasThrashedCursor   *bool `json:"has_thrashed_cursor"`
+	HasUTurns           *bool `json:"has_u_turns"`
 	HasErrors           *bool `json:"has_errors"`
 	HasOutOfOrderEvents bool  `gorm:"default:false"`
 	// The timestamp of the first payload received after the session got processed (if applicable)
@@ -1065,6 +1100,20 @@ type ErrorGroup struct {
 	ErrorObjects     []ErrorObject
 	ServiceName      string
 
+	// ResolvedInVersion is the latest service version seen when the group was resolved.
+	ResolvedInVersion  *string
+	RegressedInVersion *string
+	RegressedAt        *time.Time
+	// Regressed is set when the error group regressed while grouping the current error object.
+	Regressed bool `gorm:"-"`
+
+	// CodeOwner is the first owner of the top in-app frame in the repository CODEOWNERS file.
+	CodeOwner *string
+
+	// An error group is assigned to either an admin or a team.
+	AssigneeAdminID *int
+	AssigneeTeam    *string
+
 	// manually migrate as gorm wants to make this have a default value otherwise
 	ErrorTagID *int      `gorm:"-:migration"`
 	ErrorTag   *ErrorTag `gorm:"-:migration"`
@@ -1089,9 +1138,14 @@ type MatchedErrorObject struct {
 type ErrorGroupEventType string
 
 const (
-	ErrorGroupResolvedEvent ErrorGroupEventType = "ErrorGroupResolved"
-	ErrorGroupIgnoredEvent  ErrorGroupEventType = "ErrorGroupIgnored"
-	ErrorGroupOpenedEvent   ErrorGroupEventType = "ErrorGroupOpened"
+	ErrorGroupResolvedEvent   ErrorGroupEventType = "ErrorGroupResolved"
+	ErrorGroupIgnoredEvent    ErrorGroupEventType = "ErrorGroupIgnored"
+	ErrorGroupOpenedEvent     ErrorGroupEventType = "ErrorGroupOpened"
+	ErrorGroupMergedEvent     ErrorGroupEventType = "ErrorGroupMerged"
+	ErrorGroupSplitEvent      ErrorGroupEventType = "ErrorGroupSplit"
+	ErrorGroupRegressedEvent  ErrorGroupEventType = "ErrorGroupRegressed"
+	ErrorGroupAssignedEvent   ErrorGroupEventType = "ErrorGroupAssigned"
+	ErrorGroupUnassignedEvent ErrorGroupEventType = "ErrorGroupUnassigned"
 )
 
 type ErrorGroupActivityLog struct {
@@ -1103,6 +1157,15 @@ type ErrorGroupActivityLog struct {
 	EventData    JSONB
 }
 
+// ErrorGroupMerge routes errors matched to a merged error group to the group it was merged into.
+type ErrorGroupMerge struct {
+	Model
+	ProjectID          int `gorm:"index"`
+	SourceErrorGroupID int `gorm:"uniqueIndex"`
+	TargetErrorGroupID int `gorm:"index"`
+	AdminID            int
+}
+
 type ErrorGroupAdminsView struct {
 	ErrorGroupID int       `gorm:"primaryKey"`
 	AdminID      int       `gorm:"primaryKey"`
@@ -1135,10 +1198,12 @@ var Fingerprint = struct {
 	StackFrameCode     FingerprintType
 	StackFrameMetadata FingerprintType
 	JsonResult         FingerprintType
+	Custom             FingerprintType
 }{
 	StackFrameCode:     "CODE",
 	StackFrameMetadata: "META",
 	JsonResult:         "JSON",
+	Custom:             "CUSTOM",
 }
 
 type ErrorFingerprint struct {
@@ -1150,6 +1215,66 @@ type ErrorFingerprint struct {
 	Index        int
 }
 
+// ErrorGroupingRule customizes how errors matching Query are grouped. Rules are
+// evaluated in Priority order before an error is matched to an error group.
+type ErrorGroupingRule struct {
+	Model
+	ProjectID           int `gorm:"index;not null"`
+	Name                string
+	Query               string
+	Action              modelInputs.ErrorGroupingRuleAction
+	FingerprintTemplate *string
+	FramePattern        *string
+	Priority            int
+	Disabled            bool `gorm:"default:false"`
+}
+
+// ErrorGroupAssignmentRule assigns unassigned error groups receiving errors that match Query.
+// The first enabled rule in Priority order wins.
+type ErrorGroupAssignmentRule struct {
+	Model
+	ProjectID       int `gorm:"index;not null"`
+	Name            string
+	Query           string
+	AssigneeAdminID *int
+	AssigneeTeam    *string
+	Priority        int
+	Disabled        bool `gorm:"default:false"`
+}
+
+// RedactionRule replaces personal data found by Detector, or by Pattern for regex rules,
+// in the project's session replays, logs, traces and errors when they are ingested.
+type RedactionRule struct {
+	Model
+	ProjectID      int `gorm:"index;not null"`
+	Name           string
+	Detector       modelInputs.RedactionDetector
+	Pattern        *string
+	Disabled       bool  `gorm:"default:false"`
+	RedactionCount int64 `gorm:"default:0"`
+}
+
+// ProjectDataKey encrypts the session payloads stored for a project. It is wrapped by the master key MasterKeyID
+// of the configured KMS. The latest key of a project encrypts new payloads, older keys still decrypt existing ones.
+// Deleting the keys of a project crypto-shreds its stored payloads.
+type ProjectDataKey struct {
+	Model
+	ProjectID   int `gorm:"index;not null"`
+	MasterKeyID string
+	WrappedKey  []byte
+}
+
+// AssigneeDestination is where an admin or a team is notified about error groups assigned to them.
+type AssigneeDestination struct {
+	Model
+	ProjectID       int `gorm:"index;not null"`
+	AssigneeAdminID *int
+	AssigneeTeam    *string
+	DestinationType modelInputs.AlertDestinationType
+	TypeID          string
+	TypeName        string
+}
+
 type ExternalAttachment struct {
 	Model
 	IntegrationType modelInputs.IntegrationType
@@ -1335,6 +1460,100 @@ type SSOClient struct {
 	ProviderURL  string
 }
 
+// SAMLClient configures SAML 2.0 single sign-on for the admins of an email domain.
+type SAMLClient struct {
+	Domain string `gorm:"primary_key"`
+
+	// IdPMetadata is the identity provider metadata XML. IdPMetadataURL is fetched when it is empty.
+	IdPMetadata    string
+	IdPMetadataURL string
+	// WorkspaceID is the workspace that admins are provisioned into when they first sign in.
+	WorkspaceID int
+	// NameAttribute, EmailAttribute and RoleAttribute are the assertion attributes mapped to the admin.
+	// The email falls back to the assertion NameID.
+	NameAttribute  string
+	EmailAttribute string
+	RoleAttribute  string
+	// AdminRoleValues are comma separated values of RoleAttribute that map to the ADMIN workspace role.
+	AdminRoleValues string
+	DefaultRole     string `gorm:"default:MEMBER"`
+}
+
+// SCIMToken authenticates a workspace's identity provider against the SCIM API.
+// Only a hash of the token is stored.
+type SCIMToken struct {
+	Model
+	WorkspaceID int `gorm:"index"`
+	Name        string
+	TokenHash   string `gorm:"uniqueIndex" json:"-"`
+	LastUsedAt  *time.Time
+}
+
+// APIToken authenticates requests to the private graph. Personal tokens act as the admin
+// that created them; service account tokens act on behalf of the workspace.
+// Only a hash of the token is stored.
+type APIToken struct {
+	Model
+	WorkspaceID int `gorm:"index"`
+	AdminID     int
+	Name        string
+	Type        modelInputs.APITokenType
+	// Prefix is the start of the token, shown so that tokens can be told apart.
+	Prefix     string
+	TokenHash  string         `gorm:"uniqueIndex" json:"-"`
+	Scopes     pq.StringArray `gorm:"type:text[]"`
+	ProjectIds pq.Int32Array  `gorm:"type:integer[]"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+}
+
+// SCIMUser is an admin provisioned into a workspace with SCIM.
+// Inactive users are removed from the workspace.
+type SCIMUser struct {
+	Model
+	WorkspaceID int    `gorm:"uniqueIndex:idx_scim_users_workspace_user_name;uniqueIndex:idx_scim_users_workspace_admin"`
+	AdminID     int    `gorm:"uniqueIndex:idx_scim_users_workspace_admin"`
+	UserName    string `gorm:"uniqueIndex:idx_scim_users_workspace_user_name"`
+	ExternalID  *string
+	GivenName   string
+	FamilyName  string
+	DisplayName string
+	Email       string
+	Active      bool
+	Groups      []*SCIMGroup `gorm:"many2many:scim_group_members"`
+}
+
+// SCIMGroup maps an identity provider group to the workspace role and project access of its members.
+// Members of an ADMIN group are workspace admins. Otherwise, members can access the union of their groups' projects,
+// where a group without ProjectIds grants access to all projects.
+type SCIMGroup struct {
+	Model
+	WorkspaceID int `gorm:"index"`
+	DisplayName string
+	ExternalID  *string
+	Role        string        `gorm:"default:MEMBER"`
+	ProjectIds  pq.Int32Array `gorm:"type:integer[]"`
+	Members     []*SCIMUser   `gorm:"many2many:scim_group_members"`
+}
+
+// AuditLog records a change made to a workspace. Rows are append-only and
+// are only deleted once they are older than the workspace's retention.
+type AuditLog struct {
+	ID          int64     `gorm:"primary_key;type:bigint;autoIncrement" json:"id"`
+	CreatedAt   time.Time `gorm:"index:idx_audit_logs_workspace_id_created_at,priority:2" json:"created_at"`
+	WorkspaceID int       `gorm:"index:idx_audit_logs_workspace_id_created_at,priority:1" json:"workspace_id"`
+	ProjectID   *int      `json:"project_id"`
+	AdminID     *int      `json:"admin_id"`
+	AdminEmail  string    `json:"admin_email"`
+	Action      string    `json:"action"`
+	TargetType  string    `json:"target_type"`
+	TargetID    string    `json:"target_id"`
+	// the changed fields of the target, as {"field": {"before": ..., "after": ...}}
+	Diff      JSONB  `gorm:"type:jsonb" json:"diff"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+}
+
 var ErrorType = struct {
 	FRONTEND string
 	BACKEND  string
@@ -1722,6 +1941,27 @@ func MigrateDB(ctx context.Context, DB *gorm.DB) (bool, error) {
 		return false, err
 	}
 
+	// audit logs are append-only, so reject any update to an existing row
+	if err := DB.Exec(`
+		CREATE OR REPLACE FUNCTION prevent_audit_log_update() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'audit_logs are append-only';
+		END;
+		$$ LANGUAGE PLPGSQL;
+
+		DO $$
+			BEGIN
+				IF NOT EXISTS
+					(SELECT * FROM pg_trigger WHERE tgname = 'audit_logs_append_only')
+				THEN
+					CREATE TRIGGER audit_logs_append_only BEFORE UPDATE ON audit_logs
+						FOR EACH ROW EXECUTE FUNCTION prevent_audit_log_update();
+				END IF;
+		END $$;
+	`).Error; err != nil {
+		return false, e.Wrap(err, "Error creating audit_logs_append_only trigger")
+	}
+
 	log.WithContext(ctx).Printf("Finished running DB migrations.\n")
 
 	return true, nil
@@ -2096,6 +2336,19 @@ type Service struct {
 	ProcessDescription *string
 }
 
+// Release is a version of a project's services, identified by the `service.version`
+// reported with errors, sessions and traces.
+type Release struct {
+	Model
+	ProjectID   int    `gorm:"not null;uniqueIndex:idx_releases_project_id_version"`
+	Version     string `gorm:"not null;uniqueIndex:idx_releases_project_id_version"`
+	CommitSha   *string
+	DeployedAt  *time.Time
+	FirstSeenAt time.Time `gorm:"not null;index"`
+	// LastSeenAt is updated at most once per store.RELEASE_SEEN_INTERVAL while the release reports data.
+	LastSeenAt *time.Time
+}
+
 type LogAlert struct {
 	Model
 	AlertDeprecated
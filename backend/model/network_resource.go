@@ -0,0 +1,70 @@
+package model
+
+import (
+	"time"
+)
+
+type NetworkRequest struct {
+	ID         string `json:"id"`
+	URL        string `json:"url"`
+	Method     string `json:"verb"`
+	HeadersRaw any    `json:"headers"`
+	Body       any    `json:"body"`
+}
+
+type NetworkResponse struct {
+	Status     float64 `json:"status"`
+	Size       float64 `json:"size"`
+	HeadersRaw any     `json:"headers"`
+	Body       any     `json:"body"`
+}
+
+type RequestResponsePairs struct {
+	Request    NetworkRequest  `json:"request"`
+	Response   NetworkResponse `json:"response"`
+	URLBlocked bool            `json:"urlBlocked"`
+}
+
+type NetworkResource struct {
+	// Deprecated, use the absolute version `StartTimeAbs` instead
+	StartTime float64 `json:"startTime"`
+	// Deprecated, use the absolute version `ResponseEndAbs` instead
+	ResponseEnd float64 `json:"responseEnd"`
+
+	StartTimeAbs             float64              `json:"startTimeAbs"`
+	ResponseEndAbs           float64              `json:"responseEndAbs"`
+	ConnectStartAbs          float64              `json:"connectStartAbs"`
+	ConnectEndAbs            float64              `json:"connectEndAbs"`
+	DomainLookupStartAbs     float64              `json:"domainLookupStartAbs"`
+	DomainLookupEndAbs       float64              `json:"domainLookupEndAbs"`
+	FetchStartAbs            float64              `json:"fetchStartAbs"`
+	RedirectStartAbs         float64              `json:"redirectStartAbs"`
+	RedirectEndAbs           float64              `json:"redirectEndAbs"`
+	RequestStartAbs          float64              `json:"requestStartAbs"`
+	ResponseStartAbs         float64              `json:"responseStartAbs"`
+	SecureConnectionStartAbs float64              `json:"secureConnectionStartAbs"`
+	WorkerStartAbs           float64              `json:"workerStartAbs"`
+	DecodedBodySize          float64              `json:"decodedBodySize"`
+	TransferSize             float64              `json:"transferSize"`
+	EncodedBodySize          float64              `json:"encodedBodySize"`
+	NextHopProtocol          string               `json:"nextHopProtocol"`
+	InitiatorType            string               `json:"initiatorType"`
+	Name                     string               `json:"name"`
+	RequestResponsePairs     RequestResponsePairs `json:"requestResponsePairs"`
+}
+
+func (re *NetworkResource) Start(sessionStart time.Time) time.Time {
+	start := time.UnixMicro(int64(1000. * re.StartTimeAbs))
+	if start.Before(time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)) {
+		start = sessionStart.Add(time.Microsecond * time.Duration(1000.*re.StartTime))
+	}
+	return start
+}
+
+func (re *NetworkResource) End(sessionStart time.Time) time.Time {
+	end := time.UnixMicro(int64(1000. * re.ResponseEndAbs))
+	if end.Before(time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)) {
+		end = sessionStart.Add(time.Microsecond * time.Duration(1000.*re.ResponseEnd))
+	}
+	return end
+}
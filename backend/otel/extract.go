@@ -85,6 +85,8 @@ type extractFieldsParams struct {
 	curTime          time.Time
 
 	herokuProjectExtractor func(context.Context, string) (string, int)
+	// redactors applies the redaction rules of the project the data belongs to, when set
+	redactors *projectRedactors
 }
 
 func extractFields(ctx context.Context, params extractFieldsParams) (*extractedFields, error) {
@@ -349,6 +351,9 @@ func extractFields(ctx context.Context, params extractFieldsParams) (*extractedF
 
 	var err error
 	fields.projectIDInt, err = projectToInt(fields.projectID)
+	if err == nil && params.redactors != nil {
+		fields.redact(params.redactors.get(ctx, fields.projectIDInt))
+	}
 
 	if fields.projectIDInt == 1 && env.IsProduction() {
 		if fields.serviceName == "all" || fields.serviceName == "" {
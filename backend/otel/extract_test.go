@@ -5,7 +5,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/highlight-run/highlight/backend/model"
 	modelInputs "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/highlight-run/highlight/backend/redact"
 	"github.com/highlight/highlight/sdk/highlight-go"
 	"github.com/stretchr/testify/assert"
 	"go.opentelemetry.io/collector/pdata/pcommon"
@@ -382,3 +384,27 @@ func TestExtractFields_HandleInvalidTimestamp(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, curTime, fields.timestamp)
 }
+
+func TestExtractFields_Redact(t *testing.T) {
+	redactor, err := redact.New([]*model.RedactionRule{{Model: model.Model{ID: 1}, Detector: modelInputs.RedactionDetectorEmail}})
+	assert.NoError(t, err)
+	redactors := &projectRedactors{redactors: map[int]*redact.Redactor{1: redactor}}
+
+	resource := newResource(t, map[string]any{"user.email": "vadim@highlight.io"})
+	span := newSpan(map[string]string{})
+	span.Events().AppendEmpty().Attributes().PutStr("customer", "jay@highlight.io")
+	event := newEvent(map[string]string{
+		string(semconv.ExceptionMessageKey): "no account for vadim@highlight.io",
+	})
+	fields, err := extractFields(ctx, extractFieldsParams{resource: &resource, span: &span, event: &event, redactors: redactors})
+	assert.NoError(t, err)
+	assert.Equal(t, "no account for [REDACTED]", fields.exceptionMessage)
+	assert.Equal(t, map[string]string{"user.email": "[REDACTED]"}, fields.attrs)
+	// the exception message, the resource attribute and the event attribute holding the exception message
+	assert.Equal(t, map[int]int64{1: 3}, redactor.TakeCounts())
+
+	fields, err = extractFields(ctx, extractFieldsParams{resource: &resource, span: &span, redactors: redactors})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"customer": "[REDACTED]"}, fields.events[0]["Attributes"])
+	assert.Equal(t, map[int]int64{1: 2}, redactor.TakeCounts())
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/highlight-run/highlight/backend/public-graph/graph"
 	"github.com/highlight-run/highlight/backend/public-graph/graph/model"
 	"github.com/highlight-run/highlight/backend/redis"
+	"github.com/highlight-run/highlight/backend/spanmetrics"
 	"github.com/highlight-run/highlight/backend/stacktraces"
 	"github.com/highlight/highlight/sdk/highlight-go"
 	highlightChi "github.com/highlight/highlight/sdk/highlight-go/middleware/chi"
@@ -152,6 +153,7 @@ func (o *Handler) HandleTrace(w http.ResponseWriter, r *http.Request) {
 	var projectTraceMetrics = make(map[string]map[string][]*clickhouse.MetricSumRow)
 
 	curTime := time.Now()
+	redactors := newProjectRedactors(o.resolver.Store)
 
 	spans := req.Traces().ResourceSpans()
 	for i := 0; i < spans.Len(); i++ {
@@ -174,10 +176,11 @@ func (o *Handler) HandleTrace(w http.ResponseWriter, r *http.Request) {
 				}
 
 				fields, err := extractFields(ctx, extractFieldsParams{
-					headers:  r.Header,
-					resource: &resource,
-					span:     &span,
-					curTime:  curTime,
+					headers:   r.Header,
+					resource:  &resource,
+					span:      &span,
+					curTime:   curTime,
+					redactors: redactors,
 				})
 				if err != nil {
 					lg(ctx, fields).
@@ -196,12 +199,13 @@ func (o *Handler) HandleTrace(w http.ResponseWriter, r *http.Request) {
 					}
 					event := events.At(l)
 					fields, err := extractFields(ctx, extractFieldsParams{
-						headers:  r.Header,
-						resource: &resource,
-						scope:    &scope,
-						span:     &span,
-						event:    &event,
-						curTime:  curTime,
+						headers:   r.Header,
+						resource:  &resource,
+						scope:     &scope,
+						span:      &span,
+						event:     &event,
+						curTime:   curTime,
+						redactors: redactors,
 					})
 					if err != nil {
 						lg(ctx, fields).
@@ -335,6 +339,8 @@ func (o *Handler) HandleTrace(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	redactors.record(ctx)
+
 	for _, traceMetrics := range projectTraceMetrics {
 		for sessionID, metrics := range traceMetrics {
 			var messages []kafkaqueue.RetryableMessage
@@ -399,6 +405,7 @@ func (o *Handler) HandleLog(w http.ResponseWriter, r *http.Request) {
 	var projectSessionErrors = make(map[string]map[string][]*model.BackendErrorObjectInput)
 
 	var curTime = time.Now()
+	redactors := newProjectRedactors(o.resolver.Store)
 
 	resourceLogs := req.Logs().ResourceLogs()
 	for i := 0; i < resourceLogs.Len(); i++ {
@@ -418,6 +425,7 @@ func (o *Handler) HandleLog(w http.ResponseWriter, r *http.Request) {
 					logRecord:              &logRecord,
 					curTime:                curTime,
 					herokuProjectExtractor: o.matchHerokuDrain,
+					redactors:              redactors,
 				})
 				if err != nil {
 					lg(ctx, fields).
@@ -463,6 +471,8 @@ func (o *Handler) HandleLog(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	redactors.record(ctx)
+
 	if err := o.submitProjectLogs(ctx, projectLogs); err != nil {
 		log.WithContext(ctx).WithError(err).Error("failed to submit otel project logs")
 		w.WriteHeader(http.StatusServiceUnavailable)
@@ -752,12 +762,15 @@ func (o *Handler) submitTraceSpans(ctx context.Context, traceRows map[string][]*
 		quotaExceededByProject = map[uint32]bool{}
 	}
 
+	spanMetrics := spanmetrics.NewGenerator()
 	for traceID, traceRows := range traceRows {
 		var messages []kafkaqueue.RetryableMessage
 		for _, traceRow := range traceRows {
 			if quotaExceededByProject[traceRow.ProjectId] {
 				continue
 			}
+			// generate RED metrics before sampling so that rates reflect all received spans
+			spanMetrics.Add(traceRow)
 			if !o.resolver.IsTraceIngested(ctx, traceRow) {
 				continue
 			}
@@ -787,6 +800,63 @@ func (o *Handler) submitTraceSpans(ctx context.Context, traceRows map[string][]*
 		}
 	}
 
+	if err := o.submitSpanMetrics(ctx, spanMetrics); err != nil {
+		// span metrics are derived data, so don't fail the trace ingest
+		log.WithContext(ctx).WithError(err).Error("failed to submit span metrics")
+	}
+
+	return nil
+}
+
+func (o *Handler) submitSpanMetrics(ctx context.Context, generator *spanmetrics.Generator) error {
+	projectMetricRows := generator.MetricRows(func(projectID int) uint8 {
+		return o.resolver.GetProjectMetricRetention(ctx, projectID)
+	})
+	if len(projectMetricRows) == 0 {
+		return nil
+	}
+
+	// span metrics are billed and filtered like any other ingested metric
+	projectIds := lo.MapEntries(projectMetricRows, func(p int, _ []clickhouse.MetricRow) (uint32, struct{}) {
+		return uint32(p), struct{}{}
+	})
+	quotaExceededByProject, err := o.getQuotaExceededByProject(ctx, projectIds, model2.PricingProductTypeMetrics)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		quotaExceededByProject = map[uint32]bool{}
+	}
+
+	var sumMessages, histogramMessages []kafkaqueue.RetryableMessage
+	for projectID, metricRows := range projectMetricRows {
+		if quotaExceededByProject[uint32(projectID)] {
+			continue
+		}
+		for _, metricRow := range metricRows {
+			if !o.resolver.IsMetricIngested(ctx, metricRow) {
+				continue
+			}
+			if metricSumRow, ok := metricRow.(*clickhouse.MetricSumRow); ok {
+				sumMessages = append(sumMessages, &kafkaqueue.OTeLMetricSumRow{
+					Type:         kafkaqueue.PushOTeLMetricSum,
+					MetricSumRow: metricSumRow,
+				})
+			}
+			if metricHistogramRow, ok := metricRow.(*clickhouse.MetricHistogramRow); ok {
+				histogramMessages = append(histogramMessages, &kafkaqueue.OTeLMetricHistogramRow{
+					Type:               kafkaqueue.PushOTeLMetricHistogram,
+					MetricHistogramRow: metricHistogramRow,
+				})
+			}
+		}
+	}
+
+	// no ordering for metrics data
+	if err := o.resolver.MetricSumQueue.Submit(ctx, "", sumMessages...); err != nil {
+		return e.Wrap(err, "failed to submit span sum metrics to public worker queue")
+	}
+	if err := o.resolver.MetricHistogramQueue.Submit(ctx, "", histogramMessages...); err != nil {
+		return e.Wrap(err, "failed to submit span histogram metrics to public worker queue")
+	}
 	return nil
 }
 
@@ -904,6 +974,10 @@ func (o *Handler) Listen(r *chi.Mux) {
 		r.HandleFunc("/logs", o.HandleLog)
 		r.HandleFunc("/metrics", o.HandleMetric)
 	})
+	r.Route("/prometheus/api/v1", func(r chi.Router) {
+		r.Use(highlightChi.UseMiddleware(trace.WithSpanKind(trace.SpanKindConsumer)))
+		r.Post("/write", o.HandlePrometheusWrite)
+	})
 }
 
 func New(resolver *graph.Resolver) *Handler {
@@ -0,0 +1,316 @@
+package otel
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/highlight-run/highlight/backend/clickhouse"
+	"github.com/highlight-run/highlight/backend/public-graph/graph"
+	"github.com/highlight/highlight/sdk/highlight-go"
+	e "github.com/pkg/errors"
+	"github.com/samber/lo"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+const (
+	promMetricNameLabel     = "__name__"
+	promJobLabel            = "job"
+	promServiceNameLabel    = "service_name"
+	promServiceVersionLabel = "service_version"
+
+	// prometheus schema used by native histograms with custom bucket boundaries
+	promCustomBucketsSchema = -53
+
+	// limits of the snappy compressed body of a remote-write request and of its decoded protobuf
+	promWriteMaxBodyBytes    = 10 << 20
+	promWriteMaxDecodedBytes = 32 << 20
+)
+
+var promTraceIDLabels = []string{"trace_id", "traceID", "TraceID"}
+var promSpanIDLabels = []string{"span_id", "spanID", "SpanID"}
+
+// getPrometheusProjectID resolves the project for a remote-write request from the
+// highlight project header, basic auth credentials or a bearer token.
+func getPrometheusProjectID(r *http.Request) (int, error) {
+	projectID := r.Header.Get(highlight.ProjectIDHeader)
+	if projectID == "" {
+		if username, password, ok := r.BasicAuth(); ok {
+			projectID = password
+			if projectID == "" {
+				projectID = username
+			}
+		}
+	}
+	if projectID == "" {
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			projectID = strings.TrimSpace(token)
+		}
+	}
+	if projectID == "" {
+		return 0, e.New("no project id provided for prometheus write request")
+	}
+	return projectToInt(projectID)
+}
+
+func promMetricTypes(metadata []promMetricMetadata) map[string]promMetricType {
+	types := make(map[string]promMetricType, len(metadata))
+	for _, m := range metadata {
+		types[m.MetricFamilyName] = m.Type
+	}
+	return types
+}
+
+// isPromCounter uses the metric metadata if it was sent, otherwise falls back
+// to the prometheus naming conventions for counters.
+func isPromCounter(name string, types map[string]promMetricType) bool {
+	if t, ok := types[name]; ok {
+		return t == promMetricTypeCounter
+	}
+	for _, suffix := range []string{"_bucket", "_count", "_sum"} {
+		if family, ok := strings.CutSuffix(name, suffix); ok {
+			if t, ok := types[family]; ok {
+				return t == promMetricTypeCounter || t == promMetricTypeHistogram || t == promMetricTypeSummary
+			}
+		}
+	}
+	return strings.HasSuffix(name, "_total")
+}
+
+func promExemplars(series promTimeSeries) *exemplars {
+	ex := exemplars{}
+	for _, exemplar := range series.Exemplars {
+		attributes := make(map[string]string)
+		var traceID, spanID string
+		for _, l := range exemplar.Labels {
+			if lo.Contains(promTraceIDLabels, l.Name) {
+				traceID = l.Value
+			} else if lo.Contains(promSpanIDLabels, l.Name) {
+				spanID = l.Value
+			} else {
+				attributes[l.Name] = l.Value
+			}
+		}
+		ex.Attributes = append(ex.Attributes, attributes)
+		ex.Timestamps = append(ex.Timestamps, time.UnixMilli(exemplar.Timestamp))
+		ex.Values = append(ex.Values, exemplar.Value)
+		ex.TraceIDs = append(ex.TraceIDs, traceID)
+		ex.SpanIDs = append(ex.SpanIDs, spanID)
+		ex.SecureSessionIDs = append(ex.SecureSessionIDs, "")
+	}
+	return &ex
+}
+
+// promHistogramBuckets converts the sparse exponential buckets of a prometheus native histogram
+// into the explicit bounds representation used by the metrics histogram table.
+func promHistogramBuckets(h promHistogram) ([]float64, []uint64) {
+	type bucket struct {
+		upper float64
+		count float64
+	}
+	var buckets []bucket
+
+	upperBound := func(idx int32) float64 {
+		if h.Schema == promCustomBucketsSchema {
+			if int(idx) >= 0 && int(idx) < len(h.CustomValues) {
+				return h.CustomValues[idx]
+			}
+			return math.Inf(1)
+		}
+		return math.Pow(2, float64(idx)*math.Pow(2, -float64(h.Schema)))
+	}
+
+	iterate := func(spans []promBucketSpan, counts []float64, fn func(idx int32, count float64)) {
+		var idx int32
+		var pos int
+		for _, span := range spans {
+			idx += span.Offset
+			for j := uint32(0); j < span.Length && pos < len(counts); j++ {
+				fn(idx, counts[pos])
+				idx++
+				pos++
+			}
+		}
+	}
+
+	iterate(h.NegativeSpans, h.NegativeCounts, func(idx int32, count float64) {
+		buckets = append(buckets, bucket{upper: -upperBound(idx - 1), count: count})
+	})
+	if h.Schema != promCustomBucketsSchema {
+		buckets = append(buckets, bucket{upper: h.ZeroThreshold, count: h.ZeroCount})
+	}
+	iterate(h.PositiveSpans, h.PositiveCounts, func(idx int32, count float64) {
+		buckets = append(buckets, bucket{upper: upperBound(idx), count: count})
+	})
+
+	sort.SliceStable(buckets, func(i, j int) bool {
+		return buckets[i].upper < buckets[j].upper
+	})
+
+	var bounds []float64
+	var counts []uint64
+	var overflow uint64
+	for _, b := range buckets {
+		if math.IsInf(b.upper, 1) {
+			overflow += uint64(b.count)
+			continue
+		}
+		bounds = append(bounds, b.upper)
+		counts = append(counts, uint64(b.count))
+	}
+	// the last bucket counts values above the highest explicit bound
+	counts = append(counts, overflow)
+	return bounds, counts
+}
+
+func promWriteRequestToMetricRows(req *promWriteRequest, projectID int, retentionDays uint8, curTime time.Time) []clickhouse.MetricRow {
+	types := promMetricTypes(req.Metadata)
+
+	var rows []clickhouse.MetricRow
+	for _, series := range req.Timeseries {
+		base := clickhouse.MetricBaseRow{
+			ProjectId:     uint32(projectID),
+			Attributes:    make(map[string]string),
+			RetentionDays: retentionDays,
+		}
+		for _, l := range series.Labels {
+			switch l.Name {
+			case promMetricNameLabel:
+				base.MetricName = l.Value
+			case promJobLabel, promServiceNameLabel:
+				if base.ServiceName == "" || l.Name == promServiceNameLabel {
+					base.ServiceName = l.Value
+				}
+			case promServiceVersionLabel:
+				base.ServiceVersion = l.Value
+			default:
+				base.Attributes[l.Name] = l.Value
+			}
+		}
+		if base.MetricName == "" {
+			continue
+		}
+
+		ex := promExemplars(series)
+		withExemplars := func(row clickhouse.MetricBaseRow) clickhouse.MetricBaseRow {
+			// exemplars are only attached to the first row of the series to avoid duplicating them
+			row.ExemplarsAttributes = ex.Attributes
+			row.ExemplarsTimestamp = ex.Timestamps
+			row.ExemplarsValue = ex.Values
+			row.ExemplarsTraceID = ex.TraceIDs
+			row.ExemplarsSpanID = ex.SpanIDs
+			row.ExemplarsSecureSessionID = ex.SecureSessionIDs
+			ex = &exemplars{}
+			return row
+		}
+
+		counter := isPromCounter(base.MetricName, types)
+		for _, sample := range series.Samples {
+			// skip stale markers and other non-numeric values
+			if math.IsNaN(sample.Value) {
+				continue
+			}
+			row := &clickhouse.MetricSumRow{
+				MetricBaseRow: withExemplars(base),
+				Value:         sample.Value,
+			}
+			row.Timestamp = graph.ClampTime(time.UnixMilli(sample.Timestamp), curTime)
+			row.StartTimestamp = row.Timestamp
+			if counter {
+				row.MetricType = pmetric.MetricTypeSum
+				row.AggregationTemporality = int32(pmetric.AggregationTemporalityCumulative)
+				row.IsMonotonic = true
+			} else {
+				row.MetricType = pmetric.MetricTypeGauge
+			}
+			rows = append(rows, row)
+		}
+
+		for _, histogram := range series.Histograms {
+			bounds, counts := promHistogramBuckets(histogram)
+			// native histograms do not carry the min / max of observed values
+			row := &clickhouse.MetricHistogramRow{
+				MetricBaseRow:          withExemplars(base),
+				Count:                  uint64(histogram.Count),
+				Sum:                    histogram.Sum,
+				BucketCounts:           counts,
+				ExplicitBounds:         bounds,
+				AggregationTemporality: int32(pmetric.AggregationTemporalityCumulative),
+			}
+			row.MetricType = pmetric.MetricTypeHistogram
+			row.Timestamp = graph.ClampTime(time.UnixMilli(histogram.Timestamp), curTime)
+			row.StartTimestamp = row.Timestamp
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+func (o *Handler) HandlePrometheusWrite(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	projectID, err := getPrometheusProjectID(r)
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Warn("invalid project for prometheus write")
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	compressed, err := io.ReadAll(http.MaxBytesReader(w, r.Body, promWriteMaxBodyBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if e.As(err, &maxBytesErr) {
+			log.WithContext(ctx).WithField("project_id", projectID).Warn("prometheus write body is too large")
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		log.WithContext(ctx).WithError(err).Error("invalid data format for prometheus write")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// the decoded length is read from the payload, so it is checked before allocating the output
+	decodedLen, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Error("invalid snappy block for prometheus write")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if decodedLen > promWriteMaxDecodedBytes {
+		log.WithContext(ctx).WithField("project_id", projectID).WithField("decoded_length", decodedLen).Warn("prometheus write request is too large")
+		http.Error(w, "prometheus write request is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	span, _ := highlight.StartTrace(ctx, "prometheus.proto")
+	// remote-write uses the snappy block format rather than the framed format handled by `GetBody`
+	output, err := snappy.Decode(nil, compressed)
+	var req *promWriteRequest
+	if err == nil {
+		req, err = unmarshalPromWriteRequest(output)
+	}
+	span.RecordError(err)
+	highlight.EndTrace(span)
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Error("invalid prometheus write protobuf")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	retentionDays := o.resolver.GetProjectMetricRetention(ctx, projectID)
+	projectMetrics := map[int][]clickhouse.MetricRow{
+		projectID: promWriteRequestToMetricRows(req, projectID, retentionDays, time.Now()),
+	}
+
+	if err := o.submitProjectMetrics(ctx, projectMetrics); err != nil {
+		log.WithContext(ctx).WithError(err).Error("failed to submit prometheus project metrics")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
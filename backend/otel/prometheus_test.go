@@ -0,0 +1,188 @@
+package otel
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/highlight-run/highlight/backend/clickhouse"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func appendPromLabel(b []byte, name, value string) []byte {
+	var l []byte
+	l = protowire.AppendTag(l, 1, protowire.BytesType)
+	l = protowire.AppendString(l, name)
+	l = protowire.AppendTag(l, 2, protowire.BytesType)
+	l = protowire.AppendString(l, value)
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	return protowire.AppendBytes(b, l)
+}
+
+func appendPromSample(b []byte, value float64, ts int64) []byte {
+	var s []byte
+	s = protowire.AppendTag(s, 1, protowire.Fixed64Type)
+	s = protowire.AppendFixed64(s, math.Float64bits(value))
+	s = protowire.AppendTag(s, 2, protowire.VarintType)
+	s = protowire.AppendVarint(s, uint64(ts))
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	return protowire.AppendBytes(b, s)
+}
+
+func appendPromHistogram(b []byte, ts int64) []byte {
+	var h []byte
+	// count_int
+	h = protowire.AppendTag(h, 1, protowire.VarintType)
+	h = protowire.AppendVarint(h, 6)
+	// sum
+	h = protowire.AppendTag(h, 3, protowire.Fixed64Type)
+	h = protowire.AppendFixed64(h, math.Float64bits(12.5))
+	// schema 0, ie. bucket boundaries are powers of two
+	h = protowire.AppendTag(h, 4, protowire.VarintType)
+	h = protowire.AppendVarint(h, protowire.EncodeZigZag(0))
+	// zero_count_int
+	h = protowire.AppendTag(h, 6, protowire.VarintType)
+	h = protowire.AppendVarint(h, 1)
+	// positive span starting at index 1 of length 2, ie. (1, 2] and (2, 4]
+	var span []byte
+	span = protowire.AppendTag(span, 1, protowire.VarintType)
+	span = protowire.AppendVarint(span, protowire.EncodeZigZag(1))
+	span = protowire.AppendTag(span, 2, protowire.VarintType)
+	span = protowire.AppendVarint(span, 2)
+	h = protowire.AppendTag(h, 11, protowire.BytesType)
+	h = protowire.AppendBytes(h, span)
+	// packed positive deltas of 2, +1 => counts of 2, 3
+	var deltas []byte
+	deltas = protowire.AppendVarint(deltas, protowire.EncodeZigZag(2))
+	deltas = protowire.AppendVarint(deltas, protowire.EncodeZigZag(1))
+	h = protowire.AppendTag(h, 12, protowire.BytesType)
+	h = protowire.AppendBytes(h, deltas)
+	h = protowire.AppendTag(h, 15, protowire.VarintType)
+	h = protowire.AppendVarint(h, uint64(ts))
+	b = protowire.AppendTag(b, 4, protowire.BytesType)
+	return protowire.AppendBytes(b, h)
+}
+
+func newPromWriteRequest(ts int64) []byte {
+	var counter []byte
+	counter = appendPromLabel(counter, "__name__", "http_requests_total")
+	counter = appendPromLabel(counter, "job", "api")
+	counter = appendPromLabel(counter, "method", "GET")
+	counter = appendPromSample(counter, 42, ts)
+	counter = appendPromSample(counter, math.NaN(), ts)
+
+	var gauge []byte
+	gauge = appendPromLabel(gauge, "__name__", "memory_bytes")
+	gauge = appendPromSample(gauge, 1024, ts)
+
+	var histogram []byte
+	histogram = appendPromLabel(histogram, "__name__", "request_duration_seconds")
+	histogram = appendPromHistogram(histogram, ts)
+
+	var req []byte
+	for _, series := range [][]byte{counter, gauge, histogram} {
+		req = protowire.AppendTag(req, 1, protowire.BytesType)
+		req = protowire.AppendBytes(req, series)
+	}
+	return req
+}
+
+func TestPromWriteRequestToMetricRows(t *testing.T) {
+	now := time.Now()
+	ts := now.Add(-time.Minute).UnixMilli()
+
+	req, err := unmarshalPromWriteRequest(newPromWriteRequest(ts))
+	assert.NoError(t, err)
+	assert.Len(t, req.Timeseries, 3)
+
+	rows := promWriteRequestToMetricRows(req, 1, 30, now)
+	assert.Len(t, rows, 3)
+
+	counter := rows[0].(*clickhouse.MetricSumRow)
+	assert.Equal(t, "http_requests_total", counter.MetricName)
+	assert.Equal(t, "api", counter.ServiceName)
+	assert.Equal(t, map[string]string{"method": "GET"}, counter.Attributes)
+	assert.Equal(t, pmetric.MetricTypeSum, counter.MetricType)
+	assert.True(t, counter.IsMonotonic)
+	assert.Equal(t, 42., counter.Value)
+	assert.Equal(t, ts, counter.Timestamp.UnixMilli())
+	assert.Equal(t, uint8(30), counter.RetentionDays)
+
+	gauge := rows[1].(*clickhouse.MetricSumRow)
+	assert.Equal(t, pmetric.MetricTypeGauge, gauge.MetricType)
+	assert.Equal(t, 1024., gauge.Value)
+
+	histogram := rows[2].(*clickhouse.MetricHistogramRow)
+	assert.Equal(t, pmetric.MetricTypeHistogram, histogram.MetricType)
+	assert.Equal(t, uint64(6), histogram.Count)
+	assert.Equal(t, 12.5, histogram.Sum)
+	assert.Equal(t, []float64{0, 2, 4}, histogram.ExplicitBounds)
+	assert.Equal(t, []uint64{1, 2, 3, 0}, histogram.BucketCounts)
+}
+
+func TestPromHistogramBuckets_CustomBounds(t *testing.T) {
+	bounds, counts := promHistogramBuckets(promHistogram{
+		Schema:         promCustomBucketsSchema,
+		PositiveSpans:  []promBucketSpan{{Offset: 0, Length: 3}},
+		PositiveCounts: []float64{1, 2, 3},
+		CustomValues:   []float64{0.1, 0.5},
+	})
+	assert.Equal(t, []float64{0.1, 0.5}, bounds)
+	assert.Equal(t, []uint64{1, 2, 3}, counts)
+}
+
+func TestGetPrometheusProjectID(t *testing.T) {
+	body := snappy.Encode(nil, newPromWriteRequest(0))
+
+	r, _ := http.NewRequest(http.MethodPost, "/prometheus/api/v1/write", bytes.NewReader(body))
+	_, err := getPrometheusProjectID(r)
+	assert.Error(t, err)
+
+	r.Header.Set("x-highlight-project", "1")
+	projectID, err := getPrometheusProjectID(r)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, projectID)
+
+	r, _ = http.NewRequest(http.MethodPost, "/prometheus/api/v1/write", bytes.NewReader(body))
+	r.SetBasicAuth("prometheus", "2")
+	projectID, err = getPrometheusProjectID(r)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, projectID)
+
+	r, _ = http.NewRequest(http.MethodPost, "/prometheus/api/v1/write", bytes.NewReader(body))
+	r.Header.Set("Authorization", "Bearer 3")
+	projectID, err = getPrometheusProjectID(r)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, projectID)
+}
+
+func TestHandlePrometheusWrite_TooLarge(t *testing.T) {
+	h := &Handler{}
+
+	// a snappy block header claiming a decoded length over the limit
+	header := binary.AppendUvarint(nil, promWriteMaxDecodedBytes+1)
+	r := httptest.NewRequest(http.MethodPost, "/prometheus/api/v1/write", bytes.NewReader(header))
+	r.Header.Set("x-highlight-project", "1")
+	w := httptest.NewRecorder()
+	h.HandlePrometheusWrite(w, r)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	r = httptest.NewRequest(http.MethodPost, "/prometheus/api/v1/write", bytes.NewReader(make([]byte, promWriteMaxBodyBytes+1)))
+	r.Header.Set("x-highlight-project", "1")
+	w = httptest.NewRecorder()
+	h.HandlePrometheusWrite(w, r)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	r = httptest.NewRequest(http.MethodPost, "/prometheus/api/v1/write", bytes.NewReader([]byte{0xff}))
+	r.Header.Set("x-highlight-project", "1")
+	w = httptest.NewRecorder()
+	h.HandlePrometheusWrite(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,364 @@
+package otel
+
+import (
+	"math"
+
+	e "github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Minimal decoder for the Prometheus remote-write protobuf (prompb.WriteRequest).
+// Only the fields needed for ingest are decoded; unknown fields are skipped.
+
+type promLabel struct {
+	Name  string
+	Value string
+}
+
+type promSample struct {
+	Value     float64
+	Timestamp int64
+}
+
+type promExemplar struct {
+	Labels    []promLabel
+	Value     float64
+	Timestamp int64
+}
+
+type promBucketSpan struct {
+	Offset int32
+	Length uint32
+}
+
+type promHistogram struct {
+	Count          float64
+	Sum            float64
+	Schema         int32
+	ZeroThreshold  float64
+	ZeroCount      float64
+	NegativeSpans  []promBucketSpan
+	NegativeCounts []float64
+	PositiveSpans  []promBucketSpan
+	PositiveCounts []float64
+	Timestamp      int64
+	CustomValues   []float64
+
+	// integer histograms send bucket counts as deltas to the previous bucket
+	negativeDeltas []int64
+	positiveDeltas []int64
+}
+
+type promTimeSeries struct {
+	Labels     []promLabel
+	Samples    []promSample
+	Exemplars  []promExemplar
+	Histograms []promHistogram
+}
+
+type promMetricType int32
+
+const (
+	promMetricTypeUnknown promMetricType = iota
+	promMetricTypeCounter
+	promMetricTypeGauge
+	promMetricTypeHistogram
+	promMetricTypeGaugeHistogram
+	promMetricTypeSummary
+	promMetricTypeInfo
+	promMetricTypeStateset
+)
+
+type promMetricMetadata struct {
+	Type             promMetricType
+	MetricFamilyName string
+	Help             string
+	Unit             string
+}
+
+type promWriteRequest struct {
+	Timeseries []promTimeSeries
+	Metadata   []promMetricMetadata
+}
+
+// walkProto calls fn for every field in b. fn receives the raw field value which
+// is a varint, fixed64 or length-delimited payload depending on the wire type.
+func walkProto(b []byte, fn func(num protowire.Number, typ protowire.Type, v uint64, payload []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		var v uint64
+		var payload []byte
+		switch typ {
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			v, n = protowire.ConsumeFixed64(b)
+		case protowire.Fixed32Type:
+			var v32 uint32
+			v32, n = protowire.ConsumeFixed32(b)
+			v = uint64(v32)
+		case protowire.BytesType:
+			payload, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		if err := fn(num, typ, v, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// packedOrSingle decodes a repeated scalar field that may be sent either packed
+// or as individual values.
+func packedOrSingle(typ protowire.Type, v uint64, payload []byte, fn func(v uint64)) error {
+	if typ != protowire.BytesType {
+		fn(v)
+		return nil
+	}
+	for len(payload) > 0 {
+		val, n := protowire.ConsumeVarint(payload)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		fn(val)
+		payload = payload[n:]
+	}
+	return nil
+}
+
+func packedDoubles(typ protowire.Type, v uint64, payload []byte) ([]float64, error) {
+	if typ != protowire.BytesType {
+		return []float64{math.Float64frombits(v)}, nil
+	}
+	var values []float64
+	for len(payload) > 0 {
+		val, n := protowire.ConsumeFixed64(payload)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		values = append(values, math.Float64frombits(val))
+		payload = payload[n:]
+	}
+	return values, nil
+}
+
+func unmarshalPromLabel(b []byte) (l promLabel, err error) {
+	err = walkProto(b, func(num protowire.Number, _ protowire.Type, _ uint64, payload []byte) error {
+		switch num {
+		case 1:
+			l.Name = string(payload)
+		case 2:
+			l.Value = string(payload)
+		}
+		return nil
+	})
+	return
+}
+
+func unmarshalPromSample(b []byte) (s promSample, err error) {
+	err = walkProto(b, func(num protowire.Number, _ protowire.Type, v uint64, _ []byte) error {
+		switch num {
+		case 1:
+			s.Value = math.Float64frombits(v)
+		case 2:
+			s.Timestamp = int64(v)
+		}
+		return nil
+	})
+	return
+}
+
+func unmarshalPromExemplar(b []byte) (ex promExemplar, err error) {
+	err = walkProto(b, func(num protowire.Number, _ protowire.Type, v uint64, payload []byte) error {
+		switch num {
+		case 1:
+			l, err := unmarshalPromLabel(payload)
+			if err != nil {
+				return err
+			}
+			ex.Labels = append(ex.Labels, l)
+		case 2:
+			ex.Value = math.Float64frombits(v)
+		case 3:
+			ex.Timestamp = int64(v)
+		}
+		return nil
+	})
+	return
+}
+
+func unmarshalPromBucketSpan(b []byte) (s promBucketSpan, err error) {
+	err = walkProto(b, func(num protowire.Number, _ protowire.Type, v uint64, _ []byte) error {
+		switch num {
+		case 1:
+			s.Offset = int32(protowire.DecodeZigZag(v))
+		case 2:
+			s.Length = uint32(v)
+		}
+		return nil
+	})
+	return
+}
+
+func unmarshalPromHistogram(b []byte) (h promHistogram, err error) {
+	err = walkProto(b, func(num protowire.Number, typ protowire.Type, v uint64, payload []byte) error {
+		switch num {
+		case 1:
+			h.Count = float64(v)
+		case 2:
+			h.Count = math.Float64frombits(v)
+		case 3:
+			h.Sum = math.Float64frombits(v)
+		case 4:
+			h.Schema = int32(protowire.DecodeZigZag(v))
+		case 5:
+			h.ZeroThreshold = math.Float64frombits(v)
+		case 6:
+			h.ZeroCount = float64(v)
+		case 7:
+			h.ZeroCount = math.Float64frombits(v)
+		case 8, 11:
+			span, err := unmarshalPromBucketSpan(payload)
+			if err != nil {
+				return err
+			}
+			if num == 8 {
+				h.NegativeSpans = append(h.NegativeSpans, span)
+			} else {
+				h.PositiveSpans = append(h.PositiveSpans, span)
+			}
+		case 9:
+			return packedOrSingle(typ, v, payload, func(v uint64) {
+				h.negativeDeltas = append(h.negativeDeltas, protowire.DecodeZigZag(v))
+			})
+		case 12:
+			return packedOrSingle(typ, v, payload, func(v uint64) {
+				h.positiveDeltas = append(h.positiveDeltas, protowire.DecodeZigZag(v))
+			})
+		case 10, 13, 16:
+			values, err := packedDoubles(typ, v, payload)
+			if err != nil {
+				return err
+			}
+			switch num {
+			case 10:
+				h.NegativeCounts = append(h.NegativeCounts, values...)
+			case 13:
+				h.PositiveCounts = append(h.PositiveCounts, values...)
+			case 16:
+				h.CustomValues = append(h.CustomValues, values...)
+			}
+		case 15:
+			h.Timestamp = int64(v)
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	// convert integer histogram deltas into absolute bucket counts
+	if len(h.NegativeCounts) == 0 {
+		h.NegativeCounts = deltasToCounts(h.negativeDeltas)
+	}
+	if len(h.PositiveCounts) == 0 {
+		h.PositiveCounts = deltasToCounts(h.positiveDeltas)
+	}
+	return
+}
+
+func deltasToCounts(deltas []int64) []float64 {
+	var counts []float64
+	var cur int64
+	for _, d := range deltas {
+		cur += d
+		counts = append(counts, float64(cur))
+	}
+	return counts
+}
+
+func unmarshalPromTimeSeries(b []byte) (ts promTimeSeries, err error) {
+	err = walkProto(b, func(num protowire.Number, _ protowire.Type, _ uint64, payload []byte) error {
+		switch num {
+		case 1:
+			l, err := unmarshalPromLabel(payload)
+			if err != nil {
+				return err
+			}
+			ts.Labels = append(ts.Labels, l)
+		case 2:
+			s, err := unmarshalPromSample(payload)
+			if err != nil {
+				return err
+			}
+			ts.Samples = append(ts.Samples, s)
+		case 3:
+			ex, err := unmarshalPromExemplar(payload)
+			if err != nil {
+				return err
+			}
+			ts.Exemplars = append(ts.Exemplars, ex)
+		case 4:
+			h, err := unmarshalPromHistogram(payload)
+			if err != nil {
+				return err
+			}
+			ts.Histograms = append(ts.Histograms, h)
+		}
+		return nil
+	})
+	return
+}
+
+func unmarshalPromMetricMetadata(b []byte) (m promMetricMetadata, err error) {
+	err = walkProto(b, func(num protowire.Number, _ protowire.Type, v uint64, payload []byte) error {
+		switch num {
+		case 1:
+			m.Type = promMetricType(v)
+		case 2:
+			m.MetricFamilyName = string(payload)
+		case 4:
+			m.Help = string(payload)
+		case 5:
+			m.Unit = string(payload)
+		}
+		return nil
+	})
+	return
+}
+
+func unmarshalPromWriteRequest(b []byte) (*promWriteRequest, error) {
+	var req promWriteRequest
+	err := walkProto(b, func(num protowire.Number, _ protowire.Type, _ uint64, payload []byte) error {
+		switch num {
+		case 1:
+			ts, err := unmarshalPromTimeSeries(payload)
+			if err != nil {
+				return err
+			}
+			req.Timeseries = append(req.Timeseries, ts)
+		case 3:
+			m, err := unmarshalPromMetricMetadata(payload)
+			if err != nil {
+				return err
+			}
+			req.Metadata = append(req.Metadata, m)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, e.Wrap(err, "failed to decode prometheus write request")
+	}
+	return &req, nil
+}
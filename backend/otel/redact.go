@@ -0,0 +1,68 @@
+package otel
+
+import (
+	"context"
+
+	"github.com/highlight-run/highlight/backend/redact"
+	"github.com/highlight-run/highlight/backend/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// projectRedactors loads the redactor of each project in an otel request once,
+// so that the redactions of the request are recorded together.
+type projectRedactors struct {
+	store     *store.Store
+	redactors map[int]*redact.Redactor
+}
+
+func newProjectRedactors(store *store.Store) *projectRedactors {
+	return &projectRedactors{store: store, redactors: make(map[int]*redact.Redactor)}
+}
+
+// get returns the redactor of the project. When the redaction rules cannot be loaded,
+// the data of the request is ingested as is rather than dropped.
+func (p *projectRedactors) get(ctx context.Context, projectID int) *redact.Redactor {
+	if redactor, ok := p.redactors[projectID]; ok {
+		return redactor
+	}
+	redactor, err := p.store.GetRedactor(ctx, projectID)
+	if err != nil {
+		log.WithContext(ctx).WithError(err).WithField("project_id", projectID).Error("failed to query otel redaction rules")
+	}
+	p.redactors[projectID] = redactor
+	return redactor
+}
+
+func (p *projectRedactors) record(ctx context.Context) {
+	for projectID, redactor := range p.redactors {
+		if err := p.store.RecordRedactions(ctx, redactor); err != nil {
+			log.WithContext(ctx).WithError(err).WithField("project_id", projectID).Error("failed to record otel redactions")
+		}
+	}
+}
+
+// redact replaces the personal data found by the project's redaction rules in the log body, exception and attributes.
+func (fields *extractedFields) redact(redactor *redact.Redactor) {
+	if redactor == nil {
+		return
+	}
+	fields.logBody = redactor.String(fields.logBody)
+	exceptionMessage := redactor.String(fields.exceptionMessage)
+	if fields.logMessage == fields.exceptionMessage {
+		// exceptions use their message as the log message, count its redactions once
+		fields.logMessage = exceptionMessage
+	} else {
+		fields.logMessage = redactor.String(fields.logMessage)
+	}
+	fields.exceptionMessage = exceptionMessage
+	fields.exceptionStackTrace = redactor.JSON(fields.exceptionStackTrace)
+	fields.errorUrl = redactor.String(fields.errorUrl)
+	redactor.Strings(fields.attrs)
+	for _, items := range [][]map[string]any{fields.events, fields.links} {
+		for _, item := range items {
+			if attributes, ok := item["Attributes"].(map[string]any); ok {
+				redactor.Object(attributes)
+			}
+		}
+	}
+}
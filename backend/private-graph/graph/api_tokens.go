@@ -0,0 +1,267 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/highlight-run/highlight/backend/model"
+	modelInputs "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/highlight-run/highlight/backend/store"
+	e "github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// MIN_EXISTING_API_TOKEN_LENGTH is the minimum length of an existing credential registered as an ingest token.
+const MIN_EXISTING_API_TOKEN_LENGTH = 16
+
+type apiTokenResource struct {
+	read  modelInputs.APITokenScope
+	write modelInputs.APITokenScope
+}
+
+var (
+	apiTokenAlerts     = apiTokenResource{read: modelInputs.APITokenScopeAlertsRead, write: modelInputs.APITokenScopeAlertsWrite}
+	apiTokenDashboards = apiTokenResource{read: modelInputs.APITokenScopeDashboardsRead, write: modelInputs.APITokenScopeDashboardsWrite}
+	apiTokenErrors     = apiTokenResource{read: modelInputs.APITokenScopeErrorsRead, write: modelInputs.APITokenScopeErrorsWrite}
+	apiTokenSettings   = apiTokenResource{read: modelInputs.APITokenScopeSettingsRead, write: modelInputs.APITokenScopeSettingsWrite}
+)
+
+// apiTokenFields are the top-level query and mutation fields that API tokens can use, by the resource they belong to.
+// Queries require the resource's read scope and mutations its write scope. Other fields are not available to API tokens.
+var apiTokenFields = map[string]apiTokenResource{
+	// alerts
+	"alerts":                        apiTokenAlerts,
+	"alert":                         apiTokenAlerts,
+	"alerting_alert_state_changes":  apiTokenAlerts,
+	"last_alert_state_changes":      apiTokenAlerts,
+	"error_alerts":                  apiTokenAlerts,
+	"new_user_alerts":               apiTokenAlerts,
+	"track_properties_alerts":       apiTokenAlerts,
+	"user_properties_alerts":        apiTokenAlerts,
+	"new_session_alerts":            apiTokenAlerts,
+	"rage_click_alerts":             apiTokenAlerts,
+	"log_alerts":                    apiTokenAlerts,
+	"log_alert":                     apiTokenAlerts,
+	"metric_monitors":               apiTokenAlerts,
+	"createAlert":                   apiTokenAlerts,
+	"updateAlert":                   apiTokenAlerts,
+	"updateAlertDisabled":           apiTokenAlerts,
+	"deleteAlert":                   apiTokenAlerts,
+	"updateErrorAlert":              apiTokenAlerts,
+	"deleteErrorAlert":              apiTokenAlerts,
+	"updateErrorAlertIsDisabled":    apiTokenAlerts,
+	"updateSessionAlert":            apiTokenAlerts,
+	"deleteSessionAlert":            apiTokenAlerts,
+	"updateSessionAlertIsDisabled":  apiTokenAlerts,
+	"updateLogAlert":                apiTokenAlerts,
+	"deleteLogAlert":                apiTokenAlerts,
+	"updateLogAlertIsDisabled":      apiTokenAlerts,
+	"createMetricMonitor":           apiTokenAlerts,
+	"updateMetricMonitor":           apiTokenAlerts,
+	"deleteMetricMonitor":           apiTokenAlerts,
+	"updateMetricMonitorIsDisabled": apiTokenAlerts,
+	// dashboards
+	"dashboard_definitions": apiTokenDashboards,
+	"visualization":         apiTokenDashboards,
+	"visualizations":        apiTokenDashboards,
+	"graph":                 apiTokenDashboards,
+	"graph_templates":       apiTokenDashboards,
+	"metrics":               apiTokenDashboards,
+	"metric_tags":           apiTokenDashboards,
+	"metric_tag_values":     apiTokenDashboards,
+	"upsertDashboard":       apiTokenDashboards,
+	"deleteDashboard":       apiTokenDashboards,
+	"upsertVisualization":   apiTokenDashboards,
+	"deleteVisualization":   apiTokenDashboards,
+	"upsertGraph":           apiTokenDashboards,
+	"deleteGraph":           apiTokenDashboards,
+	// errors
+	"error_groups":                       apiTokenErrors,
+	"error_groups_clickhouse":            apiTokenErrors,
+	"error_group":                        apiTokenErrors,
+	"error_object":                       apiTokenErrors,
+	"error_objects":                      apiTokenErrors,
+	"error_instance":                     apiTokenErrors,
+	"errors_histogram":                   apiTokenErrors,
+	"errors_histogram_clickhouse":        apiTokenErrors,
+	"errors_keys":                        apiTokenErrors,
+	"errors_key_values":                  apiTokenErrors,
+	"errors_metrics":                     apiTokenErrors,
+	"error_issue":                        apiTokenErrors,
+	"error_comments":                     apiTokenErrors,
+	"errorGroupTags":                     apiTokenErrors,
+	"dailyErrorsCount":                   apiTokenErrors,
+	"dailyErrorFrequency":                apiTokenErrors,
+	"error_tags":                         apiTokenErrors,
+	"release_error_groups":               apiTokenErrors,
+	"error_grouping_rules":               apiTokenErrors,
+	"error_group_assignment_rules":       apiTokenErrors,
+	"updateErrorGroupState":              apiTokenErrors,
+	"markErrorGroupAsViewed":             apiTokenErrors,
+	"mergeErrorGroups":                   apiTokenErrors,
+	"splitErrorGroup":                    apiTokenErrors,
+	"assignErrorGroup":                   apiTokenErrors,
+	"updateErrorGroupIsPublic":           apiTokenErrors,
+	"createErrorComment":                 apiTokenErrors,
+	"createErrorCommentForExistingIssue": apiTokenErrors,
+	"replyToErrorComment":                apiTokenErrors,
+	"deleteErrorComment":                 apiTokenErrors,
+	"muteErrorCommentThread":             apiTokenErrors,
+	"removeErrorIssue":                   apiTokenErrors,
+	"createErrorGroupingRule":            apiTokenErrors,
+	"updateErrorGroupingRule":            apiTokenErrors,
+	"deleteErrorGroupingRule":            apiTokenErrors,
+	"createErrorGroupAssignmentRule":     apiTokenErrors,
+	"updateErrorGroupAssignmentRule":     apiTokenErrors,
+	"deleteErrorGroupAssignmentRule":     apiTokenErrors,
+	"createErrorTag":                     apiTokenErrors,
+	"updateErrorTags":                    apiTokenErrors,
+	// settings
+	"project":                        apiTokenSettings,
+	"projectSettings":                apiTokenSettings,
+	"workspace":                      apiTokenSettings,
+	"workspaceSettings":              apiTokenSettings,
+	"workspace_for_project":          apiTokenSettings,
+	"workspace_admins":               apiTokenSettings,
+	"workspace_admins_by_project_id": apiTokenSettings,
+	"editProject":                    apiTokenSettings,
+	"editProjectSettings":            apiTokenSettings,
+	"editProjectPlatforms":           apiTokenSettings,
+	"editWorkspace":                  apiTokenSettings,
+	"editWorkspaceSettings":          apiTokenSettings,
+	"redaction_rules":                apiTokenSettings,
+	"createRedactionRule":            apiTokenSettings,
+	"updateRedactionRule":            apiTokenSettings,
+	"deleteRedactionRule":            apiTokenSettings,
+}
+
+// apiTokenScopeForField returns the scope an API token needs to resolve the top-level field.
+func apiTokenScopeForField(object string, field string) (modelInputs.APITokenScope, bool) {
+	resource, ok := apiTokenFields[field]
+	if !ok {
+		return "", false
+	}
+	if object == "Mutation" {
+		return resource.write, true
+	}
+	return resource.read, true
+}
+
+// apiTokenHasScope returns whether the token grants the scope. Write scopes also grant reading the same resource.
+func apiTokenHasScope(token *model.APIToken, scope modelInputs.APITokenScope) bool {
+	for _, resource := range []apiTokenResource{apiTokenAlerts, apiTokenDashboards, apiTokenErrors, apiTokenSettings} {
+		if scope == resource.read && lo.Contains(token.Scopes, string(resource.write)) {
+			return true
+		}
+	}
+	return lo.Contains(token.Scopes, string(scope))
+}
+
+func apiTokenFromContext(ctx context.Context) *model.APIToken {
+	token, _ := ctx.Value(model.ContextKeys.APIToken).(*model.APIToken)
+	return token
+}
+
+// getAPITokenFromRequest returns the API token sent as a bearer token, if any.
+// Other bearer tokens are left to the OAuth server.
+func getAPITokenFromRequest(r *http.Request) string {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || !strings.HasPrefix(token, store.API_TOKEN_PREFIX) {
+		return ""
+	}
+	return token
+}
+
+// authenticateAPIToken sets the admin that created the token as the current admin.
+// What the request can do is further limited by the token's scopes and projects.
+func authenticateAPIToken(ctx context.Context, token string) (context.Context, error) {
+	if apiTokenStore == nil {
+		return ctx, AuthenticationError
+	}
+	apiToken, err := apiTokenStore.GetAPIToken(ctx, token)
+	if err != nil {
+		return ctx, err
+	}
+	var admin model.Admin
+	if err := apiTokenStore.DB.WithContext(ctx).Where(&model.Admin{Model: model.Model{ID: apiToken.AdminID}}).Take(&admin).Error; err != nil {
+		return ctx, e.Wrap(err, "error querying api token admin")
+	}
+	if admin.UID == nil {
+		return ctx, AuthenticationError
+	}
+	ctx = context.WithValue(ctx, model.ContextKeys.APIToken, apiToken)
+	ctx = context.WithValue(ctx, model.ContextKeys.UID, *admin.UID)
+	ctx = context.WithValue(ctx, model.ContextKeys.Email, "")
+	return ctx, nil
+}
+
+type apiTokenValidator struct{}
+
+// NewGraphqlAPITokenValidator rejects top-level fields that the request's API token is not scoped for.
+func NewGraphqlAPITokenValidator() OAuthValidator {
+	return &apiTokenValidator{}
+}
+
+func (v *apiTokenValidator) ExtensionName() string {
+	return "HighlightAPITokenValidator"
+}
+
+func (v *apiTokenValidator) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (v *apiTokenValidator) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	token := apiTokenFromContext(ctx)
+	if token == nil {
+		return next(ctx)
+	}
+
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || (fc.Object != "Query" && fc.Object != "Mutation") {
+		return next(ctx)
+	}
+
+	scope, ok := apiTokenScopeForField(fc.Object, fc.Field.Name)
+	if !ok || !apiTokenHasScope(token, scope) {
+		return nil, e.New(fmt.Sprintf("403 - AuthorizationError: %s", fc.Field.Name))
+	}
+	return next(ctx)
+}
+
+// authorizeAPITokenWorkspace checks that the request's API token, if any, can access the whole workspace.
+// Tokens restricted to projects cannot access the workspace.
+func authorizeAPITokenWorkspace(ctx context.Context, workspaceID int) error {
+	token := apiTokenFromContext(ctx)
+	if token == nil {
+		return nil
+	}
+	if token.WorkspaceID != workspaceID || len(token.ProjectIds) > 0 {
+		return AuthorizationError
+	}
+	return nil
+}
+
+// authorizeAPITokenProject checks that the request's API token, if any, can access the project.
+func authorizeAPITokenProject(ctx context.Context, project *model.Project) error {
+	token := apiTokenFromContext(ctx)
+	if token == nil {
+		return nil
+	}
+	if token.WorkspaceID != project.WorkspaceID {
+		return AuthorizationError
+	}
+	if len(token.ProjectIds) > 0 && !lo.Contains(token.ProjectIds, int32(project.ID)) {
+		return AuthorizationError
+	}
+	return nil
+}
+
+// isServiceAccount returns whether the request is authenticated with a service account token, which
+// acts on behalf of the workspace rather than the admin that created it.
+func isServiceAccount(ctx context.Context) bool {
+	token := apiTokenFromContext(ctx)
+	return token != nil && token.Type == modelInputs.APITokenTypeServiceAccount
+}
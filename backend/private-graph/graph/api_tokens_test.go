@@ -0,0 +1,74 @@
+package graph
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/highlight-run/highlight/backend/private-graph/graph/generated"
+	modelInputs "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPITokenFieldsExist(t *testing.T) {
+	schema := generated.NewExecutableSchema(generated.Config{}).Schema()
+	for field := range apiTokenFields {
+		assert.True(t, schema.Query.Fields.ForName(field) != nil || schema.Mutation.Fields.ForName(field) != nil, field)
+	}
+}
+
+func TestAPITokenScopes(t *testing.T) {
+	token := &model.APIToken{Scopes: pq.StringArray{string(modelInputs.APITokenScopeAlertsWrite), string(modelInputs.APITokenScopeErrorsRead)}}
+
+	scope, ok := apiTokenScopeForField("Query", "alerts")
+	assert.True(t, ok)
+	assert.Equal(t, modelInputs.APITokenScopeAlertsRead, scope)
+	assert.True(t, apiTokenHasScope(token, scope))
+
+	scope, ok = apiTokenScopeForField("Mutation", "createAlert")
+	assert.True(t, ok)
+	assert.True(t, apiTokenHasScope(token, scope))
+
+	scope, ok = apiTokenScopeForField("Mutation", "updateErrorGroupState")
+	assert.True(t, ok)
+	assert.Equal(t, modelInputs.APITokenScopeErrorsWrite, scope)
+	assert.False(t, apiTokenHasScope(token, scope))
+
+	assert.False(t, apiTokenHasScope(token, modelInputs.APITokenScopeDashboardsRead))
+
+	// fields that are not listed cannot be used by api tokens
+	_, ok = apiTokenScopeForField("Mutation", "createAPIToken")
+	assert.False(t, ok)
+}
+
+func TestAuthorizeAPIToken(t *testing.T) {
+	project := &model.Project{Model: model.Model{ID: 2}, WorkspaceID: 1}
+
+	// requests without an api token are not limited
+	assert.NoError(t, authorizeAPITokenProject(context.Background(), project))
+	assert.NoError(t, authorizeAPITokenWorkspace(context.Background(), 3))
+
+	ctx := context.WithValue(context.Background(), model.ContextKeys.APIToken, &model.APIToken{WorkspaceID: 1})
+	assert.NoError(t, authorizeAPITokenProject(ctx, project))
+	assert.NoError(t, authorizeAPITokenWorkspace(ctx, 1))
+	assert.Error(t, authorizeAPITokenWorkspace(ctx, 3))
+	assert.Error(t, authorizeAPITokenProject(ctx, &model.Project{Model: model.Model{ID: 4}, WorkspaceID: 3}))
+
+	ctx = context.WithValue(context.Background(), model.ContextKeys.APIToken, &model.APIToken{WorkspaceID: 1, ProjectIds: pq.Int32Array{5}})
+	assert.Error(t, authorizeAPITokenProject(ctx, project))
+	assert.NoError(t, authorizeAPITokenProject(ctx, &model.Project{Model: model.Model{ID: 5}, WorkspaceID: 1}))
+	assert.Error(t, authorizeAPITokenWorkspace(ctx, 1))
+}
+
+func TestGetAPITokenFromRequest(t *testing.T) {
+	r := httptest.NewRequest("POST", "/private", nil)
+	assert.Empty(t, getAPITokenFromRequest(r))
+
+	r.Header.Set("Authorization", "Bearer oauth-access-token")
+	assert.Empty(t, getAPITokenFromRequest(r))
+
+	r.Header.Set("Authorization", "Bearer hlt_abc")
+	assert.Equal(t, "hlt_abc", getAPITokenFromRequest(r))
+}
@@ -0,0 +1,106 @@
+package graph
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/highlight-run/highlight/backend/auditlog"
+	"github.com/highlight-run/highlight/backend/model"
+	modelInputs "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/highlight-run/highlight/backend/store"
+	"github.com/openlyinc/pointy"
+	log "github.com/sirupsen/logrus"
+)
+
+// auditLogEvent is a change made by the current admin. Before is nil for creations and after is nil for deletions.
+type auditLogEvent struct {
+	workspaceID int
+	projectID   *int
+	target      string
+	verb        string
+	targetID    string
+	before      any
+	after       any
+}
+
+func auditLogTargetID(id int) string {
+	return strconv.Itoa(id)
+}
+
+// recordAuditLog appends the change to the workspace audit log. Errors are logged rather than returned
+// since the change has already been made.
+func (r *Resolver) recordAuditLog(ctx context.Context, event auditLogEvent) {
+	diff, err := auditlog.Diff(event.before, event.after)
+	if err != nil {
+		log.WithContext(ctx).WithError(err).WithField("action", auditlog.Action(event.target, event.verb)).Error("failed to diff audit log change")
+		diff = model.JSONB{}
+	}
+
+	ip, _ := ctx.Value(model.ContextKeys.IP).(string)
+	userAgent, _ := ctx.Value(model.ContextKeys.UserAgent).(string)
+	auditLog := &model.AuditLog{
+		WorkspaceID: event.workspaceID,
+		ProjectID:   event.projectID,
+		Action:      auditlog.Action(event.target, event.verb),
+		TargetType:  event.target,
+		TargetID:    event.targetID,
+		Diff:        diff,
+		IP:          ip,
+		UserAgent:   userAgent,
+	}
+	if admin, err := r.getCurrentAdmin(ctx); err == nil {
+		auditLog.AdminID = &admin.ID
+		auditLog.AdminEmail = pointy.StringValue(admin.Email, "")
+	}
+
+	if err := r.Store.CreateAuditLog(ctx, auditLog); err != nil {
+		log.WithContext(ctx).WithError(err).WithField("workspaceID", event.workspaceID).WithField("action", auditLog.Action).Error("failed to record audit log")
+	}
+}
+
+// recordProjectAuditLog records a change to a resource of the project.
+func (r *Resolver) recordProjectAuditLog(ctx context.Context, project *model.Project, target string, verb string, targetID int, before any, after any) {
+	r.recordAuditLog(ctx, auditLogEvent{
+		workspaceID: project.WorkspaceID,
+		projectID:   &project.ID,
+		target:      target,
+		verb:        verb,
+		targetID:    auditLogTargetID(targetID),
+		before:      before,
+		after:       after,
+	})
+}
+
+// auditProjectSettings flattens a project and its filter settings into the fields of one audit log diff.
+type auditProjectSettings struct {
+	*model.Project
+	*model.ProjectFilterSettings
+}
+
+// auditErrorGroupMerge records the error groups merged into an error group.
+type auditErrorGroupMerge struct {
+	MergedErrorGroupIDs []int `json:"merged_error_group_ids"`
+}
+
+// auditErrorGroupSplit records the error objects split from an error group into a new error group.
+type auditErrorGroupSplit struct {
+	SplitErrorGroupID int   `json:"split_error_group_id"`
+	ErrorObjectIDs    []int `json:"error_object_ids"`
+}
+
+func getAuditLogParams(params *modelInputs.AuditLogParamsInput) store.ListAuditLogsParams {
+	if params == nil {
+		return store.ListAuditLogsParams{}
+	}
+	listParams := store.ListAuditLogsParams{
+		ProjectID:  params.ProjectID,
+		AdminID:    params.AdminID,
+		Action:     params.Action,
+		TargetType: params.TargetType,
+	}
+	if params.DateRange != nil {
+		listParams.StartDate = params.DateRange.StartDate
+		listParams.EndDate = params.DateRange.EndDate
+	}
+	return listParams
+}
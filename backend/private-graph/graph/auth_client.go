@@ -9,6 +9,7 @@ import (
 	"firebase.google.com/go/auth"
 	"fmt"
 	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/crewjam/saml"
 	"github.com/go-chi/chi"
 	"github.com/go-redis/cache/v9"
 	"github.com/golang-jwt/jwt/v4"
@@ -57,6 +58,8 @@ type OAuthAuthClient struct {
 	store                  *store.Store
 	oauthClientsByID       map[string]*OAuthClient
 	oauthClientsByProvider map[string]*OAuthClient
+	samlServiceProvider    *saml.ServiceProvider
+	samlClientsByDomain    map[string]*SAMLClient
 }
 
 type CloudAuthClient struct {
@@ -309,6 +312,8 @@ func (c *OAuthAuthClient) SetupListeners(r chi.Router) {
 		r.Post("/oauth/logout", c.handleLogout)
 		r.Get("/oauth/callback", c.handleOAuth2Callback)
 		r.Get("/validate-token", c.validateToken)
+		r.Get("/saml/metadata", c.handleSAMLMetadata)
+		r.Post("/saml/acs", c.handleSAMLAssertion)
 	})
 }
 
@@ -384,6 +389,11 @@ func (c *OAuthAuthClient) handleRedirect(w http.ResponseWriter, r *http.Request)
 	span, ctx := util.StartSpanFromContext(r.Context(), "auth.oauth.handleRedirect")
 	defer span.Finish()
 
+	if domain, ok := extractSAMLDomain(r); ok {
+		c.handleSAMLRedirect(w, r, domain)
+		return
+	}
+
 	state := util.GenerateRandomString(32)
 	c.setCallbackCookie(w, r, stateCookieName, state)
 
@@ -500,6 +510,11 @@ func (c *OAuthAuthClient) updateContextWithAuthenticatedUser(ctx context.Context
 	span.SetAttribute("token", token)
 	defer span.Finish()
 
+	if domain, ok := extractSAMLDomain(req); ok {
+		span.SetAttribute("samlDomain", domain)
+		return c.updateContextWithSAMLUser(ctx, w, req, domain, token)
+	}
+
 	// Parse and verify ID Token payload.
 	clientID := extractClientID(req)
 	span.SetAttribute("clientID", clientID)
@@ -654,7 +669,7 @@ func NewFirebaseClient(ctx context.Context, store *store.Store) *FirebaseAuthCli
 }
 
 func NewOAuthClient(ctx context.Context, store *store.Store) (*OAuthAuthClient, error) {
-	// load sso clients. private graph must be reloaded when new clients are added
+	// load sso and saml clients. private graph must be reloaded when new clients are added
 	ssoClients, err := store.GetSSOClients(ctx)
 	if err != nil {
 		log.WithContext(ctx).WithError(err).Error("failed to load sso clients")
@@ -695,7 +710,23 @@ func NewOAuthClient(ctx context.Context, store *store.Store) (*OAuthAuthClient,
 		oauthClientsByProvider[ssoClient.ProviderURL] = oauthClientsByID[ssoClient.ClientID]
 	}
 
-	return &OAuthAuthClient{store, oauthClientsByID, oauthClientsByProvider}, nil
+	samlServiceProvider, err := newSAMLServiceProvider()
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Error("failed to set up saml service provider")
+		return nil, err
+	}
+	samlClientsByDomain, err := loadSAMLClients(ctx, store, samlServiceProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OAuthAuthClient{
+		store:                  store,
+		oauthClientsByID:       oauthClientsByID,
+		oauthClientsByProvider: oauthClientsByProvider,
+		samlServiceProvider:    samlServiceProvider,
+		samlClientsByDomain:    samlClientsByDomain,
+	}, nil
 }
 
 func authenticateToken(ctx context.Context, tokenString string) (jwt.MapClaims, error) {
@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/netip"
 	"strings"
 
 	"github.com/samber/lo"
@@ -29,6 +31,7 @@ var (
 	OAuthServer           *oauth.Server
 	workspaceTokenHandler APITokenHandler
 	migrationStore        *store.Store
+	apiTokenStore         *store.Store
 )
 
 // migrationBlockedResponse is a GraphQL-formatted JSON error for blocked users.
@@ -205,6 +208,7 @@ func SetupAuthClient(ctx context.Context, store *store.Store, authMode AuthMode,
 	workspaceTokenHandler = wsTokenHandler
 	if store != nil {
 		migrationStore = store
+		apiTokenStore = store
 	}
 
 	log.WithContext(ctx).WithField("mode", authMode).Info("configuring private graph auth client")
@@ -248,6 +252,61 @@ func getSourcemapRequestToken(r *http.Request) string {
 	return graphqlQuery.Variables.APIKey
 }
 
+// trustedProxies are the networks of the proxies in front of the private graph.
+// The client IP forwarded in the request headers is only used for requests from them.
+var trustedProxies = parseTrustedProxies(env.Config.TrustedProxies)
+
+func parseTrustedProxies(proxies string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, proxy := range strings.Split(proxies, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if addr, err := netip.ParseAddr(proxy); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+		} else if prefix, err := netip.ParsePrefix(proxy); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+		} else {
+			log.WithContext(context.Background()).WithField("proxy", proxy).Warn("invalid trusted proxy")
+		}
+	}
+	return prefixes
+}
+
+func isTrustedProxy(ip string) bool {
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	return lo.ContainsBy(trustedProxies, func(prefix netip.Prefix) bool {
+		return prefix.Contains(addr)
+	})
+}
+
+func getRequestIP(r *http.Request) string {
+	// the headers can be set by any client, so they are only used when set by a trusted proxy
+	if !isTrustedProxy(r.RemoteAddr) {
+		return r.RemoteAddr
+	}
+	if ip := r.Header.Get("X-Real-Ip"); ip != "" {
+		return ip
+	}
+	// proxies append the address they received the request from, so the client
+	// is the last address that was not added by a trusted proxy
+	forwarded := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		if ip := strings.TrimSpace(forwarded[i]); ip != "" && !isTrustedProxy(ip) {
+			return ip
+		}
+	}
+	return r.RemoteAddr
+}
+
 func PrivateMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
@@ -298,6 +357,13 @@ func PrivateMiddleware(next http.Handler) http.Handler {
 					}
 				}
 			}
+		} else if apiToken := getAPITokenFromRequest(r); apiToken != "" {
+			span.SetAttribute("type", "apiToken")
+			ctx, err = authenticateAPIToken(ctx, apiToken)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
 		} else if apiKey := r.Header.Get("ApiKey"); apiKey != "" {
 			span.SetAttribute("type", "apiKeyHeader")
 			workspaceID, err := workspaceTokenHandler(ctx, apiKey)
@@ -326,6 +392,9 @@ func PrivateMiddleware(next http.Handler) http.Handler {
 			span.SetAttribute("user_id", tokenInfo.GetUserID())
 		}
 		ctx = context.WithValue(ctx, model.ContextKeys.AcceptEncoding, r.Header.Get("Accept-Encoding"))
+		// recorded in the audit log for changes made by the request
+		ctx = context.WithValue(ctx, model.ContextKeys.IP, getRequestIP(r))
+		ctx = context.WithValue(ctx, model.ContextKeys.UserAgent, r.Header.Get("User-Agent"))
 		r = r.WithContext(ctx)
 		next.ServeHTTP(w, r)
 	})
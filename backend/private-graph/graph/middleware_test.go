@@ -0,0 +1,41 @@
+package graph
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRequestIP(t *testing.T) {
+	proxies := trustedProxies
+	trustedProxies = parseTrustedProxies("10.0.0.0/8, 192.168.1.1")
+	t.Cleanup(func() { trustedProxies = proxies })
+
+	var tests = []struct {
+		Name       string
+		RemoteAddr string
+		RealIP     string
+		Forwarded  string
+		Expected   string
+	}{
+		{Name: "direct", RemoteAddr: "203.0.113.5:1234", Expected: "203.0.113.5:1234"},
+		{Name: "untrusted real ip", RemoteAddr: "203.0.113.5:1234", RealIP: "1.2.3.4", Expected: "203.0.113.5:1234"},
+		{Name: "untrusted forwarded", RemoteAddr: "203.0.113.5:1234", Forwarded: "1.2.3.4", Expected: "203.0.113.5:1234"},
+		{Name: "trusted real ip", RemoteAddr: "10.1.2.3:1234", RealIP: "198.51.100.7", Expected: "198.51.100.7"},
+		{Name: "trusted forwarded", RemoteAddr: "192.168.1.1:1234", Forwarded: "198.51.100.7", Expected: "198.51.100.7"},
+		{Name: "forged forwarded", RemoteAddr: "10.1.2.3:1234", Forwarded: "1.2.3.4, 198.51.100.7, 10.0.0.2", Expected: "198.51.100.7"},
+		{Name: "only proxies forwarded", RemoteAddr: "10.1.2.3:1234", Forwarded: "10.0.0.2", Expected: "10.1.2.3:1234"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", "/", nil)
+		r.RemoteAddr = tt.RemoteAddr
+		if tt.RealIP != "" {
+			r.Header.Set("X-Real-Ip", tt.RealIP)
+		}
+		if tt.Forwarded != "" {
+			r.Header.Set("X-Forwarded-For", tt.Forwarded)
+		}
+		assert.Equal(t, tt.Expected, getRequestIP(r), tt.Name)
+	}
+}
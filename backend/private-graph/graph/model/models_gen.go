@@ -128,12 +128,56 @@ type AllProjectSettings struct {
 	RageClickWindowSeconds            *int           `json:"rage_click_window_seconds,omitempty"`
 	RageClickRadiusPixels             *int           `json:"rage_click_radius_pixels,omitempty"`
 	RageClickCount                    *int           `json:"rage_click_count,omitempty"`
+	DeadClickTimeoutMilliseconds      *int           `json:"dead_click_timeout_milliseconds,omitempty"`
+	ErrorClickWindowMilliseconds      *int           `json:"error_click_window_milliseconds,omitempty"`
+	ThrashedCursorWindowMilliseconds  *int           `json:"thrashed_cursor_window_milliseconds,omitempty"`
+	ThrashedCursorDirectionChanges    *int           `json:"thrashed_cursor_direction_changes,omitempty"`
+	UTurnWindowSeconds                *int           `json:"u_turn_window_seconds,omitempty"`
 	FilterChromeExtension             *bool          `json:"filter_chrome_extension,omitempty"`
 	FilterSessionsWithoutError        bool           `json:"filterSessionsWithoutError"`
 	AutoResolveStaleErrorsDayInterval int            `json:"autoResolveStaleErrorsDayInterval"`
 	Sampling                          *Sampling      `json:"sampling"`
 }
 
+type AuditLogConnection struct {
+	Edges    []*AuditLogEdge `json:"edges"`
+	PageInfo *PageInfo       `json:"pageInfo"`
+}
+
+func (AuditLogConnection) IsConnection()               {}
+func (this AuditLogConnection) GetPageInfo() *PageInfo { return this.PageInfo }
+
+type AuditLogEdge struct {
+	Cursor string        `json:"cursor"`
+	Node   *AuditLogNode `json:"node"`
+}
+
+func (AuditLogEdge) IsEdge()                {}
+func (this AuditLogEdge) GetCursor() string { return this.Cursor }
+
+type AuditLogNode struct {
+	ID          int64          `json:"id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	WorkspaceID int            `json:"workspace_id"`
+	ProjectID   *int           `json:"project_id,omitempty"`
+	AdminID     *int           `json:"admin_id,omitempty"`
+	AdminEmail  string         `json:"admin_email"`
+	Action      string         `json:"action"`
+	TargetType  string         `json:"target_type"`
+	TargetID    string         `json:"target_id"`
+	Diff        map[string]any `json:"diff"`
+	IP          string         `json:"ip"`
+	UserAgent   string         `json:"user_agent"`
+}
+
+type AuditLogParamsInput struct {
+	ProjectID  *int            `json:"project_id,omitempty"`
+	AdminID    *int            `json:"admin_id,omitempty"`
+	Action     *string         `json:"action,omitempty"`
+	TargetType *string         `json:"target_type,omitempty"`
+	DateRange  *DateRangeInput `json:"date_range,omitempty"`
+}
+
 type AverageSessionLength struct {
 	Length float64 `json:"length"`
 }
@@ -323,6 +367,15 @@ type ErrorDistributionItem struct {
 	Value        int64     `json:"value"`
 }
 
+type ErrorGroupAssignmentRuleInput struct {
+	Name            string  `json:"name"`
+	Query           string  `json:"query"`
+	AssigneeAdminID *int    `json:"assignee_admin_id,omitempty"`
+	AssigneeTeam    *string `json:"assignee_team,omitempty"`
+	Priority        *int    `json:"priority,omitempty"`
+	Disabled        *bool   `json:"disabled,omitempty"`
+}
+
 type ErrorGroupFrequenciesParamsInput struct {
 	DateRange         *DateRangeRequiredInput `json:"date_range"`
 	ResolutionMinutes int                     `json:"resolution_minutes"`
@@ -339,6 +392,27 @@ type ErrorGroupTagAggregationBucket struct {
 	Percent  float64 `json:"percent"`
 }
 
+type ErrorGroupingRuleInput struct {
+	Name                string                  `json:"name"`
+	Query               string                  `json:"query"`
+	Action              ErrorGroupingRuleAction `json:"action"`
+	FingerprintTemplate *string                 `json:"fingerprint_template,omitempty"`
+	FramePattern        *string                 `json:"frame_pattern,omitempty"`
+	Priority            *int                    `json:"priority,omitempty"`
+	Disabled            *bool                   `json:"disabled,omitempty"`
+}
+
+type ErrorGroupingRulePreview struct {
+	ErrorObjectID      int      `json:"error_object_id"`
+	ErrorGroupID       int      `json:"error_group_id"`
+	Event              string   `json:"event"`
+	MatchedRules       []string `json:"matched_rules"`
+	Fingerprint        *string  `json:"fingerprint,omitempty"`
+	IgnoredFrames      int      `json:"ignored_frames"`
+	NotInAppFrames     int      `json:"not_in_app_frames"`
+	GroupingKeyChanged bool     `json:"grouping_key_changed"`
+}
+
 type ErrorMetadata struct {
 	ErrorID         int        `json:"error_id"`
 	SessionID       int        `json:"session_id"`
@@ -391,6 +465,7 @@ type ErrorTrace struct {
 	ExternalLink               *string             `json:"externalLink,omitempty"`
 	EnhancementSource          *EnhancementSource  `json:"enhancementSource,omitempty"`
 	EnhancementVersion         *string             `json:"enhancementVersion,omitempty"`
+	InApp                      *bool               `json:"inApp,omitempty"`
 }
 
 type FunnelStep struct {
@@ -437,6 +512,21 @@ type GraphInput struct {
 	SQL               *string                  `json:"sql,omitempty"`
 }
 
+type Heatmap struct {
+	URL          string          `json:"url"`
+	PageViews    int64           `json:"page_views"`
+	Clicks       []*HeatmapClick `json:"clicks"`
+	ScrollDepths []*ScrollDepth  `json:"scroll_depths"`
+}
+
+type HeatmapClick struct {
+	Selector      string `json:"selector"`
+	ViewportWidth int    `json:"viewport_width"`
+	X             int    `json:"x"`
+	Y             int    `json:"y"`
+	Count         int64  `json:"count"`
+}
+
 type HeightList struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
@@ -787,12 +877,33 @@ type RageClickEventForProject struct {
 	UserProperties  string `json:"user_properties"`
 }
 
+type RedactionRuleInput struct {
+	Name     string            `json:"name"`
+	Detector RedactionDetector `json:"detector"`
+	Pattern  *string           `json:"pattern,omitempty"`
+	Disabled *bool             `json:"disabled,omitempty"`
+}
+
 type ReferrerTablePayload struct {
 	Host    string  `json:"host"`
 	Count   int     `json:"count"`
 	Percent float64 `json:"percent"`
 }
 
+type ReleaseStats struct {
+	Version           string        `json:"version"`
+	StartDate         time.Time     `json:"start_date"`
+	EndDate           time.Time     `json:"end_date"`
+	ErrorCount        uint64        `json:"error_count"`
+	ErrorRate         float64       `json:"error_rate"`
+	NewErrorGroups    uint64        `json:"new_error_groups"`
+	SessionCount      uint64        `json:"session_count"`
+	CrashFreeSessions *float64      `json:"crash_free_sessions,omitempty"`
+	SpanCount         uint64        `json:"span_count"`
+	P95Latency        *float64      `json:"p95_latency,omitempty"`
+	Previous          *ReleaseStats `json:"previous,omitempty"`
+}
+
 type S3File struct {
 	Key *string `json:"key,omitempty"`
 }
@@ -861,6 +972,11 @@ type SanitizedSlackChannelInput struct {
 	WebhookChannelID   *string `json:"webhook_channel_id,omitempty"`
 }
 
+type ScrollDepth struct {
+	Depth     int   `json:"depth"`
+	PageViews int64 `json:"page_views"`
+}
+
 type ServiceConnection struct {
 	Edges    []*ServiceEdge `json:"edges"`
 	PageInfo *PageInfo      `json:"pageInfo"`
@@ -998,6 +1114,20 @@ type SubscriptionDiscount struct {
 	Until   *time.Time `json:"until,omitempty"`
 }
 
+type SuspectCommit struct {
+	Sha             string     `json:"sha"`
+	Message         string     `json:"message"`
+	URL             string     `json:"url"`
+	AuthorName      *string    `json:"author_name,omitempty"`
+	AuthorEmail     *string    `json:"author_email,omitempty"`
+	AuthorLogin     *string    `json:"author_login,omitempty"`
+	AuthorAvatarURL *string    `json:"author_avatar_url,omitempty"`
+	CommittedAt     *time.Time `json:"committed_at,omitempty"`
+	FileName        string     `json:"file_name"`
+	LineNumber      int        `json:"line_number"`
+	TouchesLine     bool       `json:"touches_line"`
+}
+
 type TopUsersPayload struct {
 	ID                   int     `json:"id"`
 	Identifier           string  `json:"identifier"`
@@ -1163,6 +1293,23 @@ type WebSocketEvent struct {
 	Size      int     `json:"size"`
 }
 
+type WebVitalMetric struct {
+	Name             string         `json:"name"`
+	P75              float64        `json:"p75"`
+	Rating           WebVitalRating `json:"rating"`
+	Count            int64          `json:"count"`
+	Good             int64          `json:"good"`
+	NeedsImprovement int64          `json:"needs_improvement"`
+	Poor             int64          `json:"poor"`
+	PreviousP75      *float64       `json:"previous_p75,omitempty"`
+	PreviousCount    *int64         `json:"previous_count,omitempty"`
+}
+
+type WebVitalsGroup struct {
+	Key     string            `json:"key"`
+	Metrics []*WebVitalMetric `json:"metrics"`
+}
+
 type WebhookDestinationInput struct {
 	URL           string  `json:"url"`
 	Authorization *string `json:"authorization,omitempty"`
@@ -1178,6 +1325,102 @@ type WorkspaceForInviteLink struct {
 	ProjectID       int        `json:"project_id"`
 }
 
+type APITokenScope string
+
+const (
+	APITokenScopeAlertsRead      APITokenScope = "AlertsRead"
+	APITokenScopeAlertsWrite     APITokenScope = "AlertsWrite"
+	APITokenScopeDashboardsRead  APITokenScope = "DashboardsRead"
+	APITokenScopeDashboardsWrite APITokenScope = "DashboardsWrite"
+	APITokenScopeErrorsRead      APITokenScope = "ErrorsRead"
+	APITokenScopeErrorsWrite     APITokenScope = "ErrorsWrite"
+	APITokenScopeSettingsRead    APITokenScope = "SettingsRead"
+	APITokenScopeSettingsWrite   APITokenScope = "SettingsWrite"
+	APITokenScopeLogsIngest      APITokenScope = "LogsIngest"
+)
+
+var AllAPITokenScope = []APITokenScope{
+	APITokenScopeAlertsRead,
+	APITokenScopeAlertsWrite,
+	APITokenScopeDashboardsRead,
+	APITokenScopeDashboardsWrite,
+	APITokenScopeErrorsRead,
+	APITokenScopeErrorsWrite,
+	APITokenScopeSettingsRead,
+	APITokenScopeSettingsWrite,
+	APITokenScopeLogsIngest,
+}
+
+func (e APITokenScope) IsValid() bool {
+	switch e {
+	case APITokenScopeAlertsRead, APITokenScopeAlertsWrite, APITokenScopeDashboardsRead, APITokenScopeDashboardsWrite, APITokenScopeErrorsRead, APITokenScopeErrorsWrite, APITokenScopeSettingsRead, APITokenScopeSettingsWrite, APITokenScopeLogsIngest:
+		return true
+	}
+	return false
+}
+
+func (e APITokenScope) String() string {
+	return string(e)
+}
+
+func (e *APITokenScope) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = APITokenScope(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid APITokenScope", str)
+	}
+	return nil
+}
+
+func (e APITokenScope) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type APITokenType string
+
+const (
+	APITokenTypePersonal       APITokenType = "Personal"
+	APITokenTypeServiceAccount APITokenType = "ServiceAccount"
+)
+
+var AllAPITokenType = []APITokenType{
+	APITokenTypePersonal,
+	APITokenTypeServiceAccount,
+}
+
+func (e APITokenType) IsValid() bool {
+	switch e {
+	case APITokenTypePersonal, APITokenTypeServiceAccount:
+		return true
+	}
+	return false
+}
+
+func (e APITokenType) String() string {
+	return string(e)
+}
+
+func (e *APITokenType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = APITokenType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid APITokenType", str)
+	}
+	return nil
+}
+
+func (e APITokenType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type AlertDestinationType string
 
 const (
@@ -1408,18 +1651,20 @@ func (e EmailOptOutCategory) MarshalGQL(w io.Writer) {
 type EnhancementSource string
 
 const (
-	EnhancementSourceGithub    EnhancementSource = "github"
-	EnhancementSourceSourcemap EnhancementSource = "sourcemap"
+	EnhancementSourceGithub       EnhancementSource = "github"
+	EnhancementSourceSourcemap    EnhancementSource = "sourcemap"
+	EnhancementSourceSourceBundle EnhancementSource = "source_bundle"
 )
 
 var AllEnhancementSource = []EnhancementSource{
 	EnhancementSourceGithub,
 	EnhancementSourceSourcemap,
+	EnhancementSourceSourceBundle,
 }
 
 func (e EnhancementSource) IsValid() bool {
 	switch e {
-	case EnhancementSourceGithub, EnhancementSourceSourcemap:
+	case EnhancementSourceGithub, EnhancementSourceSourcemap, EnhancementSourceSourceBundle:
 		return true
 	}
 	return false
@@ -1446,23 +1691,70 @@ func (e EnhancementSource) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+type ErrorGroupingRuleAction string
+
+const (
+	ErrorGroupingRuleActionFingerprint  ErrorGroupingRuleAction = "Fingerprint"
+	ErrorGroupingRuleActionIgnoreFrames ErrorGroupingRuleAction = "IgnoreFrames"
+	ErrorGroupingRuleActionNotInApp     ErrorGroupingRuleAction = "NotInApp"
+)
+
+var AllErrorGroupingRuleAction = []ErrorGroupingRuleAction{
+	ErrorGroupingRuleActionFingerprint,
+	ErrorGroupingRuleActionIgnoreFrames,
+	ErrorGroupingRuleActionNotInApp,
+}
+
+func (e ErrorGroupingRuleAction) IsValid() bool {
+	switch e {
+	case ErrorGroupingRuleActionFingerprint, ErrorGroupingRuleActionIgnoreFrames, ErrorGroupingRuleActionNotInApp:
+		return true
+	}
+	return false
+}
+
+func (e ErrorGroupingRuleAction) String() string {
+	return string(e)
+}
+
+func (e *ErrorGroupingRuleAction) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ErrorGroupingRuleAction(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ErrorGroupingRuleAction", str)
+	}
+	return nil
+}
+
+func (e ErrorGroupingRuleAction) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type ErrorState string
 
 const (
-	ErrorStateOpen     ErrorState = "OPEN"
-	ErrorStateResolved ErrorState = "RESOLVED"
-	ErrorStateIgnored  ErrorState = "IGNORED"
+	ErrorStateOpen      ErrorState = "OPEN"
+	ErrorStateResolved  ErrorState = "RESOLVED"
+	ErrorStateIgnored   ErrorState = "IGNORED"
+	ErrorStateRegressed ErrorState = "REGRESSED"
+	ErrorStateMerged    ErrorState = "MERGED"
 )
 
 var AllErrorState = []ErrorState{
 	ErrorStateOpen,
 	ErrorStateResolved,
 	ErrorStateIgnored,
+	ErrorStateRegressed,
+	ErrorStateMerged,
 }
 
 func (e ErrorState) IsValid() bool {
 	switch e {
-	case ErrorStateOpen, ErrorStateResolved, ErrorStateIgnored:
+	case ErrorStateOpen, ErrorStateResolved, ErrorStateIgnored, ErrorStateRegressed, ErrorStateMerged:
 		return true
 	}
 	return false
@@ -2145,12 +2437,13 @@ func (e PlanType) MarshalGQL(w io.Writer) {
 type ProductType string
 
 const (
-	ProductTypeSessions ProductType = "Sessions"
-	ProductTypeErrors   ProductType = "Errors"
-	ProductTypeLogs     ProductType = "Logs"
-	ProductTypeTraces   ProductType = "Traces"
-	ProductTypeMetrics  ProductType = "Metrics"
-	ProductTypeEvents   ProductType = "Events"
+	ProductTypeSessions  ProductType = "Sessions"
+	ProductTypeErrors    ProductType = "Errors"
+	ProductTypeLogs      ProductType = "Logs"
+	ProductTypeTraces    ProductType = "Traces"
+	ProductTypeMetrics   ProductType = "Metrics"
+	ProductTypeEvents    ProductType = "Events"
+	ProductTypeWebVitals ProductType = "WebVitals"
 )
 
 var AllProductType = []ProductType{
@@ -2160,11 +2453,12 @@ var AllProductType = []ProductType{
 	ProductTypeTraces,
 	ProductTypeMetrics,
 	ProductTypeEvents,
+	ProductTypeWebVitals,
 }
 
 func (e ProductType) IsValid() bool {
 	switch e {
-	case ProductTypeSessions, ProductTypeErrors, ProductTypeLogs, ProductTypeTraces, ProductTypeMetrics, ProductTypeEvents:
+	case ProductTypeSessions, ProductTypeErrors, ProductTypeLogs, ProductTypeTraces, ProductTypeMetrics, ProductTypeEvents, ProductTypeWebVitals:
 		return true
 	}
 	return false
@@ -2191,9 +2485,61 @@ func (e ProductType) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+type RedactionDetector string
+
+const (
+	RedactionDetectorEmail       RedactionDetector = "Email"
+	RedactionDetectorCreditCard  RedactionDetector = "CreditCard"
+	RedactionDetectorJwt         RedactionDetector = "JWT"
+	RedactionDetectorIban        RedactionDetector = "IBAN"
+	RedactionDetectorPhoneNumber RedactionDetector = "PhoneNumber"
+	RedactionDetectorRegex       RedactionDetector = "Regex"
+)
+
+var AllRedactionDetector = []RedactionDetector{
+	RedactionDetectorEmail,
+	RedactionDetectorCreditCard,
+	RedactionDetectorJwt,
+	RedactionDetectorIban,
+	RedactionDetectorPhoneNumber,
+	RedactionDetectorRegex,
+}
+
+func (e RedactionDetector) IsValid() bool {
+	switch e {
+	case RedactionDetectorEmail, RedactionDetectorCreditCard, RedactionDetectorJwt, RedactionDetectorIban, RedactionDetectorPhoneNumber, RedactionDetectorRegex:
+		return true
+	}
+	return false
+}
+
+func (e RedactionDetector) String() string {
+	return string(e)
+}
+
+func (e *RedactionDetector) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RedactionDetector(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RedactionDetector", str)
+	}
+	return nil
+}
+
+func (e RedactionDetector) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type ReservedErrorGroupKey string
 
 const (
+	ReservedErrorGroupKeyAssigneeID   ReservedErrorGroupKey = "assignee_id"
+	ReservedErrorGroupKeyAssigneeTeam ReservedErrorGroupKey = "assignee_team"
+	ReservedErrorGroupKeyCodeOwner    ReservedErrorGroupKey = "code_owner"
 	ReservedErrorGroupKeyEvent        ReservedErrorGroupKey = "event"
 	ReservedErrorGroupKeySecureID     ReservedErrorGroupKey = "secure_id"
 	ReservedErrorGroupKeySnoozedUntil ReservedErrorGroupKey = "snoozed_until"
@@ -2203,6 +2549,9 @@ const (
 )
 
 var AllReservedErrorGroupKey = []ReservedErrorGroupKey{
+	ReservedErrorGroupKeyAssigneeID,
+	ReservedErrorGroupKeyAssigneeTeam,
+	ReservedErrorGroupKeyCodeOwner,
 	ReservedErrorGroupKeyEvent,
 	ReservedErrorGroupKeySecureID,
 	ReservedErrorGroupKeySnoozedUntil,
@@ -2213,7 +2562,7 @@ var AllReservedErrorGroupKey = []ReservedErrorGroupKey{
 
 func (e ReservedErrorGroupKey) IsValid() bool {
 	switch e {
-	case ReservedErrorGroupKeyEvent, ReservedErrorGroupKeySecureID, ReservedErrorGroupKeySnoozedUntil, ReservedErrorGroupKeyStatus, ReservedErrorGroupKeyTag, ReservedErrorGroupKeyType:
+	case ReservedErrorGroupKeyAssigneeID, ReservedErrorGroupKeyAssigneeTeam, ReservedErrorGroupKeyCodeOwner, ReservedErrorGroupKeyEvent, ReservedErrorGroupKeySecureID, ReservedErrorGroupKeySnoozedUntil, ReservedErrorGroupKeyStatus, ReservedErrorGroupKeyTag, ReservedErrorGroupKeyType:
 		return true
 	}
 	return false
@@ -2317,11 +2666,14 @@ const (
 	ReservedErrorsJoinedKeyTraceID         ReservedErrorsJoinedKey = "trace_id"
 	ReservedErrorsJoinedKeyVisitedURL      ReservedErrorsJoinedKey = "visited_url"
 	// ReservedErrorGroupKey
-	ReservedErrorsJoinedKeyEvent    ReservedErrorsJoinedKey = "event"
-	ReservedErrorsJoinedKeySecureID ReservedErrorsJoinedKey = "secure_id"
-	ReservedErrorsJoinedKeyStatus   ReservedErrorsJoinedKey = "status"
-	ReservedErrorsJoinedKeyTag      ReservedErrorsJoinedKey = "tag"
-	ReservedErrorsJoinedKeyType     ReservedErrorsJoinedKey = "type"
+	ReservedErrorsJoinedKeyAssigneeID   ReservedErrorsJoinedKey = "assignee_id"
+	ReservedErrorsJoinedKeyAssigneeTeam ReservedErrorsJoinedKey = "assignee_team"
+	ReservedErrorsJoinedKeyCodeOwner    ReservedErrorsJoinedKey = "code_owner"
+	ReservedErrorsJoinedKeyEvent        ReservedErrorsJoinedKey = "event"
+	ReservedErrorsJoinedKeySecureID     ReservedErrorsJoinedKey = "secure_id"
+	ReservedErrorsJoinedKeyStatus       ReservedErrorsJoinedKey = "status"
+	ReservedErrorsJoinedKeyTag          ReservedErrorsJoinedKey = "tag"
+	ReservedErrorsJoinedKeyType         ReservedErrorsJoinedKey = "type"
 )
 
 var AllReservedErrorsJoinedKey = []ReservedErrorsJoinedKey{
@@ -2338,6 +2690,9 @@ var AllReservedErrorsJoinedKey = []ReservedErrorsJoinedKey{
 	ReservedErrorsJoinedKeyTimestamp,
 	ReservedErrorsJoinedKeyTraceID,
 	ReservedErrorsJoinedKeyVisitedURL,
+	ReservedErrorsJoinedKeyAssigneeID,
+	ReservedErrorsJoinedKeyAssigneeTeam,
+	ReservedErrorsJoinedKeyCodeOwner,
 	ReservedErrorsJoinedKeyEvent,
 	ReservedErrorsJoinedKeySecureID,
 	ReservedErrorsJoinedKeyStatus,
@@ -2347,7 +2702,7 @@ var AllReservedErrorsJoinedKey = []ReservedErrorsJoinedKey{
 
 func (e ReservedErrorsJoinedKey) IsValid() bool {
 	switch e {
-	case ReservedErrorsJoinedKeyID, ReservedErrorsJoinedKeyBrowser, ReservedErrorsJoinedKeyClientID, ReservedErrorsJoinedKeyEnvironment, ReservedErrorsJoinedKeyHasSession, ReservedErrorsJoinedKeyOsName, ReservedErrorsJoinedKeySecureSessionID, ReservedErrorsJoinedKeyServiceName, ReservedErrorsJoinedKeyServiceVersion, ReservedErrorsJoinedKeySnoozedUntil, ReservedErrorsJoinedKeyTimestamp, ReservedErrorsJoinedKeyTraceID, ReservedErrorsJoinedKeyVisitedURL, ReservedErrorsJoinedKeyEvent, ReservedErrorsJoinedKeySecureID, ReservedErrorsJoinedKeyStatus, ReservedErrorsJoinedKeyTag, ReservedErrorsJoinedKeyType:
+	case ReservedErrorsJoinedKeyID, ReservedErrorsJoinedKeyBrowser, ReservedErrorsJoinedKeyClientID, ReservedErrorsJoinedKeyEnvironment, ReservedErrorsJoinedKeyHasSession, ReservedErrorsJoinedKeyOsName, ReservedErrorsJoinedKeySecureSessionID, ReservedErrorsJoinedKeyServiceName, ReservedErrorsJoinedKeyServiceVersion, ReservedErrorsJoinedKeySnoozedUntil, ReservedErrorsJoinedKeyTimestamp, ReservedErrorsJoinedKeyTraceID, ReservedErrorsJoinedKeyVisitedURL, ReservedErrorsJoinedKeyAssigneeID, ReservedErrorsJoinedKeyAssigneeTeam, ReservedErrorsJoinedKeyCodeOwner, ReservedErrorsJoinedKeyEvent, ReservedErrorsJoinedKeySecureID, ReservedErrorsJoinedKeyStatus, ReservedErrorsJoinedKeyTag, ReservedErrorsJoinedKeyType:
 		return true
 	}
 	return false
@@ -2591,8 +2946,12 @@ const (
 	ReservedSessionKeyExcluded           ReservedSessionKey = "excluded"
 	ReservedSessionKeyFirstTime          ReservedSessionKey = "first_time"
 	ReservedSessionKeyHasComments        ReservedSessionKey = "has_comments"
+	ReservedSessionKeyHasDeadClicks      ReservedSessionKey = "has_dead_clicks"
+	ReservedSessionKeyHasErrorClicks     ReservedSessionKey = "has_error_clicks"
 	ReservedSessionKeyHasErrors          ReservedSessionKey = "has_errors"
 	ReservedSessionKeyHasRageClicks      ReservedSessionKey = "has_rage_clicks"
+	ReservedSessionKeyHasThrashedCursor  ReservedSessionKey = "has_thrashed_cursor"
+	ReservedSessionKeyHasUTurns          ReservedSessionKey = "has_u_turns"
 	ReservedSessionKeyIdentified         ReservedSessionKey = "identified"
 	ReservedSessionKeyIdentifier         ReservedSessionKey = "identifier"
 	ReservedSessionKeyIP                 ReservedSessionKey = "ip"
@@ -2626,8 +2985,12 @@ var AllReservedSessionKey = []ReservedSessionKey{
 	ReservedSessionKeyExcluded,
 	ReservedSessionKeyFirstTime,
 	ReservedSessionKeyHasComments,
+	ReservedSessionKeyHasDeadClicks,
+	ReservedSessionKeyHasErrorClicks,
 	ReservedSessionKeyHasErrors,
 	ReservedSessionKeyHasRageClicks,
+	ReservedSessionKeyHasThrashedCursor,
+	ReservedSessionKeyHasUTurns,
 	ReservedSessionKeyIdentified,
 	ReservedSessionKeyIdentifier,
 	ReservedSessionKeyIP,
@@ -2652,7 +3015,7 @@ var AllReservedSessionKey = []ReservedSessionKey{
 
 func (e ReservedSessionKey) IsValid() bool {
 	switch e {
-	case ReservedSessionKeyActiveLength, ReservedSessionKeyBrowserName, ReservedSessionKeyBrowserVersion, ReservedSessionKeyCity, ReservedSessionKeyCompleted, ReservedSessionKeyCountry, ReservedSessionKeyEnvironment, ReservedSessionKeyExcluded, ReservedSessionKeyFirstTime, ReservedSessionKeyHasComments, ReservedSessionKeyHasErrors, ReservedSessionKeyHasRageClicks, ReservedSessionKeyIdentified, ReservedSessionKeyIdentifier, ReservedSessionKeyIP, ReservedSessionKeyLength, ReservedSessionKeyNormalness, ReservedSessionKeyOsName, ReservedSessionKeyOsVersion, ReservedSessionKeyPagesVisited, ReservedSessionKeySample, ReservedSessionKeySecureID, ReservedSessionKeyServiceVersion, ReservedSessionKeyState, ReservedSessionKeyTimestamp, ReservedSessionKeyUpdatedAt, ReservedSessionKeyViewedByAnyone, ReservedSessionKeyViewedByMe, ReservedSessionKeyWithinBillingQuota, ReservedSessionKeyLocState, ReservedSessionKeyProcessed, ReservedSessionKeyViewed:
+	case ReservedSessionKeyActiveLength, ReservedSessionKeyBrowserName, ReservedSessionKeyBrowserVersion, ReservedSessionKeyCity, ReservedSessionKeyCompleted, ReservedSessionKeyCountry, ReservedSessionKeyEnvironment, ReservedSessionKeyExcluded, ReservedSessionKeyFirstTime, ReservedSessionKeyHasComments, ReservedSessionKeyHasDeadClicks, ReservedSessionKeyHasErrorClicks, ReservedSessionKeyHasErrors, ReservedSessionKeyHasRageClicks, ReservedSessionKeyHasThrashedCursor, ReservedSessionKeyHasUTurns, ReservedSessionKeyIdentified, ReservedSessionKeyIdentifier, ReservedSessionKeyIP, ReservedSessionKeyLength, ReservedSessionKeyNormalness, ReservedSessionKeyOsName, ReservedSessionKeyOsVersion, ReservedSessionKeyPagesVisited, ReservedSessionKeySample, ReservedSessionKeySecureID, ReservedSessionKeyServiceVersion, ReservedSessionKeyState, ReservedSessionKeyTimestamp, ReservedSessionKeyUpdatedAt, ReservedSessionKeyViewedByAnyone, ReservedSessionKeyViewedByMe, ReservedSessionKeyWithinBillingQuota, ReservedSessionKeyLocState, ReservedSessionKeyProcessed, ReservedSessionKeyViewed:
 		return true
 	}
 	return false
@@ -2752,6 +3115,61 @@ func (e ReservedTraceKey) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+type ReservedWebVitalKey string
+
+const (
+	ReservedWebVitalKeyBrowser         ReservedWebVitalKey = "browser"
+	ReservedWebVitalKeyDeviceClass     ReservedWebVitalKey = "device_class"
+	ReservedWebVitalKeyEnvironment     ReservedWebVitalKey = "environment"
+	ReservedWebVitalKeyMetricName      ReservedWebVitalKey = "metric_name"
+	ReservedWebVitalKeyRelease         ReservedWebVitalKey = "release"
+	ReservedWebVitalKeySecureSessionID ReservedWebVitalKey = "secure_session_id"
+	ReservedWebVitalKeyTimestamp       ReservedWebVitalKey = "timestamp"
+	ReservedWebVitalKeyURL             ReservedWebVitalKey = "url"
+	ReservedWebVitalKeyValue           ReservedWebVitalKey = "value"
+)
+
+var AllReservedWebVitalKey = []ReservedWebVitalKey{
+	ReservedWebVitalKeyBrowser,
+	ReservedWebVitalKeyDeviceClass,
+	ReservedWebVitalKeyEnvironment,
+	ReservedWebVitalKeyMetricName,
+	ReservedWebVitalKeyRelease,
+	ReservedWebVitalKeySecureSessionID,
+	ReservedWebVitalKeyTimestamp,
+	ReservedWebVitalKeyURL,
+	ReservedWebVitalKeyValue,
+}
+
+func (e ReservedWebVitalKey) IsValid() bool {
+	switch e {
+	case ReservedWebVitalKeyBrowser, ReservedWebVitalKeyDeviceClass, ReservedWebVitalKeyEnvironment, ReservedWebVitalKeyMetricName, ReservedWebVitalKeyRelease, ReservedWebVitalKeySecureSessionID, ReservedWebVitalKeyTimestamp, ReservedWebVitalKeyURL, ReservedWebVitalKeyValue:
+		return true
+	}
+	return false
+}
+
+func (e ReservedWebVitalKey) String() string {
+	return string(e)
+}
+
+func (e *ReservedWebVitalKey) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReservedWebVitalKey(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReservedWebVitalKey", str)
+	}
+	return nil
+}
+
+func (e ReservedWebVitalKey) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type RetentionPeriod string
 
 const (
@@ -3362,18 +3780,20 @@ func (e ThresholdCondition) MarshalGQL(w io.Writer) {
 type ThresholdType string
 
 const (
-	ThresholdTypeConstant ThresholdType = "Constant"
-	ThresholdTypeAnomaly  ThresholdType = "Anomaly"
+	ThresholdTypeConstant   ThresholdType = "Constant"
+	ThresholdTypeAnomaly    ThresholdType = "Anomaly"
+	ThresholdTypeRegression ThresholdType = "Regression"
 )
 
 var AllThresholdType = []ThresholdType{
 	ThresholdTypeConstant,
 	ThresholdTypeAnomaly,
+	ThresholdTypeRegression,
 }
 
 func (e ThresholdType) IsValid() bool {
 	switch e {
-	case ThresholdTypeConstant, ThresholdTypeAnomaly:
+	case ThresholdTypeConstant, ThresholdTypeAnomaly, ThresholdTypeRegression:
 		return true
 	}
 	return false
@@ -3399,3 +3819,91 @@ func (e *ThresholdType) UnmarshalGQL(v any) error {
 func (e ThresholdType) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
+
+type WebVitalRating string
+
+const (
+	WebVitalRatingGood             WebVitalRating = "Good"
+	WebVitalRatingNeedsImprovement WebVitalRating = "NeedsImprovement"
+	WebVitalRatingPoor             WebVitalRating = "Poor"
+)
+
+var AllWebVitalRating = []WebVitalRating{
+	WebVitalRatingGood,
+	WebVitalRatingNeedsImprovement,
+	WebVitalRatingPoor,
+}
+
+func (e WebVitalRating) IsValid() bool {
+	switch e {
+	case WebVitalRatingGood, WebVitalRatingNeedsImprovement, WebVitalRatingPoor:
+		return true
+	}
+	return false
+}
+
+func (e WebVitalRating) String() string {
+	return string(e)
+}
+
+func (e *WebVitalRating) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WebVitalRating(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WebVitalRating", str)
+	}
+	return nil
+}
+
+func (e WebVitalRating) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type WebVitalsGroupBy string
+
+const (
+	WebVitalsGroupByURL         WebVitalsGroupBy = "Url"
+	WebVitalsGroupByDeviceClass WebVitalsGroupBy = "DeviceClass"
+	WebVitalsGroupByBrowser     WebVitalsGroupBy = "Browser"
+	WebVitalsGroupByRelease     WebVitalsGroupBy = "Release"
+)
+
+var AllWebVitalsGroupBy = []WebVitalsGroupBy{
+	WebVitalsGroupByURL,
+	WebVitalsGroupByDeviceClass,
+	WebVitalsGroupByBrowser,
+	WebVitalsGroupByRelease,
+}
+
+func (e WebVitalsGroupBy) IsValid() bool {
+	switch e {
+	case WebVitalsGroupByURL, WebVitalsGroupByDeviceClass, WebVitalsGroupByBrowser, WebVitalsGroupByRelease:
+		return true
+	}
+	return false
+}
+
+func (e WebVitalsGroupBy) String() string {
+	return string(e)
+}
+
+func (e *WebVitalsGroupBy) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WebVitalsGroupBy(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WebVitalsGroupBy", str)
+	}
+	return nil
+}
+
+func (e WebVitalsGroupBy) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
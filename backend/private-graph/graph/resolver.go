@@ -41,6 +41,7 @@ import (
 
 	"github.com/highlight-run/highlight/backend/alerts/integrations/discord"
 	microsoft_teams "github.com/highlight-run/highlight/backend/alerts/integrations/microsoft-teams"
+	"github.com/highlight-run/highlight/backend/auditlog"
 	"github.com/highlight-run/highlight/backend/clickhouse"
 	"github.com/highlight-run/highlight/backend/clickup"
 	"github.com/highlight-run/highlight/backend/integrations"
@@ -48,6 +49,7 @@ import (
 	kafka_queue "github.com/highlight-run/highlight/backend/kafka-queue"
 	"github.com/highlight-run/highlight/backend/lambda"
 	"github.com/highlight-run/highlight/backend/oauth"
+	"github.com/highlight-run/highlight/backend/redact"
 	"github.com/highlight-run/highlight/backend/redis"
 	"github.com/highlight-run/highlight/backend/stepfunctions"
 	"github.com/highlight-run/highlight/backend/store"
@@ -73,6 +75,7 @@ import (
 
 	Email "github.com/highlight-run/highlight/backend/email"
 	"github.com/highlight-run/highlight/backend/embeddings"
+	"github.com/highlight-run/highlight/backend/errorgroups"
 	"github.com/highlight-run/highlight/backend/model"
 	"github.com/highlight-run/highlight/backend/pricing"
 	modelInputs "github.com/highlight-run/highlight/backend/private-graph/graph/model"
@@ -186,6 +189,19 @@ func (r *Resolver) createAdmin(ctx context.Context) (*model.Admin, error) {
 	}
 	firebaseSpan.Finish()
 
+	// admins provisioned with SCIM are claimed by the first user signing in with their verified email
+	if firebaseUser.EmailVerified {
+		if claimed, err := r.Store.ClaimProvisionedAdmin(ctx, *adminUID, firebaseUser.Email); err != nil {
+			return nil, e.Wrap(err, "error claiming provisioned admin")
+		} else if claimed {
+			admin := &model.Admin{}
+			if err := r.DB.WithContext(ctx).Where(&model.Admin{UID: adminUID}).Take(admin).Error; err != nil {
+				return nil, e.Wrap(err, "error retrieving provisioned admin")
+			}
+			return admin, nil
+		}
+	}
+
 	adminSpan, _ := util.StartSpanFromContext(ctx, "db.admin", util.ResourceName("resolver.createAdmin"))
 	admin := &model.Admin{
 		UID:                   adminUID,
@@ -307,6 +323,10 @@ func (r *Resolver) formatSanitizedAuthor(admin *model.Admin) *modelInputs.Saniti
 }
 
 func (r *Resolver) isWhitelistedAccount(ctx context.Context) bool {
+	// API tokens are limited to their own workspace regardless of who created them
+	if apiTokenFromContext(ctx) != nil {
+		return false
+	}
 	uid := fmt.Sprintf("%v", ctx.Value(model.ContextKeys.UID))
 	email := fmt.Sprintf("%v", ctx.Value(model.ContextKeys.Email))
 	// Allow access to engineering@highlight.run or any verified @highlight.run / @runhighlight.com email.
@@ -446,17 +466,25 @@ func (r *Resolver) addAdminMembership(ctx context.Context, workspaceId int, invi
 		return nil, e.New("405: This invite link has expired.")
 	}
 
-	if err := r.DB.Clauses(clause.OnConflict{
-		OnConstraint: "workspace_admins_pkey",
-		DoNothing:    true,
-	}).Create(&model.WorkspaceAdmin{
+	workspaceAdmin := &model.WorkspaceAdmin{
 		AdminID:     admin.ID,
 		WorkspaceID: workspace.ID,
 		Role:        inviteLink.InviteeRole,
 		ProjectIds:  inviteLink.ProjectIds,
-	}).Error; err != nil {
+	}
+	if err := r.DB.Clauses(clause.OnConflict{
+		OnConstraint: "workspace_admins_pkey",
+		DoNothing:    true,
+	}).Create(workspaceAdmin).Error; err != nil {
 		return nil, e.Wrap(err, "500: error adding admin to association")
 	}
+	r.recordAuditLog(ctx, auditLogEvent{
+		workspaceID: workspace.ID,
+		target:      auditlog.TargetAdmin,
+		verb:        auditlog.VerbJoin,
+		targetID:    auditLogTargetID(admin.ID),
+		after:       workspaceAdmin,
+	})
 
 	// Only delete the invite for specific-admin invites. Specific-admin invites are 1-time use only.
 	// Non-admin specific invites are multi-use and only have an expiration date.
@@ -478,6 +506,13 @@ func (r *Resolver) isUserInWorkspaceReadOnly(ctx context.Context, workspaceID in
 		return r.GetWorkspace(workspaceID)
 	}
 
+	if err := authorizeAPITokenWorkspace(ctx, workspaceID); err != nil {
+		return nil, err
+	}
+	if isServiceAccount(ctx) {
+		return r.GetWorkspace(workspaceID)
+	}
+
 	admin, err := r.getCurrentAdmin(ctx)
 	if err != nil {
 		return nil, err
@@ -566,6 +601,13 @@ func (r *Resolver) isUserInWorkspace(ctx context.Context, workspaceID int) (*mod
 		return r.GetWorkspace(workspaceID)
 	}
 
+	if err := authorizeAPITokenWorkspace(ctx, workspaceID); err != nil {
+		return nil, err
+	}
+	if isServiceAccount(ctx) {
+		return r.GetWorkspace(workspaceID)
+	}
+
 	admin, err := r.getCurrentAdmin(ctx)
 	if err != nil {
 		return nil, err
@@ -611,6 +653,16 @@ func (r *Resolver) isUserInProject(ctx context.Context, project_id int) (*model.
 		}
 		return project, nil
 	}
+	if isServiceAccount(ctx) {
+		project := &model.Project{}
+		if err := r.DB.WithContext(ctx).Where(&model.Project{Model: model.Model{ID: project_id}}).Take(&project).Error; err != nil {
+			return nil, AuthorizationError
+		}
+		if err := authorizeAPITokenProject(ctx, project); err != nil {
+			return nil, err
+		}
+		return project, nil
+	}
 	projects, err := r.Query().Projects(ctx)
 	if err != nil {
 		return nil, e.Wrap(err, "error querying projects")
@@ -622,6 +674,9 @@ func (r *Resolver) isUserInProject(ctx context.Context, project_id int) (*model.
 	for _, p := range projects {
 		if p.ID == project_id {
 			span.SetAttribute("WorkspaceID", p.WorkspaceID)
+			if err := authorizeAPITokenProject(ctx, p); err != nil {
+				return nil, err
+			}
 			return p, nil
 		}
 	}
@@ -829,6 +884,164 @@ func (r *Resolver) isAdminSavedSegmentOwner(ctx context.Context, segment_id int)
 	return segment, nil
 }
 
+func (r *Resolver) isUserErrorGroupingRuleProject(ctx context.Context, ruleID int) (*model.ErrorGroupingRule, *model.Project, error) {
+	authSpan, ctx := util.StartSpanFromContext(ctx, "isUserErrorGroupingRuleProject", util.ResourceName("resolver.internal.auth"))
+	defer authSpan.Finish()
+	rule := &model.ErrorGroupingRule{}
+	if err := r.DB.WithContext(ctx).Where("id = ?", ruleID).Take(&rule).Error; err != nil {
+		return nil, nil, err
+	}
+	project, err := r.isUserInProject(ctx, rule.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rule, project, nil
+}
+
+func ErrorGroupingRuleFromInput(projectID int, input modelInputs.ErrorGroupingRuleInput) (*model.ErrorGroupingRule, error) {
+	rule := &model.ErrorGroupingRule{
+		ProjectID:           projectID,
+		Name:                input.Name,
+		Query:               input.Query,
+		Action:              input.Action,
+		FingerprintTemplate: input.FingerprintTemplate,
+		FramePattern:        input.FramePattern,
+		Priority:            pointy.IntValue(input.Priority, 0),
+		Disabled:            pointy.BoolValue(input.Disabled, false),
+	}
+	if err := errorgroups.ValidateGroupingRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *Resolver) isUserErrorGroupAssignmentRuleProject(ctx context.Context, ruleID int) (*model.ErrorGroupAssignmentRule, *model.Project, error) {
+	authSpan, ctx := util.StartSpanFromContext(ctx, "isUserErrorGroupAssignmentRuleProject", util.ResourceName("resolver.internal.auth"))
+	defer authSpan.Finish()
+	rule := &model.ErrorGroupAssignmentRule{}
+	if err := r.DB.WithContext(ctx).Where("id = ?", ruleID).Take(&rule).Error; err != nil {
+		return nil, nil, err
+	}
+	project, err := r.isUserInProject(ctx, rule.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rule, project, nil
+}
+
+func (r *Resolver) ErrorGroupAssignmentRuleFromInput(ctx context.Context, projectID int, input modelInputs.ErrorGroupAssignmentRuleInput) (*model.ErrorGroupAssignmentRule, error) {
+	rule := &model.ErrorGroupAssignmentRule{
+		ProjectID:       projectID,
+		Name:            input.Name,
+		Query:           input.Query,
+		AssigneeAdminID: input.AssigneeAdminID,
+		AssigneeTeam:    input.AssigneeTeam,
+		Priority:        pointy.IntValue(input.Priority, 0),
+		Disabled:        pointy.BoolValue(input.Disabled, false),
+	}
+	if err := errorgroups.ValidateAssignmentRule(rule); err != nil {
+		return nil, err
+	}
+	if err := r.Store.ValidateAssignee(ctx, projectID, rule.AssigneeAdminID, rule.AssigneeTeam); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *Resolver) isUserRedactionRuleProject(ctx context.Context, ruleID int) (*model.RedactionRule, *model.Project, error) {
+	authSpan, ctx := util.StartSpanFromContext(ctx, "isUserRedactionRuleProject", util.ResourceName("resolver.internal.auth"))
+	defer authSpan.Finish()
+	rule := &model.RedactionRule{}
+	if err := r.DB.WithContext(ctx).Where("id = ?", ruleID).Take(&rule).Error; err != nil {
+		return nil, nil, err
+	}
+	project, err := r.isUserInProject(ctx, rule.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rule, project, nil
+}
+
+func RedactionRuleFromInput(projectID int, input modelInputs.RedactionRuleInput) (*model.RedactionRule, error) {
+	rule := &model.RedactionRule{
+		ProjectID: projectID,
+		Name:      input.Name,
+		Detector:  input.Detector,
+		Disabled:  pointy.BoolValue(input.Disabled, false),
+	}
+	if input.Detector == modelInputs.RedactionDetectorRegex {
+		rule.Pattern = input.Pattern
+	}
+	if err := redact.ValidateRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// getReleaseStats returns the stats of the release from when it was first seen
+// until it was last seen or the next release was first seen, whichever is earlier.
+func (r *Resolver) getReleaseStats(ctx context.Context, release *model.Release) (stats *modelInputs.ReleaseStats, previous *model.Release, err error) {
+	previous, next, err := r.Store.GetAdjacentReleases(ctx, release)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	startDate, endDate := store.GetReleaseWindow(release)
+	if next != nil && next.FirstSeenAt.After(startDate) && next.FirstSeenAt.Before(endDate) {
+		endDate = next.FirstSeenAt
+	}
+
+	stats, err = r.ClickhouseClient.QueryReleaseStats(ctx, release.ProjectID, release.Version, startDate, endDate)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stats, previous, nil
+}
+
+// PreviewErrorGroupingRules applies the rules to recent errors of the project,
+// comparing the resulting grouping key to the one computed without any rules.
+func (r *Resolver) PreviewErrorGroupingRules(ctx context.Context, projectID int, rules []*model.ErrorGroupingRule, count int) ([]*modelInputs.ErrorGroupingRulePreview, error) {
+	var errorObjects []*model.ErrorObject
+	if err := r.DB.WithContext(ctx).Model(&model.ErrorObject{}).
+		Where("project_id = ?", projectID).
+		Order("id DESC").
+		Limit(count).
+		Find(&errorObjects).Error; err != nil {
+		return nil, e.Wrap(err, "error querying recent error objects")
+	}
+
+	compiledRules := errorgroups.CompileGroupingRules(rules)
+	var previews []*modelInputs.ErrorGroupingRulePreview
+	for _, errorObject := range errorObjects {
+		stackTraceString := pointy.StringValue(errorObject.StackTrace, "")
+		if errorObject.MappedStackTrace != nil && *errorObject.MappedStackTrace != "" && *errorObject.MappedStackTrace != "null" {
+			stackTraceString = *errorObject.MappedStackTrace
+		}
+		structuredStackTrace, _ := r.UnmarshalStackTrace(stackTraceString)
+		before := errorgroups.GetKey(projectID, errorObject, structuredStackTrace)
+
+		grouping := errorgroups.ApplyGroupingRules(compiledRules, errorObject, structuredStackTrace)
+		after := errorgroups.GetKey(projectID, errorObject, grouping.StackTrace)
+		if grouping.Fingerprint != nil {
+			after = fmt.Sprintf("%s-%s", after, *grouping.Fingerprint)
+		}
+
+		previews = append(previews, &modelInputs.ErrorGroupingRulePreview{
+			ErrorObjectID: errorObject.ID,
+			ErrorGroupID:  errorObject.ErrorGroupID,
+			Event:         errorObject.Event,
+			MatchedRules: lo.Map(grouping.MatchedRules, func(rule *model.ErrorGroupingRule, _ int) string {
+				return rule.Name
+			}),
+			Fingerprint:        grouping.Fingerprint,
+			IgnoredFrames:      grouping.IgnoredFrames,
+			NotInAppFrames:     grouping.NotInAppFrames,
+			GroupingKeyChanged: before != after,
+		})
+	}
+	return previews, nil
+}
+
 func (r *Resolver) SendEmailAlert(
 	tos []*mail.Email,
 	ccs []*mail.Email,
@@ -1333,6 +1546,11 @@ func (r *Resolver) validateAdminRole(ctx context.Context, workspaceID int) error
 		return nil
 	}
 
+	// service accounts can only be created by workspace admins
+	if isServiceAccount(ctx) {
+		return authorizeAPITokenWorkspace(ctx, workspaceID)
+	}
+
 	admin, err := r.getCurrentAdmin(ctx)
 	if err != nil {
 		return err
@@ -0,0 +1,358 @@
+package graph
+
+import (
+	"context"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"firebase.google.com/go/auth"
+	"github.com/crewjam/saml"
+	"github.com/crewjam/saml/samlsp"
+	"github.com/go-redis/cache/v9"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/highlight-run/highlight/backend/env"
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/highlight-run/highlight/backend/store"
+	"github.com/highlight-run/highlight/backend/util"
+	e "github.com/pkg/errors"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/samber/lo"
+	"github.com/sendgrid/sendgrid-go/helpers/mail"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// samlClientIDPrefix marks the oauth client id cookie of admins signing in with SAML
+	// so that the frontend and the cloud auth client use the SSO flow for them.
+	samlClientIDPrefix = "saml:"
+	samlDomainClaim    = "saml_domain"
+	samlRequestExpiry  = 10 * time.Minute
+	samlError          = "saml login failed"
+)
+
+type SAMLClient struct {
+	client          *model.SAMLClient
+	serviceProvider *saml.ServiceProvider
+}
+
+// samlRequest is stored under the relay state of an authentication request until the identity provider responds.
+type samlRequest struct {
+	Domain    string
+	RequestID string
+}
+
+func getSAMLRequestKey(relayState string) string {
+	return fmt.Sprintf("saml-request-%s", relayState)
+}
+
+func extractSAMLDomain(r *http.Request) (string, bool) {
+	return strings.CutPrefix(extractClientID(r), samlClientIDPrefix)
+}
+
+// newSAMLServiceProvider configures the service provider shared by all SAML clients.
+// Authentication requests are signed when SAML_CERTIFICATE and SAML_PRIVATE_KEY are set.
+func newSAMLServiceProvider() (*saml.ServiceProvider, error) {
+	baseURL, err := url.Parse(strings.TrimSuffix(env.Config.PrivateGraphUri, "/"))
+	if err != nil {
+		return nil, e.Wrap(err, "invalid private graph uri")
+	}
+	sp := &saml.ServiceProvider{
+		MetadataURL: *baseURL.JoinPath("saml", "metadata"),
+		AcsURL:      *baseURL.JoinPath("saml", "acs"),
+	}
+	if env.Config.SAMLCertificate == "" || env.Config.SAMLPrivateKey == "" {
+		return sp, nil
+	}
+
+	keyPair, err := tls.X509KeyPair([]byte(env.Config.SAMLCertificate), []byte(env.Config.SAMLPrivateKey))
+	if err != nil {
+		return nil, e.Wrap(err, "invalid saml key pair")
+	}
+	key, ok := keyPair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, e.New("saml private key cannot sign")
+	}
+	if sp.Certificate, err = x509.ParseCertificate(keyPair.Certificate[0]); err != nil {
+		return nil, e.Wrap(err, "invalid saml certificate")
+	}
+	sp.Key = key
+	sp.SignatureMethod = dsig.RSASHA256SignatureMethod
+	return sp, nil
+}
+
+func newSAMLClient(ctx context.Context, sp saml.ServiceProvider, samlClient *model.SAMLClient) (*SAMLClient, error) {
+	var idpMetadata *saml.EntityDescriptor
+	var err error
+	if samlClient.IdPMetadata != "" {
+		idpMetadata, err = samlsp.ParseMetadata([]byte(samlClient.IdPMetadata))
+	} else {
+		var metadataURL *url.URL
+		if metadataURL, err = url.Parse(samlClient.IdPMetadataURL); err != nil {
+			return nil, e.Wrap(err, "invalid idp metadata url")
+		}
+		idpMetadata, err = samlsp.FetchMetadata(ctx, http.DefaultClient, *metadataURL)
+	}
+	if err != nil {
+		return nil, e.Wrap(err, "failed to load idp metadata")
+	}
+
+	sp.IDPMetadata = idpMetadata
+	return &SAMLClient{client: samlClient, serviceProvider: &sp}, nil
+}
+
+func getSAMLAttribute(assertion *saml.Assertion, name string) []string {
+	if name == "" {
+		return nil
+	}
+	var values []string
+	for _, statement := range assertion.AttributeStatements {
+		for _, attribute := range statement.Attributes {
+			if attribute.Name != name && attribute.FriendlyName != name {
+				continue
+			}
+			for _, value := range attribute.Values {
+				values = append(values, value.Value)
+			}
+		}
+	}
+	return values
+}
+
+// getSAMLUser maps the assertion attributes to the user and their workspace role.
+// The role is nil when the identity provider does not assert one.
+func getSAMLUser(samlClient *model.SAMLClient, assertion *saml.Assertion) (*auth.UserRecord, *string, error) {
+	email, _ := lo.Nth(getSAMLAttribute(assertion, samlClient.EmailAttribute), 0)
+	if email == "" && assertion.Subject != nil && assertion.Subject.NameID != nil {
+		email = assertion.Subject.NameID.Value
+	}
+	if _, err := mail.ParseEmail(email); err != nil {
+		return nil, nil, e.Wrap(err, "invalid saml email")
+	}
+	// an identity provider may only sign in admins of its own domain
+	if !strings.HasSuffix(strings.ToLower(email), "@"+strings.ToLower(samlClient.Domain)) {
+		return nil, nil, e.Errorf("saml email %s does not match domain %s", email, samlClient.Domain)
+	}
+
+	name, _ := lo.Nth(getSAMLAttribute(assertion, samlClient.NameAttribute), 0)
+	if name == "" {
+		name = email
+	}
+
+	var role *string
+	if roles := getSAMLAttribute(assertion, samlClient.RoleAttribute); len(roles) > 0 {
+		role = lo.ToPtr(model.AdminRole.MEMBER)
+		adminRoles := lo.Map(strings.Split(samlClient.AdminRoleValues, ","), func(value string, _ int) string {
+			return strings.TrimSpace(value)
+		})
+		if lo.Some(roles, lo.Without(adminRoles, "")) {
+			role = lo.ToPtr(model.AdminRole.ADMIN)
+		}
+	}
+
+	return &auth.UserRecord{
+		UserInfo: &auth.UserInfo{
+			UID:         fmt.Sprintf("saml|%s", strings.ToLower(email)),
+			DisplayName: name,
+			Email:       email,
+			ProviderID:  assertion.Issuer.Value,
+		},
+		// saml email always verified by provider
+		EmailVerified: true,
+	}, role, nil
+}
+
+func (c *OAuthAuthClient) handleSAMLMetadata(w http.ResponseWriter, r *http.Request) {
+	buf, err := xml.MarshalIndent(c.samlServiceProvider.Metadata(), "", "  ")
+	if err != nil {
+		log.WithContext(r.Context()).WithError(err).Error("failed to marshal saml metadata")
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/samlmetadata+xml")
+	if _, err := w.Write(buf); err != nil {
+		log.WithContext(r.Context()).WithError(err).Error("error writing saml metadata response")
+	}
+}
+
+func (c *OAuthAuthClient) handleSAMLRedirect(w http.ResponseWriter, r *http.Request, domain string) {
+	span, ctx := util.StartSpanFromContext(r.Context(), "auth.saml.handleSAMLRedirect")
+	span.SetAttribute("domain", domain)
+	defer span.Finish()
+
+	client := c.samlClientsByDomain[domain]
+	if client == nil {
+		log.WithContext(ctx).WithField("domain", domain).Error("no saml client found")
+		http.Error(w, samlError, http.StatusBadRequest)
+		return
+	}
+
+	sp := client.serviceProvider
+	req, err := sp.MakeAuthenticationRequest(sp.GetSSOBindingLocation(saml.HTTPRedirectBinding), saml.HTTPRedirectBinding, saml.HTTPPostBinding)
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Error("failed to make saml authentication request")
+		http.Error(w, samlError, http.StatusBadRequest)
+		return
+	}
+
+	relayState, err := generateNonce(32)
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Error("failed to generate saml relay state")
+		http.Error(w, samlError, http.StatusInternalServerError)
+		return
+	}
+	if err := c.store.Redis.Cache.Set(&cache.Item{
+		Ctx:   ctx,
+		Key:   getSAMLRequestKey(relayState),
+		Value: &samlRequest{Domain: domain, RequestID: req.ID},
+		TTL:   samlRequestExpiry,
+	}); err != nil {
+		log.WithContext(ctx).WithError(err).Error("failed to store saml request")
+		http.Error(w, samlError, http.StatusInternalServerError)
+		return
+	}
+
+	redirectURL, err := req.Redirect(relayState, sp)
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Error("failed to build saml redirect")
+		http.Error(w, samlError, http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, redirectURL.String(), http.StatusFound)
+}
+
+func (c *OAuthAuthClient) handleSAMLAssertion(w http.ResponseWriter, r *http.Request) {
+	span, ctx := util.StartSpanFromContext(r.Context(), "auth.saml.handleSAMLAssertion")
+	defer span.Finish()
+
+	if err := r.ParseForm(); err != nil {
+		log.WithContext(ctx).WithError(err).Error("failed to parse saml response form")
+		http.Error(w, samlError, http.StatusBadRequest)
+		return
+	}
+
+	// the request is single use so that a response cannot be replayed
+	key := getSAMLRequestKey(r.PostForm.Get("RelayState"))
+	var request samlRequest
+	if err := c.store.Redis.Cache.Get(ctx, key, &request); err != nil {
+		log.WithContext(ctx).WithError(err).Error("failed to retrieve saml request")
+		http.Error(w, "saml request not found", http.StatusBadRequest)
+		return
+	}
+	if err := c.store.Redis.Cache.Delete(ctx, key); err != nil {
+		log.WithContext(ctx).WithError(err).Warn("failed to delete saml request")
+	}
+	span.SetAttribute("domain", request.Domain)
+
+	client := c.samlClientsByDomain[request.Domain]
+	if client == nil {
+		log.WithContext(ctx).WithField("domain", request.Domain).Error("no saml client found")
+		http.Error(w, samlError, http.StatusBadRequest)
+		return
+	}
+
+	// validates the assertion signature against the identity provider certificates
+	assertion, err := client.serviceProvider.ParseResponse(r, []string{request.RequestID})
+	if err != nil {
+		var invalidResponseError *saml.InvalidResponseError
+		if e.As(err, &invalidResponseError) {
+			err = invalidResponseError.PrivateErr
+		}
+		log.WithContext(ctx).WithError(err).WithField("domain", request.Domain).Error("invalid saml response")
+		http.Error(w, samlError, http.StatusForbidden)
+		return
+	}
+
+	user, role, err := getSAMLUser(client.client, assertion)
+	if err != nil {
+		log.WithContext(ctx).WithError(err).WithField("domain", request.Domain).Error("invalid saml user")
+		http.Error(w, samlError, http.StatusForbidden)
+		return
+	}
+
+	if _, err := c.store.ProvisionSAMLAdmin(ctx, client.client, user.UID, user.DisplayName, user.Email, role); err != nil {
+		log.WithContext(ctx).WithError(err).Error("failed to provision saml admin")
+		http.Error(w, samlError, http.StatusInternalServerError)
+		return
+	}
+
+	if err := c.store.Redis.Cache.Set(&cache.Item{
+		Ctx:   ctx,
+		Key:   fmt.Sprintf(`user-%s`, user.UID),
+		Value: user,
+		TTL:   loginExpiry,
+	}); err != nil {
+		log.WithContext(ctx).WithError(err).Error("failed to store saml user")
+		http.Error(w, samlError, http.StatusInternalServerError)
+		return
+	}
+
+	atClaims := jwt.MapClaims{}
+	atClaims["exp"] = time.Now().Add(loginExpiry).Unix()
+	atClaims["email"] = user.Email
+	atClaims["uid"] = user.UID
+	atClaims[samlDomainClaim] = request.Domain
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, atClaims).SignedString([]byte(JwtAccessSecret))
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Error("failed to sign saml token")
+		http.Error(w, samlError, http.StatusInternalServerError)
+		return
+	}
+
+	c.setCallbackCookie(w, r, tokenCookieName, token)
+	c.setCallbackCookie(w, r, oauthClientIDCookieName, samlClientIDPrefix+request.Domain)
+	http.Redirect(w, r, fmt.Sprintf("%s/sign_in", env.Config.FrontendUri), http.StatusFound)
+}
+
+func (c *OAuthAuthClient) updateContextWithSAMLUser(ctx context.Context, w http.ResponseWriter, req *http.Request, domain string, token string) (context.Context, error) {
+	claims, err := authenticateToken(ctx, token)
+	if err != nil || claims == nil {
+		log.WithContext(ctx).WithError(err).Info("invalid saml user token")
+		c.handleLogout(w, req)
+		return ctx, e.New("invalid user token")
+	}
+	if claimDomain, _ := claims[samlDomainClaim].(string); claimDomain != domain || c.samlClientsByDomain[domain] == nil {
+		log.WithContext(ctx).WithField("domain", domain).Info("saml user token does not match saml client")
+		c.handleLogout(w, req)
+		return ctx, e.New("invalid user token")
+	}
+
+	uid, _ := claims["uid"].(string)
+	email, _ := claims["email"].(string)
+	ctx = context.WithValue(ctx, model.ContextKeys.UID, uid)
+	ctx = context.WithValue(ctx, model.ContextKeys.Email, email)
+	ctx = context.WithValue(ctx, model.ContextKeys.SSOClientID, samlClientIDPrefix+domain)
+
+	// Update admin last activity
+	if err := UpdateAdminLastActivityDB(ctx, c.store.DB, uid); err != nil {
+		log.WithContext(ctx).WithError(err).Warn("failed to update admin last activity")
+	}
+
+	return ctx, nil
+}
+
+func loadSAMLClients(ctx context.Context, store *store.Store, sp *saml.ServiceProvider) (map[string]*SAMLClient, error) {
+	samlClients, err := store.GetSAMLClients(ctx)
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Error("failed to load saml clients")
+		return nil, err
+	}
+
+	samlClientsByDomain := make(map[string]*SAMLClient)
+	for _, samlClient := range samlClients {
+		log.WithContext(ctx).WithField("domain", samlClient.Domain).Info("setting up saml client")
+		client, err := newSAMLClient(ctx, *sp, samlClient)
+		if err != nil {
+			log.WithContext(ctx).WithError(err).WithField("domain", samlClient.Domain).Error("failed to set up saml client")
+			continue
+		}
+		samlClientsByDomain[samlClient.Domain] = client
+	}
+	return samlClientsByDomain, nil
+}
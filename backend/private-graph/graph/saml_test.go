@@ -0,0 +1,66 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/crewjam/saml"
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeSAMLAssertion(nameID string, attributes map[string][]string) *saml.Assertion {
+	var statement saml.AttributeStatement
+	for name, values := range attributes {
+		attribute := saml.Attribute{Name: name}
+		for _, value := range values {
+			attribute.Values = append(attribute.Values, saml.AttributeValue{Value: value})
+		}
+		statement.Attributes = append(statement.Attributes, attribute)
+	}
+	return &saml.Assertion{
+		Issuer:              saml.Issuer{Value: "https://idp.example.com"},
+		Subject:             &saml.Subject{NameID: &saml.NameID{Value: nameID}},
+		AttributeStatements: []saml.AttributeStatement{statement},
+	}
+}
+
+func TestGetSAMLUser(t *testing.T) {
+	client := &model.SAMLClient{
+		Domain:          "example.com",
+		NameAttribute:   "displayName",
+		EmailAttribute:  "mail",
+		RoleAttribute:   "groups",
+		AdminRoleValues: "highlight-admins, owners",
+	}
+
+	user, role, err := getSAMLUser(client, makeSAMLAssertion("jay", map[string][]string{
+		"displayName": {"Jay Doe"},
+		"mail":        {"Jay@example.com"},
+		"groups":      {"engineering", "owners"},
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, "saml|jay@example.com", user.UID)
+	assert.Equal(t, "Jay Doe", user.DisplayName)
+	assert.Equal(t, "Jay@example.com", user.Email)
+	assert.Equal(t, "https://idp.example.com", user.ProviderID)
+	assert.Equal(t, model.AdminRole.ADMIN, *role)
+
+	// the email falls back to the name id and the name to the email
+	user, role, err = getSAMLUser(client, makeSAMLAssertion("jay@example.com", map[string][]string{
+		"groups": {"engineering"},
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, "jay@example.com", user.Email)
+	assert.Equal(t, "jay@example.com", user.DisplayName)
+	assert.Equal(t, model.AdminRole.MEMBER, *role)
+
+	_, role, err = getSAMLUser(client, makeSAMLAssertion("jay@example.com", nil))
+	assert.NoError(t, err)
+	assert.Nil(t, role)
+
+	_, _, err = getSAMLUser(client, makeSAMLAssertion("jay@other.com", nil))
+	assert.Error(t, err)
+
+	_, _, err = getSAMLUser(client, makeSAMLAssertion("jay", nil))
+	assert.Error(t, err)
+}
@@ -5,6 +5,7 @@ package graph
 // Code generated by github.com/99designs/gqlgen version v0.17.70
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/base64"
@@ -35,15 +36,18 @@ import (
 	alertsV2 "github.com/highlight-run/highlight/backend/alerts/v2"
 	destinationsV2 "github.com/highlight-run/highlight/backend/alerts/v2/destinations"
 	"github.com/highlight-run/highlight/backend/apolloio"
+	"github.com/highlight-run/highlight/backend/auditlog"
 	"github.com/highlight-run/highlight/backend/clickhouse"
 	"github.com/highlight-run/highlight/backend/clickup"
 	Email "github.com/highlight-run/highlight/backend/email"
 	"github.com/highlight-run/highlight/backend/env"
+	"github.com/highlight-run/highlight/backend/har"
 	"github.com/highlight-run/highlight/backend/integrations/cloudflare"
 	"github.com/highlight-run/highlight/backend/integrations/height"
 	kafka_queue "github.com/highlight-run/highlight/backend/kafka-queue"
 	delete_handlers "github.com/highlight-run/highlight/backend/lambda-functions/deleteSessions/handlers"
 	"github.com/highlight-run/highlight/backend/lambda-functions/deleteSessions/utils"
+	journey_handlers "github.com/highlight-run/highlight/backend/lambda-functions/journeys/handlers"
 	utils2 "github.com/highlight-run/highlight/backend/lambda-functions/sessionExport/utils"
 	"github.com/highlight-run/highlight/backend/model"
 	"github.com/highlight-run/highlight/backend/openai_client"
@@ -74,6 +78,20 @@ import (
 	"gorm.io/gorm/clause"
 )
 
+// Scopes is the resolver for the scopes field.
+func (r *aPITokenResolver) Scopes(ctx context.Context, obj *model.APIToken) ([]modelInputs.APITokenScope, error) {
+	return lo.Map(obj.Scopes, func(scope string, _ int) modelInputs.APITokenScope {
+		return modelInputs.APITokenScope(scope)
+	}), nil
+}
+
+// ProjectIds is the resolver for the project_ids field.
+func (r *aPITokenResolver) ProjectIds(ctx context.Context, obj *model.APIToken) ([]int, error) {
+	return lo.Map(obj.ProjectIds, func(id int32, _ int) int {
+		return int(id)
+	}), nil
+}
+
 // EnableBusinessDashboards is the resolver for the enable_business_dashboards field.
 func (r *allWorkspaceSettingsResolver) EnableBusinessDashboards(ctx context.Context, obj *model.AllWorkspaceSettings) (bool, error) {
 	w, err := r.isUserInWorkspaceReadOnly(ctx, obj.WorkspaceID)
@@ -255,6 +273,56 @@ func (r *errorGroupResolver) MetadataLog(ctx context.Context, obj *model.ErrorGr
 	return metadataLogs, nil
 }
 
+// SuspectCommits is the resolver for the suspect_commits field.
+func (r *errorGroupResolver) SuspectCommits(ctx context.Context, obj *model.ErrorGroup) ([]*modelInputs.SuspectCommit, error) {
+	stackTrace, err := r.StructuredStackTrace(ctx, obj)
+	if err != nil || len(stackTrace) == 0 {
+		return []*modelInputs.SuspectCommit{}, err
+	}
+
+	project, err := r.Store.GetProject(ctx, obj.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace, err := r.Store.GetWorkspace(ctx, project.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	suspects, err := r.Store.GetSuspectCommits(ctx, workspace, obj, stackTrace)
+	if err != nil {
+		// suspect commits are best-effort, the error group can be viewed without them
+		log.WithContext(ctx).WithError(err).WithField("error_group_id", obj.ID).Warn("failed to get suspect commits")
+		return []*modelInputs.SuspectCommit{}, nil
+	}
+	return suspects, nil
+}
+
+// Assignee is the resolver for the assignee field.
+func (r *errorGroupResolver) Assignee(ctx context.Context, obj *model.ErrorGroup) (*modelInputs.SanitizedAdmin, error) {
+	if obj.AssigneeAdminID == nil {
+		return nil, nil
+	}
+
+	var admin model.Admin
+	if err := r.DB.WithContext(ctx).Model(&model.Admin{}).Where("id = ?", *obj.AssigneeAdminID).Take(&admin).Error; err != nil {
+		return nil, e.Wrap(err, "error querying assignee")
+	}
+
+	email := ""
+	if admin.Email != nil {
+		email = *admin.Email
+	}
+
+	return &modelInputs.SanitizedAdmin{
+		ID:       admin.ID,
+		Name:     admin.Name,
+		Email:    email,
+		PhotoURL: admin.PhotoURL,
+	}, nil
+}
+
 // ErrorGroupSecureID is the resolver for the error_group_secure_id field.
 func (r *errorObjectResolver) ErrorGroupSecureID(ctx context.Context, obj *model.ErrorObject) (string, error) {
 	if obj != nil {
@@ -517,6 +585,7 @@ func (r *mutationResolver) CreateProject(ctx context.Context, name string, works
 	if err := r.DB.WithContext(ctx).Create(project).Error; err != nil {
 		return nil, e.Wrap(err, "error creating project")
 	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetProject, auditlog.VerbCreate, project.ID, nil, project)
 
 	return project, nil
 }
@@ -594,14 +663,16 @@ func (r *mutationResolver) EditProject(ctx context.Context, id int, name *string
 		BillingEmail: billingEmail,
 	}
 
+	before := *project
 	if err := r.DB.WithContext(ctx).Model(project).Updates(updates).Error; err != nil {
 		return nil, e.Wrap(err, "error updating project fields")
 	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetProject, auditlog.VerbUpdate, project.ID, &before, project)
 	return project, nil
 }
 
 // EditProjectSettings is the resolver for the editProjectSettings field.
-func (r *mutationResolver) EditProjectSettings(ctx context.Context, projectID int, excludedUsers pq.StringArray, errorFilters pq.StringArray, errorJSONPaths pq.StringArray, rageClickWindowSeconds *int, rageClickRadiusPixels *int, rageClickCount *int, filterChromeExtension *bool, filterSessionsWithoutError *bool, autoResolveStaleErrorsDayInterval *int, sampling *modelInputs.SamplingInput) (*modelInputs.AllProjectSettings, error) {
+func (r *mutationResolver) EditProjectSettings(ctx context.Context, projectID int, excludedUsers pq.StringArray, errorFilters pq.StringArray, errorJSONPaths pq.StringArray, rageClickWindowSeconds *int, rageClickRadiusPixels *int, rageClickCount *int, deadClickTimeoutMilliseconds *int, errorClickWindowMilliseconds *int, thrashedCursorWindowMilliseconds *int, thrashedCursorDirectionChanges *int, uTurnWindowSeconds *int, filterChromeExtension *bool, filterSessionsWithoutError *bool, autoResolveStaleErrorsDayInterval *int, sampling *modelInputs.SamplingInput) (*modelInputs.AllProjectSettings, error) {
 	project, err := r.isUserInProject(ctx, projectID)
 	if err != nil {
 		return nil, err
@@ -640,21 +711,52 @@ func (r *mutationResolver) EditProjectSettings(ctx context.Context, projectID in
 		projectUpdates.RageClickCount = *rageClickCount
 	}
 
+	if deadClickTimeoutMilliseconds != nil {
+		projectUpdates.DeadClickTimeoutMilliseconds = *deadClickTimeoutMilliseconds
+	}
+
+	if errorClickWindowMilliseconds != nil {
+		projectUpdates.ErrorClickWindowMilliseconds = *errorClickWindowMilliseconds
+	}
+
+	if thrashedCursorWindowMilliseconds != nil {
+		projectUpdates.ThrashedCursorWindowMilliseconds = *thrashedCursorWindowMilliseconds
+	}
+
+	if thrashedCursorDirectionChanges != nil {
+		projectUpdates.ThrashedCursorDirectionChanges = *thrashedCursorDirectionChanges
+	}
+
+	if uTurnWindowSeconds != nil {
+		projectUpdates.UTurnWindowSeconds = *uTurnWindowSeconds
+	}
+
+	beforeFilterSettings, err := r.Store.GetProjectFilterSettings(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	before := auditProjectSettings{Project: lo.ToPtr(*project), ProjectFilterSettings: beforeFilterSettings}
+
 	if err := r.DB.WithContext(ctx).Model(project).Updates(projectUpdates).Error; err != nil {
 		return nil, e.Wrap(err, "error updating project fields")
 	}
 
 	allProjectSettings := modelInputs.AllProjectSettings{
-		ID:                     project.ID,
-		Name:                   *project.Name,
-		BillingEmail:           project.BillingEmail,
-		ExcludedUsers:          project.ExcludedUsers,
-		ErrorFilters:           project.ErrorFilters,
-		ErrorJSONPaths:         project.ErrorJsonPaths,
-		FilterChromeExtension:  project.FilterChromeExtension,
-		RageClickWindowSeconds: &project.RageClickWindowSeconds,
-		RageClickRadiusPixels:  &project.RageClickRadiusPixels,
-		RageClickCount:         &project.RageClickCount,
+		ID:                               project.ID,
+		Name:                             *project.Name,
+		BillingEmail:                     project.BillingEmail,
+		ExcludedUsers:                    project.ExcludedUsers,
+		ErrorFilters:                     project.ErrorFilters,
+		ErrorJSONPaths:                   project.ErrorJsonPaths,
+		FilterChromeExtension:            project.FilterChromeExtension,
+		RageClickWindowSeconds:           &project.RageClickWindowSeconds,
+		RageClickRadiusPixels:            &project.RageClickRadiusPixels,
+		RageClickCount:                   &project.RageClickCount,
+		DeadClickTimeoutMilliseconds:     &project.DeadClickTimeoutMilliseconds,
+		ErrorClickWindowMilliseconds:     &project.ErrorClickWindowMilliseconds,
+		ThrashedCursorWindowMilliseconds: &project.ThrashedCursorWindowMilliseconds,
+		ThrashedCursorDirectionChanges:   &project.ThrashedCursorDirectionChanges,
+		UTurnWindowSeconds:               &project.UTurnWindowSeconds,
 	}
 
 	projectFilterSettings, err := r.Store.UpdateProjectFilterSettings(ctx, project.ID, store.UpdateProjectFilterSettingsParams{
@@ -665,6 +767,8 @@ func (r *mutationResolver) EditProjectSettings(ctx context.Context, projectID in
 	if err != nil {
 		return nil, err
 	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetProjectSettings, auditlog.VerbUpdate, project.ID, before, auditProjectSettings{Project: project, ProjectFilterSettings: projectFilterSettings})
+
 	allProjectSettings.FilterSessionsWithoutError = projectFilterSettings.FilterSessionsWithoutError
 	allProjectSettings.AutoResolveStaleErrorsDayInterval = projectFilterSettings.AutoResolveStaleErrorsDayInterval
 	allProjectSettings.Sampling = &modelInputs.Sampling{
@@ -712,28 +816,68 @@ func (r *mutationResolver) EditWorkspace(ctx context.Context, id int, name *stri
 		return nil, err
 	}
 
+	before := *workspace
 	if err := r.DB.WithContext(ctx).Model(workspace).Updates(&model.Workspace{
 		Name: name,
 	}).Error; err != nil {
 		return nil, e.Wrap(err, "error updating workspace fields")
 	}
+	r.recordAuditLog(ctx, auditLogEvent{
+		workspaceID: workspace.ID,
+		target:      auditlog.TargetWorkspace,
+		verb:        auditlog.VerbUpdate,
+		targetID:    auditLogTargetID(workspace.ID),
+		before:      &before,
+		after:       workspace,
+	})
 	return workspace, nil
 }
 
 // EditWorkspaceSettings is the resolver for the editWorkspaceSettings field.
-func (r *mutationResolver) EditWorkspaceSettings(ctx context.Context, workspaceID int, aiApplication *bool, aiInsights *bool, aiQueryBuilder *bool) (*model.AllWorkspaceSettings, error) {
+func (r *mutationResolver) EditWorkspaceSettings(ctx context.Context, workspaceID int, aiApplication *bool, aiInsights *bool, aiQueryBuilder *bool, auditLogRetentionDays *int) (*model.AllWorkspaceSettings, error) {
 	_, err := r.isUserWorkspaceAdmin(ctx, workspaceID)
 	if err != nil {
 		return nil, err
 	}
 
-	workspaceSettingsUpdates := map[string]interface{}{
-		"AIApplication":  *aiApplication,
-		"AIInsights":     *aiInsights,
-		"AIQueryBuilder": *aiQueryBuilder,
+	workspaceSettingsUpdates := map[string]interface{}{}
+	if aiApplication != nil {
+		workspaceSettingsUpdates["AIApplication"] = *aiApplication
+	}
+	if aiInsights != nil {
+		workspaceSettingsUpdates["AIInsights"] = *aiInsights
+	}
+	if aiQueryBuilder != nil {
+		workspaceSettingsUpdates["AIQueryBuilder"] = *aiQueryBuilder
+	}
+	if auditLogRetentionDays != nil {
+		if *auditLogRetentionDays < 1 || *auditLogRetentionDays > store.MAX_AUDIT_LOG_RETENTION_DAYS {
+			return nil, e.Errorf("audit log retention must be between 1 and %d days", store.MAX_AUDIT_LOG_RETENTION_DAYS)
+		}
+		workspaceSettingsUpdates["AuditLogRetentionDays"] = *auditLogRetentionDays
 	}
 
-	return r.Store.UpdateAllWorkspaceSettings(ctx, workspaceID, workspaceSettingsUpdates)
+	before, err := r.Store.GetAllWorkspaceSettings(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if len(workspaceSettingsUpdates) == 0 {
+		return before, nil
+	}
+
+	settings, err := r.Store.UpdateAllWorkspaceSettings(ctx, workspaceID, workspaceSettingsUpdates)
+	if err != nil {
+		return nil, err
+	}
+	r.recordAuditLog(ctx, auditLogEvent{
+		workspaceID: workspaceID,
+		target:      auditlog.TargetWorkspaceSettings,
+		verb:        auditlog.VerbUpdate,
+		targetID:    auditLogTargetID(settings.ID),
+		before:      before,
+		after:       settings,
+	})
+	return settings, nil
 }
 
 // ExportSession is the resolver for the exportSession field.
@@ -927,10 +1071,19 @@ func (r *mutationResolver) MarkSessionAsViewed(ctx context.Context, secureID str
 
 // UpdateErrorGroupState is the resolver for the updateErrorGroupState field.
 func (r *mutationResolver) UpdateErrorGroupState(ctx context.Context, secureID string, state modelInputs.ErrorState, snoozedUntil *time.Time) (*model.ErrorGroup, error) {
+	if state == modelInputs.ErrorStateRegressed {
+		return nil, e.New("error groups can only regress when a resolved error reoccurs")
+	}
+	if state == modelInputs.ErrorStateMerged {
+		return nil, e.New("error groups can only be merged with mergeErrorGroups")
+	}
 	errorGroup, err := r.canAdminModifyErrorGroup(ctx, secureID)
 	if err != nil {
 		return nil, err
 	}
+	if errorGroup.State == modelInputs.ErrorStateMerged {
+		return nil, e.New("error group was merged into another error group")
+	}
 	admin, err := r.getCurrentAdmin(ctx)
 
 	return r.Store.UpdateErrorGroupStateByAdmin(ctx, *admin, store.UpdateErrorGroupParams{
@@ -940,6 +1093,78 @@ func (r *mutationResolver) UpdateErrorGroupState(ctx context.Context, secureID s
 	})
 }
 
+// MergeErrorGroups is the resolver for the mergeErrorGroups field.
+func (r *mutationResolver) MergeErrorGroups(ctx context.Context, secureID string, mergeSecureIds []string) (*model.ErrorGroup, error) {
+	errorGroup, err := r.canAdminModifyErrorGroup(ctx, secureID)
+	if err != nil {
+		return nil, err
+	}
+	var sources []*model.ErrorGroup
+	for _, mergeSecureID := range lo.Uniq(mergeSecureIds) {
+		source, err := r.canAdminModifyErrorGroup(ctx, mergeSecureID)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	admin, err := r.getCurrentAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	project, err := r.Store.GetProject(ctx, errorGroup.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := r.Store.MergeErrorGroups(ctx, *admin, errorGroup, sources)
+	if err != nil {
+		return nil, err
+	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetErrorGroup, auditlog.VerbMerge, errorGroup.ID, nil, auditErrorGroupMerge{
+		MergedErrorGroupIDs: lo.Map(sources, func(source *model.ErrorGroup, _ int) int { return source.ID }),
+	})
+	return merged, nil
+}
+
+// SplitErrorGroup is the resolver for the splitErrorGroup field.
+func (r *mutationResolver) SplitErrorGroup(ctx context.Context, secureID string, errorObjectIds []int) (*model.ErrorGroup, error) {
+	errorGroup, err := r.canAdminModifyErrorGroup(ctx, secureID)
+	if err != nil {
+		return nil, err
+	}
+	admin, err := r.getCurrentAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	project, err := r.Store.GetProject(ctx, errorGroup.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	split, err := r.Store.SplitErrorGroup(ctx, *admin, errorGroup, errorObjectIds)
+	if err != nil {
+		return nil, err
+	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetErrorGroup, auditlog.VerbSplit, errorGroup.ID, nil, auditErrorGroupSplit{
+		SplitErrorGroupID: split.ID,
+		ErrorObjectIDs:    errorObjectIds,
+	})
+	return split, nil
+}
+
+// RegisterRelease is the resolver for the registerRelease field.
+func (r *mutationResolver) RegisterRelease(ctx context.Context, apiKey string, version string, commitSha *string, deployedAt *time.Time) (*model.Release, error) {
+	projectID, err := r.Query().APIKeyToOrgID(ctx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	if projectID == nil {
+		return nil, e.New("invalid API key - project id is nil")
+	}
+
+	return r.Store.RegisterRelease(ctx, *projectID, version, commitSha, deployedAt)
+}
+
 // DeleteProject is the resolver for the deleteProject field.
 func (r *mutationResolver) DeleteProject(ctx context.Context, id int) (*bool, error) {
 	project, err := r.isUserInProject(ctx, id)
@@ -952,6 +1177,7 @@ func (r *mutationResolver) DeleteProject(ctx context.Context, id int) (*bool, er
 	if err := r.DB.WithContext(ctx).Model(&model.Project{}).Delete("id = ?", id).Error; err != nil {
 		return nil, e.Wrap(err, "error deleting project")
 	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetProject, auditlog.VerbDelete, project.ID, project, nil)
 	return &model.T, nil
 }
 
@@ -1004,6 +1230,13 @@ func (r *mutationResolver) SendAdminWorkspaceInvite(ctx context.Context, workspa
 	if err := r.DB.WithContext(ctx).Create(inviteLink).Error; err != nil {
 		return nil, e.Wrap(err, "error creating new invite link")
 	}
+	r.recordAuditLog(ctx, auditLogEvent{
+		workspaceID: workspaceID,
+		target:      auditlog.TargetWorkspaceInviteLink,
+		verb:        auditlog.VerbInvite,
+		targetID:    auditLogTargetID(inviteLink.ID),
+		after:       inviteLink,
+	})
 
 	baseURL := env.Config.FrontendUri
 
@@ -1023,10 +1256,20 @@ func (r *mutationResolver) DeleteInviteLinkFromWorkspace(ctx context.Context, wo
 		return false, err
 	}
 
-	result := r.DB.WithContext(ctx).Where("id = ?", workspaceInviteLinkID).Where("workspace_id = ?", workspaceID).Delete(&model.WorkspaceInviteLink{})
+	var inviteLinks []*model.WorkspaceInviteLink
+	result := r.DB.WithContext(ctx).Clauses(clause.Returning{}).Where("id = ?", workspaceInviteLinkID).Where("workspace_id = ?", workspaceID).Delete(&inviteLinks)
 	if result.Error != nil {
 		return false, e.Wrap(err, "error deleting workspace invite link")
 	}
+	for _, inviteLink := range inviteLinks {
+		r.recordAuditLog(ctx, auditLogEvent{
+			workspaceID: workspaceID,
+			target:      auditlog.TargetWorkspaceInviteLink,
+			verb:        auditlog.VerbDelete,
+			targetID:    auditLogTargetID(inviteLink.ID),
+			before:      inviteLink,
+		})
+	}
 
 	return int(result.RowsAffected) > 0, nil
 }
@@ -1047,16 +1290,24 @@ func (r *mutationResolver) JoinWorkspace(ctx context.Context, workspaceID int) (
 		return nil, e.Wrap(err, "error querying workspace")
 	}
 
-	if err := r.DB.WithContext(ctx).Create(&model.WorkspaceAdmin{AdminID: admin.ID, WorkspaceID: workspace.ID, Role: pointy.String("MEMBER")}).Error; err != nil {
+	workspaceAdmin := &model.WorkspaceAdmin{AdminID: admin.ID, WorkspaceID: workspace.ID, Role: pointy.String("MEMBER")}
+	if err := r.DB.WithContext(ctx).Create(workspaceAdmin).Error; err != nil {
 		return nil, e.Wrap(err, "error adding admin to workspace")
 	}
+	r.recordAuditLog(ctx, auditLogEvent{
+		workspaceID: workspace.ID,
+		target:      auditlog.TargetAdmin,
+		verb:        auditlog.VerbJoin,
+		targetID:    auditLogTargetID(admin.ID),
+		after:       workspaceAdmin,
+	})
 
 	return &workspace.ID, nil
 }
 
 // UpdateAllowedEmailOrigins is the resolver for the updateAllowedEmailOrigins field.
 func (r *mutationResolver) UpdateAllowedEmailOrigins(ctx context.Context, workspaceID int, allowedAutoJoinEmailOrigins string) (*int, error) {
-	_, err := r.isUserWorkspaceAdmin(ctx, workspaceID)
+	workspace, err := r.isUserWorkspaceAdmin(ctx, workspaceID)
 	if err != nil {
 		return nil, err
 	}
@@ -1065,10 +1316,19 @@ func (r *mutationResolver) UpdateAllowedEmailOrigins(ctx context.Context, worksp
 		return nil, e.Wrap(err, "allowedAutoJoinEmailOrigins is not valid JSON")
 	}
 
+	before := workspace.AllowedAutoJoinEmailOrigins
 	if err := r.DB.WithContext(ctx).Model(&model.Workspace{Model: model.Model{ID: workspaceID}}).Updates(&model.Workspace{
 		AllowedAutoJoinEmailOrigins: &allowedAutoJoinEmailOrigins}).Error; err != nil {
 		return nil, e.Wrap(err, "error updating workspace")
 	}
+	r.recordAuditLog(ctx, auditLogEvent{
+		workspaceID: workspaceID,
+		target:      auditlog.TargetWorkspace,
+		verb:        auditlog.VerbUpdate,
+		targetID:    auditLogTargetID(workspaceID),
+		before:      map[string]any{"allowed_auto_join_email_origins": before},
+		after:       map[string]any{"allowed_auto_join_email_origins": allowedAutoJoinEmailOrigins},
+	})
 
 	return &workspaceID, nil
 }
@@ -1094,11 +1354,23 @@ func (r *mutationResolver) ChangeAdminRole(ctx context.Context, workspaceID int,
 	}
 
 	wa := model.WorkspaceAdmin{AdminID: adminID, WorkspaceID: workspaceID}
+	var before model.WorkspaceAdmin
+	if err := r.DB.WithContext(ctx).Where(&wa).Take(&before).Error; err != nil {
+		return nil, e.Wrap(err, "error querying workspace_admin")
+	}
 	if err := r.DB.WithContext(ctx).Model(&wa).
 		Clauses(clause.Returning{}).
 		Updates(map[string]interface{}{"Role": newRole, "ProjectIds": nil}).Error; err != nil {
 		return nil, e.Wrap(err, "Error updating workspace_admin role")
 	}
+	r.recordAuditLog(ctx, auditLogEvent{
+		workspaceID: workspaceID,
+		target:      auditlog.TargetAdmin,
+		verb:        auditlog.VerbUpdate,
+		targetID:    auditLogTargetID(adminID),
+		before:      &before,
+		after:       &wa,
+	})
 
 	return &model.WorkspaceAdminRole{
 		WorkspaceId: wa.WorkspaceID,
@@ -1143,10 +1415,22 @@ func (r *mutationResolver) ChangeProjectMembership(ctx context.Context, workspac
 	}
 
 	wa := model.WorkspaceAdmin{AdminID: adminID, WorkspaceID: workspaceID}
+	var before model.WorkspaceAdmin
+	if err := r.DB.WithContext(ctx).Where(&wa).Take(&before).Error; err != nil {
+		return nil, e.Wrap(err, "error querying workspace_admin")
+	}
 	if err := r.DB.WithContext(ctx).Model(&wa).
 		Clauses(clause.Returning{}).Update("ProjectIds", newProjectIds).Error; err != nil {
 		return nil, e.Wrap(err, "error updating workspace_admin role")
 	}
+	r.recordAuditLog(ctx, auditLogEvent{
+		workspaceID: workspaceID,
+		target:      auditlog.TargetAdmin,
+		verb:        auditlog.VerbUpdate,
+		targetID:    auditLogTargetID(adminID),
+		before:      &before,
+		after:       &wa,
+	})
 
 	return &model.WorkspaceAdminRole{
 		WorkspaceId: wa.WorkspaceID,
@@ -1173,13 +1457,263 @@ func (r *mutationResolver) DeleteAdminFromWorkspace(ctx context.Context, workspa
 		return nil, e.New("Admin tried deleting their own association")
 	}
 
+	var before model.WorkspaceAdmin
+	if err := r.DB.WithContext(ctx).Where(&model.WorkspaceAdmin{AdminID: adminID, WorkspaceID: workspaceID}).Take(&before).Error; err != nil {
+		return nil, e.Wrap(err, "error querying workspace_admin")
+	}
 	if err := r.DB.WithContext(ctx).Model(workspace).Association("Admins").Delete(model.Admin{Model: model.Model{ID: adminID}}); err != nil {
 		return nil, e.Wrap(err, "error deleting admin association")
 	}
+	r.recordAuditLog(ctx, auditLogEvent{
+		workspaceID: workspaceID,
+		target:      auditlog.TargetAdmin,
+		verb:        auditlog.VerbRemove,
+		targetID:    auditLogTargetID(adminID),
+		before:      &before,
+	})
 
 	return &adminID, nil
 }
 
+// CreateSCIMToken is the resolver for the createSCIMToken field.
+func (r *mutationResolver) CreateSCIMToken(ctx context.Context, workspaceID int, name string) (string, error) {
+	if _, err := r.isUserWorkspaceAdmin(ctx, workspaceID); err != nil {
+		return "", err
+	}
+	token, err := GenerateRandomString(40)
+	if err != nil {
+		return "", e.Wrap(err, "error generating scim token")
+	}
+	scimToken, err := r.Store.CreateSCIMToken(ctx, workspaceID, name, token)
+	if err != nil {
+		return "", e.Wrap(err, "error creating scim token")
+	}
+	r.recordAuditLog(ctx, auditLogEvent{
+		workspaceID: workspaceID,
+		target:      auditlog.TargetSCIMToken,
+		verb:        auditlog.VerbCreate,
+		targetID:    auditLogTargetID(scimToken.ID),
+		after:       scimToken,
+	})
+	// the token is only stored hashed, so it can only be shown once
+	return token, nil
+}
+
+// DeleteSCIMToken is the resolver for the deleteSCIMToken field.
+func (r *mutationResolver) DeleteSCIMToken(ctx context.Context, workspaceID int, id int) (bool, error) {
+	if _, err := r.isUserWorkspaceAdmin(ctx, workspaceID); err != nil {
+		return false, err
+	}
+	if err := r.Store.DeleteSCIMToken(ctx, workspaceID, id); err != nil {
+		return false, e.Wrap(err, "error deleting scim token")
+	}
+	r.recordAuditLog(ctx, auditLogEvent{
+		workspaceID: workspaceID,
+		target:      auditlog.TargetSCIMToken,
+		verb:        auditlog.VerbDelete,
+		targetID:    auditLogTargetID(id),
+	})
+	return true, nil
+}
+
+// UpdateSCIMGroupRole is the resolver for the updateSCIMGroupRole field.
+func (r *mutationResolver) UpdateSCIMGroupRole(ctx context.Context, workspaceID int, id int, role string, projectIds []int) (*model.SCIMGroup, error) {
+	if _, err := r.isUserWorkspaceAdmin(ctx, workspaceID); err != nil {
+		return nil, err
+	}
+	if len(projectIds) > 0 {
+		settings, err := r.Store.GetAllWorkspaceSettings(ctx, workspaceID)
+		if err != nil {
+			return nil, err
+		}
+		if !settings.EnableProjectLevelAccess {
+			return nil, e.New("Workspace does not have the project-level access feature.")
+		}
+	}
+	group, err := r.Store.GetSCIMGroup(ctx, workspaceID, id)
+	if err != nil {
+		return nil, e.Wrap(err, "error querying scim group")
+	}
+	before := map[string]any{"role": group.Role, "project_ids": group.ProjectIds}
+	if err := r.Store.UpdateSCIMGroupRole(ctx, group, role, projectIds); err != nil {
+		return nil, e.Wrap(err, "error updating scim group role")
+	}
+	r.recordAuditLog(ctx, auditLogEvent{
+		workspaceID: workspaceID,
+		target:      auditlog.TargetSCIMGroup,
+		verb:        auditlog.VerbUpdate,
+		targetID:    auditLogTargetID(group.ID),
+		before:      before,
+		after:       map[string]any{"role": group.Role, "project_ids": group.ProjectIds},
+	})
+	return group, nil
+}
+
+// CreateAPIToken is the resolver for the createAPIToken field.
+func (r *mutationResolver) CreateAPIToken(ctx context.Context, workspaceID int, name string, typeArg modelInputs.APITokenType, scopes []modelInputs.APITokenScope, projectIds []int, expiresAt *time.Time, token *string) (string, error) {
+	if typeArg == modelInputs.APITokenTypeServiceAccount {
+		if _, err := r.isUserWorkspaceAdmin(ctx, workspaceID); err != nil {
+			return "", err
+		}
+	} else if _, err := r.isUserInWorkspaceReadOnly(ctx, workspaceID); err != nil {
+		return "", err
+	}
+	admin, err := r.getCurrentAdmin(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if len(scopes) == 0 {
+		return "", e.New("api tokens need at least one scope")
+	}
+	if expiresAt != nil && expiresAt.Before(time.Now()) {
+		return "", e.New("api token expiry must be in the future")
+	}
+	// logs sent with an ingest token go to a single project
+	ingest := lo.Contains(scopes, modelInputs.APITokenScopeLogsIngest)
+	if ingest && len(projectIds) != 1 {
+		return "", e.New("ingest api tokens need exactly one project")
+	}
+	// log forwarders keep their existing credential, such as a datadog api key or a splunk HEC token
+	if token != nil {
+		if !ingest || len(lo.Uniq(scopes)) != 1 {
+			return "", e.New("only api tokens with just the LogsIngest scope can use an existing token")
+		}
+		if len(*token) < MIN_EXISTING_API_TOKEN_LENGTH {
+			return "", e.New(fmt.Sprintf("existing api tokens need at least %d characters", MIN_EXISTING_API_TOKEN_LENGTH))
+		}
+	}
+	for _, projectID := range projectIds {
+		project, err := r.isUserInProject(ctx, projectID)
+		if err != nil {
+			return "", err
+		}
+		if project.WorkspaceID != workspaceID {
+			return "", AuthorizationError
+		}
+	}
+
+	var secretToken string
+	if token != nil {
+		secretToken = *token
+	} else {
+		secret, err := GenerateRandomString(40)
+		if err != nil {
+			return "", e.Wrap(err, "error generating api token")
+		}
+		secretToken = store.API_TOKEN_PREFIX + secret
+	}
+	apiToken := &model.APIToken{
+		WorkspaceID: workspaceID,
+		AdminID:     admin.ID,
+		Name:        name,
+		Type:        typeArg,
+		Scopes: lo.Map(lo.Uniq(scopes), func(scope modelInputs.APITokenScope, _ int) string {
+			return string(scope)
+		}),
+		ProjectIds: lo.Map(projectIds, func(id int, _ int) int32 {
+			return int32(id)
+		}),
+		ExpiresAt: expiresAt,
+	}
+	if err := r.Store.CreateAPIToken(ctx, apiToken, secretToken); err != nil {
+		return "", e.Wrap(err, "error creating api token")
+	}
+	r.recordAuditLog(ctx, auditLogEvent{
+		workspaceID: workspaceID,
+		target:      auditlog.TargetAPIToken,
+		verb:        auditlog.VerbCreate,
+		targetID:    auditLogTargetID(apiToken.ID),
+		after:       apiToken,
+	})
+	// the token is only stored hashed, so it can only be shown once
+	return secretToken, nil
+}
+
+// DeleteAPIToken is the resolver for the deleteAPIToken field.
+func (r *mutationResolver) DeleteAPIToken(ctx context.Context, workspaceID int, id int) (bool, error) {
+	if _, err := r.isUserInWorkspaceReadOnly(ctx, workspaceID); err != nil {
+		return false, err
+	}
+	admin, err := r.getCurrentAdmin(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	apiToken, err := r.Store.GetAPITokenByID(ctx, workspaceID, id)
+	if err != nil {
+		return false, e.Wrap(err, "error querying api token")
+	}
+	// admins can revoke any of the workspace's tokens, members only their own personal tokens
+	isOwnToken := apiToken.Type == modelInputs.APITokenTypePersonal && apiToken.AdminID == admin.ID
+	if !isOwnToken {
+		if err := r.validateAdminRole(ctx, workspaceID); err != nil {
+			return false, err
+		}
+	}
+
+	if err := r.Store.DeleteAPIToken(ctx, workspaceID, id); err != nil {
+		return false, e.Wrap(err, "error deleting api token")
+	}
+	r.recordAuditLog(ctx, auditLogEvent{
+		workspaceID: workspaceID,
+		target:      auditlog.TargetAPIToken,
+		verb:        auditlog.VerbDelete,
+		targetID:    auditLogTargetID(id),
+		before:      apiToken,
+	})
+	return true, nil
+}
+
+// CreateRedactionRule is the resolver for the createRedactionRule field.
+func (r *mutationResolver) CreateRedactionRule(ctx context.Context, projectID int, rule modelInputs.RedactionRuleInput) (*model.RedactionRule, error) {
+	project, err := r.isUserInProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	redactionRule, err := RedactionRuleFromInput(projectID, rule)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Store.CreateRedactionRule(ctx, redactionRule); err != nil {
+		return nil, e.Wrap(err, "error creating redaction rule")
+	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetRedactionRule, auditlog.VerbCreate, redactionRule.ID, nil, redactionRule)
+	return redactionRule, nil
+}
+
+// UpdateRedactionRule is the resolver for the updateRedactionRule field.
+func (r *mutationResolver) UpdateRedactionRule(ctx context.Context, id int, rule modelInputs.RedactionRuleInput) (*model.RedactionRule, error) {
+	existing, project, err := r.isUserRedactionRuleProject(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	redactionRule, err := RedactionRuleFromInput(existing.ProjectID, rule)
+	if err != nil {
+		return nil, err
+	}
+	redactionRule.Model = existing.Model
+	redactionRule.RedactionCount = existing.RedactionCount
+	if err := r.Store.UpdateRedactionRule(ctx, redactionRule); err != nil {
+		return nil, e.Wrap(err, "error updating redaction rule")
+	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetRedactionRule, auditlog.VerbUpdate, redactionRule.ID, existing, redactionRule)
+	return redactionRule, nil
+}
+
+// DeleteRedactionRule is the resolver for the deleteRedactionRule field.
+func (r *mutationResolver) DeleteRedactionRule(ctx context.Context, id int) (bool, error) {
+	rule, project, err := r.isUserRedactionRuleProject(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if err := r.Store.DeleteRedactionRule(ctx, rule); err != nil {
+		return false, e.Wrap(err, "error deleting redaction rule")
+	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetRedactionRule, auditlog.VerbDelete, rule.ID, rule, nil)
+	return true, nil
+}
+
 // EmailSignup is the resolver for the emailSignup field.
 func (r *mutationResolver) EmailSignup(ctx context.Context, email string) (string, error) {
 	short, long, err := apolloio.Enrich(email)
@@ -1270,19 +1804,169 @@ func (r *mutationResolver) EditSavedSegment(ctx context.Context, id int, project
 	}).Error; err != nil {
 		return nil, err
 	}
-	return &model.T, nil
+	return &model.T, nil
+}
+
+// DeleteSavedSegment is the resolver for the deleteSavedSegment field.
+func (r *mutationResolver) DeleteSavedSegment(ctx context.Context, segmentID int) (*bool, error) {
+	_, err := r.isAdminSavedSegmentOwner(ctx, segmentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.DB.Where("id = ?", segmentID).Delete(&model.SavedSegment{}).Error; err != nil {
+		return nil, err
+	}
+	return &model.T, nil
+}
+
+// CreateErrorGroupingRule is the resolver for the createErrorGroupingRule field.
+func (r *mutationResolver) CreateErrorGroupingRule(ctx context.Context, projectID int, rule modelInputs.ErrorGroupingRuleInput) (*model.ErrorGroupingRule, error) {
+	project, err := r.isUserInProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	errorGroupingRule, err := ErrorGroupingRuleFromInput(projectID, rule)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Store.CreateErrorGroupingRule(ctx, errorGroupingRule); err != nil {
+		return nil, e.Wrap(err, "error creating error grouping rule")
+	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetErrorGroupingRule, auditlog.VerbCreate, errorGroupingRule.ID, nil, errorGroupingRule)
+	return errorGroupingRule, nil
+}
+
+// UpdateErrorGroupingRule is the resolver for the updateErrorGroupingRule field.
+func (r *mutationResolver) UpdateErrorGroupingRule(ctx context.Context, id int, rule modelInputs.ErrorGroupingRuleInput) (*model.ErrorGroupingRule, error) {
+	existing, project, err := r.isUserErrorGroupingRuleProject(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	errorGroupingRule, err := ErrorGroupingRuleFromInput(existing.ProjectID, rule)
+	if err != nil {
+		return nil, err
+	}
+	errorGroupingRule.Model = existing.Model
+	if err := r.Store.UpdateErrorGroupingRule(ctx, errorGroupingRule); err != nil {
+		return nil, e.Wrap(err, "error updating error grouping rule")
+	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetErrorGroupingRule, auditlog.VerbUpdate, errorGroupingRule.ID, existing, errorGroupingRule)
+	return errorGroupingRule, nil
+}
+
+// DeleteErrorGroupingRule is the resolver for the deleteErrorGroupingRule field.
+func (r *mutationResolver) DeleteErrorGroupingRule(ctx context.Context, id int) (bool, error) {
+	rule, project, err := r.isUserErrorGroupingRuleProject(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if err := r.Store.DeleteErrorGroupingRule(ctx, rule); err != nil {
+		return false, e.Wrap(err, "error deleting error grouping rule")
+	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetErrorGroupingRule, auditlog.VerbDelete, rule.ID, rule, nil)
+	return true, nil
+}
+
+// AssignErrorGroup is the resolver for the assignErrorGroup field.
+func (r *mutationResolver) AssignErrorGroup(ctx context.Context, secureID string, assigneeAdminID *int, assigneeTeam *string) (*model.ErrorGroup, error) {
+	errorGroup, err := r.canAdminModifyErrorGroup(ctx, secureID)
+	if err != nil {
+		return nil, err
+	}
+	admin, err := r.getCurrentAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Store.ValidateAssignee(ctx, errorGroup.ProjectID, assigneeAdminID, assigneeTeam); err != nil {
+		return nil, err
+	}
+
+	assigned, err := r.Store.AssignErrorGroup(ctx, admin, errorGroup, assigneeAdminID, assigneeTeam, nil)
+	if err != nil {
+		return nil, e.Wrap(err, "error assigning error group")
+	}
+
+	// admins assigning an error group to themselves are not notified
+	if assigned && (assigneeAdminID == nil || *assigneeAdminID != admin.ID) {
+		if err := alertsV2.SendErrorGroupAssignmentNotifications(ctx, r.DB, r.MailClient, r.LambdaClient, destinationsV2.NotificationTypeErrorGroupAssigned, errorGroup, admin, nil); err != nil {
+			log.WithContext(ctx).WithError(err).WithField("error_group_id", errorGroup.ID).Error("failed to send error group assignment notifications")
+		}
+	}
+	return errorGroup, nil
+}
+
+// CreateErrorGroupAssignmentRule is the resolver for the createErrorGroupAssignmentRule field.
+func (r *mutationResolver) CreateErrorGroupAssignmentRule(ctx context.Context, projectID int, rule modelInputs.ErrorGroupAssignmentRuleInput) (*model.ErrorGroupAssignmentRule, error) {
+	project, err := r.isUserInProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	assignmentRule, err := r.ErrorGroupAssignmentRuleFromInput(ctx, projectID, rule)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Store.CreateErrorGroupAssignmentRule(ctx, assignmentRule); err != nil {
+		return nil, e.Wrap(err, "error creating error group assignment rule")
+	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetErrorAssignmentRule, auditlog.VerbCreate, assignmentRule.ID, nil, assignmentRule)
+	return assignmentRule, nil
+}
+
+// UpdateErrorGroupAssignmentRule is the resolver for the updateErrorGroupAssignmentRule field.
+func (r *mutationResolver) UpdateErrorGroupAssignmentRule(ctx context.Context, id int, rule modelInputs.ErrorGroupAssignmentRuleInput) (*model.ErrorGroupAssignmentRule, error) {
+	existing, project, err := r.isUserErrorGroupAssignmentRuleProject(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	assignmentRule, err := r.ErrorGroupAssignmentRuleFromInput(ctx, existing.ProjectID, rule)
+	if err != nil {
+		return nil, err
+	}
+	assignmentRule.Model = existing.Model
+	if err := r.Store.UpdateErrorGroupAssignmentRule(ctx, assignmentRule); err != nil {
+		return nil, e.Wrap(err, "error updating error group assignment rule")
+	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetErrorAssignmentRule, auditlog.VerbUpdate, assignmentRule.ID, existing, assignmentRule)
+	return assignmentRule, nil
 }
 
-// DeleteSavedSegment is the resolver for the deleteSavedSegment field.
-func (r *mutationResolver) DeleteSavedSegment(ctx context.Context, segmentID int) (*bool, error) {
-	_, err := r.isAdminSavedSegmentOwner(ctx, segmentID)
+// DeleteErrorGroupAssignmentRule is the resolver for the deleteErrorGroupAssignmentRule field.
+func (r *mutationResolver) DeleteErrorGroupAssignmentRule(ctx context.Context, id int) (bool, error) {
+	rule, project, err := r.isUserErrorGroupAssignmentRuleProject(ctx, id)
 	if err != nil {
+		return false, err
+	}
+	if err := r.Store.DeleteErrorGroupAssignmentRule(ctx, rule); err != nil {
+		return false, e.Wrap(err, "error deleting error group assignment rule")
+	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetErrorAssignmentRule, auditlog.VerbDelete, rule.ID, rule, nil)
+	return true, nil
+}
+
+// UpdateAssigneeDestinations is the resolver for the updateAssigneeDestinations field.
+func (r *mutationResolver) UpdateAssigneeDestinations(ctx context.Context, projectID int, assigneeAdminID *int, assigneeTeam *string, destinations []*modelInputs.AlertDestinationInput) ([]*model.AssigneeDestination, error) {
+	if _, err := r.isUserInProject(ctx, projectID); err != nil {
 		return nil, err
 	}
-	if err := r.DB.Where("id = ?", segmentID).Delete(&model.SavedSegment{}).Error; err != nil {
+	if assigneeAdminID == nil && assigneeTeam == nil {
+		return nil, e.New("either an assignee admin or an assignee team is required")
+	}
+	if err := r.Store.ValidateAssignee(ctx, projectID, assigneeAdminID, assigneeTeam); err != nil {
 		return nil, err
 	}
-	return &model.T, nil
+
+	assigneeDestinations := lo.Map(destinations, func(destination *modelInputs.AlertDestinationInput, _ int) *model.AssigneeDestination {
+		return &model.AssigneeDestination{
+			DestinationType: destination.DestinationType,
+			TypeID:          destination.TypeID,
+			TypeName:        destination.TypeName,
+		}
+	})
+	assigneeDestinations, err := r.Store.ReplaceAssigneeDestinations(ctx, projectID, assigneeAdminID, assigneeTeam, assigneeDestinations)
+	if err != nil {
+		return nil, e.Wrap(err, "error updating assignee destinations")
+	}
+	return assigneeDestinations, nil
 }
 
 // CreateOrUpdateStripeSubscription is the resolver for the createOrUpdateStripeSubscription field.
@@ -3132,6 +3816,7 @@ func (r *mutationResolver) CreateMetricMonitor(ctx context.Context, projectID in
 	if err := r.DB.WithContext(ctx).Create(newMetricMonitor).Error; err != nil {
 		return nil, e.Wrap(err, "error creating a new error alert")
 	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetMetricMonitor, auditlog.VerbCreate, newMetricMonitor.ID, nil, newMetricMonitor)
 	if err := model.SendWelcomeSlackMessage(ctx, newMetricMonitor, &model.SendWelcomeSlackMessageInput{
 		Workspace:            workspace,
 		Admin:                admin,
@@ -3161,6 +3846,7 @@ func (r *mutationResolver) UpdateMetricMonitor(ctx context.Context, metricMonito
 	if err := r.DB.WithContext(ctx).Where(&model.MetricMonitor{Model: model.Model{ID: metricMonitorID}, ProjectID: projectID}).Find(&metricMonitor).Error; err != nil {
 		return nil, e.Wrap(err, "error querying metric monitor")
 	}
+	before := *metricMonitor
 
 	var createdFilterIDs []int
 	for _, f := range filters {
@@ -3228,6 +3914,7 @@ func (r *mutationResolver) UpdateMetricMonitor(ctx context.Context, metricMonito
 	if err := r.DB.Save(&metricMonitor).Error; err != nil {
 		return nil, e.Wrap(err, "error updating metric monitor")
 	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetMetricMonitor, auditlog.VerbUpdate, metricMonitor.ID, &before, metricMonitor)
 
 	if err := model.SendWelcomeSlackMessage(ctx, metricMonitor, &model.SendWelcomeSlackMessageInput{
 		Workspace:            workspace,
@@ -3267,6 +3954,10 @@ func (r *mutationResolver) CreateAlert(ctx context.Context, projectID int, name
 		thresholdConditionDeref = *thresholdCondition
 	}
 
+	if thresholdTypeDeref == modelInputs.ThresholdTypeRegression && productType != modelInputs.ProductTypeErrors {
+		return nil, e.New("regression alerts are only supported for errors")
+	}
+
 	newAlert := &model.Alert{
 		ProjectID:          projectID,
 		MetricId:           uuid.New().String(),
@@ -3304,6 +3995,7 @@ func (r *mutationResolver) CreateAlert(ctx context.Context, projectID int, name
 	if err := r.DB.WithContext(ctx).Create(alertDestinations).Error; err != nil {
 		return nil, err
 	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetAlert, auditlog.VerbCreate, createdAlert.ID, nil, createdAlert)
 
 	if len(alertDestinations) > 0 {
 		notificationInput := destinationsV2.NotificationInput{
@@ -3346,11 +4038,26 @@ func (r *mutationResolver) UpdateAlert(ctx context.Context, projectID int, alert
 		"Sql":                sql,
 	}
 
+	var before model.Alert
+	if err := r.DB.WithContext(ctx).Where(&model.Alert{Model: model.Model{ID: alertID}, ProjectID: project.ID}).Take(&before).Error; err != nil {
+		return nil, err
+	}
+
+	if thresholdType != nil && *thresholdType == modelInputs.ThresholdTypeRegression {
+		if productType == nil {
+			productType = &before.ProductType
+		}
+		if *productType != modelInputs.ProductTypeErrors {
+			return nil, e.New("regression alerts are only supported for errors")
+		}
+	}
+
 	alert := &model.Alert{}
 	updateErr := store.AssertRecordFound(r.DB.WithContext(ctx).Where(&model.Alert{Model: model.Model{ID: alertID}, ProjectID: project.ID}).Model(&alert).Clauses(clause.Returning{}).Updates(&alertUpdates))
 	if updateErr != nil {
 		return nil, updateErr
 	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetAlert, auditlog.VerbUpdate, alert.ID, &before, alert)
 	alertDestinations := []*model.AlertDestination{}
 	if err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Where(&model.AlertDestination{AlertID: alert.ID}).Delete(&model.AlertDestination{}).Error; err != nil {
@@ -3403,6 +4110,7 @@ func (r *mutationResolver) UpdateAlertDisabled(ctx context.Context, projectID in
 	).Updates(map[string]interface{}{"Disabled": disabled}).Error; err != nil {
 		return false, err
 	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetAlert, lo.Ternary(disabled, auditlog.VerbDisable, auditlog.VerbEnable), alertID, nil, nil)
 
 	return true, err
 }
@@ -3414,11 +4122,15 @@ func (r *mutationResolver) DeleteAlert(ctx context.Context, projectID int, alert
 		return false, err
 	}
 
-	if err := r.DB.Where(
+	var deletedAlerts []*model.Alert
+	if err := r.DB.Clauses(clause.Returning{}).Where(
 		&model.Alert{Model: model.Model{ID: alertID}, ProjectID: project.ID},
-	).Delete(&model.Alert{}).Error; err != nil {
+	).Delete(&deletedAlerts).Error; err != nil {
 		return false, err
 	}
+	for _, alert := range deletedAlerts {
+		r.recordProjectAuditLog(ctx, project, auditlog.TargetAlert, auditlog.VerbDelete, alert.ID, alert, nil)
+	}
 
 	// TODO(spenny): send deletion message to destinations?
 
@@ -3444,6 +4156,7 @@ func (r *mutationResolver) UpdateErrorAlert(ctx context.Context, projectID int,
 	if err := r.DB.WithContext(ctx).Where(&model.ErrorAlert{Model: model.Model{ID: errorAlertID}}).Find(&projectAlert).Error; err != nil {
 		return nil, e.Wrap(err, "error querying error alert")
 	}
+	before := *projectAlert
 
 	if slackChannels != nil {
 		channelsString, err := r.MarshalSlackChannelsToSanitizedSlackChannels(slackChannels)
@@ -3506,6 +4219,7 @@ func (r *mutationResolver) UpdateErrorAlert(ctx context.Context, projectID int,
 	}).Select("*").Where("project_id = ?", projectID).Updates(projectAlert).Error; err != nil {
 		return nil, e.Wrap(err, "error updating org fields")
 	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetErrorAlert, auditlog.VerbUpdate, errorAlertID, &before, projectAlert)
 
 	if err := model.SendWelcomeSlackMessage(ctx, projectAlert, &model.SendWelcomeSlackMessageInput{
 		Workspace:            workspace,
@@ -3539,6 +4253,7 @@ func (r *mutationResolver) DeleteErrorAlert(ctx context.Context, projectID int,
 	if err := r.DB.Delete(projectAlert).Error; err != nil {
 		return nil, e.Wrap(err, "error trying to delete error alert")
 	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetErrorAlert, auditlog.VerbDelete, errorAlertID, projectAlert, nil)
 
 	if err := model.SendWelcomeSlackMessage(ctx, projectAlert, &model.SendWelcomeSlackMessageInput{
 		Workspace:            workspace,
@@ -3573,6 +4288,7 @@ func (r *mutationResolver) DeleteMetricMonitor(ctx context.Context, projectID in
 	if err := r.DB.Delete(metricMonitor).Error; err != nil {
 		return nil, e.Wrap(err, "error trying to delete metric monitor")
 	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetMetricMonitor, auditlog.VerbDelete, metricMonitorID, metricMonitor, nil)
 
 	if err := model.SendWelcomeSlackMessage(ctx, metricMonitor, &model.SendWelcomeSlackMessageInput{
 		Workspace:            workspace,
@@ -3592,7 +4308,7 @@ func (r *mutationResolver) DeleteMetricMonitor(ctx context.Context, projectID in
 
 // UpdateSessionAlertIsDisabled is the resolver for the updateSessionAlertIsDisabled field.
 func (r *mutationResolver) UpdateSessionAlertIsDisabled(ctx context.Context, id int, projectID int, disabled bool) (*model.SessionAlert, error) {
-	_, err := r.isUserInProject(ctx, projectID)
+	project, err := r.isUserInProject(ctx, projectID)
 	if err != nil {
 		return nil, err
 	}
@@ -3610,13 +4326,14 @@ func (r *mutationResolver) UpdateSessionAlertIsDisabled(ctx context.Context, id
 	}).Where("project_id = ?", projectID).Updates(sessionAlert).Error; err != nil {
 		return nil, e.Wrap(err, "error updating org fields for new session alert")
 	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetSessionAlert, lo.Ternary(disabled, auditlog.VerbDisable, auditlog.VerbEnable), id, nil, nil)
 
 	return sessionAlert, err
 }
 
 // UpdateErrorAlertIsDisabled is the resolver for the updateErrorAlertIsDisabled field.
 func (r *mutationResolver) UpdateErrorAlertIsDisabled(ctx context.Context, id int, projectID int, disabled bool) (*model.ErrorAlert, error) {
-	_, err := r.isUserInProject(ctx, projectID)
+	project, err := r.isUserInProject(ctx, projectID)
 	if err != nil {
 		return nil, err
 	}
@@ -3634,13 +4351,14 @@ func (r *mutationResolver) UpdateErrorAlertIsDisabled(ctx context.Context, id in
 	}).Where("project_id = ?", projectID).Updates(errorAlert).Error; err != nil {
 		return nil, e.Wrap(err, "error updating disabled field for error alert")
 	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetErrorAlert, lo.Ternary(disabled, auditlog.VerbDisable, auditlog.VerbEnable), id, nil, nil)
 
 	return errorAlert, err
 }
 
 // UpdateMetricMonitorIsDisabled is the resolver for the updateMetricMonitorIsDisabled field.
 func (r *mutationResolver) UpdateMetricMonitorIsDisabled(ctx context.Context, id int, projectID int, disabled bool) (*model.MetricMonitor, error) {
-	_, err := r.isUserInProject(ctx, projectID)
+	project, err := r.isUserInProject(ctx, projectID)
 	if err != nil {
 		return nil, err
 	}
@@ -3656,6 +4374,7 @@ func (r *mutationResolver) UpdateMetricMonitorIsDisabled(ctx context.Context, id
 	}).Where("project_id = ?", projectID).Updates(metricMonitor).Error; err != nil {
 		return nil, e.Wrap(err, "error updating disabled field for metric monitor")
 	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetMetricMonitor, lo.Ternary(disabled, auditlog.VerbDisable, auditlog.VerbEnable), id, nil, nil)
 
 	return metricMonitor, err
 }
@@ -3675,6 +4394,11 @@ func (r *mutationResolver) UpdateSessionAlert(ctx context.Context, id int, input
 		return nil, e.Wrap(err, "failed to build session feedback alert")
 	}
 
+	var before model.SessionAlert
+	if err := r.DB.WithContext(ctx).Where("project_id = ?", input.ProjectID).Where("id = ?", id).Take(&before).Error; err != nil {
+		return nil, e.Wrap(err, "this session alert does not exist in this project.")
+	}
+
 	if err := r.DB.WithContext(ctx).Model(&model.SessionAlert{
 		Model: model.Model{
 			ID: id,
@@ -3682,6 +4406,7 @@ func (r *mutationResolver) UpdateSessionAlert(ctx context.Context, id int, input
 	}).Where("project_id = ?", input.ProjectID).Updates(sessionAlert).Error; err != nil {
 		return nil, e.Wrap(err, "error updating session alert")
 	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetSessionAlert, auditlog.VerbUpdate, id, &before, sessionAlert)
 
 	if err := model.SendWelcomeSlackMessage(ctx, sessionAlert, &model.SendWelcomeSlackMessageInput{
 		Workspace:            workspace,
@@ -3715,6 +4440,7 @@ func (r *mutationResolver) DeleteSessionAlert(ctx context.Context, projectID int
 	if err := r.DB.Delete(projectAlert).Error; err != nil {
 		return nil, e.Wrap(err, "error trying to delete session alert")
 	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetSessionAlert, auditlog.VerbDelete, sessionAlertID, projectAlert, nil)
 
 	if err := model.SendWelcomeSlackMessage(ctx, projectAlert, &model.SendWelcomeSlackMessageInput{
 		Workspace:            workspace,
@@ -3746,11 +4472,17 @@ func (r *mutationResolver) UpdateLogAlert(ctx context.Context, id int, input mod
 		return nil, e.Wrap(err, "failed to build log alert")
 	}
 
+	var before model.LogAlert
+	if err := r.DB.WithContext(ctx).Where("project_id = ?", input.ProjectID).Where("id = ?", id).Take(&before).Error; err != nil {
+		return nil, e.Wrap(err, "this log alert does not exist in this project.")
+	}
+
 	if err := r.DB.WithContext(ctx).Model(&model.LogAlert{Model: model.Model{ID: id}}).
 		Where("project_id = ?", input.ProjectID).
 		Updates(alert).Error; err != nil {
 		return nil, e.Wrap(err, "error updating log alert")
 	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetLogAlert, auditlog.VerbUpdate, id, &before, alert)
 
 	if err := model.SendWelcomeSlackMessage(ctx, alert, &model.SendWelcomeSlackMessageInput{
 		Workspace:            workspace,
@@ -3797,6 +4529,7 @@ func (r *mutationResolver) DeleteLogAlert(ctx context.Context, projectID int, id
 	if err := r.DB.WithContext(ctx).Where("id = ?", id).Delete(&model.LogAlert{}).Error; err != nil {
 		return nil, e.Wrap(err, "error trying to delete log alert")
 	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetLogAlert, auditlog.VerbDelete, id, alert, nil)
 
 	admin, err := r.getCurrentAdmin(ctx)
 	if err != nil {
@@ -3825,7 +4558,7 @@ func (r *mutationResolver) DeleteLogAlert(ctx context.Context, projectID int, id
 
 // UpdateLogAlertIsDisabled is the resolver for the updateLogAlertIsDisabled field.
 func (r *mutationResolver) UpdateLogAlertIsDisabled(ctx context.Context, id int, projectID int, disabled bool) (*model.LogAlert, error) {
-	_, err := r.isUserInProject(ctx, projectID)
+	project, err := r.isUserInProject(ctx, projectID)
 	if err != nil {
 		return nil, err
 	}
@@ -3843,6 +4576,7 @@ func (r *mutationResolver) UpdateLogAlertIsDisabled(ctx context.Context, id int,
 	}).Where("project_id = ?", projectID).Updates(alert).Error; err != nil {
 		return nil, e.Wrap(err, "error updating org fields for new session alert")
 	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetLogAlert, lo.Ternary(disabled, auditlog.VerbDisable, auditlog.VerbEnable), id, nil, nil)
 
 	return alert, err
 }
@@ -4184,6 +4918,12 @@ func (r *mutationResolver) DeleteSessions(ctx context.Context, projectID int, pa
 	if err != nil {
 		return false, err
 	}
+	r.recordProjectAuditLog(ctx, project, auditlog.TargetSessions, auditlog.VerbDelete, projectID, nil, map[string]any{
+		"query":         params.Query,
+		"start_date":    params.DateRange.StartDate,
+		"end_date":      params.DateRange.EndDate,
+		"session_count": sessionCount,
+	})
 	return true, nil
 }
 
@@ -4963,6 +5703,53 @@ func (r *queryResolver) RageClicksForProject(ctx context.Context, projectID int,
 	return rageClicks, nil
 }
 
+// Heatmap is the resolver for the heatmap field.
+func (r *queryResolver) Heatmap(ctx context.Context, projectID int, url string, params modelInputs.QueryInput, viewportWidth *int) (*modelInputs.Heatmap, error) {
+	project, err := r.isUserInProjectOrDemoProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	workspace, err := r.GetWorkspace(project.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	retentionDate := GetRetentionDate(workspace.RetentionPeriod)
+
+	// If there's no admin for the context, use `admin=nil`
+	// (admin is used by the "viewed by me" filter)
+	admin, err := r.getCurrentAdmin(ctx)
+	if errors.Is(err, AuthenticationError) {
+		admin = nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// heatmaps are aggregated by the normalized path of the page
+	normalizedURL, err := journey_handlers.NormalizeURL(url)
+	if err != nil {
+		return nil, err
+	}
+	if normalizedURL == "" {
+		normalizedURL = "/"
+	}
+
+	heatmap, err := r.ClickhouseClient.QueryHeatmap(ctx, admin, projectID, normalizedURL, viewportWidth, params, retentionDate)
+	if err != nil {
+		return nil, e.Wrap(err, "error querying heatmap")
+	}
+	return heatmap, nil
+}
+
+// WebVitalsReport is the resolver for the web_vitals_report field.
+func (r *queryResolver) WebVitalsReport(ctx context.Context, projectID int, params modelInputs.QueryInput, groupBy *modelInputs.WebVitalsGroupBy, compareDateRange *modelInputs.DateRangeRequiredInput, limit *int) ([]*modelInputs.WebVitalsGroup, error) {
+	project, err := r.isUserInProjectOrDemoProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.ClickhouseClient.QueryWebVitalsReport(ctx, project.ID, params, groupBy, compareDateRange, limit)
+}
+
 // ErrorGroupsClickhouse is the resolver for the error_groups_clickhouse field.
 func (r *queryResolver) ErrorGroupsClickhouse(ctx context.Context, projectID int, count int, query modelInputs.ClickhouseQuery, page *int) (*model.ErrorResults, error) {
 	_, err := r.isUserInProjectOrDemoProject(ctx, projectID)
@@ -5415,6 +6202,68 @@ func (r *queryResolver) Resources(ctx context.Context, sessionSecureID string) (
 	return resources, nil
 }
 
+// SessionHar is the resolver for the session_har field.
+func (r *queryResolver) SessionHar(ctx context.Context, sessionSecureID string, startTime *time.Time, endTime *time.Time) (string, error) {
+	s, err := r.canAdminViewSession(ctx, sessionSecureID)
+	if err != nil {
+		return "", err
+	}
+
+	s3Resources, err := r.StorageClient.GetRawData(ctx, s.ID, s.ProjectID, model.PayloadTypeResources)
+	if err != nil {
+		return "", e.Wrap(err, "error retrieving events objects from S3")
+	}
+
+	resources, err := r.Redis.GetResources(ctx, s, s3Resources)
+	if err != nil {
+		return "", e.Wrap(err, "error getting resources from redis")
+	}
+
+	webSocketEvents, err := r.StorageClient.ReadWebSocketEvents(ctx, s.ID, s.ProjectID)
+	if err != nil {
+		return "", e.Wrap(err, "failed to get websocket events from S3")
+	}
+
+	requestIDs, err := har.RequestIDs(resources)
+	if err != nil {
+		return "", err
+	}
+
+	// link the requests to the backend traces started with the X-Highlight-Request id
+	traceIDs := map[string]bool{}
+	if len(requestIDs) > 0 {
+		sessionEnd := s.CreatedAt.Add(time.Duration(s.Length) * time.Millisecond).Add(time.Hour)
+		existing, err := r.ClickhouseClient.ExistingTraceIds(ctx, s.ProjectID, requestIDs, s.CreatedAt.Add(-time.Hour), sessionEnd)
+		if err != nil {
+			return "", e.Wrap(err, "error querying traces of session requests")
+		}
+		for _, traceID := range existing {
+			traceIDs[traceID] = true
+		}
+	}
+
+	archive, err := har.Build(resources, webSocketEvents, har.Options{
+		SessionStart: s.CreatedAt,
+		StartTime:    startTime,
+		EndTime:      endTime,
+		TraceIDs:     traceIDs,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(archive)
+	if err != nil {
+		return "", e.Wrap(err, "error marshaling HAR")
+	}
+
+	if len(data) > MaxDownloadSize {
+		return "", fmt.Errorf("HAR size (%v) exceeds max download size", len(data))
+	}
+
+	return string(data), nil
+}
+
 // WebVitals is the resolver for the web_vitals field.
 func (r *queryResolver) WebVitals(ctx context.Context, sessionSecureID string) (*modelInputs.MetricsBuckets, error) {
 	// this function can be replaced with frontend query to GetMetrics
@@ -7703,6 +8552,11 @@ func (r *queryResolver) ProjectSettings(ctx context.Context, projectID int) (*mo
 		RageClickWindowSeconds:            &project.RageClickWindowSeconds,
 		RageClickRadiusPixels:             &project.RageClickRadiusPixels,
 		RageClickCount:                    &project.RageClickCount,
+		DeadClickTimeoutMilliseconds:      &project.DeadClickTimeoutMilliseconds,
+		ErrorClickWindowMilliseconds:      &project.ErrorClickWindowMilliseconds,
+		ThrashedCursorWindowMilliseconds:  &project.ThrashedCursorWindowMilliseconds,
+		ThrashedCursorDirectionChanges:    &project.ThrashedCursorDirectionChanges,
+		UTurnWindowSeconds:                &project.UTurnWindowSeconds,
 		FilterChromeExtension:             project.FilterChromeExtension,
 		FilterSessionsWithoutError:        projectFilterSettings.FilterSessionsWithoutError,
 		AutoResolveStaleErrorsDayInterval: projectFilterSettings.AutoResolveStaleErrorsDayInterval,
@@ -8417,6 +9271,13 @@ func (r *queryResolver) OauthClientMetadata(ctx context.Context, clientID string
 
 // SsoLogin is the resolver for the sso_login field.
 func (r *queryResolver) SsoLogin(ctx context.Context, domain string) (*modelInputs.SSOLogin, error) {
+	if samlClient, err := r.Store.GetSAMLClient(ctx, domain); err == nil {
+		return &modelInputs.SSOLogin{
+			Domain:   samlClient.Domain,
+			ClientID: samlClientIDPrefix + samlClient.Domain,
+		}, nil
+	}
+
 	client, err := r.Store.GetSSOClient(ctx, domain)
 	if err != nil {
 		log.WithContext(ctx).WithError(err).WithField("domain", domain).Error("error querying sso client")
@@ -8431,6 +9292,79 @@ func (r *queryResolver) SsoLogin(ctx context.Context, domain string) (*modelInpu
 	}, nil
 }
 
+// ScimTokens is the resolver for the scim_tokens field.
+func (r *queryResolver) ScimTokens(ctx context.Context, workspaceID int) ([]*model.SCIMToken, error) {
+	if _, err := r.isUserWorkspaceAdmin(ctx, workspaceID); err != nil {
+		return nil, err
+	}
+	return r.Store.GetSCIMTokens(ctx, workspaceID)
+}
+
+// ScimGroups is the resolver for the scim_groups field.
+func (r *queryResolver) ScimGroups(ctx context.Context, workspaceID int) ([]*model.SCIMGroup, error) {
+	if _, err := r.isUserWorkspaceAdmin(ctx, workspaceID); err != nil {
+		return nil, err
+	}
+	return r.Store.GetSCIMGroups(ctx, workspaceID)
+}
+
+// APITokens is the resolver for the api_tokens field.
+func (r *queryResolver) APITokens(ctx context.Context, workspaceID int) ([]*model.APIToken, error) {
+	if _, err := r.isUserInWorkspaceReadOnly(ctx, workspaceID); err != nil {
+		return nil, err
+	}
+	if err := r.validateAdminRole(ctx, workspaceID); err == nil {
+		return r.Store.GetAPITokens(ctx, workspaceID, nil)
+	}
+
+	admin, err := r.getCurrentAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.Store.GetAPITokens(ctx, workspaceID, &admin.ID)
+}
+
+// RedactionRules is the resolver for the redaction_rules field.
+func (r *queryResolver) RedactionRules(ctx context.Context, projectID int) ([]*model.RedactionRule, error) {
+	if _, err := r.isUserInProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	// read the redaction counts from the database rather than the ingest cache
+	return r.Store.GetRedactionRules(ctx, projectID, redis.WithBypassCache(true))
+}
+
+// AuditLogs is the resolver for the audit_logs field.
+func (r *queryResolver) AuditLogs(ctx context.Context, workspaceID int, params *modelInputs.AuditLogParamsInput, after *string, before *string) (*modelInputs.AuditLogConnection, error) {
+	if _, err := r.isUserWorkspaceAdmin(ctx, workspaceID); err != nil {
+		return nil, err
+	}
+
+	listParams := getAuditLogParams(params)
+	listParams.After = after
+	listParams.Before = before
+	connection, err := r.Store.ListAuditLogs(ctx, workspaceID, listParams)
+
+	return &connection, err
+}
+
+// AuditLogsExport is the resolver for the audit_logs_export field.
+func (r *queryResolver) AuditLogsExport(ctx context.Context, workspaceID int, params *modelInputs.AuditLogParamsInput) (string, error) {
+	if _, err := r.isUserWorkspaceAdmin(ctx, workspaceID); err != nil {
+		return "", err
+	}
+
+	auditLogs, err := r.Store.ExportAuditLogs(ctx, workspaceID, getAuditLogParams(params))
+	if err != nil {
+		return "", e.Wrap(err, "error querying audit logs")
+	}
+
+	var buf bytes.Buffer
+	if err := auditlog.WriteCSV(&buf, auditLogs); err != nil {
+		return "", e.Wrap(err, "error writing audit logs csv")
+	}
+	return buf.String(), nil
+}
+
 // EmailOptOuts is the resolver for the email_opt_outs field.
 func (r *queryResolver) EmailOptOuts(ctx context.Context, token *string, adminID *int) ([]modelInputs.EmailOptOutCategory, error) {
 	var adminIdDeref int
@@ -8979,6 +9913,143 @@ func (r *queryResolver) MatchErrorTag(ctx context.Context, query string) ([]*mod
 	return r.Resolver.MatchErrorTag(ctx, query)
 }
 
+// Releases is the resolver for the releases field.
+func (r *queryResolver) Releases(ctx context.Context, projectID int, count *int) ([]*model.Release, error) {
+	project, err := r.isUserInProjectOrDemoProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Store.ListReleases(ctx, project.ID, lo.FromPtr(count))
+}
+
+// ReleaseStats is the resolver for the release_stats field.
+func (r *queryResolver) ReleaseStats(ctx context.Context, projectID int, version string) (*modelInputs.ReleaseStats, error) {
+	project, err := r.isUserInProjectOrDemoProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := r.Store.GetRelease(ctx, project.ID, version)
+	if err != nil {
+		if e.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	stats, previous, err := r.getReleaseStats(ctx, release)
+	if err != nil {
+		return nil, err
+	}
+
+	if previous != nil {
+		stats.Previous, _, err = r.getReleaseStats(ctx, previous)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return stats, nil
+}
+
+// ReleaseErrorGroups is the resolver for the release_error_groups field.
+func (r *queryResolver) ReleaseErrorGroups(ctx context.Context, projectID int, version string, count int, page *int) (*model.ErrorResults, error) {
+	project, err := r.isUserInProjectOrDemoProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := r.Store.GetRelease(ctx, project.ID, version)
+	if err != nil {
+		if e.Is(err, gorm.ErrRecordNotFound) {
+			return &model.ErrorResults{ErrorGroups: []model.ErrorGroup{}}, nil
+		}
+		return nil, err
+	}
+
+	startDate, endDate := store.GetReleaseWindow(release)
+	ids, total, err := r.ClickhouseClient.QueryReleaseErrorGroupIds(ctx, project.ID, version, startDate, endDate, count, page)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*model.ErrorGroup
+	if err := r.DB.WithContext(ctx).Model(&model.ErrorGroup{}).
+		Joins("ErrorTag").
+		Where("error_groups.id in ?", ids).
+		Where("error_groups.project_id = ?", project.ID).
+		Find(&results).Error; err != nil {
+		return nil, err
+	}
+
+	if len(results) > 0 {
+		if err := r.loadErrorGroupFrequenciesClickhouse(ctx, project.ID, results); err != nil {
+			return nil, err
+		}
+	}
+
+	// keep the order of the error groups first seen most recently
+	order := map[int64]int{}
+	for idx, id := range ids {
+		order[id] = idx
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return order[int64(results[i].ID)] < order[int64(results[j].ID)]
+	})
+
+	return &model.ErrorResults{
+		ErrorGroups: lo.Map(results, func(eg *model.ErrorGroup, idx int) model.ErrorGroup { return *eg }),
+		TotalCount:  total,
+	}, nil
+}
+
+// ErrorGroupingRules is the resolver for the error_grouping_rules field.
+func (r *queryResolver) ErrorGroupingRules(ctx context.Context, projectID int) ([]*model.ErrorGroupingRule, error) {
+	if _, err := r.isUserInProjectOrDemoProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	return r.Store.GetErrorGroupingRules(ctx, projectID)
+}
+
+// ErrorGroupAssignmentRules is the resolver for the error_group_assignment_rules field.
+func (r *queryResolver) ErrorGroupAssignmentRules(ctx context.Context, projectID int) ([]*model.ErrorGroupAssignmentRule, error) {
+	if _, err := r.isUserInProjectOrDemoProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	return r.Store.GetErrorGroupAssignmentRules(ctx, projectID)
+}
+
+// AssigneeDestinations is the resolver for the assignee_destinations field.
+func (r *queryResolver) AssigneeDestinations(ctx context.Context, projectID int, assigneeAdminID *int, assigneeTeam *string) ([]*model.AssigneeDestination, error) {
+	if _, err := r.isUserInProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	return r.Store.GetAssigneeDestinations(ctx, projectID, assigneeAdminID, assigneeTeam)
+}
+
+// ErrorGroupingRulesPreview is the resolver for the error_grouping_rules_preview field.
+func (r *queryResolver) ErrorGroupingRulesPreview(ctx context.Context, projectID int, rules []*modelInputs.ErrorGroupingRuleInput, count *int) ([]*modelInputs.ErrorGroupingRulePreview, error) {
+	if _, err := r.isUserInProjectOrDemoProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	var errorGroupingRules []*model.ErrorGroupingRule
+	for idx, input := range rules {
+		rule, err := ErrorGroupingRuleFromInput(projectID, *input)
+		if err != nil {
+			return nil, err
+		}
+		// preview rules are not persisted, so order ties by their position in the input
+		rule.ID = idx + 1
+		errorGroupingRules = append(errorGroupingRules, rule)
+	}
+	limit := pointy.IntValue(count, 100)
+	if limit <= 0 || limit > 500 {
+		return nil, e.New("count must be between 1 and 500")
+	}
+	return r.PreviewErrorGroupingRules(ctx, projectID, errorGroupingRules, limit)
+}
+
 // Trace is the resolver for the trace field.
 func (r *queryResolver) Trace(ctx context.Context, projectID int, traceID string, timestamp time.Time, sessionSecureID *string) (*modelInputs.TracePayload, error) {
 	if _, err := r.canAdminViewSession(ctx, pointy.StringValue(sessionSecureID, "")); err != nil {
@@ -9259,6 +10330,8 @@ func (r *queryResolver) Metrics(ctx context.Context, productType modelInputs.Pro
 			productType = modelInputs.ProductTypeEvents
 		case "metrics":
 			productType = modelInputs.ProductTypeMetrics
+		case "web_vitals":
+			productType = modelInputs.ProductTypeWebVitals
 		default:
 			return nil, e.Errorf("Unknown table %s", table)
 		}
@@ -9278,6 +10351,11 @@ func (r *queryResolver) Metrics(ctx context.Context, productType modelInputs.Pro
 		results, err = r.ErrorsMetrics(ctx, projectID, params, sql, column, metricTypes, groupBy, bucketBy, bucketCount, bucketWindow, limit, limitAggregator, limitColumn, expressions)
 	case modelInputs.ProductTypeEvents:
 		results, err = r.EventsMetrics(ctx, projectID, params, sql, column, metricTypes, groupBy, bucketBy, bucketCount, bucketWindow, limit, limitAggregator, limitColumn, expressions)
+	case modelInputs.ProductTypeWebVitals:
+		expressions, err = normalizeExpressions(column, metricTypes, expressions)
+		if err == nil {
+			results, err = r.ClickhouseClient.ReadWebVitalsMetrics(ctx, projectID, params, sql, groupBy, bucketCount, bucketBy, bucketWindow, limit, limitAggregator, limitColumn, expressions)
+		}
 	default:
 		results, err = nil, e.Errorf("invalid product type %s", productType)
 	}
@@ -9321,6 +10399,8 @@ func (r *queryResolver) Keys(ctx context.Context, productType *modelInputs.Produ
 		return r.ErrorsKeys(ctx, projectID, dateRange, query, typeArg)
 	case modelInputs.ProductTypeEvents:
 		return r.EventsKeys(ctx, projectID, dateRange, query, typeArg, event)
+	case modelInputs.ProductTypeWebVitals:
+		return r.ClickhouseClient.WebVitalsKeys(ctx, project.ID, dateRange.StartDate, dateRange.EndDate, query, typeArg)
 	default:
 		return nil, e.Errorf("invalid product type %s", productType)
 	}
@@ -9350,6 +10430,8 @@ func (r *queryResolver) KeyValues(ctx context.Context, productType *modelInputs.
 		return r.ErrorsKeyValues(ctx, projectID, keyName, dateRange, query, count)
 	case modelInputs.ProductTypeEvents:
 		return r.EventsKeyValues(ctx, projectID, keyName, dateRange, query, count, event)
+	case modelInputs.ProductTypeWebVitals:
+		return r.ClickhouseClient.WebVitalsKeyValues(ctx, project.ID, keyName, dateRange.StartDate, dateRange.EndDate, query, count)
 	default:
 		return nil, e.Errorf("invalid product type %s", productType)
 	}
@@ -9484,11 +10566,20 @@ func (r *queryResolver) LogLines(ctx context.Context, productType modelInputs.Pr
 		return r.ClickhouseClient.ErrorsLogLines(ctx, project.ID, params)
 	case modelInputs.ProductTypeEvents:
 		return r.ClickhouseClient.EventsLogLines(ctx, project.ID, params)
+	case modelInputs.ProductTypeWebVitals:
+		return r.ClickhouseClient.WebVitalsLogLines(ctx, project.ID, params)
 	default:
 		return nil, e.Errorf("invalid product type %s", productType)
 	}
 }
 
+// ProjectIds is the resolver for the project_ids field.
+func (r *sCIMGroupResolver) ProjectIds(ctx context.Context, obj *model.SCIMGroup) ([]int, error) {
+	return lo.Map(obj.ProjectIds, func(id int32, _ int) int {
+		return int(id)
+	}), nil
+}
+
 // Params is the resolver for the params field.
 func (r *savedSegmentResolver) Params(ctx context.Context, obj *model.SavedSegment) (*model.SearchParams, error) {
 	params := &model.SearchParams{}
@@ -9854,6 +10945,9 @@ func (r *visualizationResolver) Variables(ctx context.Context, obj *model.Visual
 	return variables, nil
 }
 
+// APIToken returns generated.APITokenResolver implementation.
+func (r *Resolver) APIToken() generated.APITokenResolver { return &aPITokenResolver{r} }
+
 // AllWorkspaceSettings returns generated.AllWorkspaceSettingsResolver implementation.
 func (r *Resolver) AllWorkspaceSettings() generated.AllWorkspaceSettingsResolver {
 	return &allWorkspaceSettingsResolver{r}
@@ -9894,6 +10988,9 @@ func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResol
 // Query returns generated.QueryResolver implementation.
 func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
 
+// SCIMGroup returns generated.SCIMGroupResolver implementation.
+func (r *Resolver) SCIMGroup() generated.SCIMGroupResolver { return &sCIMGroupResolver{r} }
+
 // SavedSegment returns generated.SavedSegmentResolver implementation.
 func (r *Resolver) SavedSegment() generated.SavedSegmentResolver { return &savedSegmentResolver{r} }
 
@@ -9927,6 +11024,7 @@ func (r *Resolver) TimelineIndicatorEvent() generated.TimelineIndicatorEventReso
 // Visualization returns generated.VisualizationResolver implementation.
 func (r *Resolver) Visualization() generated.VisualizationResolver { return &visualizationResolver{r} }
 
+type aPITokenResolver struct{ *Resolver }
 type allWorkspaceSettingsResolver struct{ *Resolver }
 type commentReplyResolver struct{ *Resolver }
 type errorAlertResolver struct{ *Resolver }
@@ -9939,6 +11037,7 @@ type matchedErrorObjectResolver struct{ *Resolver }
 type metricMonitorResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type sCIMGroupResolver struct{ *Resolver }
 type savedSegmentResolver struct{ *Resolver }
 type serviceResolver struct{ *Resolver }
 type sessionResolver struct{ *Resolver }
@@ -0,0 +1,346 @@
+package promql
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	e "github.com/pkg/errors"
+	"github.com/samber/lo"
+	log "github.com/sirupsen/logrus"
+)
+
+const apiKeyHeader = "ApiKey"
+
+// ProjectAuthenticator resolves the project that a project API key belongs to.
+type ProjectAuthenticator func(ctx context.Context, apiKey string) (*int, error)
+
+// API implements the subset of the prometheus HTTP API used by dashboards such as grafana:
+// https://prometheus.io/docs/prometheus/latest/querying/api/
+type API struct {
+	querier      Querier
+	authenticate ProjectAuthenticator
+}
+
+func NewAPI(querier Querier, authenticate ProjectAuthenticator) *API {
+	return &API{querier: querier, authenticate: authenticate}
+}
+
+func (a *API) Listen(r chi.Router) {
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		r.MethodFunc(method, "/query", a.handleQuery)
+		r.MethodFunc(method, "/query_range", a.handleQueryRange)
+		r.MethodFunc(method, "/labels", a.handleLabels)
+		r.MethodFunc(method, "/series", a.handleSeries)
+	}
+	r.Get("/label/{name}/values", a.handleLabelValues)
+}
+
+type apiResponse struct {
+	Status    string `json:"status"`
+	Data      any    `json:"data,omitempty"`
+	ErrorType string `json:"errorType,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type queryData struct {
+	ResultType string `json:"resultType"`
+	Result     any    `json:"result"`
+}
+
+type vectorSample struct {
+	Metric Labels `json:"metric"`
+	Value  []any  `json:"value"`
+}
+
+type matrixSeries struct {
+	Metric Labels  `json:"metric"`
+	Values [][]any `json:"values"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, resp apiResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func writeData(w http.ResponseWriter, data any) {
+	writeJSON(w, http.StatusOK, apiResponse{Status: "success", Data: data})
+}
+
+func writeError(w http.ResponseWriter, status int, errorType string, err error) {
+	writeJSON(w, status, apiResponse{Status: "error", ErrorType: errorType, Error: err.Error()})
+}
+
+func getAPIKey(r *http.Request) string {
+	if apiKey := r.Header.Get(apiKeyHeader); apiKey != "" {
+		return apiKey
+	}
+	if username, password, ok := r.BasicAuth(); ok {
+		if password != "" {
+			return password
+		}
+		return username
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+func (a *API) getProjectID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	apiKey := getAPIKey(r)
+	if apiKey == "" {
+		writeError(w, http.StatusUnauthorized, "unauthorized", e.New("a project API key is required"))
+		return 0, false
+	}
+	projectID, err := a.authenticate(r.Context(), apiKey)
+	if err != nil || projectID == nil || *projectID == 0 {
+		writeError(w, http.StatusUnauthorized, "unauthorized", e.New("invalid project API key"))
+		return 0, false
+	}
+	return *projectID, true
+}
+
+// parseTime parses a unix timestamp in (fractional) seconds or an RFC3339 timestamp.
+func parseTime(s string, fallback time.Time) (time.Time, error) {
+	if s == "" {
+		return fallback, nil
+	}
+	if t, err := strconv.ParseFloat(s, 64); err == nil {
+		sec, frac := math.Modf(t)
+		return time.Unix(int64(sec), int64(math.Round(frac*1e3))*int64(time.Millisecond)).UTC(), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, e.Errorf("cannot parse %q to a valid timestamp", s)
+}
+
+// parseStep parses a duration in (fractional) seconds or a prometheus duration string.
+func parseStep(s string) (time.Duration, error) {
+	if d, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(d * float64(time.Second)), nil
+	}
+	return ParseDuration(s)
+}
+
+func formatTime(t time.Time) float64 {
+	return float64(t.UnixMilli()) / 1e3
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func sortSeries(series []*Series) {
+	sort.SliceStable(series, func(i, j int) bool {
+		return series[i].Labels.Key() < series[j].Labels.Key()
+	})
+}
+
+func (a *API) handleQuery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	projectID, ok := a.getProjectID(w, r)
+	if !ok {
+		return
+	}
+
+	ts, err := parseTime(r.FormValue("time"), time.Now())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_data", err)
+		return
+	}
+	expr, err := Parse(r.FormValue("query"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_data", err)
+		return
+	}
+	ev, err := NewEvaluator(a.querier, projectID, ts, ts, time.Minute)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_data", err)
+		return
+	}
+	value, err := ev.Eval(ctx, expr)
+	if err != nil {
+		log.WithContext(ctx).WithError(err).WithField("query", r.FormValue("query")).Warn("failed to evaluate promql query")
+		writeError(w, http.StatusUnprocessableEntity, "execution", err)
+		return
+	}
+
+	if value.Scalar {
+		writeData(w, queryData{
+			ResultType: "scalar",
+			Result:     []any{formatTime(ts), formatValue(value.Series[0].Values[0])},
+		})
+		return
+	}
+	sortSeries(value.Series)
+	result := lo.Map(value.Series, func(s *Series, _ int) vectorSample {
+		return vectorSample{Metric: s.Labels, Value: []any{formatTime(ts), formatValue(s.Values[0])}}
+	})
+	writeData(w, queryData{ResultType: "vector", Result: result})
+}
+
+func (a *API) handleQueryRange(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	projectID, ok := a.getProjectID(w, r)
+	if !ok {
+		return
+	}
+
+	start, err := parseTime(r.FormValue("start"), time.Time{})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_data", err)
+		return
+	}
+	end, err := parseTime(r.FormValue("end"), time.Time{})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_data", err)
+		return
+	}
+	step, err := parseStep(r.FormValue("step"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_data", err)
+		return
+	}
+	expr, err := Parse(r.FormValue("query"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_data", err)
+		return
+	}
+	ev, err := NewEvaluator(a.querier, projectID, start, end, step)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_data", err)
+		return
+	}
+	value, err := ev.Eval(ctx, expr)
+	if err != nil {
+		log.WithContext(ctx).WithError(err).WithField("query", r.FormValue("query")).Warn("failed to evaluate promql range query")
+		writeError(w, http.StatusUnprocessableEntity, "execution", err)
+		return
+	}
+
+	sortSeries(value.Series)
+	result := []matrixSeries{}
+	for _, s := range value.Series {
+		series := matrixSeries{Metric: s.Labels, Values: [][]any{}}
+		if series.Metric == nil {
+			series.Metric = Labels{}
+		}
+		for i, present := range s.Present {
+			if present {
+				series.Values = append(series.Values, []any{formatTime(ev.StepTime(i)), formatValue(s.Values[i])})
+			}
+		}
+		result = append(result, series)
+	}
+	writeData(w, queryData{ResultType: "matrix", Result: result})
+}
+
+func parseRange(r *http.Request) (time.Time, time.Time, error) {
+	end, err := parseTime(r.FormValue("end"), time.Now())
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	start, err := parseTime(r.FormValue("start"), end.Add(-24*time.Hour))
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, end, nil
+}
+
+func (a *API) handleLabels(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := a.getProjectID(w, r)
+	if !ok {
+		return
+	}
+	start, end, err := parseRange(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_data", err)
+		return
+	}
+	names, err := a.querier.LabelNames(r.Context(), projectID, start, end)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "execution", err)
+		return
+	}
+	sort.Strings(names)
+	writeData(w, lo.Uniq(names))
+}
+
+func (a *API) handleLabelValues(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := a.getProjectID(w, r)
+	if !ok {
+		return
+	}
+	start, end, err := parseRange(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_data", err)
+		return
+	}
+	values, err := a.querier.LabelValues(r.Context(), projectID, chi.URLParam(r, "name"), start, end)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "execution", err)
+		return
+	}
+	sort.Strings(values)
+	writeData(w, lo.Uniq(values))
+}
+
+func (a *API) handleSeries(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := a.getProjectID(w, r)
+	if !ok {
+		return
+	}
+	start, end, err := parseRange(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_data", err)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_data", err)
+		return
+	}
+	matches := r.Form["match[]"]
+	if len(matches) == 0 {
+		writeError(w, http.StatusBadRequest, "bad_data", e.New("no match[] parameter provided"))
+		return
+	}
+
+	seen := make(map[string]bool)
+	result := []Labels{}
+	for _, match := range matches {
+		sel, err := ParseSelector(match)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "bad_data", err)
+			return
+		}
+		series, err := a.querier.Series(r.Context(), projectID, sel.Matchers, start, end)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "execution", err)
+			return
+		}
+		for _, labels := range series {
+			if key := labels.Key(); !seen[key] {
+				seen[key] = true
+				result = append(result, labels)
+			}
+		}
+	}
+	writeData(w, result)
+}
@@ -0,0 +1,104 @@
+package promql
+
+import (
+	"time"
+)
+
+// The subset of PromQL supported by the query API. Selectors and range functions are
+// translated to ClickHouse queries, the rest of the expression is evaluated in memory.
+
+const (
+	MetricNameLabel = "__name__"
+	JobLabel        = "job"
+	BucketLabel     = "le"
+)
+
+type MatchType int
+
+const (
+	MatchEqual MatchType = iota
+	MatchNotEqual
+	MatchRegexp
+	MatchNotRegexp
+)
+
+func (m MatchType) String() string {
+	switch m {
+	case MatchNotEqual:
+		return "!="
+	case MatchRegexp:
+		return "=~"
+	case MatchNotRegexp:
+		return "!~"
+	default:
+		return "="
+	}
+}
+
+type Matcher struct {
+	Type  MatchType
+	Name  string
+	Value string
+}
+
+type Expr interface {
+	expr()
+}
+
+type NumberLiteral struct {
+	Val float64
+}
+
+type VectorSelector struct {
+	Name     string
+	Matchers []*Matcher
+}
+
+type MatrixSelector struct {
+	Vector *VectorSelector
+	Range  time.Duration
+}
+
+type Call struct {
+	Func string
+	Args []Expr
+}
+
+type AggregateExpr struct {
+	Op       string
+	Grouping []string
+	Without  bool
+	Expr     Expr
+}
+
+type BinaryExpr struct {
+	Op  string
+	LHS Expr
+	RHS Expr
+}
+
+func (*NumberLiteral) expr()  {}
+func (*VectorSelector) expr() {}
+func (*MatrixSelector) expr() {}
+func (*Call) expr()           {}
+func (*AggregateExpr) expr()  {}
+func (*BinaryExpr) expr()     {}
+
+var aggregateOps = map[string]bool{
+	"sum":   true,
+	"avg":   true,
+	"min":   true,
+	"max":   true,
+	"count": true,
+}
+
+// functions that take a range vector, evaluated from the window aggregates returned by the Querier
+var rangeFunctions = map[string]bool{
+	"rate":            true,
+	"increase":        true,
+	"sum_over_time":   true,
+	"avg_over_time":   true,
+	"count_over_time": true,
+}
+
+const histogramQuantileFunction = "histogram_quantile"
@@ -0,0 +1,508 @@
+package promql
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	e "github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// DefaultLookback is how far back an instant selector looks for the latest sample.
+const DefaultLookback = 5 * time.Minute
+
+// MaxPoints limits the resolution of range queries, matching the prometheus server limit.
+const MaxPoints = 11_000
+
+type Labels map[string]string
+
+// Key returns a canonical representation of the label set.
+func (l Labels) Key(exclude ...string) string {
+	keys := lo.Filter(lo.Keys(l), func(k string, _ int) bool {
+		return !lo.Contains(exclude, k)
+	})
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(strconv.Quote(k))
+		b.WriteString("=")
+		b.WriteString(strconv.Quote(l[k]))
+		b.WriteString(",")
+	}
+	return b.String()
+}
+
+func (l Labels) without(names ...string) Labels {
+	return lo.OmitByKeys(l, names)
+}
+
+// Window is the aggregate of the samples of a series that fall into the window ending at a step.
+type Window struct {
+	Step  int
+	First float64
+	Last  float64
+	Sum   float64
+	Count uint64
+	// Delta is set for series stored with delta aggregation temporality, where
+	// each sample is the increase since the previous one.
+	Delta bool
+}
+
+type SeriesWindows struct {
+	Labels  Labels
+	Windows []Window
+}
+
+type SelectParams struct {
+	Matchers []*Matcher
+	Start    time.Time
+	Step     time.Duration
+	Steps    int
+	// Window is the duration of the window ending at each step that samples are aggregated over.
+	Window time.Duration
+}
+
+// StepTime returns the timestamp of the i-th evaluation step.
+func (p SelectParams) StepTime(i int) time.Time {
+	return p.Start.Add(time.Duration(i) * p.Step)
+}
+
+// Querier reads metrics data for a project. It is implemented on top of the ClickHouse metrics tables.
+type Querier interface {
+	SelectWindows(ctx context.Context, projectID int, params SelectParams) ([]*SeriesWindows, error)
+	LabelNames(ctx context.Context, projectID int, start, end time.Time) ([]string, error)
+	LabelValues(ctx context.Context, projectID int, name string, start, end time.Time) ([]string, error)
+	Series(ctx context.Context, projectID int, matchers []*Matcher, start, end time.Time) ([]Labels, error)
+}
+
+// Series is an evaluated time series, with a value for each step where `Present` is set.
+type Series struct {
+	Labels  Labels
+	Values  []float64
+	Present []bool
+}
+
+func newSeries(labels Labels, steps int) *Series {
+	return &Series{Labels: labels, Values: make([]float64, steps), Present: make([]bool, steps)}
+}
+
+func (s *Series) set(step int, value float64) {
+	s.Values[step] = value
+	s.Present[step] = true
+}
+
+func (s *Series) empty() bool {
+	return !lo.Contains(s.Present, true)
+}
+
+// Value is the result of evaluating an expression: either a scalar or an instant vector at each step.
+type Value struct {
+	Scalar bool
+	Series []*Series
+}
+
+type Evaluator struct {
+	Querier   Querier
+	ProjectID int
+	Start     time.Time
+	Step      time.Duration
+	Steps     int
+	Lookback  time.Duration
+}
+
+func NewEvaluator(querier Querier, projectID int, start, end time.Time, step time.Duration) (*Evaluator, error) {
+	if end.Before(start) {
+		return nil, e.New("end timestamp must not be before start time")
+	}
+	if step <= 0 {
+		return nil, e.New("zero or negative query resolution step widths are not accepted")
+	}
+	steps := int(end.Sub(start)/step) + 1
+	if steps > MaxPoints {
+		return nil, e.New("exceeded maximum resolution of 11,000 points per timeseries")
+	}
+	return &Evaluator{
+		Querier:   querier,
+		ProjectID: projectID,
+		Start:     start,
+		Step:      step,
+		Steps:     steps,
+		Lookback:  DefaultLookback,
+	}, nil
+}
+
+func (ev *Evaluator) StepTime(i int) time.Time {
+	return ev.Start.Add(time.Duration(i) * ev.Step)
+}
+
+func (ev *Evaluator) Eval(ctx context.Context, expr Expr) (*Value, error) {
+	switch n := expr.(type) {
+	case *NumberLiteral:
+		s := newSeries(nil, ev.Steps)
+		for i := range s.Values {
+			s.set(i, n.Val)
+		}
+		return &Value{Scalar: true, Series: []*Series{s}}, nil
+	case *VectorSelector:
+		windows, err := ev.selectWindows(ctx, n, ev.Lookback)
+		if err != nil {
+			return nil, err
+		}
+		return ev.fromWindows(windows, true, func(w Window) (float64, bool) {
+			return w.Last, true
+		}), nil
+	case *MatrixSelector:
+		return nil, e.New("range vector selectors must be wrapped in a range function such as rate()")
+	case *Call:
+		return ev.evalCall(ctx, n)
+	case *AggregateExpr:
+		return ev.evalAggregate(ctx, n)
+	case *BinaryExpr:
+		return ev.evalBinary(ctx, n)
+	}
+	return nil, e.Errorf("unsupported expression %T", expr)
+}
+
+func (ev *Evaluator) selectWindows(ctx context.Context, sel *VectorSelector, window time.Duration) ([]*SeriesWindows, error) {
+	return ev.Querier.SelectWindows(ctx, ev.ProjectID, SelectParams{
+		Matchers: sel.Matchers,
+		Start:    ev.Start,
+		Step:     ev.Step,
+		Steps:    ev.Steps,
+		Window:   window,
+	})
+}
+
+func (ev *Evaluator) fromWindows(windows []*SeriesWindows, keepName bool, fn func(w Window) (float64, bool)) *Value {
+	result := &Value{}
+	for _, sw := range windows {
+		labels := sw.Labels
+		if !keepName {
+			labels = labels.without(MetricNameLabel)
+		}
+		s := newSeries(labels, ev.Steps)
+		for _, w := range sw.Windows {
+			if w.Step < 0 || w.Step >= ev.Steps {
+				continue
+			}
+			if v, ok := fn(w); ok {
+				s.set(w.Step, v)
+			}
+		}
+		if !s.empty() {
+			result.Series = append(result.Series, s)
+		}
+	}
+	return result
+}
+
+// increase returns the increase of a counter over the window. Unlike prometheus, the result is not
+// extrapolated to the window boundaries and counter resets within the window are not detected.
+func increase(w Window) (float64, bool) {
+	if w.Delta {
+		return w.Sum, true
+	}
+	if w.Count < 2 {
+		return 0, false
+	}
+	return w.Last - w.First, true
+}
+
+func (ev *Evaluator) evalCall(ctx context.Context, call *Call) (*Value, error) {
+	if call.Func == histogramQuantileFunction {
+		q := call.Args[0].(*NumberLiteral).Val
+		v, err := ev.Eval(ctx, call.Args[1])
+		if err != nil {
+			return nil, err
+		}
+		if v.Scalar {
+			return nil, e.New("histogram_quantile expects an instant vector")
+		}
+		return ev.histogramQuantile(q, v), nil
+	}
+
+	matrix := call.Args[0].(*MatrixSelector)
+	windows, err := ev.selectWindows(ctx, matrix.Vector, matrix.Range)
+	if err != nil {
+		return nil, err
+	}
+	seconds := matrix.Range.Seconds()
+	var fn func(w Window) (float64, bool)
+	switch call.Func {
+	case "rate":
+		fn = func(w Window) (float64, bool) {
+			inc, ok := increase(w)
+			return inc / seconds, ok
+		}
+	case "increase":
+		fn = func(w Window) (float64, bool) {
+			return increase(w)
+		}
+	case "sum_over_time":
+		fn = func(w Window) (float64, bool) {
+			return w.Sum, w.Count > 0
+		}
+	case "avg_over_time":
+		fn = func(w Window) (float64, bool) {
+			return w.Sum / float64(w.Count), w.Count > 0
+		}
+	case "count_over_time":
+		fn = func(w Window) (float64, bool) {
+			return float64(w.Count), w.Count > 0
+		}
+	default:
+		return nil, e.Errorf("unsupported function %q", call.Func)
+	}
+	return ev.fromWindows(windows, false, fn), nil
+}
+
+func (ev *Evaluator) evalAggregate(ctx context.Context, agg *AggregateExpr) (*Value, error) {
+	v, err := ev.Eval(ctx, agg.Expr)
+	if err != nil {
+		return nil, err
+	}
+	if v.Scalar {
+		return nil, e.Errorf("%s expects an instant vector", agg.Op)
+	}
+
+	type group struct {
+		series *Series
+		counts []int
+	}
+	groups := make(map[string]*group)
+	var keys []string
+	for _, s := range v.Series {
+		var labels Labels
+		if agg.Without {
+			labels = s.Labels.without(append(agg.Grouping, MetricNameLabel)...)
+		} else {
+			labels = lo.PickByKeys(s.Labels, agg.Grouping)
+		}
+		key := labels.Key()
+		g, ok := groups[key]
+		if !ok {
+			g = &group{series: newSeries(labels, ev.Steps), counts: make([]int, ev.Steps)}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		for i, present := range s.Present {
+			if !present {
+				continue
+			}
+			val := s.Values[i]
+			cur := g.series.Values[i]
+			switch {
+			case g.counts[i] == 0:
+				cur = val
+			case agg.Op == "sum" || agg.Op == "avg":
+				cur += val
+			case agg.Op == "min":
+				cur = math.Min(cur, val)
+			case agg.Op == "max":
+				cur = math.Max(cur, val)
+			}
+			g.counts[i]++
+			g.series.set(i, cur)
+		}
+	}
+
+	result := &Value{}
+	for _, key := range keys {
+		g := groups[key]
+		for i, count := range g.counts {
+			if count == 0 {
+				continue
+			}
+			switch agg.Op {
+			case "avg":
+				g.series.Values[i] /= float64(count)
+			case "count":
+				g.series.Values[i] = float64(count)
+			}
+		}
+		result.Series = append(result.Series, g.series)
+	}
+	return result, nil
+}
+
+func applyOp(op string, lhs, rhs float64) float64 {
+	switch op {
+	case "+":
+		return lhs + rhs
+	case "-":
+		return lhs - rhs
+	case "*":
+		return lhs * rhs
+	case "/":
+		return lhs / rhs
+	}
+	return math.NaN()
+}
+
+func (ev *Evaluator) evalBinary(ctx context.Context, bin *BinaryExpr) (*Value, error) {
+	lhs, err := ev.Eval(ctx, bin.LHS)
+	if err != nil {
+		return nil, err
+	}
+	rhs, err := ev.Eval(ctx, bin.RHS)
+	if err != nil {
+		return nil, err
+	}
+
+	// scalar / vector operations apply the scalar to every series, dropping the metric name
+	combine := func(vector *Value, scalar *Series, scalarOnLeft bool) *Value {
+		result := &Value{Scalar: vector.Scalar}
+		for _, s := range vector.Series {
+			out := newSeries(s.Labels.without(MetricNameLabel), ev.Steps)
+			for i, present := range s.Present {
+				if !present || !scalar.Present[i] {
+					continue
+				}
+				if scalarOnLeft {
+					out.set(i, applyOp(bin.Op, scalar.Values[i], s.Values[i]))
+				} else {
+					out.set(i, applyOp(bin.Op, s.Values[i], scalar.Values[i]))
+				}
+			}
+			result.Series = append(result.Series, out)
+		}
+		return result
+	}
+
+	if rhs.Scalar {
+		return combine(lhs, rhs.Series[0], false), nil
+	}
+	if lhs.Scalar {
+		return combine(rhs, lhs.Series[0], true), nil
+	}
+
+	// vector / vector operations match series one-to-one on their labels, ignoring the metric name
+	rhsByKey := make(map[string]*Series, len(rhs.Series))
+	for _, s := range rhs.Series {
+		key := s.Labels.Key(MetricNameLabel)
+		if _, ok := rhsByKey[key]; ok {
+			return nil, e.Errorf("found duplicate series for the match group %s on the right hand-side of the operation", key)
+		}
+		rhsByKey[key] = s
+	}
+	result := &Value{}
+	for _, l := range lhs.Series {
+		r, ok := rhsByKey[l.Labels.Key(MetricNameLabel)]
+		if !ok {
+			continue
+		}
+		out := newSeries(l.Labels.without(MetricNameLabel), ev.Steps)
+		for i := range l.Values {
+			if l.Present[i] && r.Present[i] {
+				out.set(i, applyOp(bin.Op, l.Values[i], r.Values[i]))
+			}
+		}
+		if !out.empty() {
+			result.Series = append(result.Series, out)
+		}
+	}
+	return result, nil
+}
+
+type bucket struct {
+	upper float64
+	count float64
+}
+
+func (ev *Evaluator) histogramQuantile(q float64, v *Value) *Value {
+	type group struct {
+		labels  Labels
+		buckets [][]bucket
+	}
+	groups := make(map[string]*group)
+	var keys []string
+	for _, s := range v.Series {
+		upper, err := strconv.ParseFloat(s.Labels[BucketLabel], 64)
+		if err != nil {
+			// series without a valid `le` label are ignored
+			continue
+		}
+		labels := s.Labels.without(BucketLabel, MetricNameLabel)
+		key := labels.Key()
+		g, ok := groups[key]
+		if !ok {
+			g = &group{labels: labels, buckets: make([][]bucket, ev.Steps)}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		for i, present := range s.Present {
+			if present {
+				g.buckets[i] = append(g.buckets[i], bucket{upper: upper, count: s.Values[i]})
+			}
+		}
+	}
+
+	result := &Value{}
+	for _, key := range keys {
+		g := groups[key]
+		out := newSeries(g.labels, ev.Steps)
+		for i, buckets := range g.buckets {
+			if len(buckets) > 0 {
+				out.set(i, bucketQuantile(q, buckets))
+			}
+		}
+		if !out.empty() {
+			result.Series = append(result.Series, out)
+		}
+	}
+	return result
+}
+
+// bucketQuantile calculates the quantile from cumulative histogram buckets, using
+// linear interpolation within the bucket like prometheus does.
+func bucketQuantile(q float64, buckets []bucket) float64 {
+	if q < 0 {
+		return math.Inf(-1)
+	}
+	if q > 1 {
+		return math.Inf(1)
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].upper < buckets[j].upper
+	})
+	if len(buckets) < 2 || !math.IsInf(buckets[len(buckets)-1].upper, 1) {
+		return math.NaN()
+	}
+	// bucket counts must be monotonic, which may not be the case due to the lack of counter reset detection
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i].count < buckets[i-1].count {
+			buckets[i].count = buckets[i-1].count
+		}
+	}
+
+	total := buckets[len(buckets)-1].count
+	if total == 0 {
+		return math.NaN()
+	}
+	rank := q * total
+	b := sort.Search(len(buckets)-1, func(i int) bool {
+		return buckets[i].count >= rank
+	})
+	if b == len(buckets)-1 {
+		return buckets[len(buckets)-2].upper
+	}
+	if b == 0 && buckets[0].upper <= 0 {
+		return buckets[0].upper
+	}
+
+	var start, prevCount float64
+	if b > 0 {
+		start = buckets[b-1].upper
+		prevCount = buckets[b-1].count
+	}
+	end := buckets[b].upper
+	count := buckets[b].count - prevCount
+	if count == 0 {
+		return end
+	}
+	return start + (end-start)*((rank-prevCount)/count)
+}
@@ -0,0 +1,192 @@
+package promql
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeQuerier struct {
+	series []*SeriesWindows
+	params []SelectParams
+}
+
+func (f *fakeQuerier) SelectWindows(_ context.Context, _ int, params SelectParams) ([]*SeriesWindows, error) {
+	f.params = append(f.params, params)
+	name, _ := lo.Find(params.Matchers, func(m *Matcher) bool {
+		return m.Name == MetricNameLabel
+	})
+	return lo.Filter(f.series, func(s *SeriesWindows, _ int) bool {
+		return name == nil || s.Labels[MetricNameLabel] == name.Value
+	}), nil
+}
+
+func (f *fakeQuerier) LabelNames(context.Context, int, time.Time, time.Time) ([]string, error) {
+	return []string{JobLabel, MetricNameLabel, JobLabel}, nil
+}
+
+func (f *fakeQuerier) LabelValues(_ context.Context, _ int, name string, _ time.Time, _ time.Time) ([]string, error) {
+	return []string{"b", "a"}, nil
+}
+
+func (f *fakeQuerier) Series(context.Context, int, []*Matcher, time.Time, time.Time) ([]Labels, error) {
+	return []Labels{{MetricNameLabel: "x"}}, nil
+}
+
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newTestEvaluator(t *testing.T, q Querier, steps int) *Evaluator {
+	ev, err := NewEvaluator(q, 1, start, start.Add(time.Duration(steps-1)*time.Minute), time.Minute)
+	assert.NoError(t, err)
+	return ev
+}
+
+func eval(t *testing.T, q Querier, query string) *Value {
+	expr, err := Parse(query)
+	assert.NoError(t, err)
+	v, err := newTestEvaluator(t, q, 2).Eval(context.Background(), expr)
+	assert.NoError(t, err)
+	return v
+}
+
+func TestEval_RateAndSumBy(t *testing.T) {
+	q := &fakeQuerier{series: []*SeriesWindows{
+		{Labels: Labels{MetricNameLabel: "requests_total", JobLabel: "api", "path": "/a"}, Windows: []Window{
+			{Step: 0, First: 0, Last: 60, Count: 2},
+			{Step: 1, First: 60, Last: 180, Count: 3},
+		}},
+		{Labels: Labels{MetricNameLabel: "requests_total", JobLabel: "api", "path": "/b"}, Windows: []Window{
+			{Step: 0, Sum: 30, Count: 1, Delta: true},
+			// a single sample of a cumulative counter has no rate
+			{Step: 1, Last: 5, Count: 1},
+		}},
+		{Labels: Labels{MetricNameLabel: "requests_total", JobLabel: "worker"}, Windows: []Window{
+			{Step: 1, First: 0, Last: 600, Count: 2},
+		}},
+	}}
+
+	v := eval(t, q, `sum by (job) (rate(requests_total[1m]))`)
+	assert.Equal(t, time.Minute, q.params[0].Window)
+	assert.Len(t, v.Series, 2)
+	api := v.Series[0]
+	assert.Equal(t, Labels{JobLabel: "api"}, api.Labels)
+	assert.Equal(t, []float64{1.5, 2}, api.Values)
+	worker := v.Series[1]
+	assert.Equal(t, []bool{false, true}, worker.Present)
+	assert.Equal(t, 10., worker.Values[1])
+
+	v = eval(t, q, `count(requests_total) without (path)`)
+	assert.Equal(t, DefaultLookback, q.params[1].Window)
+	assert.Len(t, v.Series, 2)
+	assert.Equal(t, Labels{JobLabel: "api"}, v.Series[0].Labels)
+	assert.Equal(t, []float64{2, 2}, v.Series[0].Values)
+}
+
+func TestEval_Arithmetic(t *testing.T) {
+	q := &fakeQuerier{series: []*SeriesWindows{
+		{Labels: Labels{MetricNameLabel: "errors", JobLabel: "api"}, Windows: []Window{{Step: 0, Last: 5}, {Step: 1, Last: 10}}},
+		{Labels: Labels{MetricNameLabel: "requests", JobLabel: "api"}, Windows: []Window{{Step: 0, Last: 50}, {Step: 1, Last: 200}}},
+		{Labels: Labels{MetricNameLabel: "requests", JobLabel: "worker"}, Windows: []Window{{Step: 0, Last: 1}}},
+	}}
+
+	v := eval(t, q, `errors / requests * 100`)
+	assert.Len(t, v.Series, 1)
+	assert.Equal(t, Labels{JobLabel: "api"}, v.Series[0].Labels)
+	assert.Equal(t, []float64{10, 5}, v.Series[0].Values)
+
+	v = eval(t, q, `-2 * 3 + 1`)
+	assert.True(t, v.Scalar)
+	assert.Equal(t, []float64{-5, -5}, v.Series[0].Values)
+}
+
+func TestEval_HistogramQuantile(t *testing.T) {
+	bucket := func(le string, count float64) *SeriesWindows {
+		return &SeriesWindows{
+			Labels:  Labels{MetricNameLabel: "duration_bucket", BucketLabel: le},
+			Windows: []Window{{Step: 0, Sum: count, Count: 1, Delta: true}},
+		}
+	}
+	q := &fakeQuerier{series: []*SeriesWindows{
+		bucket("0.1", 50), bucket("0.5", 90), bucket("1", 100), bucket("+Inf", 100),
+	}}
+
+	v := eval(t, q, `histogram_quantile(0.5, sum by (le) (increase(duration_bucket[5m])))`)
+	assert.Len(t, v.Series, 1)
+	assert.Equal(t, Labels{}, v.Series[0].Labels)
+	assert.InDelta(t, 0.1, v.Series[0].Values[0], 1e-9)
+
+	v = eval(t, q, `histogram_quantile(0.7, increase(duration_bucket[5m]))`)
+	assert.InDelta(t, 0.3, v.Series[0].Values[0], 1e-9)
+}
+
+func TestBucketQuantile(t *testing.T) {
+	assert.True(t, math.IsNaN(bucketQuantile(0.5, []bucket{{upper: 1, count: 1}})))
+	assert.Equal(t, 1., bucketQuantile(0.99, []bucket{{upper: 1, count: 1}, {upper: math.Inf(1), count: 2}}))
+	assert.Equal(t, math.Inf(1), bucketQuantile(2, nil))
+}
+
+func TestNewEvaluator_Validation(t *testing.T) {
+	_, err := NewEvaluator(&fakeQuerier{}, 1, start, start.Add(-time.Minute), time.Minute)
+	assert.Error(t, err)
+	_, err = NewEvaluator(&fakeQuerier{}, 1, start, start, 0)
+	assert.Error(t, err)
+	_, err = NewEvaluator(&fakeQuerier{}, 1, start, start.Add(time.Hour*24*30), time.Second)
+	assert.Error(t, err)
+}
+
+func TestAPI_QueryRange(t *testing.T) {
+	q := &fakeQuerier{series: []*SeriesWindows{
+		{Labels: Labels{MetricNameLabel: "up", JobLabel: "api"}, Windows: []Window{{Step: 1, Last: 1}}},
+	}}
+	api := NewAPI(q, func(_ context.Context, apiKey string) (*int, error) {
+		if apiKey != "secret" {
+			return nil, nil
+		}
+		return lo.ToPtr(1), nil
+	})
+	r := chi.NewRouter()
+	api.Listen(r)
+
+	params := url.Values{
+		"query": {"up"},
+		"start": {"1704067200"},
+		"end":   {"2024-01-01T00:01:00Z"},
+		"step":  {"60"},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/query_range?"+params.Encode(), nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/query_range?"+params.Encode(), nil)
+	req.SetBasicAuth("1", "secret")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"__name__":"up","job":"api"},"values":[[1704067260,"1"]]}]}}`, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/label/job/values", nil)
+	req.Header.Set(apiKeyHeader, "secret")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	var resp struct {
+		Data []string
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, []string{"a", "b"}, resp.Data)
+
+	req = httptest.NewRequest(http.MethodGet, "/query?query=rate(up)", nil)
+	req.Header.Set(apiKeyHeader, "secret")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
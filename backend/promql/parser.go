@@ -0,0 +1,454 @@
+package promql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	e "github.com/pkg/errors"
+)
+
+type tokenType int
+
+const (
+	tokenEOF tokenType = iota
+	tokenIdentifier
+	tokenNumber
+	tokenString
+	tokenDuration
+	tokenPunctuation
+)
+
+type token struct {
+	typ tokenType
+	val string
+	pos int
+}
+
+var durationPattern = regexp.MustCompile(`^([0-9]+(ms|s|m|h|d|w|y))+$`)
+var durationPartPattern = regexp.MustCompile(`([0-9]+)(ms|s|m|h|d|w|y)`)
+
+func lex(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'' || r == '`':
+			start := i
+			i++
+			for i < len(runes) && runes[i] != r {
+				if runes[i] == '\\' && r != '`' {
+					i++
+				}
+				i++
+			}
+			if i >= len(runes) {
+				return nil, e.Errorf("unterminated string at position %d", start)
+			}
+			i++
+			val, err := unquote(string(runes[start:i]))
+			if err != nil {
+				return nil, e.Wrapf(err, "invalid string at position %d", start)
+			}
+			tokens = append(tokens, token{typ: tokenString, val: val, pos: start})
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '.' ||
+				((runes[i] == '+' || runes[i] == '-') && (runes[i-1] == 'e' || runes[i-1] == 'E'))) {
+				i++
+			}
+			val := string(runes[start:i])
+			if durationPattern.MatchString(val) {
+				tokens = append(tokens, token{typ: tokenDuration, val: val, pos: start})
+			} else {
+				tokens = append(tokens, token{typ: tokenNumber, val: val, pos: start})
+			}
+		case unicode.IsLetter(r) || r == '_' || r == ':':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == ':') {
+				i++
+			}
+			tokens = append(tokens, token{typ: tokenIdentifier, val: string(runes[start:i]), pos: start})
+		default:
+			start := i
+			if i+1 < len(runes) {
+				two := string(runes[i : i+2])
+				if two == "!=" || two == "=~" || two == "!~" || two == "==" {
+					tokens = append(tokens, token{typ: tokenPunctuation, val: two, pos: start})
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune("(){}[],=+-*/", r) {
+				return nil, e.Errorf("unexpected character %q at position %d", r, start)
+			}
+			tokens = append(tokens, token{typ: tokenPunctuation, val: string(r), pos: start})
+			i++
+		}
+	}
+	return append(tokens, token{typ: tokenEOF, pos: len(runes)}), nil
+}
+
+func unquote(s string) (string, error) {
+	switch s[0] {
+	case '`':
+		return s[1 : len(s)-1], nil
+	case '\'':
+		// convert to a double quoted string so that strconv handles the escapes
+		inner := strings.ReplaceAll(s[1:len(s)-1], `\'`, `'`)
+		return strconv.Unquote(`"` + strings.ReplaceAll(inner, `"`, `\"`) + `"`)
+	default:
+		return strconv.Unquote(s)
+	}
+}
+
+// ParseDuration parses a prometheus duration such as `5m` or `1h30m`.
+func ParseDuration(s string) (time.Duration, error) {
+	if !durationPattern.MatchString(s) {
+		return 0, e.Errorf("invalid duration %q", s)
+	}
+	units := map[string]time.Duration{
+		"ms": time.Millisecond,
+		"s":  time.Second,
+		"m":  time.Minute,
+		"h":  time.Hour,
+		"d":  24 * time.Hour,
+		"w":  7 * 24 * time.Hour,
+		"y":  365 * 24 * time.Hour,
+	}
+	var d time.Duration
+	for _, part := range durationPartPattern.FindAllStringSubmatch(s, -1) {
+		n, err := strconv.ParseInt(part[1], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		d += time.Duration(n) * units[part[2]]
+	}
+	return d, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Parse parses a PromQL expression into its AST.
+func Parse(input string) (Expr, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.typ != tokenEOF {
+		return nil, e.Errorf("unexpected %q at position %d", t.val, t.pos)
+	}
+	return expr, nil
+}
+
+// ParseSelector parses a series selector as used by the `match[]` parameter.
+func ParseSelector(input string) (*VectorSelector, error) {
+	expr, err := Parse(input)
+	if err != nil {
+		return nil, err
+	}
+	sel, ok := expr.(*VectorSelector)
+	if !ok {
+		return nil, e.Errorf("%q is not a series selector", input)
+	}
+	return sel, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.typ != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isPunctuation(val string) bool {
+	t := p.peek()
+	return t.typ == tokenPunctuation && t.val == val
+}
+
+func (p *parser) expect(val string) error {
+	t := p.next()
+	if t.typ != tokenPunctuation || t.val != val {
+		return e.Errorf("expected %q but got %q at position %d", val, t.val, t.pos)
+	}
+	return nil
+}
+
+func (p *parser) parseExpr() (Expr, error) {
+	lhs, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.isPunctuation("+") || p.isPunctuation("-") {
+		op := p.next().val
+		rhs, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		lhs = &BinaryExpr{Op: op, LHS: lhs, RHS: rhs}
+	}
+	return lhs, nil
+}
+
+func (p *parser) parseTerm() (Expr, error) {
+	lhs, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isPunctuation("*") || p.isPunctuation("/") {
+		op := p.next().val
+		rhs, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		lhs = &BinaryExpr{Op: op, LHS: lhs, RHS: rhs}
+	}
+	return lhs, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if p.isPunctuation("-") || p.isPunctuation("+") {
+		op := p.next().val
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if op == "+" {
+			return expr, nil
+		}
+		if n, ok := expr.(*NumberLiteral); ok {
+			return &NumberLiteral{Val: -n.Val}, nil
+		}
+		return &BinaryExpr{Op: "*", LHS: &NumberLiteral{Val: -1}, RHS: expr}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	t := p.peek()
+	switch t.typ {
+	case tokenNumber:
+		p.next()
+		val, err := strconv.ParseFloat(t.val, 64)
+		if err != nil {
+			return nil, e.Errorf("invalid number %q at position %d", t.val, t.pos)
+		}
+		return &NumberLiteral{Val: val}, nil
+	case tokenPunctuation:
+		switch t.val {
+		case "(":
+			p.next()
+			expr, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return expr, nil
+		case "{":
+			return p.parseSelector("")
+		}
+	case tokenIdentifier:
+		p.next()
+		if aggregateOps[t.val] && (p.isPunctuation("(") || p.peekIdentifier("by") || p.peekIdentifier("without")) {
+			return p.parseAggregate(t.val)
+		}
+		if p.isPunctuation("(") {
+			return p.parseCall(t.val)
+		}
+		return p.parseSelector(t.val)
+	}
+	return nil, e.Errorf("unexpected %q at position %d", t.val, t.pos)
+}
+
+func (p *parser) peekIdentifier(val string) bool {
+	t := p.peek()
+	return t.typ == tokenIdentifier && t.val == val
+}
+
+func (p *parser) parseLabelList() ([]string, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	labels := []string{}
+	for !p.isPunctuation(")") {
+		t := p.next()
+		if t.typ != tokenIdentifier {
+			return nil, e.Errorf("expected label name but got %q at position %d", t.val, t.pos)
+		}
+		labels = append(labels, t.val)
+		if !p.isPunctuation(")") {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+	}
+	p.next()
+	return labels, nil
+}
+
+func (p *parser) parseAggregate(op string) (Expr, error) {
+	agg := &AggregateExpr{Op: op}
+	parseGrouping := func() error {
+		if p.peekIdentifier("by") || p.peekIdentifier("without") {
+			agg.Without = p.next().val == "without"
+			grouping, err := p.parseLabelList()
+			if err != nil {
+				return err
+			}
+			agg.Grouping = grouping
+		}
+		return nil
+	}
+
+	if err := parseGrouping(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	agg.Expr = expr
+	if agg.Grouping == nil {
+		if err := parseGrouping(); err != nil {
+			return nil, err
+		}
+	}
+	return agg, nil
+}
+
+func (p *parser) parseCall(name string) (Expr, error) {
+	if !rangeFunctions[name] && name != histogramQuantileFunction {
+		return nil, e.Errorf("unsupported function %q", name)
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	call := &Call{Func: name}
+	for !p.isPunctuation(")") {
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		call.Args = append(call.Args, arg)
+		if !p.isPunctuation(")") {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+	}
+	p.next()
+
+	if rangeFunctions[name] {
+		if len(call.Args) != 1 {
+			return nil, e.Errorf("%s expects 1 argument", name)
+		}
+		if _, ok := call.Args[0].(*MatrixSelector); !ok {
+			return nil, e.Errorf("%s expects a range vector argument", name)
+		}
+	} else if name == histogramQuantileFunction {
+		if len(call.Args) != 2 {
+			return nil, e.Errorf("%s expects 2 arguments", name)
+		}
+		if _, ok := call.Args[0].(*NumberLiteral); !ok {
+			return nil, e.Errorf("%s expects a scalar quantile", name)
+		}
+	}
+	return call, nil
+}
+
+func (p *parser) parseSelector(name string) (Expr, error) {
+	sel := &VectorSelector{Name: name}
+	if name != "" {
+		sel.Matchers = append(sel.Matchers, &Matcher{Type: MatchEqual, Name: MetricNameLabel, Value: name})
+	}
+	if p.isPunctuation("{") {
+		p.next()
+		for !p.isPunctuation("}") {
+			label := p.next()
+			if label.typ != tokenIdentifier {
+				return nil, e.Errorf("expected label name but got %q at position %d", label.val, label.pos)
+			}
+			op := p.next()
+			var matchType MatchType
+			switch op.val {
+			case "=":
+				matchType = MatchEqual
+			case "!=":
+				matchType = MatchNotEqual
+			case "=~":
+				matchType = MatchRegexp
+			case "!~":
+				matchType = MatchNotRegexp
+			default:
+				return nil, e.Errorf("expected label matcher but got %q at position %d", op.val, op.pos)
+			}
+			value := p.next()
+			if value.typ != tokenString {
+				return nil, e.Errorf("expected label value but got %q at position %d", value.val, value.pos)
+			}
+			if matchType == MatchRegexp || matchType == MatchNotRegexp {
+				if _, err := regexp.Compile(value.val); err != nil {
+					return nil, e.Wrapf(err, "invalid regular expression for label %s", label.val)
+				}
+			}
+			sel.Matchers = append(sel.Matchers, &Matcher{Type: matchType, Name: label.val, Value: value.val})
+			if !p.isPunctuation("}") {
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+		}
+		p.next()
+	}
+	if len(sel.Matchers) == 0 {
+		return nil, e.New("vector selector must contain at least one matcher")
+	}
+
+	if p.isPunctuation("[") {
+		p.next()
+		t := p.next()
+		if t.typ != tokenDuration {
+			return nil, e.Errorf("expected range duration but got %q at position %d", t.val, t.pos)
+		}
+		d, err := ParseDuration(t.val)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return &MatrixSelector{Vector: sel, Range: d}, nil
+	}
+	return sel, nil
+}
+
+func (m *Matcher) String() string {
+	return fmt.Sprintf("%s%s%q", m.Name, m.Type, m.Value)
+}
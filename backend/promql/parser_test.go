@@ -0,0 +1,87 @@
+package promql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse_Selector(t *testing.T) {
+	expr, err := Parse(`http_requests_total{job="api", method!="GET", path=~"/users/.*", code!~'5..'}`)
+	assert.NoError(t, err)
+	assert.Equal(t, &VectorSelector{
+		Name: "http_requests_total",
+		Matchers: []*Matcher{
+			{Type: MatchEqual, Name: MetricNameLabel, Value: "http_requests_total"},
+			{Type: MatchEqual, Name: "job", Value: "api"},
+			{Type: MatchNotEqual, Name: "method", Value: "GET"},
+			{Type: MatchRegexp, Name: "path", Value: "/users/.*"},
+			{Type: MatchNotRegexp, Name: "code", Value: "5.."},
+		},
+	}, expr)
+}
+
+func TestParse_Rate(t *testing.T) {
+	expr, err := Parse(`rate(http_requests_total[1h30m])`)
+	assert.NoError(t, err)
+	call := expr.(*Call)
+	assert.Equal(t, "rate", call.Func)
+	matrix := call.Args[0].(*MatrixSelector)
+	assert.Equal(t, 90*time.Minute, matrix.Range)
+	assert.Equal(t, "http_requests_total", matrix.Vector.Name)
+}
+
+func TestParse_Aggregate(t *testing.T) {
+	for _, query := range []string{
+		`sum by (job, le) (rate(request_duration_bucket[5m]))`,
+		`sum(rate(request_duration_bucket[5m])) by (job, le)`,
+	} {
+		expr, err := Parse(query)
+		assert.NoError(t, err, query)
+		agg := expr.(*AggregateExpr)
+		assert.Equal(t, "sum", agg.Op)
+		assert.Equal(t, []string{"job", "le"}, agg.Grouping)
+		assert.False(t, agg.Without)
+		assert.IsType(t, &Call{}, agg.Expr)
+	}
+}
+
+func TestParse_HistogramQuantileAndArithmetic(t *testing.T) {
+	expr, err := Parse(`histogram_quantile(0.95, sum by (le) (rate(x_bucket[5m]))) * 1000 - 1`)
+	assert.NoError(t, err)
+	sub := expr.(*BinaryExpr)
+	assert.Equal(t, "-", sub.Op)
+	assert.Equal(t, &NumberLiteral{Val: 1}, sub.RHS)
+	mul := sub.LHS.(*BinaryExpr)
+	assert.Equal(t, "*", mul.Op)
+	call := mul.LHS.(*Call)
+	assert.Equal(t, histogramQuantileFunction, call.Func)
+	assert.Equal(t, &NumberLiteral{Val: 0.95}, call.Args[0])
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, query := range []string{
+		``,
+		`{}`,
+		`rate(x)`,
+		`topk(5, x)`,
+		`x{job="a"`,
+		`x{job=~"("}`,
+		`sum by (job (x)`,
+		`x[5]`,
+		`histogram_quantile(x, y)`,
+	} {
+		_, err := Parse(query)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	d, err := ParseDuration("1d2h3m4s5ms")
+	assert.NoError(t, err)
+	assert.Equal(t, 26*time.Hour+3*time.Minute+4*time.Second+5*time.Millisecond, d)
+
+	_, err = ParseDuration("5")
+	assert.Error(t, err)
+}
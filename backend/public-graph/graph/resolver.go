@@ -19,11 +19,12 @@ import (
 	"strings"
 	"time"
 
-	"github.com/PaesslerAG/jsonpath"
 	"github.com/aws/smithy-go/ptr"
 	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/highlight-run/go-resthooks"
 	"github.com/highlight-run/highlight/backend/alerts"
+	alertsV2 "github.com/highlight-run/highlight/backend/alerts/v2"
+	destinationsV2 "github.com/highlight-run/highlight/backend/alerts/v2/destinations"
 	"github.com/highlight-run/highlight/backend/clickhouse"
 	"github.com/highlight-run/highlight/backend/email"
 	"github.com/highlight-run/highlight/backend/embeddings"
@@ -34,6 +35,7 @@ import (
 	kafka_queue "github.com/highlight-run/highlight/backend/kafka-queue"
 	kafkaqueue "github.com/highlight-run/highlight/backend/kafka-queue"
 	"github.com/highlight-run/highlight/backend/lambda"
+	journey_handlers "github.com/highlight-run/highlight/backend/lambda-functions/journeys/handlers"
 	"github.com/highlight-run/highlight/backend/model"
 	"github.com/highlight-run/highlight/backend/parser"
 	"github.com/highlight-run/highlight/backend/phonehome"
@@ -42,6 +44,7 @@ import (
 	privateModel "github.com/highlight-run/highlight/backend/private-graph/graph/model"
 	modelInputs "github.com/highlight-run/highlight/backend/public-graph/graph/model"
 	publicModel "github.com/highlight-run/highlight/backend/public-graph/graph/model"
+	"github.com/highlight-run/highlight/backend/redact"
 	"github.com/highlight-run/highlight/backend/redis"
 	"github.com/highlight-run/highlight/backend/stacktraces"
 	"github.com/highlight-run/highlight/backend/storage"
@@ -147,71 +150,6 @@ type FieldData struct {
 	Value string
 }
 
-type Request struct {
-	ID         string `json:"id"`
-	URL        string `json:"url"`
-	Method     string `json:"verb"`
-	HeadersRaw any    `json:"headers"`
-	Body       any    `json:"body"`
-}
-
-type Response struct {
-	Status     float64 `json:"status"`
-	Size       float64 `json:"size"`
-	HeadersRaw any     `json:"headers"`
-	Body       any     `json:"body"`
-}
-
-type RequestResponsePairs struct {
-	Request    Request  `json:"request"`
-	Response   Response `json:"response"`
-	URLBlocked bool     `json:"urlBlocked"`
-}
-
-type NetworkResource struct {
-	// Deprecated, use the absolute version `StartTimeAbs` instead
-	StartTime float64 `json:"startTime"`
-	// Deprecated, use the absolute version `ResponseEndAbs` instead
-	ResponseEnd float64 `json:"responseEnd"`
-
-	StartTimeAbs             float64              `json:"startTimeAbs"`
-	ResponseEndAbs           float64              `json:"responseEndAbs"`
-	ConnectStartAbs          float64              `json:"connectStartAbs"`
-	ConnectEndAbs            float64              `json:"connectEndAbs"`
-	DomainLookupStartAbs     float64              `json:"domainLookupStartAbs"`
-	DomainLookupEndAbs       float64              `json:"domainLookupEndAbs"`
-	FetchStartAbs            float64              `json:"fetchStartAbs"`
-	RedirectStartAbs         float64              `json:"redirectStartAbs"`
-	RedirectEndAbs           float64              `json:"redirectEndAbs"`
-	RequestStartAbs          float64              `json:"requestStartAbs"`
-	ResponseStartAbs         float64              `json:"responseStartAbs"`
-	SecureConnectionStartAbs float64              `json:"secureConnectionStartAbs"`
-	WorkerStartAbs           float64              `json:"workerStartAbs"`
-	DecodedBodySize          float64              `json:"decodedBodySize"`
-	TransferSize             float64              `json:"transferSize"`
-	EncodedBodySize          float64              `json:"encodedBodySize"`
-	NextHopProtocol          string               `json:"nextHopProtocol"`
-	InitiatorType            string               `json:"initiatorType"`
-	Name                     string               `json:"name"`
-	RequestResponsePairs     RequestResponsePairs `json:"requestResponsePairs"`
-}
-
-func (re *NetworkResource) Start(sessionStart time.Time) time.Time {
-	start := time.UnixMicro(int64(1000. * re.StartTimeAbs))
-	if start.Before(time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)) {
-		start = sessionStart.Add(time.Microsecond * time.Duration(1000.*re.StartTime))
-	}
-	return start
-}
-
-func (re *NetworkResource) End(sessionStart time.Time) time.Time {
-	end := time.UnixMicro(int64(1000. * re.ResponseEndAbs))
-	if end.Before(time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)) {
-		end = sessionStart.Add(time.Microsecond * time.Duration(1000.*re.ResponseEnd))
-	}
-	return end
-}
-
 const ERROR_EVENT_MAX_LENGTH = 10000
 
 const SESSION_FIELD_MAX_LENGTH = 2000
@@ -439,6 +377,14 @@ func (r *Resolver) GetOrCreateErrorGroup(ctx context.Context, errorObj *model.Er
 			return nil, err
 		}
 	}
+	if match != nil {
+		// errors matching a merged error group are added to the group it was merged into
+		mergedID, err := r.Store.GetMergedErrorGroupID(ctx, *match)
+		if err != nil {
+			return nil, e.Wrap(err, "error querying merged error group")
+		}
+		match = &mergedID
+	}
 	errorGroup := &model.ErrorGroup{}
 
 	if match == nil {
@@ -474,24 +420,18 @@ func (r *Resolver) GetOrCreateErrorGroup(ctx context.Context, errorObj *model.Er
 			return nil, e.Wrap(err, "error retrieving top matched error group")
 		}
 
-		updatedState := errorGroup.State
-
 		// Reopen resolved errors
 		// Note that ignored errors do change state
-		var shouldUpdate bool
-		if updatedState == privateModel.ErrorStateResolved {
-			updatedState = privateModel.ErrorStateOpen
-			shouldUpdate = true
+		if errorGroup.State == privateModel.ErrorStateResolved {
+			if err := r.reopenErrorGroup(ctx, errorGroup, errorObj); err != nil {
+				return nil, err
+			}
 		}
 
 		if errorGroup.ErrorTagID == nil && tagGroup {
 			errorGroup.ErrorTagID = r.tagErrorGroup(ctx, errorObj)
-			shouldUpdate = true
-		}
-		if shouldUpdate {
 			s, sCtx := util.StartSpanFromContext(ctx, "GetOrCreateErrorGroup.Update")
 			if err := r.DB.WithContext(sCtx).Model(errorGroup).Updates(&model.ErrorGroup{
-				State:      updatedState,
 				ErrorTagID: errorGroup.ErrorTagID,
 			}).Error; err != nil {
 				s.Finish(err)
@@ -508,6 +448,48 @@ func (r *Resolver) GetOrCreateErrorGroup(ctx context.Context, errorObj *model.Er
 	return errorGroup, nil
 }
 
+// reopenErrorGroup reopens a resolved error group that received a new error object.
+// The group is marked as regressed when the error comes from a release newer than the one it was resolved in.
+func (r *Resolver) reopenErrorGroup(ctx context.Context, errorGroup *model.ErrorGroup, errorObj *model.ErrorObject) error {
+	s, ctx := util.StartSpanFromContext(ctx, "GetOrCreateErrorGroup.Reopen")
+	defer s.Finish()
+
+	state := errorgroups.ReopenedState(errorGroup.ResolvedInVersion, errorObj.ServiceVersion)
+	updates := map[string]interface{}{"State": state}
+	var regressedInVersion *string
+	var regressedAt *time.Time
+	if state == privateModel.ErrorStateRegressed {
+		if errorObj.ServiceVersion != "" {
+			regressedInVersion = ptr.String(errorObj.ServiceVersion)
+		}
+		regressedAt = ptr.Time(time.Now())
+		updates["RegressedInVersion"] = regressedInVersion
+		updates["RegressedAt"] = regressedAt
+	}
+
+	// only the first error object to reopen the group records the regression
+	result := r.DB.WithContext(ctx).Model(errorGroup).Where("state = ?", privateModel.ErrorStateResolved).Updates(updates)
+	if result.Error != nil {
+		return e.Wrap(result.Error, "Error updating error group")
+	}
+	errorGroup.State = state
+	if result.RowsAffected == 0 || state != privateModel.ErrorStateRegressed {
+		return nil
+	}
+
+	errorGroup.RegressedInVersion = regressedInVersion
+	errorGroup.RegressedAt = regressedAt
+	errorGroup.Regressed = true
+	return r.Store.CreateErrorGroupActivityLog(ctx, model.ErrorGroupActivityLog{
+		ErrorGroupID: errorGroup.ID,
+		EventType:    model.ErrorGroupRegressedEvent,
+		EventData: model.JSONB{
+			"ResolvedInVersion":  errorGroup.ResolvedInVersion,
+			"RegressedInVersion": regressedInVersion,
+		},
+	})
+}
+
 func (r *Resolver) GetTopErrorGroupMatchByEmbedding(ctx context.Context, projectID int, method model.ErrorGroupingMethod, embedding model.Vector, threshold float64) (*int, error) {
 	span, ctx := util.StartSpanFromContext(ctx, "resolver.GetTopErrorGroupMatchByEmbedding", util.Tag("projectID", projectID), util.Tag("method", method))
 	defer span.Finish()
@@ -565,6 +547,26 @@ func (r *Resolver) GetTopErrorGroupMatchByEmbedding(ctx context.Context, project
 	return nil, nil
 }
 
+// GetErrorGroupMatchByFingerprint returns the error group last assigned the custom fingerprint.
+func (r *Resolver) GetErrorGroupMatchByFingerprint(ctx context.Context, projectID int, fingerprint string) (*int, error) {
+	span, ctx := util.StartSpanFromContext(ctx, "resolver.GetErrorGroupMatchByFingerprint", util.Tag("projectID", projectID))
+	defer span.Finish()
+
+	var errorGroupIDs []int
+	if err := r.DB.WithContext(ctx).Model(&model.ErrorFingerprint{}).
+		Where(&model.ErrorFingerprint{ProjectID: projectID, Type: model.Fingerprint.Custom, Value: fingerprint}).
+		Where("error_group_id IS NOT NULL").
+		Order("id DESC").
+		Limit(1).
+		Pluck("error_group_id", &errorGroupIDs).Error; err != nil {
+		return nil, e.Wrap(err, "error querying error group by custom fingerprint")
+	}
+	if len(errorGroupIDs) == 0 {
+		return nil, nil
+	}
+	return &errorGroupIDs[0], nil
+}
+
 func (r *Resolver) GetTopErrorGroupMatch(ctx context.Context, event string, projectID int, fingerprints []*model.ErrorFingerprint) (*int, error) {
 	span, ctx := util.StartSpanFromContext(ctx, "resolver.GetTopErrorGroupMatch", util.Tag("projectID", projectID), util.Tag("event", event), util.Tag("num_fingerprints", len(fingerprints)))
 	defer span.Finish()
@@ -703,6 +705,16 @@ func (r *Resolver) isWithinErrorQuota(ctx context.Context, workspace *model.Work
 	return withinBillingQuota
 }
 
+// redactErrorObject replaces the personal data found by the project's redaction rules in the error before it is grouped and stored.
+func redactErrorObject(redactor *redact.Redactor, errorObj *model.ErrorObject) {
+	errorObj.Event = redactor.String(errorObj.Event)
+	errorObj.URL = redactor.String(errorObj.URL)
+	redactor.JSONPtr(errorObj.Trace)
+	redactor.JSONPtr(errorObj.StackTrace)
+	redactor.JSONPtr(errorObj.MappedStackTrace)
+	redactor.JSONPtr(errorObj.Payload)
+}
+
 // HandleErrorAndGroup caches the result of handleErrorAndGroup under the exact match of the error body + stacktrace.
 // Improves performance of handleErrorAndGroup by first checking if the exact error object has been grouped before.
 func (r *Resolver) HandleErrorAndGroup(ctx context.Context, errorObj *model.ErrorObject, timestamps []time.Time, structuredStackTrace []*privateModel.ErrorTrace, projectID int, workspace *model.Workspace) (*model.ErrorGroup, []*model.ErrorObject, error) {
@@ -744,11 +756,40 @@ func (r *Resolver) HandleErrorAndGroup(ctx context.Context, errorObj *model.Erro
 		}
 	}
 
-	key := errorgroups.GetKey(projectID, errorObj, structuredStackTrace)
+	rules, err := r.Store.GetCompiledErrorGroupingRules(ctx, projectID)
+	if err != nil {
+		// the error is still grouped by its default fingerprint rather than dropped
+		log.WithContext(ctx).WithError(err).WithField("project_id", projectID).Error("failed to query error grouping rules")
+		rules = nil
+	}
+	grouping := errorgroups.ApplyGroupingRules(rules, errorObj, structuredStackTrace)
+	if grouping.NotInAppFrames > 0 && errorObj.MappedStackTrace != nil {
+		// persist the in-app flags set by the grouping rules
+		mappedStackTraceBytes, err := json.Marshal(structuredStackTrace)
+		if err != nil {
+			return nil, nil, e.Wrap(err, "error marshalling mapped stack trace")
+		}
+		errorObj.MappedStackTrace = ptr.String(string(mappedStackTraceBytes))
+	}
+
+	redactor, err := r.Store.GetRedactor(ctx, projectID)
+	if err != nil {
+		// a nil redactor stores the error as is rather than dropping it
+		log.WithContext(ctx).WithError(err).WithField("project_id", projectID).Error("failed to query error redaction rules")
+	}
+	redactErrorObject(redactor, errorObj)
+	if err := r.Store.RecordRedactions(ctx, redactor); err != nil {
+		log.WithContext(ctx).WithError(err).Error("failed to record error redactions")
+	}
+
+	key := errorgroups.GetKey(projectID, errorObj, grouping.StackTrace)
+	if grouping.Fingerprint != nil {
+		key = fmt.Sprintf("%s-%s", key, *grouping.Fingerprint)
+	}
 	var cacheMiss bool
 	eg, err := redis.CachedEval(ctx, r.Redis, key, 10*time.Second, time.Hour, func() (*model.ErrorGroup, error) {
 		cacheMiss = true
-		return r.handleErrorAndGroup(ctx, project, errorObj, structuredStackTrace, projectID, workspace)
+		return r.handleErrorAndGroup(ctx, project, errorObj, grouping.StackTrace, grouping.Fingerprint, projectID, workspace)
 	})
 	if eg == nil || err != nil {
 		log.WithContext(ctx).WithError(err).WithField("project_id", projectID).Error("failed to group error")
@@ -789,40 +830,107 @@ func (r *Resolver) HandleErrorAndGroup(ctx context.Context, errorObj *model.Erro
 		return nil, nil, err
 	}
 
+	if err := r.enqueueErrorGroupCodeOwner(ctx, eg, newObjects); err != nil {
+		log.WithContext(ctx).WithError(err).WithField("error_group_id", eg.ID).Error("failed to enqueue error group code owner assignment")
+	}
+
+	if err := r.autoAssignErrorGroup(ctx, eg, errorObj); err != nil {
+		log.WithContext(ctx).WithError(err).WithField("error_group_id", eg.ID).Error("failed to auto-assign error group")
+	}
+
+	if errorObj.ServiceVersion != "" {
+		if _, err := r.Store.UpsertRelease(ctx, projectID, errorObj.ServiceVersion, time.Now()); err != nil {
+			log.WithContext(ctx).Error(e.Wrap(err, "failed to create release"))
+		}
+	}
+
+	if eg.Regressed && len(newObjects) > 0 {
+		if err := alertsV2.SendErrorRegressionAlerts(ctx, r.DB, r.MailClient, r.LambdaClient, eg, newObjects[len(newObjects)-1]); err != nil {
+			log.WithContext(ctx).WithError(err).WithField("error_group_id", eg.ID).Error("failed to send error regression alerts")
+		}
+		if err := alertsV2.SendErrorGroupAssignmentNotifications(ctx, r.DB, r.MailClient, r.LambdaClient, destinationsV2.NotificationTypeErrorGroupRegressed, eg, nil, nil); err != nil {
+			log.WithContext(ctx).WithError(err).WithField("error_group_id", eg.ID).Error("failed to notify error group assignee of regression")
+		}
+	}
+
 	return eg, newObjects, err
 }
 
+// autoAssignErrorGroup assigns an unassigned error group with the first assignment rule matching the error,
+// and notifies the assignee. Error groups that were assigned before are left to the admins triaging them.
+func (r *Resolver) autoAssignErrorGroup(ctx context.Context, errorGroup *model.ErrorGroup, errorObj *model.ErrorObject) error {
+	if errorGroup.AssigneeAdminID != nil || errorGroup.AssigneeTeam != nil {
+		return nil
+	}
+
+	rules, err := r.Store.GetErrorGroupAssignmentRules(ctx, errorGroup.ProjectID)
+	if err != nil {
+		return err
+	}
+	rule := errorgroups.MatchAssignmentRule(rules, errorObj)
+	if rule == nil {
+		return nil
+	}
+
+	if hasHistory, err := r.Store.HasAssignmentHistory(ctx, errorGroup.ID); err != nil || hasHistory {
+		return err
+	}
+
+	assigned, err := r.Store.AssignErrorGroup(ctx, nil, errorGroup, rule.AssigneeAdminID, rule.AssigneeTeam, rule)
+	if err != nil || !assigned {
+		return err
+	}
+	return alertsV2.SendErrorGroupAssignmentNotifications(ctx, r.DB, r.MailClient, r.LambdaClient, destinationsV2.NotificationTypeErrorGroupAssigned, errorGroup, nil, rule)
+}
+
+// enqueueErrorGroupCodeOwner submits a worker task assigning the code owner of the error group.
+// The owner of a group is looked up at most once a day until one is found.
+func (r *Resolver) enqueueErrorGroupCodeOwner(ctx context.Context, errorGroup *model.ErrorGroup, errorObjects []*model.ErrorObject) error {
+	if errorGroup.CodeOwner != nil || len(errorObjects) == 0 {
+		return nil
+	}
+	errorObj := errorObjects[len(errorObjects)-1]
+	if errorObj.MappedStackTrace == nil || errorObj.ServiceName == "" {
+		return nil
+	}
+
+	if lookup, err := r.Redis.SetErrorGroupCodeOwnerLookup(ctx, errorGroup.ID); err != nil || !lookup {
+		return err
+	}
+
+	return r.ProducerQueue.Submit(ctx, strconv.Itoa(errorGroup.ID), &kafka_queue.Message{Type: kafka_queue.AssignErrorGroupCodeOwner, AssignErrorGroupCodeOwner: &kafka_queue.AssignErrorGroupCodeOwnerArgs{ErrorGroupID: errorGroup.ID, ErrorObjectID: errorObj.ID}})
+}
+
 // Matches the ErrorObject with an existing ErrorGroup, or creates a new one if the group does not exist
-func (r *Resolver) handleErrorAndGroup(ctx context.Context, project *model.Project, errorObj *model.ErrorObject, structuredStackTrace []*privateModel.ErrorTrace, projectID int, workspace *model.Workspace) (*model.ErrorGroup, error) {
+// When a grouping rule sets a custom fingerprint, the error is grouped only by that fingerprint.
+func (r *Resolver) handleErrorAndGroup(ctx context.Context, project *model.Project, errorObj *model.ErrorObject, structuredStackTrace []*privateModel.ErrorTrace, customFingerprint *string, projectID int, workspace *model.Workspace) (*model.ErrorGroup, error) {
 	span, ctx := util.StartSpanFromContext(ctx, "handleErrorAndGroup", util.Tag("projectID", projectID))
 	defer span.Finish()
 
-	var fingerprints []*model.ErrorFingerprint
-	fingerprints = append(fingerprints, errorgroups.GetFingerprints(projectID, structuredStackTrace)...)
-
-	// Try unmarshalling the Event to JSON.
-	// If this works, create an error fingerprint for each of the project's JSON paths.
-	jsonStrings := []string{}
-	if err := json.Unmarshal([]byte(errorObj.Event), &jsonStrings); err == nil && len(jsonStrings) == 1 {
-		errorAsJson := interface{}(nil)
-		if err := json.Unmarshal([]byte(jsonStrings[0]), &errorAsJson); err == nil {
-			for _, path := range project.ErrorJsonPaths {
-				value, err := jsonpath.Get(path, errorAsJson)
-				if err == nil {
-					marshalled, err := json.Marshal(value)
-					if err == nil {
-						jsonResult := model.ErrorFingerprint{
-							ProjectID: projectID,
-							Type:      model.Fingerprint.JsonResult,
-							Value:     path + "=" + string(marshalled),
-						}
-						fingerprints = append(fingerprints, &jsonResult)
-					}
-				}
-			}
+	if customFingerprint != nil {
+		fingerprints := []*model.ErrorFingerprint{{
+			ProjectID: projectID,
+			Type:      model.Fingerprint.Custom,
+			Value:     *customFingerprint,
+		}}
+		errorObj.ErrorGroupingMethod = model.ErrorGroupingMethodClassic
+		errorGroup, err := r.GetOrCreateErrorGroup(ctx, errorObj, func() (*int, error) {
+			return r.GetErrorGroupMatchByFingerprint(ctx, projectID, *customFingerprint)
+		}, nil, false)
+		if err != nil {
+			return nil, e.Wrap(err, "Error getting or creating error group")
 		}
+		if err := r.replaceErrorGroupFingerprints(ctx, errorGroup, fingerprints); err != nil {
+			return nil, err
+		}
+		return errorGroup, nil
 	}
 
+	var fingerprints []*model.ErrorFingerprint
+	fingerprints = append(fingerprints, errorgroups.GetFingerprints(projectID, structuredStackTrace)...)
+	// If the Event is JSON, create an error fingerprint for each of the project's JSON paths.
+	fingerprints = append(fingerprints, errorgroups.GetJSONFingerprints(projectID, errorObj.Event, project.ErrorJsonPaths)...)
+
 	var err error
 	var errorGroup *model.ErrorGroup
 	var settings *model.AllWorkspaceSettings
@@ -882,6 +990,14 @@ func (r *Resolver) handleErrorAndGroup(ctx context.Context, project *model.Proje
 		}
 	}
 
+	if err := r.replaceErrorGroupFingerprints(ctx, errorGroup, fingerprints); err != nil {
+		return nil, err
+	}
+
+	return errorGroup, nil
+}
+
+func (r *Resolver) replaceErrorGroupFingerprints(ctx context.Context, errorGroup *model.ErrorGroup, fingerprints []*model.ErrorFingerprint) error {
 	if err := r.DB.Transaction(func(tx *gorm.DB) error {
 		for _, f := range fingerprints {
 			f.ErrorGroupId = errorGroup.ID
@@ -914,10 +1030,9 @@ func (r *Resolver) handleErrorAndGroup(ctx context.Context, project *model.Proje
 
 		return nil
 	}); err != nil {
-		return nil, e.Wrap(err, "error replacing error group fingerprints")
+		return e.Wrap(err, "error replacing error group fingerprints")
 	}
-
-	return errorGroup, nil
+	return nil
 }
 
 func GetLocationFromIP(ctx context.Context, ip string) (location *Location, err error) {
@@ -975,6 +1090,18 @@ func GetDeviceDetails(userAgentString string) (deviceDetails DeviceDetails) {
 	return deviceDetails
 }
 
+// getDeviceClass returns whether a session with the operating system ran on a mobile or desktop device.
+func getDeviceClass(osName string) string {
+	switch osName {
+	case "":
+		return "unknown"
+	case "Android", "iOS", "iPadOS", "Windows Phone", "BlackBerry":
+		return "mobile"
+	default:
+		return "desktop"
+	}
+}
+
 func (r *Resolver) IndexSessionClickhouse(ctx context.Context, session *model.Session) error {
 	sessionProperties := map[string]string{
 		"os_name":         session.OSName,
@@ -1291,6 +1418,12 @@ func (r *Resolver) InitializeSessionImpl(ctx context.Context, input *kafka_queue
 		}
 	}
 
+	if session.AppVersion != nil && *session.AppVersion != "" {
+		if _, err := r.Store.UpsertRelease(ctx, project.ID, *session.AppVersion, session.CreatedAt); err != nil {
+			log.WithContext(ctx).Error(e.Wrap(err, "failed to create release"))
+		}
+	}
+
 	return session, nil
 }
 
@@ -1970,6 +2103,17 @@ func (r *Resolver) PushMetricsImpl(ctx context.Context, projectVerboseID *string
 			attributes["group"] = *m.Group
 		}
 		attributes[highlight.EnvironmentAttribute] = session.Environment
+		if m.Category != nil && *m.Category == clickhouse.WebVitalCategory {
+			// the group of a web vital is the url of the page it was measured on
+			urlPattern, _ := journey_handlers.NormalizeURL(ptr.ToString(m.Group))
+			if urlPattern == "" {
+				urlPattern = "/"
+			}
+			attributes[clickhouse.WebVitalURLPatternAttribute] = urlPattern
+			attributes[clickhouse.WebVitalDeviceClassAttribute] = getDeviceClass(session.OSName)
+			attributes[clickhouse.WebVitalBrowserAttribute] = session.BrowserName
+			attributes[clickhouse.WebVitalReleaseAttribute] = serviceVersion
+		}
 
 		timestamp := ClampTime(m.Timestamp, curTime)
 		metricRows = append(metricRows, &clickhouse.MetricSumRow{
@@ -2711,6 +2855,12 @@ func (r *Resolver) ProcessPayload(ctx context.Context, sessionSecureID string, e
 			hasFullSnapshot := false
 			hostUrl := parse.GetHostUrlFromEvents(parsedEvents.Events)
 
+			redactor, err := r.Store.GetRedactor(ctx, projectID)
+			if err != nil {
+				// a nil redactor stores the events as is rather than dropping the payload
+				log.WithContext(ctx).WithError(err).WithField("project_id", projectID).Error("failed to query session redaction rules")
+			}
+
 			eventLoopSpan, sCtx := util.StartSpanFromContext(ctx, "public-graph.pushPayload.eventLoop", util.Tag("project_id", projectID))
 			for _, event := range parsedEvents.Events {
 				if event.Type == parse.FullSnapshot || event.Type == parse.IncrementalSnapshot {
@@ -2726,6 +2876,8 @@ func (r *Resolver) ProcessPayload(ctx context.Context, sessionSecureID string, e
 						log.WithContext(sCtx).Error(e.Wrap(err, "Error escaping snapshot javascript"))
 					}
 
+					snapshot.Redact(redactor)
+
 					// Replace any static resources with our own, hosted in S3
 					if settings != nil && settings.ReplaceAssets {
 						project, err := r.Store.GetProject(sCtx, projectID)
@@ -2772,6 +2924,10 @@ func (r *Resolver) ProcessPayload(ctx context.Context, sessionSecureID string, e
 			}
 			eventLoopSpan.Finish()
 
+			if err := r.Store.RecordRedactions(ctx, redactor); err != nil {
+				log.WithContext(ctx).WithError(err).Error("failed to record snapshot redactions")
+			}
+
 			remarshalSpan, _ := util.StartSpanFromContext(ctx, "public-graph.pushPayload.parseEvents.remarshalEvents",
 				util.Tag("project_id", projectID))
 			// Re-format as a string to write to the db.
@@ -2827,7 +2983,7 @@ func (r *Resolver) ProcessPayload(ctx context.Context, sessionSecureID string, e
 
 		settings, err := r.Store.GetAllWorkspaceSettingsByProject(ctx, projectID)
 		if err == nil && settings.EnableNetworkTraces {
-			resourcesParsed := make(map[string][]NetworkResource)
+			resourcesParsed := make(map[string][]model.NetworkResource)
 			if err := json.Unmarshal([]byte(resources), &resourcesParsed); err != nil {
 				return e.Wrap(err, "failed to unmarshal network resources")
 			}
@@ -3304,7 +3460,7 @@ func (r *Resolver) SendSessionInitAlert(ctx context.Context, workspace *model.Wo
 	return nil
 }
 
-func (r *Resolver) submitFrontendNetworkMetric(ctx context.Context, sessionObj *model.Session, resources []NetworkResource) error {
+func (r *Resolver) submitFrontendNetworkMetric(ctx context.Context, sessionObj *model.Session, resources []model.NetworkResource) error {
 	for _, re := range resources {
 		requestHeaders, _ := re.RequestResponsePairs.Request.HeadersRaw.(map[string]interface{})
 
@@ -102,6 +102,7 @@ func TestMain(m *testing.M) {
 		StorageClient:        &storage.FilesystemClient{},
 		Store:                store.NewStore(db, redisClient, integrations.NewIntegrationsClient(db), &storage.FilesystemClient{}, &kafka_queue.MockMessageQueue{}, nil),
 		EmbeddingsClient:     &mockEmbeddingsClient{},
+		ProducerQueue:        &kafka_queue.MockMessageQueue{},
 		DataSyncQueue:        &kafka_queue.MockMessageQueue{},
 		TracesQueue:          &kafka_queue.MockMessageQueue{},
 		MetricSumQueue:       &kafka_queue.MockMessageQueue{},
@@ -504,6 +505,51 @@ func TestUpdatingErrorState(t *testing.T) {
 	})
 }
 
+func TestSplitErrorGroupIngest(t *testing.T) {
+	ctx := context.TODO()
+
+	stacktrace := `[{"fileName":"/app/handlers/checkout.go","lineNumber":42,"functionName":"Checkout","columnNumber":null,"error":"checkout failed","sourceMappingErrorMetadata":null,"lineContent":null,"linesBefore":null,"linesAfter":null},{"fileName":"/app/server.go","lineNumber":118,"functionName":"ServeHTTP","columnNumber":null,"error":"checkout failed","sourceMappingErrorMetadata":null,"lineContent":null,"linesBefore":null,"linesAfter":null}]`
+
+	var structuredStackTrace []*privateModel.ErrorTrace
+	err := json.Unmarshal([]byte(stacktrace), &structuredStackTrace)
+	if err != nil {
+		t.Fatal("failed to generate structured stacktrace")
+	}
+
+	util.RunTestWithDBWipe(t, resolver.DB, func(t *testing.T) {
+		project := model.Project{}
+		resolver.DB.Create(&project)
+		admin := model.Admin{}
+		resolver.DB.Create(&admin)
+
+		ingest := func(event string) (*model.ErrorGroup, []*model.ErrorObject) {
+			errorObject := model.ErrorObject{
+				Event:            event,
+				ProjectID:        project.ID,
+				StackTrace:       &stacktrace,
+				MappedStackTrace: &stacktrace,
+			}
+			errorGroup, errorObjects, err := resolver.HandleErrorAndGroup(ctx, &errorObject, []time.Time{errorObject.Timestamp}, structuredStackTrace, project.ID, nil)
+			assert.NoError(t, err)
+			return errorGroup, errorObjects
+		}
+
+		source, _ := ingest("card declined")
+		errorGroup, errorObjects := ingest("cart expired")
+		assert.Equal(t, source.ID, errorGroup.ID)
+
+		split, err := resolver.Store.SplitErrorGroup(ctx, admin, source, []int{errorObjects[0].ID})
+		assert.NoError(t, err)
+
+		// errors matching the split error objects are added to the new group
+		errorGroup, _ = ingest("cart expired")
+		assert.Equal(t, split.ID, errorGroup.ID)
+
+		errorGroup, _ = ingest("card declined")
+		assert.Equal(t, source.ID, errorGroup.ID)
+	})
+}
+
 func TestResolver_isExcludedError(t *testing.T) {
 	assert.False(t, resolver.isExcludedError(context.Background(), 1, []string{}, ""))
 	assert.True(t, resolver.isExcludedError(context.Background(), 2, []string{}, "[{}]"))
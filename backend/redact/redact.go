@@ -0,0 +1,279 @@
+package redact
+
+import (
+	"encoding/json"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/smithy-go/ptr"
+	"github.com/highlight-run/highlight/backend/model"
+	privateModel "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	e "github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// Replacement is the text that redacted values are replaced with.
+const Replacement = "[REDACTED]"
+
+type detector struct {
+	pattern *regexp.Regexp
+	// valid filters out matches that are not what the detector looks for, ie. digit runs that fail a checksum.
+	valid func(match string) bool
+}
+
+var detectors = map[privateModel.RedactionDetector]detector{
+	privateModel.RedactionDetectorEmail: {
+		pattern: regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9\-]+(?:\.[a-zA-Z0-9\-]+)*\.[a-zA-Z]{2,}`),
+	},
+	privateModel.RedactionDetectorCreditCard: {
+		pattern: regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`),
+		valid:   isLuhnValid,
+	},
+	privateModel.RedactionDetectorJwt: {
+		pattern: regexp.MustCompile(`\beyJ[a-zA-Z0-9_\-]+\.eyJ[a-zA-Z0-9_\-]+\.[a-zA-Z0-9_\-]*`),
+	},
+	privateModel.RedactionDetectorIban: {
+		pattern: regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]){11,30}\b`),
+		valid:   isIBANValid,
+	},
+	// phone numbers need a country code or separators so that ids and timestamps are not redacted
+	privateModel.RedactionDetectorPhoneNumber: {
+		pattern: regexp.MustCompile(`\+\d{1,3}[ .\-]?\(?\d{1,4}\)?(?:[ .\-]?\d{2,4}){2,4}\b|(?:\(\d{3}\) ?|\b\d{3}[.\-])\d{3}[ .\-]\d{4}\b`),
+	},
+}
+
+// customPatterns caches the compiled patterns of regex rules, which are evaluated for every ingested payload.
+var customPatterns sync.Map
+
+func compileCustomPattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := customPatterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	customPatterns.Store(pattern, re)
+	return re, nil
+}
+
+func isLuhnValid(match string) bool {
+	var sum, digits int
+	for i := len(match) - 1; i >= 0; i-- {
+		c := match[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if digits%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+	}
+	return digits >= 13 && digits <= 19 && sum%10 == 0
+}
+
+func isIBANValid(match string) bool {
+	iban := strings.ReplaceAll(match, " ", "")
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+	// move the country code and check digits to the end and convert letters to numbers, A = 10 ... Z = 35
+	var numeric strings.Builder
+	for _, c := range iban[4:] + iban[:4] {
+		if c >= 'A' && c <= 'Z' {
+			numeric.WriteString(strconv.Itoa(int(c - 'A' + 10)))
+		} else {
+			numeric.WriteRune(c)
+		}
+	}
+	n, ok := new(big.Int).SetString(numeric.String(), 10)
+	return ok && new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+// ValidateRule checks that the rule uses a known detector, and that regex rules have a valid pattern.
+func ValidateRule(rule *model.RedactionRule) error {
+	if rule.Detector == privateModel.RedactionDetectorRegex {
+		if ptr.ToString(rule.Pattern) == "" {
+			return e.New("pattern is required")
+		}
+		if _, err := regexp.Compile(*rule.Pattern); err != nil {
+			return e.Wrap(err, "invalid pattern")
+		}
+		return nil
+	}
+	if _, ok := detectors[rule.Detector]; !ok {
+		return e.Errorf("unknown redaction detector %q", rule.Detector)
+	}
+	return nil
+}
+
+type redactionRule struct {
+	id int
+	detector
+}
+
+// Redactor replaces the values matched by a project's redaction rules and counts the redactions of each rule.
+// A nil Redactor leaves values unchanged.
+type Redactor struct {
+	rules []redactionRule
+
+	mu     sync.Mutex
+	counts map[int]int64
+}
+
+// New returns a Redactor applying the enabled rules, or nil if there are none.
+func New(rules []*model.RedactionRule) (*Redactor, error) {
+	var redactor Redactor
+	for _, rule := range rules {
+		if rule.Disabled {
+			continue
+		}
+		if rule.Detector == privateModel.RedactionDetectorRegex {
+			pattern, err := compileCustomPattern(ptr.ToString(rule.Pattern))
+			if err != nil {
+				return nil, e.Wrapf(err, "invalid pattern for redaction rule %d", rule.ID)
+			}
+			redactor.rules = append(redactor.rules, redactionRule{id: rule.ID, detector: detector{pattern: pattern}})
+		} else if d, ok := detectors[rule.Detector]; ok {
+			redactor.rules = append(redactor.rules, redactionRule{id: rule.ID, detector: d})
+		}
+	}
+	if len(redactor.rules) == 0 {
+		return nil, nil
+	}
+	redactor.counts = make(map[int]int64)
+	return &redactor, nil
+}
+
+// String returns the value with the matches of every rule replaced.
+func (r *Redactor) String(value string) string {
+	if r == nil || value == "" {
+		return value
+	}
+	for _, rule := range r.rules {
+		var count int64
+		value = rule.pattern.ReplaceAllStringFunc(value, func(match string) string {
+			if rule.valid != nil && !rule.valid(match) {
+				return match
+			}
+			count++
+			return Replacement
+		})
+		if count > 0 {
+			r.mu.Lock()
+			r.counts[rule.id] += count
+			r.mu.Unlock()
+		}
+	}
+	return value
+}
+
+func (r *Redactor) matches(value string) bool {
+	for _, rule := range r.rules {
+		for _, match := range rule.pattern.FindAllString(value, -1) {
+			if rule.valid == nil || rule.valid(match) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// StringPtr redacts the value in place if it is set.
+func (r *Redactor) StringPtr(value *string) {
+	if r == nil || value == nil {
+		return
+	}
+	*value = r.String(*value)
+}
+
+// Strings redacts the values of the map in place.
+func (r *Redactor) Strings(values map[string]string) {
+	if r == nil {
+		return
+	}
+	for k, v := range values {
+		values[k] = r.String(v)
+	}
+}
+
+// JSON redacts the string values of a serialized JSON object or array, keeping it valid JSON.
+// Other values are redacted as a string.
+func (r *Redactor) JSON(value string) string {
+	if r == nil {
+		return value
+	}
+	// keep the value as it was serialized unless something needs to be redacted
+	if !r.matches(value) {
+		return value
+	}
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+		return r.String(value)
+	}
+	switch parsed.(type) {
+	case map[string]interface{}, []interface{}:
+	default:
+		return r.String(value)
+	}
+	b, err := json.Marshal(r.value(parsed, nil))
+	if err != nil {
+		return r.String(value)
+	}
+	return string(b)
+}
+
+// JSONPtr redacts the serialized JSON value in place if it is set.
+func (r *Redactor) JSONPtr(value *string) {
+	if r == nil || value == nil {
+		return
+	}
+	*value = r.JSON(*value)
+}
+
+// Object redacts the string values of the object and of its nested objects and arrays in place.
+// Values of skipKeys are left unchanged at every level.
+func (r *Redactor) Object(object map[string]interface{}, skipKeys ...string) {
+	if r == nil {
+		return
+	}
+	for k, v := range object {
+		if !lo.Contains(skipKeys, k) {
+			object[k] = r.value(v, skipKeys)
+		}
+	}
+}
+
+func (r *Redactor) value(v interface{}, skipKeys []string) interface{} {
+	switch typed := v.(type) {
+	case string:
+		return r.String(typed)
+	case map[string]interface{}:
+		r.Object(typed, skipKeys...)
+	case []interface{}:
+		for i, item := range typed {
+			typed[i] = r.value(item, skipKeys)
+		}
+	}
+	return v
+}
+
+// TakeCounts returns the number of values redacted by each rule, by rule id, since the last call.
+func (r *Redactor) TakeCounts() map[int]int64 {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts := r.counts
+	r.counts = make(map[int]int64)
+	return counts
+}
@@ -25,6 +25,7 @@ import (
 
 const CacheKeyHubspotCompanies = "hubspot-companies"
 const CacheKeySessionsToProcess = "sessions-to-process"
+const CacheKeyRedactionCounts = "redaction-counts"
 
 type Client struct {
 	Client  redis.Cmdable
@@ -66,6 +67,10 @@ func GithubRateLimitKey(gitHubRepo string) string {
 	return fmt.Sprintf("github-rate-limit-exceeded-%s", gitHubRepo)
 }
 
+func ErrorGroupCodeOwnerKey(errorGroupID int) string {
+	return fmt.Sprintf("error-group-code-owner-%d", errorGroupID)
+}
+
 func GitHubFileErrorKey(gitHubRepo string, version string, fileName string) string {
 	return fmt.Sprintf("github-file-error-%s-%s-%s", gitHubRepo, version, fileName)
 }
@@ -689,6 +694,57 @@ func (r *Client) GetGitHubFileError(ctx context.Context, repo string, version st
 	return r.getFlag(ctx, GitHubFileErrorKey(repo, version, fileName))
 }
 
+// SetErrorGroupCodeOwnerLookup marks the code owner of the error group as looked up. It returns false when
+// the owner was already looked up recently, so groups without an owner are only looked up again after a day.
+func (r *Client) SetErrorGroupCodeOwnerLookup(ctx context.Context, errorGroupID int) (bool, error) {
+	ok, err := r.Client.SetNX(ctx, ErrorGroupCodeOwnerKey(errorGroupID), true, 24*time.Hour).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "error setting error group code owner lookup flag")
+	}
+	return ok, nil
+}
+
+// IncrementRedactionCounts adds redactions made during ingest to the counts of their rules,
+// which are written to the database by FlushRedactionCounts.
+func (r *Client) IncrementRedactionCounts(ctx context.Context, counts map[int]int64) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	_, err := r.Client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for ruleID, count := range counts {
+			pipe.HIncrBy(ctx, CacheKeyRedactionCounts, strconv.Itoa(ruleID), count)
+		}
+		return nil
+	})
+	return err
+}
+
+// TakeRedactionCounts returns the redaction counts by rule since the last call and resets them.
+func (r *Client) TakeRedactionCounts(ctx context.Context) (map[int]int64, error) {
+	var values *redis.MapStringStringCmd
+	if _, err := r.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		values = pipe.HGetAll(ctx, CacheKeyRedactionCounts)
+		pipe.Del(ctx, CacheKeyRedactionCounts)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int]int64)
+	for field, value := range values.Val() {
+		ruleID, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid redaction rule id")
+		}
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid redaction count")
+		}
+		counts[ruleID] = count
+	}
+	return counts, nil
+}
+
 func (r *Client) AcquireLock(_ context.Context, key string, timeout time.Duration) (*redsync.Mutex, error) {
 	mutex := r.Redsync.NewMutex(
 		key,
@@ -0,0 +1,513 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/highlight-run/highlight/backend/model"
+	e "github.com/pkg/errors"
+	"github.com/samber/lo"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	contentType     = "application/scim+json"
+	defaultCount    = 100
+	maxCount        = 1000
+	maxRequestBytes = 1 << 20
+)
+
+type contextKey string
+
+const workspaceIDKey contextKey = "scimWorkspaceID"
+
+// Store persists the users and groups provisioned into a workspace.
+// Lookups of missing resources return gorm.ErrRecordNotFound.
+type Store interface {
+	GetSCIMTokenWorkspaceID(ctx context.Context, token string) (int, error)
+	GetSCIMUsers(ctx context.Context, workspaceID int) ([]*model.SCIMUser, error)
+	GetSCIMUser(ctx context.Context, workspaceID int, id int) (*model.SCIMUser, error)
+	CreateSCIMUser(ctx context.Context, user *model.SCIMUser) error
+	UpdateSCIMUser(ctx context.Context, user *model.SCIMUser) error
+	DeleteSCIMUser(ctx context.Context, user *model.SCIMUser) error
+	GetSCIMGroups(ctx context.Context, workspaceID int) ([]*model.SCIMGroup, error)
+	GetSCIMGroup(ctx context.Context, workspaceID int, id int) (*model.SCIMGroup, error)
+	CreateSCIMGroup(ctx context.Context, group *model.SCIMGroup) error
+	UpdateSCIMGroup(ctx context.Context, group *model.SCIMGroup) error
+	DeleteSCIMGroup(ctx context.Context, group *model.SCIMGroup) error
+}
+
+// API implements a SCIM 2.0 service provider for workspace users and groups:
+// https://datatracker.ietf.org/doc/html/rfc7644
+// Users are workspace admins, and groups map to their workspace role and project access.
+type API struct {
+	store   Store
+	baseURL string
+}
+
+func NewAPI(store Store, baseURL string) *API {
+	return &API{store: store, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+func (a *API) Listen(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(a.authenticate)
+		r.Get("/ServiceProviderConfig", a.handleServiceProviderConfig)
+		r.Get("/ResourceTypes", a.handleResourceTypes)
+		r.Get("/Schemas", a.handleSchemas)
+		r.Route("/Users", func(r chi.Router) {
+			r.Get("/", a.handleListUsers)
+			r.Post("/", a.handleCreateUser)
+			r.Get("/{id}", a.handleGetUser)
+			r.Put("/{id}", a.handleReplaceUser)
+			r.Patch("/{id}", a.handlePatchUser)
+			r.Delete("/{id}", a.handleDeleteUser)
+		})
+		r.Route("/Groups", func(r chi.Router) {
+			r.Get("/", a.handleListGroups)
+			r.Post("/", a.handleCreateGroup)
+			r.Get("/{id}", a.handleGetGroup)
+			r.Put("/{id}", a.handleReplaceGroup)
+			r.Patch("/{id}", a.handlePatchGroup)
+			r.Delete("/{id}", a.handleDeleteGroup)
+		})
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var scimError *Error
+	if !e.As(err, &scimError) {
+		if e.Is(err, gorm.ErrRecordNotFound) {
+			scimError = newError(http.StatusNotFound, "", "resource not found")
+		} else {
+			log.WithContext(r.Context()).WithError(err).WithField("path", r.URL.Path).Error("scim request failed")
+			scimError = newError(http.StatusInternalServerError, "", "internal error")
+		}
+	}
+	body := map[string]any{
+		"schemas": []string{errorSchema},
+		"status":  strconv.Itoa(scimError.Status),
+		"detail":  scimError.Detail,
+	}
+	if scimError.SCIMType != "" {
+		body["scimType"] = scimError.SCIMType
+	}
+	writeJSON(w, scimError.Status, body)
+}
+
+func (a *API) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || strings.TrimSpace(token) == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="scim"`)
+			writeError(w, r, newError(http.StatusUnauthorized, "", "a SCIM bearer token is required"))
+			return
+		}
+		workspaceID, err := a.store.GetSCIMTokenWorkspaceID(r.Context(), strings.TrimSpace(token))
+		if err != nil {
+			if !e.Is(err, gorm.ErrRecordNotFound) {
+				log.WithContext(r.Context()).WithError(err).Error("failed to authenticate scim token")
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="scim"`)
+			writeError(w, r, newError(http.StatusUnauthorized, "", "invalid SCIM bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), workspaceIDKey, workspaceID)))
+	})
+}
+
+func getWorkspaceID(r *http.Request) int {
+	workspaceID, _ := r.Context().Value(workspaceIDKey).(int)
+	return workspaceID
+}
+
+func getID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return 0, newError(http.StatusNotFound, "", "resource not found")
+	}
+	return id, nil
+}
+
+func decodeJSON(r *http.Request, body any) error {
+	if err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxRequestBytes)).Decode(body); err != nil {
+		return newError(http.StatusBadRequest, "invalidSyntax", "invalid JSON body")
+	}
+	return nil
+}
+
+func splitAttributes(value string) []string {
+	return lo.Compact(lo.Map(strings.Split(value, ","), func(attribute string, _ int) string {
+		return strings.TrimSpace(attribute)
+	}))
+}
+
+// writeResource writes the resource with the attributes selected by the request.
+func writeResource(w http.ResponseWriter, r *http.Request, status int, resource map[string]any) {
+	query := r.URL.Query()
+	writeJSON(w, status, selectAttributes(resource, splitAttributes(query.Get("attributes")), splitAttributes(query.Get("excludedAttributes"))))
+}
+
+// writeList filters and paginates the resources as described in https://datatracker.ietf.org/doc/html/rfc7644#section-3.4.2
+func writeList(w http.ResponseWriter, r *http.Request, resources []map[string]any) {
+	query := r.URL.Query()
+	if filterExpression := query.Get("filter"); filterExpression != "" {
+		filter, err := ParseFilter(filterExpression)
+		if err != nil {
+			writeError(w, r, newError(http.StatusBadRequest, "invalidFilter", err.Error()))
+			return
+		}
+		resources = lo.Filter(resources, func(resource map[string]any, _ int) bool {
+			return filter.Match(resource)
+		})
+	}
+
+	startIndex, count := 1, defaultCount
+	var err error
+	if value := query.Get("startIndex"); value != "" {
+		if startIndex, err = strconv.Atoi(value); err != nil {
+			writeError(w, r, invalidValue("startIndex must be an integer"))
+			return
+		}
+	}
+	if value := query.Get("count"); value != "" {
+		if count, err = strconv.Atoi(value); err != nil {
+			writeError(w, r, invalidValue("count must be an integer"))
+			return
+		}
+	}
+	startIndex = max(startIndex, 1)
+	count = min(max(count, 0), maxCount)
+
+	page := lo.Slice(resources, startIndex-1, startIndex-1+count)
+	attributes, excludedAttributes := splitAttributes(query.Get("attributes")), splitAttributes(query.Get("excludedAttributes"))
+	writeJSON(w, http.StatusOK, map[string]any{
+		"schemas":      []string{listResponseSchema},
+		"totalResults": len(resources),
+		"startIndex":   startIndex,
+		"itemsPerPage": len(page),
+		"Resources": lo.Map(page, func(resource map[string]any, _ int) map[string]any {
+			return selectAttributes(resource, attributes, excludedAttributes)
+		}),
+	})
+}
+
+// checkUserNameAvailable enforces that user names are unique within a workspace.
+func (a *API) checkUserNameAvailable(ctx context.Context, user *model.SCIMUser) error {
+	users, err := a.store.GetSCIMUsers(ctx, user.WorkspaceID)
+	if err != nil {
+		return err
+	}
+	if lo.ContainsBy(users, func(other *model.SCIMUser) bool {
+		return other.ID != user.ID && strings.EqualFold(other.UserName, user.UserName)
+	}) {
+		return newError(http.StatusConflict, "uniqueness", "userName "+user.UserName+" is already in use")
+	}
+	return nil
+}
+
+func (a *API) handleListUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := a.store.GetSCIMUsers(r.Context(), getWorkspaceID(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeList(w, r, lo.Map(users, func(user *model.SCIMUser, _ int) map[string]any {
+		return a.userResource(user)
+	}))
+}
+
+func (a *API) handleGetUser(w http.ResponseWriter, r *http.Request) {
+	id, err := getID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	user, err := a.store.GetSCIMUser(r.Context(), getWorkspaceID(r), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeResource(w, r, http.StatusOK, a.userResource(user))
+}
+
+func (a *API) handleCreateUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var resource map[string]any
+	if err := decodeJSON(r, &resource); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	user := &model.SCIMUser{WorkspaceID: getWorkspaceID(r)}
+	if err := parseUser(resource, user); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := a.checkUserNameAvailable(ctx, user); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := a.store.CreateSCIMUser(ctx, user); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	log.WithContext(ctx).WithField("workspaceID", user.WorkspaceID).WithField("adminID", user.AdminID).Info("scim user provisioned")
+
+	w.Header().Set("Location", a.location("User", user.ID))
+	writeResource(w, r, http.StatusCreated, a.userResource(user))
+}
+
+// updateUser applies the change to a copy of the user's resource and saves the result.
+func (a *API) updateUser(w http.ResponseWriter, r *http.Request, change func(user *model.SCIMUser) (map[string]any, error)) {
+	ctx := r.Context()
+	id, err := getID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	user, err := a.store.GetSCIMUser(ctx, getWorkspaceID(r), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	resource, err := change(user)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := parseUser(resource, user); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := a.checkUserNameAvailable(ctx, user); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := a.store.UpdateSCIMUser(ctx, user); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if user, err = a.store.GetSCIMUser(ctx, user.WorkspaceID, user.ID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeResource(w, r, http.StatusOK, a.userResource(user))
+}
+
+func (a *API) handleReplaceUser(w http.ResponseWriter, r *http.Request) {
+	a.updateUser(w, r, func(user *model.SCIMUser) (map[string]any, error) {
+		var resource map[string]any
+		return resource, decodeJSON(r, &resource)
+	})
+}
+
+func (a *API) handlePatchUser(w http.ResponseWriter, r *http.Request) {
+	a.updateUser(w, r, func(user *model.SCIMUser) (map[string]any, error) {
+		var request PatchRequest
+		if err := decodeJSON(r, &request); err != nil {
+			return nil, err
+		}
+		resource := a.userResource(user)
+		return resource, applyPatch(resource, request.Operations)
+	})
+}
+
+func (a *API) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := getID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	user, err := a.store.GetSCIMUser(ctx, getWorkspaceID(r), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := a.store.DeleteSCIMUser(ctx, user); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	log.WithContext(ctx).WithField("workspaceID", user.WorkspaceID).WithField("adminID", user.AdminID).Info("scim user deprovisioned")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setGroupMembers resolves the member ids of the group, which must be users of the group's workspace.
+func (a *API) setGroupMembers(ctx context.Context, group *model.SCIMGroup, memberIDs []int) error {
+	users, err := a.store.GetSCIMUsers(ctx, group.WorkspaceID)
+	if err != nil {
+		return err
+	}
+	usersByID := lo.KeyBy(users, func(user *model.SCIMUser) int { return user.ID })
+	group.Members = nil
+	for _, id := range lo.Uniq(memberIDs) {
+		user, ok := usersByID[id]
+		if !ok {
+			return invalidValue("member " + strconv.Itoa(id) + " does not exist")
+		}
+		group.Members = append(group.Members, user)
+	}
+	sort.Slice(group.Members, func(i, j int) bool {
+		return group.Members[i].ID < group.Members[j].ID
+	})
+	return nil
+}
+
+func (a *API) checkGroupNameAvailable(ctx context.Context, group *model.SCIMGroup) error {
+	groups, err := a.store.GetSCIMGroups(ctx, group.WorkspaceID)
+	if err != nil {
+		return err
+	}
+	if lo.ContainsBy(groups, func(other *model.SCIMGroup) bool {
+		return other.ID != group.ID && strings.EqualFold(other.DisplayName, group.DisplayName)
+	}) {
+		return newError(http.StatusConflict, "uniqueness", "displayName "+group.DisplayName+" is already in use")
+	}
+	return nil
+}
+
+func (a *API) handleListGroups(w http.ResponseWriter, r *http.Request) {
+	groups, err := a.store.GetSCIMGroups(r.Context(), getWorkspaceID(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeList(w, r, lo.Map(groups, func(group *model.SCIMGroup, _ int) map[string]any {
+		return a.groupResource(group)
+	}))
+}
+
+func (a *API) handleGetGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := getID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	group, err := a.store.GetSCIMGroup(r.Context(), getWorkspaceID(r), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeResource(w, r, http.StatusOK, a.groupResource(group))
+}
+
+func (a *API) handleCreateGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var resource map[string]any
+	if err := decodeJSON(r, &resource); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	group := &model.SCIMGroup{WorkspaceID: getWorkspaceID(r), Role: model.AdminRole.MEMBER}
+	memberIDs, err := parseGroup(resource, group)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := a.checkGroupNameAvailable(ctx, group); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := a.setGroupMembers(ctx, group, memberIDs); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := a.store.CreateSCIMGroup(ctx, group); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", a.location("Group", group.ID))
+	writeResource(w, r, http.StatusCreated, a.groupResource(group))
+}
+
+// updateGroup applies the change to a copy of the group's resource and saves the result.
+func (a *API) updateGroup(w http.ResponseWriter, r *http.Request, change func(group *model.SCIMGroup) (map[string]any, error)) {
+	ctx := r.Context()
+	id, err := getID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	group, err := a.store.GetSCIMGroup(ctx, getWorkspaceID(r), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	resource, err := change(group)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	memberIDs, err := parseGroup(resource, group)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := a.checkGroupNameAvailable(ctx, group); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := a.setGroupMembers(ctx, group, memberIDs); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := a.store.UpdateSCIMGroup(ctx, group); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if group, err = a.store.GetSCIMGroup(ctx, group.WorkspaceID, group.ID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeResource(w, r, http.StatusOK, a.groupResource(group))
+}
+
+func (a *API) handleReplaceGroup(w http.ResponseWriter, r *http.Request) {
+	a.updateGroup(w, r, func(group *model.SCIMGroup) (map[string]any, error) {
+		var resource map[string]any
+		return resource, decodeJSON(r, &resource)
+	})
+}
+
+func (a *API) handlePatchGroup(w http.ResponseWriter, r *http.Request) {
+	a.updateGroup(w, r, func(group *model.SCIMGroup) (map[string]any, error) {
+		var request PatchRequest
+		if err := decodeJSON(r, &request); err != nil {
+			return nil, err
+		}
+		resource := a.groupResource(group)
+		return resource, applyPatch(resource, request.Operations)
+	})
+}
+
+func (a *API) handleDeleteGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := getID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	group, err := a.store.GetSCIMGroup(ctx, getWorkspaceID(r), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := a.store.DeleteSCIMGroup(ctx, group); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,389 @@
+package scim
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+const testToken = "scim-test-token"
+
+// memoryStore is an in-memory Store for a single workspace.
+type memoryStore struct {
+	workspaceID int
+	nextID      int
+	users       []*model.SCIMUser
+	groups      []*model.SCIMGroup
+}
+
+func (s *memoryStore) GetSCIMTokenWorkspaceID(_ context.Context, token string) (int, error) {
+	if token != testToken {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return s.workspaceID, nil
+}
+
+func (s *memoryStore) GetSCIMUsers(_ context.Context, workspaceID int) ([]*model.SCIMUser, error) {
+	return lo.Map(s.users, func(user *model.SCIMUser, _ int) *model.SCIMUser { return s.loadUser(user) }), nil
+}
+
+func (s *memoryStore) loadUser(user *model.SCIMUser) *model.SCIMUser {
+	result := *user
+	result.Groups = lo.Filter(s.groups, func(group *model.SCIMGroup, _ int) bool {
+		return lo.ContainsBy(group.Members, func(member *model.SCIMUser) bool { return member.ID == user.ID })
+	})
+	return &result
+}
+
+func (s *memoryStore) GetSCIMUser(_ context.Context, workspaceID int, id int) (*model.SCIMUser, error) {
+	user, ok := lo.Find(s.users, func(user *model.SCIMUser) bool { return user.ID == id && user.WorkspaceID == workspaceID })
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return s.loadUser(user), nil
+}
+
+func (s *memoryStore) CreateSCIMUser(_ context.Context, user *model.SCIMUser) error {
+	s.nextID++
+	user.ID, user.CreatedAt, user.UpdatedAt = s.nextID, time.Now(), time.Now()
+	stored := *user
+	s.users = append(s.users, &stored)
+	return nil
+}
+
+func (s *memoryStore) UpdateSCIMUser(_ context.Context, user *model.SCIMUser) error {
+	for i, existing := range s.users {
+		if existing.ID == user.ID {
+			stored := *user
+			stored.Groups, stored.UpdatedAt = nil, time.Now()
+			s.users[i] = &stored
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) DeleteSCIMUser(_ context.Context, user *model.SCIMUser) error {
+	s.users = lo.Reject(s.users, func(existing *model.SCIMUser, _ int) bool { return existing.ID == user.ID })
+	for _, group := range s.groups {
+		group.Members = lo.Reject(group.Members, func(member *model.SCIMUser, _ int) bool { return member.ID == user.ID })
+	}
+	return nil
+}
+
+func (s *memoryStore) GetSCIMGroups(_ context.Context, workspaceID int) ([]*model.SCIMGroup, error) {
+	return lo.Map(s.groups, func(group *model.SCIMGroup, _ int) *model.SCIMGroup {
+		result := *group
+		return &result
+	}), nil
+}
+
+func (s *memoryStore) GetSCIMGroup(_ context.Context, workspaceID int, id int) (*model.SCIMGroup, error) {
+	group, ok := lo.Find(s.groups, func(group *model.SCIMGroup) bool { return group.ID == id && group.WorkspaceID == workspaceID })
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	result := *group
+	return &result, nil
+}
+
+func (s *memoryStore) CreateSCIMGroup(_ context.Context, group *model.SCIMGroup) error {
+	s.nextID++
+	group.ID, group.CreatedAt, group.UpdatedAt = s.nextID, time.Now(), time.Now()
+	stored := *group
+	s.groups = append(s.groups, &stored)
+	return nil
+}
+
+func (s *memoryStore) UpdateSCIMGroup(_ context.Context, group *model.SCIMGroup) error {
+	for i, existing := range s.groups {
+		if existing.ID == group.ID {
+			stored := *group
+			stored.UpdatedAt = time.Now()
+			s.groups[i] = &stored
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) DeleteSCIMGroup(_ context.Context, group *model.SCIMGroup) error {
+	s.groups = lo.Reject(s.groups, func(existing *model.SCIMGroup, _ int) bool { return existing.ID == group.ID })
+	return nil
+}
+
+type harness struct {
+	t      *testing.T
+	server *httptest.Server
+	store  *memoryStore
+}
+
+func newHarness(t *testing.T) *harness {
+	store := &memoryStore{workspaceID: 1}
+	r := chi.NewRouter()
+	r.Route("/scim/v2", func(r chi.Router) {
+		NewAPI(store, "https://pri.highlight.io/scim/v2").Listen(r)
+	})
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return &harness{t: t, server: server, store: store}
+}
+
+func (h *harness) do(method string, path string, body any) (int, map[string]any) {
+	var reader *bytes.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		require.NoError(h.t, err)
+		reader = bytes.NewReader(b)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, h.server.URL+"/scim/v2"+path, reader)
+	require.NoError(h.t, err)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	req.Header.Set("Content-Type", contentType)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(h.t, err)
+	defer res.Body.Close()
+
+	var result map[string]any
+	if res.StatusCode != http.StatusNoContent {
+		assert.Equal(h.t, contentType, res.Header.Get("Content-Type"))
+		require.NoError(h.t, json.NewDecoder(res.Body).Decode(&result))
+	}
+	return res.StatusCode, result
+}
+
+func (h *harness) createUser(userName string) string {
+	status, user := h.do(http.MethodPost, "/Users", map[string]any{
+		"schemas":  []string{userSchema},
+		"userName": userName,
+		"name":     map[string]any{"givenName": "Jay", "familyName": "Khatri"},
+		"emails":   []any{map[string]any{"value": userName, "type": "work", "primary": true}},
+		"active":   true,
+	})
+	require.Equal(h.t, http.StatusCreated, status)
+	return user["id"].(string)
+}
+
+func patch(operations ...map[string]any) map[string]any {
+	return map[string]any{"schemas": []string{patchOpSchema}, "Operations": operations}
+}
+
+func TestAuthentication(t *testing.T) {
+	h := newHarness(t)
+
+	res, err := http.Get(h.server.URL + "/scim/v2/Users")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	req, _ := http.NewRequest(http.MethodGet, h.server.URL+"/scim/v2/Users", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	res, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	var body map[string]any
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+	assert.Equal(t, []any{errorSchema}, body["schemas"])
+	assert.Equal(t, "401", body["status"])
+}
+
+func TestDiscovery(t *testing.T) {
+	h := newHarness(t)
+
+	status, config := h.do(http.MethodGet, "/ServiceProviderConfig", nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, true, config["patch"].(map[string]any)["supported"])
+	assert.Equal(t, true, config["filter"].(map[string]any)["supported"])
+
+	status, resourceTypes := h.do(http.MethodGet, "/ResourceTypes", nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, float64(2), resourceTypes["totalResults"])
+
+	status, schemas := h.do(http.MethodGet, "/Schemas", nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, float64(3), schemas["totalResults"])
+}
+
+func TestUsers(t *testing.T) {
+	h := newHarness(t)
+
+	// identity providers check whether a user exists before creating it
+	status, list := h.do(http.MethodGet, "/Users?filter="+url.QueryEscape(`userName eq "jay@example.com"`), nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []any{listResponseSchema}, list["schemas"])
+	assert.Equal(t, float64(0), list["totalResults"])
+	assert.Empty(t, list["Resources"])
+
+	id := h.createUser("jay@example.com")
+
+	status, user := h.do(http.MethodGet, "/Users/"+id, nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "jay@example.com", user["userName"])
+	assert.Equal(t, true, user["active"])
+	assert.Equal(t, "Jay", user["name"].(map[string]any)["givenName"])
+	assert.Equal(t, "https://pri.highlight.io/scim/v2/Users/"+id, user["meta"].(map[string]any)["location"])
+
+	status, list = h.do(http.MethodGet, "/Users?filter="+url.QueryEscape(`userName eq "JAY@example.com"`), nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, float64(1), list["totalResults"])
+
+	status, conflict := h.do(http.MethodPost, "/Users", map[string]any{"schemas": []string{userSchema}, "userName": "jay@example.com"})
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Equal(t, "uniqueness", conflict["scimType"])
+
+	status, user = h.do(http.MethodPut, "/Users/"+id, map[string]any{
+		"schemas":  []string{userSchema},
+		"userName": "jay@example.com",
+		"name":     map[string]any{"givenName": "Jay", "familyName": "K"},
+		"active":   true,
+	})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "K", user["name"].(map[string]any)["familyName"])
+
+	// Okta deactivates users with a replace operation without a path
+	status, user = h.do(http.MethodPatch, "/Users/"+id, patch(map[string]any{"op": "replace", "value": map[string]any{"active": false}}))
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, false, user["active"])
+
+	// Azure AD sends capitalized operations and string booleans
+	status, user = h.do(http.MethodPatch, "/Users/"+id, patch(
+		map[string]any{"op": "Replace", "path": "active", "value": "True"},
+		map[string]any{"op": "Add", "path": "name.givenName", "value": "Jaydev"},
+		map[string]any{"op": "Replace", "path": `emails[type eq "work"].value`, "value": "jay@highlight.io"},
+	))
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, true, user["active"])
+	assert.Equal(t, "Jaydev", user["name"].(map[string]any)["givenName"])
+	assert.Equal(t, "jay@highlight.io", user["emails"].([]any)[0].(map[string]any)["value"])
+
+	status, user = h.do(http.MethodGet, "/Users/"+id+"?attributes=userName", nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "jay@example.com", user["userName"])
+	assert.Equal(t, id, user["id"])
+	assert.NotContains(t, user, "name")
+
+	status, _ = h.do(http.MethodDelete, "/Users/"+id, nil)
+	assert.Equal(t, http.StatusNoContent, status)
+
+	status, notFound := h.do(http.MethodGet, "/Users/"+id, nil)
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "404", notFound["status"])
+}
+
+func TestUsersValidation(t *testing.T) {
+	h := newHarness(t)
+
+	status, body := h.do(http.MethodPost, "/Users", map[string]any{"schemas": []string{userSchema}})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalidValue", body["scimType"])
+
+	status, body = h.do(http.MethodGet, "/Users?filter="+url.QueryEscape(`userName eq`), nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalidFilter", body["scimType"])
+
+	status, _ = h.do(http.MethodGet, "/Users/not-a-user", nil)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, _ = h.do(http.MethodDelete, "/Users/123", nil)
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestUsersPagination(t *testing.T) {
+	h := newHarness(t)
+	for _, userName := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		h.createUser(userName)
+	}
+
+	status, list := h.do(http.MethodGet, "/Users?startIndex=2&count=1", nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, float64(3), list["totalResults"])
+	assert.Equal(t, float64(2), list["startIndex"])
+	assert.Equal(t, float64(1), list["itemsPerPage"])
+	assert.Equal(t, "b@example.com", list["Resources"].([]any)[0].(map[string]any)["userName"])
+
+	status, list = h.do(http.MethodGet, "/Users?startIndex=10", nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, float64(0), list["itemsPerPage"])
+}
+
+func TestGroups(t *testing.T) {
+	h := newHarness(t)
+	jay := h.createUser("jay@example.com")
+	vadim := h.createUser("vadim@example.com")
+
+	status, group := h.do(http.MethodPost, "/Groups", map[string]any{
+		"schemas":     []string{groupSchema},
+		"displayName": "Engineering",
+		"members":     []any{map[string]any{"value": jay}},
+	})
+	require.Equal(t, http.StatusCreated, status)
+	id := group["id"].(string)
+	assert.Len(t, group["members"], 1)
+	assert.Equal(t, model.AdminRole.MEMBER, group[groupExtensionSchema].(map[string]any)["role"])
+
+	status, conflict := h.do(http.MethodPost, "/Groups", map[string]any{"schemas": []string{groupSchema}, "displayName": "engineering"})
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Equal(t, "uniqueness", conflict["scimType"])
+
+	status, _ = h.do(http.MethodPost, "/Groups", map[string]any{
+		"schemas":     []string{groupSchema},
+		"displayName": "Missing",
+		"members":     []any{map[string]any{"value": "999"}},
+	})
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, group = h.do(http.MethodPatch, "/Groups/"+id, patch(map[string]any{
+		"op":    "add",
+		"path":  "members",
+		"value": []any{map[string]any{"value": vadim}},
+	}))
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, group["members"], 2)
+
+	status, user := h.do(http.MethodGet, "/Users/"+vadim, nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, id, user["groups"].([]any)[0].(map[string]any)["value"])
+
+	status, group = h.do(http.MethodPatch, "/Groups/"+id, patch(
+		map[string]any{"op": "remove", "path": `members[value eq "` + jay + `"]`},
+		map[string]any{"op": "replace", "path": "displayName", "value": "Platform"},
+		map[string]any{"op": "replace", "path": groupExtensionSchema + ":role", "value": model.AdminRole.ADMIN},
+	))
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "Platform", group["displayName"])
+	assert.Equal(t, vadim, group["members"].([]any)[0].(map[string]any)["value"])
+	assert.Len(t, group["members"], 1)
+	assert.Equal(t, model.AdminRole.ADMIN, group[groupExtensionSchema].(map[string]any)["role"])
+
+	status, list := h.do(http.MethodGet, "/Groups?excludedAttributes=members&filter="+url.QueryEscape(`displayName eq "Platform"`), nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, float64(1), list["totalResults"])
+	assert.NotContains(t, list["Resources"].([]any)[0], "members")
+
+	status, _ = h.do(http.MethodPatch, "/Groups/"+id, patch(map[string]any{
+		"op":    "replace",
+		"path":  groupExtensionSchema + ":role",
+		"value": "OWNER",
+	}))
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = h.do(http.MethodDelete, "/Groups/"+id, nil)
+	assert.Equal(t, http.StatusNoContent, status)
+
+	status, _ = h.do(http.MethodGet, "/Groups/"+id, nil)
+	assert.Equal(t, http.StatusNotFound, status)
+}
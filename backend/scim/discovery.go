@@ -0,0 +1,145 @@
+package scim
+
+import (
+	"net/http"
+)
+
+// handleServiceProviderConfig describes the supported SCIM features: https://datatracker.ietf.org/doc/html/rfc7643#section-5
+func (a *API) handleServiceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"schemas":          []string{serviceProviderConfigSchema},
+		"documentationUri": "https://www.highlight.io/docs/general/company/open-source/hosting/self-host-enterprise",
+		"patch":            map[string]any{"supported": true},
+		"bulk":             map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":           map[string]any{"supported": true, "maxResults": maxCount},
+		"changePassword":   map[string]any{"supported": false},
+		"sort":             map[string]any{"supported": false},
+		"etag":             map[string]any{"supported": false},
+		"authenticationSchemes": []any{map[string]any{
+			"type":        "oauthbearertoken",
+			"name":        "OAuth Bearer Token",
+			"description": "Authentication with a workspace SCIM token",
+			"primary":     true,
+		}},
+		"meta": map[string]any{
+			"resourceType": "ServiceProviderConfig",
+			"location":     a.baseURL + "/ServiceProviderConfig",
+		},
+	})
+}
+
+func (a *API) resourceTypes() []any {
+	return []any{
+		map[string]any{
+			"schemas":     []string{resourceTypeSchema},
+			"id":          "User",
+			"name":        "User",
+			"endpoint":    "/Users",
+			"description": "Workspace members",
+			"schema":      userSchema,
+			"meta":        map[string]any{"resourceType": "ResourceType", "location": a.baseURL + "/ResourceTypes/User"},
+		},
+		map[string]any{
+			"schemas":     []string{resourceTypeSchema},
+			"id":          "Group",
+			"name":        "Group",
+			"endpoint":    "/Groups",
+			"description": "Groups granting a workspace role and project access to their members",
+			"schema":      groupSchema,
+			"schemaExtensions": []any{map[string]any{
+				"schema":   groupExtensionSchema,
+				"required": false,
+			}},
+			"meta": map[string]any{"resourceType": "ResourceType", "location": a.baseURL + "/ResourceTypes/Group"},
+		},
+	}
+}
+
+func (a *API) handleResourceTypes(w http.ResponseWriter, r *http.Request) {
+	resources := a.resourceTypes()
+	writeJSON(w, http.StatusOK, map[string]any{
+		"schemas":      []string{listResponseSchema},
+		"totalResults": len(resources),
+		"startIndex":   1,
+		"itemsPerPage": len(resources),
+		"Resources":    resources,
+	})
+}
+
+func attribute(name string, typ string, multiValued bool, required bool, subAttributes ...map[string]any) map[string]any {
+	result := map[string]any{
+		"name":        name,
+		"type":        typ,
+		"multiValued": multiValued,
+		"required":    required,
+		"caseExact":   false,
+		"mutability":  "readWrite",
+		"returned":    "default",
+		"uniqueness":  "none",
+	}
+	if len(subAttributes) > 0 {
+		result["subAttributes"] = subAttributes
+	}
+	return result
+}
+
+func (a *API) schemas() []any {
+	schema := func(id string, name string, attributes ...map[string]any) map[string]any {
+		return map[string]any{
+			"schemas":    []string{schemaSchema},
+			"id":         id,
+			"name":       name,
+			"attributes": attributes,
+			"meta":       map[string]any{"resourceType": "Schema", "location": a.baseURL + "/Schemas/" + id},
+		}
+	}
+	userName := attribute("userName", "string", false, true)
+	userName["uniqueness"] = "server"
+	displayName := attribute("displayName", "string", false, true)
+	displayName["uniqueness"] = "server"
+	return []any{
+		schema(userSchema, "User",
+			userName,
+			attribute("name", "complex", false, false,
+				attribute("givenName", "string", false, false),
+				attribute("familyName", "string", false, false),
+				attribute("formatted", "string", false, false),
+			),
+			attribute("displayName", "string", false, false),
+			attribute("emails", "complex", true, false,
+				attribute("value", "string", false, false),
+				attribute("type", "string", false, false),
+				attribute("primary", "boolean", false, false),
+			),
+			attribute("active", "boolean", false, false),
+			attribute("groups", "complex", true, false,
+				attribute("value", "string", false, false),
+				attribute("display", "string", false, false),
+				attribute("$ref", "reference", false, false),
+			),
+		),
+		schema(groupSchema, "Group",
+			displayName,
+			attribute("members", "complex", true, false,
+				attribute("value", "string", false, false),
+				attribute("display", "string", false, false),
+				attribute("$ref", "reference", false, false),
+			),
+		),
+		schema(groupExtensionSchema, "HighlightGroup",
+			attribute("role", "string", false, false),
+			attribute("projectIds", "string", true, false),
+		),
+	}
+}
+
+func (a *API) handleSchemas(w http.ResponseWriter, r *http.Request) {
+	resources := a.schemas()
+	writeJSON(w, http.StatusOK, map[string]any{
+		"schemas":      []string{listResponseSchema},
+		"totalResults": len(resources),
+		"startIndex":   1,
+		"itemsPerPage": len(resources),
+		"Resources":    resources,
+	})
+}
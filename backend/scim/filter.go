@@ -0,0 +1,489 @@
+package scim
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode"
+
+	e "github.com/pkg/errors"
+)
+
+// Filter matches SCIM resources as described in https://datatracker.ietf.org/doc/html/rfc7644#section-3.4.2.2
+type Filter interface {
+	Match(resource map[string]any) bool
+}
+
+type compareFilter struct {
+	path  string
+	op    string
+	value any
+}
+
+type presentFilter struct {
+	path string
+}
+
+type logicalFilter struct {
+	op          string
+	left, right Filter
+}
+
+type notFilter struct {
+	filter Filter
+}
+
+// valuePathFilter matches when any value of a multi-valued complex attribute matches the filter, e.g. emails[type eq "work"].
+type valuePathFilter struct {
+	path   string
+	filter Filter
+}
+
+func (f *compareFilter) Match(resource map[string]any) bool {
+	values := getValues(resource, f.path)
+	if f.op == "ne" {
+		for _, value := range values {
+			if compareValue(value, "eq", f.value) {
+				return false
+			}
+		}
+		return f.value != nil || len(values) > 0
+	}
+	if f.value == nil {
+		return f.op == "eq" && len(values) == 0
+	}
+	for _, value := range values {
+		if compareValue(value, f.op, f.value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *presentFilter) Match(resource map[string]any) bool {
+	for _, value := range getValues(resource, f.path) {
+		if value != nil && value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *logicalFilter) Match(resource map[string]any) bool {
+	if f.op == "and" {
+		return f.left.Match(resource) && f.right.Match(resource)
+	}
+	return f.left.Match(resource) || f.right.Match(resource)
+}
+
+func (f *notFilter) Match(resource map[string]any) bool {
+	return !f.filter.Match(resource)
+}
+
+func (f *valuePathFilter) Match(resource map[string]any) bool {
+	container, key := resolvePath(resource, f.path, false)
+	if container == nil {
+		return false
+	}
+	elements, _ := container[key].([]any)
+	for _, element := range elements {
+		if value, ok := element.(map[string]any); ok && f.filter.Match(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func compareValue(value any, op string, expected any) bool {
+	switch expected := expected.(type) {
+	case bool:
+		actual, ok := value.(bool)
+		return ok && op == "eq" && actual == expected
+	case float64:
+		var actual float64
+		switch v := value.(type) {
+		case float64:
+			actual = v
+		case int:
+			actual = float64(v)
+		default:
+			return false
+		}
+		switch op {
+		case "eq":
+			return actual == expected
+		case "gt":
+			return actual > expected
+		case "ge":
+			return actual >= expected
+		case "lt":
+			return actual < expected
+		case "le":
+			return actual <= expected
+		}
+		return false
+	case string:
+		actual, ok := value.(string)
+		if !ok {
+			return false
+		}
+		// attributes are compared case-insensitively as most are not case exact
+		actual, expected = strings.ToLower(actual), strings.ToLower(expected)
+		switch op {
+		case "eq":
+			return actual == expected
+		case "co":
+			return strings.Contains(actual, expected)
+		case "sw":
+			return strings.HasPrefix(actual, expected)
+		case "ew":
+			return strings.HasSuffix(actual, expected)
+		case "gt":
+			return actual > expected
+		case "ge":
+			return actual >= expected
+		case "lt":
+			return actual < expected
+		case "le":
+			return actual <= expected
+		}
+	}
+	return false
+}
+
+// findKey returns the key of the map matching the attribute name case-insensitively, or the name itself.
+func findKey(m map[string]any, name string) string {
+	if _, ok := m[name]; ok {
+		return name
+	}
+	for key := range m {
+		if strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return name
+}
+
+// splitSchema returns the map holding the attributes of the path's schema and the path without the schema URN.
+// Attributes of extension schemas are nested under the schema URN.
+func splitSchema(resource map[string]any, path string, create bool) (map[string]any, string) {
+	idx := strings.LastIndex(path, ":")
+	if idx < 0 {
+		return resource, path
+	}
+	urn := path[:idx]
+	if isCoreSchema(urn) {
+		return resource, path[idx+1:]
+	}
+	key := findKey(resource, urn)
+	extension, _ := resource[key].(map[string]any)
+	if extension == nil {
+		if !create {
+			return nil, ""
+		}
+		extension = map[string]any{}
+		resource[key] = extension
+	}
+	return extension, path[idx+1:]
+}
+
+// resolvePath returns the map containing the last attribute of the path and the attribute key.
+// Intermediate maps are created when create is set.
+func resolvePath(resource map[string]any, path string, create bool) (map[string]any, string) {
+	container, path := splitSchema(resource, path, create)
+	if container == nil {
+		return nil, ""
+	}
+	parts := strings.Split(path, ".")
+	for _, part := range parts[:len(parts)-1] {
+		key := findKey(container, part)
+		next, _ := container[key].(map[string]any)
+		if next == nil {
+			if !create {
+				return nil, ""
+			}
+			next = map[string]any{}
+			container[key] = next
+		}
+		container = next
+	}
+	return container, findKey(container, parts[len(parts)-1])
+}
+
+// getValues returns the values of the attribute path, flattening multi-valued attributes.
+// The primary value of a complex multi-valued attribute is its "value" sub-attribute.
+func getValues(resource map[string]any, path string) []any {
+	container, path := splitSchema(resource, path, false)
+	if container == nil {
+		return nil
+	}
+	return collectValues([]any{container}, strings.Split(path, "."))
+}
+
+func collectValues(values []any, parts []string) []any {
+	if len(parts) == 0 {
+		var result []any
+		for _, value := range values {
+			switch v := value.(type) {
+			case []any:
+				result = append(result, collectValues(v, nil)...)
+			case map[string]any:
+				if inner, ok := v[findKey(v, "value")]; ok {
+					result = append(result, inner)
+				}
+			default:
+				result = append(result, v)
+			}
+		}
+		return result
+	}
+
+	var next []any
+	for _, value := range values {
+		switch v := value.(type) {
+		case []any:
+			next = append(next, collectValues(v, parts)...)
+			continue
+		case map[string]any:
+			if inner, ok := v[findKey(v, parts[0])]; ok {
+				next = append(next, inner)
+			}
+		}
+	}
+	if next == nil {
+		return nil
+	}
+	return collectValues(next, parts[1:])
+}
+
+type tokenType int
+
+const (
+	tokenEOF tokenType = iota
+	tokenWord
+	tokenString
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+)
+
+type token struct {
+	typ   tokenType
+	value string
+}
+
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(input); {
+		c := input[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '(':
+			tokens = append(tokens, token{typ: tokenLParen, value: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{typ: tokenRParen, value: ")"})
+			i++
+		case c == '[':
+			tokens = append(tokens, token{typ: tokenLBracket, value: "["})
+			i++
+		case c == ']':
+			tokens = append(tokens, token{typ: tokenRBracket, value: "]"})
+			i++
+		case c == '"':
+			end := i + 1
+			for ; end < len(input) && input[end] != '"'; end++ {
+				if input[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(input) {
+				return nil, e.Errorf("unterminated string at position %d", i)
+			}
+			var value string
+			if err := json.Unmarshal([]byte(input[i:end+1]), &value); err != nil {
+				return nil, e.Errorf("invalid string at position %d", i)
+			}
+			tokens = append(tokens, token{typ: tokenString, value: value})
+			i = end + 1
+		default:
+			end := i
+			for ; end < len(input); end++ {
+				r := rune(input[end])
+				if unicode.IsSpace(r) || strings.ContainsRune("()[]\"", r) {
+					break
+				}
+			}
+			tokens = append(tokens, token{typ: tokenWord, value: input[i:end]})
+			i = end
+		}
+	}
+	return append(tokens, token{typ: tokenEOF}), nil
+}
+
+type filterParser struct {
+	tokens []token
+	pos    int
+}
+
+// ParseFilter parses a SCIM filter expression such as `userName eq "jay@example.com" and active eq true`.
+func ParseFilter(input string) (Filter, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().typ != tokenEOF {
+		return nil, e.Errorf("unexpected %q", p.peek().value)
+	}
+	return filter, nil
+}
+
+func (p *filterParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() token {
+	t := p.tokens[p.pos]
+	if t.typ != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *filterParser) isKeyword(keyword string) bool {
+	t := p.peek()
+	return t.typ == tokenWord && strings.EqualFold(t.value, keyword)
+}
+
+func (p *filterParser) expect(typ tokenType, value string) error {
+	if t := p.next(); t.typ != typ {
+		return e.Errorf("expected %q", value)
+	}
+	return nil
+}
+
+func (p *filterParser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalFilter{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (Filter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalFilter{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (Filter, error) {
+	if p.isKeyword("not") {
+		p.next()
+		if err := p.expect(tokenLParen, "("); err != nil {
+			return nil, err
+		}
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return &notFilter{filter: filter}, nil
+	}
+	if p.peek().typ == tokenLParen {
+		p.next()
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return filter, nil
+	}
+	return p.parseAttributeExpression()
+}
+
+func (p *filterParser) parseAttributeExpression() (Filter, error) {
+	path := p.next()
+	if path.typ != tokenWord {
+		return nil, e.Errorf("expected attribute path but got %q", path.value)
+	}
+
+	if p.peek().typ == tokenLBracket {
+		p.next()
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRBracket, "]"); err != nil {
+			return nil, err
+		}
+		return &valuePathFilter{path: path.value, filter: filter}, nil
+	}
+
+	op := p.next()
+	if op.typ != tokenWord {
+		return nil, e.Errorf("expected operator after %q", path.value)
+	}
+	switch strings.ToLower(op.value) {
+	case "pr":
+		return &presentFilter{path: path.value}, nil
+	case "eq", "ne", "co", "sw", "ew", "gt", "ge", "lt", "le":
+	default:
+		return nil, e.Errorf("unknown operator %q", op.value)
+	}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	return &compareFilter{path: path.value, op: strings.ToLower(op.value), value: value}, nil
+}
+
+func (p *filterParser) parseValue() (any, error) {
+	t := p.next()
+	switch t.typ {
+	case tokenString:
+		return t.value, nil
+	case tokenWord:
+		switch strings.ToLower(t.value) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		if number, err := strconv.ParseFloat(t.value, 64); err == nil {
+			return number, nil
+		}
+	}
+	return nil, e.Errorf("invalid comparison value %q", t.value)
+}
@@ -0,0 +1,92 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilter(t *testing.T) {
+	resource := map[string]any{
+		"userName": "Jay@Example.com",
+		"active":   true,
+		"name":     map[string]any{"givenName": "Jay", "familyName": "Khatri"},
+		"emails": []any{
+			map[string]any{"value": "jay@example.com", "type": "work", "primary": true},
+			map[string]any{"value": "jay@home.com", "type": "home"},
+		},
+		"meta":               map[string]any{"lastModified": "2024-01-02T00:00:00Z"},
+		groupExtensionSchema: map[string]any{"role": "ADMIN"},
+	}
+
+	for filter, expected := range map[string]bool{
+		`userName eq "jay@example.com"`:                          true,
+		`userName ne "jay@example.com"`:                          false,
+		`userName sw "jay"`:                                      true,
+		`userName ew ".org"`:                                     false,
+		`name.familyName co "hat"`:                               true,
+		`active eq true`:                                         true,
+		`active eq false`:                                        false,
+		`title pr`:                                               false,
+		`emails pr`:                                              true,
+		`emails eq "jay@home.com"`:                               true,
+		`emails[type eq "work" and value co "example"]`:          true,
+		`emails[type eq "other"]`:                                false,
+		`meta.lastModified gt "2023-12-31T00:00:00Z"`:            true,
+		`userName eq "x" or (active eq true and not (title pr))`: true,
+		`urn:ietf:params:scim:schemas:core:2.0:User:userName sw "JAY"`: true,
+		groupExtensionSchema + `:role eq "admin"`:                      true,
+		`USERNAME EQ "jay@example.com" AND name.givenName Eq "jay"`:    true,
+		`externalId eq null`: true,
+	} {
+		parsed, err := ParseFilter(filter)
+		if assert.NoError(t, err, filter) {
+			assert.Equal(t, expected, parsed.Match(resource), filter)
+		}
+	}
+
+	for _, filter := range []string{
+		``,
+		`userName`,
+		`userName eq`,
+		`userName foo "x"`,
+		`(userName eq "x"`,
+		`emails[type eq "work"`,
+		`userName eq "unterminated`,
+		`userName eq "x" and`,
+		`not userName eq "x"`,
+	} {
+		_, err := ParseFilter(filter)
+		assert.Error(t, err, filter)
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	resource := map[string]any{
+		"userName": "jay@example.com",
+		"name":     map[string]any{"givenName": "Jay"},
+		"emails":   []any{map[string]any{"value": "jay@example.com", "type": "work"}},
+	}
+
+	assert.NoError(t, applyPatch(resource, []PatchOperation{
+		{Op: "add", Value: map[string]any{"name.familyName": "Khatri", "title": "CEO"}},
+		{Op: "add", Path: "emails", Value: []any{map[string]any{"value": "jay@home.com", "type": "home"}}},
+		{Op: "replace", Path: `emails[type eq "work"].value`, Value: "jay@highlight.io"},
+		{Op: "remove", Path: "title"},
+		{Op: "add", Path: groupExtensionSchema + ":role", Value: "ADMIN"},
+	}))
+	assert.Equal(t, "Khatri", resource["name"].(map[string]any)["familyName"])
+	assert.NotContains(t, resource, "title")
+	assert.Len(t, resource["emails"], 2)
+	assert.Equal(t, "jay@highlight.io", resource["emails"].([]any)[0].(map[string]any)["value"])
+	assert.Equal(t, "ADMIN", resource[groupExtensionSchema].(map[string]any)["role"])
+
+	assert.NoError(t, applyPatch(resource, []PatchOperation{
+		{Op: "remove", Path: "emails", Value: []any{map[string]any{"value": "jay@home.com"}}},
+	}))
+	assert.Len(t, resource["emails"], 1)
+
+	assert.Error(t, applyPatch(resource, []PatchOperation{{Op: "move", Path: "userName"}}))
+	assert.Error(t, applyPatch(resource, []PatchOperation{{Op: "remove"}}))
+	assert.Error(t, applyPatch(resource, []PatchOperation{{Op: "replace", Path: `emails[type eq "other"].value`, Value: "x"}}))
+}
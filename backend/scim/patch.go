@@ -0,0 +1,206 @@
+package scim
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// PatchOperation is a single operation of a SCIM PATCH request: https://datatracker.ietf.org/doc/html/rfc7644#section-3.5.2
+type PatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// patchPath is an attribute path with an optional value filter, e.g. emails[type eq "work"].value
+type patchPath struct {
+	attribute    string
+	filter       Filter
+	subAttribute string
+}
+
+func parsePatchPath(path string) (*patchPath, error) {
+	start := strings.Index(path, "[")
+	if start < 0 {
+		return &patchPath{attribute: path}, nil
+	}
+	end := strings.LastIndex(path, "]")
+	if end < start {
+		return nil, newError(http.StatusBadRequest, "invalidPath", "unterminated value filter in path "+path)
+	}
+	filter, err := ParseFilter(path[start+1 : end])
+	if err != nil {
+		return nil, newError(http.StatusBadRequest, "invalidPath", err.Error())
+	}
+	result := &patchPath{attribute: path[:start], filter: filter}
+	if rest := path[end+1:]; rest != "" {
+		if !strings.HasPrefix(rest, ".") {
+			return nil, newError(http.StatusBadRequest, "invalidPath", "invalid path "+path)
+		}
+		result.subAttribute = rest[1:]
+	}
+	return result, nil
+}
+
+// applyPatch applies the operations to the JSON representation of a resource.
+func applyPatch(resource map[string]any, operations []PatchOperation) error {
+	for _, operation := range operations {
+		if err := applyOperation(resource, operation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyOperation(resource map[string]any, operation PatchOperation) error {
+	op := strings.ToLower(operation.Op)
+	if op != "add" && op != "replace" && op != "remove" {
+		return newError(http.StatusBadRequest, "invalidSyntax", "unknown patch operation "+operation.Op)
+	}
+
+	if operation.Path == "" {
+		if op == "remove" {
+			return newError(http.StatusBadRequest, "noTarget", "remove operations require a path")
+		}
+		values, ok := operation.Value.(map[string]any)
+		if !ok {
+			return invalidValue("operations without a path require an object value")
+		}
+		// keys may be attribute paths such as name.givenName or extension schema URNs
+		for key, value := range values {
+			if extension, ok := value.(map[string]any); ok && strings.Contains(key, ":") && !isCoreSchema(key) {
+				for attribute, v := range extension {
+					if err := applyOperation(resource, PatchOperation{Op: op, Path: key + ":" + attribute, Value: v}); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if err := applyOperation(resource, PatchOperation{Op: op, Path: key, Value: value}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	path, err := parsePatchPath(operation.Path)
+	if err != nil {
+		return err
+	}
+	container, key := resolvePath(resource, path.attribute, op != "remove")
+	if container == nil {
+		if op == "remove" {
+			return nil
+		}
+		return newError(http.StatusBadRequest, "invalidPath", "invalid path "+operation.Path)
+	}
+
+	if path.filter != nil {
+		return applyFilteredOperation(container, key, op, path, operation)
+	}
+
+	switch op {
+	case "add":
+		existing, isMultiValued := container[key].([]any)
+		if !isMultiValued {
+			if values, ok := operation.Value.(map[string]any); ok {
+				if existing, ok := container[key].(map[string]any); ok {
+					for k, v := range values {
+						existing[findKey(existing, k)] = v
+					}
+					return nil
+				}
+			}
+			container[key] = operation.Value
+			return nil
+		}
+		values, ok := operation.Value.([]any)
+		if !ok {
+			values = []any{operation.Value}
+		}
+		for _, value := range values {
+			if !containsValue(existing, value) {
+				existing = append(existing, value)
+			}
+		}
+		container[key] = existing
+	case "replace":
+		container[key] = operation.Value
+	case "remove":
+		existing, isMultiValued := container[key].([]any)
+		values, hasValues := operation.Value.([]any)
+		if !isMultiValued || !hasValues {
+			delete(container, key)
+			return nil
+		}
+		// removes the listed values of a multi-valued attribute, e.g. the members of a group
+		var remaining []any
+		for _, element := range existing {
+			if !containsValue(values, element) {
+				remaining = append(remaining, element)
+			}
+		}
+		container[key] = remaining
+	}
+	return nil
+}
+
+func applyFilteredOperation(container map[string]any, key string, op string, path *patchPath, operation PatchOperation) error {
+	existing, _ := container[key].([]any)
+	var remaining []any
+	matched := false
+	for _, element := range existing {
+		value, ok := element.(map[string]any)
+		if !ok || !path.filter.Match(value) {
+			remaining = append(remaining, element)
+			continue
+		}
+		matched = true
+		switch {
+		case op == "remove" && path.subAttribute == "":
+			continue
+		case op == "remove":
+			delete(value, findKey(value, path.subAttribute))
+		case path.subAttribute != "":
+			value[findKey(value, path.subAttribute)] = operation.Value
+		default:
+			replacement, ok := operation.Value.(map[string]any)
+			if !ok {
+				return invalidValue("filtered operations without a sub-attribute require an object value")
+			}
+			for k, v := range replacement {
+				value[findKey(value, k)] = v
+			}
+		}
+		remaining = append(remaining, value)
+	}
+	if !matched && op != "remove" {
+		return newError(http.StatusBadRequest, "noTarget", "no values match the path "+operation.Path)
+	}
+	container[key] = remaining
+	return nil
+}
+
+// containsValue compares complex values by their "value" sub-attribute.
+func containsValue(values []any, value any) bool {
+	primary := func(v any) any {
+		if m, ok := v.(map[string]any); ok {
+			if inner, ok := m[findKey(m, "value")]; ok {
+				return inner
+			}
+		}
+		return v
+	}
+	for _, v := range values {
+		if reflect.DeepEqual(primary(v), primary(value)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,308 @@
+package scim
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/samber/lo"
+	"github.com/sendgrid/sendgrid-go/helpers/mail"
+)
+
+const (
+	userSchema                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	groupSchema                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	groupExtensionSchema        = "urn:ietf:params:scim:schemas:extension:highlight:2.0:Group"
+	serviceProviderConfigSchema = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	resourceTypeSchema          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	schemaSchema                = "urn:ietf:params:scim:schemas:core:2.0:Schema"
+	listResponseSchema          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	patchOpSchema               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	errorSchema                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+var coreSchemas = []string{userSchema, groupSchema}
+
+func isCoreSchema(urn string) bool {
+	return lo.ContainsBy(coreSchemas, func(schema string) bool {
+		return strings.EqualFold(schema, urn)
+	})
+}
+
+// Error is a SCIM error response: https://datatracker.ietf.org/doc/html/rfc7644#section-3.12
+type Error struct {
+	Status   int
+	SCIMType string
+	Detail   string
+}
+
+func (err *Error) Error() string {
+	return err.Detail
+}
+
+func newError(status int, scimType string, detail string) *Error {
+	return &Error{Status: status, SCIMType: scimType, Detail: detail}
+}
+
+func invalidValue(detail string) *Error {
+	return newError(http.StatusBadRequest, "invalidValue", detail)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func (a *API) meta(resourceType string, id int, created time.Time, lastModified time.Time) map[string]any {
+	return map[string]any{
+		"resourceType": resourceType,
+		"created":      formatTime(created),
+		"lastModified": formatTime(lastModified),
+		"location":     a.location(resourceType, id),
+	}
+}
+
+func (a *API) location(resourceType string, id int) string {
+	return a.baseURL + "/" + resourceType + "s/" + strconv.Itoa(id)
+}
+
+func (a *API) userResource(user *model.SCIMUser) map[string]any {
+	resource := map[string]any{
+		"schemas":  []any{userSchema},
+		"id":       strconv.Itoa(user.ID),
+		"userName": user.UserName,
+		"name": map[string]any{
+			"givenName":  user.GivenName,
+			"familyName": user.FamilyName,
+			"formatted":  strings.TrimSpace(user.GivenName + " " + user.FamilyName),
+		},
+		"emails": []any{map[string]any{
+			"value":   user.Email,
+			"type":    "work",
+			"primary": true,
+		}},
+		"active": user.Active,
+		"groups": lo.Map(user.Groups, func(group *model.SCIMGroup, _ int) any {
+			return map[string]any{
+				"value":   strconv.Itoa(group.ID),
+				"display": group.DisplayName,
+				"$ref":    a.location("Group", group.ID),
+			}
+		}),
+		"meta": a.meta("User", user.ID, user.CreatedAt, user.UpdatedAt),
+	}
+	if user.DisplayName != "" {
+		resource["displayName"] = user.DisplayName
+	}
+	if user.ExternalID != nil {
+		resource["externalId"] = *user.ExternalID
+	}
+	return resource
+}
+
+func (a *API) groupResource(group *model.SCIMGroup) map[string]any {
+	resource := map[string]any{
+		"schemas":     []any{groupSchema, groupExtensionSchema},
+		"id":          strconv.Itoa(group.ID),
+		"displayName": group.DisplayName,
+		"members": lo.Map(group.Members, func(user *model.SCIMUser, _ int) any {
+			return map[string]any{
+				"value":   strconv.Itoa(user.ID),
+				"display": user.UserName,
+				"type":    "User",
+				"$ref":    a.location("User", user.ID),
+			}
+		}),
+		groupExtensionSchema: map[string]any{
+			"role": group.Role,
+			"projectIds": lo.Map(group.ProjectIds, func(id int32, _ int) any {
+				return strconv.Itoa(int(id))
+			}),
+		},
+		"meta": a.meta("Group", group.ID, group.CreatedAt, group.UpdatedAt),
+	}
+	if group.ExternalID != nil {
+		resource["externalId"] = *group.ExternalID
+	}
+	return resource
+}
+
+func getString(resource map[string]any, path string) (string, error) {
+	container, key := resolvePath(resource, path, false)
+	if container == nil || container[key] == nil {
+		return "", nil
+	}
+	value, ok := container[key].(string)
+	if !ok {
+		return "", invalidValue(path + " must be a string")
+	}
+	return value, nil
+}
+
+// getBool also accepts the string booleans sent by some identity providers, e.g. Azure AD.
+func getBool(resource map[string]any, path string, fallback bool) (bool, error) {
+	container, key := resolvePath(resource, path, false)
+	if container == nil || container[key] == nil {
+		return fallback, nil
+	}
+	switch value := container[key].(type) {
+	case bool:
+		return value, nil
+	case string:
+		if parsed, err := strconv.ParseBool(strings.ToLower(value)); err == nil {
+			return parsed, nil
+		}
+	}
+	return false, invalidValue(path + " must be a boolean")
+}
+
+func getPrimaryEmail(resource map[string]any) (string, error) {
+	emails, _ := resource[findKey(resource, "emails")].([]any)
+	var email string
+	for _, value := range emails {
+		element, ok := value.(map[string]any)
+		if !ok {
+			return "", invalidValue("emails must be complex values")
+		}
+		address, _ := element[findKey(element, "value")].(string)
+		if primary, _ := element[findKey(element, "primary")].(bool); primary || email == "" {
+			email = address
+		}
+	}
+	return email, nil
+}
+
+func getIDs(values []any, path string) ([]int, error) {
+	var ids []int
+	for _, value := range values {
+		if element, ok := value.(map[string]any); ok {
+			value = element[findKey(element, "value")]
+		}
+		str, _ := value.(string)
+		id, err := strconv.Atoi(str)
+		if err != nil {
+			return nil, invalidValue(path + " must reference existing resources")
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// parseUser replaces the attributes of the user with those of the resource.
+func parseUser(resource map[string]any, user *model.SCIMUser) error {
+	var err error
+	if user.UserName, err = getString(resource, "userName"); err != nil {
+		return err
+	}
+	if user.UserName == "" {
+		return invalidValue("userName is required")
+	}
+
+	externalID, err := getString(resource, "externalId")
+	if err != nil {
+		return err
+	}
+	user.ExternalID = lo.EmptyableToPtr(externalID)
+	if user.GivenName, err = getString(resource, "name.givenName"); err != nil {
+		return err
+	}
+	if user.FamilyName, err = getString(resource, "name.familyName"); err != nil {
+		return err
+	}
+	if user.DisplayName, err = getString(resource, "displayName"); err != nil {
+		return err
+	}
+	if user.Active, err = getBool(resource, "active", true); err != nil {
+		return err
+	}
+
+	if user.Email, err = getPrimaryEmail(resource); err != nil {
+		return err
+	}
+	if user.Email == "" {
+		user.Email = user.UserName
+	}
+	if _, err := mail.ParseEmail(user.Email); err != nil {
+		return invalidValue("a valid email is required")
+	}
+	return nil
+}
+
+// parseGroup replaces the attributes of the group with those of the resource and returns the ids of its members.
+// The workspace role and project access of the group are only replaced when the highlight extension is set.
+func parseGroup(resource map[string]any, group *model.SCIMGroup) ([]int, error) {
+	var err error
+	if group.DisplayName, err = getString(resource, "displayName"); err != nil {
+		return nil, err
+	}
+	if group.DisplayName == "" {
+		return nil, invalidValue("displayName is required")
+	}
+
+	externalID, err := getString(resource, "externalId")
+	if err != nil {
+		return nil, err
+	}
+	group.ExternalID = lo.EmptyableToPtr(externalID)
+
+	members, _ := resource[findKey(resource, "members")].([]any)
+	memberIDs, err := getIDs(members, "members")
+	if err != nil {
+		return nil, err
+	}
+
+	if extension, ok := resource[findKey(resource, groupExtensionSchema)].(map[string]any); ok {
+		role, err := getString(extension, "role")
+		if err != nil {
+			return nil, err
+		}
+		if role != model.AdminRole.ADMIN && role != model.AdminRole.MEMBER {
+			return nil, invalidValue("role must be ADMIN or MEMBER")
+		}
+		projects, _ := extension[findKey(extension, "projectIds")].([]any)
+		projectIDs, err := getIDs(projects, "projectIds")
+		if err != nil {
+			return nil, err
+		}
+		group.Role = role
+		group.ProjectIds = nil
+		if role == model.AdminRole.MEMBER && len(projectIDs) > 0 {
+			group.ProjectIds = lo.Map(projectIDs, func(id int, _ int) int32 {
+				return int32(id)
+			})
+		}
+	}
+	return memberIDs, nil
+}
+
+// selectAttributes applies the attributes and excludedAttributes query parameters to the resource.
+// The id and schemas are always returned.
+func selectAttributes(resource map[string]any, attributes []string, excludedAttributes []string) map[string]any {
+	topLevel := func(path string) string {
+		if idx := strings.LastIndex(path, ":"); idx >= 0 {
+			if urn := path[:idx]; !isCoreSchema(urn) {
+				return urn
+			}
+			path = path[idx+1:]
+		}
+		return strings.Split(path, ".")[0]
+	}
+	if len(attributes) > 0 {
+		keep := lo.Map(attributes, func(attribute string, _ int) string { return topLevel(attribute) })
+		for key := range resource {
+			if key == "id" || key == "schemas" {
+				continue
+			}
+			if !lo.ContainsBy(keep, func(attribute string) bool { return strings.EqualFold(attribute, key) }) {
+				delete(resource, key)
+			}
+		}
+	}
+	for _, attribute := range excludedAttributes {
+		if key := findKey(resource, topLevel(attribute)); key != "id" && key != "schemas" {
+			delete(resource, key)
+		}
+	}
+	return resource
+}
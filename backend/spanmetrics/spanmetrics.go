@@ -0,0 +1,210 @@
+package spanmetrics
+
+import (
+	"time"
+
+	"github.com/highlight-run/highlight/backend/clickhouse"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+const (
+	CallsMetricName    = "span.calls"
+	ErrorsMetricName   = "span.errors"
+	DurationMetricName = "span.duration"
+
+	SpanNameAttribute   = "span.name"
+	SpanKindAttribute   = "span.kind"
+	StatusCodeAttribute = "status.code"
+	EnvironmentKey      = "environment"
+
+	// Spans are bucketed to this resolution before being aggregated.
+	BucketWindow = time.Minute
+	// Limit the number of trace exemplars stored per metric row.
+	MaxExemplars = 5
+)
+
+// DurationBoundsMs are the explicit histogram bounds for span durations, matching
+// the defaults of the OpenTelemetry collector spanmetrics connector.
+var DurationBoundsMs = []float64{2, 4, 6, 8, 10, 50, 100, 200, 400, 800, 1000, 1400, 2000, 5000, 10000, 15000}
+
+type key struct {
+	projectID      uint32
+	serviceName    string
+	serviceVersion string
+	environment    string
+	spanName       string
+	spanKind       string
+	statusCode     string
+	bucket         time.Time
+}
+
+type exemplar struct {
+	timestamp       time.Time
+	durationMs      float64
+	traceID         string
+	spanID          string
+	secureSessionID string
+}
+
+type aggregate struct {
+	calls        uint64
+	errors       uint64
+	sum          float64
+	min          float64
+	max          float64
+	bucketCounts []uint64
+	exemplars    []exemplar
+	errExemplars []exemplar
+}
+
+// Generator accumulates RED (rate, errors, duration) metrics from ingested spans.
+// It is not safe for concurrent use; create one per batch of spans.
+type Generator struct {
+	aggregates map[key]*aggregate
+}
+
+func NewGenerator() *Generator {
+	return &Generator{aggregates: make(map[key]*aggregate)}
+}
+
+func IsError(row *clickhouse.TraceRow) bool {
+	return row.HasErrors || row.StatusCode == ptrace.StatusCodeError.String()
+}
+
+func (g *Generator) Add(row *clickhouse.TraceRow) {
+	if row == nil || row.ProjectId == 0 {
+		return
+	}
+
+	k := key{
+		projectID:      row.ProjectId,
+		serviceName:    row.ServiceName,
+		serviceVersion: row.ServiceVersion,
+		environment:    row.Environment,
+		spanName:       row.SpanName,
+		spanKind:       row.SpanKind,
+		statusCode:     row.StatusCode,
+		bucket:         row.Timestamp.Truncate(BucketWindow),
+	}
+	agg, ok := g.aggregates[k]
+	if !ok {
+		agg = &aggregate{bucketCounts: make([]uint64, len(DurationBoundsMs)+1)}
+		g.aggregates[k] = agg
+	}
+
+	durationMs := float64(row.Duration) / float64(time.Millisecond)
+	if agg.calls == 0 || durationMs < agg.min {
+		agg.min = durationMs
+	}
+	if agg.calls == 0 || durationMs > agg.max {
+		agg.max = durationMs
+	}
+	agg.calls++
+	agg.sum += durationMs
+	agg.bucketCounts[bucketIndex(durationMs)]++
+
+	ex := exemplar{
+		timestamp:       row.Timestamp,
+		durationMs:      durationMs,
+		traceID:         row.TraceId,
+		spanID:          row.SpanId,
+		secureSessionID: row.SecureSessionId,
+	}
+	if len(agg.exemplars) < MaxExemplars {
+		agg.exemplars = append(agg.exemplars, ex)
+	}
+	if IsError(row) {
+		agg.errors++
+		if len(agg.errExemplars) < MaxExemplars {
+			agg.errExemplars = append(agg.errExemplars, ex)
+		}
+	}
+}
+
+func bucketIndex(durationMs float64) int {
+	for idx, bound := range DurationBoundsMs {
+		if durationMs <= bound {
+			return idx
+		}
+	}
+	return len(DurationBoundsMs)
+}
+
+// MetricRows returns the aggregated metrics keyed by project id, in the same shape
+// accepted by the otel metrics submission path. The retention callback is invoked
+// once per project.
+func (g *Generator) MetricRows(retention func(projectID int) uint8) map[int][]clickhouse.MetricRow {
+	retentionByProject := make(map[uint32]uint8)
+	rows := make(map[int][]clickhouse.MetricRow)
+	for k, agg := range g.aggregates {
+		retentionDays, ok := retentionByProject[k.projectID]
+		if !ok {
+			retentionDays = retention(int(k.projectID))
+			retentionByProject[k.projectID] = retentionDays
+		}
+
+		base := func(name, description, unit string, metricType pmetric.MetricType, exemplars []exemplar) clickhouse.MetricBaseRow {
+			row := clickhouse.MetricBaseRow{
+				ProjectId:         k.projectID,
+				ServiceName:       k.serviceName,
+				ServiceVersion:    k.serviceVersion,
+				MetricName:        name,
+				MetricDescription: description,
+				MetricUnit:        unit,
+				Attributes:        k.attributes(),
+				MetricType:        metricType,
+				Timestamp:         k.bucket,
+				StartTimestamp:    k.bucket,
+				RetentionDays:     retentionDays,
+			}
+			for _, ex := range exemplars {
+				row.ExemplarsAttributes = append(row.ExemplarsAttributes, map[string]string{})
+				row.ExemplarsTimestamp = append(row.ExemplarsTimestamp, ex.timestamp)
+				row.ExemplarsValue = append(row.ExemplarsValue, ex.durationMs)
+				row.ExemplarsTraceID = append(row.ExemplarsTraceID, ex.traceID)
+				row.ExemplarsSpanID = append(row.ExemplarsSpanID, ex.spanID)
+				row.ExemplarsSecureSessionID = append(row.ExemplarsSecureSessionID, ex.secureSessionID)
+			}
+			return row
+		}
+
+		projectID := int(k.projectID)
+		rows[projectID] = append(rows[projectID], &clickhouse.MetricSumRow{
+			MetricBaseRow:          base(CallsMetricName, "Number of spans ingested", "1", pmetric.MetricTypeSum, agg.exemplars),
+			Value:                  float64(agg.calls),
+			AggregationTemporality: int32(pmetric.AggregationTemporalityDelta),
+			IsMonotonic:            true,
+		}, &clickhouse.MetricHistogramRow{
+			MetricBaseRow:          base(DurationMetricName, "Duration of spans ingested", "ms", pmetric.MetricTypeHistogram, agg.exemplars),
+			Count:                  agg.calls,
+			Sum:                    agg.sum,
+			BucketCounts:           agg.bucketCounts,
+			ExplicitBounds:         DurationBoundsMs,
+			Min:                    agg.min,
+			Max:                    agg.max,
+			AggregationTemporality: int32(pmetric.AggregationTemporalityDelta),
+		})
+		if agg.errors > 0 {
+			rows[projectID] = append(rows[projectID], &clickhouse.MetricSumRow{
+				MetricBaseRow:          base(ErrorsMetricName, "Number of spans ingested with an error status", "1", pmetric.MetricTypeSum, agg.errExemplars),
+				Value:                  float64(agg.errors),
+				AggregationTemporality: int32(pmetric.AggregationTemporalityDelta),
+				IsMonotonic:            true,
+			})
+		}
+	}
+	return rows
+}
+
+func (k key) attributes() map[string]string {
+	attrs := map[string]string{
+		SpanNameAttribute:   k.spanName,
+		SpanKindAttribute:   k.spanKind,
+		StatusCodeAttribute: k.statusCode,
+	}
+	if k.environment != "" {
+		attrs[EnvironmentKey] = k.environment
+	}
+	return attrs
+}
@@ -0,0 +1,85 @@
+package spanmetrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/highlight-run/highlight/backend/clickhouse"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSpan(ts time.Time, name string, duration time.Duration, statusCode string) *clickhouse.TraceRow {
+	row := clickhouse.NewTraceRow(ts, 1).
+		WithTraceId("trace-"+name).
+		WithSpanId("span-"+name).
+		WithSpanName(name).
+		WithSpanKind("Server").
+		WithServiceName("api").
+		WithStatusCode(statusCode).
+		WithDuration(ts, ts.Add(duration))
+	return row
+}
+
+func TestGenerator_MetricRows(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 12, 0, 30, 0, time.UTC)
+	g := NewGenerator()
+	g.Add(newSpan(ts, "GET /users", 5*time.Millisecond, "Ok"))
+	g.Add(newSpan(ts.Add(time.Second), "GET /users", 500*time.Millisecond, "Ok"))
+	g.Add(newSpan(ts, "GET /users", 20*time.Second, "Error"))
+	// a span from another project with no id is ignored
+	g.Add(&clickhouse.TraceRow{})
+
+	var retentionCalls int
+	rows := g.MetricRows(func(projectID int) uint8 {
+		retentionCalls++
+		return 30
+	})
+	assert.Equal(t, 1, retentionCalls)
+	assert.Len(t, rows, 1)
+
+	var calls, errors float64
+	var histograms []*clickhouse.MetricHistogramRow
+	for _, row := range rows[1] {
+		switch r := row.(type) {
+		case *clickhouse.MetricSumRow:
+			assert.Equal(t, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), r.Timestamp)
+			assert.Equal(t, uint8(30), r.RetentionDays)
+			assert.Equal(t, "GET /users", r.Attributes[SpanNameAttribute])
+			if r.MetricName == CallsMetricName {
+				calls += r.Value
+			} else if r.MetricName == ErrorsMetricName {
+				errors += r.Value
+				assert.Equal(t, []string{"trace-GET /users"}, r.ExemplarsTraceID)
+			}
+		case *clickhouse.MetricHistogramRow:
+			histograms = append(histograms, r)
+		}
+	}
+	assert.Equal(t, 3., calls)
+	assert.Equal(t, 1., errors)
+	assert.Len(t, histograms, 2)
+
+	for _, h := range histograms {
+		assert.Equal(t, DurationMetricName, h.MetricName)
+		assert.Len(t, h.BucketCounts, len(DurationBoundsMs)+1)
+		if h.Attributes[StatusCodeAttribute] == "Ok" {
+			assert.Equal(t, uint64(2), h.Count)
+			assert.Equal(t, 505., h.Sum)
+			assert.Equal(t, 5., h.Min)
+			assert.Equal(t, 500., h.Max)
+			assert.Equal(t, uint64(1), h.BucketCounts[bucketIndex(5)])
+			assert.Equal(t, uint64(1), h.BucketCounts[bucketIndex(500)])
+			assert.Len(t, h.ExemplarsTraceID, 2)
+		} else {
+			assert.Equal(t, uint64(1), h.Count)
+			assert.Equal(t, uint64(1), h.BucketCounts[len(DurationBoundsMs)])
+		}
+	}
+}
+
+func TestBucketIndex(t *testing.T) {
+	assert.Equal(t, 0, bucketIndex(0))
+	assert.Equal(t, 0, bucketIndex(2))
+	assert.Equal(t, 1, bucketIndex(2.5))
+	assert.Equal(t, len(DurationBoundsMs), bucketIndex(15001))
+}
@@ -18,7 +18,6 @@ import (
 	"time"
 
 	"github.com/andybalholm/brotli"
-	"github.com/go-sourcemap/sourcemap"
 	privateModel "github.com/highlight-run/highlight/backend/private-graph/graph/model"
 	publicModel "github.com/highlight-run/highlight/backend/public-graph/graph/model"
 	"github.com/highlight-run/highlight/backend/storage"
@@ -155,10 +154,23 @@ func EnhanceStackTrace(ctx context.Context, input []*publicModel.StackFrameInput
 	}
 
 	var mappedStackTrace []*privateModel.ErrorTrace
+	var proguardMapping *proguardMapping
+	var proguardMappingLoaded bool
 	for idx, stackFrame := range input {
 		if idx >= ERROR_STACK_MAX_FRAME_COUNT {
 			break
 		}
+		// obfuscated android frames are retraced with the R8/ProGuard mapping of the app version
+		if isJVMFrame(stackFrame) {
+			if !proguardMappingLoaded {
+				proguardMapping = getProguardMapping(ctx, projectId, version, storageClient)
+				proguardMappingLoaded = true
+			}
+			if proguardMapping != nil {
+				mappedStackTrace = append(mappedStackTrace, retraceJVMFrame(proguardMapping, *stackFrame)...)
+				continue
+			}
+		}
 		if stackFrame == nil || (stackFrame.FileName == nil || len(*stackFrame.FileName) < 1 || stackFrame.LineNumber == nil || stackFrame.ColumnNumber == nil) {
 			continue
 		}
@@ -300,6 +312,18 @@ func getURLSourcemap(ctx context.Context, projectId int, version *string, stackT
 			err := e.Errorf("error parsing source map url: %s", sourceMapURL)
 			return "", nil, err
 		}
+
+		// a sourcemap uploaded for the debug id of the minified file is found regardless of its path and version
+		if debugID := getDebugID(minifiedFileBytes); debugID != "" {
+			debugIDFileName := debugIDSourceMapFileName(debugID)
+			sourceMapFileBytes, err = storageClient.ReadSourceMapFileCached(ctx, projectId, pointy.String(DEBUG_ID_SOURCEMAP_VERSION), debugIDFileName)
+			if sourceMapFileBytes != nil && err == nil {
+				stackTraceError.SourcemapFetchStrategy = pointy.String("Debug ID")
+				stackTraceError.ActualSourcemapFetchedPath = &debugIDFileName
+				return sourceMapURL, sourceMapFileBytes, nil
+			}
+		}
+
 		sourceMapFilePath := u2.Path
 		if len(sourceMapFilePath) > 1 && sourceMapFilePath[0:1] == "/" {
 			sourceMapFilePath = sourceMapFilePath[1:]
@@ -327,7 +351,7 @@ func getURLSourcemap(ctx context.Context, projectId int, version *string, stackT
 				err := e.Wrapf(err, "error fetching source map file: %v", sourceMapURL)
 				return "", nil, err
 			}
-			smap, err := sourcemap.Parse(sourceMapURL, sourceMapFileBytes)
+			smap, err := parseSourceMap(sourceMapURL, sourceMapFileBytes)
 			if err != nil || smap == nil {
 				// what we expected to be a source map is not. don't store it in s3
 				// SOURCEMAP_ERROR: sourcemap library could not parse the source map file
@@ -341,6 +365,12 @@ func getURLSourcemap(ctx context.Context, projectId int, version *string, stackT
 			if err != nil {
 				log.WithContext(ctx).Error(e.Wrapf(err, "error pushing file to s3: %v", sourceMapFileName))
 			}
+			if debugID := getSourceMapDebugID(sourceMapFileBytes); debugID != "" {
+				_, err = storageClient.PushSourceMapFile(ctx, projectId, pointy.String(DEBUG_ID_SOURCEMAP_VERSION), debugIDSourceMapFileName(debugID), sourceMapFileBytes)
+				if err != nil {
+					log.WithContext(ctx).Error(e.Wrapf(err, "error pushing file to s3: %v", debugID))
+				}
+			}
 		}
 	}
 	return sourceMapURL, sourceMapFileBytes, nil
@@ -451,7 +481,7 @@ func processStackFrame(ctx context.Context, projectId int, version *string, stac
 		err := e.Errorf("source map file over %dmb: %v, size: %v", int(SOURCE_MAP_MAX_FILE_SIZE/1e6), stackTraceFilePath, sourceMapFileSize)
 		return nil, err, stackTraceError
 	}
-	smap, err := sourcemap.Parse(sourceMapURL, sourceMapFileBytes)
+	smap, err := parseSourceMap(sourceMapURL, sourceMapFileBytes)
 	if err != nil {
 		// SOURCEMAP_ERROR: the sourcemap library couldn't parse
 		// the source map with the input URL and file content
@@ -423,3 +423,44 @@ func TestEnhanceStackTraceProd(t *testing.T) {
 	assert.Equal(t, "", *mappedStackTrace[0].FunctionName)
 	assert.Equal(t, "      console.error(`Supplementary data not found for identifier ${identifier}`);\n", *mappedStackTrace[0].LineContent)
 }
+
+func TestEnhanceStackTraceDebugIDAndIndexedSourcemaps(t *testing.T) {
+	ctx := context.Background()
+	client, err := storage.NewFSClient(ctx, "http://localhost:8082/public", t.TempDir())
+	if err != nil {
+		t.Fatalf("error creating storage client: %v", err)
+	}
+	fetch = DiskFetcher{}
+
+	// the sourcemap is only uploaded by the debug id, so the path of the minified file does not matter
+	_, err = client.PushSourceMapFile(ctx, 1, pointy.String(DEBUG_ID_SOURCEMAP_VERSION), "85314830-023f-4cf1-a267-535f4e37bb17.map", []byte(`{
+		"version": 3, "sources": ["src/greet.ts"], "names": [], "mappings": "AAAA,kBACC",
+		"sourcesContent": ["export function greet(name: string) {\n\tthrow new Error('hello ' + name)\n}\n"],
+		"debugId": "85314830-023f-4cf1-a267-535f4e37bb17"
+	}`))
+	assert.NoError(t, err)
+
+	mappedStackTrace, err := EnhanceStackTrace(ctx, []*publicModelInput.StackFrameInput{
+		{FileName: ptr.String("./test-files/debug-id.min.js"), LineNumber: ptr.Int(1), ColumnNumber: ptr.Int(18)},
+		{FileName: ptr.String("./test-files/indexed.min.js"), LineNumber: ptr.Int(1), ColumnNumber: ptr.Int(5)},
+		{FileName: ptr.String("./test-files/indexed.min.js"), LineNumber: ptr.Int(1), ColumnNumber: ptr.Int(36)},
+	}, 1, pointy.String("v1"), client)
+	if err != nil {
+		t.Fatal(e.Wrap(err, "error enhancing source map"))
+	}
+	assert.Equal(t, 3, len(mappedStackTrace))
+
+	assert.Nil(t, mappedStackTrace[0].Error)
+	assert.Equal(t, "src/greet.ts", *mappedStackTrace[0].FileName)
+	assert.Equal(t, 2, *mappedStackTrace[0].LineNumber)
+	assert.Equal(t, "\tthrow new Error('hello ' + name)\n", *mappedStackTrace[0].LineContent)
+
+	// the frames are mapped with the section of the indexed sourcemap that contains them
+	assert.Nil(t, mappedStackTrace[1].Error)
+	assert.Equal(t, "src/a.ts", *mappedStackTrace[1].FileName)
+	assert.Equal(t, 1, *mappedStackTrace[1].LineNumber)
+	assert.Nil(t, mappedStackTrace[2].Error)
+	assert.Equal(t, "src/b.ts", *mappedStackTrace[2].FileName)
+	assert.Equal(t, 2, *mappedStackTrace[2].LineNumber)
+	assert.Equal(t, "\tthrow new Error('b')\n", *mappedStackTrace[2].LineContent)
+}
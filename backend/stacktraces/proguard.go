@@ -0,0 +1,270 @@
+package stacktraces
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	privateModel "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	publicModel "github.com/highlight-run/highlight/backend/public-graph/graph/model"
+	"github.com/highlight-run/highlight/backend/storage"
+	"github.com/openlyinc/pointy"
+	e "github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// PROGUARD_MAPPING_FILE_NAME is the name of the Android R8/ProGuard mapping file uploaded for an app version.
+const PROGUARD_MAPPING_FILE_NAME = "mapping.txt"
+
+var (
+	proguardClassPattern  = regexp.MustCompile(`^(\S+) -> (\S+):$`)
+	proguardMemberPattern = regexp.MustCompile(`^(?:(\d+):(\d+):)?(\S+) ([^\s(]+)\(([^)]*)\)(?::(\d+)(?::(\d+))?)? -> (\S+)$`)
+	// file names of the frames of an obfuscated JVM stack trace. R8 replaces the source file with
+	// `SourceFile` or the id of the mapping, unless the source file attribute is kept.
+	jvmFileNamePattern = regexp.MustCompile(`^(SourceFile|Unknown Source|r8-map-id-[0-9a-f]+|[\w$-]+\.(java|kt|scala|groovy))$`)
+)
+
+// parsed mappings are cached as they are large and used for every error of the app version
+var proguardMappingCache = expirable.NewLRU[string, *proguardMapping](32, nil, 10*time.Minute)
+
+type proguardMember struct {
+	// startLine and endLine are the obfuscated line range of the member, or 0 when the mapping has no line numbers
+	startLine int
+	endLine   int
+	// originalClass is set when the method was inlined from another class
+	originalClass     string
+	originalName      string
+	originalStartLine int
+	originalEndLine   int
+}
+
+type proguardClass struct {
+	originalName string
+	sourceFile   string
+	// members by obfuscated name, in the order of the mapping file
+	members map[string][]proguardMember
+}
+
+// proguardMapping is a parsed R8/ProGuard mapping.txt.
+type proguardMapping struct {
+	classes         map[string]*proguardClass
+	originalClasses map[string]*proguardClass
+}
+
+// retracedFrame is an original frame of an obfuscated JVM frame.
+type retracedFrame struct {
+	className  string
+	methodName string
+	fileName   string
+	lineNumber int
+}
+
+func parseProguardMapping(mappingFileBytes []byte) (*proguardMapping, error) {
+	mapping := &proguardMapping{
+		classes:         map[string]*proguardClass{},
+		originalClasses: map[string]*proguardClass{},
+	}
+	var class *proguardClass
+	scanner := bufio.NewScanner(bytes.NewReader(mappingFileBytes))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			// R8 records the original source file of the class as metadata after the class line
+			var metadata struct {
+				ID       string `json:"id"`
+				FileName string `json:"fileName"`
+			}
+			if class != nil && json.Unmarshal([]byte(strings.TrimSpace(line[1:])), &metadata) == nil && metadata.ID == "sourceFile" {
+				class.sourceFile = metadata.FileName
+			}
+			continue
+		}
+		if matches := proguardClassPattern.FindStringSubmatch(line); matches != nil {
+			class = &proguardClass{originalName: matches[1], members: map[string][]proguardMember{}}
+			mapping.classes[matches[2]] = class
+			mapping.originalClasses[matches[1]] = class
+			continue
+		}
+		matches := proguardMemberPattern.FindStringSubmatch(line)
+		if class == nil || matches == nil {
+			// fields and unsupported lines are not needed to retrace frames
+			continue
+		}
+		member := proguardMember{originalName: matches[4]}
+		member.startLine, _ = strconv.Atoi(matches[1])
+		member.endLine, _ = strconv.Atoi(matches[2])
+		member.originalStartLine, _ = strconv.Atoi(matches[6])
+		member.originalEndLine, _ = strconv.Atoi(matches[7])
+		if idx := strings.LastIndex(member.originalName, "."); idx != -1 {
+			member.originalClass, member.originalName = member.originalName[:idx], member.originalName[idx+1:]
+		}
+		class.members[matches[8]] = append(class.members[matches[8]], member)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, e.Wrap(err, "error reading proguard mapping")
+	}
+	if len(mapping.classes) == 0 {
+		return nil, e.New("proguard mapping has no classes")
+	}
+	return mapping, nil
+}
+
+// originalLine returns the line in the original source of the obfuscated line of the member.
+func (m proguardMember) originalLine(line int) int {
+	if m.originalStartLine == 0 {
+		// without an original range, the obfuscated lines are the original lines
+		return line
+	}
+	if m.startLine > 0 && m.originalEndLine-m.originalStartLine == m.endLine-m.startLine {
+		return m.originalStartLine + line - m.startLine
+	}
+	return m.originalStartLine
+}
+
+func (m *proguardMapping) sourceFile(className string) string {
+	if class, ok := m.originalClasses[className]; ok && class.sourceFile != "" {
+		return class.sourceFile
+	}
+	name := className[strings.LastIndex(className, ".")+1:]
+	if idx := strings.Index(name, "$"); idx > 0 {
+		name = name[:idx]
+	}
+	return fmt.Sprintf("%s.java", name)
+}
+
+// retrace returns the original frames of an obfuscated frame, the innermost inlined method first.
+// It returns nil when the class is not obfuscated by the mapping.
+func (m *proguardMapping) retrace(className, methodName string, line int) []retracedFrame {
+	class, ok := m.classes[className]
+	if !ok {
+		return nil
+	}
+	members := class.members[methodName]
+
+	// methods inlined into the obfuscated method share its line range, the inlined method first
+	var matched []proguardMember
+	for _, member := range members {
+		if member.startLine > 0 && member.startLine <= line && line <= member.endLine {
+			matched = append(matched, member)
+		}
+	}
+	if len(matched) == 0 {
+		for _, member := range members {
+			if member.startLine == 0 {
+				matched = append(matched, member)
+				break
+			}
+		}
+	}
+	if len(matched) == 0 && len(members) > 0 {
+		matched = members[:1]
+	}
+	if len(matched) == 0 {
+		// the method is not renamed, only its class
+		return []retracedFrame{{
+			className:  class.originalName,
+			methodName: methodName,
+			fileName:   m.sourceFile(class.originalName),
+			lineNumber: line,
+		}}
+	}
+
+	var frames []retracedFrame
+	for _, member := range matched {
+		originalClass := class.originalName
+		if member.originalClass != "" {
+			originalClass = member.originalClass
+		}
+		frame := retracedFrame{
+			className:  originalClass,
+			methodName: member.originalName,
+			fileName:   m.sourceFile(originalClass),
+		}
+		// the line of frames without a line number cannot be retraced
+		if line > 0 {
+			frame.lineNumber = member.originalLine(line)
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+// isJVMFrame returns whether the frame is of a JVM stack trace, with a class qualified method name.
+func isJVMFrame(stackFrame *publicModel.StackFrameInput) bool {
+	if stackFrame == nil || stackFrame.FileName == nil || stackFrame.FunctionName == nil {
+		return false
+	}
+	return strings.Contains(*stackFrame.FunctionName, ".") && jvmFileNamePattern.MatchString(*stackFrame.FileName)
+}
+
+// getProguardMapping returns the parsed mapping uploaded for the app version,
+// falling back to the unversioned mapping. It returns nil when no mapping was uploaded.
+func getProguardMapping(ctx context.Context, projectId int, version *string, storageClient storage.Client) *proguardMapping {
+	var versions = []*string{version}
+	if versions[0] != nil {
+		versions = append(versions, nil)
+	}
+	for _, v := range versions {
+		cacheKey := fmt.Sprintf("%d/%s", projectId, pointy.StringValue(v, ""))
+		if mapping, ok := proguardMappingCache.Get(cacheKey); ok {
+			if mapping != nil {
+				return mapping
+			}
+			continue
+		}
+
+		mappingFileBytes, err := storageClient.ReadSourceMapFileCached(ctx, projectId, v, PROGUARD_MAPPING_FILE_NAME)
+		var mapping *proguardMapping
+		if err == nil && mappingFileBytes != nil {
+			mapping, err = parseProguardMapping(mappingFileBytes)
+			if err != nil {
+				log.WithContext(ctx).WithError(err).WithField("project_id", projectId).Warn("failed to parse proguard mapping")
+			}
+		}
+		proguardMappingCache.Add(cacheKey, mapping)
+		if mapping != nil {
+			return mapping
+		}
+	}
+	return nil
+}
+
+// retraceJVMFrame rewrites an obfuscated JVM frame to its original class, method and line,
+// expanding the frames of the methods inlined by R8.
+func retraceJVMFrame(mapping *proguardMapping, stackFrame publicModel.StackFrameInput) []*privateModel.ErrorTrace {
+	functionName := *stackFrame.FunctionName
+	idx := strings.LastIndex(functionName, ".")
+	className, methodName := functionName[:idx], functionName[idx+1:]
+	line := pointy.IntValue(stackFrame.LineNumber, 0)
+
+	frames := mapping.retrace(className, methodName, line)
+	if frames == nil {
+		return []*privateModel.ErrorTrace{{
+			FileName:     limitMaxSize(stackFrame.FileName),
+			LineNumber:   stackFrame.LineNumber,
+			FunctionName: limitMaxSize(stackFrame.FunctionName),
+			ColumnNumber: stackFrame.ColumnNumber,
+		}}
+	}
+
+	var result []*privateModel.ErrorTrace
+	for _, frame := range frames {
+		result = append(result, &privateModel.ErrorTrace{
+			FileName:     pointy.String(frame.fileName),
+			LineNumber:   pointy.Int(frame.lineNumber),
+			FunctionName: limitMaxSize(pointy.String(fmt.Sprintf("%s.%s", frame.className, frame.methodName))),
+		})
+	}
+	return result
+}
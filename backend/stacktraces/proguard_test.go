@@ -0,0 +1,85 @@
+package stacktraces
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/smithy-go/ptr"
+	publicModelInput "github.com/highlight-run/highlight/backend/public-graph/graph/model"
+	"github.com/highlight-run/highlight/backend/storage"
+	"github.com/openlyinc/pointy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testProguardMapping = `# compiler: R8
+# pg_map_id: 5b46fdc
+com.example.app.CheckoutActivity -> a.b.c:
+# {"id":"sourceFile","fileName":"CheckoutActivity.kt"}
+    int retries -> a
+    1:4:void onCreate(android.os.Bundle):21:24 -> onCreate
+    5:5:void validate(java.lang.String):40:40 -> d
+    5:5:void com.example.app.Cart.total():12:12 -> d
+    5:5:void submit():30 -> d
+    6:9:void submit():31:34 -> d
+    void reset() -> e
+com.example.app.Cart$Item -> a.b.e:
+    1:1:int price() -> a
+`
+
+func TestRetraceProguardMapping(t *testing.T) {
+	mapping, err := parseProguardMapping([]byte(testProguardMapping))
+	require.NoError(t, err)
+
+	// inlined methods are expanded to a frame each, the innermost first
+	assert.Equal(t, []retracedFrame{
+		{className: "com.example.app.CheckoutActivity", methodName: "validate", fileName: "CheckoutActivity.kt", lineNumber: 40},
+		{className: "com.example.app.Cart", methodName: "total", fileName: "Cart.java", lineNumber: 12},
+		{className: "com.example.app.CheckoutActivity", methodName: "submit", fileName: "CheckoutActivity.kt", lineNumber: 30},
+	}, mapping.retrace("a.b.c", "d", 5))
+
+	assert.Equal(t, []retracedFrame{
+		{className: "com.example.app.CheckoutActivity", methodName: "submit", fileName: "CheckoutActivity.kt", lineNumber: 33},
+	}, mapping.retrace("a.b.c", "d", 8))
+	assert.Equal(t, []retracedFrame{
+		{className: "com.example.app.CheckoutActivity", methodName: "onCreate", fileName: "CheckoutActivity.kt", lineNumber: 23},
+	}, mapping.retrace("a.b.c", "onCreate", 3))
+	assert.Equal(t, []retracedFrame{
+		{className: "com.example.app.CheckoutActivity", methodName: "reset", fileName: "CheckoutActivity.kt"},
+	}, mapping.retrace("a.b.c", "e", 0))
+	assert.Equal(t, []retracedFrame{
+		{className: "com.example.app.Cart$Item", methodName: "price", fileName: "Cart.java", lineNumber: 1},
+	}, mapping.retrace("a.b.e", "a", 1))
+
+	// classes that are not obfuscated are not retraced
+	assert.Nil(t, mapping.retrace("android.os.Handler", "dispatchMessage", 106))
+}
+
+func TestEnhanceStackTraceProguard(t *testing.T) {
+	ctx := context.Background()
+	client, err := storage.NewFSClient(ctx, "http://localhost:8082/public", t.TempDir())
+	require.NoError(t, err)
+	_, err = client.PushSourceMapFile(ctx, 1, pointy.String("1.2.3"), PROGUARD_MAPPING_FILE_NAME, []byte(testProguardMapping))
+	require.NoError(t, err)
+
+	mappedStackTrace, err := EnhanceStackTrace(ctx, []*publicModelInput.StackFrameInput{
+		{FunctionName: ptr.String("a.b.c.d"), FileName: ptr.String("SourceFile"), LineNumber: ptr.Int(5)},
+		{FunctionName: ptr.String("android.os.Handler.dispatchMessage"), FileName: ptr.String("Handler.java"), LineNumber: ptr.Int(106)},
+	}, 1, pointy.String("1.2.3"), client)
+	require.NoError(t, err)
+
+	var functions []string
+	var lines []int
+	for _, frame := range mappedStackTrace {
+		functions = append(functions, *frame.FunctionName)
+		lines = append(lines, *frame.LineNumber)
+	}
+	assert.Equal(t, []string{
+		"com.example.app.CheckoutActivity.validate",
+		"com.example.app.Cart.total",
+		"com.example.app.CheckoutActivity.submit",
+		"android.os.Handler.dispatchMessage",
+	}, functions)
+	assert.Equal(t, []int{40, 12, 30, 106}, lines)
+	assert.Equal(t, "CheckoutActivity.kt", *mappedStackTrace[0].FileName)
+}
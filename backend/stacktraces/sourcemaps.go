@@ -0,0 +1,141 @@
+package stacktraces
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-sourcemap/sourcemap"
+	e "github.com/pkg/errors"
+)
+
+// DEBUG_ID_SOURCEMAP_VERSION is the version under which sourcemaps are uploaded by their debug id,
+// so that they are found regardless of the release version and the path the minified file is served from.
+const DEBUG_ID_SOURCEMAP_VERSION = "debug-id"
+
+// the TC39 debug id comment, see https://github.com/tc39/source-map/blob/main/proposals/debug-id.md
+var debugIDRegex = regexp.MustCompile(`(?m)^//# debugId=([0-9a-fA-F-]+)\s*$`)
+
+// getDebugID returns the normalized debug id of a minified file, or an empty string if it has none.
+func getDebugID(minifiedFileBytes []byte) string {
+	matches := debugIDRegex.FindAllSubmatch(minifiedFileBytes, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	// the last comment of the file applies, as with sourceMappingURL
+	return normalizeDebugID(string(matches[len(matches)-1][1]))
+}
+
+// getSourceMapDebugID returns the normalized debug id of a sourcemap, or an empty string if it has none.
+func getSourceMapDebugID(sourceMapFileBytes []byte) string {
+	var sourceMap struct {
+		DebugID string `json:"debugId"`
+		// emitted by tools that predate the proposal
+		DeprecatedDebugID string `json:"debug_id"`
+	}
+	if err := json.Unmarshal(sourceMapFileBytes, &sourceMap); err != nil {
+		return ""
+	}
+	if sourceMap.DebugID != "" {
+		return normalizeDebugID(sourceMap.DebugID)
+	}
+	return normalizeDebugID(sourceMap.DeprecatedDebugID)
+}
+
+func normalizeDebugID(debugID string) string {
+	return strings.ToLower(strings.TrimSpace(debugID))
+}
+
+// debugIDSourceMapFileName is the file name of the sourcemap uploaded for a debug id.
+func debugIDSourceMapFileName(debugID string) string {
+	return fmt.Sprintf("%s.map", debugID)
+}
+
+// sourceMapConsumer is implemented by a parsed regular or indexed sourcemap.
+type sourceMapConsumer interface {
+	Source(genLine, genColumn int) (source, name string, line, column int, ok bool)
+	SourceContent(source string) string
+}
+
+type sourceMapSection struct {
+	line     int
+	column   int
+	consumer *sourcemap.Consumer
+}
+
+// indexedSourceMap is a sourcemap made of sections, each mapping the generated code from its offset.
+type indexedSourceMap struct {
+	sections []sourceMapSection
+}
+
+// Source returns the original position in the section that contains the generated position.
+// Section offsets are 0-based while generated lines are 1-based. The column offset only applies
+// to the first line of a section.
+func (m *indexedSourceMap) Source(genLine, genColumn int) (source, name string, line, column int, ok bool) {
+	idx := sort.Search(len(m.sections), func(i int) bool {
+		s := m.sections[i]
+		return s.line > genLine-1 || (s.line == genLine-1 && s.column > genColumn)
+	}) - 1
+	if idx < 0 {
+		return
+	}
+	s := m.sections[idx]
+	if s.line == genLine-1 {
+		genColumn -= s.column
+	}
+	return s.consumer.Source(genLine-s.line, genColumn)
+}
+
+func (m *indexedSourceMap) SourceContent(source string) string {
+	for _, s := range m.sections {
+		if content := s.consumer.SourceContent(source); content != "" {
+			return content
+		}
+	}
+	return ""
+}
+
+// parseSourceMap parses a regular or an indexed sourcemap.
+// Sections of indexed sourcemaps are parsed individually as the sourcemap library
+// does not apply section offsets correctly.
+func parseSourceMap(sourceMapURL string, sourceMapFileBytes []byte) (sourceMapConsumer, error) {
+	var indexed struct {
+		Sections []struct {
+			Offset struct {
+				Line   int `json:"line"`
+				Column int `json:"column"`
+			} `json:"offset"`
+			URL string          `json:"url"`
+			Map json.RawMessage `json:"map"`
+		} `json:"sections"`
+	}
+	if err := json.Unmarshal(sourceMapFileBytes, &indexed); err != nil {
+		return nil, err
+	}
+	if len(indexed.Sections) == 0 {
+		return sourcemap.Parse(sourceMapURL, sourceMapFileBytes)
+	}
+
+	result := &indexedSourceMap{}
+	for _, section := range indexed.Sections {
+		if len(section.Map) == 0 {
+			return nil, e.Errorf("indexed sourcemap section at %d:%d does not embed its map (url %q)", section.Offset.Line, section.Offset.Column, section.URL)
+		}
+		consumer, err := sourcemap.Parse(sourceMapURL, section.Map)
+		if err != nil {
+			return nil, e.Wrapf(err, "error parsing indexed sourcemap section at %d:%d", section.Offset.Line, section.Offset.Column)
+		}
+		result.sections = append(result.sections, sourceMapSection{
+			line:     section.Offset.Line,
+			column:   section.Offset.Column,
+			consumer: consumer,
+		})
+	}
+	sort.SliceStable(result.sections, func(i, j int) bool {
+		a, b := result.sections[i], result.sections[j]
+		return a.line < b.line || (a.line == b.line && a.column < b.column)
+	})
+	return result, nil
+}
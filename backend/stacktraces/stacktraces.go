@@ -21,6 +21,7 @@ const Python Language = "python"
 const Golang Language = "golang"
 const DotNET Language = "dotnet"
 const Ruby Language = "ruby"
+const Java Language = "java"
 
 var (
 	jsPattern               = regexp.MustCompile(` {4}at ((.+) )?\(?(.+):(\d+):(\d+)\)?`)
@@ -37,6 +38,8 @@ var (
 	dotnetCSPattern         = regexp.MustCompile(`\.cs`)
 	dotnetExceptionPattern  = regexp.MustCompile(`^([\w.]+: .+?)( at .+)?$`)
 	dotnetFilePattern       = regexp.MustCompile(`^\s*at (.+?)(?: in (.+?)(?::line (\d+))?)?$`)
+	javaPattern             = regexp.MustCompile(`^\s*at (?:[^\s/()]*/)*([\w$.<>-]+\.[\w$<>-]+)\(([^:()]+)(?::(\d+))?\)`)
+	javaSkipPattern         = regexp.MustCompile(`^\s*(Caused by: |Suppressed: |\.\.\. \d+ (more|common frames omitted)$)`)
 	generalPattern          = regexp.MustCompile(`^(.+)`)
 )
 
@@ -117,6 +120,17 @@ func StructureOTELStackTrace(stackTrace string, opts ...StructureStackTraceOptio
 			frame.FileName = pointy.String(string(matches[2]))
 			line, _ := strconv.ParseInt(string(matches[3]), 10, 32)
 			frame.LineNumber = pointy.Int(int(line))
+		} else if matches := javaPattern.FindSubmatch([]byte(line)); language != Golang && matches != nil {
+			language = Java
+			frame.FunctionName = pointy.String(string(matches[1]))
+			frame.FileName = pointy.String(string(matches[2]))
+			if matches[3] != nil {
+				line, _ := strconv.ParseInt(string(matches[3]), 10, 32)
+				frame.LineNumber = pointy.Int(int(line))
+			}
+		} else if matches := javaSkipPattern.FindSubmatch([]byte(line)); language == Java && matches != nil {
+			// frames of the causes follow their header
+			continue
 		} else if matches := jsPattern.FindSubmatch([]byte(line)); matches != nil {
 			language = Javascript
 			if cfg.FromOTeL {
@@ -189,7 +203,7 @@ func StructureOTELStackTrace(stackTrace string, opts ...StructureStackTraceOptio
 	}
 	// for some non-otel-native errors, stacktraces are sent top-down (top frame is most outer; bottom frame is most inner)
 	// our backend expects to store stack traces in the opposite order, so we have to reverse it before returning.
-	if language != JavascriptOTeL && language != Golang && language != DotNET && language != Ruby {
+	if language != JavascriptOTeL && language != Golang && language != DotNET && language != Ruby && language != Java {
 		for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
 			frames[i], frames[j] = frames[j], frames[i]
 		}
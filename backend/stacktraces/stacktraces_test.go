@@ -40,6 +40,7 @@ func TestFormatStructureStackTrace(t *testing.T) {
 		{language: "node.js-console", stacktrace: "\"Error\\n    at console.<computed> [as error] (webpack-internal:///(api)/../../sdk/highlight-node/dist/index.mjs:194:15)\\n    at DevServer.logErrorWithOriginalStack (/Users/vkorolik/work/highlight/e2e/nextjs/node_modules/next/dist/server/dev/next-dev-server.js:803:71)\\n    at processTicksAndRejections (node:internal/process/task_queues:96:5)\"", expectedFrameError: "Error"},
 		{language: ".NET", stacktrace: "System.Exception: oh no, a random error occurred 1a77a6d6-4803-4de8-822b-13a62397b9d3\n   at Program.<>c__DisplayClass0_0.<<Main>$>b__2() in /home/vkorolik/work/highlight/e2e/dotnet/Program.cs:line 89\n   at lambda_method3(Closure, Object, HttpContext)\n   at Microsoft.AspNetCore.HttpsPolicy.HttpsRedirectionMiddleware.Invoke(HttpContext context) in /_/src/aspnetcore/artifacts/source-build/self/src/src/Middleware/HttpsPolicy/src/HttpsRedirectionMiddleware.cs:line 88\n   at Microsoft.AspNetCore.StaticFiles.StaticFileMiddleware.Invoke(HttpContext context) in /_/src/aspnetcore/artifacts/source-build/self/src/src/Middleware/StaticFiles/src/StaticFileMiddleware.cs:line 82\n   at Swashbuckle.AspNetCore.SwaggerUI.SwaggerUIMiddleware.Invoke(HttpContext httpContext)\n   at Swashbuckle.AspNetCore.Swagger.SwaggerMiddleware.Invoke(HttpContext httpContext, ISwaggerProvider swaggerProvider)\n   at Microsoft.AspNetCore.Diagnostics.DeveloperExceptionPageMiddlewareImpl.Invoke(HttpContext context) in /_/src/aspnetcore/artifacts/source", expectedFrameError: "System.Exception: oh no, a random error occurred 1a77a6d6-4803-4de8-822b-13a62397b9d3", expectedFrameCount: 7, expectedFramesWithFileNames: []bool{true, false, true, true, false, false, true}, expectedFramesWithLineNumbers: []bool{true, true, true, true, true, true, false}},
 		{language: ".NET Azure Functions", stacktrace: "System.NullReferenceException: Object reference not set to an instance of an object. at FooMgmt.LibraryV2.Services.FooService.GetAllCountries() in C:\\BarRepo\\ops-foomanagement-automation\\FunctionApps\\FooMgmt\\FooMgmt.LibraryV2\\Services\\FooService.cs:line 466 at FooMgmt.Function.WorkflowsV2.UserWorkflow.GetAllCountries.Run(HttpRequest req, ILogger log) in C:\\BarRepo\\ops-foomanagement-automation\\FunctionApps\\FooMgmt\\FooMgmt.Function\\WorkflowsV2\\UserWorkflow\\GetAllCountries.cs:line 43\n", expectedFrameError: "System.NullReferenceException: Object reference not set to an instance of an object.", expectedFrameCount: 2, expectedFramesWithFileNames: []bool{true, true}, expectedFramesWithLineNumbers: []bool{true, true}},
+		{language: "java", stacktrace: "java.lang.IllegalStateException: boom\n\tat a.b.c.d(SourceFile:12)\n\tat a.b.c.e(SourceFile:5)\n\tat android.os.Handler.dispatchMessage(Handler.java:106)\n\tat java.base/java.lang.Thread.run(Thread.java:833)\nCaused by: java.lang.NullPointerException\n\tat a.b.f.g(Unknown Source)\n\t... 4 more", expectedFrameError: "java.lang.IllegalStateException: boom", expectedFrameCount: 5, expectedFramesWithFileNames: []bool{true, true, true, true, true}, expectedFramesWithLineNumbers: []bool{true, true, true, true, false}},
 		{language: "OTeL Web.js", stacktrace: "O@https://www.foo.com/_next/static/chunks/107-60134c870dee3eda.js:1:24365\n@https://www.foo.com/_next/static/chunks/107-60134c870dee3eda.js:1:24567\n@https://www.foo.com/_next/static/chunks/1621-5b42b9472d365188.js:1:4158\nrW@https://www.foo.com/_next/static/chunks/1dd3208c-335eaf9e3a8a834b.js:1:44417\nuseState@https://www.foo.com/_next/static/chunks/1dd3208c-335eaf9e3a8a834b.js:1:50596\nO@https://www.foo.com/_next/static/chunks/1621-5b42b9472d365188.js:1:4130\n@https://www.foo.com/_next/static/chunks/107-60134c870dee3eda.js:1:24532\nT@https://www.foo.com/_next/static/chunks/107-60134c870dee3eda.js:1:77654\nrE@https://www.foo.com/_next/static/chunks/1dd3208c-335eaf9e3a8a834b.js:1:40343\nl$@https://www.foo.com/_next/static/chunks/1dd3208c-335eaf9e3a8a834b.js:1:59319\niZ@https://www.foo.com/_next/static/chunks/1dd3208c-335eaf9e3a8a834b.js:1:117682\nia@https://www.foo.com/_next/static/chunks/1dd3208c-335eaf9e3a8a834b.js:1:95165\n@https://www.foo.com/_next/static/chunks/1dd3208c-335eaf9e3a8a834b.js:1:94987\nil@https://www.foo.com/_next/static/chunks/1dd3208c-335eaf9e3a8a834b.js:1:94992\noJ@https://www.foo.com/_next/static/chunks/1dd3208c-335eaf9e3a8a834b.js:1:92350\noZ@https://www.foo.com/_next/static/chunks/1dd3208c-335eaf9e3a8a834b.js:1:91769\noZ@[native code]\nT@https://www.foo.com/_next/static/chunks/286-4bf9fb5921165e1e.js:1:84044", expectedFrameError: "O@https://www.foo.com/_next/static/chunks/107-60134c870dee3eda.js:1:24365", expectedFrameCount: 18, expectedFramesWithFileNames: []bool{true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, false, true}, expectedFramesWithLineNumbers: []bool{true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, false, true}},
 	}
 	for _, input := range inputs {
@@ -0,0 +1,2 @@
+function a(){return 1};function b(){throw new Error("b")}
+//# sourceMappingURL=indexed.min.js.map
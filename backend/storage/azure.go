@@ -0,0 +1,404 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/google/uuid"
+	"github.com/highlight-run/highlight/backend/env"
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/highlight-run/highlight/backend/payload"
+	privateModel "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	hredis "github.com/highlight-run/highlight/backend/redis"
+	"github.com/highlight-run/highlight/backend/util"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/samber/lo"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+)
+
+// AzureBlobClient stores data in Azure Blob Storage, using the bucket names configured for S3 as container names.
+// Like with S3, blobs are tagged with their retention period and deleted by the lifecycle management policy
+// of the storage account, which filters on the RetentionPeriod blob index tag and the raw-events/ prefix.
+type AzureBlobClient struct {
+	payloadEncryption
+	client *azblob.Client
+	redis  *hredis.Client
+}
+
+// NewAzureBlobClient creates a client authenticated with the storage account shared key.
+// Set AZURE_STORAGE_ENDPOINT to use an emulator such as Azurite.
+func NewAzureBlobClient(ctx context.Context) (*AzureBlobClient, error) {
+	credential, err := azblob.NewSharedKeyCredential(env.Config.AzureStorageAccountName, env.Config.AzureStorageAccountKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating azure shared key credential")
+	}
+	endpoint := env.Config.AzureStorageEndpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.blob.core.windows.net/", env.Config.AzureStorageAccountName)
+	}
+	client, err := azblob.NewClientWithSharedKeyCredential(endpoint, credential, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating azure blob client")
+	}
+	return &AzureBlobClient{client: client, redis: hredis.NewClient()}, nil
+}
+
+// countingReader counts the bytes read, as block blob uploads do not return the size of the blob.
+type countingReader struct {
+	reader io.Reader
+	size   int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.size += int64(n)
+	return n, err
+}
+
+func (a *AzureBlobClient) put(ctx context.Context, containerName string, key string, body io.Reader, options azblob.UploadStreamOptions) (*int64, error) {
+	reader := &countingReader{reader: body}
+	if _, err := a.client.UploadStream(ctx, containerName, key, reader, &options); err != nil {
+		return nil, errors.Wrap(err, "error uploading azure blob")
+	}
+	return &reader.size, nil
+}
+
+func (a *AzureBlobClient) get(ctx context.Context, containerName string, key string) ([]byte, error) {
+	resp, err := a.client.DownloadStream(ctx, containerName, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.WithContext(ctx).Error(err)
+		}
+	}()
+	return io.ReadAll(resp.Body)
+}
+
+func (a *AzureBlobClient) list(ctx context.Context, containerName string, prefix string) ([]*container.BlobItem, error) {
+	var items []*container.BlobItem
+	pager := a.client.NewListBlobsFlatPager(containerName, &azblob.ListBlobsFlatOptions{Prefix: &prefix})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "error listing azure blobs")
+		}
+		items = append(items, page.Segment.BlobItems...)
+	}
+	return items, nil
+}
+
+func (a *AzureBlobClient) signedURL(containerName string, key string, permissions sas.BlobPermissions) (string, error) {
+	url, err := a.client.ServiceClient().NewContainerClient(containerName).NewBlobClient(key).
+		GetSASURL(permissions, time.Now().Add(15*time.Minute), nil)
+	if err != nil {
+		return "", errors.Wrap(err, "error signing azure blob url")
+	}
+	return url, nil
+}
+
+func retentionTags(retentionPeriod privateModel.RetentionPeriod) map[string]string {
+	return map[string]string{"RetentionPeriod": string(retentionPeriod)}
+}
+
+func (a *AzureBlobClient) GetAssetURL(_ context.Context, projectId string, hashVal string) (string, error) {
+	return a.signedURL(S3ResourcesBucketName, projectId+"/"+hashVal, sas.BlobPermissions{Read: true})
+}
+
+func (a *AzureBlobClient) GetDirectDownloadURL(ctx context.Context, projectId int, sessionId int, payloadType PayloadType, chunkId *int) (*string, error) {
+	if a.encryptor != nil {
+		return a.decryptedDownloadURL(ctx, projectId, sessionId, payloadType, chunkId)
+	}
+	key := *bucketKey(sessionId, projectId, payloadType)
+	if chunkId != nil {
+		key = fmt.Sprintf("%s-%04d", key, *chunkId)
+	}
+	url, err := a.signedURL(S3SessionsPayloadBucketNameNew, key, sas.BlobPermissions{Read: true})
+	if err != nil {
+		return nil, err
+	}
+	return &url, nil
+}
+
+func (a *AzureBlobClient) GetRawData(ctx context.Context, sessionId, projectId int, payloadType model.RawPayloadType) (map[int64]string, error) {
+	items, err := a.list(ctx, S3SessionsStagingBucketName, "raw-events/"+*bucketKey(sessionId, projectId, payloadType))
+	if err != nil {
+		return nil, err
+	}
+
+	var eg errgroup.Group
+	results := make([][]redis.Z, len(items))
+	for idx, item := range items {
+		idx := idx
+		item := item
+		eg.Go(func() error {
+			b, err := a.get(ctx, S3SessionsStagingBucketName, *item.Name)
+			if err != nil {
+				return errors.Wrap(err, "error retrieving blob from azure")
+			}
+			if b, err = a.decrypt(ctx, projectId, b); err != nil {
+				return err
+			}
+			var result []redis.Z
+			if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&result); err != nil {
+				return errors.Wrap(err, "error decoding gob")
+			}
+			results[idx] = result
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, errors.Wrap(err, "error in task retrieving blob from azure")
+	}
+
+	return rawEventRows(results), nil
+}
+
+// GetSourceMapUploadUrl returns a SAS url for uploading the sourcemap.
+// Uploads must set the x-ms-blob-type: BlockBlob header.
+func (a *AzureBlobClient) GetSourceMapUploadUrl(_ context.Context, key string) (string, error) {
+	return a.signedURL(S3SourceMapBucketNameNew, key, sas.BlobPermissions{Create: true, Write: true})
+}
+
+func (a *AzureBlobClient) GetSourcemapFiles(ctx context.Context, projectId int, version *string) ([]s3Types.Object, error) {
+	if version == nil || len(*version) == 0 {
+		// If no version is specified we put files in an "unversioned" directory.
+		version = lo.ToPtr("unversioned")
+	}
+
+	items, err := a.list(ctx, S3SourceMapBucketNameNew, fmt.Sprintf("%d/%s/", projectId, *version))
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting sourcemaps from azure")
+	}
+
+	return lo.Map(items, func(item *container.BlobItem, _ int) s3Types.Object {
+		return s3Types.Object{
+			Key:          item.Name,
+			Size:         item.Properties.ContentLength,
+			LastModified: item.Properties.LastModified,
+		}
+	}), nil
+}
+
+func (a *AzureBlobClient) GetSourcemapVersions(ctx context.Context, projectId int) ([]string, error) {
+	var versions []string
+	pager := a.client.ServiceClient().NewContainerClient(S3SourceMapBucketNameNew).
+		NewListBlobsHierarchyPager("/", &container.ListBlobsHierarchyOptions{Prefix: lo.ToPtr(fmt.Sprintf("%d/", projectId))})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "error getting sourcemap app versions from azure")
+		}
+		for _, prefix := range page.Segment.BlobPrefixes {
+			versions = append(versions, *prefix.Name)
+		}
+	}
+	return versions, nil
+}
+
+func (a *AzureBlobClient) PushCompressedFile(ctx context.Context, sessionId, projectId int, file *os.File, payloadType PayloadType, retentionPeriod privateModel.RetentionPeriod) (*int64, error) {
+	body, err := a.encryptFile(ctx, projectId, file)
+	if err != nil {
+		return nil, err
+	}
+	return a.put(ctx, S3SessionsPayloadBucketNameNew, *bucketKey(sessionId, projectId, payloadType), body, azblob.UploadStreamOptions{
+		HTTPHeaders: &blob.HTTPHeaders{
+			BlobContentType:     lo.ToPtr(MIME_TYPE_JSON),
+			BlobContentEncoding: lo.ToPtr(CONTENT_ENCODING_BROTLI),
+		},
+		Tags: retentionTags(retentionPeriod),
+	})
+}
+
+func (a *AzureBlobClient) PushFiles(ctx context.Context, sessionId, projectId int, payloadManager *payload.PayloadManager, retentionPeriod privateModel.RetentionPeriod) (int64, error) {
+	var totalSize int64
+	for fileType, payloadType := range StoredPayloadTypes {
+		size, err := a.PushCompressedFile(ctx, sessionId, projectId, payloadManager.GetFile(fileType), payloadType, retentionPeriod)
+		if err != nil {
+			return 0, errors.Wrapf(err, "error pushing %s payload to azure", string(payloadType))
+		}
+
+		if size != nil {
+			totalSize += *size
+		}
+	}
+
+	return totalSize, nil
+}
+
+func (a *AzureBlobClient) PushRawEvents(ctx context.Context, sessionId, projectId int, payloadType model.RawPayloadType, events []redis.Z) error {
+	buf := new(bytes.Buffer)
+	encoder := gob.NewEncoder(buf)
+	if err := encoder.Encode(events); err != nil {
+		return errors.Wrap(err, "error encoding gob")
+	}
+
+	b, err := a.encrypt(ctx, projectId, buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	// Adding to a separate raw-events folder so these can be expired by prefix with a lifecycle policy.
+	key := "raw-events/" + *bucketKey(sessionId, projectId, string(payloadType)+"-"+uuid.New().String())
+	if _, err := a.put(ctx, S3SessionsStagingBucketName, key, bytes.NewReader(b), azblob.UploadStreamOptions{}); err != nil {
+		return errors.Wrap(err, "error uploading raw events to azure")
+	}
+	return nil
+}
+
+func (a *AzureBlobClient) PushSourceMapFile(ctx context.Context, projectId int, version *string, fileName string, fileBytes []byte) (*int64, error) {
+	span, ctx := util.StartSpanFromContext(ctx, "azure.PushSourceMapFile")
+	defer span.Finish()
+
+	size, err := a.put(ctx, S3SourceMapBucketNameNew, *sourceMapBucketKey(projectId, version, fileName), bytes.NewReader(fileBytes), azblob.UploadStreamOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "error 'put'ing sourcemap file in azure container")
+	}
+	return size, nil
+}
+
+func (a *AzureBlobClient) ReadResources(ctx context.Context, sessionId int, projectId int) ([]interface{}, error) {
+	return a.ReadCompressedEvents(ctx, sessionId, projectId, NetworkResourcesCompressed)
+}
+
+func (a *AzureBlobClient) ReadWebSocketEvents(ctx context.Context, sessionId int, projectId int) ([]interface{}, error) {
+	return a.ReadCompressedEvents(ctx, sessionId, projectId, WebSocketEventsCompressed)
+}
+
+func (a *AzureBlobClient) ReadSessionEvents(ctx context.Context, sessionId int, projectId int) ([]interface{}, error) {
+	return a.ReadCompressedEvents(ctx, sessionId, projectId, SessionContentsCompressed)
+}
+
+func (a *AzureBlobClient) ReadCompressedEvents(ctx context.Context, sessionId int, projectId int, payloadType PayloadType) ([]interface{}, error) {
+	events := []interface{}{}
+	if err := a.readCompressed(ctx, sessionId, projectId, payloadType, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (a *AzureBlobClient) ReadTimelineIndicatorEvents(ctx context.Context, sessionId int, projectId int) ([]*model.TimelineIndicatorEvent, error) {
+	var events []*model.TimelineIndicatorEvent
+	if err := a.readCompressed(ctx, sessionId, projectId, TimelineIndicatorEvents, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// readPayload returns the stored compressed payload, or nil if it does not exist.
+func (a *AzureBlobClient) readPayload(ctx context.Context, sessionId int, projectId int, payloadType PayloadType) ([]byte, error) {
+	b, err := a.get(ctx, S3SessionsPayloadBucketNameNew, *bucketKey(sessionId, projectId, payloadType))
+	if bloberror.HasCode(err, bloberror.BlobNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting blob from azure")
+	}
+	return a.decrypt(ctx, projectId, b)
+}
+
+// readCompressed decodes the payload into results, leaving them unchanged if the payload does not exist.
+func (a *AzureBlobClient) readCompressed(ctx context.Context, sessionId int, projectId int, payloadType PayloadType, results interface{}) error {
+	b, err := a.readPayload(ctx, sessionId, projectId, payloadType)
+	if err != nil || b == nil {
+		return err
+	}
+
+	buf, err := decompress(bytes.NewBuffer(b))
+	if err != nil {
+		return errors.Wrap(err, "error decompressing compressed buffer from azure")
+	}
+
+	if err := json.Unmarshal(buf.Bytes(), results); err != nil {
+		return errors.Wrap(err, "error decoding data")
+	}
+	return nil
+}
+
+func (a *AzureBlobClient) readSourceMapFile(ctx context.Context, projectId int, version *string, fileName string) ([]byte, error) {
+	span, ctx := util.StartSpanFromContext(ctx, "azure.ReadSourceMapFile")
+	defer span.Finish()
+	b, err := a.get(ctx, S3SourceMapBucketNameNew, *sourceMapBucketKey(projectId, version, fileName))
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting blob from azure")
+	}
+	return b, nil
+}
+
+func (a *AzureBlobClient) ReadSourceMapFileCached(ctx context.Context, projectId int, version *string, fileName string) ([]byte, error) {
+	span, ctx := util.StartSpanFromContext(ctx, "azure.ReadSourceMapFileCached")
+	defer span.Finish()
+	key := sourceMapBucketKey(projectId, version, fileName)
+	span.SetAttribute("key", key)
+	b, err := hredis.CachedEval(ctx, a.redis, *key, time.Second, time.Minute, func() (*[]byte, error) {
+		bt, err := a.readSourceMapFile(ctx, projectId, version, fileName)
+		return &bt, err
+	}, hredis.WithStoreNil(true), hredis.WithIgnoreError(true))
+
+	if b == nil || err != nil {
+		return nil, err
+	}
+	return *b, nil
+}
+
+func (a *AzureBlobClient) UploadAsset(ctx context.Context, uuid string, contentType string, reader io.Reader, retentionPeriod privateModel.RetentionPeriod) error {
+	if _, err := a.put(ctx, S3ResourcesBucketName, uuid, reader, azblob.UploadStreamOptions{
+		HTTPHeaders: &blob.HTTPHeaders{BlobContentType: lo.ToPtr(contentType)},
+		Tags:        retentionTags(retentionPeriod),
+	}); err != nil {
+		return errors.Wrap(err, "error uploading asset to azure")
+	}
+	return nil
+}
+
+func (a *AzureBlobClient) ReadGitHubFile(ctx context.Context, repoPath string, fileName string, version string) ([]byte, error) {
+	b, err := a.get(ctx, S3GithubBucketName, *githubBucketKey(repoPath, version, fileName))
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting blob from azure")
+	}
+	return b, nil
+}
+
+func (a *AzureBlobClient) PushGitHubFile(ctx context.Context, repoPath string, fileName string, version string, fileBytes []byte) (*int64, error) {
+	size, err := a.put(ctx, S3GithubBucketName, *githubBucketKey(repoPath, version, fileName), bytes.NewReader(fileBytes), azblob.UploadStreamOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "error 'put'ing github file in azure container")
+	}
+	return size, nil
+}
+
+func (a *AzureBlobClient) DeleteSessionData(ctx context.Context, projectId int, sessionId int) error {
+	items, err := a.list(ctx, S3SessionsPayloadBucketNameNew, *bucketKey(sessionId, projectId, ""))
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if _, err := a.client.DeleteBlob(ctx, S3SessionsPayloadBucketNameNew, *item.Name, nil); err != nil {
+			return errors.Wrap(err, "error deleting blobs from azure")
+		}
+	}
+
+	return nil
+}
+
+// CleanupRawEvents is a no-op as raw events are deleted by the lifecycle management policy of the storage account.
+func (a *AzureBlobClient) CleanupRawEvents(ctx context.Context, projectId int) error {
+	return nil
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// PayloadEncryptor envelope encrypts the session payloads and raw events stored for a project.
+type PayloadEncryptor interface {
+	Encrypt(ctx context.Context, projectId int, plaintext []byte) ([]byte, error)
+	// Decrypt returns data that is not encrypted unchanged.
+	Decrypt(ctx context.Context, projectId int, data []byte) ([]byte, error)
+	Sign(ctx context.Context, projectId int, message string) (string, error)
+	Verify(ctx context.Context, projectId int, message string, signature string) bool
+}
+
+// payloadEncryption is embedded by the clients to encrypt the payloads they store once an encryptor is set.
+type payloadEncryption struct {
+	encryptor PayloadEncryptor
+	origin    string
+}
+
+// SetPayloadEncryptor makes the client encrypt the session payloads and raw events it stores.
+// Direct download urls then link to the private graph at origin, which serves the decrypted payloads.
+func (p *payloadEncryption) SetPayloadEncryptor(encryptor PayloadEncryptor, origin string) {
+	p.encryptor = encryptor
+	p.origin = origin
+}
+
+func (p *payloadEncryption) encrypt(ctx context.Context, projectId int, data []byte) ([]byte, error) {
+	if p.encryptor == nil {
+		return data, nil
+	}
+	encrypted, err := p.encryptor.Encrypt(ctx, projectId, data)
+	if err != nil {
+		return nil, errors.Wrap(err, "error encrypting payload")
+	}
+	return encrypted, nil
+}
+
+func (p *payloadEncryption) decrypt(ctx context.Context, projectId int, data []byte) ([]byte, error) {
+	if p.encryptor == nil {
+		return data, nil
+	}
+	decrypted, err := p.encryptor.Decrypt(ctx, projectId, data)
+	if err != nil {
+		return nil, errors.Wrap(err, "error decrypting payload")
+	}
+	return decrypted, nil
+}
+
+// encryptFile returns the contents of the payload file to store, reading the file to encrypt it if encryption is enabled.
+func (p *payloadEncryption) encryptFile(ctx context.Context, projectId int, file *os.File) (io.ReadSeeker, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, errors.Wrap(err, "error seeking to beginning of file")
+	}
+	if p.encryptor == nil {
+		return file, nil
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading payload file")
+	}
+	encrypted, err := p.encrypt(ctx, projectId, data)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(encrypted), nil
+}
+
+// decryptedDownloadURL returns a signed url of the decrypted payload, valid for 15 minutes,
+// or nil if payloads are not encrypted and can be downloaded directly from the storage backend.
+func (p *payloadEncryption) decryptedDownloadURL(ctx context.Context, projectId int, sessionId int, payloadType PayloadType, chunkId *int) (*string, error) {
+	if p.encryptor == nil {
+		return nil, nil
+	}
+	if chunkId != nil {
+		payloadType = PayloadType(fmt.Sprintf("%s-%04d", payloadType, *chunkId))
+	}
+	path := fmt.Sprintf("/decrypted/%d/%d/%s", projectId, sessionId, payloadType)
+	expires := strconv.FormatInt(time.Now().Add(15*time.Minute).Unix(), 10)
+	signature, err := p.encryptor.Sign(ctx, projectId, path+"?expires="+expires)
+	if err != nil {
+		return nil, errors.Wrap(err, "error signing decrypted payload url")
+	}
+	signedURL := fmt.Sprintf("%s%s?%s", p.origin, path, url.Values{"expires": {expires}, "signature": {signature}}.Encode())
+	return &signedURL, nil
+}
+
+func (p *payloadEncryption) verifyDownload(ctx context.Context, projectId int, message string, signature string) bool {
+	return p.encryptor != nil && p.encryptor.Verify(ctx, projectId, message, signature)
+}
+
+// SetupDecryptedPayloadListener serves the decrypted payloads linked by the direct download urls of the client.
+func SetupDecryptedPayloadListener(r chi.Router, client Client) {
+	r.Get("/decrypted/{project-id}/{session-id}/{payload-type}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		projectId, err := strconv.Atoi(chi.URLParam(r, "project-id"))
+		if err != nil {
+			http.Error(w, "invalid project id", http.StatusBadRequest)
+			return
+		}
+		sessionId, err := strconv.Atoi(chi.URLParam(r, "session-id"))
+		if err != nil {
+			http.Error(w, "invalid session id", http.StatusBadRequest)
+			return
+		}
+		payloadType := PayloadType(chi.URLParam(r, "payload-type"))
+
+		query := r.URL.Query()
+		expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+		message := fmt.Sprintf("/decrypted/%d/%d/%s?expires=%s", projectId, sessionId, payloadType, query.Get("expires"))
+		if err != nil || time.Now().Unix() > expires || !client.verifyDownload(ctx, projectId, message, query.Get("signature")) {
+			http.Error(w, "invalid or expired signature", http.StatusForbidden)
+			return
+		}
+
+		data, err := client.readPayload(ctx, sessionId, projectId, payloadType)
+		if err != nil {
+			log.WithContext(ctx).WithError(err).Error("failed to read decrypted payload")
+			http.Error(w, "failed to read payload", http.StatusInternalServerError)
+			return
+		}
+		if data == nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Add("Content-Type", MIME_TYPE_JSON)
+		w.Header().Add("Content-Encoding", CONTENT_ENCODING_BROTLI)
+		w.Header().Add("Content-Length", strconv.Itoa(len(data)))
+		if _, err := w.Write(data); err != nil {
+			log.WithContext(ctx).WithError(err).Error("failed to write decrypted payload")
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/highlight-run/highlight/backend/model"
+	privateModel "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/redis/go-redis/v9"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testEncryptor encodes data so that it is not stored as plaintext.
+type testEncryptor struct{}
+
+func (testEncryptor) Encrypt(_ context.Context, projectId int, plaintext []byte) ([]byte, error) {
+	return []byte(fmt.Sprintf("enc:%d:%s", projectId, base64.StdEncoding.EncodeToString(plaintext))), nil
+}
+
+func (testEncryptor) Decrypt(_ context.Context, projectId int, data []byte) ([]byte, error) {
+	prefix := fmt.Sprintf("enc:%d:", projectId)
+	if !bytes.HasPrefix(data, []byte("enc:")) {
+		return data, nil
+	}
+	if !bytes.HasPrefix(data, []byte(prefix)) {
+		return nil, fmt.Errorf("encrypted for another project")
+	}
+	return base64.StdEncoding.DecodeString(string(data[len(prefix):]))
+}
+
+func (testEncryptor) Sign(_ context.Context, projectId int, message string) (string, error) {
+	return fmt.Sprintf("%d:%s", projectId, message), nil
+}
+
+func (testEncryptor) Verify(_ context.Context, projectId int, message string, signature string) bool {
+	return signature == fmt.Sprintf("%d:%s", projectId, message)
+}
+
+func TestPayloadEncryption(t *testing.T) {
+	ctx := context.Background()
+	fsRoot := t.TempDir()
+
+	router := chi.NewRouter()
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	client, err := NewFSClient(ctx, server.URL, fsRoot)
+	require.NoError(t, err)
+	client.SetPayloadEncryptor(testEncryptor{}, server.URL)
+	SetupDecryptedPayloadListener(router, client)
+
+	// payloads stored before encryption was enabled are still read
+	legacy := compressedFile(t, []map[string]interface{}{{"type": "legacy"}})
+	require.NoError(t, os.MkdirAll(fmt.Sprintf("%s/1/2", fsRoot), 0750))
+	legacyBytes, err := os.ReadFile(legacy.Name())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(fmt.Sprintf("%s/1/2/%s", fsRoot, NetworkResourcesCompressed), legacyBytes, 0600))
+	resources, err := client.ReadResources(ctx, 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{"type": "legacy"}}, resources)
+
+	file := compressedFile(t, []map[string]interface{}{{"type": "snapshot"}})
+	_, err = client.PushCompressedFile(ctx, 2, 1, file, SessionContentsCompressed, privateModel.RetentionPeriodSevenDays)
+	require.NoError(t, err)
+	stored, err := os.ReadFile(fmt.Sprintf("%s/1/2/%s", fsRoot, SessionContentsCompressed))
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(stored, []byte("enc:1:")))
+
+	events, err := client.ReadSessionEvents(ctx, 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{"type": "snapshot"}}, events)
+
+	require.NoError(t, client.PushRawEvents(ctx, 2, 1, model.PayloadTypeEvents, []redis.Z{{Score: 1, Member: "raw"}}))
+	rows, err := client.GetRawData(ctx, 2, 1, model.PayloadTypeEvents)
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]string{1: "raw"}, rows)
+
+	// direct downloads are decrypted by the private graph
+	url, err := client.GetDirectDownloadURL(ctx, 1, 2, SessionContentsCompressed, nil)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(*url, server.URL+"/decrypted/1/2/"))
+	resp, err := http.Get(*url)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, CONTENT_ENCODING_BROTLI, resp.Header.Get("Content-Encoding"))
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	// the http client does not decode brotli
+	_, err = file.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+	compressed, err := io.ReadAll(file)
+	require.NoError(t, err)
+	assert.Equal(t, compressed, b)
+
+	resp, err = http.Get(strings.Replace(*url, "/decrypted/1/2/", "/decrypted/1/3/", 1))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	chunkURL, err := client.GetDirectDownloadURL(ctx, 1, 2, SessionContentsCompressed, lo.ToPtr(1))
+	require.NoError(t, err)
+	resp, err = http.Get(*chunkURL)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
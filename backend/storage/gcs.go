@@ -0,0 +1,423 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	gcs "cloud.google.com/go/storage"
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/google/uuid"
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/highlight-run/highlight/backend/payload"
+	privateModel "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	hredis "github.com/highlight-run/highlight/backend/redis"
+	"github.com/highlight-run/highlight/backend/util"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/samber/lo"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/api/iterator"
+)
+
+// GCSClient stores data in Google Cloud Storage, using the bucket names configured for S3.
+// Objects with a retention period have their custom time set to when they expire,
+// and are deleted by a lifecycle rule on the bucket the day after.
+type GCSClient struct {
+	payloadEncryption
+	client *gcs.Client
+	redis  *hredis.Client
+	// signedURLOptions sets the service account that signs URLs. By default, the client credentials are used.
+	signedURLOptions gcs.SignedURLOptions
+}
+
+// NewGCSClient creates a client with the application default credentials.
+// Set STORAGE_EMULATOR_HOST to use an emulator such as fake-gcs-server.
+func NewGCSClient(ctx context.Context) (*GCSClient, error) {
+	client, err := gcs.NewClient(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating gcs client")
+	}
+	c := &GCSClient{client: client, redis: hredis.NewClient()}
+	if err := c.ensureLifecycleRules(ctx); err != nil {
+		log.WithContext(ctx).WithError(err).Warn("failed to configure gcs bucket lifecycle rules, expired data will not be deleted")
+	}
+	return c, nil
+}
+
+// ensureLifecycleRules adds the rules deleting expired payloads and assets, and raw events, to the buckets.
+func (g *GCSClient) ensureLifecycleRules(ctx context.Context) error {
+	expired := gcs.LifecycleRule{
+		Action:    gcs.LifecycleAction{Type: gcs.DeleteAction},
+		Condition: gcs.LifecycleCondition{DaysSinceCustomTime: 1},
+	}
+	rawEvents := gcs.LifecycleRule{
+		Action:    gcs.LifecycleAction{Type: gcs.DeleteAction},
+		Condition: gcs.LifecycleCondition{AgeInDays: RAW_EVENT_RETENTION_DAYS, MatchesPrefix: []string{"raw-events/"}},
+	}
+	for bucket, rule := range map[string]gcs.LifecycleRule{
+		S3SessionsPayloadBucketNameNew: expired,
+		S3ResourcesBucketName:          expired,
+		S3SessionsStagingBucketName:    rawEvents,
+	} {
+		if bucket == "" {
+			continue
+		}
+		attrs, err := g.client.Bucket(bucket).Attrs(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "error getting attributes of bucket %s", bucket)
+		}
+		if lo.ContainsBy(attrs.Lifecycle.Rules, func(r gcs.LifecycleRule) bool {
+			return r.Action == rule.Action && r.Condition.DaysSinceCustomTime == rule.Condition.DaysSinceCustomTime &&
+				r.Condition.AgeInDays == rule.Condition.AgeInDays
+		}) {
+			continue
+		}
+		lifecycle := attrs.Lifecycle
+		lifecycle.Rules = append(lifecycle.Rules, rule)
+		if _, err := g.client.Bucket(bucket).Update(ctx, gcs.BucketAttrsToUpdate{Lifecycle: &lifecycle}); err != nil {
+			return errors.Wrapf(err, "error updating lifecycle of bucket %s", bucket)
+		}
+	}
+	return nil
+}
+
+func (g *GCSClient) put(ctx context.Context, bucket string, key string, body io.Reader, attrs gcs.ObjectAttrs) (*int64, error) {
+	writer := g.client.Bucket(bucket).Object(key).NewWriter(ctx)
+	writer.ContentType = attrs.ContentType
+	writer.ContentEncoding = attrs.ContentEncoding
+	writer.CustomTime = attrs.CustomTime
+	if _, err := io.Copy(writer, body); err != nil {
+		_ = writer.Close()
+		return nil, errors.Wrap(err, "error writing gcs object")
+	}
+	if err := writer.Close(); err != nil {
+		return nil, errors.Wrap(err, "error writing gcs object")
+	}
+	return &writer.Attrs().Size, nil
+}
+
+func (g *GCSClient) get(ctx context.Context, bucket string, key string) ([]byte, error) {
+	reader, err := g.client.Bucket(bucket).Object(key).ReadCompressed(true).NewReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.WithContext(ctx).Error(err)
+		}
+	}()
+	return io.ReadAll(reader)
+}
+
+func (g *GCSClient) list(ctx context.Context, bucket string, query *gcs.Query) ([]*gcs.ObjectAttrs, error) {
+	var objects []*gcs.ObjectAttrs
+	it := g.client.Bucket(bucket).Objects(ctx, query)
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "error listing objects in gcs")
+		}
+		objects = append(objects, attrs)
+	}
+}
+
+func (g *GCSClient) signedURL(bucket string, key string, method string) (string, error) {
+	opts := g.signedURLOptions
+	opts.Scheme = gcs.SigningSchemeV4
+	opts.Method = method
+	opts.Expires = time.Now().Add(15 * time.Minute)
+	url, err := g.client.Bucket(bucket).SignedURL(key, &opts)
+	if err != nil {
+		return "", errors.Wrap(err, "error signing gcs url")
+	}
+	return url, nil
+}
+
+func (g *GCSClient) GetAssetURL(_ context.Context, projectId string, hashVal string) (string, error) {
+	return g.signedURL(S3ResourcesBucketName, projectId+"/"+hashVal, http.MethodGet)
+}
+
+func (g *GCSClient) GetDirectDownloadURL(ctx context.Context, projectId int, sessionId int, payloadType PayloadType, chunkId *int) (*string, error) {
+	if g.encryptor != nil {
+		return g.decryptedDownloadURL(ctx, projectId, sessionId, payloadType, chunkId)
+	}
+	key := *bucketKey(sessionId, projectId, payloadType)
+	if chunkId != nil {
+		key = fmt.Sprintf("%s-%04d", key, *chunkId)
+	}
+	url, err := g.signedURL(S3SessionsPayloadBucketNameNew, key, http.MethodGet)
+	if err != nil {
+		return nil, err
+	}
+	return &url, nil
+}
+
+func (g *GCSClient) GetRawData(ctx context.Context, sessionId, projectId int, payloadType model.RawPayloadType) (map[int64]string, error) {
+	objects, err := g.list(ctx, S3SessionsStagingBucketName, &gcs.Query{Prefix: "raw-events/" + *bucketKey(sessionId, projectId, payloadType)})
+	if err != nil {
+		return nil, err
+	}
+
+	var eg errgroup.Group
+	results := make([][]redis.Z, len(objects))
+	for idx, object := range objects {
+		idx := idx
+		object := object
+		eg.Go(func() error {
+			b, err := g.get(ctx, S3SessionsStagingBucketName, object.Name)
+			if err != nil {
+				return errors.Wrap(err, "error retrieving object from gcs")
+			}
+			if b, err = g.decrypt(ctx, projectId, b); err != nil {
+				return err
+			}
+			var result []redis.Z
+			if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&result); err != nil {
+				return errors.Wrap(err, "error decoding gob")
+			}
+			results[idx] = result
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, errors.Wrap(err, "error in task retrieving object from gcs")
+	}
+
+	return rawEventRows(results), nil
+}
+
+func (g *GCSClient) GetSourceMapUploadUrl(_ context.Context, key string) (string, error) {
+	return g.signedURL(S3SourceMapBucketNameNew, key, http.MethodPut)
+}
+
+func (g *GCSClient) GetSourcemapFiles(ctx context.Context, projectId int, version *string) ([]s3Types.Object, error) {
+	if version == nil || len(*version) == 0 {
+		// If no version is specified we put files in an "unversioned" directory.
+		version = lo.ToPtr("unversioned")
+	}
+
+	objects, err := g.list(ctx, S3SourceMapBucketNameNew, &gcs.Query{Prefix: fmt.Sprintf("%d/%s/", projectId, *version)})
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting sourcemaps from gcs")
+	}
+
+	return lo.Map(objects, func(object *gcs.ObjectAttrs, _ int) s3Types.Object {
+		return s3Types.Object{
+			Key:          lo.ToPtr(object.Name),
+			Size:         lo.ToPtr(object.Size),
+			LastModified: lo.ToPtr(object.Updated),
+		}
+	}), nil
+}
+
+func (g *GCSClient) GetSourcemapVersions(ctx context.Context, projectId int) ([]string, error) {
+	objects, err := g.list(ctx, S3SourceMapBucketNameNew, &gcs.Query{Prefix: fmt.Sprintf("%d/", projectId), Delimiter: "/"})
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting sourcemap app versions from gcs")
+	}
+
+	return lo.FilterMap(objects, func(object *gcs.ObjectAttrs, _ int) (string, bool) {
+		return object.Prefix, object.Prefix != ""
+	}), nil
+}
+
+func (g *GCSClient) PushCompressedFile(ctx context.Context, sessionId, projectId int, file *os.File, payloadType PayloadType, retentionPeriod privateModel.RetentionPeriod) (*int64, error) {
+	body, err := g.encryptFile(ctx, projectId, file)
+	if err != nil {
+		return nil, err
+	}
+	return g.put(ctx, S3SessionsPayloadBucketNameNew, *bucketKey(sessionId, projectId, payloadType), body, gcs.ObjectAttrs{
+		ContentType:     MIME_TYPE_JSON,
+		ContentEncoding: CONTENT_ENCODING_BROTLI,
+		CustomTime:      retentionExpiry(retentionPeriod),
+	})
+}
+
+func (g *GCSClient) PushFiles(ctx context.Context, sessionId, projectId int, payloadManager *payload.PayloadManager, retentionPeriod privateModel.RetentionPeriod) (int64, error) {
+	var totalSize int64
+	for fileType, payloadType := range StoredPayloadTypes {
+		size, err := g.PushCompressedFile(ctx, sessionId, projectId, payloadManager.GetFile(fileType), payloadType, retentionPeriod)
+		if err != nil {
+			return 0, errors.Wrapf(err, "error pushing %s payload to gcs", string(payloadType))
+		}
+
+		if size != nil {
+			totalSize += *size
+		}
+	}
+
+	return totalSize, nil
+}
+
+func (g *GCSClient) PushRawEvents(ctx context.Context, sessionId, projectId int, payloadType model.RawPayloadType, events []redis.Z) error {
+	buf := new(bytes.Buffer)
+	encoder := gob.NewEncoder(buf)
+	if err := encoder.Encode(events); err != nil {
+		return errors.Wrap(err, "error encoding gob")
+	}
+
+	b, err := g.encrypt(ctx, projectId, buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	// Adding to a separate raw-events folder so these can be expired by prefix with a lifecycle rule.
+	key := "raw-events/" + *bucketKey(sessionId, projectId, string(payloadType)+"-"+uuid.New().String())
+	if _, err := g.put(ctx, S3SessionsStagingBucketName, key, bytes.NewReader(b), gcs.ObjectAttrs{}); err != nil {
+		return errors.Wrap(err, "error uploading raw events to gcs")
+	}
+	return nil
+}
+
+func (g *GCSClient) PushSourceMapFile(ctx context.Context, projectId int, version *string, fileName string, fileBytes []byte) (*int64, error) {
+	span, ctx := util.StartSpanFromContext(ctx, "gcs.PushSourceMapFile")
+	defer span.Finish()
+
+	size, err := g.put(ctx, S3SourceMapBucketNameNew, *sourceMapBucketKey(projectId, version, fileName), bytes.NewReader(fileBytes), gcs.ObjectAttrs{})
+	if err != nil {
+		return nil, errors.Wrap(err, "error 'put'ing sourcemap file in gcs bucket")
+	}
+	return size, nil
+}
+
+func (g *GCSClient) ReadResources(ctx context.Context, sessionId int, projectId int) ([]interface{}, error) {
+	return g.ReadCompressedEvents(ctx, sessionId, projectId, NetworkResourcesCompressed)
+}
+
+func (g *GCSClient) ReadWebSocketEvents(ctx context.Context, sessionId int, projectId int) ([]interface{}, error) {
+	return g.ReadCompressedEvents(ctx, sessionId, projectId, WebSocketEventsCompressed)
+}
+
+func (g *GCSClient) ReadSessionEvents(ctx context.Context, sessionId int, projectId int) ([]interface{}, error) {
+	return g.ReadCompressedEvents(ctx, sessionId, projectId, SessionContentsCompressed)
+}
+
+func (g *GCSClient) ReadCompressedEvents(ctx context.Context, sessionId int, projectId int, payloadType PayloadType) ([]interface{}, error) {
+	events := []interface{}{}
+	if err := g.readCompressed(ctx, sessionId, projectId, payloadType, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (g *GCSClient) ReadTimelineIndicatorEvents(ctx context.Context, sessionId int, projectId int) ([]*model.TimelineIndicatorEvent, error) {
+	var events []*model.TimelineIndicatorEvent
+	if err := g.readCompressed(ctx, sessionId, projectId, TimelineIndicatorEvents, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// readPayload returns the stored compressed payload, or nil if it does not exist.
+func (g *GCSClient) readPayload(ctx context.Context, sessionId int, projectId int, payloadType PayloadType) ([]byte, error) {
+	b, err := g.get(ctx, S3SessionsPayloadBucketNameNew, *bucketKey(sessionId, projectId, payloadType))
+	if errors.Is(err, gcs.ErrObjectNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting object from gcs")
+	}
+	return g.decrypt(ctx, projectId, b)
+}
+
+// readCompressed decodes the payload into results, leaving them unchanged if the payload does not exist.
+func (g *GCSClient) readCompressed(ctx context.Context, sessionId int, projectId int, payloadType PayloadType, results interface{}) error {
+	b, err := g.readPayload(ctx, sessionId, projectId, payloadType)
+	if err != nil || b == nil {
+		return err
+	}
+
+	buf, err := decompress(bytes.NewBuffer(b))
+	if err != nil {
+		return errors.Wrap(err, "error decompressing compressed buffer from gcs")
+	}
+
+	if err := json.Unmarshal(buf.Bytes(), results); err != nil {
+		return errors.Wrap(err, "error decoding data")
+	}
+	return nil
+}
+
+func (g *GCSClient) readSourceMapFile(ctx context.Context, projectId int, version *string, fileName string) ([]byte, error) {
+	span, ctx := util.StartSpanFromContext(ctx, "gcs.ReadSourceMapFile")
+	defer span.Finish()
+	b, err := g.get(ctx, S3SourceMapBucketNameNew, *sourceMapBucketKey(projectId, version, fileName))
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting object from gcs")
+	}
+	return b, nil
+}
+
+func (g *GCSClient) ReadSourceMapFileCached(ctx context.Context, projectId int, version *string, fileName string) ([]byte, error) {
+	span, ctx := util.StartSpanFromContext(ctx, "gcs.ReadSourceMapFileCached")
+	defer span.Finish()
+	key := sourceMapBucketKey(projectId, version, fileName)
+	span.SetAttribute("key", key)
+	b, err := hredis.CachedEval(ctx, g.redis, *key, time.Second, time.Minute, func() (*[]byte, error) {
+		bt, err := g.readSourceMapFile(ctx, projectId, version, fileName)
+		return &bt, err
+	}, hredis.WithStoreNil(true), hredis.WithIgnoreError(true))
+
+	if b == nil || err != nil {
+		return nil, err
+	}
+	return *b, nil
+}
+
+func (g *GCSClient) UploadAsset(ctx context.Context, uuid string, contentType string, reader io.Reader, retentionPeriod privateModel.RetentionPeriod) error {
+	if _, err := g.put(ctx, S3ResourcesBucketName, uuid, reader, gcs.ObjectAttrs{
+		ContentType: contentType,
+		CustomTime:  retentionExpiry(retentionPeriod),
+	}); err != nil {
+		return errors.Wrap(err, "error uploading asset to gcs")
+	}
+	return nil
+}
+
+func (g *GCSClient) ReadGitHubFile(ctx context.Context, repoPath string, fileName string, version string) ([]byte, error) {
+	b, err := g.get(ctx, S3GithubBucketName, *githubBucketKey(repoPath, version, fileName))
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting object from gcs")
+	}
+	return b, nil
+}
+
+func (g *GCSClient) PushGitHubFile(ctx context.Context, repoPath string, fileName string, version string, fileBytes []byte) (*int64, error) {
+	size, err := g.put(ctx, S3GithubBucketName, *githubBucketKey(repoPath, version, fileName), bytes.NewReader(fileBytes), gcs.ObjectAttrs{})
+	if err != nil {
+		return nil, errors.Wrap(err, "error 'put'ing github file in gcs bucket")
+	}
+	return size, nil
+}
+
+func (g *GCSClient) DeleteSessionData(ctx context.Context, projectId int, sessionId int) error {
+	objects, err := g.list(ctx, S3SessionsPayloadBucketNameNew, &gcs.Query{Prefix: *bucketKey(sessionId, projectId, "")})
+	if err != nil {
+		return err
+	}
+
+	for _, object := range objects {
+		if err := g.client.Bucket(S3SessionsPayloadBucketNameNew).Object(object.Name).Delete(ctx); err != nil {
+			return errors.Wrap(err, "error deleting objects from gcs")
+		}
+	}
+
+	return nil
+}
+
+// CleanupRawEvents is a no-op as raw events are deleted by a lifecycle rule of the staging bucket.
+func (g *GCSClient) CleanupRawEvents(ctx context.Context, projectId int) error {
+	return nil
+}
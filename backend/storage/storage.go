@@ -104,15 +104,22 @@ type Client interface {
 	PushGitHubFile(ctx context.Context, repoPath string, fileName string, version string, fileBytes []byte) (*int64, error)
 	DeleteSessionData(ctx context.Context, projectId int, sessionId int) error
 	CleanupRawEvents(ctx context.Context, projectId int) error
+	SetPayloadEncryptor(encryptor PayloadEncryptor, origin string)
+	readPayload(ctx context.Context, sessionId int, projectId int, payloadType PayloadType) ([]byte, error)
+	verifyDownload(ctx context.Context, projectId int, message string, signature string) bool
 }
 
 type FilesystemClient struct {
+	payloadEncryption
 	origin string
 	fsRoot string
 	redis  *hredis.Client
 }
 
-func (f *FilesystemClient) GetDirectDownloadURL(_ context.Context, projectId int, sessionId int, payloadType PayloadType, chunkId *int) (*string, error) {
+func (f *FilesystemClient) GetDirectDownloadURL(ctx context.Context, projectId int, sessionId int, payloadType PayloadType, chunkId *int) (*string, error) {
+	if f.encryptor != nil {
+		return f.decryptedDownloadURL(ctx, projectId, sessionId, payloadType, chunkId)
+	}
 	key := fmt.Sprintf("/direct/%d/%d/%v", projectId, sessionId, payloadType)
 	if chunkId != nil {
 		key = fmt.Sprintf("%s-%04d", key, *chunkId)
@@ -147,7 +154,13 @@ func (f *FilesystemClient) GetRawData(ctx context.Context, sessionId, projectId
 				return
 			}
 
-			decoder := gob.NewDecoder(buf)
+			b, err := f.decrypt(ctx, projectId, buf.Bytes())
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			decoder := gob.NewDecoder(bytes.NewReader(b))
 			if err := decoder.Decode(&result); err != nil {
 				errs <- errors.Wrap(err, "error decoding gob")
 				return
@@ -164,20 +177,7 @@ func (f *FilesystemClient) GetRawData(ctx context.Context, sessionId, projectId
 	default:
 	}
 
-	eventRows := map[int64]string{}
-	for _, zRange := range results {
-		for _, z := range zRange {
-			intScore := int64(z.Score)
-			// Beacon events have decimals, skip them
-			if z.Score != float64(intScore) {
-				continue
-			}
-
-			eventRows[intScore] = z.Member.(string)
-		}
-	}
-
-	return eventRows, nil
+	return rawEventRows(results), nil
 }
 
 func (f *FilesystemClient) GetSourceMapUploadUrl(_ context.Context, key string) (string, error) {
@@ -212,12 +212,12 @@ func (f *FilesystemClient) GetSourcemapVersions(_ context.Context, projectId int
 }
 
 func (f *FilesystemClient) PushCompressedFile(ctx context.Context, sessionId, projectId int, file *os.File, payloadType PayloadType, retentionPeriod privateModel.RetentionPeriod) (*int64, error) {
-	_, err := file.Seek(0, io.SeekStart)
+	body, err := f.encryptFile(ctx, projectId, file)
 	if err != nil {
-		return nil, errors.Wrap(err, "error seeking to beginning of file")
+		return nil, err
 	}
 	key := fmt.Sprintf("%s/%d/%d/%v", f.fsRoot, projectId, sessionId, payloadType)
-	size, err := f.writeFSBytes(ctx, key, file)
+	size, err := f.writeFSBytes(ctx, key, body)
 	return &size, err
 }
 
@@ -250,8 +250,13 @@ func (f *FilesystemClient) PushRawEvents(ctx context.Context, sessionId, project
 		return errors.Wrap(err, "error encoding gob")
 	}
 
+	b, err := f.encrypt(ctx, projectId, buf.Bytes())
+	if err != nil {
+		return err
+	}
+
 	key := fmt.Sprintf("%s/raw-events/%d/%d/%v-%s", f.fsRoot, projectId, sessionId, payloadType, uuid.New().String())
-	_, err := f.writeFSBytes(ctx, key, buf)
+	_, err = f.writeFSBytes(ctx, key, bytes.NewReader(b))
 	return err
 }
 
@@ -362,18 +367,26 @@ func (f *FilesystemClient) UploadAsset(ctx context.Context, uuid, _ string, read
 	return err
 }
 
-func (f *FilesystemClient) readCompressed(ctx context.Context, sessionId int, projectId int, t PayloadType, results interface{}) error {
-	key := fmt.Sprintf("%s/%v/%v/%v", f.fsRoot, projectId, sessionId, t)
+func (f *FilesystemClient) readPayload(ctx context.Context, sessionId int, projectId int, payloadType PayloadType) ([]byte, error) {
+	key := fmt.Sprintf("%s/%v/%v/%v", f.fsRoot, projectId, sessionId, payloadType)
 	if _, err := os.Stat(key); err != nil {
 		log.WithContext(ctx).Warnf("file %s does not exist", key)
-		return nil
+		return nil, nil
 	}
 
 	buf, err := f.readFSBytes(ctx, key)
 	if err != nil {
+		return nil, err
+	}
+	return f.decrypt(ctx, projectId, buf.Bytes())
+}
+
+func (f *FilesystemClient) readCompressed(ctx context.Context, sessionId int, projectId int, t PayloadType, results interface{}) error {
+	b, err := f.readPayload(ctx, sessionId, projectId, t)
+	if err != nil || b == nil {
 		return err
 	}
-	buf, err = decompress(buf)
+	buf, err := decompress(bytes.NewBuffer(b))
 	if err != nil {
 		return errors.Wrap(err, "error decompressing compressed buffer from fs")
 	}
@@ -581,6 +594,7 @@ func NewFSClient(_ context.Context, origin, fsRoot string) (*FilesystemClient, e
 }
 
 type S3Client struct {
+	payloadEncryption
 	S3ClientEast2   *s3.Client
 	S3PresignClient *s3.PresignClient
 	URLSigner       *sign.URLSigner
@@ -642,9 +656,9 @@ func (s *S3Client) getSessionClientAndBucket(sessionId int) (*s3.Client, *string
 }
 
 func (s *S3Client) pushFileToS3WithOptions(ctx context.Context, sessionId, projectId int, file *os.File, payloadType PayloadType, options s3.PutObjectInput) (*int64, error) {
-	_, err := file.Seek(0, io.SeekStart)
+	body, err := s.encryptFile(ctx, projectId, file)
 	if err != nil {
-		return nil, errors.Wrap(err, "error seeking to beginning of file")
+		return nil, err
 	}
 
 	key := bucketKey(sessionId, projectId, payloadType)
@@ -652,7 +666,7 @@ func (s *S3Client) pushFileToS3WithOptions(ctx context.Context, sessionId, proje
 
 	options.Bucket = bucket
 	options.Key = key
-	options.Body = file
+	options.Body = body
 	_, err = client.PutObject(ctx, &options)
 	if err != nil {
 		return nil, err
@@ -688,15 +702,20 @@ func (s *S3Client) PushRawEvents(ctx context.Context, sessionId, projectId int,
 		return errors.Wrap(err, "error encoding gob")
 	}
 
+	b, err := s.encrypt(ctx, projectId, buf.Bytes())
+	if err != nil {
+		return err
+	}
+
 	// Adding to a separate raw-events folder so these can be expired by prefix with an S3 expiration rule.
 	key := "raw-events/" + *bucketKey(sessionId, projectId, string(payloadType)+"-"+uuid.New().String())
 
 	options := s3.PutObjectInput{
 		Bucket: &S3SessionsStagingBucketName,
 		Key:    &key,
-		Body:   bytes.NewReader(buf.Bytes()),
+		Body:   bytes.NewReader(b),
 	}
-	_, err := s.S3ClientEast2.PutObject(ctx, &options)
+	_, err = s.S3ClientEast2.PutObject(ctx, &options)
 	if err != nil {
 		return errors.Wrap(err, "error uploading raw events to S3")
 	}
@@ -739,7 +758,12 @@ func (s *S3Client) GetRawData(ctx context.Context, sessionId, projectId int, pay
 				return errors.Wrap(err, "error reading from s3 buffer")
 			}
 
-			decoder := gob.NewDecoder(buf)
+			b, err := s.decrypt(ctx, projectId, buf.Bytes())
+			if err != nil {
+				return err
+			}
+
+			decoder := gob.NewDecoder(bytes.NewReader(b))
 			if err := decoder.Decode(&result); err != nil {
 				return errors.Wrap(err, "error decoding gob")
 			}
@@ -753,20 +777,7 @@ func (s *S3Client) GetRawData(ctx context.Context, sessionId, projectId int, pay
 		return nil, errors.Wrap(err, "error in task retrieving object from S3")
 	}
 
-	eventRows := map[int64]string{}
-	for _, zRange := range results {
-		for _, z := range zRange {
-			intScore := int64(z.Score)
-			// Beacon events have decimals, skip them
-			if z.Score != float64(intScore) {
-				continue
-			}
-
-			eventRows[intScore] = z.Member.(string)
-		}
-	}
-
-	return eventRows, nil
+	return rawEventRows(results), nil
 }
 
 func (s *S3Client) PushFileToS3(ctx context.Context, sessionId, projectId int, file *os.File, payloadType PayloadType) (*int64, error) {
@@ -809,7 +820,8 @@ func (s *S3Client) ReadSessionEvents(ctx context.Context, sessionId int, project
 	return s.ReadCompressedEvents(ctx, sessionId, projectId, SessionContentsCompressed)
 }
 
-func (s *S3Client) ReadCompressedEvents(ctx context.Context, sessionId int, projectId int, payloadType PayloadType) ([]interface{}, error) {
+// readPayload returns the stored compressed payload, or nil if it does not exist.
+func (s *S3Client) readPayload(ctx context.Context, sessionId int, projectId int, payloadType PayloadType) ([]byte, error) {
 	client, bucket := s.getSessionClientAndBucket(sessionId)
 	output, err := client.GetObject(ctx, &s3.GetObjectInput{
 		Bucket:                  bucket,
@@ -819,16 +831,27 @@ func (s *S3Client) ReadCompressedEvents(ctx context.Context, sessionId int, proj
 	})
 	if err != nil {
 		if strings.Contains(err.Error(), "NoSuchKey") {
-			return []interface{}{}, nil
+			return nil, nil
 		}
-		return nil, err
+		return nil, errors.Wrap(err, "error getting object from s3")
 	}
 	buf := new(bytes.Buffer)
 	_, err = buf.ReadFrom(output.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "error reading from s3 buffer")
 	}
-	buf, err = decompress(buf)
+	return s.decrypt(ctx, projectId, buf.Bytes())
+}
+
+func (s *S3Client) ReadCompressedEvents(ctx context.Context, sessionId int, projectId int, payloadType PayloadType) ([]interface{}, error) {
+	b, err := s.readPayload(ctx, sessionId, projectId, payloadType)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return []interface{}{}, nil
+	}
+	buf, err := decompress(bytes.NewBuffer(b))
 	if err != nil {
 		return nil, errors.Wrap(err, "error decompressing compressed buffer from s3")
 	}
@@ -841,29 +864,13 @@ func (s *S3Client) ReadCompressedEvents(ctx context.Context, sessionId int, proj
 }
 
 func (s *S3Client) ReadTimelineIndicatorEvents(ctx context.Context, sessionId int, projectId int) ([]*model.TimelineIndicatorEvent, error) {
-	client, bucket := s.getSessionClientAndBucket(sessionId)
-
 	var events []*model.TimelineIndicatorEvent
-	output, err := client.GetObject(ctx, &s3.GetObjectInput{
-		Bucket:                  bucket,
-		Key:                     bucketKey(sessionId, projectId, TimelineIndicatorEvents),
-		ResponseContentType:     ptr.String(MIME_TYPE_JSON),
-		ResponseContentEncoding: ptr.String(CONTENT_ENCODING_BROTLI),
-	})
-	if err != nil {
-		if strings.Contains(err.Error(), "NoSuchKey") {
-			return events, nil
-		}
-		return nil, errors.Wrap(err, "error getting object from s3")
+	b, err := s.readPayload(ctx, sessionId, projectId, TimelineIndicatorEvents)
+	if err != nil || b == nil {
+		return events, err
 	}
 
-	buf := new(bytes.Buffer)
-	_, err = buf.ReadFrom(output.Body)
-	if err != nil {
-		return nil, errors.Wrap(err, "error reading from s3 buffer")
-	}
-
-	buf, err = decompress(buf)
+	buf, err := decompress(bytes.NewBuffer(b))
 	if err != nil {
 		return nil, errors.Wrap(err, "error decompressing compressed buffer from s3")
 	}
@@ -874,6 +881,43 @@ func (s *S3Client) ReadTimelineIndicatorEvents(ctx context.Context, sessionId in
 	return events, nil
 }
 
+// rawEventRows merges batches of raw events pushed with PushRawEvents by their timestamp score.
+func rawEventRows(results [][]redis.Z) map[int64]string {
+	eventRows := map[int64]string{}
+	for _, zRange := range results {
+		for _, z := range zRange {
+			intScore := int64(z.Score)
+			// Beacon events have decimals, skip them
+			if z.Score != float64(intScore) {
+				continue
+			}
+
+			eventRows[intScore] = z.Member.(string)
+		}
+	}
+	return eventRows
+}
+
+// retentionExpiry returns when data stored now with the retention period should be deleted.
+func retentionExpiry(retentionPeriod privateModel.RetentionPeriod) time.Time {
+	now := time.Now()
+	switch retentionPeriod {
+	case privateModel.RetentionPeriodSevenDays:
+		return now.AddDate(0, 0, 7)
+	case privateModel.RetentionPeriodThirtyDays:
+		return now.AddDate(0, 0, 30)
+	case privateModel.RetentionPeriodThreeMonths:
+		return now.AddDate(0, 3, 0)
+	case privateModel.RetentionPeriodTwelveMonths:
+		return now.AddDate(1, 0, 0)
+	case privateModel.RetentionPeriodTwoYears:
+		return now.AddDate(2, 0, 0)
+	case privateModel.RetentionPeriodThreeYears:
+		return now.AddDate(3, 0, 0)
+	}
+	return now.AddDate(0, 6, 0)
+}
+
 func bucketKey[T ~string](sessionId int, projectId int, key T) *string {
 	versionPart := ""
 	if UseNewSessionBucket(sessionId) {
@@ -885,7 +929,7 @@ func bucketKey[T ~string](sessionId int, projectId int, key T) *string {
 	return pointy.String(fmt.Sprintf("%s%v/%v/%v", versionPart, projectId, sessionId, key))
 }
 
-func (s *S3Client) sourceMapBucketKey(projectId int, version *string, fileName string) *string {
+func sourceMapBucketKey(projectId int, version *string, fileName string) *string {
 	var key string
 	if env.IsDevEnv() {
 		key = "dev/"
@@ -899,7 +943,7 @@ func (s *S3Client) sourceMapBucketKey(projectId int, version *string, fileName s
 }
 
 func (s *S3Client) PushSourceMapFileReaderToS3(ctx context.Context, projectId int, version *string, fileName string, file io.Reader) (*int64, error) {
-	key := s.sourceMapBucketKey(projectId, version, fileName)
+	key := sourceMapBucketKey(projectId, version, fileName)
 	_, err := s.S3ClientEast2.PutObject(ctx, &s3.PutObjectInput{
 		Bucket: pointy.String(S3SourceMapBucketNameNew), Key: key, Body: file,
 	})
@@ -929,7 +973,7 @@ func (s *S3Client) readSourceMapFile(ctx context.Context, projectId int, version
 	span, ctx := util.StartSpanFromContext(ctx, "s3.ReadSourceMapFile")
 	defer span.Finish()
 	output, err := s.S3ClientEast2.GetObject(ctx, &s3.GetObjectInput{Bucket: pointy.String(S3SourceMapBucketNameNew),
-		Key: s.sourceMapBucketKey(projectId, version, fileName)})
+		Key: sourceMapBucketKey(projectId, version, fileName)})
 	if err != nil {
 		return nil, errors.Wrap(err, "error getting object from s3")
 	}
@@ -944,7 +988,7 @@ func (s *S3Client) readSourceMapFile(ctx context.Context, projectId int, version
 func (s *S3Client) ReadSourceMapFileCached(ctx context.Context, projectId int, version *string, fileName string) ([]byte, error) {
 	span, ctx := util.StartSpanFromContext(ctx, "s3.ReadSourceMapFileCached")
 	defer span.Finish()
-	key := s.sourceMapBucketKey(projectId, version, fileName)
+	key := sourceMapBucketKey(projectId, version, fileName)
 	span.SetAttribute("key", key)
 	b, err := hredis.CachedEval(ctx, s.Redis, *key, time.Second, time.Minute, func() (*[]byte, error) {
 		bt, err := s.readSourceMapFile(ctx, projectId, version, fileName)
@@ -957,7 +1001,10 @@ func (s *S3Client) ReadSourceMapFileCached(ctx context.Context, projectId int, v
 	return *b, nil
 }
 
-func (s *S3Client) GetDirectDownloadURL(_ context.Context, projectId int, sessionId int, payloadType PayloadType, chunkId *int) (*string, error) {
+func (s *S3Client) GetDirectDownloadURL(ctx context.Context, projectId int, sessionId int, payloadType PayloadType, chunkId *int) (*string, error) {
+	if s.encryptor != nil {
+		return s.decryptedDownloadURL(ctx, projectId, sessionId, payloadType, chunkId)
+	}
 	if s.URLSigner == nil {
 		return nil, nil
 	}
@@ -1058,7 +1105,7 @@ func (s *S3Client) GetSourcemapVersions(ctx context.Context, projectId int) ([]s
 	}), nil
 }
 
-func (s *S3Client) githubBucketKey(repoPath string, version string, fileName string) *string {
+func githubBucketKey(repoPath string, version string, fileName string) *string {
 	var key string
 	if env.IsDevEnv() {
 		key = "dev/"
@@ -1069,7 +1116,7 @@ func (s *S3Client) githubBucketKey(repoPath string, version string, fileName str
 
 func (s *S3Client) ReadGitHubFile(ctx context.Context, repoPath string, fileName string, version string) ([]byte, error) {
 	output, err := s.S3ClientEast2.GetObject(ctx, &s3.GetObjectInput{Bucket: pointy.String(S3GithubBucketName),
-		Key: s.githubBucketKey(repoPath, version, fileName)})
+		Key: githubBucketKey(repoPath, version, fileName)})
 	if err != nil {
 		return nil, errors.Wrap(err, "error getting object from s3")
 	}
@@ -1082,7 +1129,7 @@ func (s *S3Client) ReadGitHubFile(ctx context.Context, repoPath string, fileName
 }
 
 func (s *S3Client) PushGitHubFileReaderToS3(ctx context.Context, repoPath string, fileName string, version string, file io.Reader) (*int64, error) {
-	key := s.githubBucketKey(repoPath, version, fileName)
+	key := githubBucketKey(repoPath, version, fileName)
 	_, err := s.S3ClientEast2.PutObject(ctx, &s3.PutObjectInput{
 		Bucket: pointy.String(S3GithubBucketName), Key: key, Body: file,
 	})
@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"strings"
+	"testing"
+
+	gcs "cloud.google.com/go/storage"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/andybalholm/brotli"
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/highlight-run/highlight/backend/env"
+	"github.com/highlight-run/highlight/backend/model"
+	privateModel "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/redis/go-redis/v9"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// azuriteAccountKey is the well-known key of the Azurite devstoreaccount1 account.
+const azuriteAccountKey = "Eby8vdM02xNOcqFlqUwJPLlmEsl6gUPVVWIt7KgN0ICdFNa5/Q2HL1KDj5YU1P7H3AFdD9RKflTTYw1YSrJ6LQ=="
+
+func setTestBucketNames() []string {
+	S3SessionsPayloadBucketNameNew = "test-sessions"
+	S3SessionsStagingBucketName = "test-sessions-staging"
+	S3SourceMapBucketNameNew = "test-sourcemaps"
+	S3ResourcesBucketName = "test-resources"
+	S3GithubBucketName = "test-github"
+	return []string{S3SessionsPayloadBucketNameNew, S3SessionsStagingBucketName, S3SourceMapBucketNameNew, S3ResourcesBucketName, S3GithubBucketName}
+}
+
+// TestGCSClient runs against fake-gcs-server, e.g.
+// docker run -p 4443:4443 fsouza/fake-gcs-server -scheme http
+// STORAGE_EMULATOR_HOST=localhost:4443 go test ./storage/...
+func TestGCSClient(t *testing.T) {
+	if os.Getenv("STORAGE_EMULATOR_HOST") == "" {
+		t.Skip("STORAGE_EMULATOR_HOST is not set")
+	}
+	ctx := context.Background()
+
+	setup, err := gcs.NewClient(ctx)
+	require.NoError(t, err)
+	for _, bucket := range setTestBucketNames() {
+		if _, err := setup.Bucket(bucket).Attrs(ctx); err == nil {
+			continue
+		}
+		require.NoError(t, setup.Bucket(bucket).Create(ctx, "test", nil))
+	}
+
+	client, err := NewGCSClient(ctx)
+	require.NoError(t, err)
+
+	// the emulator has no service account to sign urls
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	client.signedURLOptions = gcs.SignedURLOptions{
+		GoogleAccessID: "test@highlight.iam.gserviceaccount.com",
+		PrivateKey:     pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+	}
+
+	testClientContract(t, client)
+}
+
+// TestAzureBlobClient runs against Azurite, e.g.
+// docker run -p 10000:10000 mcr.microsoft.com/azure-storage/azurite azurite-blob --blobHost 0.0.0.0
+// AZURE_STORAGE_ENDPOINT=http://127.0.0.1:10000/devstoreaccount1/ go test ./storage/...
+func TestAzureBlobClient(t *testing.T) {
+	if env.Config.AzureStorageEndpoint == "" {
+		t.Skip("AZURE_STORAGE_ENDPOINT is not set")
+	}
+	ctx := context.Background()
+
+	if env.Config.AzureStorageAccountName == "" {
+		env.Config.AzureStorageAccountName = "devstoreaccount1"
+		env.Config.AzureStorageAccountKey = azuriteAccountKey
+	}
+	client, err := NewAzureBlobClient(ctx)
+	require.NoError(t, err)
+	for _, containerName := range setTestBucketNames() {
+		if _, err := client.client.CreateContainer(ctx, containerName, nil); err != nil && !bloberror.HasCode(err, bloberror.ContainerAlreadyExists) {
+			require.NoError(t, err)
+		}
+	}
+
+	testClientContract(t, client)
+}
+
+func compressedFile(t *testing.T, value interface{}) *os.File {
+	file, err := os.CreateTemp(t.TempDir(), "payload")
+	require.NoError(t, err)
+	b, err := json.Marshal(value)
+	require.NoError(t, err)
+	writer := brotli.NewWriter(file)
+	_, err = writer.Write(b)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return file
+}
+
+// testClientContract checks the behavior shared by the object storage backends.
+func testClientContract(t *testing.T, client Client) {
+	ctx := context.Background()
+	projectId, sessionId := 1, 1
+
+	t.Run("session payloads", func(t *testing.T) {
+		events, err := client.ReadSessionEvents(ctx, sessionId, projectId)
+		assert.NoError(t, err)
+		assert.Empty(t, events)
+
+		file := compressedFile(t, []map[string]interface{}{{"type": float64(2)}})
+		size, err := client.PushCompressedFile(ctx, sessionId, projectId, file, SessionContentsCompressed, privateModel.RetentionPeriodSevenDays)
+		require.NoError(t, err)
+		info, err := file.Stat()
+		require.NoError(t, err)
+		assert.Equal(t, info.Size(), *size)
+
+		events, err = client.ReadSessionEvents(ctx, sessionId, projectId)
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{map[string]interface{}{"type": float64(2)}}, events)
+
+		url, err := client.GetDirectDownloadURL(ctx, projectId, sessionId, SessionContentsCompressed, lo.ToPtr(1))
+		assert.NoError(t, err)
+		assert.Contains(t, *url, *bucketKey(sessionId, projectId, SessionContentsCompressed)+"-0001")
+
+		assert.NoError(t, client.DeleteSessionData(ctx, projectId, sessionId))
+		events, err = client.ReadSessionEvents(ctx, sessionId, projectId)
+		assert.NoError(t, err)
+		assert.Empty(t, events)
+	})
+
+	t.Run("raw events", func(t *testing.T) {
+		assert.NoError(t, client.PushRawEvents(ctx, sessionId, projectId, model.PayloadTypeEvents, []redis.Z{
+			{Score: 1, Member: "a"},
+			{Score: 2.5, Member: "beacon"},
+		}))
+		assert.NoError(t, client.PushRawEvents(ctx, sessionId, projectId, model.PayloadTypeEvents, []redis.Z{
+			{Score: 3, Member: "b"},
+		}))
+
+		rows, err := client.GetRawData(ctx, sessionId, projectId, model.PayloadTypeEvents)
+		assert.NoError(t, err)
+		assert.Equal(t, map[int64]string{1: "a", 3: "b"}, rows)
+		assert.NoError(t, client.CleanupRawEvents(ctx, projectId))
+	})
+
+	t.Run("sourcemaps", func(t *testing.T) {
+		size, err := client.PushSourceMapFile(ctx, projectId, lo.ToPtr("v1"), "main.js.map", []byte("{}"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), *size)
+		_, err = client.PushSourceMapFile(ctx, projectId, nil, "other.js.map", []byte("{}"))
+		require.NoError(t, err)
+
+		files, err := client.GetSourcemapFiles(ctx, projectId, lo.ToPtr("v1"))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{*sourceMapBucketKey(projectId, lo.ToPtr("v1"), "main.js.map")}, lo.Map(files, func(f s3Types.Object, _ int) string {
+			return *f.Key
+		}))
+		assert.Equal(t, int64(2), *files[0].Size)
+
+		versions, err := client.GetSourcemapVersions(ctx, projectId)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"1/unversioned/", "1/v1/"}, versions)
+
+		url, err := client.GetSourceMapUploadUrl(ctx, "1/v2/upload.js.map")
+		assert.NoError(t, err)
+		assert.Contains(t, url, "upload.js.map")
+	})
+
+	t.Run("assets", func(t *testing.T) {
+		assert.NoError(t, client.UploadAsset(ctx, "1/asset", "text/css", strings.NewReader("body {}"), privateModel.RetentionPeriodThirtyDays))
+		url, err := client.GetAssetURL(ctx, "1", "asset")
+		assert.NoError(t, err)
+		assert.Contains(t, url, "1/asset")
+	})
+
+	t.Run("github files", func(t *testing.T) {
+		size, err := client.PushGitHubFile(ctx, "highlight/highlight", "main.go", "abc", []byte("package main"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(12), *size)
+
+		b, err := client.ReadGitHubFile(ctx, "highlight/highlight", "main.go", "abc")
+		assert.NoError(t, err)
+		assert.True(t, bytes.Equal([]byte("package main"), b))
+
+		_, err = client.ReadGitHubFile(ctx, "highlight/highlight", "missing.go", "abc")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,120 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/highlight-run/highlight/backend/model"
+	privateModel "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/highlight-run/highlight/backend/redis"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// API_TOKEN_PREFIX starts every API token so that they can be told apart from other credentials.
+const API_TOKEN_PREFIX = "hlt_"
+
+// API_TOKEN_LAST_USED_INTERVAL is how often the last use of a token is written, as tokens authenticate every request.
+const API_TOKEN_LAST_USED_INTERVAL = time.Minute
+
+var ErrAPITokenExpired = errors.New("api token has expired")
+
+func getIngestTokenKey(tokenHash string) string {
+	return fmt.Sprintf("ingest-token-%s", tokenHash)
+}
+
+// CreateAPIToken stores a hash of the token. The rest of apiToken is saved as provided.
+func (store *Store) CreateAPIToken(ctx context.Context, apiToken *model.APIToken, token string) error {
+	apiToken.TokenHash = hashToken(token)
+	apiToken.Prefix = token[:min(len(token), len(API_TOKEN_PREFIX)+4)]
+	return store.DB.WithContext(ctx).Create(apiToken).Error
+}
+
+// GetAPITokens returns the workspace's API tokens. If adminID is set, only that admin's personal tokens are returned.
+func (store *Store) GetAPITokens(ctx context.Context, workspaceID int, adminID *int) ([]*model.APIToken, error) {
+	query := store.DB.WithContext(ctx).Where(&model.APIToken{WorkspaceID: workspaceID})
+	if adminID != nil {
+		query = query.Where(&model.APIToken{AdminID: *adminID, Type: privateModel.APITokenTypePersonal})
+	}
+	var tokens []*model.APIToken
+	return tokens, query.Order("id ASC").Find(&tokens).Error
+}
+
+func (store *Store) GetAPITokenByID(ctx context.Context, workspaceID int, id int) (*model.APIToken, error) {
+	var apiToken model.APIToken
+	return &apiToken, store.DB.WithContext(ctx).
+		Where(&model.APIToken{Model: model.Model{ID: id}, WorkspaceID: workspaceID}).
+		Take(&apiToken).Error
+}
+
+// DeleteAPIToken revokes the token, including its cached use as an ingest token.
+func (store *Store) DeleteAPIToken(ctx context.Context, workspaceID int, id int) error {
+	apiToken, err := store.GetAPITokenByID(ctx, workspaceID, id)
+	if err != nil {
+		return err
+	}
+	if err := store.DB.WithContext(ctx).Delete(apiToken).Error; err != nil {
+		return err
+	}
+	return store.Redis.Cache.Delete(ctx, getIngestTokenKey(apiToken.TokenHash))
+}
+
+// GetAPIToken returns the unexpired API token matching the token and records that it was used,
+// at most once per API_TOKEN_LAST_USED_INTERVAL.
+func (store *Store) GetAPIToken(ctx context.Context, token string) (*model.APIToken, error) {
+	var apiToken model.APIToken
+	if err := store.DB.WithContext(ctx).
+		Where(&model.APIToken{TokenHash: hashToken(token)}).
+		Take(&apiToken).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invalid api token")
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if apiToken.ExpiresAt != nil && apiToken.ExpiresAt.Before(now) {
+		return nil, ErrAPITokenExpired
+	}
+	if apiToken.LastUsedAt == nil || apiToken.LastUsedAt.Before(now.Add(-API_TOKEN_LAST_USED_INTERVAL)) {
+		// a failure to record the use does not reject the request
+		if err := store.DB.WithContext(ctx).Model(&apiToken).UpdateColumn("last_used_at", now).Error; err != nil {
+			log.WithContext(ctx).WithError(err).WithField("api_token_id", apiToken.ID).Error("failed to record api token use")
+		} else {
+			apiToken.LastUsedAt = &now
+		}
+	}
+	return &apiToken, nil
+}
+
+// GetIngestTokenProject returns the project that an API token with the LogsIngest scope sends data to.
+// The lookup is cached as it runs for every request of a log forwarder, so unlike GetAPIToken it does
+// not record that the token was used.
+func (store *Store) GetIngestTokenProject(ctx context.Context, token string) (int, error) {
+	tokenHash := hashToken(token)
+	apiToken, err := redis.CachedEval(ctx, store.Redis, getIngestTokenKey(tokenHash), time.Second, time.Minute, func() (*model.APIToken, error) {
+		var apiToken model.APIToken
+		if err := store.DB.WithContext(ctx).
+			Where(&model.APIToken{TokenHash: tokenHash}).
+			Where("scopes @> ?", pq.StringArray{string(privateModel.APITokenScopeLogsIngest)}).
+			Take(&apiToken).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return &apiToken, nil
+	}, redis.WithStoreNil(true))
+	if err != nil {
+		return 0, err
+	}
+	if apiToken == nil || len(apiToken.ProjectIds) != 1 {
+		return 0, errors.New("invalid ingest token")
+	}
+	if apiToken.ExpiresAt != nil && apiToken.ExpiresAt.Before(time.Now()) {
+		return 0, ErrAPITokenExpired
+	}
+	return int(apiToken.ProjectIds[0]), nil
+}
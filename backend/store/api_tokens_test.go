@@ -0,0 +1,89 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/highlight-run/highlight/backend/model"
+	privateModel "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/lib/pq"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPITokens(t *testing.T) {
+	ctx := context.Background()
+
+	defer teardown(t)
+
+	personal := &model.APIToken{WorkspaceID: 1, AdminID: 2, Name: "terraform", Type: privateModel.APITokenTypePersonal, Scopes: pq.StringArray{"AlertsWrite"}}
+	require.NoError(t, store.CreateAPIToken(ctx, personal, "hlt_personal"))
+	assert.NotEqual(t, "hlt_personal", personal.TokenHash)
+	assert.Equal(t, "hlt_pers", personal.Prefix)
+
+	serviceAccount := &model.APIToken{WorkspaceID: 1, AdminID: 3, Name: "reports", Type: privateModel.APITokenTypeServiceAccount, Scopes: pq.StringArray{"ErrorsRead"}}
+	require.NoError(t, store.CreateAPIToken(ctx, serviceAccount, "hlt_service"))
+
+	expired := &model.APIToken{WorkspaceID: 1, AdminID: 2, Name: "old", Type: privateModel.APITokenTypePersonal, ExpiresAt: lo.ToPtr(time.Now().Add(-time.Hour))}
+	require.NoError(t, store.CreateAPIToken(ctx, expired, "hlt_expired"))
+
+	token, err := store.GetAPIToken(ctx, "hlt_personal")
+	require.NoError(t, err)
+	assert.Equal(t, personal.ID, token.ID)
+	assert.NotNil(t, token.LastUsedAt)
+
+	// the last use is only written once per interval
+	lastUsedAt := *token.LastUsedAt
+	token, err = store.GetAPIToken(ctx, "hlt_personal")
+	require.NoError(t, err)
+	assert.True(t, token.LastUsedAt.Equal(lastUsedAt))
+
+	_, err = store.GetAPIToken(ctx, "hlt_expired")
+	assert.ErrorIs(t, err, ErrAPITokenExpired)
+
+	_, err = store.GetAPIToken(ctx, "hlt_other")
+	assert.Error(t, err)
+
+	tokens, err := store.GetAPITokens(ctx, 1, nil)
+	require.NoError(t, err)
+	assert.Len(t, tokens, 3)
+
+	adminID := 2
+	tokens, err = store.GetAPITokens(ctx, 1, &adminID)
+	require.NoError(t, err)
+	assert.Len(t, tokens, 2)
+
+	require.NoError(t, store.DeleteAPIToken(ctx, 1, personal.ID))
+	_, err = store.GetAPIToken(ctx, "hlt_personal")
+	assert.Error(t, err)
+}
+
+func TestGetIngestTokenProject(t *testing.T) {
+	ctx := context.Background()
+
+	defer teardown(t)
+
+	// an existing datadog api key registered for a project
+	datadog := &model.APIToken{WorkspaceID: 1, AdminID: 2, Name: "datadog agents", Type: privateModel.APITokenTypeServiceAccount, Scopes: pq.StringArray{"LogsIngest"}, ProjectIds: pq.Int32Array{5}}
+	require.NoError(t, store.CreateAPIToken(ctx, datadog, "0123456789abcdef0123456789abcdef"))
+	other := &model.APIToken{WorkspaceID: 1, AdminID: 2, Name: "reports", Type: privateModel.APITokenTypeServiceAccount, Scopes: pq.StringArray{"ErrorsRead"}, ProjectIds: pq.Int32Array{5}}
+	require.NoError(t, store.CreateAPIToken(ctx, other, "hlt_reports"))
+
+	projectID, err := store.GetIngestTokenProject(ctx, "0123456789abcdef0123456789abcdef")
+	require.NoError(t, err)
+	assert.Equal(t, 5, projectID)
+
+	// tokens without the ingest scope and unknown tokens are rejected
+	_, err = store.GetIngestTokenProject(ctx, "hlt_reports")
+	assert.Error(t, err)
+	_, err = store.GetIngestTokenProject(ctx, "5")
+	assert.Error(t, err)
+
+	// deleted tokens are rejected in every environment although the lookup is cached
+	setProductionEnv(t)
+	require.NoError(t, store.DeleteAPIToken(ctx, 1, datadog.ID))
+	_, err = store.GetIngestTokenProject(ctx, "0123456789abcdef0123456789abcdef")
+	assert.Error(t, err)
+}
@@ -0,0 +1,174 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/highlight-run/highlight/backend/model"
+	privateModel "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+const (
+	AUDIT_LOG_LIMIT                  = 50
+	AUDIT_LOG_EXPORT_LIMIT           = 10_000
+	AUDIT_LOG_DELETE_BATCH           = 10_000
+	DEFAULT_AUDIT_LOG_RETENTION_DAYS = 365
+	MAX_AUDIT_LOG_RETENTION_DAYS     = 3650
+)
+
+type ListAuditLogsParams struct {
+	ProjectID  *int
+	AdminID    *int
+	Action     *string
+	TargetType *string
+	StartDate  *time.Time
+	EndDate    *time.Time
+	After      *string
+	Before     *string
+}
+
+func (store *Store) CreateAuditLog(ctx context.Context, auditLog *model.AuditLog) error {
+	return store.DB.WithContext(ctx).Create(auditLog).Error
+}
+
+func (store *Store) auditLogsQuery(ctx context.Context, workspaceID int, params ListAuditLogsParams) *gorm.DB {
+	query := store.DB.WithContext(ctx).Model(&model.AuditLog{}).Where(&model.AuditLog{WorkspaceID: workspaceID})
+	if params.ProjectID != nil {
+		query = query.Where("project_id = ?", *params.ProjectID)
+	}
+	if params.AdminID != nil {
+		query = query.Where("admin_id = ?", *params.AdminID)
+	}
+	if params.Action != nil && *params.Action != "" {
+		query = query.Where("action = ?", *params.Action)
+	}
+	if params.TargetType != nil && *params.TargetType != "" {
+		query = query.Where("target_type = ?", *params.TargetType)
+	}
+	if params.StartDate != nil {
+		query = query.Where("created_at >= ?", *params.StartDate)
+	}
+	if params.EndDate != nil {
+		query = query.Where("created_at <= ?", *params.EndDate)
+	}
+	return query
+}
+
+// ListAuditLogs returns a page of the workspace's audit logs, newest first.
+func (store *Store) ListAuditLogs(ctx context.Context, workspaceID int, params ListAuditLogsParams) (privateModel.AuditLogConnection, error) {
+	connection := privateModel.AuditLogConnection{
+		Edges:    []*privateModel.AuditLogEdge{},
+		PageInfo: &privateModel.PageInfo{},
+	}
+
+	query := store.auditLogsQuery(ctx, workspaceID, params).Limit(AUDIT_LOG_LIMIT + 1)
+	if params.After != nil {
+		after, err := strconv.ParseInt(*params.After, 10, 64)
+		if err != nil {
+			return connection, errors.Wrap(err, "invalid audit log cursor")
+		}
+		query = query.Order("id DESC").Where("id < ?", after)
+	} else if params.Before != nil {
+		before, err := strconv.ParseInt(*params.Before, 10, 64)
+		if err != nil {
+			return connection, errors.Wrap(err, "invalid audit log cursor")
+		}
+		query = query.Order("id ASC").Where("id > ?", before)
+	} else {
+		query = query.Order("id DESC")
+	}
+
+	var auditLogs []*model.AuditLog
+	if err := query.Find(&auditLogs).Error; err != nil {
+		return connection, err
+	}
+	if len(auditLogs) == 0 {
+		return connection, nil
+	}
+
+	var hasNextPage, hasPreviousPage bool
+	if params.After != nil {
+		hasPreviousPage = true
+		if len(auditLogs) > AUDIT_LOG_LIMIT {
+			auditLogs = auditLogs[:AUDIT_LOG_LIMIT]
+			hasNextPage = true
+		}
+	} else if params.Before != nil {
+		hasNextPage = true
+		if len(auditLogs) > AUDIT_LOG_LIMIT {
+			auditLogs = auditLogs[:AUDIT_LOG_LIMIT]
+			hasPreviousPage = true
+		}
+		// Reverse the slice to maintain a descending order view
+		sort.Slice(auditLogs, func(i, j int) bool {
+			return auditLogs[i].ID > auditLogs[j].ID
+		})
+	} else if len(auditLogs) > AUDIT_LOG_LIMIT {
+		auditLogs = auditLogs[:AUDIT_LOG_LIMIT]
+		hasNextPage = true
+	}
+
+	connection.Edges = lo.Map(auditLogs, func(auditLog *model.AuditLog, _ int) *privateModel.AuditLogEdge {
+		return &privateModel.AuditLogEdge{
+			Cursor: strconv.FormatInt(auditLog.ID, 10),
+			Node: &privateModel.AuditLogNode{
+				ID:          auditLog.ID,
+				CreatedAt:   auditLog.CreatedAt,
+				WorkspaceID: auditLog.WorkspaceID,
+				ProjectID:   auditLog.ProjectID,
+				AdminID:     auditLog.AdminID,
+				AdminEmail:  auditLog.AdminEmail,
+				Action:      auditLog.Action,
+				TargetType:  auditLog.TargetType,
+				TargetID:    auditLog.TargetID,
+				Diff:        auditLog.Diff,
+				IP:          auditLog.IP,
+				UserAgent:   auditLog.UserAgent,
+			},
+		}
+	})
+	connection.PageInfo = &privateModel.PageInfo{
+		HasNextPage:     hasNextPage,
+		HasPreviousPage: hasPreviousPage,
+		StartCursor:     connection.Edges[0].Cursor,
+		EndCursor:       connection.Edges[len(connection.Edges)-1].Cursor,
+	}
+	return connection, nil
+}
+
+// ExportAuditLogs returns the newest audit logs matching the params, up to AUDIT_LOG_EXPORT_LIMIT.
+func (store *Store) ExportAuditLogs(ctx context.Context, workspaceID int, params ListAuditLogsParams) ([]*model.AuditLog, error) {
+	var auditLogs []*model.AuditLog
+	return auditLogs, store.auditLogsQuery(ctx, workspaceID, params).
+		Order("id DESC").
+		Limit(AUDIT_LOG_EXPORT_LIMIT).
+		Find(&auditLogs).Error
+}
+
+// DeleteExpiredAuditLogs deletes audit logs older than their workspace's retention, returning the number deleted.
+func (store *Store) DeleteExpiredAuditLogs(ctx context.Context) (int64, error) {
+	var total int64
+	for {
+		result := store.DB.WithContext(ctx).Exec(`
+			DELETE FROM audit_logs
+			WHERE id IN (
+				SELECT audit_logs.id
+				FROM audit_logs
+				LEFT JOIN all_workspace_settings ON all_workspace_settings.workspace_id = audit_logs.workspace_id
+				WHERE audit_logs.created_at < now() - make_interval(days => COALESCE(all_workspace_settings.audit_log_retention_days, ?))
+				LIMIT ?
+			)`, DEFAULT_AUDIT_LOG_RETENTION_DAYS, AUDIT_LOG_DELETE_BATCH)
+		if result.Error != nil {
+			return total, errors.Wrap(result.Error, "error deleting expired audit logs")
+		}
+		total += result.RowsAffected
+		if result.RowsAffected < AUDIT_LOG_DELETE_BATCH {
+			return total, nil
+		}
+	}
+}
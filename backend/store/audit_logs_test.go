@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAuditLogs(t *testing.T) {
+	ctx := context.Background()
+
+	defer teardown(t)
+
+	for i := 0; i < AUDIT_LOG_LIMIT+5; i++ {
+		require.NoError(t, store.CreateAuditLog(ctx, &model.AuditLog{
+			WorkspaceID: 1,
+			ProjectID:   lo.ToPtr(1 + i%2),
+			Action:      "alert.create",
+			TargetType:  "alert",
+		}))
+	}
+	require.NoError(t, store.CreateAuditLog(ctx, &model.AuditLog{WorkspaceID: 1, Action: "workspace.update", TargetType: "workspace"}))
+	require.NoError(t, store.CreateAuditLog(ctx, &model.AuditLog{WorkspaceID: 2, Action: "workspace.update", TargetType: "workspace"}))
+
+	connection, err := store.ListAuditLogs(ctx, 1, ListAuditLogsParams{})
+	require.NoError(t, err)
+	assert.Len(t, connection.Edges, AUDIT_LOG_LIMIT)
+	assert.True(t, connection.PageInfo.HasNextPage)
+	assert.False(t, connection.PageInfo.HasPreviousPage)
+	assert.Equal(t, "workspace.update", connection.Edges[0].Node.Action)
+
+	next, err := store.ListAuditLogs(ctx, 1, ListAuditLogsParams{After: &connection.PageInfo.EndCursor})
+	require.NoError(t, err)
+	assert.Len(t, next.Edges, 6)
+	assert.False(t, next.PageInfo.HasNextPage)
+	assert.True(t, next.PageInfo.HasPreviousPage)
+
+	previous, err := store.ListAuditLogs(ctx, 1, ListAuditLogsParams{Before: &next.PageInfo.StartCursor})
+	require.NoError(t, err)
+	assert.Len(t, previous.Edges, AUDIT_LOG_LIMIT)
+	assert.Equal(t, connection.Edges[0].Cursor, previous.Edges[0].Cursor)
+
+	filtered, err := store.ListAuditLogs(ctx, 1, ListAuditLogsParams{ProjectID: lo.ToPtr(2), TargetType: lo.ToPtr("alert")})
+	require.NoError(t, err)
+	assert.Len(t, filtered.Edges, 27)
+
+	filtered, err = store.ListAuditLogs(ctx, 1, ListAuditLogsParams{StartDate: lo.ToPtr(time.Now().Add(time.Hour))})
+	require.NoError(t, err)
+	assert.Empty(t, filtered.Edges)
+
+	exported, err := store.ExportAuditLogs(ctx, 1, ListAuditLogsParams{Action: lo.ToPtr("workspace.update")})
+	require.NoError(t, err)
+	assert.Len(t, exported, 1)
+	assert.Equal(t, 1, exported[0].WorkspaceID)
+}
+
+func TestDeleteExpiredAuditLogs(t *testing.T) {
+	ctx := context.Background()
+
+	defer teardown(t)
+
+	workspace := model.Workspace{}
+	store.DB.Create(&workspace)
+	_, err := store.UpdateAllWorkspaceSettings(ctx, workspace.ID, map[string]interface{}{"AuditLogRetentionDays": 7})
+	require.NoError(t, err)
+
+	// workspaces without settings use the default retention
+	for _, auditLog := range []*model.AuditLog{
+		{WorkspaceID: workspace.ID, Action: "old", CreatedAt: time.Now().AddDate(0, 0, -8)},
+		{WorkspaceID: workspace.ID, Action: "new", CreatedAt: time.Now().AddDate(0, 0, -6)},
+		{WorkspaceID: workspace.ID + 1, Action: "old", CreatedAt: time.Now().AddDate(0, 0, -DEFAULT_AUDIT_LOG_RETENTION_DAYS-1)},
+		{WorkspaceID: workspace.ID + 1, Action: "new", CreatedAt: time.Now().AddDate(0, 0, -8)},
+	} {
+		require.NoError(t, store.CreateAuditLog(ctx, auditLog))
+	}
+
+	deleted, err := store.DeleteExpiredAuditLogs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	var remaining []*model.AuditLog
+	require.NoError(t, store.DB.Find(&remaining).Error)
+	assert.Len(t, remaining, 2)
+	for _, auditLog := range remaining {
+		assert.Equal(t, "new", auditLog.Action)
+	}
+}
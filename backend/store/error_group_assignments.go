@@ -0,0 +1,213 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	kafka_queue "github.com/highlight-run/highlight/backend/kafka-queue"
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/highlight-run/highlight/backend/redis"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+func getErrorGroupAssignmentRulesKey(projectID int) string {
+	return fmt.Sprintf("error-group-assignment-rules-%d", projectID)
+}
+
+// ValidateAssignee checks that at most one of an admin and a team is set.
+// The admin must have access to the project.
+func (store *Store) ValidateAssignee(ctx context.Context, projectID int, assigneeAdminID *int, assigneeTeam *string) error {
+	if assigneeAdminID != nil && assigneeTeam != nil {
+		return errors.New("an error group is assigned to either an admin or a team")
+	}
+	if assigneeTeam != nil && *assigneeTeam == "" {
+		return errors.New("assignee team must not be empty")
+	}
+	if assigneeAdminID == nil {
+		return nil
+	}
+	_, err := store.GetProjectAdmin(ctx, projectID, *assigneeAdminID)
+	return err
+}
+
+// GetProjectAdmin returns the admin when they are a member of the project's workspace with access to the project.
+func (store *Store) GetProjectAdmin(ctx context.Context, projectID int, adminID int) (*model.Admin, error) {
+	var admin model.Admin
+	if err := AssertRecordFound(store.DB.WithContext(ctx).Model(&model.Admin{}).Where(`
+		id = ?
+		AND id IN (
+			SELECT wa.admin_id
+			FROM workspace_admins wa
+			INNER JOIN projects p ON p.workspace_id = wa.workspace_id
+			WHERE p.id = ?
+			AND (
+				wa.role = 'ADMIN'
+				OR wa.project_ids IS NULL
+				OR p.id = ANY(wa.project_ids)
+			)
+		)
+	`, adminID, projectID).Take(&admin)); err != nil {
+		return nil, errors.Wrap(err, "admin is not a member of the project")
+	}
+	return &admin, nil
+}
+
+// AssignErrorGroup assigns the error group to an admin or a team, or unassigns it when both are nil.
+// admin is nil when the group is assigned by an auto-assignment rule.
+// Returns false when the assignee did not change.
+func (store *Store) AssignErrorGroup(ctx context.Context, admin *model.Admin, errorGroup *model.ErrorGroup, assigneeAdminID *int, assigneeTeam *string, rule *model.ErrorGroupAssignmentRule) (bool, error) {
+	if lo.FromPtr(errorGroup.AssigneeAdminID) == lo.FromPtr(assigneeAdminID) && lo.FromPtr(errorGroup.AssigneeTeam) == lo.FromPtr(assigneeTeam) {
+		return false, nil
+	}
+
+	eventType := model.ErrorGroupAssignedEvent
+	if assigneeAdminID == nil && assigneeTeam == nil {
+		eventType = model.ErrorGroupUnassignedEvent
+	}
+	eventData := model.JSONB{
+		"AssigneeAdminID":         assigneeAdminID,
+		"AssigneeTeam":            assigneeTeam,
+		"PreviousAssigneeAdminID": errorGroup.AssigneeAdminID,
+		"PreviousAssigneeTeam":    errorGroup.AssigneeTeam,
+	}
+	if rule != nil {
+		eventData["AssignmentRuleID"] = rule.ID
+	}
+	var adminID int
+	if admin != nil {
+		adminID = admin.ID
+	}
+
+	assigned := true
+	if err := store.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&model.ErrorGroup{Model: model.Model{ID: errorGroup.ID}})
+		if rule != nil {
+			// concurrent errors of the group only apply the rule once
+			query = query.Where("assignee_admin_id IS NULL AND assignee_team IS NULL")
+		}
+		result := query.Updates(map[string]interface{}{
+			"AssigneeAdminID": assigneeAdminID,
+			"AssigneeTeam":    assigneeTeam,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			assigned = false
+			return nil
+		}
+		return tx.Create(&model.ErrorGroupActivityLog{
+			ErrorGroupID: errorGroup.ID,
+			AdminID:      adminID,
+			EventType:    eventType,
+			EventData:    eventData,
+		}).Error
+	}); err != nil || !assigned {
+		return false, err
+	}
+	errorGroup.AssigneeAdminID = assigneeAdminID
+	errorGroup.AssigneeTeam = assigneeTeam
+
+	return true, store.DataSyncQueue.Submit(ctx, strconv.Itoa(errorGroup.ID), &kafka_queue.Message{Type: kafka_queue.ErrorGroupDataSync, ErrorGroupDataSync: &kafka_queue.ErrorGroupDataSyncArgs{ErrorGroupID: errorGroup.ID}})
+}
+
+// HasAssignmentHistory reports whether the error group was ever assigned or unassigned.
+// Auto-assignment rules skip such groups so that manually unassigned groups stay unassigned.
+func (store *Store) HasAssignmentHistory(ctx context.Context, errorGroupID int) (bool, error) {
+	var count int64
+	if err := store.DB.WithContext(ctx).Model(&model.ErrorGroupActivityLog{}).
+		Where("error_group_id = ?", errorGroupID).
+		Where("event_type IN ?", []model.ErrorGroupEventType{model.ErrorGroupAssignedEvent, model.ErrorGroupUnassignedEvent}).
+		Limit(1).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetErrorGroupAssignmentRules returns the project's assignment rules in the order they are evaluated.
+func (store *Store) GetErrorGroupAssignmentRules(ctx context.Context, projectID int, opts ...redis.Option) ([]*model.ErrorGroupAssignmentRule, error) {
+	rules, err := redis.CachedEval(ctx, store.Redis, getErrorGroupAssignmentRulesKey(projectID), 250*time.Millisecond, time.Minute, func() (*[]*model.ErrorGroupAssignmentRule, error) {
+		rules := []*model.ErrorGroupAssignmentRule{}
+		if err := store.DB.WithContext(ctx).Where(&model.ErrorGroupAssignmentRule{ProjectID: projectID}).Order("priority ASC, id ASC").Find(&rules).Error; err != nil {
+			return nil, err
+		}
+		return &rules, nil
+	}, opts...)
+	if err != nil || rules == nil {
+		return nil, err
+	}
+	return *rules, nil
+}
+
+func (store *Store) CreateErrorGroupAssignmentRule(ctx context.Context, rule *model.ErrorGroupAssignmentRule) error {
+	if err := store.DB.WithContext(ctx).Create(rule).Error; err != nil {
+		return err
+	}
+	return store.Redis.Cache.Delete(ctx, getErrorGroupAssignmentRulesKey(rule.ProjectID))
+}
+
+func (store *Store) UpdateErrorGroupAssignmentRule(ctx context.Context, rule *model.ErrorGroupAssignmentRule) error {
+	if err := store.DB.WithContext(ctx).Select("*").Omit("created_at").Updates(rule).Error; err != nil {
+		return err
+	}
+	return store.Redis.Cache.Delete(ctx, getErrorGroupAssignmentRulesKey(rule.ProjectID))
+}
+
+func (store *Store) DeleteErrorGroupAssignmentRule(ctx context.Context, rule *model.ErrorGroupAssignmentRule) error {
+	if err := store.DB.WithContext(ctx).Delete(rule).Error; err != nil {
+		return err
+	}
+	return store.Redis.Cache.Delete(ctx, getErrorGroupAssignmentRulesKey(rule.ProjectID))
+}
+
+func assigneeDestinationsQuery(tx *gorm.DB, projectID int, assigneeAdminID *int, assigneeTeam *string) *gorm.DB {
+	tx = tx.Where("project_id = ?", projectID)
+	if assigneeAdminID != nil {
+		return tx.Where("assignee_admin_id = ?", *assigneeAdminID)
+	}
+	return tx.Where("assignee_team = ?", lo.FromPtr(assigneeTeam))
+}
+
+// GetAssigneeDestinations returns where the admin or team is notified about their assigned error groups.
+func (store *Store) GetAssigneeDestinations(ctx context.Context, projectID int, assigneeAdminID *int, assigneeTeam *string) ([]*model.AssigneeDestination, error) {
+	destinations := []*model.AssigneeDestination{}
+	if assigneeAdminID == nil && assigneeTeam == nil {
+		return destinations, nil
+	}
+	if err := assigneeDestinationsQuery(store.DB.WithContext(ctx), projectID, assigneeAdminID, assigneeTeam).
+		Order("id ASC").
+		Find(&destinations).Error; err != nil {
+		return nil, err
+	}
+	return destinations, nil
+}
+
+// ReplaceAssigneeDestinations replaces the destinations of the admin or team.
+func (store *Store) ReplaceAssigneeDestinations(ctx context.Context, projectID int, assigneeAdminID *int, assigneeTeam *string, destinations []*model.AssigneeDestination) ([]*model.AssigneeDestination, error) {
+	if assigneeAdminID == nil && assigneeTeam == nil {
+		return nil, errors.New("either an admin or a team is required")
+	}
+	for _, destination := range destinations {
+		destination.ProjectID = projectID
+		destination.AssigneeAdminID = assigneeAdminID
+		destination.AssigneeTeam = assigneeTeam
+	}
+
+	if err := store.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := assigneeDestinationsQuery(tx, projectID, assigneeAdminID, assigneeTeam).Delete(&model.AssigneeDestination{}).Error; err != nil {
+			return err
+		}
+		if len(destinations) == 0 {
+			return nil
+		}
+		return tx.Create(&destinations).Error
+	}); err != nil {
+		return nil, err
+	}
+	return destinations, nil
+}
@@ -0,0 +1,135 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/smithy-go/ptr"
+	"github.com/highlight-run/highlight/backend/model"
+	modelInputs "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssignErrorGroup(t *testing.T) {
+	ctx := context.Background()
+
+	defer teardown(t)
+
+	workspace := model.Workspace{}
+	store.DB.Create(&workspace)
+	project := model.Project{WorkspaceID: workspace.ID}
+	store.DB.Create(&project)
+	admin := model.Admin{Email: ptr.String("assignee@example.com")}
+	store.DB.Create(&admin)
+	outsider := model.Admin{}
+	store.DB.Create(&outsider)
+	store.DB.Create(&model.WorkspaceAdmin{AdminID: admin.ID, WorkspaceID: workspace.ID})
+
+	assert.NoError(t, store.ValidateAssignee(ctx, project.ID, &admin.ID, nil))
+	assert.NoError(t, store.ValidateAssignee(ctx, project.ID, nil, ptr.String("@highlight/payments")))
+	assert.Error(t, store.ValidateAssignee(ctx, project.ID, &outsider.ID, nil))
+	assert.Error(t, store.ValidateAssignee(ctx, project.ID, &admin.ID, ptr.String("@highlight/payments")))
+
+	errorGroup := model.ErrorGroup{ProjectID: project.ID, State: modelInputs.ErrorStateOpen}
+	store.DB.Create(&errorGroup)
+
+	hasHistory, err := store.HasAssignmentHistory(ctx, errorGroup.ID)
+	assert.NoError(t, err)
+	assert.False(t, hasHistory)
+
+	rule := &model.ErrorGroupAssignmentRule{Model: model.Model{ID: 1}, ProjectID: project.ID, AssigneeTeam: ptr.String("@highlight/payments")}
+	assigned, err := store.AssignErrorGroup(ctx, nil, &errorGroup, nil, rule.AssigneeTeam, rule)
+	assert.NoError(t, err)
+	assert.True(t, assigned)
+
+	// rules do not override an existing assignee
+	stale := model.ErrorGroup{Model: errorGroup.Model, ProjectID: project.ID}
+	assigned, err = store.AssignErrorGroup(ctx, nil, &stale, &admin.ID, nil, rule)
+	assert.NoError(t, err)
+	assert.False(t, assigned)
+
+	assigned, err = store.AssignErrorGroup(ctx, &admin, &errorGroup, &admin.ID, nil, nil)
+	assert.NoError(t, err)
+	assert.True(t, assigned)
+
+	assigned, err = store.AssignErrorGroup(ctx, &admin, &errorGroup, &admin.ID, nil, nil)
+	assert.NoError(t, err)
+	assert.False(t, assigned)
+
+	assigned, err = store.AssignErrorGroup(ctx, &admin, &errorGroup, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.True(t, assigned)
+
+	var updated model.ErrorGroup
+	store.DB.Where("id = ?", errorGroup.ID).Take(&updated)
+	assert.Nil(t, updated.AssigneeAdminID)
+	assert.Nil(t, updated.AssigneeTeam)
+
+	logs, err := store.GetErrorGroupActivityLogs(ctx, errorGroup.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.ErrorGroupEventType{model.ErrorGroupAssignedEvent, model.ErrorGroupAssignedEvent, model.ErrorGroupUnassignedEvent}, []model.ErrorGroupEventType{logs[0].EventType, logs[1].EventType, logs[2].EventType})
+
+	hasHistory, err = store.HasAssignmentHistory(ctx, errorGroup.ID)
+	assert.NoError(t, err)
+	assert.True(t, hasHistory)
+}
+
+func TestAssigneeDestinations(t *testing.T) {
+	ctx := context.Background()
+
+	defer teardown(t)
+
+	project := model.Project{}
+	store.DB.Create(&project)
+
+	team := ptr.String("@highlight/payments")
+	destinations, err := store.ReplaceAssigneeDestinations(ctx, project.ID, nil, team, []*model.AssigneeDestination{
+		{DestinationType: modelInputs.AlertDestinationTypeSlack, TypeID: "C123", TypeName: "#payments"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, destinations, 1)
+
+	destinations, err = store.ReplaceAssigneeDestinations(ctx, project.ID, nil, team, []*model.AssigneeDestination{
+		{DestinationType: modelInputs.AlertDestinationTypeEmail, TypeID: "payments@example.com", TypeName: "payments@example.com"},
+	})
+	assert.NoError(t, err)
+
+	destinations, err = store.GetAssigneeDestinations(ctx, project.ID, nil, team)
+	assert.NoError(t, err)
+	assert.Len(t, destinations, 1)
+	assert.Equal(t, modelInputs.AlertDestinationTypeEmail, destinations[0].DestinationType)
+
+	destinations, err = store.GetAssigneeDestinations(ctx, project.ID, nil, ptr.String("@highlight/billing"))
+	assert.NoError(t, err)
+	assert.Empty(t, destinations)
+
+	_, err = store.ReplaceAssigneeDestinations(ctx, project.ID, nil, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestErrorGroupAssignmentRulesCacheInvalidation(t *testing.T) {
+	ctx := context.Background()
+
+	defer teardown(t)
+	setProductionEnv(t)
+
+	project := model.Project{}
+	store.DB.Create(&project)
+
+	rule := model.ErrorGroupAssignmentRule{ProjectID: project.ID, Name: "payments", AssigneeTeam: ptr.String("@highlight/payments")}
+	assert.NoError(t, store.CreateErrorGroupAssignmentRule(ctx, &rule))
+	rules, err := store.GetErrorGroupAssignmentRules(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.Len(t, rules, 1)
+
+	rule.AssigneeTeam = ptr.String("@highlight/billing")
+	assert.NoError(t, store.UpdateErrorGroupAssignmentRule(ctx, &rule))
+	rules, err = store.GetErrorGroupAssignmentRules(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "@highlight/billing", ptr.ToString(rules[0].AssigneeTeam))
+
+	assert.NoError(t, store.DeleteErrorGroupAssignmentRule(ctx, &rule))
+	rules, err = store.GetErrorGroupAssignmentRules(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+}
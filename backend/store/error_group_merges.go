@@ -0,0 +1,278 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/highlight-run/highlight/backend/errorgroups"
+	kafka_queue "github.com/highlight-run/highlight/backend/kafka-queue"
+	"github.com/highlight-run/highlight/backend/model"
+	privateModel "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/highlight-run/highlight/backend/redis"
+	e "github.com/pkg/errors"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+const errorObjectDataSyncBatchSize = 10_000
+
+func getErrorGroupMergeKey(errorGroupID int) string {
+	return fmt.Sprintf("error-group-merge-%d", errorGroupID)
+}
+
+// GetMergedErrorGroupID returns the error group that errors matched to errorGroupID should be added to.
+func (store *Store) GetMergedErrorGroupID(ctx context.Context, errorGroupID int) (int, error) {
+	targetID, err := redis.CachedEval(ctx, store.Redis, getErrorGroupMergeKey(errorGroupID), 250*time.Millisecond, time.Minute, func() (*int, error) {
+		var merges []*model.ErrorGroupMerge
+		if err := store.DB.WithContext(ctx).Where(&model.ErrorGroupMerge{SourceErrorGroupID: errorGroupID}).Limit(1).Find(&merges).Error; err != nil {
+			return nil, err
+		}
+		if len(merges) == 0 {
+			return &errorGroupID, nil
+		}
+		return &merges[0].TargetErrorGroupID, nil
+	})
+	if err != nil {
+		return errorGroupID, err
+	}
+	return *targetID, nil
+}
+
+func (store *Store) submitErrorGroupDataSync(ctx context.Context, errorGroupIDs []int, errorObjectIDs []int) error {
+	for _, errorGroupID := range errorGroupIDs {
+		if err := store.DataSyncQueue.Submit(ctx, strconv.Itoa(errorGroupID), &kafka_queue.Message{Type: kafka_queue.ErrorGroupDataSync, ErrorGroupDataSync: &kafka_queue.ErrorGroupDataSyncArgs{ErrorGroupID: errorGroupID}}); err != nil {
+			return err
+		}
+	}
+	// rewrite the clickhouse error rows with their new error group
+	for _, chunk := range lo.Chunk(errorObjectIDs, errorObjectDataSyncBatchSize) {
+		messages := lo.Map(chunk, func(id int, _ int) kafka_queue.RetryableMessage {
+			return &kafka_queue.Message{Type: kafka_queue.ErrorObjectDataSync, ErrorObjectDataSync: &kafka_queue.ErrorObjectDataSyncArgs{ErrorObjectID: id}}
+		})
+		if err := store.DataSyncQueue.Submit(ctx, "", messages...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MergeErrorGroups moves the error objects, fingerprints and comments of the source error groups
+// into the target error group. The merge is recorded so that errors matching a source group
+// are added to the target group from then on, and the source groups are left in the MERGED state.
+func (store *Store) MergeErrorGroups(ctx context.Context, admin model.Admin, target *model.ErrorGroup, sources []*model.ErrorGroup) (*model.ErrorGroup, error) {
+	sourceIDs := lo.Map(sources, func(eg *model.ErrorGroup, _ int) int { return eg.ID })
+	if len(sourceIDs) == 0 {
+		return nil, e.New("no error groups to merge")
+	}
+	for _, source := range sources {
+		if source.ID == target.ID {
+			return nil, e.New("cannot merge an error group into itself")
+		}
+		if source.ProjectID != target.ProjectID {
+			return nil, e.New("cannot merge error groups from different projects")
+		}
+		if source.State == privateModel.ErrorStateMerged {
+			return nil, e.Errorf("error group %d was already merged", source.ID)
+		}
+	}
+	if mergedID, err := store.GetMergedErrorGroupID(ctx, target.ID); err != nil {
+		return nil, err
+	} else if mergedID != target.ID {
+		return nil, e.Errorf("error group %d was merged into error group %d", target.ID, mergedID)
+	}
+
+	var errorObjectIDs []int
+	if err := store.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.ErrorObject{}).Where("error_group_id IN ?", sourceIDs).Pluck("id", &errorObjectIDs).Error; err != nil {
+			return e.Wrap(err, "error querying merged error objects")
+		}
+		if err := tx.Model(&model.ErrorObject{}).Where("error_group_id IN ?", sourceIDs).Update("error_group_id", target.ID).Error; err != nil {
+			return e.Wrap(err, "error moving error objects")
+		}
+		if err := tx.Model(&model.ErrorFingerprint{}).Where("error_group_id IN ?", sourceIDs).Update("error_group_id", target.ID).Error; err != nil {
+			return e.Wrap(err, "error moving error fingerprints")
+		}
+		// issue links are attachments of the error comments, so they move with them
+		if err := tx.Model(&model.ErrorComment{}).Where("error_id IN ?", sourceIDs).Updates(map[string]interface{}{
+			"error_id":        target.ID,
+			"error_secure_id": target.SecureID,
+		}).Error; err != nil {
+			return e.Wrap(err, "error moving error comments")
+		}
+		// merged groups are kept for their links, but are no longer searched or alerted on
+		if err := tx.Model(&model.ErrorGroup{}).Where("id IN ?", sourceIDs).Updates(map[string]interface{}{
+			"State":        privateModel.ErrorStateMerged,
+			"SnoozedUntil": nil,
+		}).Error; err != nil {
+			return e.Wrap(err, "error marking error groups as merged")
+		}
+		if err := tx.Where("error_group_id IN ?", sourceIDs).Delete(&model.ErrorGroupEmbeddings{}).Error; err != nil {
+			return e.Wrap(err, "error deleting merged error group embeddings")
+		}
+		// groups previously merged into a source group now route to the target
+		if err := tx.Model(&model.ErrorGroupMerge{}).Where("target_error_group_id IN ?", sourceIDs).Update("target_error_group_id", target.ID).Error; err != nil {
+			return e.Wrap(err, "error updating previous error group merges")
+		}
+		merges := lo.Map(sourceIDs, func(id int, _ int) *model.ErrorGroupMerge {
+			return &model.ErrorGroupMerge{ProjectID: target.ProjectID, SourceErrorGroupID: id, TargetErrorGroupID: target.ID, AdminID: admin.ID}
+		})
+		if err := tx.Create(&merges).Error; err != nil {
+			return e.Wrap(err, "error saving error group merges")
+		}
+
+		logs := []*model.ErrorGroupActivityLog{{
+			ErrorGroupID: target.ID,
+			AdminID:      admin.ID,
+			EventType:    model.ErrorGroupMergedEvent,
+			EventData:    model.JSONB{"MergedErrorGroupIDs": sourceIDs},
+		}}
+		for _, id := range sourceIDs {
+			logs = append(logs, &model.ErrorGroupActivityLog{
+				ErrorGroupID: id,
+				AdminID:      admin.ID,
+				EventType:    model.ErrorGroupMergedEvent,
+				EventData:    model.JSONB{"MergedIntoErrorGroupID": target.ID},
+			})
+		}
+		return tx.Create(&logs).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	var merged []int
+	if err := store.DB.WithContext(ctx).Model(&model.ErrorGroupMerge{}).Where("target_error_group_id = ?", target.ID).Pluck("source_error_group_id", &merged).Error; err != nil {
+		return nil, err
+	}
+	// errors must not be added to a merged group after the merge, so the cached routes are deleted in every environment
+	for _, id := range merged {
+		if err := store.Redis.Cache.Delete(ctx, getErrorGroupMergeKey(id)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := store.submitErrorGroupDataSync(ctx, append([]int{target.ID}, sourceIDs...), errorObjectIDs); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// getErrorObjectGrouping returns the fingerprints that ingest matched the error object to its group with,
+// and the key that the group of identical error objects is cached under, see HandleErrorAndGroup.
+func getErrorObjectGrouping(project *model.Project, rules errorgroups.GroupingRules, errorObj *model.ErrorObject) ([]*model.ErrorFingerprint, string) {
+	var structuredStackTrace []*privateModel.ErrorTrace
+	if errorObj.MappedStackTrace != nil {
+		if err := json.Unmarshal([]byte(*errorObj.MappedStackTrace), &structuredStackTrace); err != nil {
+			structuredStackTrace = nil
+		}
+	}
+
+	grouping := errorgroups.ApplyGroupingRules(rules, errorObj, structuredStackTrace)
+	key := errorgroups.GetKey(project.ID, errorObj, grouping.StackTrace)
+	if grouping.Fingerprint != nil {
+		return []*model.ErrorFingerprint{{
+			ProjectID: project.ID,
+			Type:      model.Fingerprint.Custom,
+			Value:     *grouping.Fingerprint,
+		}}, fmt.Sprintf("%s-%s", key, *grouping.Fingerprint)
+	}
+
+	fingerprints := errorgroups.GetFingerprints(project.ID, grouping.StackTrace)
+	fingerprints = append(fingerprints, errorgroups.GetJSONFingerprints(project.ID, errorObj.Event, project.ErrorJsonPaths)...)
+	return fingerprints, key
+}
+
+// SplitErrorGroup moves the error objects out of the source error group into a new error group.
+// The fingerprints of the error objects move with them, so that matching errors are added to the new group.
+func (store *Store) SplitErrorGroup(ctx context.Context, admin model.Admin, source *model.ErrorGroup, errorObjectIDs []int) (*model.ErrorGroup, error) {
+	var errorObjects []*model.ErrorObject
+	if err := store.DB.WithContext(ctx).Where("id IN ? AND error_group_id = ?", errorObjectIDs, source.ID).Order("id DESC").Find(&errorObjects).Error; err != nil {
+		return nil, e.Wrap(err, "error querying error objects to split")
+	}
+	if len(errorObjects) == 0 {
+		return nil, e.New("no error objects of the error group to split")
+	}
+	if len(errorObjects) != len(lo.Uniq(errorObjectIDs)) {
+		return nil, e.New("error objects do not belong to the error group")
+	}
+
+	project, err := store.GetProject(ctx, source.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := store.GetCompiledErrorGroupingRules(ctx, source.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	// like an error group, the new group is matched by the fingerprints of its most recent error object
+	fingerprints, _ := getErrorObjectGrouping(project, rules, errorObjects[0])
+	cacheKeys := lo.Uniq(lo.Map(errorObjects, func(eo *model.ErrorObject, _ int) string {
+		_, key := getErrorObjectGrouping(project, rules, eo)
+		return key
+	}))
+
+	// the new group is described by the most recent of its error objects
+	latest := errorObjects[0]
+	errorGroup := &model.ErrorGroup{
+		ProjectID:        source.ProjectID,
+		Event:            latest.Event,
+		StackTrace:       lo.FromPtr(latest.StackTrace),
+		MappedStackTrace: latest.MappedStackTrace,
+		Type:             latest.Type,
+		State:            privateModel.ErrorStateOpen,
+		ServiceName:      latest.ServiceName,
+	}
+	ids := lo.Map(errorObjects, func(eo *model.ErrorObject, _ int) int { return eo.ID })
+	if err := store.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(errorGroup).Error; err != nil {
+			return e.Wrap(err, "error creating split error group")
+		}
+		if err := tx.Model(&model.ErrorObject{}).Where("id IN ?", ids).Update("error_group_id", errorGroup.ID).Error; err != nil {
+			return e.Wrap(err, "error moving error objects")
+		}
+		if len(fingerprints) > 0 {
+			values := lo.Map(fingerprints, func(f *model.ErrorFingerprint, _ int) []interface{} {
+				return []interface{}{f.Type, f.Value, f.Index}
+			})
+			if err := tx.Model(&model.ErrorFingerprint{}).
+				Where("error_group_id = ?", source.ID).
+				Where("(type, value, index) IN ?", values).
+				Update("error_group_id", nil).Error; err != nil {
+				return e.Wrap(err, "error removing split fingerprints from the error group")
+			}
+			for _, f := range fingerprints {
+				f.ErrorGroupId = errorGroup.ID
+			}
+			if err := tx.Create(&fingerprints).Error; err != nil {
+				return e.Wrap(err, "error moving error fingerprints")
+			}
+		}
+		return tx.Create([]*model.ErrorGroupActivityLog{{
+			ErrorGroupID: source.ID,
+			AdminID:      admin.ID,
+			EventType:    model.ErrorGroupSplitEvent,
+			EventData:    model.JSONB{"SplitErrorGroupID": errorGroup.ID, "ErrorObjectIDs": ids},
+		}, {
+			ErrorGroupID: errorGroup.ID,
+			AdminID:      admin.ID,
+			EventType:    model.ErrorGroupSplitEvent,
+			EventData:    model.JSONB{"SplitFromErrorGroupID": source.ID, "ErrorObjectIDs": ids},
+		}}).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	// identical errors are grouped from the cache, which would add them back to the source group
+	for _, key := range cacheKeys {
+		if err := store.Redis.Cache.Delete(ctx, key); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := store.submitErrorGroupDataSync(ctx, []int{source.ID, errorGroup.ID}, ids); err != nil {
+		return nil, err
+	}
+	return errorGroup, nil
+}
@@ -0,0 +1,108 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/smithy-go/ptr"
+	"github.com/highlight-run/highlight/backend/model"
+	privateModel "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeErrorGroups(t *testing.T) {
+	ctx := context.Background()
+	defer teardown(t)
+
+	admin := model.Admin{}
+	store.DB.Create(&admin)
+
+	target := model.ErrorGroup{ProjectID: 1, State: privateModel.ErrorStateOpen, Event: "target"}
+	source := model.ErrorGroup{ProjectID: 1, State: privateModel.ErrorStateOpen, Event: "source"}
+	store.DB.Create(&target)
+	store.DB.Create(&source)
+
+	errorObject := model.ErrorObject{ProjectID: 1, ErrorGroupID: source.ID}
+	store.DB.Create(&errorObject)
+	fingerprint := model.ErrorFingerprint{ProjectID: 1, ErrorGroupId: source.ID, Type: model.Fingerprint.StackFrameCode, Value: "code"}
+	store.DB.Create(&fingerprint)
+	comment := model.ErrorComment{ProjectID: 1, ErrorId: source.ID, ErrorSecureId: source.SecureID}
+	store.DB.Create(&comment)
+
+	_, err := store.MergeErrorGroups(ctx, admin, &target, []*model.ErrorGroup{&target})
+	assert.Error(t, err)
+
+	_, err = store.MergeErrorGroups(ctx, admin, &target, []*model.ErrorGroup{&source})
+	assert.NoError(t, err)
+
+	store.DB.First(&errorObject, errorObject.ID)
+	assert.Equal(t, target.ID, errorObject.ErrorGroupID)
+	store.DB.First(&fingerprint, fingerprint.ID)
+	assert.Equal(t, target.ID, fingerprint.ErrorGroupId)
+	store.DB.First(&comment, comment.ID)
+	assert.Equal(t, target.ID, comment.ErrorId)
+	assert.Equal(t, target.SecureID, comment.ErrorSecureId)
+
+	mergedID, err := store.GetMergedErrorGroupID(ctx, source.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, target.ID, mergedID)
+
+	// the source group is no longer open, so it is not searched or alerted on
+	store.DB.First(&source, source.ID)
+	assert.Equal(t, privateModel.ErrorStateMerged, source.State)
+	_, err = store.MergeErrorGroups(ctx, admin, &target, []*model.ErrorGroup{&source})
+	assert.Error(t, err)
+
+	logs, err := store.GetErrorGroupActivityLogs(ctx, source.ID)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 1)
+	assert.Equal(t, model.ErrorGroupMergedEvent, logs[0].EventType)
+
+	// a group can't be merged into a group that was merged away
+	other := model.ErrorGroup{ProjectID: 1, State: privateModel.ErrorStateOpen, Event: "other"}
+	store.DB.Create(&other)
+	_, err = store.MergeErrorGroups(ctx, admin, &source, []*model.ErrorGroup{&other})
+	assert.Error(t, err)
+}
+
+func TestSplitErrorGroup(t *testing.T) {
+	ctx := context.Background()
+	defer teardown(t)
+
+	admin := model.Admin{}
+	store.DB.Create(&admin)
+
+	project := model.Project{}
+	store.DB.Create(&project)
+
+	source := model.ErrorGroup{ProjectID: project.ID, State: privateModel.ErrorStateResolved, Event: "source"}
+	store.DB.Create(&source)
+	kept := model.ErrorObject{ProjectID: project.ID, ErrorGroupID: source.ID, Event: "source"}
+	split := model.ErrorObject{ProjectID: project.ID, ErrorGroupID: source.ID, Event: "split", StackTrace: ptr.String("[]"),
+		MappedStackTrace: ptr.String(`[{"fileName":"handler.go","functionName":"split","lineNumber":12}]`)}
+	store.DB.Create(&kept)
+	store.DB.Create(&split)
+	fingerprint := model.ErrorFingerprint{ProjectID: project.ID, ErrorGroupId: source.ID, Type: model.Fingerprint.StackFrameMetadata, Value: "handler.go;split;12;"}
+	store.DB.Create(&fingerprint)
+
+	_, err := store.SplitErrorGroup(ctx, admin, &source, []int{split.ID, 0})
+	assert.Error(t, err)
+
+	errorGroup, err := store.SplitErrorGroup(ctx, admin, &source, []int{split.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, "split", errorGroup.Event)
+	assert.Equal(t, privateModel.ErrorStateOpen, errorGroup.State)
+
+	store.DB.First(&split, split.ID)
+	assert.Equal(t, errorGroup.ID, split.ErrorGroupID)
+	store.DB.First(&kept, kept.ID)
+	assert.Equal(t, source.ID, kept.ErrorGroupID)
+
+	// the fingerprints of the split error objects move to the new group
+	var fingerprints []*model.ErrorFingerprint
+	store.DB.Where(&model.ErrorFingerprint{ProjectID: project.ID}).Order("id ASC").Find(&fingerprints)
+	assert.Len(t, fingerprints, 2)
+	assert.Zero(t, fingerprints[0].ErrorGroupId)
+	assert.Equal(t, errorGroup.ID, fingerprints[1].ErrorGroupId)
+	assert.Equal(t, fingerprint.Value, fingerprints[1].Value)
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/smithy-go/ptr"
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/highlight-run/highlight/backend/errorgroups"
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/highlight-run/highlight/backend/redis"
+)
+
+// compiled rules are cached as they are applied to every ingested error of the project
+var compiledGroupingRulesCache = expirable.NewLRU[int, *compiledGroupingRules](1024, nil, 10*time.Minute)
+
+type compiledGroupingRules struct {
+	// signature of the rules that were compiled, so that changed rules are compiled again
+	signature string
+	rules     errorgroups.GroupingRules
+}
+
+func getErrorGroupingRulesKey(projectID int) string {
+	return fmt.Sprintf("error-grouping-rules-%d", projectID)
+}
+
+// GetErrorGroupingRules returns the project's grouping rules in the order they are evaluated.
+func (store *Store) GetErrorGroupingRules(ctx context.Context, projectID int, opts ...redis.Option) ([]*model.ErrorGroupingRule, error) {
+	rules, err := redis.CachedEval(ctx, store.Redis, getErrorGroupingRulesKey(projectID), 250*time.Millisecond, time.Minute, func() (*[]*model.ErrorGroupingRule, error) {
+		rules := []*model.ErrorGroupingRule{}
+		if err := store.DB.WithContext(ctx).Where(&model.ErrorGroupingRule{ProjectID: projectID}).Order("priority ASC, id ASC").Find(&rules).Error; err != nil {
+			return nil, err
+		}
+		return &rules, nil
+	}, opts...)
+	if err != nil || rules == nil {
+		return nil, err
+	}
+	return *rules, nil
+}
+
+func groupingRulesSignature(rules []*model.ErrorGroupingRule) string {
+	var signature strings.Builder
+	for _, rule := range rules {
+		fmt.Fprintf(&signature, "%d|%s|%s|%s|%s|%d|%t\n", rule.ID, rule.Query, rule.Action, ptr.ToString(rule.FingerprintTemplate), ptr.ToString(rule.FramePattern), rule.Priority, rule.Disabled)
+	}
+	return signature.String()
+}
+
+// GetCompiledErrorGroupingRules returns the project's grouping rules prepared to be applied to errors.
+// The rules are only compiled again when the cached rules of the project change.
+func (store *Store) GetCompiledErrorGroupingRules(ctx context.Context, projectID int) (errorgroups.GroupingRules, error) {
+	rules, err := store.GetErrorGroupingRules(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	signature := groupingRulesSignature(rules)
+	if compiled, ok := compiledGroupingRulesCache.Get(projectID); ok && compiled.signature == signature {
+		return compiled.rules, nil
+	}
+	compiled := &compiledGroupingRules{signature: signature, rules: errorgroups.CompileGroupingRules(rules)}
+	compiledGroupingRulesCache.Add(projectID, compiled)
+	return compiled.rules, nil
+}
+
+func (store *Store) CreateErrorGroupingRule(ctx context.Context, rule *model.ErrorGroupingRule) error {
+	if err := store.DB.WithContext(ctx).Create(rule).Error; err != nil {
+		return err
+	}
+	return store.Redis.Cache.Delete(ctx, getErrorGroupingRulesKey(rule.ProjectID))
+}
+
+func (store *Store) UpdateErrorGroupingRule(ctx context.Context, rule *model.ErrorGroupingRule) error {
+	if err := store.DB.WithContext(ctx).Select("*").Omit("created_at").Updates(rule).Error; err != nil {
+		return err
+	}
+	return store.Redis.Cache.Delete(ctx, getErrorGroupingRulesKey(rule.ProjectID))
+}
+
+func (store *Store) DeleteErrorGroupingRule(ctx context.Context, rule *model.ErrorGroupingRule) error {
+	if err := store.DB.WithContext(ctx).Delete(rule).Error; err != nil {
+		return err
+	}
+	return store.Redis.Cache.Delete(ctx, getErrorGroupingRulesKey(rule.ProjectID))
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/smithy-go/ptr"
+	"github.com/highlight-run/highlight/backend/model"
+	modelInputs "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorGroupingRules(t *testing.T) {
+	ctx := context.Background()
+
+	defer teardown(t)
+
+	project := model.Project{}
+	store.DB.Create(&project)
+
+	rules, err := store.GetErrorGroupingRules(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+
+	second := model.ErrorGroupingRule{ProjectID: project.ID, Name: "second", Action: modelInputs.ErrorGroupingRuleActionNotInApp, FramePattern: ptr.String("node_modules"), Priority: 2}
+	first := model.ErrorGroupingRule{ProjectID: project.ID, Name: "first", Action: modelInputs.ErrorGroupingRuleActionFingerprint, FingerprintTemplate: ptr.String("{{ event }}"), Priority: 1}
+	assert.NoError(t, store.CreateErrorGroupingRule(ctx, &second))
+	assert.NoError(t, store.CreateErrorGroupingRule(ctx, &first))
+
+	rules, err = store.GetErrorGroupingRules(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, []string{rules[0].Name, rules[1].Name})
+
+	second.Disabled = true
+	assert.NoError(t, store.UpdateErrorGroupingRule(ctx, &second))
+	rules, err = store.GetErrorGroupingRules(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.True(t, rules[1].Disabled)
+
+	assert.NoError(t, store.DeleteErrorGroupingRule(ctx, &first))
+	rules, err = store.GetErrorGroupingRules(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.Len(t, rules, 1)
+}
+
+func TestErrorGroupingRulesCacheInvalidation(t *testing.T) {
+	ctx := context.Background()
+
+	defer teardown(t)
+	setProductionEnv(t)
+
+	project := model.Project{}
+	store.DB.Create(&project)
+
+	rule := model.ErrorGroupingRule{ProjectID: project.ID, Name: "rule", Action: modelInputs.ErrorGroupingRuleActionNotInApp, FramePattern: ptr.String("node_modules")}
+	assert.NoError(t, store.CreateErrorGroupingRule(ctx, &rule))
+	rules, err := store.GetErrorGroupingRules(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.Len(t, rules, 1)
+
+	rule.FramePattern = ptr.String("vendor")
+	assert.NoError(t, store.UpdateErrorGroupingRule(ctx, &rule))
+	rules, err = store.GetErrorGroupingRules(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "vendor", ptr.ToString(rules[0].FramePattern))
+
+	assert.NoError(t, store.DeleteErrorGroupingRule(ctx, &rule))
+	rules, err = store.GetErrorGroupingRules(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+}
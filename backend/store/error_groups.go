@@ -142,14 +142,32 @@ func (store *Store) UpdateErrorGroupStateBySystem(ctx context.Context,
 func (store *Store) updateErrorGroupState(ctx context.Context,
 	admin *model.Admin, params UpdateErrorGroupParams) error {
 
+	updates := map[string]interface{}{
+		"State":        params.State,
+		"SnoozedUntil": params.SnoozedUntil,
+	}
+	if params.State == privateModel.ErrorStateResolved {
+		// errors from versions up to the resolving one reopen the group instead of regressing it
+		var serviceVersions []string
+		if err := store.DB.WithContext(ctx).Model(&model.ErrorObject{}).
+			Where("error_group_id = ?", params.ID).
+			Order("id DESC").
+			Limit(1).
+			Pluck("service_version", &serviceVersions).Error; err != nil {
+			return err
+		}
+		var resolvedInVersion *string
+		if len(serviceVersions) > 0 && serviceVersions[0] != "" {
+			resolvedInVersion = &serviceVersions[0]
+		}
+		updates["ResolvedInVersion"] = resolvedInVersion
+	}
+
 	if err := AssertRecordFound(store.DB.WithContext(ctx).Where(&model.ErrorGroup{
 		Model: model.Model{
 			ID: params.ID,
 		},
-	}).Model(&model.ErrorGroup{}).Clauses(clause.Returning{}).Updates(map[string]interface{}{
-		"State":        params.State,
-		"SnoozedUntil": params.SnoozedUntil,
-	})); err != nil {
+	}).Model(&model.ErrorGroup{}).Clauses(clause.Returning{}).Updates(updates)); err != nil {
 		return err
 	}
 
@@ -193,6 +211,10 @@ func getEventType(state privateModel.ErrorState) (model.ErrorGroupEventType, err
 		return model.ErrorGroupOpenedEvent, nil
 	case privateModel.ErrorStateIgnored:
 		return model.ErrorGroupIgnoredEvent, nil
+	case privateModel.ErrorStateRegressed:
+		return model.ErrorGroupRegressedEvent, nil
+	case privateModel.ErrorStateMerged:
+		return model.ErrorGroupMergedEvent, nil
 	}
 
 	return event, errors.New("unable to determine event type")
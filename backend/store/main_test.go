@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/highlight-run/highlight/backend/clickhouse"
+	"github.com/highlight-run/highlight/backend/env"
 	"github.com/highlight-run/highlight/backend/integrations"
 	kafka_queue "github.com/highlight-run/highlight/backend/kafka-queue"
 	"github.com/highlight-run/highlight/backend/redis"
@@ -49,3 +50,10 @@ func teardown(t *testing.T) {
 		t.Fatal(e.Wrap(err, "error clearing database"))
 	}
 }
+
+// setProductionEnv runs the rest of the test outside of the dev and test environments, e.g. where Redis.Del does nothing.
+func setProductionEnv(t *testing.T) {
+	environment := env.Config.Environment
+	env.Config.Environment = "production"
+	t.Cleanup(func() { env.Config.Environment = environment })
+}
@@ -0,0 +1,91 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/highlight-run/highlight/backend/redact"
+	"github.com/highlight-run/highlight/backend/redis"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func getRedactionRulesKey(projectID int) string {
+	return fmt.Sprintf("redaction-rules-%d", projectID)
+}
+
+// GetRedactionRules returns the project's redaction rules. The cached rules may have outdated redaction counts.
+func (store *Store) GetRedactionRules(ctx context.Context, projectID int, opts ...redis.Option) ([]*model.RedactionRule, error) {
+	rules, err := redis.CachedEval(ctx, store.Redis, getRedactionRulesKey(projectID), 250*time.Millisecond, time.Minute, func() (*[]*model.RedactionRule, error) {
+		rules := []*model.RedactionRule{}
+		if err := store.DB.WithContext(ctx).Where(&model.RedactionRule{ProjectID: projectID}).Order("id ASC").Find(&rules).Error; err != nil {
+			return nil, err
+		}
+		return &rules, nil
+	}, opts...)
+	if err != nil || rules == nil {
+		return nil, err
+	}
+	return *rules, nil
+}
+
+// GetRedactor returns the redactor for data ingested into the project, or nil if it has no enabled redaction rules.
+func (store *Store) GetRedactor(ctx context.Context, projectID int) (*redact.Redactor, error) {
+	rules, err := store.GetRedactionRules(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return redact.New(rules)
+}
+
+// RecordRedactions adds the redactions made by the redactor since the last call to the counts of its rules.
+// The counts are accumulated in redis as this runs for every ingested payload, see FlushRedactionCounts.
+func (store *Store) RecordRedactions(ctx context.Context, redactor *redact.Redactor) error {
+	return store.Redis.IncrementRedactionCounts(ctx, redactor.TakeCounts())
+}
+
+// FlushRedactionCounts writes the redaction counts accumulated since the last flush to the database.
+// Counts that fail to be written are kept for the next flush.
+func (store *Store) FlushRedactionCounts(ctx context.Context) (int, error) {
+	counts, err := store.Redis.TakeRedactionCounts(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var flushed int
+	for ruleID, count := range counts {
+		if err := store.DB.WithContext(ctx).Model(&model.RedactionRule{Model: model.Model{ID: ruleID}}).
+			UpdateColumn("redaction_count", gorm.Expr("redaction_count + ?", count)).Error; err != nil {
+			if err := store.Redis.IncrementRedactionCounts(ctx, counts); err != nil {
+				log.WithContext(ctx).WithError(err).Error("failed to restore redaction counts")
+			}
+			return flushed, err
+		}
+		delete(counts, ruleID)
+		flushed++
+	}
+	return flushed, nil
+}
+
+func (store *Store) CreateRedactionRule(ctx context.Context, rule *model.RedactionRule) error {
+	if err := store.DB.WithContext(ctx).Create(rule).Error; err != nil {
+		return err
+	}
+	return store.Redis.Cache.Delete(ctx, getRedactionRulesKey(rule.ProjectID))
+}
+
+func (store *Store) UpdateRedactionRule(ctx context.Context, rule *model.RedactionRule) error {
+	if err := store.DB.WithContext(ctx).Select("*").Omit("created_at", "redaction_count").Updates(rule).Error; err != nil {
+		return err
+	}
+	return store.Redis.Cache.Delete(ctx, getRedactionRulesKey(rule.ProjectID))
+}
+
+func (store *Store) DeleteRedactionRule(ctx context.Context, rule *model.RedactionRule) error {
+	if err := store.DB.WithContext(ctx).Delete(rule).Error; err != nil {
+		return err
+	}
+	return store.Redis.Cache.Delete(ctx, getRedactionRulesKey(rule.ProjectID))
+}
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/highlight-run/highlight/backend/model"
+	modelInputs "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/highlight-run/highlight/backend/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactionRules(t *testing.T) {
+	ctx := context.Background()
+
+	defer teardown(t)
+
+	project := model.Project{}
+	store.DB.Create(&project)
+
+	redactor, err := store.GetRedactor(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, redactor)
+
+	rule := model.RedactionRule{ProjectID: project.ID, Name: "emails", Detector: modelInputs.RedactionDetectorEmail}
+	assert.NoError(t, store.CreateRedactionRule(ctx, &rule))
+
+	redactor, err = store.GetRedactor(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "user [REDACTED] and [REDACTED]", redactor.String("user vadim@highlight.io and jay@highlight.io"))
+	assert.NoError(t, store.RecordRedactions(ctx, redactor))
+	assert.NoError(t, store.RecordRedactions(ctx, redactor))
+
+	// counts are accumulated during ingest until they are flushed
+	rules, err := store.GetRedactionRules(ctx, project.ID, redis.WithBypassCache(true))
+	assert.NoError(t, err)
+	assert.Len(t, rules, 1)
+	assert.Equal(t, int64(0), rules[0].RedactionCount)
+
+	flushed, err := store.FlushRedactionCounts(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, flushed)
+	flushed, err = store.FlushRedactionCounts(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, flushed)
+
+	// the cached rules are used for ingest, counts are read from the database
+	rules, err = store.GetRedactionRules(ctx, project.ID, redis.WithBypassCache(true))
+	assert.NoError(t, err)
+	assert.Len(t, rules, 1)
+	assert.Equal(t, int64(2), rules[0].RedactionCount)
+
+	// updating a rule keeps its redaction count
+	rule.Disabled = true
+	assert.NoError(t, store.UpdateRedactionRule(ctx, &rule))
+	rules, err = store.GetRedactionRules(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.True(t, rules[0].Disabled)
+	rules, err = store.GetRedactionRules(ctx, project.ID, redis.WithBypassCache(true))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), rules[0].RedactionCount)
+
+	redactor, err = store.GetRedactor(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, redactor)
+
+	assert.NoError(t, store.DeleteRedactionRule(ctx, &rule))
+	rules, err = store.GetRedactionRules(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+}
+
+func TestRedactionRulesCacheInvalidation(t *testing.T) {
+	ctx := context.Background()
+
+	defer teardown(t)
+	setProductionEnv(t)
+
+	project := model.Project{}
+	store.DB.Create(&project)
+
+	rule := model.RedactionRule{ProjectID: project.ID, Name: "emails", Detector: modelInputs.RedactionDetectorEmail}
+	assert.NoError(t, store.CreateRedactionRule(ctx, &rule))
+	redactor, err := store.GetRedactor(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, redactor)
+
+	rule.Disabled = true
+	assert.NoError(t, store.UpdateRedactionRule(ctx, &rule))
+	rules, err := store.GetRedactionRules(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.True(t, rules[0].Disabled)
+	redactor, err = store.GetRedactor(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, redactor)
+
+	assert.NoError(t, store.DeleteRedactionRule(ctx, &rule))
+	rules, err = store.GetRedactionRules(ctx, project.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+}
@@ -0,0 +1,150 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/highlight-run/highlight/backend/redis"
+	e "github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Number of releases returned by default
+const RELEASE_LIMIT = 50
+
+// How often the last seen time of a release is updated while it reports data
+const RELEASE_SEEN_INTERVAL = time.Hour
+
+func getReleaseKey(projectID int, version string) string {
+	return fmt.Sprintf("release-%d-%s", projectID, version)
+}
+
+// UpsertRelease creates the release the first time a version is seen,
+// and updates when it was last seen once per RELEASE_SEEN_INTERVAL.
+func (store *Store) UpsertRelease(ctx context.Context, projectID int, version string, seenAt time.Time) (*model.Release, error) {
+	if version == "" {
+		return nil, e.New("release version is required")
+	}
+	return redis.CachedEval(ctx, store.Redis, getReleaseKey(projectID, version), time.Second, RELEASE_SEEN_INTERVAL, func() (*model.Release, error) {
+		release := model.Release{
+			ProjectID:   projectID,
+			Version:     version,
+			FirstSeenAt: seenAt,
+			LastSeenAt:  &seenAt,
+		}
+		if err := store.DB.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "project_id"}, {Name: "version"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"last_seen_at": gorm.Expr("GREATEST(releases.last_seen_at, excluded.last_seen_at)"),
+			}),
+		}).Create(&release).Error; err != nil {
+			return nil, err
+		}
+
+		if err := store.DB.WithContext(ctx).Where(&model.Release{ProjectID: projectID, Version: version}).Take(&release).Error; err != nil {
+			return nil, err
+		}
+		return &release, nil
+	})
+}
+
+// RegisterRelease creates or updates a release with the commit and deploy time reported by a deploy.
+func (store *Store) RegisterRelease(ctx context.Context, projectID int, version string, commitSha *string, deployedAt *time.Time) (*model.Release, error) {
+	if version == "" {
+		return nil, e.New("release version is required")
+	}
+	release := model.Release{
+		ProjectID:   projectID,
+		Version:     version,
+		CommitSha:   commitSha,
+		DeployedAt:  deployedAt,
+		FirstSeenAt: time.Now(),
+	}
+	if deployedAt != nil {
+		release.FirstSeenAt = *deployedAt
+	}
+	if err := store.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "project_id"}, {Name: "version"}},
+		DoUpdates: clause.AssignmentColumns([]string{"commit_sha", "deployed_at", "updated_at"}),
+	}).Create(&release).Error; err != nil {
+		return nil, err
+	}
+	if err := store.DB.WithContext(ctx).Where(&model.Release{ProjectID: projectID, Version: version}).Take(&release).Error; err != nil {
+		return nil, err
+	}
+
+	if err := store.Redis.Del(ctx, getReleaseKey(projectID, version)); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// GetReleaseWindow returns the time range that the release reported data in.
+func GetReleaseWindow(release *model.Release) (startDate time.Time, endDate time.Time) {
+	endDate = time.Now()
+	if release.LastSeenAt != nil {
+		// data reported since the last seen time was updated is included
+		if lastSeen := release.LastSeenAt.Add(RELEASE_SEEN_INTERVAL); lastSeen.Before(endDate) {
+			endDate = lastSeen
+		}
+	}
+	return release.FirstSeenAt, endDate
+}
+
+func (store *Store) GetRelease(ctx context.Context, projectID int, version string) (*model.Release, error) {
+	var release model.Release
+	if err := AssertRecordFound(store.DB.WithContext(ctx).Where(&model.Release{ProjectID: projectID, Version: version}).Take(&release)); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// ListReleases returns the project's releases, most recent first.
+func (store *Store) ListReleases(ctx context.Context, projectID int, count int) ([]*model.Release, error) {
+	if count <= 0 {
+		count = RELEASE_LIMIT
+	}
+	releases := []*model.Release{}
+	if err := store.DB.WithContext(ctx).
+		Where(&model.Release{ProjectID: projectID}).
+		Order("first_seen_at DESC, id DESC").
+		Limit(count).
+		Find(&releases).Error; err != nil {
+		return nil, err
+	}
+	return releases, nil
+}
+
+// GetAdjacentReleases returns the releases first seen right before and right after the release.
+// Either is nil when there is no such release.
+func (store *Store) GetAdjacentReleases(ctx context.Context, release *model.Release) (previous *model.Release, next *model.Release, err error) {
+	var releases []*model.Release
+	if err := store.DB.WithContext(ctx).
+		Where(&model.Release{ProjectID: release.ProjectID}).
+		Where("first_seen_at < ? OR (first_seen_at = ? AND id < ?)", release.FirstSeenAt, release.FirstSeenAt, release.ID).
+		Order("first_seen_at DESC, id DESC").
+		Limit(1).
+		Find(&releases).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(releases) > 0 {
+		previous = releases[0]
+	}
+
+	releases = nil
+	if err := store.DB.WithContext(ctx).
+		Where(&model.Release{ProjectID: release.ProjectID}).
+		Where("first_seen_at > ? OR (first_seen_at = ? AND id > ?)", release.FirstSeenAt, release.FirstSeenAt, release.ID).
+		Order("first_seen_at ASC, id ASC").
+		Limit(1).
+		Find(&releases).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(releases) > 0 {
+		next = releases[0]
+	}
+	return previous, next, nil
+}
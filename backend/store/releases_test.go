@@ -0,0 +1,66 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/ptr"
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleases(t *testing.T) {
+	ctx := context.Background()
+
+	defer teardown(t)
+
+	project := model.Project{}
+	store.DB.Create(&project)
+
+	firstSeenAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	first, err := store.UpsertRelease(ctx, project.ID, "1.0.0", firstSeenAt)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0.0", first.Version)
+
+	found, err := store.UpsertRelease(ctx, project.ID, "1.0.0", time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID, found.ID)
+	assert.True(t, found.FirstSeenAt.Equal(firstSeenAt))
+
+	deployedAt := time.Now().Truncate(time.Second)
+	second, err := store.RegisterRelease(ctx, project.ID, "1.1.0", ptr.String("abc123"), &deployedAt)
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", ptr.ToString(second.CommitSha))
+	assert.True(t, second.FirstSeenAt.Equal(deployedAt))
+
+	// registering a seen release keeps when it was first seen
+	registered, err := store.RegisterRelease(ctx, project.ID, "1.0.0", ptr.String("def456"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID, registered.ID)
+	assert.Equal(t, "def456", ptr.ToString(registered.CommitSha))
+	assert.True(t, registered.FirstSeenAt.Equal(firstSeenAt))
+
+	releases, err := store.ListReleases(ctx, project.ID, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.1.0", "1.0.0"}, []string{releases[0].Version, releases[1].Version})
+
+	previous, next, err := store.GetAdjacentReleases(ctx, first)
+	assert.NoError(t, err)
+	assert.Nil(t, previous)
+	assert.Equal(t, second.ID, next.ID)
+
+	previous, next, err = store.GetAdjacentReleases(ctx, second)
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID, previous.ID)
+	assert.Nil(t, next)
+
+	// the window of a release ends an interval after it was last seen
+	startDate, endDate := GetReleaseWindow(found)
+	assert.True(t, startDate.Equal(firstSeenAt))
+	assert.True(t, endDate.After(firstSeenAt))
+	assert.False(t, endDate.After(time.Now()))
+
+	_, err = store.GetRelease(ctx, project.ID, "2.0.0")
+	assert.Error(t, err)
+}
@@ -0,0 +1,303 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func (store *Store) CreateSCIMToken(ctx context.Context, workspaceID int, name string, token string) (*model.SCIMToken, error) {
+	scimToken := &model.SCIMToken{
+		WorkspaceID: workspaceID,
+		Name:        name,
+		TokenHash:   hashToken(token),
+	}
+	if err := store.DB.WithContext(ctx).Create(scimToken).Error; err != nil {
+		return nil, err
+	}
+	return scimToken, nil
+}
+
+func (store *Store) GetSCIMTokens(ctx context.Context, workspaceID int) ([]*model.SCIMToken, error) {
+	var tokens []*model.SCIMToken
+	return tokens, store.DB.WithContext(ctx).
+		Where(&model.SCIMToken{WorkspaceID: workspaceID}).
+		Order("id ASC").
+		Find(&tokens).Error
+}
+
+func (store *Store) DeleteSCIMToken(ctx context.Context, workspaceID int, id int) error {
+	return store.DB.WithContext(ctx).
+		Where(&model.SCIMToken{Model: model.Model{ID: id}, WorkspaceID: workspaceID}).
+		Delete(&model.SCIMToken{}).Error
+}
+
+// GetSCIMTokenWorkspaceID returns the workspace that the SCIM token belongs to.
+func (store *Store) GetSCIMTokenWorkspaceID(ctx context.Context, token string) (int, error) {
+	var scimToken model.SCIMToken
+	if err := store.DB.WithContext(ctx).
+		Where(&model.SCIMToken{TokenHash: hashToken(token)}).
+		Take(&scimToken).Error; err != nil {
+		return 0, err
+	}
+	if err := store.DB.WithContext(ctx).Model(&scimToken).UpdateColumn("last_used_at", time.Now()).Error; err != nil {
+		return 0, err
+	}
+	return scimToken.WorkspaceID, nil
+}
+
+func (store *Store) GetSCIMUsers(ctx context.Context, workspaceID int) ([]*model.SCIMUser, error) {
+	var users []*model.SCIMUser
+	return users, store.DB.WithContext(ctx).
+		Preload("Groups").
+		Where(&model.SCIMUser{WorkspaceID: workspaceID}).
+		Order("id ASC").
+		Find(&users).Error
+}
+
+func (store *Store) GetSCIMUser(ctx context.Context, workspaceID int, id int) (*model.SCIMUser, error) {
+	var user model.SCIMUser
+	return &user, store.DB.WithContext(ctx).
+		Preload("Groups").
+		Where(&model.SCIMUser{Model: model.Model{ID: id}, WorkspaceID: workspaceID}).
+		Take(&user).Error
+}
+
+// CreateSCIMUser provisions the user into their workspace.
+// The user is linked to an existing admin with the same email, otherwise a new admin is created
+// that is claimed when the user first signs in.
+func (store *Store) CreateSCIMUser(ctx context.Context, user *model.SCIMUser) error {
+	return store.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var admin model.Admin
+		if err := tx.Where("lower(email) = lower(?)", user.Email).Order("id ASC").Take(&admin).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			name := getSCIMUserName(user)
+			admin = model.Admin{
+				Name:                  &name,
+				FirstName:             &user.GivenName,
+				LastName:              &user.FamilyName,
+				Email:                 &user.Email,
+				EmailVerified:         &model.F,
+				AboutYouDetailsFilled: &model.F,
+			}
+			if err := tx.Create(&admin).Error; err != nil {
+				return errors.Wrap(err, "error creating scim admin")
+			}
+		}
+
+		user.AdminID = admin.ID
+		if err := tx.Omit("Groups").Create(user).Error; err != nil {
+			return errors.Wrap(err, "error creating scim user")
+		}
+		return syncSCIMWorkspaceAdmin(tx, user)
+	})
+}
+
+func (store *Store) UpdateSCIMUser(ctx context.Context, user *model.SCIMUser) error {
+	return store.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("*").Omit("created_at", "Groups").Updates(user).Error; err != nil {
+			return errors.Wrap(err, "error updating scim user")
+		}
+		return syncSCIMWorkspaceAdmin(tx, user)
+	})
+}
+
+// DeleteSCIMUser removes the user and their admin from the workspace.
+func (store *Store) DeleteSCIMUser(ctx context.Context, user *model.SCIMUser) error {
+	return store.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Association("Groups").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Delete(user).Error; err != nil {
+			return err
+		}
+		return tx.Where(&model.WorkspaceAdmin{AdminID: user.AdminID, WorkspaceID: user.WorkspaceID}).Delete(&model.WorkspaceAdmin{}).Error
+	})
+}
+
+func (store *Store) GetSCIMGroups(ctx context.Context, workspaceID int) ([]*model.SCIMGroup, error) {
+	var groups []*model.SCIMGroup
+	return groups, store.DB.WithContext(ctx).
+		Preload("Members").
+		Where(&model.SCIMGroup{WorkspaceID: workspaceID}).
+		Order("id ASC").
+		Find(&groups).Error
+}
+
+func (store *Store) GetSCIMGroup(ctx context.Context, workspaceID int, id int) (*model.SCIMGroup, error) {
+	var group model.SCIMGroup
+	return &group, store.DB.WithContext(ctx).
+		Preload("Members").
+		Where(&model.SCIMGroup{Model: model.Model{ID: id}, WorkspaceID: workspaceID}).
+		Take(&group).Error
+}
+
+func (store *Store) CreateSCIMGroup(ctx context.Context, group *model.SCIMGroup) error {
+	return store.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Members").Create(group).Error; err != nil {
+			return errors.Wrap(err, "error creating scim group")
+		}
+		if err := tx.Model(group).Association("Members").Replace(group.Members); err != nil {
+			return err
+		}
+		return syncSCIMGroupMembers(tx, group.Members)
+	})
+}
+
+// UpdateSCIMGroup updates the group and its members, then syncs the workspace role of previous and current members.
+func (store *Store) UpdateSCIMGroup(ctx context.Context, group *model.SCIMGroup) error {
+	return store.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var previousMembers []*model.SCIMUser
+		if err := tx.Model(group).Association("Members").Find(&previousMembers); err != nil {
+			return err
+		}
+		if err := tx.Select("*").Omit("created_at", "Members").Updates(group).Error; err != nil {
+			return errors.Wrap(err, "error updating scim group")
+		}
+		if err := tx.Model(group).Association("Members").Replace(group.Members); err != nil {
+			return err
+		}
+		return syncSCIMGroupMembers(tx, append(previousMembers, group.Members...))
+	})
+}
+
+func (store *Store) DeleteSCIMGroup(ctx context.Context, group *model.SCIMGroup) error {
+	return store.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var members []*model.SCIMUser
+		if err := tx.Model(group).Association("Members").Find(&members); err != nil {
+			return err
+		}
+		if err := tx.Model(group).Association("Members").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Delete(group).Error; err != nil {
+			return err
+		}
+		return syncSCIMGroupMembers(tx, members)
+	})
+}
+
+// UpdateSCIMGroupRole changes the workspace role and project access that the group grants to its members.
+func (store *Store) UpdateSCIMGroupRole(ctx context.Context, group *model.SCIMGroup, role string, projectIDs []int) error {
+	if role != model.AdminRole.ADMIN && role != model.AdminRole.MEMBER {
+		return errors.Errorf("invalid role %s", role)
+	}
+	group.Role = role
+	group.ProjectIds = nil
+	if role == model.AdminRole.MEMBER && len(projectIDs) > 0 {
+		group.ProjectIds = lo.Map(projectIDs, func(id int, _ int) int32 {
+			return int32(id)
+		})
+	}
+	return store.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(group).Select("Role", "ProjectIds").Updates(group).Error; err != nil {
+			return err
+		}
+		return syncSCIMGroupMembers(tx, group.Members)
+	})
+}
+
+func getSCIMUserName(user *model.SCIMUser) string {
+	if user.DisplayName != "" {
+		return user.DisplayName
+	}
+	if user.GivenName != "" || user.FamilyName != "" {
+		return lo.Ternary(user.FamilyName == "", user.GivenName, user.GivenName+" "+user.FamilyName)
+	}
+	return user.UserName
+}
+
+// getSCIMGroupsAccess returns the workspace role and project restriction granted by the groups.
+func getSCIMGroupsAccess(groups []*model.SCIMGroup) (string, pq.Int32Array) {
+	if lo.SomeBy(groups, func(group *model.SCIMGroup) bool {
+		return group.Role == model.AdminRole.ADMIN
+	}) {
+		return model.AdminRole.ADMIN, nil
+	}
+	if lo.SomeBy(groups, func(group *model.SCIMGroup) bool {
+		return len(group.ProjectIds) == 0
+	}) {
+		return model.AdminRole.MEMBER, nil
+	}
+	var projectIDs pq.Int32Array = lo.Uniq(lo.FlatMap(groups, func(group *model.SCIMGroup, _ int) []int32 {
+		return group.ProjectIds
+	}))
+	return model.AdminRole.MEMBER, projectIDs
+}
+
+func syncSCIMGroupMembers(tx *gorm.DB, members []*model.SCIMUser) error {
+	for _, member := range lo.UniqBy(members, func(member *model.SCIMUser) int { return member.ID }) {
+		var user model.SCIMUser
+		if err := tx.Where(&model.SCIMUser{Model: model.Model{ID: member.ID}}).Take(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return err
+		}
+		if err := syncSCIMWorkspaceAdmin(tx, &user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncSCIMWorkspaceAdmin adds an active user to their workspace with the access granted by their groups
+// and removes an inactive user. Users without groups keep the role they already have.
+func syncSCIMWorkspaceAdmin(tx *gorm.DB, user *model.SCIMUser) error {
+	if !user.Active {
+		return tx.Where(&model.WorkspaceAdmin{AdminID: user.AdminID, WorkspaceID: user.WorkspaceID}).Delete(&model.WorkspaceAdmin{}).Error
+	}
+
+	var groups []*model.SCIMGroup
+	if err := tx.Model(&model.SCIMGroup{}).
+		Joins("INNER JOIN scim_group_members ON scim_group_members.scim_group_id = scim_groups.id").
+		Where("scim_group_members.scim_user_id = ?", user.ID).
+		Find(&groups).Error; err != nil {
+		return err
+	}
+
+	workspaceAdmin := model.WorkspaceAdmin{
+		AdminID:     user.AdminID,
+		WorkspaceID: user.WorkspaceID,
+		Role:        lo.ToPtr(model.AdminRole.MEMBER),
+	}
+	onConflict := clause.OnConflict{OnConstraint: "workspace_admins_pkey", DoNothing: true}
+	if len(groups) > 0 {
+		role, projectIDs := getSCIMGroupsAccess(groups)
+		workspaceAdmin.Role = &role
+		workspaceAdmin.ProjectIds = projectIDs
+		onConflict = clause.OnConflict{OnConstraint: "workspace_admins_pkey", DoUpdates: clause.AssignmentColumns([]string{"role", "project_ids"})}
+	}
+	return tx.Clauses(onConflict).Create(&workspaceAdmin).Error
+}
+
+// ClaimProvisionedAdmin links an admin provisioned by SCIM to the uid of the user signing in with the same email.
+// Returns false when there is no unclaimed admin for the email.
+func (store *Store) ClaimProvisionedAdmin(ctx context.Context, uid string, email string) (bool, error) {
+	return claimProvisionedAdmin(store.DB.WithContext(ctx), uid, email)
+}
+
+func claimProvisionedAdmin(tx *gorm.DB, uid string, email string) (bool, error) {
+	if email == "" {
+		return false, nil
+	}
+	result := tx.Model(&model.Admin{}).
+		Where("id = (SELECT id FROM admins WHERE uid IS NULL AND lower(email) = lower(?) ORDER BY id ASC LIMIT 1)", email).
+		Update("uid", uid)
+	return result.RowsAffected > 0, result.Error
+}
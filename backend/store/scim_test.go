@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSCIMTokens(t *testing.T) {
+	ctx := context.Background()
+
+	defer teardown(t)
+
+	token, err := store.CreateSCIMToken(ctx, 1, "Okta", "secret")
+	require.NoError(t, err)
+	assert.NotEqual(t, "secret", token.TokenHash)
+
+	workspaceID, err := store.GetSCIMTokenWorkspaceID(ctx, "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, workspaceID)
+
+	_, err = store.GetSCIMTokenWorkspaceID(ctx, "other")
+	assert.Error(t, err)
+
+	tokens, err := store.GetSCIMTokens(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, tokens, 1)
+	assert.NotNil(t, tokens[0].LastUsedAt)
+
+	assert.NoError(t, store.DeleteSCIMToken(ctx, 1, token.ID))
+	_, err = store.GetSCIMTokenWorkspaceID(ctx, "secret")
+	assert.Error(t, err)
+}
+
+func TestSCIMProvisioning(t *testing.T) {
+	ctx := context.Background()
+
+	defer teardown(t)
+
+	workspace := model.Workspace{}
+	store.DB.Create(&workspace)
+
+	user := model.SCIMUser{WorkspaceID: workspace.ID, UserName: "jay@example.com", Email: "jay@example.com", GivenName: "Jay", Active: true}
+	require.NoError(t, store.CreateSCIMUser(ctx, &user))
+
+	var admin model.Admin
+	require.NoError(t, store.DB.Where(&model.Admin{Model: model.Model{ID: user.AdminID}}).Take(&admin).Error)
+	assert.Nil(t, admin.UID)
+	assert.Equal(t, "Jay", *admin.Name)
+
+	var workspaceAdmin model.WorkspaceAdmin
+	require.NoError(t, store.DB.Where(&model.WorkspaceAdmin{AdminID: admin.ID, WorkspaceID: workspace.ID}).Take(&workspaceAdmin).Error)
+	assert.Equal(t, model.AdminRole.MEMBER, *workspaceAdmin.Role)
+
+	// groups grant their role and project access to members
+	group := model.SCIMGroup{WorkspaceID: workspace.ID, DisplayName: "Engineering", Role: model.AdminRole.MEMBER, ProjectIds: pq.Int32Array{1, 2}, Members: []*model.SCIMUser{&user}}
+	require.NoError(t, store.CreateSCIMGroup(ctx, &group))
+	require.NoError(t, store.DB.Where(&model.WorkspaceAdmin{AdminID: admin.ID, WorkspaceID: workspace.ID}).Take(&workspaceAdmin).Error)
+	assert.Equal(t, pq.Int32Array{1, 2}, workspaceAdmin.ProjectIds)
+
+	require.NoError(t, store.UpdateSCIMGroupRole(ctx, &group, model.AdminRole.ADMIN, nil))
+	require.NoError(t, store.DB.Where(&model.WorkspaceAdmin{AdminID: admin.ID, WorkspaceID: workspace.ID}).Take(&workspaceAdmin).Error)
+	assert.Equal(t, model.AdminRole.ADMIN, *workspaceAdmin.Role)
+	assert.Empty(t, workspaceAdmin.ProjectIds)
+
+	found, err := store.GetSCIMUser(ctx, workspace.ID, user.ID)
+	require.NoError(t, err)
+	assert.Len(t, found.Groups, 1)
+
+	// deactivating the user removes them from the workspace
+	found.Active = false
+	require.NoError(t, store.UpdateSCIMUser(ctx, found))
+	assert.Error(t, store.DB.Where(&model.WorkspaceAdmin{AdminID: admin.ID, WorkspaceID: workspace.ID}).Take(&workspaceAdmin).Error)
+
+	// the provisioned admin is claimed when the user signs in
+	claimed, err := store.ClaimProvisionedAdmin(ctx, "uid", "JAY@example.com")
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	require.NoError(t, store.DB.Where(&model.Admin{Model: model.Model{ID: user.AdminID}}).Take(&admin).Error)
+	assert.Equal(t, "uid", *admin.UID)
+
+	claimed, err = store.ClaimProvisionedAdmin(ctx, "other", "jay@example.com")
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+
+	require.NoError(t, store.DeleteSCIMGroup(ctx, &group))
+	require.NoError(t, store.DeleteSCIMUser(ctx, found))
+	_, err = store.GetSCIMUser(ctx, workspace.ID, user.ID)
+	assert.Error(t, err)
+}
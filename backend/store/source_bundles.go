@@ -0,0 +1,232 @@
+package store
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/openlyinc/pointy"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/highlight-run/highlight/backend/model"
+	privateModel "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/highlight-run/highlight/backend/util"
+)
+
+// SOURCE_BUNDLE_DIRECTORY is the directory of a service version's sourcemap files
+// that zips of backend source files are uploaded to, named by service.
+const SOURCE_BUNDLE_DIRECTORY = "source-bundles"
+
+// source bundles and the files in them larger than this are not read
+const MAX_SOURCE_BUNDLE_FILE_SIZE = 10 * 1024 * 1024
+
+// parsed bundles are cached as they are used for every error of the service version
+var sourceBundleCache = expirable.NewLRU[string, *sourceBundle](16, nil, 10*time.Minute)
+
+type sourceBundle struct {
+	// files by their path relative to the root of the zip
+	files map[string]*zip.File
+}
+
+func SourceBundleFileName(serviceName string) string {
+	return fmt.Sprintf("%s/%s.zip", SOURCE_BUNDLE_DIRECTORY, serviceName)
+}
+
+func parseSourceBundle(bundleBytes []byte) (*sourceBundle, error) {
+	if len(bundleBytes) > MAX_SOURCE_BUNDLE_FILE_SIZE {
+		return nil, errors.New("source bundle is too large")
+	}
+	reader, err := zip.NewReader(bytes.NewReader(bundleBytes), int64(len(bundleBytes)))
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading source bundle zip")
+	}
+
+	bundle := &sourceBundle{files: map[string]*zip.File{}}
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		bundle.files[strings.TrimPrefix(path.Clean("/"+file.Name), "/")] = file
+	}
+	if len(bundle.files) == 0 {
+		return nil, errors.New("source bundle has no files")
+	}
+	return bundle, nil
+}
+
+// find returns the bundle file of a stack frame's file name. Files are matched by their full path,
+// otherwise the longest bundle path that the file name ends with is used, so that
+// the directory the service was built or deployed in does not need to be configured.
+func (b *sourceBundle) find(fileName string) *zip.File {
+	fileName = strings.TrimPrefix(path.Clean("/"+fileName), "/")
+	if file, ok := b.files[fileName]; ok {
+		return file
+	}
+
+	var match string
+	for name := range b.files {
+		if strings.HasSuffix(fileName, "/"+name) && len(name) > len(match) {
+			match = name
+		}
+	}
+	if match == "" {
+		return nil
+	}
+	return b.files[match]
+}
+
+func (b *sourceBundle) readLines(file *zip.File) ([]string, error) {
+	if file.UncompressedSize64 > MAX_SOURCE_BUNDLE_FILE_SIZE {
+		return nil, errors.New("source bundle file is too large")
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return nil, errors.Wrap(err, "error opening source bundle file")
+	}
+	defer reader.Close()
+
+	// bound the read regardless of the size declared in the zip header
+	content, err := io.ReadAll(io.LimitReader(reader, MAX_SOURCE_BUNDLE_FILE_SIZE+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading source bundle file")
+	}
+	if len(content) > MAX_SOURCE_BUNDLE_FILE_SIZE {
+		return nil, errors.New("source bundle file is too large")
+	}
+	return strings.Split(string(content), "\n"), nil
+}
+
+// getSourceBundle returns the parsed source bundle uploaded for the service version,
+// falling back to the unversioned bundle. It returns nil when no bundle was uploaded.
+func (store *Store) getSourceBundle(ctx context.Context, projectID int, serviceName string, serviceVersion string) *sourceBundle {
+	versions := []*string{nil}
+	if serviceVersion != "" {
+		versions = []*string{&serviceVersion, nil}
+	}
+
+	for _, version := range versions {
+		cacheKey := fmt.Sprintf("%d/%s/%s", projectID, pointy.StringValue(version, ""), serviceName)
+		if bundle, ok := sourceBundleCache.Get(cacheKey); ok {
+			if bundle != nil {
+				return bundle
+			}
+			continue
+		}
+
+		bundleBytes, err := store.StorageClient.ReadSourceMapFileCached(ctx, projectID, version, SourceBundleFileName(serviceName))
+		var bundle *sourceBundle
+		if err == nil && bundleBytes != nil {
+			bundle, err = parseSourceBundle(bundleBytes)
+			if err != nil {
+				log.WithContext(ctx).WithError(err).WithField("project_id", projectID).WithField("service_name", serviceName).Warn("failed to parse source bundle")
+			}
+		}
+		sourceBundleCache.Add(cacheKey, bundle)
+		if bundle != nil {
+			return bundle
+		}
+	}
+	return nil
+}
+
+// enhanceTraceWithSourceBundle returns the trace with the source lines around its line from the bundle,
+// or nil when the file of the trace is not in the bundle.
+func (store *Store) enhanceTraceWithSourceBundle(ctx context.Context, trace *privateModel.ErrorTrace, bundle *sourceBundle, serviceVersion string, fileName string) (*privateModel.ErrorTrace, error) {
+	file := bundle.find(fileName)
+	if file == nil {
+		return nil, nil
+	}
+
+	lines, err := bundle.readLines(file)
+	if err != nil {
+		return nil, err
+	}
+
+	lineContent, beforeContent, afterContent, err := store.ExpandedStackTrace(ctx, lines, *trace.LineNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	enhancementSource := privateModel.EnhancementSourceSourceBundle
+	enhancedTrace := *trace
+	enhancedTrace.EnhancementSource = &enhancementSource
+	enhancedTrace.EnhancementVersion = pointy.String(serviceVersion)
+	enhancedTrace.LineContent = lineContent
+	enhancedTrace.LinesBefore = beforeContent
+	enhancedTrace.LinesAfter = afterContent
+	return &enhancedTrace, nil
+}
+
+// SourceBundleEnhancedStackTrace adds source context to the frames of a backend error from the source bundle
+// uploaded for its service. It returns nil when the service has no source bundle.
+func (store *Store) SourceBundleEnhancedStackTrace(ctx context.Context, stackTrace []*privateModel.ErrorTrace, project *model.Project, errorObj *model.ErrorObject) ([]*privateModel.ErrorTrace, error) {
+	span, ctx := util.StartSpanFromContext(ctx, "SourceBundleEnhancedStackTrace")
+	defer span.Finish()
+
+	if errorObj.ServiceName == "" {
+		return nil, nil
+	}
+
+	bundle := store.getSourceBundle(ctx, project.ID, errorObj.ServiceName, errorObj.ServiceVersion)
+	if bundle == nil {
+		return nil, nil
+	}
+
+	// the service is only used for its path prefixes, which are optional with a source bundle
+	var buildPrefix, sourcePrefix *string
+	if service, err := store.FindService(ctx, project.ID, errorObj.ServiceName); err == nil && service != nil {
+		buildPrefix, sourcePrefix = service.BuildPrefix, service.GithubPrefix
+	}
+
+	cfg, err := store.GetSystemConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ignoredFiles []*regexp.Regexp
+	for _, fileExpr := range cfg.IgnoredFiles {
+		ignoredFile, err := regexp.Compile(fileExpr)
+		if err != nil {
+			log.WithContext(ctx).WithError(err).WithField("expression", fileExpr).Warn("invalid ignored file expression")
+			continue
+		}
+		ignoredFiles = append(ignoredFiles, ignoredFile)
+	}
+
+	newMappedStackTrace := []*privateModel.ErrorTrace{}
+	for _, trace := range stackTrace {
+		newMappedStackTrace = append(newMappedStackTrace, store.enhanceSourceBundleTrace(ctx, trace, bundle, errorObj.ServiceVersion, buildPrefix, sourcePrefix, ignoredFiles))
+	}
+	return newMappedStackTrace, nil
+}
+
+func (store *Store) enhanceSourceBundleTrace(ctx context.Context, trace *privateModel.ErrorTrace, bundle *sourceBundle, serviceVersion string, buildPrefix *string, sourcePrefix *string, ignoredFiles []*regexp.Regexp) *privateModel.ErrorTrace {
+	// frames mapped with a sourcemap already have their source lines
+	if trace.FileName == nil || trace.LineNumber == nil || trace.LineContent != nil {
+		return trace
+	}
+
+	fileName := store.GitHubFilePath(ctx, *trace.FileName, buildPrefix, sourcePrefix)
+	for _, ignoredFile := range ignoredFiles {
+		if ignoredFile.MatchString(fileName) {
+			return trace
+		}
+	}
+
+	enhancedTrace, err := store.enhanceTraceWithSourceBundle(ctx, trace, bundle, serviceVersion, fileName)
+	if err != nil {
+		log.WithContext(ctx).WithField("frame", trace).WithError(err).Warn("Error enhancing stacktrace frame from source bundle")
+	}
+	if enhancedTrace == nil {
+		return trace
+	}
+	return enhancedTrace
+}
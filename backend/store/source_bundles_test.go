@@ -0,0 +1,108 @@
+package store
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/aws/smithy-go/ptr"
+	"github.com/highlight-run/highlight/backend/model"
+	privateModel "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/highlight-run/highlight/backend/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createSourceBundle(t *testing.T, files map[string]string) []byte {
+	buf := new(bytes.Buffer)
+	writer := zip.NewWriter(buf)
+	for name, content := range files {
+		w, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestSourceBundleFind(t *testing.T) {
+	bundle, err := parseSourceBundle(createSourceBundle(t, map[string]string{
+		"./src/handlers/user.py": "",
+		"user.py":                "",
+		"cmd/main.go":            "",
+	}))
+	require.NoError(t, err)
+
+	var tests = []struct {
+		FileName string
+		Expected string
+	}{
+		{FileName: "src/handlers/user.py", Expected: "src/handlers/user.py"},
+		{FileName: "/src/handlers/user.py", Expected: "src/handlers/user.py"},
+		{FileName: "/app/src/handlers/user.py", Expected: "src/handlers/user.py"},
+		{FileName: "/app/src/models/user.py", Expected: "user.py"},
+		{FileName: "/go/src/github.com/example/api/cmd/main.go", Expected: "cmd/main.go"},
+		{FileName: "/app/cmd/server/main.go", Expected: ""},
+		{FileName: "/app/src/handlers/auser.py", Expected: ""},
+	}
+	for _, tt := range tests {
+		file := bundle.find(tt.FileName)
+		if tt.Expected == "" {
+			assert.Nil(t, file, tt.FileName)
+		} else {
+			assert.Same(t, bundle.files[tt.Expected], file, tt.FileName)
+		}
+	}
+}
+
+func TestParseSourceBundleTooLarge(t *testing.T) {
+	_, err := parseSourceBundle(make([]byte, MAX_SOURCE_BUNDLE_FILE_SIZE+1))
+	assert.EqualError(t, err, "source bundle is too large")
+}
+
+func TestSourceBundleEnhancedStackTrace(t *testing.T) {
+	defer teardown(t)
+	ctx := context.Background()
+
+	client, err := storage.NewFSClient(ctx, "http://localhost:8082/public", t.TempDir())
+	require.NoError(t, err)
+	bundleStore := NewStore(store.DB, store.Redis, store.IntegrationsClient, client, store.DataSyncQueue, store.ClickhouseClient)
+
+	_, err = client.PushSourceMapFile(ctx, 1, ptr.String("abc123"), SourceBundleFileName("api"), createSourceBundle(t, map[string]string{
+		"api/handlers.py": "import os\n\ndef handler():\n    raise ValueError('boom')\n\nhandler()",
+	}))
+	require.NoError(t, err)
+
+	stackTrace := []*privateModel.ErrorTrace{
+		{FileName: ptr.String("/srv/api/handlers.py"), LineNumber: ptr.Int(4), FunctionName: ptr.String("handler")},
+		{FileName: ptr.String("/usr/lib/python3.11/site-packages/api/handlers.py"), LineNumber: ptr.Int(4)},
+		{FileName: ptr.String("/srv/api/other.py"), LineNumber: ptr.Int(1)},
+		{FileName: ptr.String("/srv/api/handlers.py"), LineNumber: ptr.Int(6), LineContent: ptr.String("handler()")},
+	}
+	mappedStackTrace, err := bundleStore.SourceBundleEnhancedStackTrace(ctx, stackTrace, &model.Project{Model: model.Model{ID: 1}}, &model.ErrorObject{ServiceName: "api", ServiceVersion: "abc123"})
+	require.NoError(t, err)
+	require.Len(t, mappedStackTrace, 4)
+
+	enhancementSource := privateModel.EnhancementSourceSourceBundle
+	assert.Equal(t, &privateModel.ErrorTrace{
+		FileName:           ptr.String("/srv/api/handlers.py"),
+		LineNumber:         ptr.Int(4),
+		FunctionName:       ptr.String("handler"),
+		EnhancementSource:  &enhancementSource,
+		EnhancementVersion: ptr.String("abc123"),
+		LineContent:        ptr.String("    raise ValueError('boom')"),
+		LinesBefore:        ptr.String("import os\n\ndef handler():"),
+		LinesAfter:         ptr.String("\nhandler()"),
+	}, mappedStackTrace[0])
+	// ignored files, files missing from the bundle and frames with source lines are not changed
+	assert.Same(t, stackTrace[1], mappedStackTrace[1])
+	assert.Same(t, stackTrace[2], mappedStackTrace[2])
+	assert.Same(t, stackTrace[3], mappedStackTrace[3])
+
+	// services without a source bundle are not enhanced
+	mappedStackTrace, err = bundleStore.SourceBundleEnhancedStackTrace(ctx, stackTrace, &model.Project{Model: model.Model{ID: 1}}, &model.ErrorObject{ServiceName: "worker", ServiceVersion: "abc123"})
+	require.NoError(t, err)
+	assert.Nil(t, mappedStackTrace)
+}
@@ -2,7 +2,11 @@ package store
 
 import (
 	"context"
+
 	"github.com/highlight-run/highlight/backend/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func (store *Store) GetSSOClients(ctx context.Context) ([]*model.SSOClient, error) {
@@ -21,3 +25,58 @@ func (store *Store) GetSSOClient(ctx context.Context, domain string) (*model.SSO
 		Where(&model.SSOClient{Domain: domain}).
 		Take(&ssoClient).Error
 }
+
+func (store *Store) GetSAMLClients(ctx context.Context) ([]*model.SAMLClient, error) {
+	var samlClients []*model.SAMLClient
+	return samlClients, store.DB.
+		WithContext(ctx).
+		Model(&model.SAMLClient{}).
+		Find(&samlClients).Error
+}
+
+func (store *Store) GetSAMLClient(ctx context.Context, domain string) (*model.SAMLClient, error) {
+	var samlClient model.SAMLClient
+	return &samlClient, store.DB.
+		WithContext(ctx).
+		Model(&model.SAMLClient{}).
+		Where(&model.SAMLClient{Domain: domain}).
+		Take(&samlClient).Error
+}
+
+// ProvisionSAMLAdmin creates or updates the admin signing in with SAML and adds them to the client's workspace.
+// An existing workspace role is only changed when the identity provider asserts one.
+func (store *Store) ProvisionSAMLAdmin(ctx context.Context, samlClient *model.SAMLClient, uid string, name string, email string, role *string) (*model.Admin, error) {
+	var admin model.Admin
+	if err := store.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := claimProvisionedAdmin(tx, uid, email); err != nil {
+			return errors.Wrap(err, "error claiming provisioned admin")
+		}
+		if err := tx.Where(&model.Admin{UID: &uid}).
+			Assign(model.Admin{Name: &name, Email: &email, EmailVerified: &model.T}).
+			Attrs(model.Admin{AboutYouDetailsFilled: &model.F}).
+			FirstOrCreate(&admin).Error; err != nil {
+			return errors.Wrap(err, "error provisioning saml admin")
+		}
+
+		onConflict := clause.OnConflict{OnConstraint: "workspace_admins_pkey", DoNothing: true}
+		workspaceRole := samlClient.DefaultRole
+		if role != nil {
+			onConflict = clause.OnConflict{OnConstraint: "workspace_admins_pkey", DoUpdates: clause.AssignmentColumns([]string{"role"})}
+			workspaceRole = *role
+		}
+		if workspaceRole == "" {
+			workspaceRole = model.AdminRole.MEMBER
+		}
+		if err := tx.Clauses(onConflict).Create(&model.WorkspaceAdmin{
+			AdminID:     admin.ID,
+			WorkspaceID: samlClient.WorkspaceID,
+			Role:        &workspaceRole,
+		}).Error; err != nil {
+			return errors.Wrap(err, "error adding saml admin to workspace")
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return &admin, nil
+}
@@ -39,3 +39,69 @@ func TestGetSSOClients(t *testing.T) {
 	assert.Equal(t, foundClient.ClientSecret, client.ClientSecret)
 	assert.Equal(t, foundClient.ProviderURL, client.ProviderURL)
 }
+
+func TestGetSAMLClients(t *testing.T) {
+	ctx := context.Background()
+
+	defer teardown(t)
+
+	res, err := store.GetSAMLClients(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, res)
+
+	client := model.SAMLClient{
+		Domain:         "example.com",
+		IdPMetadataURL: "https://idp.example.com/metadata",
+		WorkspaceID:    1,
+		EmailAttribute: "email",
+	}
+	store.DB.Create(&client)
+
+	foundClients, err := store.GetSAMLClients(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, client.Domain, foundClients[0].Domain)
+	assert.Equal(t, client.IdPMetadataURL, foundClients[0].IdPMetadataURL)
+	assert.Equal(t, model.AdminRole.MEMBER, foundClients[0].DefaultRole)
+
+	foundClient, err := store.GetSAMLClient(ctx, "example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, client.Domain, foundClient.Domain)
+	assert.Equal(t, client.WorkspaceID, foundClient.WorkspaceID)
+	assert.Equal(t, client.EmailAttribute, foundClient.EmailAttribute)
+
+	_, err = store.GetSAMLClient(ctx, "other.com")
+	assert.Error(t, err)
+}
+
+func TestProvisionSAMLAdmin(t *testing.T) {
+	ctx := context.Background()
+
+	defer teardown(t)
+
+	workspace := model.Workspace{}
+	store.DB.Create(&workspace)
+	client := model.SAMLClient{Domain: "example.com", WorkspaceID: workspace.ID, DefaultRole: model.AdminRole.MEMBER}
+
+	admin, err := store.ProvisionSAMLAdmin(ctx, &client, "saml:example.com:jay", "Jay", "jay@example.com", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "jay@example.com", *admin.Email)
+
+	var workspaceAdmin model.WorkspaceAdmin
+	assert.NoError(t, store.DB.Where(&model.WorkspaceAdmin{AdminID: admin.ID, WorkspaceID: workspace.ID}).Take(&workspaceAdmin).Error)
+	assert.Equal(t, model.AdminRole.MEMBER, *workspaceAdmin.Role)
+
+	// signing in again updates the admin and the asserted role
+	updated, err := store.ProvisionSAMLAdmin(ctx, &client, "saml:example.com:jay", "Jay Doe", "jay@example.com", &model.AdminRole.ADMIN)
+	assert.NoError(t, err)
+	assert.Equal(t, admin.ID, updated.ID)
+	assert.Equal(t, "Jay Doe", *updated.Name)
+
+	assert.NoError(t, store.DB.Where(&model.WorkspaceAdmin{AdminID: admin.ID, WorkspaceID: workspace.ID}).Take(&workspaceAdmin).Error)
+	assert.Equal(t, model.AdminRole.ADMIN, *workspaceAdmin.Role)
+
+	// without an asserted role the existing role is kept
+	_, err = store.ProvisionSAMLAdmin(ctx, &client, "saml:example.com:jay", "Jay Doe", "jay@example.com", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, store.DB.Where(&model.WorkspaceAdmin{AdminID: admin.ID, WorkspaceID: workspace.ID}).Take(&workspaceAdmin).Error)
+	assert.Equal(t, model.AdminRole.ADMIN, *workspaceAdmin.Role)
+}
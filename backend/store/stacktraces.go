@@ -239,6 +239,11 @@ func (store *Store) GitHubEnhancedStackTrace(ctx context.Context, stackTrace []*
 	failedAllEnhancements := true
 
 	for _, trace := range stackTrace {
+		// frames enhanced from a source bundle already have their source lines
+		if trace.EnhancementSource != nil && *trace.EnhancementSource == privateModel.EnhancementSourceSourceBundle {
+			newMappedStackTrace = append(newMappedStackTrace, trace)
+			continue
+		}
 		enhancedTrace, fileEnhancable, fileEnhanced := store.EnhanceTrace(ctx, trace, service, *validServiceVersion, cfg.IgnoredFiles, client)
 
 		newMappedStackTrace = append(newMappedStackTrace, enhancedTrace)
@@ -280,9 +285,28 @@ func (store *Store) EnhancedStackTrace(ctx context.Context, stackTrace string, w
 	}
 
 	var newMappedStackTraceString *string
-	mappedStackTrace, err := store.GitHubEnhancedStackTrace(ctx, structuredStackTrace, workspace, project, errorObj, validateService)
+	// frames found in an uploaded source bundle are preferred over GitHub, which enhances the remaining frames,
+	// unless the GitHub settings of a service are being validated
+	var bundleStackTrace []*privateModel.ErrorTrace
+	gitHubStackTrace := structuredStackTrace
+	if validateService == nil {
+		bundleStackTrace, err = store.SourceBundleEnhancedStackTrace(ctx, structuredStackTrace, project, errorObj)
+		if err != nil {
+			log.WithContext(ctx).WithError(err).Warn("Error enhancing stacktrace from source bundle")
+		} else if bundleStackTrace != nil {
+			gitHubStackTrace = bundleStackTrace
+		}
+	}
+
+	mappedStackTrace, err := store.GitHubEnhancedStackTrace(ctx, gitHubStackTrace, workspace, project, errorObj, validateService)
 	if err != nil {
-		return nil, structuredStackTrace, errors.Wrap(err, "Error enhancing stacktrace")
+		if bundleStackTrace == nil {
+			return nil, structuredStackTrace, errors.Wrap(err, "Error enhancing stacktrace")
+		}
+		log.WithContext(ctx).WithError(err).Warn("Error enhancing stacktrace from GitHub")
+	}
+	if mappedStackTrace == nil {
+		mappedStackTrace = bundleStackTrace
 	}
 	if mappedStackTrace == nil {
 		return nil, structuredStackTrace, nil
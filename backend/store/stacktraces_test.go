@@ -307,6 +307,13 @@ func (c *MockGithubClient) GetRepoContent(ctx context.Context, githubPath string
 	if path == "/error.js" {
 		return nil, nil, nil, errors.New("repo error")
 	}
+	if path == ".github/CODEOWNERS" && githubPath == "highlight/found" {
+		fileContent := github2.RepositoryContent{
+			// base64 for "* @highlight/core\n/src/checkout.ts @highlight/payments\n"
+			Content: ptr.String("KiBAaGlnaGxpZ2h0L2NvcmUKL3NyYy9jaGVja291dC50cyBAaGlnaGxpZ2h0L3BheW1lbnRzCg=="),
+		}
+		return &fileContent, nil, nil, nil
+	}
 	if path == "/file.js" {
 		fileContent := github2.RepositoryContent{
 			// base64 for console.log('hello world')
@@ -398,6 +405,27 @@ func (c *MockGithubClient) GetLatestCommitHash(ctx context.Context, githubPath s
 	return "", nil, nil
 }
 
+func (c *MockGithubClient) ListFileCommits(ctx context.Context, githubPath string, path string, version string, count int) ([]*github2.RepositoryCommit, *github2.Response, error) {
+	if path == "/src/checkout.ts" {
+		return []*github2.RepositoryCommit{
+			{SHA: ptr.String("1111111"), HTMLURL: ptr.String("https://github.com/highlight/found/commit/1111111"), Commit: &github2.Commit{Message: ptr.String("refactor checkout styles"), Author: &github2.CommitAuthor{Name: ptr.String("Jay")}}},
+			{SHA: ptr.String("2222222"), HTMLURL: ptr.String("https://github.com/highlight/found/commit/2222222"), Commit: &github2.Commit{Message: ptr.String("submit orders in batches"), Author: &github2.CommitAuthor{Name: ptr.String("Sam")}}, Author: &github2.User{Login: ptr.String("sam")}},
+		}, nil, nil
+	}
+	return nil, nil, nil
+}
+
+func (c *MockGithubClient) GetCommit(ctx context.Context, githubPath string, sha string) (*github2.RepositoryCommit, *github2.Response, error) {
+	patches := map[string]string{
+		"1111111": "@@ -1,2 +1,2 @@\n-const color = 'red'\n+const color = 'blue'\n import { submit } from './api'",
+		"2222222": "@@ -40,3 +40,3 @@\n function submitOrder() {\n-\tsubmit(order)\n+\tsubmit([order])\n }",
+	}
+	return &github2.RepositoryCommit{
+		SHA:   ptr.String(sha),
+		Files: []*github2.CommitFile{{Filename: ptr.String("src/checkout.ts"), Patch: ptr.String(patches[sha])}},
+	}, nil, nil
+}
+
 // other methods not used in this test but needed for interface
 func (c *MockGithubClient) CreateIssue(ctx context.Context, repo string, issueRequest *github2.IssueRequest) (*github2.Issue, error) {
 	return nil, nil
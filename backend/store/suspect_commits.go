@@ -0,0 +1,300 @@
+package store
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/smithy-go/ptr"
+	"github.com/highlight-run/highlight/backend/integrations/github"
+	kafka_queue "github.com/highlight-run/highlight/backend/kafka-queue"
+	"github.com/highlight-run/highlight/backend/model"
+	privateModel "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/highlight-run/highlight/backend/redis"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// Number of in-app frames, from the top of the stack, to look up commits for
+const SUSPECT_COMMIT_FRAMES = 3
+
+// Number of recent commits touching a frame's file to inspect
+const SUSPECT_COMMIT_HISTORY = 5
+
+// Commits changing lines this close to a frame's line are considered to touch it
+const SUSPECT_COMMIT_LINE_WINDOW = 3
+
+// gitHubServiceClient returns the service of the error along with a GitHub client for its repository.
+// The service is nil when it is not connected to a GitHub repository.
+func (store *Store) gitHubServiceClient(ctx context.Context, workspace *model.Workspace, projectID int, serviceName string) (*model.Service, github.ClientInterface, error) {
+	if workspace == nil || serviceName == "" {
+		return nil, nil, nil
+	}
+
+	service, err := store.FindService(ctx, projectID, serviceName)
+	if err != nil || service == nil || service.GithubRepoPath == nil || service.Status != "healthy" {
+		return nil, nil, nil
+	}
+
+	gitHubAccessToken, err := store.IntegrationsClient.GetWorkspaceAccessToken(ctx, workspace, privateModel.IntegrationTypeGitHub)
+	if err != nil || gitHubAccessToken == nil {
+		return nil, nil, err
+	}
+
+	client, err := github.NewClient(ctx, *gitHubAccessToken, store.Redis)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return service, client, nil
+}
+
+type repoFrame struct {
+	fileName   string
+	lineNumber int
+}
+
+// inAppFrames returns the repository paths of the top frames of the stack trace that are in-app code.
+func (store *Store) inAppFrames(ctx context.Context, stackTrace []*privateModel.ErrorTrace, service *model.Service, ignoredFiles []string) []repoFrame {
+	var frames []repoFrame
+	for _, frame := range stackTrace {
+		if frame == nil || frame.FileName == nil || frame.LineNumber == nil {
+			continue
+		}
+		if frame.InApp != nil && !*frame.InApp {
+			continue
+		}
+		fileName := store.GitHubFilePath(ctx, *frame.FileName, service.BuildPrefix, service.GithubPrefix)
+		if lo.SomeBy(ignoredFiles, func(fileExpr string) bool {
+			return regexp.MustCompile(fileExpr).MatchString(fileName)
+		}) {
+			continue
+		}
+		frames = append(frames, repoFrame{fileName: fileName, lineNumber: *frame.LineNumber})
+		if len(frames) == SUSPECT_COMMIT_FRAMES {
+			break
+		}
+	}
+	return frames
+}
+
+// GetCodeOwners returns the CODEOWNERS rules of the repository at the version.
+func (store *Store) GetCodeOwners(ctx context.Context, gitHubRepoPath string, version string, gitHubClient github.ClientInterface) (*github.CodeOwners, error) {
+	content, err := redis.CachedEval(ctx, store.Redis, fmt.Sprintf("github-codeowners-%s-%s", gitHubRepoPath, version), 5*time.Second, 24*time.Hour, func() (*string, error) {
+		for _, path := range github.CodeOwnersPaths {
+			fileContent, _, _, err := gitHubClient.GetRepoContent(ctx, gitHubRepoPath, path, version)
+			if err != nil || fileContent == nil || fileContent.Content == nil {
+				continue
+			}
+			decoded, err := base64.StdEncoding.DecodeString(*fileContent.Content)
+			if err != nil {
+				return nil, err
+			}
+			return ptr.String(string(decoded)), nil
+		}
+		// cache repositories without a CODEOWNERS file too
+		return ptr.String(""), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return github.ParseCodeOwners(*content), nil
+}
+
+// CodeOwner returns the first owner of the topmost in-app frame that has an owner.
+func (store *Store) CodeOwner(ctx context.Context, stackTrace []*privateModel.ErrorTrace, service *model.Service, version string, ignoredFiles []string, gitHubClient github.ClientInterface) (*string, error) {
+	codeOwners, err := store.GetCodeOwners(ctx, *service.GithubRepoPath, version, gitHubClient)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, frame := range store.inAppFrames(ctx, stackTrace, service, ignoredFiles) {
+		if owners := codeOwners.Owners(frame.fileName); len(owners) > 0 {
+			return &owners[0], nil
+		}
+	}
+	return nil, nil
+}
+
+// AssignErrorGroupCodeOwner sets the owner of the error group from the CODEOWNERS file of the
+// repository connected to the service of the error object. It runs in the worker as the lookup makes several GitHub requests.
+func (store *Store) AssignErrorGroupCodeOwner(ctx context.Context, errorGroupID int, errorObjectID int) error {
+	var errorGroup model.ErrorGroup
+	if err := store.DB.WithContext(ctx).Where(&model.ErrorGroup{Model: model.Model{ID: errorGroupID}}).Take(&errorGroup).Error; err != nil {
+		return err
+	}
+	if errorGroup.CodeOwner != nil {
+		return nil
+	}
+
+	var errorObj model.ErrorObject
+	if err := store.DB.WithContext(ctx).Where(&model.ErrorObject{Model: model.Model{ID: errorObjectID}}).Take(&errorObj).Error; err != nil {
+		return err
+	}
+
+	var stackTrace []*privateModel.ErrorTrace
+	if errorObj.MappedStackTrace == nil || json.Unmarshal([]byte(*errorObj.MappedStackTrace), &stackTrace) != nil || len(stackTrace) == 0 {
+		return nil
+	}
+
+	project, err := store.GetProject(ctx, errorGroup.ProjectID)
+	if err != nil {
+		return err
+	}
+	workspace, err := store.GetWorkspace(ctx, project.WorkspaceID)
+	if err != nil {
+		return err
+	}
+
+	service, client, err := store.gitHubServiceClient(ctx, workspace, errorGroup.ProjectID, errorObj.ServiceName)
+	if err != nil || service == nil {
+		return err
+	}
+
+	version, err := store.GitHubGitSHA(ctx, *service.GithubRepoPath, errorObj.ServiceVersion, client)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := store.GetSystemConfiguration(ctx)
+	if err != nil {
+		return err
+	}
+
+	codeOwner, err := store.CodeOwner(ctx, stackTrace, service, *version, cfg.IgnoredFiles, client)
+	if err != nil || codeOwner == nil {
+		return err
+	}
+
+	if err := store.DB.WithContext(ctx).Model(&errorGroup).Update("CodeOwner", codeOwner).Error; err != nil {
+		return err
+	}
+
+	return store.DataSyncQueue.Submit(ctx, strconv.Itoa(errorGroup.ID), &kafka_queue.Message{Type: kafka_queue.ErrorGroupDataSync, ErrorGroupDataSync: &kafka_queue.ErrorGroupDataSyncArgs{ErrorGroupID: errorGroup.ID}})
+}
+
+// SuspectCommits returns the recent commits of the version changing the files of the top in-app frames.
+// Commits changing lines around a frame's line are listed first.
+// Line numbers of older commits may have shifted, so touching a line is a best-effort signal.
+func (store *Store) SuspectCommits(ctx context.Context, stackTrace []*privateModel.ErrorTrace, service *model.Service, version string, ignoredFiles []string, gitHubClient github.ClientInterface) ([]*privateModel.SuspectCommit, error) {
+	rateLimit, _ := store.Redis.GetGithubRateLimitExceeded(ctx, *service.GithubRepoPath)
+	if rateLimit {
+		return nil, errors.New("Exceeded GitHub rate limit")
+	}
+
+	suspects := []*privateModel.SuspectCommit{}
+	seen := map[string]bool{}
+	for _, frame := range store.inAppFrames(ctx, stackTrace, service, ignoredFiles) {
+		commits, resp, err := gitHubClient.ListFileCommits(ctx, *service.GithubRepoPath, frame.fileName, version, SUSPECT_COMMIT_HISTORY)
+		if resp != nil && resp.Rate.Remaining <= 0 {
+			_ = store.Redis.SetGithubRateLimitExceeded(ctx, *service.GithubRepoPath, resp.Rate.Reset.Time)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, commit := range commits {
+			sha := commit.GetSHA()
+			if sha == "" || seen[sha] {
+				continue
+			}
+			seen[sha] = true
+
+			// the commit listing does not include the changed files
+			detailed, _, err := gitHubClient.GetCommit(ctx, *service.GithubRepoPath, sha)
+			if err != nil {
+				return nil, err
+			} else if detailed == nil {
+				detailed = commit
+			}
+
+			touchesLine := false
+			for _, file := range detailed.Files {
+				if file.GetFilename() != strings.TrimPrefix(frame.fileName, "/") {
+					continue
+				}
+				touchesLine = lo.SomeBy(github.PatchChangedLines(file.GetPatch()), func(line int) bool {
+					return line >= frame.lineNumber-SUSPECT_COMMIT_LINE_WINDOW && line <= frame.lineNumber+SUSPECT_COMMIT_LINE_WINDOW
+				})
+			}
+
+			suspect := &privateModel.SuspectCommit{
+				Sha:         sha,
+				Message:     commit.GetCommit().GetMessage(),
+				URL:         commit.GetHTMLURL(),
+				FileName:    frame.fileName,
+				LineNumber:  frame.lineNumber,
+				TouchesLine: touchesLine,
+			}
+			if author := commit.GetCommit().GetAuthor(); author != nil {
+				suspect.AuthorName = author.Name
+				suspect.AuthorEmail = author.Email
+				if author.Date != nil {
+					suspect.CommittedAt = &author.Date.Time
+				}
+			}
+			if author := commit.GetAuthor(); author != nil {
+				suspect.AuthorLogin = author.Login
+				suspect.AuthorAvatarURL = author.AvatarURL
+			}
+			suspects = append(suspects, suspect)
+		}
+	}
+
+	sort.SliceStable(suspects, func(i, j int) bool {
+		return suspects[i].TouchesLine && !suspects[j].TouchesLine
+	})
+	return suspects, nil
+}
+
+// GetSuspectCommits returns the suspect commits of the error group for the version of its latest error.
+func (store *Store) GetSuspectCommits(ctx context.Context, workspace *model.Workspace, errorGroup *model.ErrorGroup, stackTrace []*privateModel.ErrorTrace) ([]*privateModel.SuspectCommit, error) {
+	if len(stackTrace) == 0 {
+		return []*privateModel.SuspectCommit{}, nil
+	}
+
+	var errorObject model.ErrorObject
+	if err := store.DB.WithContext(ctx).
+		Where(&model.ErrorObject{ErrorGroupID: errorGroup.ID}).
+		Order("id DESC").
+		Limit(1).
+		Find(&errorObject).Error; err != nil {
+		return nil, err
+	}
+
+	service, client, err := store.gitHubServiceClient(ctx, workspace, errorGroup.ProjectID, errorObject.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+	if service == nil {
+		return []*privateModel.SuspectCommit{}, nil
+	}
+
+	version, err := store.GitHubGitSHA(ctx, *service.GithubRepoPath, errorObject.ServiceVersion, client)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := store.GetSystemConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	suspects, err := redis.CachedEval(ctx, store.Redis, fmt.Sprintf("suspect-commits-%d-%s", errorGroup.ID, *version), 5*time.Second, time.Hour, func() (*[]*privateModel.SuspectCommit, error) {
+		suspects, err := store.SuspectCommits(ctx, stackTrace, service, *version, cfg.IgnoredFiles, client)
+		if err != nil {
+			return nil, err
+		}
+		return &suspects, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return *suspects, nil
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/smithy-go/ptr"
+	"github.com/highlight-run/highlight/backend/model"
+	privateModel "github.com/highlight-run/highlight/backend/private-graph/graph/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func suspectCommitsStackTrace() []*privateModel.ErrorTrace {
+	return []*privateModel.ErrorTrace{
+		{FileName: ptr.String("node_modules/react-dom/index.js"), LineNumber: ptr.Int(10), InApp: ptr.Bool(false)},
+		{FileName: ptr.String("/build/src/checkout.ts"), LineNumber: ptr.Int(41)},
+		{FileName: ptr.String("/build/src/index.ts"), LineNumber: ptr.Int(3)},
+	}
+}
+
+func TestCodeOwner(t *testing.T) {
+	ctx := context.Background()
+	githubClientMock := MockGithubClient{}
+	service := &model.Service{GithubRepoPath: ptr.String("highlight/found"), BuildPrefix: ptr.String("/build")}
+
+	codeOwner, err := store.CodeOwner(ctx, suspectCommitsStackTrace(), service, "abc123", nil, &githubClientMock)
+	assert.NoError(t, err)
+	assert.Equal(t, "@highlight/payments", ptr.ToString(codeOwner))
+
+	codeOwner, err = store.CodeOwner(ctx, suspectCommitsStackTrace()[2:], service, "abc123", nil, &githubClientMock)
+	assert.NoError(t, err)
+	assert.Equal(t, "@highlight/core", ptr.ToString(codeOwner))
+
+	// repositories without a CODEOWNERS file have no owners
+	service = &model.Service{GithubRepoPath: ptr.String("highlight/missing"), BuildPrefix: ptr.String("/build")}
+	codeOwner, err = store.CodeOwner(ctx, suspectCommitsStackTrace(), service, "abc123", nil, &githubClientMock)
+	assert.NoError(t, err)
+	assert.Nil(t, codeOwner)
+}
+
+func TestSuspectCommits(t *testing.T) {
+	ctx := context.Background()
+	githubClientMock := MockGithubClient{}
+	service := &model.Service{GithubRepoPath: ptr.String("highlight/found"), BuildPrefix: ptr.String("/build")}
+
+	suspects, err := store.SuspectCommits(ctx, suspectCommitsStackTrace(), service, "abc123", nil, &githubClientMock)
+	assert.NoError(t, err)
+	assert.Len(t, suspects, 2)
+
+	// the commit changing the frame's line is the most likely suspect
+	assert.Equal(t, "2222222", suspects[0].Sha)
+	assert.True(t, suspects[0].TouchesLine)
+	assert.Equal(t, "sam", ptr.ToString(suspects[0].AuthorLogin))
+	assert.Equal(t, "/src/checkout.ts", suspects[0].FileName)
+	assert.Equal(t, 41, suspects[0].LineNumber)
+
+	assert.Equal(t, "1111111", suspects[1].Sha)
+	assert.False(t, suspects[1].TouchesLine)
+	assert.Equal(t, "Jay", ptr.ToString(suspects[1].AuthorName))
+
+	suspects, err = store.SuspectCommits(ctx, suspectCommitsStackTrace()[:1], service, "abc123", nil, &githubClientMock)
+	assert.NoError(t, err)
+	assert.Empty(t, suspects)
+}
@@ -426,7 +426,7 @@ func sendSlackAlert(ctx context.Context, db *gorm.DB, obj *model.AlertDeprecated
 		var actionBlocks []slack.BlockElement
 		caser := cases.Title(language.AmericanEnglish)
 		for _, action := range modelInputs.AllErrorState {
-			if input.Group.State == action {
+			if input.Group.State == action || action == modelInputs.ErrorStateRegressed || action == modelInputs.ErrorStateMerged {
 				continue
 			}
 
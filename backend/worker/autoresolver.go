@@ -70,9 +70,9 @@ func (autoResolver *AutoResolver) resolveStaleErrorsForProject(ctx context.Conte
 	err := db.
 		Select("DISTINCT(error_groups.id), error_groups.project_id").
 		Where(model.ErrorGroup{
-			State:     privateModel.ErrorStateOpen,
 			ProjectID: project.ID,
 		}).
+		Where("state IN ?", []privateModel.ErrorState{privateModel.ErrorStateOpen, privateModel.ErrorStateRegressed}).
 		Where("NOT EXISTS (?)", subQuery).
 		Find(&errorGroups).Error
 
@@ -0,0 +1,279 @@
+package worker
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	parse "github.com/highlight-run/highlight/backend/event-parse"
+	"github.com/highlight-run/highlight/backend/model"
+	"github.com/pkg/errors"
+)
+
+// Tags of the custom events added to the timeline indicator events for detected frustration signals
+const (
+	DeadClickTag      = "DeadClick"
+	ErrorClickTag     = "ErrorClick"
+	ThrashedCursorTag = "ThrashedCursor"
+	UTurnTag          = "UTurn"
+)
+
+// Cursor movements shorter than this along an axis are ignored as jitter when counting direction changes
+const thrashedCursorMinDistancePixels = 8
+
+// FrustrationSettings are the parameters for detecting frustration signals other than rage clicks.
+// A signal is not detected when its window or timeout is zero.
+type FrustrationSettings struct {
+	DeadClickTimeout               time.Duration
+	ErrorClickWindow               time.Duration
+	ThrashedCursorWindow           time.Duration
+	ThrashedCursorDirectionChanges int
+	UTurnWindow                    time.Duration
+}
+
+func GetFrustrationSettings(project *model.Project) FrustrationSettings {
+	return FrustrationSettings{
+		DeadClickTimeout:               time.Duration(project.DeadClickTimeoutMilliseconds) * time.Millisecond,
+		ErrorClickWindow:               time.Duration(project.ErrorClickWindowMilliseconds) * time.Millisecond,
+		ThrashedCursorWindow:           time.Duration(project.ThrashedCursorWindowMilliseconds) * time.Millisecond,
+		ThrashedCursorDirectionChanges: project.ThrashedCursorDirectionChanges,
+		UTurnWindow:                    time.Duration(project.UTurnWindowSeconds) * time.Second,
+	}
+}
+
+// sessionError is a console error, uncaught exception or failed network request that a click may have caused.
+type sessionError struct {
+	Timestamp   time.Time
+	Description string
+}
+
+// frustrationState holds the state of the frustration signal detection between events.
+type frustrationState struct {
+	// pendingClicks are the clicks not yet followed by a DOM mutation or navigation
+	pendingClicks []*parse.ReplayEvent
+	// clicks are all clicks of the session, matched to errors once all payloads were read
+	clicks []*parse.ReplayEvent
+	errors []sessionError
+
+	cursorAnchor           [2]float64
+	cursorDirection        [2]float64
+	cursorDirectionChanges []time.Time
+	// thrashedCursor is the signal of the current thrashed cursor set, extended by further direction changes
+	thrashedCursor        *parse.ReplayEvent
+	thrashedCursorChanges int
+
+	pageURL         string
+	previousPageURL string
+	pageVisitedAt   time.Time
+}
+
+// addFrustrationSignal records a custom event for the signal so that it is shown on the session timeline.
+func (a *EventProcessingAccumulator) addFrustrationSignal(tag string, timestamp time.Time, payload string) *parse.ReplayEvent {
+	event := &parse.ReplayEvent{
+		Timestamp:    timestamp,
+		TimestampRaw: float64(timestamp.UnixMilli()),
+		Type:         parse.Custom,
+		Data:         map[string]interface{}{"tag": tag, "payload": payload},
+	}
+	a.FrustrationSignals = append(a.FrustrationSignals, event)
+	return event
+}
+
+// HasFrustrationSignal returns whether a signal with the tag was detected in the session.
+func (a *EventProcessingAccumulator) HasFrustrationSignal(tag string) bool {
+	for _, event := range a.FrustrationSignals {
+		if event.Data["tag"] == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// isPageChange returns whether the event shows the page responding to the user, with a DOM mutation or navigation.
+func isPageChange(event *parse.ReplayEvent) bool {
+	switch event.Type {
+	case parse.FullSnapshot, parse.Meta:
+		return true
+	case parse.IncrementalSnapshot:
+		source, ok := event.Data["source"].(float64)
+		return ok && parse.EventSource(source) == parse.Mutation
+	case parse.Custom:
+		tag, _ := event.Data["tag"].(string)
+		return tag == "Navigate" || tag == "Reload"
+	}
+	return false
+}
+
+// detectDeadClicks reports the pending clicks that were not followed by a page change within the timeout.
+// The clicks still pending when the session ends are not reported, as the page may have responded after recording stopped.
+func (a *EventProcessingAccumulator) detectDeadClicks(event *parse.ReplayEvent) {
+	if len(a.frustration.pendingClicks) == 0 {
+		return
+	}
+	pageChange := isPageChange(event)
+	var pending []*parse.ReplayEvent
+	for _, click := range a.frustration.pendingClicks {
+		if event.Timestamp.Sub(click.Timestamp) > a.FrustrationSettings.DeadClickTimeout {
+			a.addFrustrationSignal(DeadClickTag, click.Timestamp, fmt.Sprintf("The page did not change within %s of the click", a.FrustrationSettings.DeadClickTimeout))
+		} else if !pageChange {
+			pending = append(pending, click)
+		}
+	}
+	a.frustration.pendingClicks = pending
+}
+
+func (a *EventProcessingAccumulator) addClick(event *parse.ReplayEvent) {
+	if a.FrustrationSettings.DeadClickTimeout > 0 {
+		a.frustration.pendingClicks = append(a.frustration.pendingClicks, event)
+	}
+	if a.FrustrationSettings.ErrorClickWindow > 0 {
+		a.frustration.clicks = append(a.frustration.clicks, event)
+	}
+}
+
+// detectThrashedCursor counts the direction changes of the cursor positions of a mouse move event,
+// reporting a thrashed cursor when enough of them happen within the window.
+func (a *EventProcessingAccumulator) detectThrashedCursor(event *parse.ReplayEvent) {
+	if a.FrustrationSettings.ThrashedCursorWindow <= 0 || a.FrustrationSettings.ThrashedCursorDirectionChanges <= 0 {
+		return
+	}
+	positions, _ := event.Data["positions"].([]interface{})
+	for _, p := range positions {
+		position, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		x, okX := position["x"].(float64)
+		y, okY := position["y"].(float64)
+		if !okX || !okY {
+			continue
+		}
+		timeOffset, _ := position["timeOffset"].(float64)
+		timestamp := event.Timestamp.Add(time.Duration(timeOffset) * time.Millisecond)
+
+		s := &a.frustration
+		changed := false
+		for axis, value := range [2]float64{x, y} {
+			distance := value - s.cursorAnchor[axis]
+			if math.Abs(distance) < thrashedCursorMinDistancePixels {
+				continue
+			}
+			direction := math.Copysign(1, distance)
+			if s.cursorDirection[axis] != 0 && s.cursorDirection[axis] != direction {
+				changed = true
+			}
+			s.cursorDirection[axis] = direction
+			s.cursorAnchor[axis] = value
+		}
+		if !changed {
+			continue
+		}
+
+		s.cursorDirectionChanges = append(s.cursorDirectionChanges, timestamp)
+		for len(s.cursorDirectionChanges) > 0 && timestamp.Sub(s.cursorDirectionChanges[0]) > a.FrustrationSettings.ThrashedCursorWindow {
+			s.cursorDirectionChanges = s.cursorDirectionChanges[1:]
+		}
+		if len(s.cursorDirectionChanges) < a.FrustrationSettings.ThrashedCursorDirectionChanges {
+			s.thrashedCursor = nil
+			continue
+		}
+		if s.thrashedCursor == nil {
+			s.thrashedCursor = a.addFrustrationSignal(ThrashedCursorTag, s.cursorDirectionChanges[0], "")
+			s.thrashedCursorChanges = len(s.cursorDirectionChanges)
+		} else {
+			s.thrashedCursorChanges += 1
+		}
+		s.thrashedCursor.Data["payload"] = fmt.Sprintf("The cursor changed direction %d times", s.thrashedCursorChanges)
+	}
+}
+
+// detectUTurn reports navigating back to the previous page shortly after leaving it.
+func (a *EventProcessingAccumulator) detectUTurn(url string, timestamp time.Time) {
+	s := &a.frustration
+	if url == "" || url == s.pageURL {
+		return
+	}
+	if a.FrustrationSettings.UTurnWindow > 0 && s.pageURL != "" && url == s.previousPageURL &&
+		timestamp.Sub(s.pageVisitedAt) <= a.FrustrationSettings.UTurnWindow {
+		a.addFrustrationSignal(UTurnTag, s.pageVisitedAt, s.pageURL)
+	}
+	s.previousPageURL = s.pageURL
+	s.pageURL = url
+	s.pageVisitedAt = timestamp
+}
+
+// navigationURL returns the url of the page loaded or navigated to by the event, if any.
+func navigationURL(event *parse.ReplayEvent) string {
+	switch event.Type {
+	case parse.Meta:
+		href, _ := event.Data["href"].(string)
+		return href
+	case parse.Custom:
+		if tag, _ := event.Data["tag"].(string); tag == "Navigate" || tag == "Reload" {
+			url, _ := event.Data["payload"].(string)
+			return url
+		}
+	}
+	return ""
+}
+
+// AddErrorObjects adds the errors of the session that may have been caused by a click.
+func (a *EventProcessingAccumulator) AddErrorObjects(errorObjects []*model.ErrorObject) {
+	for _, errorObject := range errorObjects {
+		a.frustration.errors = append(a.frustration.errors, sessionError{
+			Timestamp:   errorObject.Timestamp,
+			Description: errorObject.Event,
+		})
+	}
+}
+
+// AddNetworkErrors adds the failed requests of a network resources payload that may have been caused by a click.
+func (a *EventProcessingAccumulator) AddNetworkErrors(sessionStart time.Time, resourcesPayload string) error {
+	if a.FrustrationSettings.ErrorClickWindow <= 0 {
+		return nil
+	}
+	var resources struct {
+		Resources []model.NetworkResource `json:"resources"`
+	}
+	if err := json.Unmarshal([]byte(resourcesPayload), &resources); err != nil {
+		return errors.Wrap(err, "error unmarshalling network resources")
+	}
+	for _, resource := range resources.Resources {
+		status := int(resource.RequestResponsePairs.Response.Status)
+		if status < http.StatusBadRequest {
+			continue
+		}
+		method := resource.RequestResponsePairs.Request.Method
+		if method == "" {
+			method = http.MethodGet
+		}
+		a.frustration.errors = append(a.frustration.errors, sessionError{
+			Timestamp:   resource.End(sessionStart),
+			Description: fmt.Sprintf("%s %s returned %d", method, resource.Name, status),
+		})
+	}
+	return nil
+}
+
+// DetectErrorClicks reports the clicks followed by an error within the window.
+// It runs once all payloads of the session were read, as errors are not part of the replay events.
+func (a *EventProcessingAccumulator) DetectErrorClicks() {
+	if a.FrustrationSettings.ErrorClickWindow <= 0 {
+		return
+	}
+	errs := a.frustration.errors
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Timestamp.Before(errs[j].Timestamp)
+	})
+	for _, click := range a.frustration.clicks {
+		idx := sort.Search(len(errs), func(i int) bool {
+			return !errs[i].Timestamp.Before(click.Timestamp)
+		})
+		if idx < len(errs) && errs[idx].Timestamp.Sub(click.Timestamp) <= a.FrustrationSettings.ErrorClickWindow {
+			a.addFrustrationSignal(ErrorClickTag, click.Timestamp, errs[idx].Description)
+		}
+	}
+}
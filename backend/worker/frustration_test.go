@@ -0,0 +1,106 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/highlight-run/highlight/backend/model"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrustrationSignals(t *testing.T) {
+	log.SetOutput(io.Discard)
+	start := int64(1700000000000)
+	at := func(offset int64) int64 {
+		return start + offset
+	}
+
+	// alternates the cursor between two points
+	var positions []string
+	for i := 0; i < 10; i++ {
+		positions = append(positions, fmt.Sprintf(`{"x": %d, "y": 50, "id": 1, "timeOffset": %d}`, (i%2)*100, (i-9)*100))
+	}
+
+	events := []model.EventsObject{{Events: fmt.Sprintf(`{"events": [
+		{"_sid": 1, "type": 4, "timestamp": %d, "data": {"href": "https://example.com/"}},
+		{"_sid": 2, "type": 2, "timestamp": %d, "data": {}},
+		{"_sid": 3, "type": 3, "timestamp": %d, "data": {"source": 2, "type": 2, "x": 10, "y": 10}},
+		{"_sid": 4, "type": 3, "timestamp": %d, "data": {"source": 0, "adds": [], "removes": [], "texts": [], "attributes": []}},
+		{"_sid": 5, "type": 3, "timestamp": %d, "data": {"source": 2, "type": 2, "x": 200, "y": 200}}
+	]}`, at(0), at(0), at(2000), at(2500), at(3000))}, {Events: fmt.Sprintf(`{"events": [
+		{"_sid": 6, "type": 3, "timestamp": %d, "data": {"source": 1, "positions": [%s]}},
+		{"_sid": 7, "type": 5, "timestamp": %d, "data": {"tag": "Navigate", "payload": "https://example.com/settings"}},
+		{"_sid": 8, "type": 5, "timestamp": %d, "data": {"tag": "Navigate", "payload": "https://example.com/"}},
+		{"_sid": 9, "type": 3, "timestamp": %d, "data": {"source": 2, "type": 2, "x": 400, "y": 400}},
+		{"_sid": 10, "type": 3, "timestamp": %d, "data": {"source": 0, "adds": [], "removes": [], "texts": [], "attributes": []}},
+		{"_sid": 11, "type": 3, "timestamp": %d, "data": {"source": 2, "type": 2, "x": 600, "y": 600}}
+	]}`, at(5000), strings.Join(positions, ", "), at(6000), at(8000), at(9000), at(9100), at(12000))}}
+
+	a := MakeEventProcessingAccumulator("fakeSecureID", RageClickSettings{
+		Window: 5 * time.Second,
+		Radius: 8,
+		Count:  5,
+	}, FrustrationSettings{
+		DeadClickTimeout:               time.Second,
+		ErrorClickWindow:               time.Second,
+		ThrashedCursorWindow:           1500 * time.Millisecond,
+		ThrashedCursorDirectionChanges: 8,
+		UTurnWindow:                    7 * time.Second,
+	})
+	for _, chunk := range events {
+		a = processEventChunk(context.TODO(), a, chunk)
+		require.NoError(t, a.Error)
+	}
+
+	require.NoError(t, a.AddNetworkErrors(time.UnixMilli(start), fmt.Sprintf(`{"resources": [
+		{"name": "https://example.com/api", "startTimeAbs": %d, "responseEndAbs": %d, "requestResponsePairs": {"request": {"verb": "POST"}, "response": {"status": 500}}},
+		{"name": "https://example.com/ok", "startTimeAbs": %d, "responseEndAbs": %d, "requestResponsePairs": {"request": {"verb": "GET"}, "response": {"status": 200}}}
+	]}`, at(3100), at(3200), at(2100), at(2200))))
+	a.AddErrorObjects([]*model.ErrorObject{{Event: "TypeError: settings is undefined", Timestamp: time.UnixMilli(at(9500))}})
+	a.DetectErrorClicks()
+
+	type signal struct {
+		Tag       string
+		Timestamp int64
+		Payload   string
+	}
+	var signals []signal
+	for _, event := range a.FrustrationSignals {
+		signals = append(signals, signal{
+			Tag:       event.Data["tag"].(string),
+			Timestamp: event.Timestamp.UnixMilli() - start,
+			Payload:   event.Data["payload"].(string),
+		})
+	}
+	assert.Equal(t, []signal{
+		// the click at 2000 changed the page, the one at 3000 did not
+		{Tag: DeadClickTag, Timestamp: 3000, Payload: "The page did not change within 1s of the click"},
+		{Tag: ThrashedCursorTag, Timestamp: 4300, Payload: "The cursor changed direction 8 times"},
+		{Tag: UTurnTag, Timestamp: 6000, Payload: "https://example.com/settings"},
+		{Tag: ErrorClickTag, Timestamp: 3000, Payload: "POST https://example.com/api returned 500"},
+		{Tag: ErrorClickTag, Timestamp: 9000, Payload: "TypeError: settings is undefined"},
+	}, signals)
+
+	assert.True(t, a.HasFrustrationSignal(DeadClickTag))
+	assert.True(t, a.HasFrustrationSignal(UTurnTag))
+
+	// the signals are not detected when disabled
+	a = MakeEventProcessingAccumulator("fakeSecureID", RageClickSettings{
+		Window: 5 * time.Second,
+		Radius: 8,
+		Count:  5,
+	}, FrustrationSettings{})
+	for _, chunk := range events {
+		a = processEventChunk(context.TODO(), a, chunk)
+		require.NoError(t, a.Error)
+	}
+	a.AddErrorObjects([]*model.ErrorObject{{Event: "TypeError: settings is undefined", Timestamp: time.UnixMilli(at(9500))}})
+	a.DetectErrorClicks()
+	assert.Empty(t, a.FrustrationSignals)
+}
@@ -0,0 +1,233 @@
+package worker
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/highlight-run/highlight/backend/clickhouse"
+	parse "github.com/highlight-run/highlight/backend/event-parse"
+	journey_handlers "github.com/highlight-run/highlight/backend/lambda-functions/journeys/handlers"
+	"github.com/highlight-run/highlight/backend/model"
+)
+
+// Viewport widths are bucketed to the lower bound of these breakpoints so that clicks are compared across similar layouts
+var heatmapViewportBreakpoints = []int32{1920, 1536, 1280, 1024, 768, 640, 0}
+
+// Click offsets from the top of the page are bucketed to this many pixels
+const heatmapClickBucketPixels = 10
+
+// The highlight Click event of an rrweb click, which has the selector of the clicked element, is recorded within this window of it
+const heatmapClickSelectorWindow = 100 * time.Millisecond
+
+type heatmapClickKey struct {
+	URL           string
+	ViewportWidth int32
+	X             int32
+	Y             int32
+}
+
+type heatmapClick struct {
+	heatmapClickKey
+	Timestamp time.Time
+}
+
+type heatmapSelector struct {
+	Timestamp time.Time
+	Selector  string
+}
+
+// heatmapState holds the page view of the session being aggregated into click heatmaps and scroll-depth maps.
+type heatmapState struct {
+	pageURL        string
+	pageViewStart  time.Time
+	viewportWidth  float64
+	viewportHeight float64
+	// documentNodeID is the rrweb node id of the document, the target of the page scroll events
+	documentNodeID float64
+	scrollY        float64
+	maxScrollDepth float64
+
+	clicks       []heatmapClick
+	selectors    []heatmapSelector
+	scrollDepths []*clickhouse.SessionScrollDepth
+}
+
+func heatmapViewportBreakpoint(width float64) int32 {
+	for _, breakpoint := range heatmapViewportBreakpoints {
+		if int32(width) >= breakpoint {
+			return breakpoint
+		}
+	}
+	return 0
+}
+
+func normalizeHeatmapURL(url string) string {
+	normalized, err := journey_handlers.NormalizeURL(url)
+	if err != nil || normalized == "" {
+		return "/"
+	}
+	return normalized
+}
+
+// endPageView records the scroll depth of the current page view, if any.
+func (s *heatmapState) endPageView() {
+	if s.pageURL == "" {
+		return
+	}
+	s.scrollDepths = append(s.scrollDepths, &clickhouse.SessionScrollDepth{
+		URL:            s.pageURL,
+		PageViewStart:  s.pageViewStart,
+		ViewportWidth:  heatmapViewportBreakpoint(s.viewportWidth),
+		ViewportHeight: int32(s.viewportHeight),
+		MaxScrollDepth: int32(s.maxScrollDepth),
+	})
+}
+
+func (s *heatmapState) startPageView(url string, timestamp time.Time) {
+	s.endPageView()
+	s.pageURL = normalizeHeatmapURL(url)
+	s.pageViewStart = timestamp
+	s.maxScrollDepth = s.scrollY + s.viewportHeight
+}
+
+func (s *heatmapState) scrollTo(y float64) {
+	s.scrollY = math.Max(y, 0)
+	s.maxScrollDepth = math.Max(s.maxScrollDepth, s.scrollY+s.viewportHeight)
+}
+
+// aggregateHeatmap tracks the page views, viewport and scroll position of the session,
+// recording the position of clicks and the max scroll depth of every page view.
+func (a *EventProcessingAccumulator) aggregateHeatmap(event *parse.ReplayEvent) {
+	s := &a.heatmap
+	switch event.Type {
+	case parse.Meta:
+		href, _ := event.Data["href"].(string)
+		if width, ok := event.Data["width"].(float64); ok {
+			s.viewportWidth = width
+		}
+		if height, ok := event.Data["height"].(float64); ok {
+			s.viewportHeight = height
+		}
+		s.scrollY = 0
+		if href != "" {
+			s.startPageView(href, event.Timestamp)
+		}
+	case parse.FullSnapshot:
+		if node, ok := event.Data["node"].(map[string]interface{}); ok {
+			s.documentNodeID, _ = node["id"].(float64)
+		}
+		if offset, ok := event.Data["initialOffset"].(map[string]interface{}); ok {
+			top, _ := offset["top"].(float64)
+			s.scrollTo(top)
+		}
+	case parse.Custom:
+		tag, _ := event.Data["tag"].(string)
+		switch tag {
+		case "Navigate":
+			url, _ := event.Data["payload"].(string)
+			// single page app navigations do not emit a meta event
+			if url != "" && normalizeHeatmapURL(url) != s.pageURL {
+				s.startPageView(url, event.Timestamp)
+			}
+		case "Click":
+			payload, _ := event.Data["payload"].(map[string]interface{})
+			if selector, _ := payload["clickSelector"].(string); selector != "" {
+				s.selectors = append(s.selectors, heatmapSelector{Timestamp: event.Timestamp, Selector: selector})
+			}
+		}
+	case parse.IncrementalSnapshot:
+		source, ok := event.Data["source"].(float64)
+		if !ok {
+			return
+		}
+		switch parse.EventSource(source) {
+		case parse.ViewportResize:
+			width, okWidth := event.Data["width"].(float64)
+			height, okHeight := event.Data["height"].(float64)
+			if okWidth && okHeight {
+				s.viewportWidth, s.viewportHeight = width, height
+				s.scrollTo(s.scrollY)
+			}
+		case parse.Scroll:
+			if id, _ := event.Data["id"].(float64); id == s.documentNodeID {
+				y, _ := event.Data["y"].(float64)
+				s.scrollTo(y)
+			}
+		case parse.MouseInteraction:
+			interaction, _ := event.Data["type"].(float64)
+			if parse.MouseInteractions(interaction) != parse.Click {
+				return
+			}
+			x, okX := event.Data["x"].(float64)
+			y, okY := event.Data["y"].(float64)
+			if !okX || !okY || s.pageURL == "" || s.viewportWidth <= 0 {
+				return
+			}
+			s.clicks = append(s.clicks, heatmapClick{
+				heatmapClickKey: heatmapClickKey{
+					URL:           s.pageURL,
+					ViewportWidth: heatmapViewportBreakpoint(s.viewportWidth),
+					X:             int32(math.Min(math.Max(x/s.viewportWidth*100, 0), 100)),
+					Y:             int32((s.scrollY+y)/heatmapClickBucketPixels) * heatmapClickBucketPixels,
+				},
+				Timestamp: event.Timestamp,
+			})
+		}
+	}
+}
+
+// HeatmapRows returns the click counts and page view scroll depths of the session to write to ClickHouse.
+// Clicks are matched to the selector of the clicked element recorded by the highlight Click event.
+func (a *EventProcessingAccumulator) HeatmapRows(session *model.Session) ([]*clickhouse.SessionClick, []*clickhouse.SessionScrollDepth) {
+	s := &a.heatmap
+	s.endPageView()
+	s.pageURL = ""
+
+	selectors := s.selectors
+	sort.Slice(selectors, func(i, j int) bool {
+		return selectors[i].Timestamp.Before(selectors[j].Timestamp)
+	})
+
+	type clickKey struct {
+		heatmapClickKey
+		Selector string
+	}
+	counts := map[clickKey]uint64{}
+	var keys []clickKey
+	for _, click := range s.clicks {
+		key := clickKey{heatmapClickKey: click.heatmapClickKey}
+		idx := sort.Search(len(selectors), func(i int) bool {
+			return !selectors[i].Timestamp.Before(click.Timestamp.Add(-heatmapClickSelectorWindow))
+		})
+		if idx < len(selectors) && selectors[idx].Timestamp.Sub(click.Timestamp) <= heatmapClickSelectorWindow {
+			key.Selector = selectors[idx].Selector
+		}
+		if _, ok := counts[key]; !ok {
+			keys = append(keys, key)
+		}
+		counts[key] += 1
+	}
+
+	var clicks []*clickhouse.SessionClick
+	for _, key := range keys {
+		clicks = append(clicks, &clickhouse.SessionClick{
+			ProjectID:        int32(session.ProjectID),
+			SessionID:        int64(session.ID),
+			SessionCreatedAt: session.CreatedAt,
+			URL:              key.URL,
+			Selector:         key.Selector,
+			ViewportWidth:    key.ViewportWidth,
+			X:                key.X,
+			Y:                key.Y,
+			Count:            counts[key],
+		})
+	}
+
+	for _, scrollDepth := range s.scrollDepths {
+		scrollDepth.ProjectID = int32(session.ProjectID)
+		scrollDepth.SessionID = int64(session.ID)
+		scrollDepth.SessionCreatedAt = session.CreatedAt
+	}
+	return clicks, s.scrollDepths
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/highlight-run/highlight/backend/clickhouse"
+	"github.com/highlight-run/highlight/backend/model"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeatmapRows(t *testing.T) {
+	log.SetOutput(io.Discard)
+	start := int64(1700000000000)
+	at := func(offset int64) int64 {
+		return start + offset
+	}
+
+	events := []model.EventsObject{{Events: fmt.Sprintf(`{"events": [
+		{"_sid": 1, "type": 4, "timestamp": %d, "data": {"href": "https://example.com/", "width": 1300, "height": 800}},
+		{"_sid": 2, "type": 2, "timestamp": %d, "data": {"node": {"id": 1, "type": 0}, "initialOffset": {"left": 0, "top": 0}}},
+		{"_sid": 3, "type": 5, "timestamp": %d, "data": {"tag": "Click", "payload": {"clickSelector": "button.signup", "clickTarget": "Sign up"}}},
+		{"_sid": 4, "type": 3, "timestamp": %d, "data": {"source": 2, "type": 2, "x": 650, "y": 104}},
+		{"_sid": 5, "type": 3, "timestamp": %d, "data": {"source": 3, "id": 1, "x": 0, "y": 1500}},
+		{"_sid": 6, "type": 3, "timestamp": %d, "data": {"source": 3, "id": 42, "x": 0, "y": 9000}},
+		{"_sid": 7, "type": 3, "timestamp": %d, "data": {"source": 3, "id": 1, "x": 0, "y": 200}}
+	]}`, at(0), at(0), at(1000), at(1020), at(2000), at(2500), at(3000))}, {Events: fmt.Sprintf(`{"events": [
+		{"_sid": 8, "type": 3, "timestamp": %d, "data": {"source": 2, "type": 2, "x": 650, "y": 100}},
+		{"_sid": 9, "type": 3, "timestamp": %d, "data": {"source": 2, "type": 2, "x": 650, "y": 100}},
+		{"_sid": 10, "type": 5, "timestamp": %d, "data": {"tag": "Navigate", "payload": "https://example.com/users/123"}},
+		{"_sid": 11, "type": 3, "timestamp": %d, "data": {"source": 4, "width": 700, "height": 600}},
+		{"_sid": 12, "type": 3, "timestamp": %d, "data": {"source": 2, "type": 2, "x": 70, "y": 50}}
+	]}`, at(4000), at(4500), at(5000), at(5500), at(6000))}}
+
+	a := MakeEventProcessingAccumulator("fakeSecureID", RageClickSettings{
+		Window: 5 * time.Second,
+		Radius: 8,
+		Count:  5,
+	}, FrustrationSettings{})
+	for _, chunk := range events {
+		a = processEventChunk(context.TODO(), a, chunk)
+		require.NoError(t, a.Error)
+	}
+
+	createdAt := time.UnixMilli(start)
+	clicks, scrollDepths := a.HeatmapRows(&model.Session{Model: model.Model{ID: 2, CreatedAt: createdAt}, ProjectID: 1})
+	click := func(url string, selector string, width, x, y int32, count uint64) *clickhouse.SessionClick {
+		return &clickhouse.SessionClick{ProjectID: 1, SessionID: 2, SessionCreatedAt: createdAt, URL: url, Selector: selector, ViewportWidth: width, X: x, Y: y, Count: count}
+	}
+	assert.Equal(t, []*clickhouse.SessionClick{
+		click("/", "button.signup", 1280, 50, 100, 1),
+		// the scroll offset is added to the position of clicks on the page
+		click("/", "", 1280, 50, 300, 2),
+		click("/users/{id-1}", "", 640, 10, 250, 1),
+	}, clicks)
+	assert.Equal(t, []*clickhouse.SessionScrollDepth{
+		{ProjectID: 1, SessionID: 2, SessionCreatedAt: createdAt, URL: "/", PageViewStart: time.UnixMilli(at(0)).UTC(), ViewportWidth: 1280, ViewportHeight: 800, MaxScrollDepth: 2300},
+		{ProjectID: 1, SessionID: 2, SessionCreatedAt: createdAt, URL: "/users/{id-1}", PageViewStart: time.UnixMilli(at(5000)).UTC(), ViewportWidth: 640, ViewportHeight: 600, MaxScrollDepth: 1000},
+	}, scrollDepths)
+}
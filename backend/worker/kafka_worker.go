@@ -475,12 +475,31 @@ func (k *KafkaBatchWorker) flushTraces(ctx context.Context, traceRows []*clickho
 		return err
 	}
 
+	type release struct {
+		projectID int
+		version   string
+	}
+	releases := map[release]time.Time{}
 	filteredTraceRows := []*clickhouse.ClickhouseTraceRow{}
 	for _, trace := range traceRows {
 		if quotaExceededByProject[trace.ProjectId] {
 			continue
 		}
 		filteredTraceRows = append(filteredTraceRows, trace)
+
+		if trace.ServiceVersion != "" {
+			key := release{projectID: int(trace.ProjectId), version: trace.ServiceVersion}
+			if seenAt, ok := releases[key]; !ok || trace.Timestamp.Before(seenAt) {
+				releases[key] = trace.Timestamp
+			}
+		}
+	}
+
+	// create release records for any service versions found in ingested traces
+	for key, seenAt := range releases {
+		if _, err := k.Worker.Resolver.Store.UpsertRelease(ctx, key.projectID, key.version, seenAt); err != nil {
+			log.WithContext(ctx).Error(e.Wrap(err, "failed to create release"))
+		}
 	}
 
 	span, ctxT := util.StartSpanFromContext(ctx, fmt.Sprintf("worker.kafka.%s.flush.clickhouse.traces", k.Name), util.WithHighlightTracingDisabled(true))
@@ -291,6 +291,10 @@ func (w *Worker) writeSessionDataFromRedis(ctx context.Context, manager *payload
 			if accumulator.Error != nil {
 				return e.Wrap(accumulator.Error, "error processing event chunk")
 			}
+		} else if payloadType == model.PayloadTypeResources {
+			if err := accumulator.AddNetworkErrors(s.CreatedAt, dataObject.Contents()); err != nil {
+				log.WithContext(ctx).WithError(err).WithField("session_secure_id", s.SecureID).Warn("failed to read network errors for error clicks")
+			}
 		}
 
 		if err := compressedWriter.WriteObject(&dataObject, unmarshalled); err != nil {
@@ -492,6 +496,14 @@ func (w *Worker) processPublicWorkerMessage(ctx context.Context, task *kafkaqueu
 			log.WithContext(ctx).WithError(err).WithField("type", task.Type).WithField("key", string(task.KafkaMessage.Key)).Error("failed to process task")
 			return err
 		}
+	case kafkaqueue.AssignErrorGroupCodeOwner:
+		if task.AssignErrorGroupCodeOwner == nil {
+			break
+		}
+		if err := w.PublicResolver.Store.AssignErrorGroupCodeOwner(ctx, task.AssignErrorGroupCodeOwner.ErrorGroupID, task.AssignErrorGroupCodeOwner.ErrorObjectID); err != nil {
+			log.WithContext(ctx).WithError(err).WithField("type", task.Type).WithField("key", string(task.KafkaMessage.Key)).Error("failed to process task")
+			return err
+		}
 	case kafkaqueue.HealthCheck:
 	default:
 		log.WithContext(ctx).Errorf("Unknown task type %+v", task.Type)
@@ -634,6 +646,26 @@ func (w *Worker) AutoResolveStaleErrors(ctx context.Context) {
 	autoResolver.AutoResolveStaleErrors(ctx)
 }
 
+// Deletes audit logs older than their workspace's audit log retention
+func (w *Worker) DeleteExpiredAuditLogs(ctx context.Context) {
+	deleted, err := w.Resolver.Store.DeleteExpiredAuditLogs(ctx)
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Error("failed to delete expired audit logs")
+		return
+	}
+	log.WithContext(ctx).WithField("count", deleted).Info("deleted expired audit logs")
+}
+
+// Writes the redaction counts of redaction rules accumulated during ingest to the database
+func (w *Worker) FlushRedactionCounts(ctx context.Context) {
+	flushed, err := w.Resolver.Store.FlushRedactionCounts(ctx)
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Error("failed to flush redaction counts")
+		return
+	}
+	log.WithContext(ctx).WithField("count", flushed).Debug("flushed redaction counts")
+}
+
 func (w *Worker) excludeSession(ctx context.Context, s *model.Session, reason backend.SessionExcludedReason) error {
 	s.Excluded = true
 	s.ExcludedReason = &reason
@@ -666,7 +698,7 @@ func (w *Worker) processSession(ctx context.Context, s *model.Session) error {
 		Count:  project.RageClickCount,
 	}
 
-	accumulator := MakeEventProcessingAccumulator(s.SecureID, rageClickSettings)
+	accumulator := MakeEventProcessingAccumulator(s.SecureID, rageClickSettings, GetFrustrationSettings(project))
 
 	sessionIdString := env.Config.SessionFilePathPrefix + strconv.FormatInt(int64(s.ID), 10)
 
@@ -698,6 +730,15 @@ func (w *Worker) processSession(ctx context.Context, s *model.Session) error {
 		return errors.Wrap(err, "error scanning session payload")
 	}
 
+	if accumulator.FrustrationSettings.ErrorClickWindow > 0 {
+		var errorObjects []*model.ErrorObject
+		if err := w.Resolver.DB.WithContext(ctx).Select("timestamp", "event").Where(&model.ErrorObject{SessionID: &s.ID}).Find(&errorObjects).Error; err != nil {
+			log.WithContext(ctx).Error(e.Wrap(err, "error querying session errors for error clicks"))
+		}
+		accumulator.AddErrorObjects(errorObjects)
+		accumulator.DetectErrorClicks()
+	}
+
 	// Measure payload sizes.
 	if err := payloadManager.ReportPayloadSizes(); err != nil {
 		return errors.Wrap(err, "error reporting payload sizes")
@@ -714,10 +755,16 @@ func (w *Worker) processSession(ctx context.Context, s *model.Session) error {
 
 	payloadManager.SeekStart(ctx)
 
+	timelineIndicatorEvents := lo.Flatten([][]*parse.ReplayEvent{accumulator.EventsForTimelineIndicator, accumulator.FrustrationSignals})
+	// frustration signals are detected after the events they are shown at
+	sort.SliceStable(timelineIndicatorEvents, func(i, j int) bool {
+		return timelineIndicatorEvents[i].Timestamp.Before(timelineIndicatorEvents[j].Timestamp)
+	})
+
 	var normalness float64
-	if len(accumulator.EventsForTimelineIndicator) > 0 {
+	if len(timelineIndicatorEvents) > 0 {
 		var eventsForTimelineIndicator []*model.TimelineIndicatorEvent
-		for _, customEvent := range accumulator.EventsForTimelineIndicator {
+		for _, customEvent := range timelineIndicatorEvents {
 			eventsForTimelineIndicator = append(eventsForTimelineIndicator, &model.TimelineIndicatorEvent{
 				SessionSecureID: s.SecureID,
 				Timestamp:       customEvent.TimestampRaw,
@@ -760,6 +807,11 @@ func (w *Worker) processSession(ctx context.Context, s *model.Session) error {
 		}
 	}
 
+	clicks, scrollDepths := accumulator.HeatmapRows(s)
+	if err := w.Resolver.ClickhouseClient.WriteSessionHeatmaps(ctx, clicks, scrollDepths); err != nil {
+		log.WithContext(ctx).Error(e.Wrap(err, "error writing session heatmaps"))
+	}
+
 	userInteractionEvents := accumulator.UserInteractionEvents
 	userInteractionEvents = append(userInteractionEvents, []*parse.ReplayEvent{{
 		Timestamp: accumulator.FirstFullSnapshotTimestamp,
@@ -964,6 +1016,10 @@ func (w *Worker) processSession(ctx context.Context, s *model.Session) error {
 			ActiveLength:        accumulator.ActiveDuration.Milliseconds(),
 			EventCounts:         &eventCountsString,
 			HasRageClicks:       &hasRageClicks,
+			HasDeadClicks:       pointy.Bool(accumulator.HasFrustrationSignal(DeadClickTag)),
+			HasErrorClicks:      pointy.Bool(accumulator.HasFrustrationSignal(ErrorClickTag)),
+			HasThrashedCursor:   pointy.Bool(accumulator.HasFrustrationSignal(ThrashedCursorTag)),
+			HasUTurns:           pointy.Bool(accumulator.HasFrustrationSignal(UTurnTag)),
 			HasOutOfOrderEvents: accumulator.AreEventsOutOfOrder,
 			PagesVisited:        pagesVisited,
 			WithinBillingQuota:  &withinBillingQuota,
@@ -1326,6 +1382,17 @@ func (w *Worker) ScheduledTasks(ctx context.Context) {
 			w.AutoResolveStaleErrors(ctx)
 		}
 	}()
+	go func() {
+		w.DeleteExpiredAuditLogs(ctx)
+		for range time.Tick(time.Hour) {
+			w.DeleteExpiredAuditLogs(ctx)
+		}
+	}()
+	go func() {
+		for range time.Tick(time.Minute) {
+			w.FlushRedactionCounts(ctx)
+		}
+	}()
 
 	// block forever
 	select {}
@@ -1588,11 +1655,18 @@ type EventProcessingAccumulator struct {
 	Error error
 	// Parameters for triggering rage click detection
 	RageClickSettings RageClickSettings
+	// FrustrationSignals contains the custom events of the frustration signals detected other than rage clicks
+	FrustrationSignals []*parse.ReplayEvent
+	// Parameters for triggering frustration signal detection
+	FrustrationSettings FrustrationSettings
+	frustration         frustrationState
+	// heatmap holds the clicks and scroll depths of the page views aggregated into heatmaps
+	heatmap heatmapState
 	// Event chunk metadata for syncing player time with event chunks
 	EventChunks []*model.EventChunk
 }
 
-func MakeEventProcessingAccumulator(sessionSecureID string, rageClickSettings RageClickSettings) EventProcessingAccumulator {
+func MakeEventProcessingAccumulator(sessionSecureID string, rageClickSettings RageClickSettings, frustrationSettings FrustrationSettings) EventProcessingAccumulator {
 	return EventProcessingAccumulator{
 		SessionSecureID:            sessionSecureID,
 		ClickEventQueue:            list.New(),
@@ -1608,6 +1682,8 @@ func MakeEventProcessingAccumulator(sessionSecureID string, rageClickSettings Ra
 		AreEventsOutOfOrder:        false,
 		Error:                      nil,
 		RageClickSettings:          rageClickSettings,
+		FrustrationSignals:         []*parse.ReplayEvent{},
+		FrustrationSettings:        frustrationSettings,
 	}
 }
 
@@ -1646,6 +1722,9 @@ func processEventChunk(ctx context.Context, a EventProcessingAccumulator, events
 				continue
 			}
 		}
+		a.detectDeadClicks(event)
+		a.detectUTurn(navigationURL(event), event.Timestamp)
+		a.aggregateHeatmap(event)
 		if event.Type == parse.IncrementalSnapshot {
 			var diff time.Duration
 			if !a.LastEventTimestamp.IsZero() {
@@ -1683,6 +1762,10 @@ func processEventChunk(ctx context.Context, a EventProcessingAccumulator, events
 			// Obtains all user interaction events for calculating active and inactive segments
 			a.UserInteractionEvents = append(a.UserInteractionEvents, event)
 
+			if *mouseInteractionEventData.Source == parse.MouseMove {
+				a.detectThrashedCursor(event)
+			}
+
 			ts := event.Timestamp.Round(time.Millisecond)
 			if _, ok := a.TimestampCounts[ts]; !ok {
 				a.TimestampCounts[ts] = 0
@@ -1705,6 +1788,7 @@ func processEventChunk(ctx context.Context, a EventProcessingAccumulator, events
 
 			// save all new click events
 			a.ClickEventQueue.PushBack(event)
+			a.addClick(event)
 
 			numTotal := 0
 			rageClick := model.RageClickEvent{
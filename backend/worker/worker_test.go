@@ -710,7 +710,7 @@ func TestGetActiveDuration(t *testing.T) {
 				Window: 5 * time.Second,
 				Radius: 8,
 				Count:  5,
-			})
+			}, FrustrationSettings{})
 			for _, event := range tt.events {
 				a = processEventChunk(context.TODO(), a, event)
 				if a.Error != nil {
@@ -829,7 +829,7 @@ func TestFullSnapshotProcessed(t *testing.T) {
 				Window: 5 * time.Second,
 				Radius: 8,
 				Count:  5,
-			})
+			}, FrustrationSettings{})
 			for _, event := range tt.events {
 				a = processEventChunk(context.TODO(), a, event)
 				if a.Error != nil {
@@ -514,6 +514,7 @@ export type EnhancedUserDetailsResult = {
 export enum EnhancementSource {
 	Github = 'github',
 	Sourcemap = 'sourcemap',
+	SourceBundle = 'source_bundle',
 }
 
 export type ErrorAlert = {
@@ -692,7 +693,9 @@ export type ErrorResults = {
 
 export enum ErrorState {
 	Ignored = 'IGNORED',
+	Merged = 'MERGED',
 	Open = 'OPEN',
+	Regressed = 'REGRESSED',
 	Resolved = 'RESOLVED',
 }
 
@@ -111,7 +111,8 @@ export const ErrorFeedCard = ({ errorGroup, onClick }: Props) => {
 										: 'secondary'
 								}
 								emphasis={
-									errorGroup?.state === ErrorState.Open
+									errorGroup?.state === ErrorState.Open ||
+									errorGroup?.state === ErrorState.Regressed
 										? 'medium'
 										: 'high'
 								}
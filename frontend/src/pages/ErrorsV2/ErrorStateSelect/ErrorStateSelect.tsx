@@ -63,7 +63,10 @@ const ErrorStateSelectImpl: React.FC<Props> = ({
 	})
 
 	const { isLoggedIn } = useAuthContext()
-	const ErrorStatuses = Object.keys(ErrorState)
+	// the regressed and merged states are set by reoccurring errors and merges
+	const ErrorStatuses = Object.keys(ErrorState).filter(
+		(state) => state !== 'Regressed' && state !== 'Merged',
+	)
 	const snoozed = snoozedUntil && moment().isBefore(moment(snoozedUntil))
 
 	const handleChange = useCallback(
@@ -84,7 +84,7 @@ type Params = { project_id: string; error_secure_id: string; referrer?: string }
 
 const ERROR_QUERY_PARAM = withDefault(
 	StringParam,
-	`status=${ErrorStateEnum.Open} `,
+	`status=(${ErrorStateEnum.Open} OR ${ErrorStateEnum.Regressed}) `,
 )
 
 export default function ErrorsV2() {
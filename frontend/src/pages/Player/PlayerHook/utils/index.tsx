@@ -327,6 +327,10 @@ const CustomEventsForTimeline = [
 	'Web Vitals',
 	'Referrer',
 	'RageClicks',
+	'DeadClick',
+	'ErrorClick',
+	'ThrashedCursor',
+	'UTurn',
 	'TabHidden',
 ] as const
 const CustomEventsForTimelineSet = new Set(CustomEventsForTimeline)
@@ -26,6 +26,10 @@ export const TimelineAnnotationColors: {
 	Referrer: '--color-yellow-800',
 	TabHidden: '--color-gray-800',
 	RageClicks: '--color-red-900',
+	DeadClick: '--color-orange-500',
+	ErrorClick: '--color-red-600',
+	ThrashedCursor: '--color-yellow-800',
+	UTurn: '--color-purple-600',
 }
 
 export function getAnnotationColor(
@@ -81,6 +81,14 @@ export const getTimelineEventDisplayName = (name: string) => {
 			return 'Tab State'
 		case 'RageClicks':
 			return 'Rage Clicks'
+		case 'DeadClick':
+			return 'Dead Clicks'
+		case 'ErrorClick':
+			return 'Error Clicks'
+		case 'ThrashedCursor':
+			return 'Thrashed Cursor'
+		case 'UTurn':
+			return 'U-turns'
 		default:
 			return name
 	}
@@ -112,6 +120,14 @@ export const getTimelineEventTooltipText = (name: string) => {
 			return 'The application was hidden.'
 		case 'TabShown':
 			return 'The application became visible.'
+		case 'DeadClick':
+			return 'A click was not followed by any change of the page.'
+		case 'ErrorClick':
+			return 'A click was followed by an error.'
+		case 'ThrashedCursor':
+			return 'The cursor was moved back and forth erratically.'
+		case 'UTurn':
+			return 'The user navigated back shortly after opening a page.'
 		default:
 			return name
 	}
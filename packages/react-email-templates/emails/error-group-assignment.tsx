@@ -0,0 +1,35 @@
+import { Link, Text } from '@react-email/components'
+import * as React from 'react'
+
+import { Break, Footer, textStyle, Title } from '../components/alerts'
+import { EmailHtml, HighlightLogo } from '../components/common'
+
+export interface ErrorGroupAssignmentEmailProps {
+	title?: string
+	description?: string
+	errorLink?: string
+	event?: string
+}
+
+export const ErrorGroupAssignmentEmail = ({
+	title = 'Error Assigned to Spencer',
+	description = 'Vadim assigned an error in Highlight Production to Spencer.',
+	errorLink = 'https://localhost:3000/1/errors/1',
+	event = 'TypeError: Cannot read properties of undefined',
+}: ErrorGroupAssignmentEmailProps) => (
+	<EmailHtml previewText={title}>
+		<HighlightLogo />
+		<Title>{title}</Title>
+
+		<Text style={textStyle}>{description}</Text>
+		<Text style={textStyle}>
+			<Link href={errorLink}>{event}</Link>
+		</Text>
+
+		<Break />
+
+		<Footer alertLink={errorLink} />
+	</EmailHtml>
+)
+
+export default ErrorGroupAssignmentEmail
@@ -1,5 +1,6 @@
 import { AlertUpsertEmail } from './alert-upsert'
 import { ErrorAlertEmail } from './error-alert'
+import { ErrorGroupAssignmentEmail } from './error-group-assignment'
 import { ErrorsAlertV2Email } from './errors-alert-v2'
 import { LogAlertEmail } from './log-alert'
 import { LogsAlertV2Email } from './logs-alert-v2'
@@ -17,6 +18,7 @@ import { TrackUserPropertiesAlertEmail } from './track-user-properties-alert'
 export {
 	AlertUpsertEmail,
 	ErrorAlertEmail,
+	ErrorGroupAssignmentEmail,
 	ErrorsAlertV2Email,
 	LogAlertEmail,
 	LogsAlertV2Email,
@@ -3,6 +3,7 @@ import { APIGatewayEvent } from 'aws-lambda'
 import {
 	AlertUpsertEmail,
 	ErrorAlertEmail,
+	ErrorGroupAssignmentEmail,
 	ErrorsAlertV2Email,
 	LogAlertEmail,
 	LogsAlertV2Email,
@@ -71,6 +72,8 @@ const getEmailTemplate = (template: string) => {
 			return EventsAlertV2Email
 		case 'alert-upsert':
 			return AlertUpsertEmail
+		case 'error-group-assignment':
+			return ErrorGroupAssignmentEmail
 		default:
 			console.error('No email template found for ', template)
 	}
@@ -23,6 +23,18 @@ program
     "-bu, --backendUrl [string]",
     "An optional backend url for self-hosted deployments",
   )
+  .option(
+    "-pm, --proguardMapping [string]",
+    "Uploads the Android R8/ProGuard mapping.txt at this path for the app version instead of sourcemaps",
+  )
+  .option(
+    "-sb, --sourceBundle [string]",
+    "Uploads the zip of backend source files at this path for the service and app version instead of sourcemaps",
+  )
+  .option(
+    "-sn, --serviceName [string]",
+    "The name of the backend service of the source bundle",
+  )
   .action(uploadSourcemaps);
 
 program.parse();
@@ -10,6 +10,16 @@ const VERIFY_API_KEY_QUERY = `
   }
 `;
 
+// sourcemaps with a debug id are also uploaded under this version, indexed by the debug id,
+// so that they are found regardless of the app version and the path of the minified file
+const DEBUG_ID_VERSION = "debug-id";
+
+// android R8/ProGuard mapping files are uploaded under this name for the app version
+const PROGUARD_MAPPING_FILE_NAME = "mapping.txt";
+
+// zips of backend source files are uploaded to this directory of the app version, named by service
+const SOURCE_BUNDLE_DIRECTORY = "source-bundles/";
+
 const GET_SOURCE_MAP_URLS_QUERY = `
   query GetSourceMapUploadUrls($api_key: String!, $paths: [String!]!) {
     get_source_map_upload_urls(api_key: $api_key, paths: $paths)
@@ -23,6 +33,9 @@ export const uploadSourcemaps = async ({
   basePath,
   backendUrl,
   allowNoop,
+  proguardMapping,
+  sourceBundle,
+  serviceName,
 }: {
   apiKey: string;
   appVersion: string;
@@ -30,6 +43,9 @@ export const uploadSourcemaps = async ({
   basePath?: string;
   backendUrl?: string;
   allowNoop?: boolean;
+  proguardMapping?: string;
+  sourceBundle?: string;
+  serviceName?: string;
 }) => {
   if (!apiKey || apiKey === "") {
     if (process.env.HIGHLIGHT_SOURCEMAP_UPLOAD_API_KEY) {
@@ -74,6 +90,57 @@ export const uploadSourcemaps = async ({
 
   let organizationId = res.data.api_key_to_org_id;
 
+  if (proguardMapping) {
+    if (!appVersion) {
+      console.warn(
+        "Warning: no app version set, the mapping will be used for all unversioned errors.",
+      );
+    }
+    const mappingKey = getS3Key(
+      organizationId,
+      appVersion,
+      "",
+      PROGUARD_MAPPING_FILE_NAME,
+    );
+    const mappingPath = join(cwd(), proguardMapping);
+    const uploadUrls = await getUploadUrls(backend, apiKey, [mappingKey]);
+    if (!uploadUrls) {
+      console.info(
+        "Failed to upload the mapping file. Please see reason above.",
+      );
+      return;
+    }
+    await uploadFile(mappingPath, uploadUrls[0], mappingKey);
+    return;
+  }
+
+  if (sourceBundle) {
+    if (!serviceName) {
+      throw new Error("service name is required to upload a source bundle");
+    }
+    if (!appVersion) {
+      console.warn(
+        "Warning: no app version set, the source bundle will be used for all unversioned errors.",
+      );
+    }
+    const bundleKey = getS3Key(
+      organizationId,
+      appVersion,
+      SOURCE_BUNDLE_DIRECTORY,
+      `${serviceName}.zip`,
+    );
+    const bundlePath = join(cwd(), sourceBundle);
+    const uploadUrls = await getUploadUrls(backend, apiKey, [bundleKey]);
+    if (!uploadUrls) {
+      console.info(
+        "Failed to upload the source bundle. Please see reason above.",
+      );
+      return;
+    }
+    await uploadFile(bundlePath, uploadUrls[0], bundleKey);
+    return;
+  }
+
   console.info(`Starting to upload source maps from ${path}`);
 
   const fileList = await getAllSourceMapFiles([path], { allowNoop });
@@ -86,10 +153,30 @@ export const uploadSourcemaps = async ({
     return;
   }
 
-  const s3Keys = fileList.map(({ name }) =>
-    getS3Key(organizationId, appVersion, basePath || "", name),
+  const s3Keys = fileList.map(({ name, debugId }) =>
+    debugId
+      ? getS3Key(organizationId, DEBUG_ID_VERSION, "", `${debugId}.map`)
+      : getS3Key(organizationId, appVersion, basePath || "", name),
+  );
+
+  const uploadUrls = await getUploadUrls(backend, apiKey, s3Keys);
+  if (!uploadUrls) {
+    console.info("Failed to upload source maps. Please see reason above.");
+    return;
+  }
+
+  await Promise.all(
+    fileList.map(({ path, name }, idx) =>
+      uploadFile(path, uploadUrls[idx], name),
+    ),
   );
+};
 
+async function getUploadUrls(
+  backend: string,
+  apiKey: string,
+  paths: string[],
+): Promise<string[] | undefined> {
   const urlRes = await fetch(backend, {
     method: "post",
     headers: {
@@ -99,7 +186,7 @@ export const uploadSourcemaps = async ({
       query: GET_SOURCE_MAP_URLS_QUERY,
       variables: {
         api_key: apiKey,
-        paths: s3Keys,
+        paths,
       },
     }),
   })
@@ -118,18 +205,11 @@ export const uploadSourcemaps = async ({
     urlRes.data.get_source_map_upload_urls.length === 0
   ) {
     console.error("Error: Unable to generate source map upload urls.", urlRes);
-    console.info("Failed to upload source maps. Please see reason above.");
-    return;
+    return undefined;
   }
 
-  const uploadUrls = urlRes.data.get_source_map_upload_urls;
-
-  await Promise.all(
-    fileList.map(({ path, name }, idx) =>
-      uploadFile(path, uploadUrls[idx], name),
-    ),
-  );
-};
+  return urlRes.data.get_source_map_upload_urls;
+}
 
 const NextRouteGroupPattern = new RegExp(/(\(.+?\))\//gm);
 
@@ -137,7 +217,13 @@ async function getAllSourceMapFiles(
   paths: string[],
   { allowNoop }: { allowNoop?: boolean },
 ) {
-  const map: { path: string; name: string }[] = [];
+  const map: { path: string; name: string; debugId?: string }[] = [];
+  const addDebugId = (path: string) => {
+    const debugId = getDebugId(path);
+    if (debugId) {
+      map.push({ path, name: basename(path), debugId });
+    }
+  };
 
   await Promise.all(
     paths.map(async (path) => {
@@ -148,6 +234,7 @@ async function getAllSourceMapFiles(
           path: realPath,
           name: basename(realPath),
         });
+        addDebugId(realPath);
 
         return;
       }
@@ -174,6 +261,7 @@ async function getAllSourceMapFiles(
           path: join(realPath, file),
           name: file,
         });
+        addDebugId(join(realPath, file));
         const routeGroupRemovedPath = file.replaceAll(
           new RegExp(/(\(.+?\))\//gm),
           "",
@@ -205,8 +293,29 @@ function getS3Key(
   return `${organizationId}/${version}/${basePath}${fileName}`;
 }
 
+// returns the TC39 debug id of a sourcemap, see https://github.com/tc39/source-map/blob/main/proposals/debug-id.md
+function getDebugId(filePath: string): string | undefined {
+  if (!filePath.endsWith(".map")) {
+    return undefined;
+  }
+  try {
+    const sourceMap = JSON.parse(readFileSync(filePath, "utf8"));
+    const debugId = sourceMap.debugId ?? sourceMap.debug_id;
+    return typeof debugId === "string" && debugId
+      ? debugId.trim().toLowerCase()
+      : undefined;
+  } catch {
+    return undefined;
+  }
+}
+
 async function uploadFile(filePath: string, uploadUrl: string, name: string) {
   const fileContent = readFileSync(filePath);
-  await fetch(uploadUrl, { method: "put", body: fileContent });
+  await fetch(uploadUrl, {
+    method: "put",
+    body: fileContent,
+    // required by azure blob storage upload urls, ignored by other backends
+    headers: { "x-ms-blob-type": "BlockBlob" },
+  });
   console.log(`[Highlight] Uploaded ${filePath} to ${name}`);
 }